@@ -0,0 +1,61 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// Scheme antecede a todas las llaves para reconocerlas, p. ej. en un escaneo de secretos
+const Scheme = "usk_"
+
+const (
+	idBytes     = 6
+	secretBytes = 32
+)
+
+// Generate crea una llave nueva de la forma usk_<id>_<secreto>. Devuelve la llave, que
+// solo se muestra una vez, su prefijo visible (usk_<id>) y el hash que se guarda.
+func Generate() (key, prefix, hash string, err error) {
+	id := make([]byte, idBytes)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", err
+	}
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+	prefix = Scheme + hex.EncodeToString(id)
+	key = prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, prefix, Hash(key), nil
+}
+
+// Prefix devuelve el prefijo visible de la llave, o false si no tiene el formato
+func Prefix(key string) (string, bool) {
+	if !strings.HasPrefix(key, Scheme) {
+		return "", false
+	}
+	id, secret, found := strings.Cut(strings.TrimPrefix(key, Scheme), "_")
+	if !found || len(id) != 2*idBytes || secret == "" {
+		return "", false
+	}
+	if _, err := hex.DecodeString(id); err != nil {
+		return "", false
+	}
+	return Scheme + id, true
+}
+
+// Hash calcula el hash que se guarda de la llave. Las llaves tienen 256 bits aleatorios,
+// por lo que basta SHA-256 y no hace falta un hash lento como el de las contraseñas.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Matches compara la llave con el hash guardado en tiempo constante
+func Matches(key, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(Hash(key)), []byte(hash)) == 1
+}
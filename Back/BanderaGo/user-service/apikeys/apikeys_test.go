@@ -0,0 +1,38 @@
+package apikeys
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: la llave generada contiene su prefijo y solo coincide con su hash
+func TestGenerate(t *testing.T) {
+	key, prefix, hash, err := Generate()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(key, prefix+"_"), key)
+	assert.Len(t, prefix, len(Scheme)+12)
+	assert.NotContains(t, hash, strings.TrimPrefix(key, prefix+"_"))
+	assert.True(t, Matches(key, hash))
+	assert.False(t, Matches(key+"x", hash))
+
+	parsed, ok := Prefix(key)
+	assert.True(t, ok)
+	assert.Equal(t, prefix, parsed)
+
+	other, otherPrefix, _, err := Generate()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+	assert.NotEqual(t, prefix, otherPrefix)
+}
+
+// Caso de prueba: los valores sin el formato de una llave no tienen prefijo
+func TestPrefixRejects(t *testing.T) {
+	for _, key := range []string{"", "abc", "usk_", "usk_0123456789ab", "usk_0123456789ab_", "usk_zz23456789ab_secreto", "usk_0123_secreto", "sk_0123456789ab_secreto"} {
+		_, ok := Prefix(key)
+		assert.False(t, ok, key)
+	}
+}
@@ -0,0 +1,138 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errores centinela que clasifican las fallas del dominio. Las capas inferiores los
+// envuelven y el controlador los traduce a códigos HTTP con errors.Is.
+var (
+	ErrNotFound    = errors.New("recurso no encontrado")
+	ErrConflict    = errors.New("conflicto con el estado actual del recurso")
+	ErrValidation  = errors.New("datos inválidos")
+	ErrUnavailable = errors.New("servicio no disponible")
+	// ErrUnauthorized indica que las credenciales no son válidas
+	ErrUnauthorized = errors.New("credenciales inválidas")
+	// ErrForbidden indica que quien hace la solicitud no tiene permiso para la operación
+	ErrForbidden = errors.New("permisos insuficientes")
+	// ErrPreconditionFailed indica que el recurso cambió desde la versión que se esperaba
+	ErrPreconditionFailed = errors.New("la versión del recurso no coincide")
+	// ErrTooManyRequests indica que hay que esperar antes de volver a intentar
+	ErrTooManyRequests = errors.New("demasiados intentos")
+)
+
+// Error asocia una de las categorías centinela con la causa original y, cuando aplica,
+// el campo involucrado.
+type Error struct {
+	Kind  error
+	Field string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	message := e.Kind.Error()
+	if e.Field != "" {
+		message = fmt.Sprintf("%s: %s", message, e.Field)
+	}
+	if e.Err != nil {
+		message = fmt.Sprintf("%s: %v", message, e.Err)
+	}
+	return message
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+func NotFound(err error) error {
+	return &Error{Kind: ErrNotFound, Err: err}
+}
+
+// NotFoundField indica que no existe el recurso al que se refiere el campo, p. ej. el
+// rol que se quiere asignar
+func NotFoundField(field string, err error) error {
+	return &Error{Kind: ErrNotFound, Field: field, Err: err}
+}
+
+func Conflict(field string, err error) error {
+	return &Error{Kind: ErrConflict, Field: field, Err: err}
+}
+
+func Validation(field string, err error) error {
+	return &Error{Kind: ErrValidation, Field: field, Err: err}
+}
+
+// Violation es una regla de validación que no cumple un campo. Rule y Param
+// identifican el mensaje que se muestra al cliente.
+type Violation struct {
+	Field string
+	Rule  string
+	Param string
+}
+
+// Violations reúne todas las reglas incumplidas para reportarlas en una sola respuesta.
+type Violations []Violation
+
+func (v Violations) Error() string {
+	parts := make([]string, 0, len(v))
+	for _, violation := range v {
+		parts = append(parts, fmt.Sprintf("%s: %s", violation.Field, violation.Rule))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Invalid devuelve un error de validación con las reglas incumplidas, o nil si no hay
+// ninguna. El campo del error es el de la primera regla.
+func Invalid(violations Violations) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	return &Error{Kind: ErrValidation, Field: violations[0].Field, Err: violations}
+}
+
+// ViolationsOf devuelve las reglas incumplidas asociadas al error, si las hay.
+func ViolationsOf(err error) Violations {
+	var violations Violations
+	errors.As(err, &violations)
+	return violations
+}
+
+func Unavailable(err error) error {
+	return &Error{Kind: ErrUnavailable, Err: err}
+}
+
+func Unauthorized(err error) error {
+	return &Error{Kind: ErrUnauthorized, Err: err}
+}
+
+// UnauthorizedField indica qué credencial falta, p. ej. "otp" cuando se requiere el
+// segundo factor
+func UnauthorizedField(field string, err error) error {
+	return &Error{Kind: ErrUnauthorized, Field: field, Err: err}
+}
+
+func Forbidden(err error) error {
+	return &Error{Kind: ErrForbidden, Err: err}
+}
+
+func PreconditionFailed(err error) error {
+	return &Error{Kind: ErrPreconditionFailed, Err: err}
+}
+
+func TooManyRequests(err error) error {
+	return &Error{Kind: ErrTooManyRequests, Err: err}
+}
+
+// FieldOf devuelve el campo asociado al error, si lo hay.
+func FieldOf(err error) string {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Field
+	}
+	return ""
+}
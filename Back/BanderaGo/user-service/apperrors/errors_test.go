@@ -0,0 +1,63 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Caso de prueba: cada constructor conserva la categoría y la causa
+func TestErrorKinds(t *testing.T) {
+	cause := errors.New("driver error")
+
+	cases := []struct {
+		err  error
+		kind error
+	}{
+		{NotFound(cause), ErrNotFound},
+		{Conflict("name", cause), ErrConflict},
+		{Validation("name", cause), ErrValidation},
+		{Unavailable(cause), ErrUnavailable},
+		{PreconditionFailed(cause), ErrPreconditionFailed},
+		{Unauthorized(cause), ErrUnauthorized},
+		{Forbidden(cause), ErrForbidden},
+		{TooManyRequests(cause), ErrTooManyRequests},
+		{NotFoundField("role", cause), ErrNotFound},
+	}
+
+	for _, tc := range cases {
+		assert.ErrorIs(t, tc.err, tc.kind)
+		assert.ErrorIs(t, tc.err, cause)
+	}
+	assert.NotErrorIs(t, NotFound(cause), ErrConflict)
+}
+
+// Caso de prueba: el mensaje incluye el campo y la causa
+func TestErrorMessage(t *testing.T) {
+	err := Conflict("name", errors.New("duplicate"))
+	assert.Equal(t, "conflicto con el estado actual del recurso: name: duplicate", err.Error())
+	assert.Equal(t, "recurso no encontrado", NotFound(nil).Error())
+}
+
+// Caso de prueba: obtener el campo de un error envuelto
+func TestFieldOf(t *testing.T) {
+	err := Validation("last_name", nil)
+	assert.Equal(t, "last_name", FieldOf(err))
+	assert.Equal(t, "role", FieldOf(NotFoundField("role", nil)))
+	assert.Equal(t, "otp", FieldOf(UnauthorizedField("otp", nil)))
+	assert.Equal(t, "", FieldOf(errors.New("plain")))
+}
+
+// Caso de prueba: las reglas incumplidas se conservan en un solo error de validación
+func TestInvalid(t *testing.T) {
+	assert.NoError(t, Invalid(nil))
+
+	err := Invalid(Violations{{Field: "name", Rule: "required"}, {Field: "phone", Rule: "phone"}})
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.Equal(t, "name", FieldOf(err))
+	assert.Equal(t, "datos inválidos: name: name: required; phone: phone", err.Error())
+	assert.Len(t, ViolationsOf(fmt.Errorf("envuelto: %w", err)), 2)
+	assert.Empty(t, ViolationsOf(Validation("name", nil)))
+}
@@ -1,18 +1,238 @@
 package config
 
 import (
+	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+const (
+	DefaultRequestTimeout = 30 * time.Second
+	DefaultLanguage       = "es"
+	DefaultSQLitePath     = "users.db"
+	DefaultSSLMode        = "disable"
+	DefaultPurgeInterval  = time.Hour
+)
+
+// Valores por defecto de la política y del hash de contraseñas; los parámetros de
+// argon2id son los mínimos que recomienda OWASP
+const (
+	PasswordArgon2id = "argon2id"
+	PasswordBcrypt   = "bcrypt"
+
+	DefaultPasswordAlgorithm = PasswordArgon2id
+	DefaultPasswordMinLength = 12
+	DefaultPasswordMaxLength = 128
+	DefaultArgon2Memory      = 19 * 1024
+	DefaultArgon2Iterations  = 2
+	DefaultArgon2Parallelism = 1
+	DefaultBcryptCost        = 12
+	bcryptMaxPasswordBytes   = 72
+)
+
+// Valores por defecto de los tokens que emite y acepta el servicio
+const (
+	DefaultTokenIssuer   = "user-service"
+	DefaultTokenAudience = "user-service"
+	DefaultTokenTTL      = 15 * time.Minute
+	DefaultTokenLeeway   = 30 * time.Second
+	DefaultRefreshTTL    = 30 * 24 * time.Hour
+	// DefaultSessionCacheTTL es cuánto se recuerda que la sesión de un token sigue activa
+	DefaultSessionCacheTTL = 10 * time.Second
+	// DefaultMFAIssuer es el nombre con el que las aplicaciones de autenticación muestran la cuenta
+	DefaultMFAIssuer = "user-service"
+	// minTokenSecretBytes es el tamaño mínimo del secreto de HS256 (RFC 7518, sección 3.2)
+	minTokenSecretBytes = 32
+)
+
+// Valores por defecto de los bloqueos por intentos fallidos de inicio de sesión
+const (
+	DefaultLockoutMaxUserFailures = 5
+	DefaultLockoutMaxIPFailures   = 50
+	DefaultLockoutWindow          = 15 * time.Minute
+	DefaultLockoutDuration        = 15 * time.Minute
+	DefaultLockoutDelay           = time.Second
+	DefaultLockoutMaxDelay        = 30 * time.Second
+)
+
+// Medios de envío de correos que se pueden elegir con MAIL_DRIVER
+const (
+	MailSMTP   = "smtp"
+	MailFile   = "file"
+	MailMemory = "memory"
+)
+
+// Valores por defecto de los correos de verificación y de restablecimiento de contraseña
+const (
+	DefaultMailDriver       = MailFile
+	DefaultMailDir          = "mail"
+	DefaultMailFrom         = "user-service <no-reply@localhost>"
+	DefaultSMTPPort         = 587
+	DefaultVerificationTTL  = 24 * time.Hour
+	DefaultResetTTL         = time.Hour
+	DefaultResetMaxPerEmail = 3
+	DefaultResetMaxPerIP    = 20
+	DefaultResetWindow      = time.Hour
+)
+
+// Valores por defecto del proveedor OpenID Connect
+const (
+	DefaultOIDCCodeTTL      = time.Minute
+	DefaultOIDCTokenTTL     = time.Hour
+	DefaultOIDCKeyRotation  = 30 * 24 * time.Hour
+	DefaultOIDCKeyRetention = 24 * time.Hour
+)
+
+// Motores de persistencia que se pueden elegir con DB_DRIVER
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
+
 type UserConfig struct {
-	DBUser          string
-	DBPassword      string
-	DBName          string
-	DBPort          string
-	DBTable         string
-	ApplicationPort string
+	DBDriver   string
+	DBPath     string
+	DBSSLMode  string
+	DBUser     string
+	DBPassword string
+	DBName     string
+	DBPort     string
+	DBTable    string
+	// MigrateOnStartup aplica las migraciones pendientes al iniciar la aplicación
+	MigrateOnStartup bool
+	ApplicationPort  string
+	RequestTimeout   time.Duration
+	DefaultLanguage  string
+	// TrustedProxies son las IP o redes CIDR de los proxies cuyo X-Forwarded-For se
+	// acepta como IP del cliente; sin ninguno se usa la IP de la conexión
+	TrustedProxies []string
+	// DeletedRetention es el tiempo que se conservan los usuarios eliminados lógicamente
+	// antes de purgarlos; con 0 no se purgan
+	DeletedRetention time.Duration
+	PurgeInterval    time.Duration
+	Password         PasswordConfig
+	Auth             AuthConfig
+	MFA              MFAConfig
+	Lockout          LockoutConfig
+	Mail             MailConfig
+	OIDC             OIDCConfig
+}
+
+// AuthConfig define cómo se firman y verifican los tokens de acceso. Se aceptan tokens
+// HS256 firmados con Secret y tokens RS256 o EdDSA firmados con alguna llave de JWKSFile.
+type AuthConfig struct {
+	Issuer   string
+	Audience string
+	Secret   string
+	// JWKSFile es un archivo JSON Web Key Set local con las llaves públicas aceptadas y,
+	// opcionalmente, la llave privada con la que firma el servicio
+	JWKSFile string
+	// SigningKeyID es el kid de la llave de JWKSFile con la que se emiten los tokens; si
+	// no se define se firman con Secret
+	SigningKeyID string
+	TokenTTL     time.Duration
+	// Leeway tolera diferencias de reloj al validar exp, nbf e iat
+	Leeway time.Duration
+	// RefreshTTL es el tiempo que una sesión puede estar sin renovarse antes de vencer
+	RefreshTTL time.Duration
+	// SessionCacheTTL es cuánto se recuerda que la sesión de un token está activa; una
+	// sesión revocada en otra instancia se rechaza a lo sumo tras este tiempo
+	SessionCacheTTL time.Duration
+}
+
+// MFAConfig define el segundo factor de autenticación
+type MFAConfig struct {
+	// Issuer es el emisor del enlace otpauth que registran las aplicaciones de autenticación
+	Issuer string
+	// RequiredRoles son los roles cuyos permisos solo se otorgan a los tokens emitidos con
+	// un segundo factor
+	RequiredRoles []string
+}
+
+// LockoutConfig limita los intentos fallidos de inicio de sesión por cuenta y por IP
+type LockoutConfig struct {
+	// MaxUserFailures son los intentos fallidos seguidos que bloquean una cuenta
+	MaxUserFailures int
+	// MaxIPFailures son los intentos fallidos desde una misma IP, a cualquier cuenta, que
+	// bloquean esa IP
+	MaxIPFailures int
+	// Window es el tiempo sin fallos tras el cual se olvidan los intentos anteriores
+	Window time.Duration
+	// Duration es lo que dura un bloqueo
+	Duration time.Duration
+	// Delay es la espera que se exige a una cuenta tras su primer fallo; se duplica con
+	// cada fallo hasta MaxDelay. Con 0 no hay espera entre intentos.
+	Delay    time.Duration
+	MaxDelay time.Duration
+}
+
+// MailConfig define cómo se envían los correos y los enlaces de un solo uso que contienen
+type MailConfig struct {
+	Driver string
+	From   string
+	// Dir es la carpeta donde el medio file deja cada correo como un archivo .eml
+	Dir          string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	// BaseURL es la dirección de la aplicación que abre los enlaces de los correos, p. ej.
+	// https://app.example.com/verify-email?token=...
+	BaseURL string
+	// TokenSecret firma los enlaces; por defecto es JWT_SECRET
+	TokenSecret     string
+	VerificationTTL time.Duration
+	ResetTTL        time.Duration
+	// ResetMaxPerEmail y ResetMaxPerIP son las solicitudes de restablecimiento que se
+	// aceptan por correo y por IP en ResetWindow; con 0 no se limitan
+	ResetMaxPerEmail int
+	ResetMaxPerIP    int
+	ResetWindow      time.Duration
+}
+
+// OIDCConfig define el proveedor OpenID Connect con el que otras aplicaciones inician
+// sesión con los usuarios del servicio
+type OIDCConfig struct {
+	// Issuer es la URL pública de la raíz del servicio; de ella cuelgan el discovery y los
+	// endpoints de OAuth
+	Issuer string
+	// LoginURL es la página a la que /oauth2/authorize envía al usuario sin sesión, con la
+	// dirección de regreso en return_to
+	LoginURL string
+	// KeySecret cifra las llaves privadas de firma guardadas en la base; por defecto es
+	// JWT_SECRET
+	KeySecret string
+	CodeTTL   time.Duration
+	TokenTTL  time.Duration
+	// KeyRotation es la edad a la que se reemplaza la llave de firma; con 0 solo se rota
+	// desde la API
+	KeyRotation time.Duration
+	// KeyRetention es el tiempo que una llave retirada se sigue publicando para verificar
+	// los tokens que firmó
+	KeyRetention time.Duration
+}
+
+// PasswordConfig define la política de contraseñas y el algoritmo con el que se
+// guardan. Los campos en cero toman el valor por defecto.
+type PasswordConfig struct {
+	Algorithm string
+	MinLength int
+	MaxLength int
+	// CheckBreached rechaza las contraseñas de la lista de contraseñas filtradas
+	CheckBreached bool
+	// Argon2Memory es la memoria en KiB que usa cada hash
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	BcryptCost        int
 }
 
 func NewUserConfig() (*UserConfig, error) {
@@ -21,14 +241,367 @@ func NewUserConfig() (*UserConfig, error) {
 		return nil, err
 	}
 
+	requestTimeout, err := getDurationEnv("REQUEST_TIMEOUT", DefaultRequestTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	deletedRetention, err := getDurationEnv("DELETED_RETENTION", 0)
+	if err != nil {
+		return nil, err
+	}
+	purgeInterval, err := getDurationEnv("PURGE_INTERVAL", DefaultPurgeInterval)
+	if err != nil {
+		return nil, err
+	}
+	if purgeInterval <= 0 {
+		return nil, fmt.Errorf("la variable de entorno 'PURGE_INTERVAL' debe ser mayor que cero")
+	}
+
+	trustedProxies := getListEnv("TRUSTED_PROXIES")
+	for _, proxy := range trustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return nil, fmt.Errorf("la variable de entorno 'TRUSTED_PROXIES' debe contener IP o redes CIDR: '%s'", proxy)
+		}
+	}
+
+	dbDriver := getEnvOrDefault("DB_DRIVER", DriverMySQL)
+	switch dbDriver {
+	case DriverMySQL, DriverPostgres, DriverSQLite, DriverMemory:
+	default:
+		return nil, fmt.Errorf("la variable de entorno 'DB_DRIVER' debe ser %s, %s, %s o %s: '%s'", DriverMySQL, DriverPostgres, DriverSQLite, DriverMemory, dbDriver)
+	}
+
+	// SQLite se usa para desarrollo y pruebas, por lo que migra al iniciar salvo que se indique lo contrario
+	migrateOnStartup, err := getBoolEnv("DB_MIGRATE_ON_STARTUP", dbDriver == DriverSQLite)
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := newPasswordConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := newAuthConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	lockout, err := newLockoutConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	mail, err := newMailConfig(auth.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	oidc, err := newOIDCConfig(auth.Secret, mail.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
 	userConfig := &UserConfig{
-		DBUser:          os.Getenv("DB_USER"),
-		DBPassword:      os.Getenv("DB_PASSWORD"),
-		DBName:          os.Getenv("DB_NAME"),
-		DBPort:          os.Getenv("DB_PORT"),
-		DBTable:         os.Getenv("DB_TABLE"),
-		ApplicationPort: os.Getenv("APP_PORT"),
+		DBDriver:         dbDriver,
+		DBPath:           getEnvOrDefault("DB_PATH", DefaultSQLitePath),
+		DBSSLMode:        getEnvOrDefault("DB_SSLMODE", DefaultSSLMode),
+		DBUser:           os.Getenv("DB_USER"),
+		DBPassword:       os.Getenv("DB_PASSWORD"),
+		DBName:           os.Getenv("DB_NAME"),
+		DBPort:           os.Getenv("DB_PORT"),
+		DBTable:          os.Getenv("DB_TABLE"),
+		MigrateOnStartup: migrateOnStartup,
+		ApplicationPort:  os.Getenv("APP_PORT"),
+		RequestTimeout:   requestTimeout,
+		DefaultLanguage:  getEnvOrDefault("DEFAULT_LANGUAGE", DefaultLanguage),
+		TrustedProxies:   trustedProxies,
+		DeletedRetention: deletedRetention,
+		PurgeInterval:    purgeInterval,
+		Password:         password,
+		Auth:             auth,
+		MFA: MFAConfig{
+			Issuer:        getEnvOrDefault("MFA_ISSUER", DefaultMFAIssuer),
+			RequiredRoles: getListEnv("MFA_REQUIRED_ROLES"),
+		},
+		Lockout: lockout,
+		Mail:    mail,
+		OIDC:    oidc,
 	}
 
 	return userConfig, nil
 }
+
+func newPasswordConfig() (PasswordConfig, error) {
+	password := PasswordConfig{Algorithm: getEnvOrDefault("PASSWORD_ALGORITHM", DefaultPasswordAlgorithm)}
+	if password.Algorithm != PasswordArgon2id && password.Algorithm != PasswordBcrypt {
+		return password, fmt.Errorf("la variable de entorno 'PASSWORD_ALGORITHM' debe ser %s o %s: '%s'", PasswordArgon2id, PasswordBcrypt, password.Algorithm)
+	}
+
+	var err error
+	if password.CheckBreached, err = getBoolEnv("PASSWORD_CHECK_BREACHED", true); err != nil {
+		return password, err
+	}
+	ints := []struct {
+		key          string
+		target       *int
+		defaultValue int
+	}{
+		{"PASSWORD_MIN_LENGTH", &password.MinLength, DefaultPasswordMinLength},
+		{"PASSWORD_MAX_LENGTH", &password.MaxLength, DefaultPasswordMaxLength},
+		{"BCRYPT_COST", &password.BcryptCost, DefaultBcryptCost},
+	}
+	for _, setting := range ints {
+		if *setting.target, err = getIntEnv(setting.key, setting.defaultValue); err != nil {
+			return password, err
+		}
+	}
+	memory, err := getIntEnv("ARGON2_MEMORY", DefaultArgon2Memory)
+	if err != nil {
+		return password, err
+	}
+	iterations, err := getIntEnv("ARGON2_ITERATIONS", DefaultArgon2Iterations)
+	if err != nil {
+		return password, err
+	}
+	parallelism, err := getIntEnv("ARGON2_PARALLELISM", DefaultArgon2Parallelism)
+	if err != nil {
+		return password, err
+	}
+	if parallelism > 255 {
+		return password, fmt.Errorf("la variable de entorno 'ARGON2_PARALLELISM' debe ser como máximo 255")
+	}
+	password.Argon2Memory, password.Argon2Iterations, password.Argon2Parallelism = uint32(memory), uint32(iterations), uint8(parallelism)
+
+	if password.MaxLength < password.MinLength {
+		return password, fmt.Errorf("la variable de entorno 'PASSWORD_MAX_LENGTH' no puede ser menor que 'PASSWORD_MIN_LENGTH'")
+	}
+	// bcrypt solo considera los primeros 72 bytes de la contraseña
+	if password.Algorithm == PasswordBcrypt && password.MaxLength > bcryptMaxPasswordBytes {
+		return password, fmt.Errorf("con bcrypt la variable de entorno 'PASSWORD_MAX_LENGTH' debe ser como máximo %d", bcryptMaxPasswordBytes)
+	}
+	if password.BcryptCost < 4 || password.BcryptCost > 31 {
+		return password, fmt.Errorf("la variable de entorno 'BCRYPT_COST' debe estar entre 4 y 31")
+	}
+	return password, nil
+}
+
+func newAuthConfig() (AuthConfig, error) {
+	auth := AuthConfig{
+		Issuer:       getEnvOrDefault("JWT_ISSUER", DefaultTokenIssuer),
+		Audience:     getEnvOrDefault("JWT_AUDIENCE", DefaultTokenAudience),
+		Secret:       os.Getenv("JWT_SECRET"),
+		JWKSFile:     os.Getenv("JWT_JWKS_FILE"),
+		SigningKeyID: os.Getenv("JWT_SIGNING_KEY_ID"),
+	}
+	var err error
+	if auth.TokenTTL, err = getDurationEnv("JWT_TTL", DefaultTokenTTL); err != nil {
+		return auth, err
+	}
+	if auth.Leeway, err = getDurationEnv("JWT_LEEWAY", DefaultTokenLeeway); err != nil {
+		return auth, err
+	}
+	if auth.RefreshTTL, err = getDurationEnv("JWT_REFRESH_TTL", DefaultRefreshTTL); err != nil {
+		return auth, err
+	}
+	if auth.SessionCacheTTL, err = getDurationEnv("JWT_SESSION_CACHE_TTL", DefaultSessionCacheTTL); err != nil {
+		return auth, err
+	}
+	if auth.TokenTTL <= 0 {
+		return auth, fmt.Errorf("la variable de entorno 'JWT_TTL' debe ser mayor que cero")
+	}
+	if auth.RefreshTTL <= auth.TokenTTL {
+		return auth, fmt.Errorf("la variable de entorno 'JWT_REFRESH_TTL' debe ser mayor que 'JWT_TTL'")
+	}
+	if auth.SessionCacheTTL < 0 || auth.SessionCacheTTL >= auth.TokenTTL {
+		return auth, fmt.Errorf("la variable de entorno 'JWT_SESSION_CACHE_TTL' no puede ser negativa y debe ser menor que 'JWT_TTL'")
+	}
+	if auth.Secret != "" && len(auth.Secret) < minTokenSecretBytes {
+		return auth, fmt.Errorf("la variable de entorno 'JWT_SECRET' debe tener al menos %d bytes", minTokenSecretBytes)
+	}
+	if auth.SigningKeyID != "" && auth.JWKSFile == "" {
+		return auth, fmt.Errorf("la variable de entorno 'JWT_SIGNING_KEY_ID' requiere 'JWT_JWKS_FILE'")
+	}
+	return auth, nil
+}
+
+func newLockoutConfig() (LockoutConfig, error) {
+	var lockout LockoutConfig
+	var err error
+	if lockout.MaxUserFailures, err = getIntEnv("LOCKOUT_MAX_USER_FAILURES", DefaultLockoutMaxUserFailures); err != nil {
+		return lockout, err
+	}
+	if lockout.MaxIPFailures, err = getIntEnv("LOCKOUT_MAX_IP_FAILURES", DefaultLockoutMaxIPFailures); err != nil {
+		return lockout, err
+	}
+	if lockout.Window, err = getDurationEnv("LOCKOUT_WINDOW", DefaultLockoutWindow); err != nil {
+		return lockout, err
+	}
+	if lockout.Duration, err = getDurationEnv("LOCKOUT_DURATION", DefaultLockoutDuration); err != nil {
+		return lockout, err
+	}
+	if lockout.Delay, err = getDurationEnv("LOCKOUT_DELAY", DefaultLockoutDelay); err != nil {
+		return lockout, err
+	}
+	if lockout.MaxDelay, err = getDurationEnv("LOCKOUT_MAX_DELAY", DefaultLockoutMaxDelay); err != nil {
+		return lockout, err
+	}
+	if lockout.Window <= 0 || lockout.Duration <= 0 {
+		return lockout, fmt.Errorf("las variables de entorno 'LOCKOUT_WINDOW' y 'LOCKOUT_DURATION' deben ser mayores que cero")
+	}
+	if lockout.Delay < 0 || lockout.MaxDelay < lockout.Delay {
+		return lockout, fmt.Errorf("la variable de entorno 'LOCKOUT_MAX_DELAY' debe ser mayor o igual que 'LOCKOUT_DELAY', y esta no puede ser negativa")
+	}
+	return lockout, nil
+}
+
+func newMailConfig(authSecret string) (MailConfig, error) {
+	mail := MailConfig{
+		Driver:       getEnvOrDefault("MAIL_DRIVER", DefaultMailDriver),
+		From:         getEnvOrDefault("MAIL_FROM", DefaultMailFrom),
+		Dir:          getEnvOrDefault("MAIL_DIR", DefaultMailDir),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		BaseURL:      strings.TrimSuffix(getEnvOrDefault("APP_BASE_URL", "http://localhost:"+getEnvOrDefault("APP_PORT", "9091")), "/"),
+		TokenSecret:  getEnvOrDefault("MAIL_TOKEN_SECRET", authSecret),
+	}
+	switch mail.Driver {
+	case MailSMTP, MailFile, MailMemory:
+	default:
+		return mail, fmt.Errorf("la variable de entorno 'MAIL_DRIVER' debe ser %s, %s o %s: '%s'", MailSMTP, MailFile, MailMemory, mail.Driver)
+	}
+
+	var err error
+	if mail.SMTPPort, err = getIntEnv("SMTP_PORT", DefaultSMTPPort); err != nil {
+		return mail, err
+	}
+	if mail.VerificationTTL, err = getDurationEnv("EMAIL_VERIFICATION_TTL", DefaultVerificationTTL); err != nil {
+		return mail, err
+	}
+	if mail.ResetTTL, err = getDurationEnv("PASSWORD_RESET_TTL", DefaultResetTTL); err != nil {
+		return mail, err
+	}
+	if mail.VerificationTTL <= 0 || mail.ResetTTL <= 0 {
+		return mail, fmt.Errorf("las variables de entorno 'EMAIL_VERIFICATION_TTL' y 'PASSWORD_RESET_TTL' deben ser mayores que cero")
+	}
+	if mail.ResetMaxPerEmail, err = getIntEnv("PASSWORD_RESET_MAX_PER_EMAIL", DefaultResetMaxPerEmail); err != nil {
+		return mail, err
+	}
+	if mail.ResetMaxPerIP, err = getIntEnv("PASSWORD_RESET_MAX_PER_IP", DefaultResetMaxPerIP); err != nil {
+		return mail, err
+	}
+	if mail.ResetWindow, err = getDurationEnv("PASSWORD_RESET_WINDOW", DefaultResetWindow); err != nil {
+		return mail, err
+	}
+	if mail.ResetMaxPerEmail < 0 || mail.ResetMaxPerIP < 0 {
+		return mail, fmt.Errorf("las variables de entorno 'PASSWORD_RESET_MAX_PER_EMAIL' y 'PASSWORD_RESET_MAX_PER_IP' no pueden ser negativas")
+	}
+	if mail.ResetWindow <= 0 {
+		return mail, fmt.Errorf("la variable de entorno 'PASSWORD_RESET_WINDOW' debe ser mayor que cero")
+	}
+	if mail.Driver == MailSMTP && mail.SMTPHost == "" {
+		return mail, fmt.Errorf("la variable de entorno 'SMTP_HOST' es obligatoria con MAIL_DRIVER=%s", MailSMTP)
+	}
+	if baseURL, err := url.Parse(mail.BaseURL); err != nil || !baseURL.IsAbs() {
+		return mail, fmt.Errorf("la variable de entorno 'APP_BASE_URL' debe ser una URL absoluta: '%s'", mail.BaseURL)
+	}
+	if mail.TokenSecret != "" && len(mail.TokenSecret) < minTokenSecretBytes {
+		return mail, fmt.Errorf("la variable de entorno 'MAIL_TOKEN_SECRET' debe tener al menos %d bytes", minTokenSecretBytes)
+	}
+	return mail, nil
+}
+
+func newOIDCConfig(authSecret, appBaseURL string) (OIDCConfig, error) {
+	oidc := OIDCConfig{
+		Issuer:    strings.TrimSuffix(getEnvOrDefault("OIDC_ISSUER", "http://localhost:"+getEnvOrDefault("APP_PORT", "9091")), "/"),
+		LoginURL:  getEnvOrDefault("OIDC_LOGIN_URL", appBaseURL+"/login"),
+		KeySecret: getEnvOrDefault("OIDC_KEY_SECRET", authSecret),
+	}
+	var err error
+	if oidc.CodeTTL, err = getDurationEnv("OIDC_CODE_TTL", DefaultOIDCCodeTTL); err != nil {
+		return oidc, err
+	}
+	if oidc.TokenTTL, err = getDurationEnv("OIDC_TOKEN_TTL", DefaultOIDCTokenTTL); err != nil {
+		return oidc, err
+	}
+	if oidc.KeyRotation, err = getDurationEnv("OIDC_KEY_ROTATION", DefaultOIDCKeyRotation); err != nil {
+		return oidc, err
+	}
+	if oidc.KeyRetention, err = getDurationEnv("OIDC_KEY_RETENTION", DefaultOIDCKeyRetention); err != nil {
+		return oidc, err
+	}
+	if oidc.CodeTTL <= 0 || oidc.TokenTTL <= 0 || oidc.KeyRotation < 0 {
+		return oidc, fmt.Errorf("las variables de entorno 'OIDC_CODE_TTL' y 'OIDC_TOKEN_TTL' deben ser mayores que cero y 'OIDC_KEY_ROTATION' no puede ser negativa")
+	}
+	// Una llave retirada debe seguir publicada hasta que venzan los tokens que firmó
+	if oidc.KeyRetention < oidc.TokenTTL {
+		return oidc, fmt.Errorf("la variable de entorno 'OIDC_KEY_RETENTION' debe ser mayor o igual que 'OIDC_TOKEN_TTL'")
+	}
+	if issuer, err := url.Parse(oidc.Issuer); err != nil || !issuer.IsAbs() || issuer.RawQuery != "" || issuer.Fragment != "" {
+		return oidc, fmt.Errorf("la variable de entorno 'OIDC_ISSUER' debe ser una URL absoluta sin query ni fragmento: '%s'", oidc.Issuer)
+	}
+	if loginURL, err := url.Parse(oidc.LoginURL); err != nil || !loginURL.IsAbs() {
+		return oidc, fmt.Errorf("la variable de entorno 'OIDC_LOGIN_URL' debe ser una URL absoluta: '%s'", oidc.LoginURL)
+	}
+	if oidc.KeySecret != "" && len(oidc.KeySecret) < minTokenSecretBytes {
+		return oidc, fmt.Errorf("la variable de entorno 'OIDC_KEY_SECRET' debe tener al menos %d bytes", minTokenSecretBytes)
+	}
+	return oidc, nil
+}
+
+// getIntEnv lee un entero positivo
+func getIntEnv(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		return 0, fmt.Errorf("la variable de entorno '%s' debe ser un entero positivo: '%s'", key, value)
+	}
+	return parsed, nil
+}
+
+func getDurationEnv(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("la variable de entorno '%s' no es una duración válida: %v", key, err)
+	}
+	return duration, nil
+}
+
+func getBoolEnv(key string, defaultValue bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("la variable de entorno '%s' debe ser true o false: '%s'", key, value)
+	}
+	return parsed, nil
+}
+
+// getListEnv lee una lista separada por comas, sin elementos vacíos
+func getListEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -18,11 +20,15 @@ func TestNewUserConfig(t *testing.T) {
 				DB_PORT=3306
 				DB_TABLE=test_table
 				APP_PORT=8080
+				REQUEST_TIMEOUT=5s
+				DEFAULT_LANGUAGE=en
 				`
 
 	err := os.WriteFile(".env", []byte(content), 0644) //Creando archivo temporal en el paquete para test
 	require.NoError(t, err, "Failed to create .env file")
 	defer os.Remove(".env") //Eliminar el archivo .env al final de la prueba
+	defer os.Unsetenv("REQUEST_TIMEOUT")
+	defer os.Unsetenv("DEFAULT_LANGUAGE")
 
 	// carga las variables de ambiente del archivo .env
 	config, err := NewUserConfig()
@@ -35,6 +41,81 @@ func TestNewUserConfig(t *testing.T) {
 	assert.Equal(t, "3306", config.DBPort, "DBPort should be set to '3306'")
 	assert.Equal(t, "test_table", config.DBTable, "DBTable should be set to 'test_table'")
 	assert.Equal(t, "8080", config.ApplicationPort, "ApplicationPort should be set to '8080'")
+	assert.Equal(t, 5*time.Second, config.RequestTimeout, "RequestTimeout should be set to 5s")
+	assert.Equal(t, "en", config.DefaultLanguage, "DefaultLanguage should be set to 'en'")
+}
+
+// Probar el valor por defecto del tiempo de espera por solicitud
+func TestNewUserConfigDefaultRequestTimeout(t *testing.T) {
+	err := os.WriteFile(".env", []byte("APP_PORT=8080\n"), 0644)
+	require.NoError(t, err, "Failed to create .env file")
+	defer os.Remove(".env")
+
+	config, err := NewUserConfig()
+	require.NoError(t, err, "Failed to load user config")
+	assert.Equal(t, DefaultRequestTimeout, config.RequestTimeout, "RequestTimeout should fall back to the default")
+	assert.Equal(t, DefaultLanguage, config.DefaultLanguage, "DefaultLanguage should fall back to the default")
+	assert.Equal(t, DriverMySQL, config.DBDriver, "DBDriver should fall back to MySQL")
+	assert.Equal(t, DefaultSQLitePath, config.DBPath, "DBPath should fall back to the default")
+	assert.Equal(t, DefaultSSLMode, config.DBSSLMode, "DBSSLMode should fall back to the default")
+	assert.False(t, config.MigrateOnStartup, "MigrateOnStartup should be disabled for MySQL by default")
+	assert.Zero(t, config.DeletedRetention, "DeletedRetention should be disabled by default")
+	assert.Equal(t, DefaultPurgeInterval, config.PurgeInterval, "PurgeInterval should fall back to the default")
+	assert.Nil(t, config.TrustedProxies, "TrustedProxies should trust no proxy by default")
+}
+
+// Probar los proxies de confianza: IP o redes CIDR separadas por comas
+func TestNewUserConfigTrustedProxies(t *testing.T) {
+	err := os.WriteFile(".env", []byte("APP_PORT=8080\n"), 0644)
+	require.NoError(t, err, "Failed to create .env file")
+	defer os.Remove(".env")
+
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.1,")
+	config, err := NewUserConfig()
+	require.NoError(t, err, "Failed to load user config")
+	assert.Equal(t, []string{"10.0.0.0/8", "192.0.2.1"}, config.TrustedProxies)
+
+	t.Setenv("TRUSTED_PROXIES", "balanceador")
+	_, err = NewUserConfig()
+	assert.ErrorContains(t, err, "TRUSTED_PROXIES")
+}
+
+// Probar la selección del motor de persistencia
+func TestNewUserConfigDBDriver(t *testing.T) {
+	err := os.WriteFile(".env", []byte("DB_DRIVER=sqlite\nDB_PATH=:memory:\n"), 0644)
+	require.NoError(t, err, "Failed to create .env file")
+	defer os.Remove(".env")
+	defer os.Unsetenv("DB_DRIVER")
+	defer os.Unsetenv("DB_PATH")
+
+	config, err := NewUserConfig()
+	require.NoError(t, err, "Failed to load user config")
+	assert.Equal(t, DriverSQLite, config.DBDriver)
+	assert.Equal(t, ":memory:", config.DBPath)
+	assert.True(t, config.MigrateOnStartup, "MigrateOnStartup should be enabled for SQLite by default")
+}
+
+// Probar un motor de persistencia desconocido
+func TestNewUserConfigInvalidDBDriver(t *testing.T) {
+	err := os.WriteFile(".env", []byte("DB_DRIVER=oracle\n"), 0644)
+	require.NoError(t, err, "Failed to create .env file")
+	defer os.Remove(".env")
+	defer os.Unsetenv("DB_DRIVER")
+
+	_, err = NewUserConfig()
+	assert.ErrorContains(t, err, "DB_DRIVER")
+}
+
+// Probar un tiempo de espera con formato inválido
+func TestNewUserConfigInvalidRequestTimeout(t *testing.T) {
+	err := os.WriteFile(".env", []byte("REQUEST_TIMEOUT=soon\n"), 0644)
+	require.NoError(t, err, "Failed to create .env file")
+	defer os.Remove(".env")
+	defer os.Unsetenv("REQUEST_TIMEOUT")
+
+	_, err = NewUserConfig()
+	assert.Error(t, err, "Expected error when REQUEST_TIMEOUT is not a duration")
+	assert.Contains(t, err.Error(), "REQUEST_TIMEOUT")
 }
 
 // Probar cuando no existe el archivo .env
@@ -44,3 +125,279 @@ func TestNewUserConfigMissingEnvFile(t *testing.T) {
 	_, err := NewUserConfig()
 	assert.Error(t, err, "Expected error when .env file is missing")
 }
+
+// Probar la configuración de PostgreSQL
+func TestNewUserConfigPostgres(t *testing.T) {
+	err := os.WriteFile(".env", []byte("DB_DRIVER=postgres\nDB_SSLMODE=require\n"), 0644)
+	require.NoError(t, err, "Failed to create .env file")
+	defer os.Remove(".env")
+	defer os.Unsetenv("DB_DRIVER")
+	defer os.Unsetenv("DB_SSLMODE")
+
+	config, err := NewUserConfig()
+	require.NoError(t, err, "Failed to load user config")
+	assert.Equal(t, DriverPostgres, config.DBDriver)
+	assert.Equal(t, "require", config.DBSSLMode)
+}
+
+// Probar la migración al iniciar
+func TestNewUserConfigMigrateOnStartup(t *testing.T) {
+	err := os.WriteFile(".env", []byte("DB_MIGRATE_ON_STARTUP=true\n"), 0644)
+	require.NoError(t, err, "Failed to create .env file")
+	defer os.Remove(".env")
+	defer os.Unsetenv("DB_MIGRATE_ON_STARTUP")
+
+	config, err := NewUserConfig()
+	require.NoError(t, err, "Failed to load user config")
+	assert.True(t, config.MigrateOnStartup)
+
+	os.Setenv("DB_MIGRATE_ON_STARTUP", "maybe")
+	_, err = NewUserConfig()
+	assert.ErrorContains(t, err, "DB_MIGRATE_ON_STARTUP")
+}
+
+// Probar la retención de usuarios eliminados
+func TestNewUserConfigDeletedRetention(t *testing.T) {
+	err := os.WriteFile(".env", []byte("DELETED_RETENTION=720h\nPURGE_INTERVAL=10m\n"), 0644)
+	require.NoError(t, err, "Failed to create .env file")
+	defer os.Remove(".env")
+	defer os.Unsetenv("DELETED_RETENTION")
+	defer os.Unsetenv("PURGE_INTERVAL")
+
+	config, err := NewUserConfig()
+	require.NoError(t, err, "Failed to load user config")
+	assert.Equal(t, 720*time.Hour, config.DeletedRetention)
+	assert.Equal(t, 10*time.Minute, config.PurgeInterval)
+
+	os.Setenv("PURGE_INTERVAL", "0s")
+	_, err = NewUserConfig()
+	assert.ErrorContains(t, err, "PURGE_INTERVAL")
+}
+
+// Probar la política y el algoritmo de contraseñas
+func TestNewUserConfigPassword(t *testing.T) {
+	err := os.WriteFile(".env", []byte("APP_PORT=8080\n"), 0644)
+	require.NoError(t, err, "Failed to create .env file")
+	defer os.Remove(".env")
+
+	config, err := NewUserConfig()
+	require.NoError(t, err, "Failed to load user config")
+	assert.Equal(t, PasswordConfig{
+		Algorithm:         PasswordArgon2id,
+		MinLength:         DefaultPasswordMinLength,
+		MaxLength:         DefaultPasswordMaxLength,
+		CheckBreached:     true,
+		Argon2Memory:      DefaultArgon2Memory,
+		Argon2Iterations:  DefaultArgon2Iterations,
+		Argon2Parallelism: DefaultArgon2Parallelism,
+		BcryptCost:        DefaultBcryptCost,
+	}, config.Password)
+
+	for key, value := range map[string]string{"PASSWORD_ALGORITHM": "bcrypt", "PASSWORD_MAX_LENGTH": "64", "BCRYPT_COST": "10", "PASSWORD_CHECK_BREACHED": "false"} {
+		t.Setenv(key, value)
+	}
+	config, err = NewUserConfig()
+	require.NoError(t, err, "Failed to load user config")
+	assert.Equal(t, PasswordBcrypt, config.Password.Algorithm)
+	assert.Equal(t, 64, config.Password.MaxLength)
+	assert.Equal(t, 10, config.Password.BcryptCost)
+	assert.False(t, config.Password.CheckBreached)
+}
+
+// Probar valores inválidos de la configuración de contraseñas
+func TestNewUserConfigInvalidPassword(t *testing.T) {
+	err := os.WriteFile(".env", []byte("APP_PORT=8080\n"), 0644)
+	require.NoError(t, err, "Failed to create .env file")
+	defer os.Remove(".env")
+
+	cases := []struct {
+		env map[string]string
+		key string
+	}{
+		{map[string]string{"PASSWORD_ALGORITHM": "md5"}, "PASSWORD_ALGORITHM"},
+		{map[string]string{"PASSWORD_MIN_LENGTH": "-1"}, "PASSWORD_MIN_LENGTH"},
+		{map[string]string{"PASSWORD_MIN_LENGTH": "20", "PASSWORD_MAX_LENGTH": "10"}, "PASSWORD_MAX_LENGTH"},
+		{map[string]string{"PASSWORD_ALGORITHM": "bcrypt"}, "PASSWORD_MAX_LENGTH"},
+		{map[string]string{"BCRYPT_COST": "40"}, "BCRYPT_COST"},
+		{map[string]string{"ARGON2_PARALLELISM": "300"}, "ARGON2_PARALLELISM"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.key, func(t *testing.T) {
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+			_, err := NewUserConfig()
+			assert.ErrorContains(t, err, tc.key)
+		})
+	}
+}
+
+// Probar los valores por defecto y personalizados de los tokens de acceso
+func TestNewUserConfigAuth(t *testing.T) {
+	err := os.WriteFile(".env", []byte("APP_PORT=8080\n"), 0644)
+	require.NoError(t, err, "Failed to create .env file")
+	defer os.Remove(".env")
+
+	config, err := NewUserConfig()
+	require.NoError(t, err, "Failed to load user config")
+	assert.Equal(t, AuthConfig{
+		Issuer:          DefaultTokenIssuer,
+		Audience:        DefaultTokenAudience,
+		TokenTTL:        DefaultTokenTTL,
+		Leeway:          DefaultTokenLeeway,
+		RefreshTTL:      DefaultRefreshTTL,
+		SessionCacheTTL: DefaultSessionCacheTTL,
+	}, config.Auth)
+
+	for key, value := range map[string]string{"JWT_ISSUER": "https://id.example.com", "JWT_SECRET": strings.Repeat("s", 32), "JWT_TTL": "1h",
+		"JWT_JWKS_FILE": "/etc/user-service/jwks.json", "JWT_SIGNING_KEY_ID": "rsa-1", "JWT_REFRESH_TTL": "168h"} {
+		t.Setenv(key, value)
+	}
+	config, err = NewUserConfig()
+	require.NoError(t, err, "Failed to load user config")
+	assert.Equal(t, "https://id.example.com", config.Auth.Issuer)
+	assert.Equal(t, time.Hour, config.Auth.TokenTTL)
+	assert.Equal(t, "rsa-1", config.Auth.SigningKeyID)
+	assert.Equal(t, 7*24*time.Hour, config.Auth.RefreshTTL)
+}
+
+// Probar la configuración del segundo factor
+func TestNewUserConfigMFA(t *testing.T) {
+	err := os.WriteFile(".env", []byte("APP_PORT=8080\n"), 0644)
+	require.NoError(t, err, "Failed to create .env file")
+	defer os.Remove(".env")
+
+	config, err := NewUserConfig()
+	require.NoError(t, err, "Failed to load user config")
+	assert.Equal(t, MFAConfig{Issuer: DefaultMFAIssuer}, config.MFA)
+
+	t.Setenv("MFA_ISSUER", "Bandera")
+	t.Setenv("MFA_REQUIRED_ROLES", " admin, ,auditor ")
+	config, err = NewUserConfig()
+	require.NoError(t, err, "Failed to load user config")
+	assert.Equal(t, MFAConfig{Issuer: "Bandera", RequiredRoles: []string{"admin", "auditor"}}, config.MFA)
+}
+
+// Probar valores inválidos de la configuración de los tokens
+func TestNewUserConfigInvalidAuth(t *testing.T) {
+	err := os.WriteFile(".env", []byte("APP_PORT=8080\n"), 0644)
+	require.NoError(t, err, "Failed to create .env file")
+	defer os.Remove(".env")
+
+	cases := []struct {
+		env map[string]string
+		key string
+	}{
+		{map[string]string{"JWT_SECRET": "corto"}, "JWT_SECRET"},
+		{map[string]string{"JWT_TTL": "0s"}, "JWT_TTL"},
+		{map[string]string{"JWT_LEEWAY": "mucho"}, "JWT_LEEWAY"},
+		{map[string]string{"JWT_SIGNING_KEY_ID": "rsa-1"}, "JWT_SIGNING_KEY_ID"},
+		{map[string]string{"JWT_REFRESH_TTL": "10m"}, "JWT_REFRESH_TTL"},
+		{map[string]string{"JWT_SESSION_CACHE_TTL": "15m"}, "JWT_SESSION_CACHE_TTL"},
+		{map[string]string{"JWT_SESSION_CACHE_TTL": "-1s"}, "JWT_SESSION_CACHE_TTL"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.key, func(t *testing.T) {
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+			_, err := NewUserConfig()
+			assert.ErrorContains(t, err, tc.key)
+		})
+	}
+}
+
+// Probar la configuración de los bloqueos por intentos fallidos
+func TestNewUserConfigLockout(t *testing.T) {
+	err := os.WriteFile(".env", []byte("APP_PORT=8080\n"), 0644)
+	require.NoError(t, err, "Failed to create .env file")
+	defer os.Remove(".env")
+
+	config, err := NewUserConfig()
+	require.NoError(t, err, "Failed to load user config")
+	assert.Equal(t, LockoutConfig{MaxUserFailures: 5, MaxIPFailures: 50, Window: 15 * time.Minute, Duration: 15 * time.Minute,
+		Delay: time.Second, MaxDelay: 30 * time.Second}, config.Lockout)
+
+	t.Setenv("LOCKOUT_MAX_USER_FAILURES", "3")
+	t.Setenv("LOCKOUT_DELAY", "0s")
+	config, err = NewUserConfig()
+	require.NoError(t, err, "Failed to load user config")
+	assert.Equal(t, 3, config.Lockout.MaxUserFailures)
+	assert.Zero(t, config.Lockout.Delay)
+
+	for key, value := range map[string]string{"LOCKOUT_MAX_IP_FAILURES": "0", "LOCKOUT_DURATION": "0s", "LOCKOUT_MAX_DELAY": "-1s"} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			_, err := NewUserConfig()
+			assert.ErrorContains(t, err, key)
+		})
+	}
+}
+
+// Probar la configuración del envío de correos
+func TestNewUserConfigMail(t *testing.T) {
+	err := os.WriteFile(".env", []byte("APP_PORT=8080\n"), 0644)
+	require.NoError(t, err, "Failed to create .env file")
+	defer os.Remove(".env")
+
+	t.Setenv("JWT_SECRET", "0123456789abcdef0123456789abcdef")
+	config, err := NewUserConfig()
+	require.NoError(t, err, "Failed to load user config")
+	assert.Equal(t, MailConfig{Driver: MailFile, From: DefaultMailFrom, Dir: DefaultMailDir, SMTPPort: DefaultSMTPPort,
+		BaseURL: "http://localhost:8080", TokenSecret: "0123456789abcdef0123456789abcdef",
+		VerificationTTL: 24 * time.Hour, ResetTTL: time.Hour, ResetMaxPerEmail: 3, ResetMaxPerIP: 20, ResetWindow: time.Hour}, config.Mail)
+
+	t.Setenv("MAIL_DRIVER", "smtp")
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	t.Setenv("APP_BASE_URL", "https://app.example.com/")
+	t.Setenv("PASSWORD_RESET_TTL", "30m")
+	config, err = NewUserConfig()
+	require.NoError(t, err, "Failed to load user config")
+	assert.Equal(t, "smtp.example.com", config.Mail.SMTPHost)
+	assert.Equal(t, "https://app.example.com", config.Mail.BaseURL)
+	assert.Equal(t, 30*time.Minute, config.Mail.ResetTTL)
+
+	for key, value := range map[string]string{"MAIL_DRIVER": "paloma", "APP_BASE_URL": "app.example.com", "MAIL_TOKEN_SECRET": "corto", "EMAIL_VERIFICATION_TTL": "0s",
+		"PASSWORD_RESET_MAX_PER_IP": "-1", "PASSWORD_RESET_WINDOW": "0s"} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			_, err := NewUserConfig()
+			assert.ErrorContains(t, err, key)
+		})
+	}
+	t.Run("SMTP_HOST", func(t *testing.T) {
+		t.Setenv("SMTP_HOST", "")
+		_, err := NewUserConfig()
+		assert.ErrorContains(t, err, "SMTP_HOST")
+	})
+}
+
+// Caso de prueba: el emisor toma el puerto de la aplicación y la retención no puede ser menor que la vida de los tokens
+func TestNewUserConfigOIDC(t *testing.T) {
+	err := os.WriteFile(".env", []byte("APP_PORT=8080\n"), 0644)
+	require.NoError(t, err, "Failed to create .env file")
+	defer os.Remove(".env")
+
+	t.Setenv("JWT_SECRET", "0123456789abcdef0123456789abcdef")
+	t.Setenv("APP_BASE_URL", "https://app.example.com")
+	config, err := NewUserConfig()
+	require.NoError(t, err, "Failed to load user config")
+	assert.Equal(t, OIDCConfig{Issuer: "http://localhost:8080", LoginURL: "https://app.example.com/login",
+		KeySecret: "0123456789abcdef0123456789abcdef", CodeTTL: time.Minute, TokenTTL: time.Hour,
+		KeyRotation: 30 * 24 * time.Hour, KeyRetention: 24 * time.Hour}, config.OIDC)
+
+	t.Setenv("OIDC_ISSUER", "https://sso.example.com/")
+	t.Setenv("OIDC_KEY_ROTATION", "0s")
+	config, err = NewUserConfig()
+	require.NoError(t, err, "Failed to load user config")
+	assert.Equal(t, "https://sso.example.com", config.OIDC.Issuer)
+	assert.Zero(t, config.OIDC.KeyRotation)
+
+	for key, value := range map[string]string{"OIDC_ISSUER": "https://sso.example.com/?a=1", "OIDC_LOGIN_URL": "/login", "OIDC_KEY_SECRET": "corto", "OIDC_CODE_TTL": "0s", "OIDC_KEY_RETENTION": "30m"} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			_, err := NewUserConfig()
+			assert.ErrorContains(t, err, key)
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"application/dtos/input"
+	"application/facade"
+	"application/i18n"
+	"application/problems"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyController struct {
+	responder
+	APIKeyFacade facade.APIKeyFacade
+}
+
+func NewAPIKeyController(facade facade.APIKeyFacade, catalog *i18n.Catalog) *APIKeyController {
+	return &APIKeyController{responder: responder{catalog: catalog}, APIKeyFacade: facade}
+}
+
+// @Summary List API keys
+// @Description List the API keys, including revoked ones, ordered by ID. Only the visible prefix of each key is returned
+// @Produce json
+// @Success 200 {array} output.APIKeyOut
+// @Failure 401,403,500,503,504 {object} output.ProblemOut
+// @Tags API keys
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/api-keys [get]
+func (ac *APIKeyController) GetAPIKeys(c *gin.Context) {
+	keysOut, err := ac.APIKeyFacade.GetAPIKeys(c.Request.Context())
+	if err != nil {
+		ac.respondError(c, err, problems.CodeAPIKeyGetFailed, ac.messages(c).MessageErrorGetAPIKeys)
+		return
+	}
+
+	c.JSON(http.StatusOK, keysOut)
+}
+
+// @Summary Get an API key
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} output.APIKeyOut
+// @Failure 400,401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags API keys
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/api-keys/{id} [get]
+func (ac *APIKeyController) GetAPIKey(c *gin.Context) {
+	id, ok := ac.apiKeyID(c)
+	if !ok {
+		return
+	}
+
+	keyOut, err := ac.APIKeyFacade.GetAPIKey(c.Request.Context(), id)
+	if err != nil {
+		ac.respondError(c, err, problems.CodeAPIKeyGetFailed, ac.messages(c).MessageErrorGetAPIKeys)
+		return
+	}
+
+	c.JSON(http.StatusOK, keyOut)
+}
+
+// @Summary Create an API key
+// @Description Create an API key with a set of permissions, which cannot exceed those of the caller. The key is only returned in this response; store it securely
+// @Accept json
+// @Produce json
+// @Param apiKey body input.APIKeyIn true "Name, permissions and optional expiration"
+// @Success 201 {object} output.APIKeyCreatedOut
+// @Failure 400,401,403,422,500,503,504 {object} output.ProblemOut
+// @Tags API keys
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/api-keys [post]
+func (ac *APIKeyController) CreateAPIKey(c *gin.Context) {
+	var apiKeyIn input.APIKeyIn
+	if err := c.ShouldBindJSON(&apiKeyIn); err != nil {
+		ac.respondBindingError(c, err)
+		return
+	}
+
+	createdOut, err := ac.APIKeyFacade.CreateAPIKey(c.Request.Context(), apiKeyIn)
+	if err != nil {
+		ac.respondError(c, err, problems.CodeAPIKeyUpdateFailed, ac.messages(c).MessageErrorUpdateAPIKey)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, createdOut)
+}
+
+// @Summary Rotate an API key
+// @Description Replace the secret and prefix of an API key, keeping its permissions and expiration. The previous key stops working immediately. Revoked keys cannot be rotated, and the caller must hold every permission of the key
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} output.APIKeyCreatedOut
+// @Failure 400,401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags API keys
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/api-keys/{id}/rotate [post]
+func (ac *APIKeyController) RotateAPIKey(c *gin.Context) {
+	id, ok := ac.apiKeyID(c)
+	if !ok {
+		return
+	}
+
+	rotatedOut, err := ac.APIKeyFacade.RotateAPIKey(c.Request.Context(), id)
+	if err != nil {
+		ac.respondError(c, err, problems.CodeAPIKeyUpdateFailed, ac.messages(c).MessageErrorUpdateAPIKey)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, rotatedOut)
+}
+
+// @Summary Revoke an API key
+// @Description Revoke an API key immediately. The key is kept in the listing with its revocation date. Revoking a revoked key has no effect
+// @Param id path int true "API key ID"
+// @Success 204
+// @Failure 400,401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags API keys
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/api-keys/{id} [delete]
+func (ac *APIKeyController) RevokeAPIKey(c *gin.Context) {
+	id, ok := ac.apiKeyID(c)
+	if !ok {
+		return
+	}
+
+	if err := ac.APIKeyFacade.RevokeAPIKey(c.Request.Context(), id); err != nil {
+		ac.respondError(c, err, problems.CodeAPIKeyUpdateFailed, ac.messages(c).MessageErrorUpdateAPIKey)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// apiKeyID lee el parámetro id; si no es válido responde 400 y devuelve false
+func (ac *APIKeyController) apiKeyID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		problems.Respond(c, problems.New(http.StatusBadRequest, problems.CodeInvalidAPIKeyID, ac.messages(c).MessageErrorAPIKeyID))
+		return 0, false
+	}
+	return uint(id), true
+}
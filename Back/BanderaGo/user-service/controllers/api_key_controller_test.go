@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/problems"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAPIKeyFacade simula la fachada de llaves de API
+type MockAPIKeyFacade struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyFacade) GetAPIKeys(ctx context.Context) ([]output.APIKeyOut, error) {
+	args := m.Called()
+	return args.Get(0).([]output.APIKeyOut), args.Error(1)
+}
+
+func (m *MockAPIKeyFacade) GetAPIKey(ctx context.Context, id uint) (output.APIKeyOut, error) {
+	args := m.Called(id)
+	return args.Get(0).(output.APIKeyOut), args.Error(1)
+}
+
+func (m *MockAPIKeyFacade) CreateAPIKey(ctx context.Context, apiKeyIn input.APIKeyIn) (output.APIKeyCreatedOut, error) {
+	args := m.Called(apiKeyIn)
+	return args.Get(0).(output.APIKeyCreatedOut), args.Error(1)
+}
+
+func (m *MockAPIKeyFacade) RotateAPIKey(ctx context.Context, id uint) (output.APIKeyCreatedOut, error) {
+	args := m.Called(id)
+	return args.Get(0).(output.APIKeyCreatedOut), args.Error(1)
+}
+
+func (m *MockAPIKeyFacade) RevokeAPIKey(ctx context.Context, id uint) error {
+	return m.Called(id).Error(0)
+}
+
+// Caso de prueba: la llave creada se devuelve una sola vez y sin caché
+func TestCreateAPIKey(t *testing.T) {
+	apiKeyFacade := new(MockAPIKeyFacade)
+	apiKeyController := NewAPIKeyController(apiKeyFacade, testCatalog)
+	createdAt := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	apiKeyFacade.On("CreateAPIKey", input.APIKeyIn{Name: "batch", Permissions: []string{"users:read"}}).Return(output.APIKeyCreatedOut{
+		APIKeyOut: output.APIKeyOut{ID: 1, Name: "batch", Prefix: "usk_0123456789ab", Permissions: []string{"users:read"}, CreatedAt: createdAt},
+		Key:       "usk_0123456789ab_secreto",
+	}, nil)
+
+	w := authRequest(apiKeyController.CreateAPIKey, "POST", "/api/api-keys", "", `{"name":"batch","permissions":["users:read"]}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"id":1,"name":"batch","prefix":"usk_0123456789ab","permissions":["users:read"],"created_at":"2024-03-01T00:00:00Z","key":"usk_0123456789ab_secreto"}`, w.Body.String())
+
+	w = authRequest(apiKeyController.CreateAPIKey, "POST", "/api/api-keys", "", `{"name":"batch"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	apiKeyFacade.AssertNumberOfCalls(t, "CreateAPIKey", 1)
+}
+
+// Caso de prueba: identificador inválido, llave inexistente y revocación sin contenido
+func TestRevokeAPIKey(t *testing.T) {
+	apiKeyFacade := new(MockAPIKeyFacade)
+	apiKeyController := NewAPIKeyController(apiKeyFacade, testCatalog)
+	apiKeyFacade.On("RevokeAPIKey", uint(1)).Return(nil)
+	apiKeyFacade.On("RevokeAPIKey", uint(9)).Return(apperrors.NotFoundField("api_key", errors.New("no existe")))
+
+	w := authRequest(apiKeyController.RevokeAPIKey, "DELETE", "/api/api-keys/1", "1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = authRequest(apiKeyController.RevokeAPIKey, "DELETE", "/api/api-keys/9", "9", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assertProblem(t, w, problems.CodeAPIKeyNotFound, testMessages.MessageErrorAPIKeyNotFound)
+
+	w = authRequest(apiKeyController.RotateAPIKey, "POST", "/api/api-keys/abc/rotate", "abc", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertProblem(t, w, problems.CodeInvalidAPIKeyID, testMessages.MessageErrorAPIKeyID)
+	apiKeyFacade.AssertExpectations(t)
+}
@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/facade"
+	"application/i18n"
+	"application/lockout"
+	"application/problems"
+	"application/requestctx"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthController struct {
+	responder
+	AuthFacade facade.AuthFacade
+}
+
+func NewAuthController(facade facade.AuthFacade, catalog *i18n.Catalog) *AuthController {
+	return &AuthController{responder: responder{catalog: catalog}, AuthFacade: facade}
+}
+
+// @Summary Set the password of a user
+// @Description Set a new password without asking for the current one. The password must satisfy the password policy
+// @Accept json
+// @Param id path int true "User ID"
+// @Param password body input.SetPasswordIn true "New password"
+// @Success 204
+// @Failure 400,401,403,404,422,500,503,504 {object} output.ProblemOut
+// @Tags Contraseñas
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/password [put]
+func (ac *AuthController) SetPassword(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		ac.respondInvalidID(c)
+		return
+	}
+
+	var passwordIn input.SetPasswordIn
+	if err := c.ShouldBindJSON(&passwordIn); err != nil {
+		ac.respondBindingError(c, err)
+		return
+	}
+
+	if err := ac.AuthFacade.SetPassword(c.Request.Context(), uint(userID), passwordIn); err != nil {
+		ac.respondError(c, err, problems.CodePasswordFailed, ac.messages(c).MessageErrorPassword)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Change the password of a user
+// @Description Change the password after checking the current one. A wrong current password responds 422 on the current_password field
+// @Accept json
+// @Param id path int true "User ID"
+// @Param password body input.ChangePasswordIn true "Current and new password"
+// @Success 204
+// @Failure 400,401,403,404,422,500,503,504 {object} output.ProblemOut
+// @Tags Contraseñas
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/password/change [post]
+func (ac *AuthController) ChangePassword(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		ac.respondInvalidID(c)
+		return
+	}
+
+	var passwordIn input.ChangePasswordIn
+	if err := c.ShouldBindJSON(&passwordIn); err != nil {
+		ac.respondBindingError(c, err)
+		return
+	}
+
+	if err := ac.AuthFacade.ChangePassword(c.Request.Context(), uint(userID), passwordIn); err != nil {
+		ac.respondError(c, err, problems.CodePasswordFailed, ac.messages(c).MessageErrorPassword)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Log in
+// @Description Check the credentials of a user by email or username. Unknown users and wrong passwords respond the same 401. Users with a second factor also send the TOTP code or a recovery code in otp; without it the response is 401 MFA_REQUIRED
+// @Accept json
+// @Produce json
+// @Param credentials body input.LoginIn true "Email or username, password and second factor code"
+// @Success 200 {object} output.LoginOut
+// @Failure 400,401,422,429,500,503,504 {object} output.ProblemOut
+// @Tags Autenticación
+// @Router /api/auth/login [post]
+func (ac *AuthController) Login(c *gin.Context) {
+	var loginIn input.LoginIn
+	if err := c.ShouldBindJSON(&loginIn); err != nil {
+		ac.respondBindingError(c, err)
+		return
+	}
+
+	loginOut, err := ac.AuthFacade.Login(clientContext(c), loginIn)
+	if err != nil {
+		ac.respondLoginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loginOut)
+}
+
+// @Summary Issue an access token
+// @Description Issue a bearer access token with the OAuth 2.0 password grant. The username is the email or username of the user and the token subject is the user ID. Each token opens a new session and comes with a refresh token. Users with a second factor also send otp; the token then carries the amr claim with "mfa"
+// @Accept json,x-www-form-urlencoded
+// @Produce json
+// @Param token body input.TokenIn true "Grant type, email or username, password and second factor code"
+// @Success 200 {object} output.TokenOut
+// @Failure 400,401,422,429,500,503,504 {object} output.ProblemOut
+// @Tags Autenticación
+// @Router /api/auth/token [post]
+func (ac *AuthController) IssueToken(c *gin.Context) {
+	var tokenIn input.TokenIn
+	if err := c.ShouldBind(&tokenIn); err != nil {
+		ac.respondBindingError(c, err)
+		return
+	}
+
+	tokenOut, err := ac.AuthFacade.IssueToken(clientContext(c), tokenIn)
+	if err != nil {
+		ac.respondLoginError(c, err)
+		return
+	}
+
+	// Las respuestas con tokens no deben guardarse en caché (RFC 6749, sección 5.1)
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(http.StatusOK, tokenOut)
+}
+
+// @Summary Refresh an access token
+// @Description Exchange a refresh token for a new access token and a new refresh token. Each refresh token can be used once; reusing one revokes its session
+// @Accept json,x-www-form-urlencoded
+// @Produce json
+// @Param refresh body input.RefreshIn true "Refresh token"
+// @Success 200 {object} output.TokenOut
+// @Failure 400,401,422,500,503,504 {object} output.ProblemOut
+// @Tags Autenticación
+// @Router /api/auth/refresh [post]
+func (ac *AuthController) Refresh(c *gin.Context) {
+	var refreshIn input.RefreshIn
+	if err := c.ShouldBind(&refreshIn); err != nil {
+		ac.respondBindingError(c, err)
+		return
+	}
+
+	tokenOut, err := ac.AuthFacade.Refresh(clientContext(c), refreshIn)
+	if errors.Is(err, apperrors.ErrUnauthorized) {
+		problems.Respond(c, problems.New(http.StatusUnauthorized, problems.CodeInvalidRefresh, ac.messages(c).MessageErrorRefreshToken))
+		return
+	}
+	if err != nil {
+		ac.respondError(c, err, problems.CodeLoginFailed, ac.messages(c).MessageErrorLogin)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(http.StatusOK, tokenOut)
+}
+
+// @Summary Log out
+// @Description Revoke the session of a refresh token. Access tokens already issued stay valid until they expire. Unknown or revoked tokens also respond 204
+// @Accept json,x-www-form-urlencoded
+// @Param refresh body input.RefreshIn true "Refresh token"
+// @Success 204
+// @Failure 400,422,500,503,504 {object} output.ProblemOut
+// @Tags Autenticación
+// @Router /api/auth/logout [post]
+func (ac *AuthController) Logout(c *gin.Context) {
+	var refreshIn input.RefreshIn
+	if err := c.ShouldBind(&refreshIn); err != nil {
+		ac.respondBindingError(c, err)
+		return
+	}
+
+	if err := ac.AuthFacade.Logout(c.Request.Context(), refreshIn); err != nil {
+		ac.respondError(c, err, problems.CodeSessionRevokeFailed, ac.messages(c).MessageErrorRevokeSession)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get the failed login attempts of a user
+// @Description Get the recent failed login attempts of a user and whether the account is locked
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} output.LockoutOut
+// @Failure 400,401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags Autenticación
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/lockout [get]
+func (ac *AuthController) GetLockout(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		ac.respondInvalidID(c)
+		return
+	}
+
+	lockoutOut, err := ac.AuthFacade.GetLockout(c.Request.Context(), uint(userID))
+	if err != nil {
+		ac.respondError(c, err, problems.CodeLockoutGetFailed, ac.messages(c).MessageErrorGetLockout)
+		return
+	}
+
+	c.JSON(http.StatusOK, lockoutOut)
+}
+
+// @Summary Unlock a user
+// @Description Lift the lockout of a user and forget the failed login attempts. Unlocking a user that is not locked also responds 204
+// @Param id path int true "User ID"
+// @Success 204
+// @Failure 400,401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags Autenticación
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/lockout [delete]
+func (ac *AuthController) Unlock(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		ac.respondInvalidID(c)
+		return
+	}
+
+	if err := ac.AuthFacade.Unlock(c.Request.Context(), uint(userID)); err != nil {
+		ac.respondError(c, err, problems.CodeUnlockFailed, ac.messages(c).MessageErrorUnlock)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// respondLoginError responde los errores del inicio de sesión. Un bloqueo o una espera
+// entre intentos responden 429 con Retry-After en segundos.
+func (ac *AuthController) respondLoginError(c *gin.Context, err error) {
+	var locked *lockout.LockedError
+	if !errors.Is(err, apperrors.ErrTooManyRequests) || !errors.As(err, &locked) {
+		ac.respondError(c, err, problems.CodeLoginFailed, ac.messages(c).MessageErrorLogin)
+		return
+	}
+
+	setRetryAfter(c, locked.RetryAfter)
+	if locked.Locked {
+		problems.Respond(c, problems.New(http.StatusTooManyRequests, problems.CodeAccountLocked, ac.messages(c).MessageErrorAccountLocked))
+		return
+	}
+	problems.Respond(c, problems.New(http.StatusTooManyRequests, problems.CodeLoginThrottled, ac.messages(c).MessageErrorLoginThrottled))
+}
+
+// clientContext agrega al contexto de la solicitud la IP y el agente del cliente que se
+// guardan con la sesión
+func clientContext(c *gin.Context) context.Context {
+	return requestctx.WithClient(c.Request.Context(), requestctx.Client{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+}
@@ -0,0 +1,258 @@
+package controllers
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/lockout"
+	"application/problems"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuthFacade simula la fachada de contraseñas e inicio de sesión
+type MockAuthFacade struct {
+	mock.Mock
+}
+
+func (m *MockAuthFacade) SetPassword(ctx context.Context, id uint, passwordIn input.SetPasswordIn) error {
+	return m.Called(id, passwordIn).Error(0)
+}
+
+func (m *MockAuthFacade) ChangePassword(ctx context.Context, id uint, passwordIn input.ChangePasswordIn) error {
+	return m.Called(id, passwordIn).Error(0)
+}
+
+func (m *MockAuthFacade) Login(ctx context.Context, loginIn input.LoginIn) (output.LoginOut, error) {
+	args := m.Called(loginIn)
+	return args.Get(0).(output.LoginOut), args.Error(1)
+}
+
+func (m *MockAuthFacade) IssueToken(ctx context.Context, tokenIn input.TokenIn) (output.TokenOut, error) {
+	args := m.Called(tokenIn)
+	return args.Get(0).(output.TokenOut), args.Error(1)
+}
+
+func (m *MockAuthFacade) Refresh(ctx context.Context, refreshIn input.RefreshIn) (output.TokenOut, error) {
+	args := m.Called(refreshIn)
+	return args.Get(0).(output.TokenOut), args.Error(1)
+}
+
+func (m *MockAuthFacade) Logout(ctx context.Context, refreshIn input.RefreshIn) error {
+	return m.Called(refreshIn).Error(0)
+}
+
+func (m *MockAuthFacade) GetLockout(ctx context.Context, userID uint) (output.LockoutOut, error) {
+	args := m.Called(userID)
+	return args.Get(0).(output.LockoutOut), args.Error(1)
+}
+
+func (m *MockAuthFacade) Unlock(ctx context.Context, userID uint) error {
+	return m.Called(userID).Error(0)
+}
+
+// authRequest ejecuta el handler con el cuerpo JSON y el parámetro id indicados
+func authRequest(handler gin.HandlerFunc, method, path, id, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	if id != "" {
+		c.Params = gin.Params{{Key: "id", Value: id}}
+	}
+	c.Request = req
+	handler(c)
+	// Fuera de un router gin no escribe el estado de una respuesta sin cuerpo
+	c.Writer.WriteHeaderNow()
+	return w
+}
+
+// Caso de prueba: asignar y cambiar la contraseña responden sin contenido
+func TestSetAndChangePassword(t *testing.T) {
+	authFacade := new(MockAuthFacade)
+	authController := NewAuthController(authFacade, testCatalog)
+	authFacade.On("SetPassword", uint(1), input.SetPasswordIn{Password: "caballo correcto"}).Return(nil)
+	authFacade.On("ChangePassword", uint(1), input.ChangePasswordIn{CurrentPassword: "caballo correcto", NewPassword: "batería grapa"}).Return(nil)
+
+	w := authRequest(authController.SetPassword, "PUT", "/api/users/1/password", "1", `{"password":"caballo correcto"}`)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	w = authRequest(authController.ChangePassword, "POST", "/api/users/1/password/change", "1", `{"current_password":"caballo correcto","new_password":"batería grapa"}`)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	authFacade.AssertExpectations(t)
+}
+
+// Caso de prueba: identificador inválido, cuerpo incompleto y contraseña que no cumple la política
+func TestSetPasswordInvalid(t *testing.T) {
+	authFacade := new(MockAuthFacade)
+	authController := NewAuthController(authFacade, testCatalog)
+	authFacade.On("SetPassword", uint(1), mock.Anything).Return(apperrors.Invalid(apperrors.Violations{{Field: "password", Rule: "breached"}}))
+
+	w := authRequest(authController.SetPassword, "PUT", "/api/users/abc/password", "abc", `{"password":"x"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertProblem(t, w, problems.CodeInvalidUserID, testMessages.MessageErrorID)
+
+	w = authRequest(authController.ChangePassword, "POST", "/api/users/1/password/change", "1", `{"new_password":"batería grapa"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	problem := assertProblem(t, w, problems.CodeValidationFailed, testMessages.MessageErrorValidation)
+	assert.Equal(t, "current_password", problem.Errors[0].Field)
+
+	w = authRequest(authController.SetPassword, "PUT", "/api/users/1/password", "1", `{"password":"password1234"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	problem = assertProblem(t, w, problems.CodeValidationFailed, testMessages.MessageErrorValidation)
+	assert.Equal(t, []output.FieldErrorOut{{Field: "password", Code: "breached", Message: testMessages.Field("breached", "")}}, problem.Errors)
+}
+
+// Caso de prueba: un error sin categoría responde con el código propio de la operación
+func TestSetPasswordError(t *testing.T) {
+	authFacade := new(MockAuthFacade)
+	authFacade.On("SetPassword", uint(1), mock.Anything).Return(errors.New("hash failed"))
+
+	w := authRequest(NewAuthController(authFacade, testCatalog).SetPassword, "PUT", "/api/users/1/password", "1", `{"password":"caballo correcto"}`)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assertProblem(t, w, problems.CodePasswordFailed, testMessages.MessageErrorPassword)
+}
+
+// Caso de prueba: inicio de sesión correcto y con credenciales inválidas
+func TestLogin(t *testing.T) {
+	authFacade := new(MockAuthFacade)
+	authController := NewAuthController(authFacade, testCatalog)
+	authFacade.On("Login", input.LoginIn{Login: "ana", Password: "caballo correcto"}).Return(output.LoginOut{User: output.GetUserOut{ID: 1, Name: "Ana", LastName: "Díaz", Username: "ana", Version: 1}}, nil)
+	authFacade.On("Login", input.LoginIn{Login: "admin", Password: "caballo correcto"}).Return(output.LoginOut{}, apperrors.UnauthorizedField("otp", errors.New("mfa")))
+	authFacade.On("Login", mock.Anything).Return(output.LoginOut{}, apperrors.Unauthorized(errors.New("wrong password")))
+
+	w := authRequest(authController.Login, "POST", "/api/auth/login", "", `{"login":"ana","password":"caballo correcto"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user":{"id":1,"name":"Ana","last_name":"Díaz","username":"ana"}}`, w.Body.String())
+
+	w = authRequest(authController.Login, "POST", "/api/auth/login", "", `{"login":"ana","password":"otra"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assertProblem(t, w, problems.CodeInvalidCredentials, testMessages.MessageErrorCredentials)
+
+	// Sin el código del segundo factor se indica que falta
+	w = authRequest(authController.Login, "POST", "/api/auth/login", "", `{"login":"admin","password":"caballo correcto"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assertProblem(t, w, problems.CodeMFARequired, testMessages.MessageErrorMFARequired)
+
+	w = authRequest(authController.Login, "POST", "/api/auth/login", "", `{"login":"ana"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+// Caso de prueba: el token se emite desde JSON o formulario y solo con la concesión password
+func TestIssueToken(t *testing.T) {
+	authFacade := new(MockAuthFacade)
+	authController := NewAuthController(authFacade, testCatalog)
+	tokenIn := input.TokenIn{GrantType: "password", Username: "ana", Password: "caballo correcto"}
+	authFacade.On("IssueToken", tokenIn).Return(output.TokenOut{AccessToken: "abc", TokenType: "Bearer", ExpiresIn: 900}, nil)
+	authFacade.On("IssueToken", mock.Anything).Return(output.TokenOut{}, apperrors.Unauthorized(errors.New("wrong password")))
+
+	w := authRequest(authController.IssueToken, "POST", "/api/auth/token", "", `{"grant_type":"password","username":"ana","password":"caballo correcto"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"access_token":"abc","token_type":"Bearer","expires_in":900}`, w.Body.String())
+
+	req, _ := http.NewRequest("POST", "/api/auth/token", strings.NewReader("grant_type=password&username=ana&password=caballo+correcto"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	authController.IssueToken(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = authRequest(authController.IssueToken, "POST", "/api/auth/token", "", `{"grant_type":"password","username":"ana","password":"otra"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assertProblem(t, w, problems.CodeInvalidCredentials, testMessages.MessageErrorCredentials)
+
+	w = authRequest(authController.IssueToken, "POST", "/api/auth/token", "", `{"grant_type":"client_credentials","username":"ana","password":"x"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	authFacade.AssertNumberOfCalls(t, "IssueToken", 3)
+}
+
+// Caso de prueba: renovar devuelve tokens nuevos y un refresh token inválido responde INVALID_REFRESH_TOKEN
+func TestRefresh(t *testing.T) {
+	authFacade := new(MockAuthFacade)
+	authController := NewAuthController(authFacade, testCatalog)
+	authFacade.On("Refresh", input.RefreshIn{RefreshToken: "r1"}).Return(output.TokenOut{AccessToken: "abc", TokenType: "Bearer", ExpiresIn: 900, RefreshToken: "r2"}, nil)
+	authFacade.On("Refresh", input.RefreshIn{RefreshToken: "r0"}).Return(output.TokenOut{}, apperrors.Unauthorized(errors.New("reused")))
+
+	w := authRequest(authController.Refresh, "POST", "/api/auth/refresh", "", `{"refresh_token":"r1"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"access_token":"abc","token_type":"Bearer","expires_in":900,"refresh_token":"r2"}`, w.Body.String())
+
+	w = authRequest(authController.Refresh, "POST", "/api/auth/refresh", "", `{"refresh_token":"r0"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assertProblem(t, w, problems.CodeInvalidRefresh, testMessages.MessageErrorRefreshToken)
+
+	w = authRequest(authController.Refresh, "POST", "/api/auth/refresh", "", `{}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	authFacade.AssertNumberOfCalls(t, "Refresh", 2)
+}
+
+// Caso de prueba: cerrar sesión responde sin contenido
+func TestLogout(t *testing.T) {
+	authFacade := new(MockAuthFacade)
+	authController := NewAuthController(authFacade, testCatalog)
+	authFacade.On("Logout", input.RefreshIn{RefreshToken: "r1"}).Return(nil)
+
+	w := authRequest(authController.Logout, "POST", "/api/auth/logout", "", `{"refresh_token":"r1"}`)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	authFacade.AssertExpectations(t)
+}
+
+// Caso de prueba: una cuenta bloqueada o un intento demasiado pronto responden 429 con Retry-After
+func TestLoginTooManyRequests(t *testing.T) {
+	authFacade := new(MockAuthFacade)
+	authController := NewAuthController(authFacade, testCatalog)
+	authFacade.On("Login", input.LoginIn{Login: "ana", Password: "x"}).Return(output.LoginOut{},
+		apperrors.TooManyRequests(&lockout.LockedError{Locked: true, RetryAfter: 90*time.Second + time.Millisecond}))
+	authFacade.On("IssueToken", mock.Anything).Return(output.TokenOut{},
+		apperrors.TooManyRequests(&lockout.LockedError{RetryAfter: 2 * time.Second}))
+
+	w := authRequest(authController.Login, "POST", "/api/auth/login", "", `{"login":"ana","password":"x"}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "91", w.Header().Get("Retry-After"))
+	assertProblem(t, w, problems.CodeAccountLocked, testMessages.MessageErrorAccountLocked)
+
+	w = authRequest(authController.IssueToken, "POST", "/api/auth/token", "", `{"grant_type":"password","username":"ana","password":"x"}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assertProblem(t, w, problems.CodeLoginThrottled, testMessages.MessageErrorLoginThrottled)
+}
+
+// Caso de prueba: consultar y levantar el bloqueo de un usuario
+func TestLockout(t *testing.T) {
+	authFacade := new(MockAuthFacade)
+	authController := NewAuthController(authFacade, testCatalog)
+	lockedUntil := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	authFacade.On("GetLockout", uint(1)).Return(output.LockoutOut{Locked: true, LockedUntil: &lockedUntil}, nil)
+	authFacade.On("GetLockout", uint(2)).Return(output.LockoutOut{}, apperrors.NotFound(errors.New("user")))
+	authFacade.On("Unlock", uint(1)).Return(nil)
+
+	w := authRequest(authController.GetLockout, "GET", "/api/users/1/lockout", "1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"locked":true,"locked_until":"2024-05-01T12:00:00Z","failed_attempts":0}`, w.Body.String())
+
+	w = authRequest(authController.GetLockout, "GET", "/api/users/2/lockout", "2", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assertProblem(t, w, problems.CodeUserNotFound, testMessages.MessageErrorUserNotFound)
+
+	w = authRequest(authController.Unlock, "DELETE", "/api/users/1/lockout", "1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = authRequest(authController.Unlock, "DELETE", "/api/users/abc/lockout", "abc", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	authFacade.AssertExpectations(t)
+}
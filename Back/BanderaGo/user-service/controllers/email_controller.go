@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/facade"
+	"application/i18n"
+	"application/lockout"
+	"application/problems"
+	"application/requestctx"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailController struct {
+	responder
+	EmailFacade facade.EmailFacade
+}
+
+func NewEmailController(facade facade.EmailFacade, catalog *i18n.Catalog) *EmailController {
+	return &EmailController{responder: responder{catalog: catalog}, EmailFacade: facade}
+}
+
+// @Summary Request the email verification link
+// @Description Send the user an email with a single-use link to verify their address, in the language of Accept-Language. A new link invalidates the previous ones
+// @Param id path int true "User ID"
+// @Success 202
+// @Failure 400,401,403,404,409,422,500,503,504 {object} output.ProblemOut
+// @Tags Correo
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/email/verification [post]
+func (ec *EmailController) RequestVerification(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		ec.respondInvalidID(c)
+		return
+	}
+
+	if err := ec.EmailFacade.RequestVerification(ec.localized(c), uint(userID)); err != nil {
+		messages := ec.messages(c)
+		if errors.Is(err, apperrors.ErrConflict) {
+			problems.Respond(c, problems.New(http.StatusConflict, problems.CodeEmailVerified, messages.MessageErrorEmailVerified))
+			return
+		}
+		ec.respondError(c, err, problems.CodeEmailVerifyFailed, messages.MessageErrorEmailVerify)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// @Summary Verify an email address
+// @Description Mark the email of the user as verified with the token of the link. Each token can be used once and stops working if the user changes their email
+// @Accept json
+// @Param token body input.VerifyEmailIn true "Token of the link"
+// @Success 204
+// @Failure 400,422,500,503,504 {object} output.ProblemOut
+// @Tags Correo
+// @Router /api/auth/email/verify [post]
+func (ec *EmailController) VerifyEmail(c *gin.Context) {
+	var verifyIn input.VerifyEmailIn
+	if err := c.ShouldBindJSON(&verifyIn); err != nil {
+		ec.respondBindingError(c, err)
+		return
+	}
+
+	if err := ec.EmailFacade.VerifyEmail(c.Request.Context(), verifyIn); err != nil {
+		ec.respondEmailError(c, err, problems.CodeEmailVerifyFailed, ec.messages(c).MessageErrorEmailVerify)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Request a password reset link
+// @Description Send a single-use link to reset the password to the user with that email, in the language of Accept-Language. Unknown emails also respond 202 and the link is sent in the background, so the response does not reveal which emails are registered. Too many requests for an email or from an IP address respond 429 with Retry-After
+// @Accept json
+// @Param email body input.ForgotPasswordIn true "Email of the user"
+// @Success 202
+// @Failure 400,422,429,500,503,504 {object} output.ProblemOut
+// @Tags Contraseñas
+// @Router /api/auth/password/forgot [post]
+func (ec *EmailController) RequestPasswordReset(c *gin.Context) {
+	var forgotIn input.ForgotPasswordIn
+	if err := c.ShouldBindJSON(&forgotIn); err != nil {
+		ec.respondBindingError(c, err)
+		return
+	}
+
+	if err := ec.EmailFacade.RequestPasswordReset(ec.localized(c), forgotIn); err != nil {
+		var locked *lockout.LockedError
+		if errors.Is(err, apperrors.ErrTooManyRequests) && errors.As(err, &locked) {
+			setRetryAfter(c, locked.RetryAfter)
+			problems.Respond(c, problems.New(http.StatusTooManyRequests, problems.CodePasswordResetThrottled, ec.messages(c).MessageErrorResetThrottled))
+			return
+		}
+		ec.respondError(c, err, problems.CodePasswordResetFailed, ec.messages(c).MessageErrorPasswordReset)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// @Summary Reset the password
+// @Description Set a new password with the token of the link. The password must satisfy the password policy; the sessions of the user are revoked and their account is unlocked
+// @Accept json
+// @Param password body input.ResetPasswordIn true "Token of the link and new password"
+// @Success 204
+// @Failure 400,422,500,503,504 {object} output.ProblemOut
+// @Tags Contraseñas
+// @Router /api/auth/password/reset [post]
+func (ec *EmailController) ResetPassword(c *gin.Context) {
+	var resetIn input.ResetPasswordIn
+	if err := c.ShouldBindJSON(&resetIn); err != nil {
+		ec.respondBindingError(c, err)
+		return
+	}
+
+	if err := ec.EmailFacade.ResetPassword(c.Request.Context(), resetIn); err != nil {
+		ec.respondEmailError(c, err, problems.CodePasswordResetFailed, ec.messages(c).MessageErrorPasswordReset)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// localized agrega al contexto el idioma de la respuesta para escribir el correo en el
+// mismo idioma, y el cliente para limitar las solicitudes por IP
+func (ec *EmailController) localized(c *gin.Context) context.Context {
+	return requestctx.WithLanguage(clientContext(c), ec.messages(c).Language)
+}
+
+// respondEmailError responde INVALID_EMAIL_TOKEN cuando el token del enlace no es válido,
+// venció o ya se usó
+func (ec *EmailController) respondEmailError(c *gin.Context, err error, code, message string) {
+	if errors.Is(err, apperrors.ErrUnauthorized) {
+		problems.Respond(c, problems.New(http.StatusBadRequest, problems.CodeInvalidEmailToken, ec.messages(c).MessageErrorEmailToken))
+		return
+	}
+	ec.respondError(c, err, code, message)
+}
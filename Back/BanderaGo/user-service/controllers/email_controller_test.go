@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/lockout"
+	"application/problems"
+	"application/requestctx"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockEmailFacade simula la fachada de los correos; registra el idioma con el que se
+// escribiría el correo
+type MockEmailFacade struct {
+	mock.Mock
+}
+
+func (m *MockEmailFacade) RequestVerification(ctx context.Context, userID uint) error {
+	return m.Called(requestctx.Language(ctx), userID).Error(0)
+}
+
+func (m *MockEmailFacade) VerifyEmail(ctx context.Context, verifyIn input.VerifyEmailIn) error {
+	return m.Called(verifyIn).Error(0)
+}
+
+func (m *MockEmailFacade) RequestPasswordReset(ctx context.Context, forgotIn input.ForgotPasswordIn) error {
+	return m.Called(requestctx.Language(ctx), forgotIn).Error(0)
+}
+
+func (m *MockEmailFacade) ResetPassword(ctx context.Context, resetIn input.ResetPasswordIn) error {
+	return m.Called(resetIn).Error(0)
+}
+
+// Caso de prueba: pedir la verificación responde 202 y un correo ya verificado responde EMAIL_ALREADY_VERIFIED
+func TestRequestVerification(t *testing.T) {
+	emailFacade := new(MockEmailFacade)
+	emailController := NewEmailController(emailFacade, testCatalog)
+	emailFacade.On("RequestVerification", "es", uint(1)).Return(nil)
+	emailFacade.On("RequestVerification", "es", uint(2)).Return(apperrors.Conflict("email_verified", errors.New("verificado")))
+
+	w := authRequest(emailController.RequestVerification, "POST", "/api/users/1/email/verification", "1", "")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	w = authRequest(emailController.RequestVerification, "POST", "/api/users/2/email/verification", "2", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assertProblem(t, w, problems.CodeEmailVerified, testMessages.MessageErrorEmailVerified)
+	emailFacade.AssertExpectations(t)
+}
+
+// Caso de prueba: un token que no es válido responde 400 INVALID_EMAIL_TOKEN
+func TestVerifyEmail(t *testing.T) {
+	emailFacade := new(MockEmailFacade)
+	emailController := NewEmailController(emailFacade, testCatalog)
+	emailFacade.On("VerifyEmail", input.VerifyEmailIn{Token: "bueno"}).Return(nil)
+	emailFacade.On("VerifyEmail", input.VerifyEmailIn{Token: "usado"}).Return(apperrors.Unauthorized(errors.New("usado")))
+
+	w := authRequest(emailController.VerifyEmail, "POST", "/api/auth/email/verify", "", `{"token":"bueno"}`)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = authRequest(emailController.VerifyEmail, "POST", "/api/auth/email/verify", "", `{"token":"usado"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertProblem(t, w, problems.CodeInvalidEmailToken, testMessages.MessageErrorEmailToken)
+
+	w = authRequest(emailController.VerifyEmail, "POST", "/api/auth/email/verify", "", `{}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	emailFacade.AssertNumberOfCalls(t, "VerifyEmail", 2)
+}
+
+// Caso de prueba: pedir el enlace responde 202 o 429 al superar el límite y restablecer con la contraseña débil responde 422
+func TestPasswordReset(t *testing.T) {
+	emailFacade := new(MockEmailFacade)
+	emailController := NewEmailController(emailFacade, testCatalog)
+	resetIn := input.ResetPasswordIn{Token: "bueno", Password: "otra clave segura 123"}
+	emailFacade.On("RequestPasswordReset", "es", input.ForgotPasswordIn{Email: "ana@example.com"}).Return(nil).Once()
+	emailFacade.On("RequestPasswordReset", "es", input.ForgotPasswordIn{Email: "ana@example.com"}).
+		Return(apperrors.TooManyRequests(&lockout.LockedError{Locked: true, RetryAfter: 90*time.Second + time.Millisecond})).Once()
+	emailFacade.On("ResetPassword", resetIn).Return(nil)
+	emailFacade.On("ResetPassword", input.ResetPasswordIn{Token: "bueno", Password: "corta"}).Return(apperrors.Invalid(apperrors.Violations{{Field: "password", Rule: "min"}}))
+
+	w := authRequest(emailController.RequestPasswordReset, "POST", "/api/auth/password/forgot", "", `{"email":"ana@example.com"}`)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	w = authRequest(emailController.RequestPasswordReset, "POST", "/api/auth/password/forgot", "", `{"email":"ana@example.com"}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "91", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), problems.CodePasswordResetThrottled)
+	w = authRequest(emailController.RequestPasswordReset, "POST", "/api/auth/password/forgot", "", `{"email":"ana"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = authRequest(emailController.ResetPassword, "POST", "/api/auth/password/reset", "", `{"token":"bueno","password":"otra clave segura 123"}`)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = authRequest(emailController.ResetPassword, "POST", "/api/auth/password/reset", "", `{"token":"bueno","password":"corta"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"password"`)
+	emailFacade.AssertExpectations(t)
+}
@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"application/apperrors"
+	"application/dtos/output"
+	"application/i18n"
+	"application/problems"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Reportar los errores de binding con el nombre JSON (o del parámetro de consulta)
+	// del campo en lugar del nombre Go
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterTagNameFunc(fieldName)
+	}
+}
+
+func fieldName(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		tag = field.Tag.Get("form")
+	}
+	name := strings.SplitN(tag, ",", 2)[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// responder arma las respuestas de error en el idioma de la solicitud; lo comparten
+// todos los controladores
+type responder struct {
+	catalog *i18n.Catalog
+}
+
+// respondError traduce los errores del dominio a un problema con su código HTTP. Los
+// errores sin categoría responden 500 con el código y mensaje propios de la operación.
+func (r responder) respondError(c *gin.Context, err error, code, message string) {
+	messages := r.messages(c)
+	status := http.StatusInternalServerError
+
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound) && apperrors.FieldOf(err) == "role":
+		status, code, message = http.StatusNotFound, problems.CodeRoleNotFound, messages.MessageErrorRoleNotFound
+	case errors.Is(err, apperrors.ErrNotFound) && apperrors.FieldOf(err) == "api_key":
+		status, code, message = http.StatusNotFound, problems.CodeAPIKeyNotFound, messages.MessageErrorAPIKeyNotFound
+	case errors.Is(err, apperrors.ErrNotFound) && apperrors.FieldOf(err) == "session":
+		status, code, message = http.StatusNotFound, problems.CodeSessionNotFound, messages.MessageErrorSessionNotFound
+	case errors.Is(err, apperrors.ErrNotFound) && apperrors.FieldOf(err) == "mfa":
+		status, code, message = http.StatusNotFound, problems.CodeMFANotFound, messages.MessageErrorMFANotFound
+	case errors.Is(err, apperrors.ErrNotFound) && apperrors.FieldOf(err) == "client_id":
+		status, code, message = http.StatusNotFound, problems.CodeOIDCClientNotFound, messages.MessageErrorOIDCClientNotFound
+	case errors.Is(err, apperrors.ErrNotFound):
+		status, code, message = http.StatusNotFound, problems.CodeUserNotFound, messages.MessageErrorUserNotFound
+	case errors.Is(err, apperrors.ErrConflict):
+		status, code, message = http.StatusConflict, problems.CodeUserConflict, messages.MessageErrorConflict
+	case errors.Is(err, apperrors.ErrValidation):
+		status, code, message = http.StatusUnprocessableEntity, problems.CodeValidationFailed, messages.MessageErrorValidation
+	case errors.Is(err, apperrors.ErrUnauthorized) && apperrors.FieldOf(err) == "otp":
+		status, code, message = http.StatusUnauthorized, problems.CodeMFARequired, messages.MessageErrorMFARequired
+	case errors.Is(err, apperrors.ErrUnauthorized):
+		status, code, message = http.StatusUnauthorized, problems.CodeInvalidCredentials, messages.MessageErrorCredentials
+	case errors.Is(err, apperrors.ErrForbidden):
+		status, code, message = http.StatusForbidden, problems.CodeForbidden, messages.MessageErrorForbidden
+	case errors.Is(err, apperrors.ErrTooManyRequests):
+		status, code, message = http.StatusTooManyRequests, problems.CodeLoginThrottled, messages.MessageErrorLoginThrottled
+	case errors.Is(err, apperrors.ErrPreconditionFailed):
+		status, code, message = http.StatusPreconditionFailed, problems.CodePreconditionFailed, messages.MessageErrorPrecondition
+	case errors.Is(err, apperrors.ErrUnavailable):
+		status, code, message = http.StatusServiceUnavailable, problems.CodeServiceUnavailable, messages.MessageErrorUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		status, code, message = http.StatusGatewayTimeout, problems.CodeRequestTimeout, messages.MessageErrorTimeout
+	}
+
+	problem := problems.New(status, code, message)
+	switch status {
+	case http.StatusUnprocessableEntity:
+		var validationErrs validator.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			problem.Errors = fieldErrors(messages, validationErrs)
+		} else if violations := apperrors.ViolationsOf(err); len(violations) > 0 {
+			problem.Errors = violationErrors(messages, violations)
+		} else if field := apperrors.FieldOf(err); field != "" {
+			problem.Errors = []output.FieldErrorOut{{Field: field, Code: "invalid", Message: messages.Field("invalid", "")}}
+		}
+	case http.StatusConflict:
+		// Nombrar el campo cuyo valor ya está registrado para otro usuario
+		if field := apperrors.FieldOf(err); field != "" {
+			problem.Errors = []output.FieldErrorOut{{Field: field, Code: "unique", Message: messages.Field("unique", "")}}
+		}
+	}
+	problems.Respond(c, problem)
+}
+
+// setRetryAfter indica en Retry-After los segundos que faltan para volver a intentar
+func setRetryAfter(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
+
+// respondBindingError responde a un cuerpo que no pudo decodificarse (400) o que no
+// cumple las reglas de binding (422), detallando los campos involucrados.
+func (r responder) respondBindingError(c *gin.Context, err error) {
+	r.respondInvalidInput(c, err, problems.CodeInvalidRequestBody, r.messages(c).MessageErrorJson)
+}
+
+// respondQueryError es el equivalente de respondBindingError para los parámetros de consulta.
+func (r responder) respondQueryError(c *gin.Context, err error) {
+	r.respondInvalidInput(c, err, problems.CodeInvalidQuery, r.messages(c).MessageErrorQuery)
+}
+
+func (r responder) respondInvalidInput(c *gin.Context, err error, code, detail string) {
+	messages := r.messages(c)
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		problem := problems.New(http.StatusUnprocessableEntity, problems.CodeValidationFailed, messages.MessageErrorValidation)
+		problem.Errors = fieldErrors(messages, validationErrs)
+		problems.Respond(c, problem)
+		return
+	}
+
+	problem := problems.New(http.StatusBadRequest, code, detail)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		problem.Errors = []output.FieldErrorOut{{Field: typeErr.Field, Code: "type", Message: messages.Field("type", "")}}
+	}
+	problems.Respond(c, problem)
+}
+
+func fieldErrors(messages *i18n.Messages, validationErrs validator.ValidationErrors) []output.FieldErrorOut {
+	fieldErrs := make([]output.FieldErrorOut, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fieldErrs = append(fieldErrs, output.FieldErrorOut{
+			Field:   fieldErr.Field(),
+			Code:    fieldErr.Tag(),
+			Message: messages.Field(fieldErr.Tag(), fieldErr.Param()),
+		})
+	}
+	return fieldErrs
+}
+
+// violationErrors traduce las reglas incumplidas que reporta el servicio
+func violationErrors(messages *i18n.Messages, violations apperrors.Violations) []output.FieldErrorOut {
+	fieldErrs := make([]output.FieldErrorOut, 0, len(violations))
+	for _, violation := range violations {
+		fieldErrs = append(fieldErrs, output.FieldErrorOut{
+			Field:   violation.Field,
+			Code:    violation.Rule,
+			Message: messages.Field(violation.Rule, violation.Param),
+		})
+	}
+	return fieldErrs
+}
+
+func (r responder) respondInvalidID(c *gin.Context) {
+	problems.Respond(c, problems.New(http.StatusBadRequest, problems.CodeInvalidUserID, r.messages(c).MessageErrorID))
+}
+
+// messages selecciona el idioma de la respuesta según Accept-Language y lo anuncia
+// en Content-Language.
+func (r responder) messages(c *gin.Context) *i18n.Messages {
+	messages := r.catalog.Match(c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", messages.Language)
+	return messages
+}
+
+func (r responder) respondInvalidVersion(c *gin.Context) {
+	problems.Respond(c, problems.New(http.StatusBadRequest, problems.CodeInvalidVersion, r.messages(c).MessageErrorVersion))
+}
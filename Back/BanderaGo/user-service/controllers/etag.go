@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"application/dtos/input"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// formatETag representa la versión del usuario como una etiqueta fuerte, p. ej. "3"
+func formatETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+func parseETag(tag string) (uint, bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.ParseUint(tag[1:len(tag)-1], 10, 0)
+	if err != nil {
+		return 0, false
+	}
+	return uint(version), true
+}
+
+// entityTags junta los valores del encabezado aunque lleguen en varias líneas
+func entityTags(c *gin.Context, header string) []string {
+	var tags []string
+	for _, value := range c.Request.Header.Values(header) {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// ifMatch convierte If-Match en una precondición para el servicio. If-Match usa la
+// comparación fuerte, por lo que las etiquetas débiles (W/) nunca coinciden.
+func ifMatch(c *gin.Context) *input.Precondition {
+	tags := entityTags(c, "If-Match")
+	if len(tags) == 0 {
+		return nil
+	}
+
+	precondition := &input.Precondition{Versions: []uint{}}
+	for _, tag := range tags {
+		if tag == "*" {
+			precondition.Any = true
+		} else if version, ok := parseETag(tag); ok {
+			precondition.Versions = append(precondition.Versions, version)
+		}
+	}
+	return precondition
+}
+
+// ifNoneMatch indica si el cliente ya tiene la versión actual. If-None-Match usa la
+// comparación débil: W/"3" coincide con "3".
+func ifNoneMatch(c *gin.Context, version uint) bool {
+	for _, tag := range entityTags(c, "If-None-Match") {
+		if tag == "*" {
+			return true
+		}
+		if tagVersion, ok := parseETag(strings.TrimPrefix(tag, "W/")); ok && tagVersion == version {
+			return true
+		}
+	}
+	return false
+}
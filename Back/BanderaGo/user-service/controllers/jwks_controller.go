@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"application/facade"
+	"application/i18n"
+	"application/problems"
+	"application/tokens"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSController struct {
+	responder
+	Tokens     *tokens.Manager
+	OIDCFacade facade.OIDCFacade
+}
+
+func NewJWKSController(manager *tokens.Manager, oidcFacade facade.OIDCFacade, catalog *i18n.Catalog) *JWKSController {
+	return &JWKSController{responder: responder{catalog: catalog}, Tokens: manager, OIDCFacade: oidcFacade}
+}
+
+// @Summary Get the public signing keys
+// @Description List the public keys of the JWKS file so other services can verify the access tokens and the OpenID Connect clients can verify the ID tokens. The HS256 secret is never published
+// @Produce json
+// @Success 200 {object} tokens.JWKS
+// @Failure 500,503,504 {object} output.ProblemOut
+// @Tags Autenticación
+// @Router /.well-known/jwks.json [get]
+func (jc *JWKSController) GetJWKS(c *gin.Context) {
+	oidcKeys, err := jc.OIDCFacade.PublicKeys(c.Request.Context())
+	if err != nil {
+		jc.respondError(c, err, problems.CodeSigningKeyGetFailed, jc.messages(c).MessageErrorGetSigningKeys)
+		return
+	}
+
+	set := jc.Tokens.PublicKeys()
+	set.Keys = append(set.Keys, oidcKeys.Keys...)
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, set)
+}
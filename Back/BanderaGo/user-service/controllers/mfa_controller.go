@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/facade"
+	"application/i18n"
+	"application/problems"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MFAController struct {
+	responder
+	MFAFacade facade.MFAFacade
+}
+
+func NewMFAController(facade facade.MFAFacade, catalog *i18n.Catalog) *MFAController {
+	return &MFAController{responder: responder{catalog: catalog}, MFAFacade: facade}
+}
+
+// @Summary Get the second factor of a user
+// @Description Tell whether the user has a confirmed TOTP second factor and how many unused recovery codes remain. The secret is never returned
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} output.MFAOut
+// @Failure 400,401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags MFA
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/mfa [get]
+func (mc *MFAController) GetMFA(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		mc.respondInvalidID(c)
+		return
+	}
+
+	mfaOut, err := mc.MFAFacade.GetMFA(c.Request.Context(), uint(userID))
+	if err != nil {
+		mc.respondError(c, err, problems.CodeMFAGetFailed, mc.messages(c).MessageErrorGetMFA)
+		return
+	}
+
+	c.JSON(http.StatusOK, mfaOut)
+}
+
+// @Summary Enroll a TOTP second factor
+// @Description Generate a TOTP secret (RFC 6238) for the authenticated user and return it with its otpauth URI and a QR code PNG as a data URI. The second factor stays pending until it is confirmed; enrolling again replaces a pending secret. Only the user can enroll their own second factor
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 201 {object} output.TOTPEnrollmentOut
+// @Failure 400,401,403,404,409,500,503,504 {object} output.ProblemOut
+// @Tags MFA
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/mfa/totp [post]
+func (mc *MFAController) EnrollTOTP(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		mc.respondInvalidID(c)
+		return
+	}
+
+	enrollmentOut, err := mc.MFAFacade.EnrollTOTP(c.Request.Context(), uint(userID))
+	if err != nil {
+		mc.respondMFAError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, enrollmentOut)
+}
+
+// @Summary Confirm a TOTP second factor
+// @Description Activate the pending second factor with a code from the authenticator app and return ten single-use recovery codes. They are shown only once; from then on login requires the otp field
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param code body input.ConfirmTOTPIn true "Code from the authenticator app"
+// @Success 200 {object} output.RecoveryCodesOut
+// @Failure 400,401,403,404,409,422,500,503,504 {object} output.ProblemOut
+// @Tags MFA
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/mfa/totp/confirm [post]
+func (mc *MFAController) ConfirmTOTP(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		mc.respondInvalidID(c)
+		return
+	}
+	var confirmIn input.ConfirmTOTPIn
+	if err := c.ShouldBindJSON(&confirmIn); err != nil {
+		mc.respondBindingError(c, err)
+		return
+	}
+
+	codesOut, err := mc.MFAFacade.ConfirmTOTP(c.Request.Context(), uint(userID), confirmIn)
+	if err != nil {
+		mc.respondMFAError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, codesOut)
+}
+
+// @Summary Regenerate the recovery codes
+// @Description Replace the recovery codes of a user with ten new ones; the previous codes stop working. The user needs a session in which they verified the second factor
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} output.RecoveryCodesOut
+// @Failure 400,401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags MFA
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/mfa/recovery-codes [post]
+func (mc *MFAController) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		mc.respondInvalidID(c)
+		return
+	}
+
+	codesOut, err := mc.MFAFacade.RegenerateRecoveryCodes(c.Request.Context(), uint(userID))
+	if err != nil {
+		mc.respondMFAError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, codesOut)
+}
+
+// @Summary Disable the second factor
+// @Description Remove the second factor of a user, confirmed or pending, with its recovery codes. The user needs a session in which they verified the second factor; with the mfa:manage permission it can be removed for any user, for example after a lost device
+// @Param id path int true "User ID"
+// @Success 204
+// @Failure 400,401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags MFA
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/mfa [delete]
+func (mc *MFAController) DisableMFA(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		mc.respondInvalidID(c)
+		return
+	}
+
+	if err := mc.MFAFacade.DisableMFA(c.Request.Context(), uint(userID)); err != nil {
+		mc.respondMFAError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// respondMFAError responde MFA_CONFLICT cuando el usuario ya tiene un segundo factor confirmado
+func (mc *MFAController) respondMFAError(c *gin.Context, err error) {
+	messages := mc.messages(c)
+	if errors.Is(err, apperrors.ErrConflict) {
+		problems.Respond(c, problems.New(http.StatusConflict, problems.CodeMFAConflict, messages.MessageErrorMFAConflict))
+		return
+	}
+	mc.respondError(c, err, problems.CodeMFAUpdateFailed, messages.MessageErrorUpdateMFA)
+}
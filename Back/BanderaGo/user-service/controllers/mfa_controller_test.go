@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/problems"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockMFAFacade simula la fachada del segundo factor
+type MockMFAFacade struct {
+	mock.Mock
+}
+
+func (m *MockMFAFacade) GetMFA(ctx context.Context, userID uint) (output.MFAOut, error) {
+	args := m.Called(userID)
+	return args.Get(0).(output.MFAOut), args.Error(1)
+}
+
+func (m *MockMFAFacade) EnrollTOTP(ctx context.Context, userID uint) (output.TOTPEnrollmentOut, error) {
+	args := m.Called(userID)
+	return args.Get(0).(output.TOTPEnrollmentOut), args.Error(1)
+}
+
+func (m *MockMFAFacade) ConfirmTOTP(ctx context.Context, userID uint, confirmIn input.ConfirmTOTPIn) (output.RecoveryCodesOut, error) {
+	args := m.Called(userID, confirmIn)
+	return args.Get(0).(output.RecoveryCodesOut), args.Error(1)
+}
+
+func (m *MockMFAFacade) RegenerateRecoveryCodes(ctx context.Context, userID uint) (output.RecoveryCodesOut, error) {
+	args := m.Called(userID)
+	return args.Get(0).(output.RecoveryCodesOut), args.Error(1)
+}
+
+func (m *MockMFAFacade) DisableMFA(ctx context.Context, userID uint) error {
+	return m.Called(userID).Error(0)
+}
+
+// Caso de prueba: inscribir devuelve el secreto y un segundo factor ya confirmado responde MFA_CONFLICT
+func TestEnrollTOTP(t *testing.T) {
+	mfaFacade := new(MockMFAFacade)
+	mfaController := NewMFAController(mfaFacade, testCatalog)
+	mfaFacade.On("EnrollTOTP", uint(1)).Return(output.TOTPEnrollmentOut{Secret: "JBSWY3DPEHPK3PXP", OTPAuthURI: "otpauth://totp/x", QRCode: "data:image/png;base64,"}, nil)
+	mfaFacade.On("EnrollTOTP", uint(2)).Return(output.TOTPEnrollmentOut{}, apperrors.Conflict("mfa", errors.New("confirmado")))
+
+	w := authRequest(mfaController.EnrollTOTP, "POST", "/api/users/1/mfa/totp", "1", "")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"secret":"JBSWY3DPEHPK3PXP","otpauth_uri":"otpauth://totp/x","qr_code":"data:image/png;base64,"}`, w.Body.String())
+
+	w = authRequest(mfaController.EnrollTOTP, "POST", "/api/users/2/mfa/totp", "2", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assertProblem(t, w, problems.CodeMFAConflict, testMessages.MessageErrorMFAConflict)
+}
+
+// Caso de prueba: confirmar con un código incorrecto responde 422 con el campo code
+func TestConfirmTOTP(t *testing.T) {
+	mfaFacade := new(MockMFAFacade)
+	mfaController := NewMFAController(mfaFacade, testCatalog)
+	mfaFacade.On("ConfirmTOTP", uint(1), input.ConfirmTOTPIn{Code: "123456"}).Return(output.RecoveryCodesOut{RecoveryCodes: []string{"abcd-efgh"}}, nil)
+	mfaFacade.On("ConfirmTOTP", uint(1), input.ConfirmTOTPIn{Code: "000000"}).Return(output.RecoveryCodesOut{}, apperrors.Invalid(apperrors.Violations{{Field: "code", Rule: "totp"}}))
+
+	w := authRequest(mfaController.ConfirmTOTP, "POST", "/api/users/1/mfa/totp/confirm", "1", `{"code":"123456"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"recovery_codes":["abcd-efgh"]}`, w.Body.String())
+
+	w = authRequest(mfaController.ConfirmTOTP, "POST", "/api/users/1/mfa/totp/confirm", "1", `{"code":"000000"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"code","code":"totp"`)
+
+	w = authRequest(mfaController.ConfirmTOTP, "POST", "/api/users/1/mfa/totp/confirm", "1", `{}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	mfaFacade.AssertNumberOfCalls(t, "ConfirmTOTP", 2)
+}
+
+// Caso de prueba: consultar o desactivar un segundo factor inexistente responde MFA_NOT_FOUND
+func TestGetAndDisableMFA(t *testing.T) {
+	mfaFacade := new(MockMFAFacade)
+	mfaController := NewMFAController(mfaFacade, testCatalog)
+	mfaFacade.On("GetMFA", uint(1)).Return(output.MFAOut{Enabled: true, RecoveryCodesRemaining: 9}, nil)
+	mfaFacade.On("DisableMFA", uint(1)).Return(nil)
+	mfaFacade.On("DisableMFA", uint(2)).Return(apperrors.NotFoundField("mfa", errors.New("no existe")))
+	mfaFacade.On("RegenerateRecoveryCodes", uint(1)).Return(output.RecoveryCodesOut{}, apperrors.Forbidden(errors.New("sin segundo factor")))
+
+	w := authRequest(mfaController.GetMFA, "GET", "/api/users/1/mfa", "1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"enabled":true,"recovery_codes_remaining":9}`, w.Body.String())
+
+	w = authRequest(mfaController.DisableMFA, "DELETE", "/api/users/1/mfa", "1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = authRequest(mfaController.DisableMFA, "DELETE", "/api/users/2/mfa", "2", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assertProblem(t, w, problems.CodeMFANotFound, testMessages.MessageErrorMFANotFound)
+
+	w = authRequest(mfaController.RegenerateRecoveryCodes, "POST", "/api/users/1/mfa/recovery-codes", "1", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assertProblem(t, w, problems.CodeForbidden, testMessages.MessageErrorForbidden)
+}
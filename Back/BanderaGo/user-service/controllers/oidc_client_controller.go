@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/facade"
+	"application/i18n"
+	"application/problems"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCClientController struct {
+	responder
+	OIDCClientFacade facade.OIDCClientFacade
+}
+
+func NewOIDCClientController(facade facade.OIDCClientFacade, catalog *i18n.Catalog) *OIDCClientController {
+	return &OIDCClientController{responder: responder{catalog: catalog}, OIDCClientFacade: facade}
+}
+
+// @Summary List OpenID Connect clients
+// @Description List the applications registered to sign in with the OpenID Connect provider, oldest first
+// @Produce json
+// @Success 200 {array} output.OIDCClientOut
+// @Failure 401,403,500,503,504 {object} output.ProblemOut
+// @Tags OpenID Connect
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/oidc/clients [get]
+func (oc *OIDCClientController) GetClients(c *gin.Context) {
+	clientsOut, err := oc.OIDCClientFacade.GetClients(c.Request.Context())
+	if err != nil {
+		oc.respondError(c, err, problems.CodeOIDCClientGetFailed, oc.messages(c).MessageErrorGetOIDCClients)
+		return
+	}
+
+	c.JSON(http.StatusOK, clientsOut)
+}
+
+// @Summary Get an OpenID Connect client
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {object} output.OIDCClientOut
+// @Failure 401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags OpenID Connect
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/oidc/clients/{id} [get]
+func (oc *OIDCClientController) GetClient(c *gin.Context) {
+	clientOut, err := oc.OIDCClientFacade.GetClient(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		oc.respondError(c, err, problems.CodeOIDCClientGetFailed, oc.messages(c).MessageErrorGetOIDCClients)
+		return
+	}
+
+	c.JSON(http.StatusOK, clientOut)
+}
+
+// @Summary Register an OpenID Connect client
+// @Description Register an application with its redirect URIs, which must be https or http on localhost. Confidential clients receive a secret that is only returned in this response; public clients (SPAs, mobile apps) have no secret and rely on PKCE
+// @Accept json
+// @Produce json
+// @Param client body input.OIDCClientIn true "Name, redirect URIs and whether the client is public"
+// @Success 201 {object} output.OIDCClientCreatedOut
+// @Failure 400,401,403,422,500,503,504 {object} output.ProblemOut
+// @Tags OpenID Connect
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/oidc/clients [post]
+func (oc *OIDCClientController) CreateClient(c *gin.Context) {
+	var clientIn input.OIDCClientIn
+	if err := c.ShouldBindJSON(&clientIn); err != nil {
+		oc.respondBindingError(c, err)
+		return
+	}
+
+	createdOut, err := oc.OIDCClientFacade.CreateClient(c.Request.Context(), clientIn)
+	if err != nil {
+		oc.respondError(c, err, problems.CodeOIDCClientUpdateFailed, oc.messages(c).MessageErrorUpdateOIDCClient)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, createdOut)
+}
+
+// @Summary Update an OpenID Connect client
+// @Description Replace the name and redirect URIs of a client. Authorization codes already issued keep the redirect URI they were issued for
+// @Accept json
+// @Produce json
+// @Param id path string true "Client ID"
+// @Param client body input.UpdateOIDCClientIn true "Name and redirect URIs"
+// @Success 200 {object} output.OIDCClientOut
+// @Failure 400,401,403,404,422,500,503,504 {object} output.ProblemOut
+// @Tags OpenID Connect
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/oidc/clients/{id} [put]
+func (oc *OIDCClientController) UpdateClient(c *gin.Context) {
+	var clientIn input.UpdateOIDCClientIn
+	if err := c.ShouldBindJSON(&clientIn); err != nil {
+		oc.respondBindingError(c, err)
+		return
+	}
+
+	clientOut, err := oc.OIDCClientFacade.UpdateClient(c.Request.Context(), c.Param("id"), clientIn)
+	if err != nil {
+		oc.respondError(c, err, problems.CodeOIDCClientUpdateFailed, oc.messages(c).MessageErrorUpdateOIDCClient)
+		return
+	}
+
+	c.JSON(http.StatusOK, clientOut)
+}
+
+// @Summary Rotate the secret of an OpenID Connect client
+// @Description Replace the secret of a confidential client; the previous one stops working immediately. Public clients have no secret
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {object} output.OIDCClientCreatedOut
+// @Failure 401,403,404,409,500,503,504 {object} output.ProblemOut
+// @Tags OpenID Connect
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/oidc/clients/{id}/secret [post]
+func (oc *OIDCClientController) RotateClientSecret(c *gin.Context) {
+	rotatedOut, err := oc.OIDCClientFacade.RotateClientSecret(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		messages := oc.messages(c)
+		if errors.Is(err, apperrors.ErrConflict) {
+			problems.Respond(c, problems.New(http.StatusConflict, problems.CodeOIDCPublicClient, messages.MessageErrorOIDCPublicClient))
+			return
+		}
+		oc.respondError(c, err, problems.CodeOIDCClientUpdateFailed, messages.MessageErrorUpdateOIDCClient)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, rotatedOut)
+}
+
+// @Summary Delete an OpenID Connect client
+// @Description Delete a client and its pending authorization codes. The access tokens it received stop working at the userinfo endpoint
+// @Param id path string true "Client ID"
+// @Success 204
+// @Failure 401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags OpenID Connect
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/oidc/clients/{id} [delete]
+func (oc *OIDCClientController) DeleteClient(c *gin.Context) {
+	if err := oc.OIDCClientFacade.DeleteClient(c.Request.Context(), c.Param("id")); err != nil {
+		oc.respondError(c, err, problems.CodeOIDCClientUpdateFailed, oc.messages(c).MessageErrorUpdateOIDCClient)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/problems"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOIDCClientFacade struct {
+	mock.Mock
+}
+
+func (m *MockOIDCClientFacade) GetClients(ctx context.Context) ([]output.OIDCClientOut, error) {
+	args := m.Called()
+	return args.Get(0).([]output.OIDCClientOut), args.Error(1)
+}
+
+func (m *MockOIDCClientFacade) GetClient(ctx context.Context, id string) (output.OIDCClientOut, error) {
+	args := m.Called(id)
+	return args.Get(0).(output.OIDCClientOut), args.Error(1)
+}
+
+func (m *MockOIDCClientFacade) CreateClient(ctx context.Context, clientIn input.OIDCClientIn) (output.OIDCClientCreatedOut, error) {
+	args := m.Called(clientIn)
+	return args.Get(0).(output.OIDCClientCreatedOut), args.Error(1)
+}
+
+func (m *MockOIDCClientFacade) UpdateClient(ctx context.Context, id string, clientIn input.UpdateOIDCClientIn) (output.OIDCClientOut, error) {
+	args := m.Called(id, clientIn)
+	return args.Get(0).(output.OIDCClientOut), args.Error(1)
+}
+
+func (m *MockOIDCClientFacade) RotateClientSecret(ctx context.Context, id string) (output.OIDCClientCreatedOut, error) {
+	args := m.Called(id)
+	return args.Get(0).(output.OIDCClientCreatedOut), args.Error(1)
+}
+
+func (m *MockOIDCClientFacade) DeleteClient(ctx context.Context, id string) error {
+	return m.Called(id).Error(0)
+}
+
+// Caso de prueba: registrar un cliente devuelve su secreto una sola vez y uno inexistente responde OIDC_CLIENT_NOT_FOUND
+func TestCreateAndGetOIDCClient(t *testing.T) {
+	clientFacade := new(MockOIDCClientFacade)
+	clientController := NewOIDCClientController(clientFacade, testCatalog)
+	clientIn := input.OIDCClientIn{Name: "Portal", RedirectURIs: []string{"https://portal.example.com/cb"}}
+	clientFacade.On("CreateClient", clientIn).Return(output.OIDCClientCreatedOut{OIDCClientOut: output.OIDCClientOut{ClientID: "portal"}, ClientSecret: "secreto"}, nil)
+	clientFacade.On("GetClient", "otro").Return(output.OIDCClientOut{}, apperrors.NotFoundField("client_id", errors.New("no existe")))
+
+	w := authRequest(clientController.CreateClient, "POST", "/api/oidc/clients", "", `{"name":"Portal","redirect_uris":["https://portal.example.com/cb"]}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Contains(t, w.Body.String(), `"client_secret":"secreto"`)
+
+	w = authRequest(clientController.CreateClient, "POST", "/api/oidc/clients", "", `{"name":"Portal"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = authRequest(clientController.GetClient, "GET", "/api/oidc/clients/otro", "otro", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assertProblem(t, w, problems.CodeOIDCClientNotFound, testMessages.MessageErrorOIDCClientNotFound)
+	clientFacade.AssertExpectations(t)
+}
+
+// Caso de prueba: rotar el secreto de un cliente público responde 409 OIDC_PUBLIC_CLIENT
+func TestRotateOIDCClientSecret(t *testing.T) {
+	clientFacade := new(MockOIDCClientFacade)
+	clientController := NewOIDCClientController(clientFacade, testCatalog)
+	clientFacade.On("RotateClientSecret", "portal").Return(output.OIDCClientCreatedOut{ClientSecret: "nuevo"}, nil)
+	clientFacade.On("RotateClientSecret", "spa").Return(output.OIDCClientCreatedOut{}, apperrors.Conflict("public", errors.New("público")))
+
+	w := authRequest(clientController.RotateClientSecret, "POST", "/api/oidc/clients/portal/secret", "portal", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"client_secret":"nuevo"`)
+
+	w = authRequest(clientController.RotateClientSecret, "POST", "/api/oidc/clients/spa/secret", "spa", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assertProblem(t, w, problems.CodeOIDCPublicClient, testMessages.MessageErrorOIDCPublicClient)
+	clientFacade.AssertExpectations(t)
+}
@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/facade"
+	"application/i18n"
+	"application/middlewares"
+	"application/oidc"
+	"application/problems"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type OIDCController struct {
+	responder
+	OIDCFacade facade.OIDCFacade
+}
+
+func NewOIDCController(facade facade.OIDCFacade, catalog *i18n.Catalog) *OIDCController {
+	return &OIDCController{responder: responder{catalog: catalog}, OIDCFacade: facade}
+}
+
+// @Summary Get the OpenID Connect discovery document
+// @Description Describe the endpoints, scopes and algorithms of the OpenID Connect provider (OpenID Connect Discovery 1.0)
+// @Produce json
+// @Success 200 {object} output.DiscoveryOut
+// @Tags OpenID Connect
+// @Router /.well-known/openid-configuration [get]
+func (oc *OIDCController) Discovery(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, oc.OIDCFacade.Discovery())
+}
+
+// @Summary Authorize a client
+// @Description Start the authorization code flow with PKCE (S256). If the user has a session the browser is redirected to the client with the code; otherwise it is redirected to the login page, which returns to this URL after signing in. Errors of a registered client are reported in its redirect URI
+// @Param response_type query string true "Must be code"
+// @Param client_id query string true "Client ID"
+// @Param redirect_uri query string true "Registered redirect URI"
+// @Param scope query string true "Must include openid"
+// @Param state query string false "Opaque value returned to the client"
+// @Param nonce query string false "Value copied into the ID token"
+// @Param code_challenge query string true "PKCE challenge"
+// @Param code_challenge_method query string true "Must be S256"
+// @Param prompt query string false "none to fail with login_required instead of asking to sign in"
+// @Success 302
+// @Failure 400,500,503,504 {object} output.ProblemOut
+// @Tags OpenID Connect
+// @Router /oauth2/authorize [get]
+func (oc *OIDCController) Authorize(c *gin.Context) {
+	redirectTo, ok := oc.authorize(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, redirectTo)
+}
+
+// @Summary Authorize a client from the login page
+// @Description Same as GET /oauth2/authorize but returns the URL the browser must open instead of redirecting, so a login page that keeps the access token in memory can finish the flow with its Bearer token
+// @Accept json
+// @Produce json
+// @Param request body input.AuthorizeIn true "Parameters of the authorization request"
+// @Success 200 {object} output.AuthorizeOut
+// @Failure 400,500,503,504 {object} output.ProblemOut
+// @Tags OpenID Connect
+// @Security BearerAuth
+// @Router /oauth2/authorize [post]
+func (oc *OIDCController) AuthorizeJSON(c *gin.Context) {
+	redirectTo, ok := oc.authorize(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, output.AuthorizeOut{RedirectTo: redirectTo})
+}
+
+// authorize responde INVALID_AUTHORIZATION_REQUEST cuando el cliente no existe o la URI
+// de redirección no es suya, porque no hay a dónde redirigir el error
+func (oc *OIDCController) authorize(c *gin.Context) (string, bool) {
+	var authorizeIn input.AuthorizeIn
+	if err := c.ShouldBind(&authorizeIn); err != nil {
+		oc.respondBindingError(c, err)
+		return "", false
+	}
+
+	redirectTo, err := oc.OIDCFacade.Authorize(c.Request.Context(), authorizeIn)
+	if err != nil {
+		messages := oc.messages(c)
+		if errors.Is(err, apperrors.ErrNotFound) || errors.Is(err, apperrors.ErrValidation) {
+			problems.Respond(c, problems.New(http.StatusBadRequest, problems.CodeInvalidAuthorizationRequest, messages.MessageErrorAuthorizeRequest))
+			return "", false
+		}
+		oc.respondError(c, err, problems.CodeAuthorizeFailed, messages.MessageErrorAuthorize)
+		return "", false
+	}
+	return redirectTo, true
+}
+
+// @Summary Exchange an authorization code for tokens
+// @Description Exchange the code and its PKCE verifier for an access token and an ID token signed with RS256. Confidential clients authenticate with HTTP Basic or client_secret in the form; public clients only send client_id. Errors follow RFC 6749, section 5.2
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "Must be authorization_code"
+// @Param code formData string true "Authorization code"
+// @Param redirect_uri formData string true "Redirect URI of the authorization request"
+// @Param client_id formData string false "Client ID, if not sent with Basic"
+// @Param client_secret formData string false "Client secret, if not sent with Basic"
+// @Param code_verifier formData string true "PKCE verifier"
+// @Success 200 {object} output.OIDCTokenOut
+// @Failure 400,401 {object} output.OAuthErrorOut
+// @Failure 500,503,504 {object} output.ProblemOut
+// @Tags OpenID Connect
+// @Router /oauth2/token [post]
+func (oc *OIDCController) Token(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var tokenIn input.TokenRequestIn
+	if err := c.ShouldBindWith(&tokenIn, binding.Form); err != nil {
+		c.JSON(http.StatusBadRequest, output.OAuthErrorOut{Error: oidc.ErrorInvalidRequest, ErrorDescription: "the request must be a form"})
+		return
+	}
+	// Las credenciales de Basic van codificadas como formulario (RFC 6749, sección 2.3.1)
+	basic := false
+	if username, password, ok := c.Request.BasicAuth(); ok {
+		clientID, idErr := url.QueryUnescape(username)
+		secret, secretErr := url.QueryUnescape(password)
+		if idErr != nil || secretErr != nil {
+			c.JSON(http.StatusBadRequest, output.OAuthErrorOut{Error: oidc.ErrorInvalidRequest, ErrorDescription: "malformed Basic credentials"})
+			return
+		}
+		tokenIn.ClientID, tokenIn.ClientSecret, basic = clientID, secret, true
+	}
+
+	tokenOut, err := oc.OIDCFacade.Token(c.Request.Context(), tokenIn)
+	if err != nil {
+		var oauthErr *oidc.Error
+		if errors.As(err, &oauthErr) {
+			status := http.StatusBadRequest
+			if oauthErr.Code == oidc.ErrorInvalidClient && basic {
+				status = http.StatusUnauthorized
+				c.Header("WWW-Authenticate", `Basic realm="`+middlewares.AuthRealm+`"`)
+			}
+			c.JSON(status, output.OAuthErrorOut{Error: oauthErr.Code, ErrorDescription: oauthErr.Description})
+			return
+		}
+		oc.respondError(c, err, problems.CodeAuthorizeFailed, oc.messages(c).MessageErrorAuthorize)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenOut)
+}
+
+// @Summary Get the claims of the signed-in user
+// @Description Return the claims of the user of an access token issued by the token endpoint, according to its scopes. The access tokens of the API are not accepted
+// @Produce json
+// @Success 200 {object} output.UserInfoOut
+// @Failure 401 {object} output.OAuthErrorOut
+// @Failure 500,503,504 {object} output.ProblemOut
+// @Tags OpenID Connect
+// @Security BearerAuth
+// @Router /oauth2/userinfo [get]
+// @Router /oauth2/userinfo [post]
+func (oc *OIDCController) UserInfo(c *gin.Context) {
+	scheme, raw, _ := strings.Cut(strings.TrimSpace(c.GetHeader("Authorization")), " ")
+	if !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(raw) == "" {
+		c.Header("WWW-Authenticate", `Bearer realm="`+middlewares.AuthRealm+`"`)
+		c.JSON(http.StatusUnauthorized, output.OAuthErrorOut{Error: oidc.ErrorInvalidRequest, ErrorDescription: "an access token is required"})
+		return
+	}
+
+	userInfo, err := oc.OIDCFacade.UserInfo(c.Request.Context(), strings.TrimSpace(raw))
+	if err != nil {
+		var oauthErr *oidc.Error
+		if errors.As(err, &oauthErr) {
+			c.Header("WWW-Authenticate", `Bearer realm="`+middlewares.AuthRealm+`", error="`+oauthErr.Code+`"`)
+			c.JSON(http.StatusUnauthorized, output.OAuthErrorOut{Error: oauthErr.Code, ErrorDescription: oauthErr.Description})
+			return
+		}
+		oc.respondError(c, err, problems.CodeAuthorizeFailed, oc.messages(c).MessageErrorAuthorize)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, userInfo)
+}
+
+// @Summary List the OpenID Connect signing keys
+// @Description List the RS256 keys that sign the ID tokens, newest first. Only the active key signs; retired keys stay published until the tokens they signed expire
+// @Produce json
+// @Success 200 {array} output.SigningKeyOut
+// @Failure 401,403,500,503,504 {object} output.ProblemOut
+// @Tags OpenID Connect
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/oidc/keys [get]
+func (oc *OIDCController) GetSigningKeys(c *gin.Context) {
+	keysOut, err := oc.OIDCFacade.GetSigningKeys(c.Request.Context())
+	if err != nil {
+		oc.respondError(c, err, problems.CodeSigningKeyGetFailed, oc.messages(c).MessageErrorGetSigningKeys)
+		return
+	}
+
+	c.JSON(http.StatusOK, keysOut)
+}
+
+// @Summary Rotate the OpenID Connect signing key
+// @Description Generate a new signing key and retire the active one, which stays in the JWKS until the tokens it signed expire
+// @Produce json
+// @Success 201 {object} output.SigningKeyOut
+// @Failure 401,403,500,503,504 {object} output.ProblemOut
+// @Tags OpenID Connect
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/oidc/keys/rotate [post]
+func (oc *OIDCController) RotateSigningKey(c *gin.Context) {
+	keyOut, err := oc.OIDCFacade.RotateSigningKey(c.Request.Context())
+	if err != nil {
+		oc.respondError(c, err, problems.CodeSigningKeyRotateFailed, oc.messages(c).MessageErrorRotateSigningKey)
+		return
+	}
+
+	c.JSON(http.StatusCreated, keyOut)
+}
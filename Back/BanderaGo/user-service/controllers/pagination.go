@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"application/dtos/output"
+	"net/url"
+	"strconv"
+)
+
+// pageLinks arma los enlaces de navegación conservando los filtros y el ordenamiento
+// de la solicitud. En paginación por número se usa page; por cursor, cursor.
+func pageLinks(requestURL *url.URL, page output.GetUsersPageOut) output.PageLinksOut {
+	links := output.PageLinksOut{Self: requestURL.RequestURI()}
+
+	linkWith := func(key, value string) string {
+		query := requestURL.Query()
+		query.Del("page")
+		query.Del("cursor")
+		query.Set(key, value)
+
+		link := *requestURL
+		link.RawQuery = query.Encode()
+		return link.RequestURI()
+	}
+
+	if page.Page > 0 {
+		if int64(page.Page)*int64(page.PageSize) < page.Total {
+			links.Next = linkWith("page", strconv.Itoa(page.Page+1))
+		}
+		if page.Page > 1 {
+			links.Prev = linkWith("page", strconv.Itoa(page.Page-1))
+		}
+		return links
+	}
+
+	if page.NextCursor != "" {
+		links.Next = linkWith("cursor", page.NextCursor)
+	}
+	if page.PrevCursor != "" {
+		links.Prev = linkWith("cursor", page.PrevCursor)
+	}
+	return links
+}
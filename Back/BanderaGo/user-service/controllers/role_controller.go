@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/facade"
+	"application/i18n"
+	"application/problems"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleController struct {
+	responder
+	RoleFacade facade.RoleFacade
+}
+
+func NewRoleController(facade facade.RoleFacade, catalog *i18n.Catalog) *RoleController {
+	return &RoleController{responder: responder{catalog: catalog}, RoleFacade: facade}
+}
+
+// @Summary List roles
+// @Description List the roles with their permissions, ordered by name
+// @Produce json
+// @Success 200 {array} output.RoleOut
+// @Failure 401,403,500,503,504 {object} output.ProblemOut
+// @Tags Roles
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/roles [get]
+func (rc *RoleController) GetRoles(c *gin.Context) {
+	rolesOut, err := rc.RoleFacade.GetRoles(c.Request.Context())
+	if err != nil {
+		rc.respondError(c, err, problems.CodeRoleGetFailed, rc.messages(c).MessageErrorGetRoles)
+		return
+	}
+
+	c.JSON(http.StatusOK, rolesOut)
+}
+
+// @Summary Get a role
+// @Produce json
+// @Param name path string true "Role name"
+// @Success 200 {object} output.RoleOut
+// @Failure 401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags Roles
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/roles/{name} [get]
+func (rc *RoleController) GetRole(c *gin.Context) {
+	roleOut, err := rc.RoleFacade.GetRole(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		rc.respondError(c, err, problems.CodeRoleGetFailed, rc.messages(c).MessageErrorGetRoles)
+		return
+	}
+
+	c.JSON(http.StatusOK, roleOut)
+}
+
+// @Summary Create a role
+// @Description Create a role with a set of permissions. The name is used in the routes, so it only admits lowercase letters, digits, '_' and '-'
+// @Accept json
+// @Produce json
+// @Param role body input.RoleIn true "Role name, description and permissions"
+// @Success 201 {object} output.RoleOut
+// @Failure 400,401,403,409,422,500,503,504 {object} output.ProblemOut
+// @Tags Roles
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/roles [post]
+func (rc *RoleController) CreateRole(c *gin.Context) {
+	var roleIn input.RoleIn
+	if err := c.ShouldBindJSON(&roleIn); err != nil {
+		rc.respondBindingError(c, err)
+		return
+	}
+
+	roleOut, err := rc.RoleFacade.CreateRole(c.Request.Context(), roleIn)
+	if err != nil {
+		rc.respondRoleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, roleOut)
+}
+
+// @Summary Update a role
+// @Description Replace the description and permissions of a role. The change applies to the next request of every user with the role
+// @Accept json
+// @Produce json
+// @Param name path string true "Role name"
+// @Param role body input.UpdateRoleIn true "Description and permissions"
+// @Success 200 {object} output.RoleOut
+// @Failure 400,401,403,404,422,500,503,504 {object} output.ProblemOut
+// @Tags Roles
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/roles/{name} [put]
+func (rc *RoleController) UpdateRole(c *gin.Context) {
+	var roleIn input.UpdateRoleIn
+	if err := c.ShouldBindJSON(&roleIn); err != nil {
+		rc.respondBindingError(c, err)
+		return
+	}
+
+	roleOut, err := rc.RoleFacade.UpdateRole(c.Request.Context(), c.Param("name"), roleIn)
+	if err != nil {
+		rc.respondRoleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, roleOut)
+}
+
+// @Summary Delete a role
+// @Description Delete a role and revoke it from every user that has it
+// @Param name path string true "Role name"
+// @Success 204
+// @Failure 401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags Roles
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/roles/{name} [delete]
+func (rc *RoleController) DeleteRole(c *gin.Context) {
+	if err := rc.RoleFacade.DeleteRole(c.Request.Context(), c.Param("name")); err != nil {
+		rc.respondRoleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get the roles of a user
+// @Description List the roles assigned to a user and the permissions they grant. Users can always read their own roles
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} output.UserRolesOut
+// @Failure 400,401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags Roles
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/roles [get]
+func (rc *RoleController) GetUserRoles(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		rc.respondInvalidID(c)
+		return
+	}
+
+	rolesOut, err := rc.RoleFacade.GetUserRoles(c.Request.Context(), uint(userID))
+	if err != nil {
+		rc.respondError(c, err, problems.CodeRoleGetFailed, rc.messages(c).MessageErrorGetRoles)
+		return
+	}
+
+	c.JSON(http.StatusOK, rolesOut)
+}
+
+// @Summary Assign a role to a user
+// @Description Assign a role to a user. Assigning a role the user already has has no effect. The change is recorded in the audit with the roles operation
+// @Param id path int true "User ID"
+// @Param role path string true "Role name"
+// @Success 204
+// @Failure 400,401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags Roles
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/roles/{role} [put]
+func (rc *RoleController) AssignRole(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		rc.respondInvalidID(c)
+		return
+	}
+
+	if err := rc.RoleFacade.AssignRole(c.Request.Context(), uint(userID), c.Param("role")); err != nil {
+		rc.respondRoleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Revoke a role from a user
+// @Description Revoke a role from a user. Revoking a role the user does not have has no effect
+// @Param id path int true "User ID"
+// @Param role path string true "Role name"
+// @Success 204
+// @Failure 400,401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags Roles
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/roles/{role} [delete]
+func (rc *RoleController) RevokeRole(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		rc.respondInvalidID(c)
+		return
+	}
+
+	if err := rc.RoleFacade.RevokeRole(c.Request.Context(), uint(userID), c.Param("role")); err != nil {
+		rc.respondRoleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// respondRoleError responde los errores de las operaciones que modifican roles; un
+// nombre repetido es un conflicto con otro rol, no con un usuario
+func (rc *RoleController) respondRoleError(c *gin.Context, err error) {
+	messages := rc.messages(c)
+	if errors.Is(err, apperrors.ErrConflict) {
+		problems.Respond(c, problems.New(http.StatusConflict, problems.CodeRoleConflict, messages.MessageErrorRoleConflict))
+		return
+	}
+	rc.respondError(c, err, problems.CodeRoleUpdateFailed, messages.MessageErrorUpdateRole)
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/problems"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRoleFacade simula la fachada de roles
+type MockRoleFacade struct {
+	mock.Mock
+}
+
+func (m *MockRoleFacade) GetRoles(ctx context.Context) ([]output.RoleOut, error) {
+	args := m.Called()
+	return args.Get(0).([]output.RoleOut), args.Error(1)
+}
+
+func (m *MockRoleFacade) GetRole(ctx context.Context, name string) (output.RoleOut, error) {
+	args := m.Called(name)
+	return args.Get(0).(output.RoleOut), args.Error(1)
+}
+
+func (m *MockRoleFacade) CreateRole(ctx context.Context, roleIn input.RoleIn) (output.RoleOut, error) {
+	args := m.Called(roleIn)
+	return args.Get(0).(output.RoleOut), args.Error(1)
+}
+
+func (m *MockRoleFacade) UpdateRole(ctx context.Context, name string, roleIn input.UpdateRoleIn) (output.RoleOut, error) {
+	args := m.Called(name, roleIn)
+	return args.Get(0).(output.RoleOut), args.Error(1)
+}
+
+func (m *MockRoleFacade) DeleteRole(ctx context.Context, name string) error {
+	return m.Called(name).Error(0)
+}
+
+func (m *MockRoleFacade) GetUserRoles(ctx context.Context, userID uint) (output.UserRolesOut, error) {
+	args := m.Called(userID)
+	return args.Get(0).(output.UserRolesOut), args.Error(1)
+}
+
+func (m *MockRoleFacade) AssignRole(ctx context.Context, userID uint, role string) error {
+	return m.Called(userID, role).Error(0)
+}
+
+func (m *MockRoleFacade) RevokeRole(ctx context.Context, userID uint, role string) error {
+	return m.Called(userID, role).Error(0)
+}
+
+// withParams agrega parámetros de ruta a un handler para usarlo con authRequest
+func withParams(handler gin.HandlerFunc, params ...gin.Param) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Params = append(c.Params, params...)
+		handler(c)
+	}
+}
+
+// Caso de prueba: crear un rol responde 201 y un nombre repetido responde ROLE_CONFLICT
+func TestCreateRole(t *testing.T) {
+	roleFacade := new(MockRoleFacade)
+	roleController := NewRoleController(roleFacade, testCatalog)
+	roleIn := input.RoleIn{Name: "support", Permissions: []string{"users:read"}}
+	roleFacade.On("CreateRole", roleIn).Return(output.RoleOut{Name: "support", Permissions: []string{"users:read"}}, nil).Once()
+	roleFacade.On("CreateRole", roleIn).Return(output.RoleOut{}, apperrors.Conflict("name", errors.New("duplicate"))).Once()
+
+	w := authRequest(roleController.CreateRole, "POST", "/api/roles", "", `{"name":"support","permissions":["users:read"]}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"name":"support","permissions":["users:read"]}`, w.Body.String())
+
+	w = authRequest(roleController.CreateRole, "POST", "/api/roles", "", `{"name":"support","permissions":["users:read"]}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assertProblem(t, w, problems.CodeRoleConflict, testMessages.MessageErrorRoleConflict)
+
+	w = authRequest(roleController.CreateRole, "POST", "/api/roles", "", `{"name":"support"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	roleFacade.AssertExpectations(t)
+}
+
+// Caso de prueba: asignar un rol responde sin contenido y un rol inexistente responde ROLE_NOT_FOUND
+func TestAssignRole(t *testing.T) {
+	roleFacade := new(MockRoleFacade)
+	roleController := NewRoleController(roleFacade, testCatalog)
+	roleFacade.On("AssignRole", uint(1), "auditor").Return(nil)
+	roleFacade.On("AssignRole", uint(1), "nope").Return(apperrors.NotFoundField("role", errors.New("role not found")))
+
+	w := authRequest(withParams(roleController.AssignRole, gin.Param{Key: "role", Value: "auditor"}), "PUT", "/api/users/1/roles/auditor", "1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = authRequest(withParams(roleController.AssignRole, gin.Param{Key: "role", Value: "nope"}), "PUT", "/api/users/1/roles/nope", "1", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assertProblem(t, w, problems.CodeRoleNotFound, testMessages.MessageErrorRoleNotFound)
+
+	w = authRequest(withParams(roleController.AssignRole, gin.Param{Key: "role", Value: "auditor"}), "PUT", "/api/users/abc/roles/auditor", "abc", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	roleFacade.AssertExpectations(t)
+}
+
+// Caso de prueba: los roles de otro usuario sin permiso responden 403
+func TestGetUserRolesForbidden(t *testing.T) {
+	roleFacade := new(MockRoleFacade)
+	roleFacade.On("GetUserRoles", uint(2)).Return(output.UserRolesOut{}, apperrors.Forbidden(errors.New("not self")))
+
+	w := authRequest(NewRoleController(roleFacade, testCatalog).GetUserRoles, "GET", "/api/users/2/roles", "2", "")
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assertProblem(t, w, problems.CodeForbidden, testMessages.MessageErrorForbidden)
+}
@@ -0,0 +1,465 @@
+package controllers
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/facade"
+	"application/i18n"
+	"application/scim"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scimAttributes nombra con su atributo de SCIM los campos del usuario que reporta la validación
+var scimAttributes = map[string]string{
+	"name":         "name.givenName",
+	"last_name":    "name.familyName",
+	"email":        "emails",
+	"username":     "userName",
+	"phone":        "phoneNumbers",
+	"display_name": "displayName",
+}
+
+type SCIMController struct {
+	responder
+	SCIMFacade facade.SCIMFacade
+}
+
+func NewSCIMController(facade facade.SCIMFacade, catalog *i18n.Catalog) *SCIMController {
+	return &SCIMController{responder: responder{catalog: catalog}, SCIMFacade: facade}
+}
+
+// @Summary List users (SCIM)
+// @Description List users, including the deleted ones with active set to false, ordered by id. The filter supports comparisons joined with and: userName, emails and phoneNumbers with eq, name.givenName and name.familyName with sw, active with eq and meta.created with gt or lt. Each comparison may appear only once
+// @Produce json
+// @Param filter query string false "SCIM filter, e.g. userName eq \"ana\""
+// @Param startIndex query int false "1-based index of the first result"
+// @Param count query int false "Page size, up to 100"
+// @Success 200 {object} output.SCIMUserListOut
+// @Failure 400,500,503,504 {object} output.SCIMErrorOut
+// @Failure 401,403 {object} output.ProblemOut
+// @Tags SCIM
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /scim/v2/Users [get]
+func (sc *SCIMController) GetUsers(c *gin.Context) {
+	var listIn input.SCIMListIn
+	if err := c.ShouldBindQuery(&listIn); err != nil {
+		sc.respondSCIMProblem(c, scim.NewError(scim.ErrorInvalidValue, err.Error()), "")
+		return
+	}
+
+	listOut, err := sc.SCIMFacade.GetUsers(c.Request.Context(), listIn)
+	if err != nil {
+		sc.respondSCIMProblem(c, err, sc.messages(c).MessageErrorGetUsers)
+		return
+	}
+
+	scimJSON(c, http.StatusOK, listOut)
+}
+
+// @Summary Get a user (SCIM)
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} output.SCIMUserOut
+// @Header 200 {string} ETag "Current version of the user"
+// @Failure 404,500,503,504 {object} output.SCIMErrorOut
+// @Failure 401,403 {object} output.ProblemOut
+// @Tags SCIM
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /scim/v2/Users/{id} [get]
+func (sc *SCIMController) GetUser(c *gin.Context) {
+	userOut, err := sc.SCIMFacade.GetUser(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		sc.respondSCIMProblem(c, err, sc.messages(c).MessageErrorGetUser)
+		return
+	}
+
+	c.Header("ETag", formatETag(userOut.Version))
+	scimJSON(c, http.StatusOK, userOut)
+}
+
+// @Summary Create a user (SCIM)
+// @Description Create a user with the user service. userName, name.givenName and name.familyName are required; only the primary email and phone number are stored. Creating an inactive user requires users:delete
+// @Accept json
+// @Produce json
+// @Param user body input.SCIMUserIn true "SCIM user"
+// @Success 201 {object} output.SCIMUserOut
+// @Header 201 {string} Location "URL of the new user"
+// @Failure 400,409,500,503,504 {object} output.SCIMErrorOut
+// @Failure 401,403 {object} output.ProblemOut
+// @Tags SCIM
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /scim/v2/Users [post]
+func (sc *SCIMController) CreateUser(c *gin.Context) {
+	var userIn input.SCIMUserIn
+	if err := c.ShouldBindJSON(&userIn); err != nil {
+		sc.respondSCIMProblem(c, scim.NewError(scim.ErrorInvalidSyntax, err.Error()), "")
+		return
+	}
+
+	userOut, err := sc.SCIMFacade.CreateUser(c.Request.Context(), userIn)
+	if err != nil {
+		sc.respondSCIMProblem(c, err, sc.messages(c).MessageErrorCreation)
+		return
+	}
+
+	c.Header("Location", userOut.Meta.Location)
+	c.Header("ETag", formatETag(userOut.Version))
+	scimJSON(c, http.StatusCreated, userOut)
+}
+
+// @Summary Replace a user (SCIM)
+// @Description Replace the attributes of a user. Setting active to false deletes the user logically and setting it to true restores it, which requires users:delete; an inactive user cannot be modified without activating it
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param If-Match header string false "ETag obtained from a previous GET"
+// @Param user body input.SCIMUserIn true "SCIM user"
+// @Success 200 {object} output.SCIMUserOut
+// @Header 200 {string} ETag "New version of the user"
+// @Failure 400,404,409,412,500,503,504 {object} output.SCIMErrorOut
+// @Failure 401,403 {object} output.ProblemOut
+// @Tags SCIM
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /scim/v2/Users/{id} [put]
+func (sc *SCIMController) ReplaceUser(c *gin.Context) {
+	var userIn input.SCIMUserIn
+	if err := c.ShouldBindJSON(&userIn); err != nil {
+		sc.respondSCIMProblem(c, scim.NewError(scim.ErrorInvalidSyntax, err.Error()), "")
+		return
+	}
+
+	userOut, err := sc.SCIMFacade.ReplaceUser(c.Request.Context(), c.Param("id"), userIn, ifMatch(c))
+	if err != nil {
+		sc.respondSCIMProblem(c, err, sc.messages(c).MessageErrorUpdateUser)
+		return
+	}
+
+	c.Header("ETag", formatETag(userOut.Version))
+	scimJSON(c, http.StatusOK, userOut)
+}
+
+// @Summary Modify a user (SCIM)
+// @Description Apply add, replace and remove operations. Supported paths are userName, displayName, name and its sub-attributes, emails, phoneNumbers (optionally with a value filter and .value) and active; without a path the value is an object with the attributes to modify
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param If-Match header string false "ETag obtained from a previous GET"
+// @Param operations body input.SCIMPatchIn true "SCIM PatchOp"
+// @Success 200 {object} output.SCIMUserOut
+// @Header 200 {string} ETag "New version of the user"
+// @Failure 400,404,409,412,500,503,504 {object} output.SCIMErrorOut
+// @Failure 401,403 {object} output.ProblemOut
+// @Tags SCIM
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /scim/v2/Users/{id} [patch]
+func (sc *SCIMController) PatchUser(c *gin.Context) {
+	var patchIn input.SCIMPatchIn
+	if err := c.ShouldBindJSON(&patchIn); err != nil {
+		sc.respondSCIMProblem(c, scim.NewError(scim.ErrorInvalidSyntax, err.Error()), "")
+		return
+	}
+
+	userOut, err := sc.SCIMFacade.PatchUser(c.Request.Context(), c.Param("id"), patchIn, ifMatch(c))
+	if err != nil {
+		sc.respondSCIMProblem(c, err, sc.messages(c).MessageErrorUpdateUser)
+		return
+	}
+
+	c.Header("ETag", formatETag(userOut.Version))
+	scimJSON(c, http.StatusOK, userOut)
+}
+
+// @Summary Delete a user (SCIM)
+// @Description Delete the user permanently. To keep the user and its history, set active to false instead. Requires users:purge in addition to users:delete
+// @Param id path string true "User ID"
+// @Success 204
+// @Failure 404,500,503,504 {object} output.SCIMErrorOut
+// @Failure 401,403 {object} output.ProblemOut
+// @Tags SCIM
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /scim/v2/Users/{id} [delete]
+func (sc *SCIMController) DeleteUser(c *gin.Context) {
+	if err := sc.SCIMFacade.DeleteUser(c.Request.Context(), c.Param("id")); err != nil {
+		sc.respondSCIMProblem(c, err, sc.messages(c).MessageErrorDeleteUser)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary List groups (SCIM)
+// @Description List the roles as groups, ordered by name. The only supported filter is displayName eq
+// @Produce json
+// @Param filter query string false "SCIM filter, e.g. displayName eq \"support\""
+// @Param startIndex query int false "1-based index of the first result"
+// @Param count query int false "Page size, up to 100"
+// @Success 200 {object} output.SCIMGroupListOut
+// @Failure 400,500,503,504 {object} output.SCIMErrorOut
+// @Failure 401,403 {object} output.ProblemOut
+// @Tags SCIM
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /scim/v2/Groups [get]
+func (sc *SCIMController) GetGroups(c *gin.Context) {
+	var listIn input.SCIMListIn
+	if err := c.ShouldBindQuery(&listIn); err != nil {
+		sc.respondSCIMProblem(c, scim.NewError(scim.ErrorInvalidValue, err.Error()), "")
+		return
+	}
+
+	listOut, err := sc.SCIMFacade.GetGroups(c.Request.Context(), listIn)
+	if err != nil {
+		sc.respondSCIMProblem(c, err, sc.messages(c).MessageErrorGetRoles)
+		return
+	}
+
+	scimJSON(c, http.StatusOK, listOut)
+}
+
+// @Summary Get a group (SCIM)
+// @Produce json
+// @Param id path string true "Role name"
+// @Success 200 {object} output.SCIMGroupOut
+// @Failure 404,500,503,504 {object} output.SCIMErrorOut
+// @Failure 401,403 {object} output.ProblemOut
+// @Tags SCIM
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /scim/v2/Groups/{id} [get]
+func (sc *SCIMController) GetGroup(c *gin.Context) {
+	groupOut, err := sc.SCIMFacade.GetGroup(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		sc.respondSCIMProblem(c, err, sc.messages(c).MessageErrorGetRoles)
+		return
+	}
+
+	scimJSON(c, http.StatusOK, groupOut)
+}
+
+// @Summary Create a group (SCIM)
+// @Description Create a role without permissions named after displayName and assign it to the members, whose values are user IDs. Permissions are granted with the roles API
+// @Accept json
+// @Produce json
+// @Param group body input.SCIMGroupIn true "SCIM group"
+// @Success 201 {object} output.SCIMGroupOut
+// @Header 201 {string} Location "URL of the new group"
+// @Failure 400,409,500,503,504 {object} output.SCIMErrorOut
+// @Failure 401,403 {object} output.ProblemOut
+// @Tags SCIM
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /scim/v2/Groups [post]
+func (sc *SCIMController) CreateGroup(c *gin.Context) {
+	var groupIn input.SCIMGroupIn
+	if err := c.ShouldBindJSON(&groupIn); err != nil {
+		sc.respondSCIMProblem(c, scim.NewError(scim.ErrorInvalidSyntax, err.Error()), "")
+		return
+	}
+
+	groupOut, err := sc.SCIMFacade.CreateGroup(c.Request.Context(), groupIn)
+	if err != nil {
+		sc.respondSCIMProblem(c, err, sc.messages(c).MessageErrorUpdateRole)
+		return
+	}
+
+	c.Header("Location", groupOut.Meta.Location)
+	scimJSON(c, http.StatusCreated, groupOut)
+}
+
+// @Summary Replace a group (SCIM)
+// @Description Replace the members of a group. The displayName cannot change because it is the name of the role
+// @Accept json
+// @Produce json
+// @Param id path string true "Role name"
+// @Param group body input.SCIMGroupIn true "SCIM group"
+// @Success 200 {object} output.SCIMGroupOut
+// @Failure 400,404,500,503,504 {object} output.SCIMErrorOut
+// @Failure 401,403 {object} output.ProblemOut
+// @Tags SCIM
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /scim/v2/Groups/{id} [put]
+func (sc *SCIMController) ReplaceGroup(c *gin.Context) {
+	var groupIn input.SCIMGroupIn
+	if err := c.ShouldBindJSON(&groupIn); err != nil {
+		sc.respondSCIMProblem(c, scim.NewError(scim.ErrorInvalidSyntax, err.Error()), "")
+		return
+	}
+
+	groupOut, err := sc.SCIMFacade.ReplaceGroup(c.Request.Context(), c.Param("id"), groupIn)
+	if err != nil {
+		sc.respondSCIMProblem(c, err, sc.messages(c).MessageErrorUpdateRole)
+		return
+	}
+
+	scimJSON(c, http.StatusOK, groupOut)
+}
+
+// @Summary Modify a group (SCIM)
+// @Description Add, replace or remove members. A single member is removed with the path members[value eq "id"]
+// @Accept json
+// @Produce json
+// @Param id path string true "Role name"
+// @Param operations body input.SCIMPatchIn true "SCIM PatchOp"
+// @Success 200 {object} output.SCIMGroupOut
+// @Failure 400,404,500,503,504 {object} output.SCIMErrorOut
+// @Failure 401,403 {object} output.ProblemOut
+// @Tags SCIM
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /scim/v2/Groups/{id} [patch]
+func (sc *SCIMController) PatchGroup(c *gin.Context) {
+	var patchIn input.SCIMPatchIn
+	if err := c.ShouldBindJSON(&patchIn); err != nil {
+		sc.respondSCIMProblem(c, scim.NewError(scim.ErrorInvalidSyntax, err.Error()), "")
+		return
+	}
+
+	groupOut, err := sc.SCIMFacade.PatchGroup(c.Request.Context(), c.Param("id"), patchIn)
+	if err != nil {
+		sc.respondSCIMProblem(c, err, sc.messages(c).MessageErrorUpdateRole)
+		return
+	}
+
+	scimJSON(c, http.StatusOK, groupOut)
+}
+
+// @Summary Delete a group (SCIM)
+// @Description Delete the role and remove it from its members
+// @Param id path string true "Role name"
+// @Success 204
+// @Failure 404,500,503,504 {object} output.SCIMErrorOut
+// @Failure 401,403 {object} output.ProblemOut
+// @Tags SCIM
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /scim/v2/Groups/{id} [delete]
+func (sc *SCIMController) DeleteGroup(c *gin.Context) {
+	if err := sc.SCIMFacade.DeleteGroup(c.Request.Context(), c.Param("id")); err != nil {
+		sc.respondSCIMProblem(c, err, sc.messages(c).MessageErrorUpdateRole)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get the SCIM service provider configuration
+// @Description Describe the SCIM features the service supports (RFC 7643, section 5)
+// @Produce json
+// @Success 200 {object} output.SCIMServiceProviderConfigOut
+// @Tags SCIM
+// @Router /scim/v2/ServiceProviderConfig [get]
+func (sc *SCIMController) ServiceProviderConfig(c *gin.Context) {
+	scimJSON(c, http.StatusOK, sc.SCIMFacade.ServiceProviderConfig())
+}
+
+// @Summary List the SCIM resource types
+// @Produce json
+// @Success 200 {object} output.SCIMResourceTypeListOut
+// @Tags SCIM
+// @Router /scim/v2/ResourceTypes [get]
+func (sc *SCIMController) ResourceTypes(c *gin.Context) {
+	scimJSON(c, http.StatusOK, sc.SCIMFacade.ResourceTypes())
+}
+
+// @Summary List the SCIM schemas
+// @Description Describe the attributes of users and groups the service stores
+// @Produce json
+// @Success 200 {object} output.SCIMSchemaListOut
+// @Tags SCIM
+// @Router /scim/v2/Schemas [get]
+func (sc *SCIMController) Schemas(c *gin.Context) {
+	scimJSON(c, http.StatusOK, sc.SCIMFacade.Schemas())
+}
+
+// respondSCIMProblem responde los errores con el formato de SCIM (RFC 7644, sección 3.12)
+// en lugar de problem+json, porque es el que entienden los proveedores de identidad. Los
+// errores sin categoría responden 500 con el mensaje propio de la operación.
+func (sc *SCIMController) respondSCIMProblem(c *gin.Context, err error, message string) {
+	messages := sc.messages(c)
+	status, scimType := http.StatusInternalServerError, ""
+
+	var scimErr *scim.Error
+	switch {
+	case errors.As(err, &scimErr):
+		status, scimType, message = http.StatusBadRequest, scimErr.Type, scimMessage(messages, scimErr.Type)
+	case errors.Is(err, apperrors.ErrNotFound) && apperrors.FieldOf(err) == "role":
+		status, message = http.StatusNotFound, messages.MessageErrorRoleNotFound
+	case errors.Is(err, apperrors.ErrNotFound):
+		status, message = http.StatusNotFound, messages.MessageErrorUserNotFound
+	case errors.Is(err, apperrors.ErrConflict) && apperrors.FieldOf(err) == "name":
+		status, scimType, message = http.StatusConflict, scim.ErrorUniqueness, messages.MessageErrorRoleConflict
+	case errors.Is(err, apperrors.ErrConflict):
+		status, scimType, message = http.StatusConflict, scim.ErrorUniqueness, messages.MessageErrorConflict
+	case errors.Is(err, apperrors.ErrValidation):
+		status, scimType, message = http.StatusBadRequest, scim.ErrorInvalidValue, validationDetail(messages, err)
+	case errors.Is(err, apperrors.ErrForbidden):
+		status, message = http.StatusForbidden, messages.MessageErrorForbidden
+	case errors.Is(err, apperrors.ErrPreconditionFailed):
+		status, message = http.StatusPreconditionFailed, messages.MessageErrorPrecondition
+	case errors.Is(err, apperrors.ErrUnavailable):
+		status, message = http.StatusServiceUnavailable, messages.MessageErrorUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		status, message = http.StatusGatewayTimeout, messages.MessageErrorTimeout
+	}
+
+	c.Abort()
+	scimJSON(c, status, output.SCIMErrorOut{
+		Schemas:  []string{scim.SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   message,
+	})
+}
+
+func scimMessage(messages *i18n.Messages, scimType string) string {
+	switch scimType {
+	case scim.ErrorInvalidFilter:
+		return messages.MessageErrorSCIMFilter
+	case scim.ErrorInvalidPath:
+		return messages.MessageErrorSCIMPath
+	case scim.ErrorInvalidValue:
+		return messages.MessageErrorSCIMValue
+	case scim.ErrorNoTarget:
+		return messages.MessageErrorSCIMNoTarget
+	case scim.ErrorMutability:
+		return messages.MessageErrorSCIMMutability
+	}
+	return messages.MessageErrorSCIMSyntax
+}
+
+// validationDetail agrega al mensaje los atributos que no cumplen las reglas, p. ej.
+// "userName: Debe tener al menos 3 caracteres"
+func validationDetail(messages *i18n.Messages, err error) string {
+	var details []string
+	for _, violation := range apperrors.ViolationsOf(err) {
+		attribute, ok := scimAttributes[violation.Field]
+		if !ok {
+			attribute = violation.Field
+		}
+		details = append(details, attribute+": "+messages.Field(violation.Rule, violation.Param))
+	}
+	if len(details) == 0 {
+		return messages.MessageErrorValidation
+	}
+	return messages.MessageErrorValidation + ". " + strings.Join(details, "; ")
+}
+
+// scimJSON responde con el tipo de contenido de SCIM; c.JSON respeta el que ya está fijado
+func scimJSON(c *gin.Context, status int, body any) {
+	c.Header("Content-Type", scim.MediaType+"; charset=utf-8")
+	c.JSON(status, body)
+}
@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/scim"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSCIMFacade simula la fachada de SCIM
+type MockSCIMFacade struct {
+	mock.Mock
+}
+
+func (m *MockSCIMFacade) GetUsers(ctx context.Context, listIn input.SCIMListIn) (output.SCIMUserListOut, error) {
+	args := m.Called(listIn)
+	return args.Get(0).(output.SCIMUserListOut), args.Error(1)
+}
+
+func (m *MockSCIMFacade) GetUser(ctx context.Context, id string) (output.SCIMUserOut, error) {
+	args := m.Called(id)
+	return args.Get(0).(output.SCIMUserOut), args.Error(1)
+}
+
+func (m *MockSCIMFacade) CreateUser(ctx context.Context, userIn input.SCIMUserIn) (output.SCIMUserOut, error) {
+	args := m.Called(userIn)
+	return args.Get(0).(output.SCIMUserOut), args.Error(1)
+}
+
+func (m *MockSCIMFacade) ReplaceUser(ctx context.Context, id string, userIn input.SCIMUserIn, ifMatch *input.Precondition) (output.SCIMUserOut, error) {
+	args := m.Called(id, userIn, ifMatch)
+	return args.Get(0).(output.SCIMUserOut), args.Error(1)
+}
+
+func (m *MockSCIMFacade) PatchUser(ctx context.Context, id string, patchIn input.SCIMPatchIn, ifMatch *input.Precondition) (output.SCIMUserOut, error) {
+	args := m.Called(id, patchIn, ifMatch)
+	return args.Get(0).(output.SCIMUserOut), args.Error(1)
+}
+
+func (m *MockSCIMFacade) DeleteUser(ctx context.Context, id string) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockSCIMFacade) GetGroups(ctx context.Context, listIn input.SCIMListIn) (output.SCIMGroupListOut, error) {
+	args := m.Called(listIn)
+	return args.Get(0).(output.SCIMGroupListOut), args.Error(1)
+}
+
+func (m *MockSCIMFacade) GetGroup(ctx context.Context, id string) (output.SCIMGroupOut, error) {
+	args := m.Called(id)
+	return args.Get(0).(output.SCIMGroupOut), args.Error(1)
+}
+
+func (m *MockSCIMFacade) CreateGroup(ctx context.Context, groupIn input.SCIMGroupIn) (output.SCIMGroupOut, error) {
+	args := m.Called(groupIn)
+	return args.Get(0).(output.SCIMGroupOut), args.Error(1)
+}
+
+func (m *MockSCIMFacade) ReplaceGroup(ctx context.Context, id string, groupIn input.SCIMGroupIn) (output.SCIMGroupOut, error) {
+	args := m.Called(id, groupIn)
+	return args.Get(0).(output.SCIMGroupOut), args.Error(1)
+}
+
+func (m *MockSCIMFacade) PatchGroup(ctx context.Context, id string, patchIn input.SCIMPatchIn) (output.SCIMGroupOut, error) {
+	args := m.Called(id, patchIn)
+	return args.Get(0).(output.SCIMGroupOut), args.Error(1)
+}
+
+func (m *MockSCIMFacade) DeleteGroup(ctx context.Context, id string) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockSCIMFacade) ServiceProviderConfig() output.SCIMServiceProviderConfigOut {
+	return m.Called().Get(0).(output.SCIMServiceProviderConfigOut)
+}
+
+func (m *MockSCIMFacade) ResourceTypes() output.SCIMResourceTypeListOut {
+	return m.Called().Get(0).(output.SCIMResourceTypeListOut)
+}
+
+func (m *MockSCIMFacade) Schemas() output.SCIMSchemaListOut {
+	return m.Called().Get(0).(output.SCIMSchemaListOut)
+}
+
+func assertSCIMProblem(t *testing.T, w *httptest.ResponseRecorder, scimType, detail string) {
+	t.Helper()
+	assert.Contains(t, w.Header().Get("Content-Type"), scim.MediaType)
+
+	var problem output.SCIMErrorOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, []string{scim.SchemaError}, problem.Schemas)
+	assert.Equal(t, strconv.Itoa(w.Code), problem.Status)
+	assert.Equal(t, scimType, problem.ScimType)
+	assert.Equal(t, detail, problem.Detail)
+}
+
+// Caso de prueba: crear un usuario responde 201 con Location, ETag y el tipo de contenido de SCIM
+func TestSCIMCreateUser(t *testing.T) {
+	scimFacade := new(MockSCIMFacade)
+	scimController := NewSCIMController(scimFacade, testCatalog)
+	userIn := input.SCIMUserIn{UserName: "ana", Name: &input.SCIMNameIn{GivenName: "Ana", FamilyName: "Díaz"}}
+	userOut := output.SCIMUserOut{ID: "1", UserName: "ana", Active: true, Version: 1, Meta: output.SCIMMetaOut{Location: "/scim/v2/Users/1"}}
+	scimFacade.On("CreateUser", userIn).Return(userOut, nil).Once()
+	scimFacade.On("CreateUser", userIn).Return(output.SCIMUserOut{}, apperrors.Conflict("email", errors.New("duplicate"))).Once()
+
+	body := `{"userName":"ana","name":{"givenName":"Ana","familyName":"Díaz"}}`
+	w := authRequest(scimController.CreateUser, "POST", "/scim/v2/Users", "", body)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), scim.MediaType)
+	assert.Equal(t, "/scim/v2/Users/1", w.Header().Get("Location"))
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+
+	w = authRequest(scimController.CreateUser, "POST", "/scim/v2/Users", "", body)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assertSCIMProblem(t, w, scim.ErrorUniqueness, testMessages.MessageErrorConflict)
+
+	w = authRequest(scimController.CreateUser, "POST", "/scim/v2/Users", "", `{"userName":`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertSCIMProblem(t, w, scim.ErrorInvalidSyntax, testMessages.MessageErrorSCIMSyntax)
+	scimFacade.AssertExpectations(t)
+}
+
+// Caso de prueba: los errores del servicio se responden con el formato de error de SCIM
+func TestSCIMPatchUserErrors(t *testing.T) {
+	scimFacade := new(MockSCIMFacade)
+	scimController := NewSCIMController(scimFacade, testCatalog)
+	patchIn := input.SCIMPatchIn{Operations: []input.SCIMOperationIn{{Op: "replace", Path: "userName", Value: json.RawMessage(`"a"`)}}}
+	ifMatch := &input.Precondition{Versions: []uint{2}}
+	violations := apperrors.Violations{{Field: "username", Rule: "min", Param: "3"}}
+
+	for _, tc := range []struct {
+		err      error
+		status   int
+		scimType string
+		detail   string
+	}{
+		{scim.NewError(scim.ErrorInvalidPath, "title"), http.StatusBadRequest, scim.ErrorInvalidPath, testMessages.MessageErrorSCIMPath},
+		{apperrors.Invalid(violations), http.StatusBadRequest, scim.ErrorInvalidValue, testMessages.MessageErrorValidation + ". userName: " + testMessages.Field("min", "3")},
+		{apperrors.PreconditionFailed(errors.New("stale")), http.StatusPreconditionFailed, "", testMessages.MessageErrorPrecondition},
+		{apperrors.NotFound(errors.New("missing")), http.StatusNotFound, "", testMessages.MessageErrorUserNotFound},
+		{errors.New("boom"), http.StatusInternalServerError, "", testMessages.MessageErrorUpdateUser},
+	} {
+		scimFacade.On("PatchUser", "1", patchIn, ifMatch).Return(output.SCIMUserOut{}, tc.err).Once()
+
+		req, _ := http.NewRequest("PATCH", "/scim/v2/Users/1", bytes.NewBufferString(`{"Operations":[{"op":"replace","path":"userName","value":"a"}]}`))
+		req.Header.Set("If-Match", `"2"`)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+		c.Request = req
+		scimController.PatchUser(c)
+
+		assert.Equal(t, tc.status, w.Code, tc.err.Error())
+		assertSCIMProblem(t, w, tc.scimType, tc.detail)
+	}
+	scimFacade.AssertExpectations(t)
+}
+
+// Caso de prueba: un grupo inexistente responde 404 y la configuración del proveedor no requiere identidad
+func TestSCIMGroupsAndDiscovery(t *testing.T) {
+	scimFacade := new(MockSCIMFacade)
+	scimController := NewSCIMController(scimFacade, testCatalog)
+	scimFacade.On("GetGroup", "nope").Return(output.SCIMGroupOut{}, apperrors.NotFoundField("role", errors.New("role not found")))
+	scimFacade.On("ServiceProviderConfig").Return(output.SCIMServiceProviderConfigOut{Patch: output.SCIMSupportedOut{Supported: true}})
+
+	w := authRequest(scimController.GetGroup, "GET", "/scim/v2/Groups/nope", "nope", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assertSCIMProblem(t, w, "", testMessages.MessageErrorRoleNotFound)
+
+	w = authRequest(scimController.GetGroups, "GET", "/scim/v2/Groups?count=muchos", "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertSCIMProblem(t, w, scim.ErrorInvalidValue, testMessages.MessageErrorSCIMValue)
+
+	w = authRequest(scimController.ServiceProviderConfig, "GET", "/scim/v2/ServiceProviderConfig", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), scim.MediaType)
+	assert.Contains(t, w.Body.String(), `"patch":{"supported":true}`)
+	scimFacade.AssertExpectations(t)
+}
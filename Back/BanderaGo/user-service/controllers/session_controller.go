@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"application/facade"
+	"application/i18n"
+	"application/problems"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionController struct {
+	responder
+	SessionFacade facade.SessionFacade
+}
+
+func NewSessionController(facade facade.SessionFacade, catalog *i18n.Catalog) *SessionController {
+	return &SessionController{responder: responder{catalog: catalog}, SessionFacade: facade}
+}
+
+// @Summary List the sessions of a user
+// @Description List the active sessions of a user, most recently used first, with the IP address and user agent that last used them. The session of the current token is marked as current
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} output.SessionOut
+// @Failure 400,401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags Sesiones
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/sessions [get]
+func (sc *SessionController) GetUserSessions(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		sc.respondInvalidID(c)
+		return
+	}
+
+	sessionsOut, err := sc.SessionFacade.GetUserSessions(c.Request.Context(), uint(userID))
+	if err != nil {
+		sc.respondError(c, err, problems.CodeSessionGetFailed, sc.messages(c).MessageErrorGetSessions)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessionsOut)
+}
+
+// @Summary Revoke a session of a user
+// @Description Revoke a session so that its refresh tokens and the access tokens already issued for it can no longer be used. Revoking a revoked session has no effect
+// @Param id path int true "User ID"
+// @Param session path int true "Session ID"
+// @Success 204
+// @Failure 400,401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags Sesiones
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/sessions/{session} [delete]
+func (sc *SessionController) RevokeSession(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		sc.respondInvalidID(c)
+		return
+	}
+	sessionID, err := strconv.ParseUint(c.Param("session"), 10, 64)
+	if err != nil {
+		problems.Respond(c, problems.New(http.StatusBadRequest, problems.CodeInvalidSessionID, sc.messages(c).MessageErrorSessionID))
+		return
+	}
+
+	if err := sc.SessionFacade.RevokeSession(c.Request.Context(), uint(userID), uint(sessionID)); err != nil {
+		sc.respondError(c, err, problems.CodeSessionRevokeFailed, sc.messages(c).MessageErrorRevokeSession)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Revoke all the sessions of a user
+// @Description Revoke every session of a user, for example after a password leak. Their refresh tokens and access tokens stop working
+// @Param id path int true "User ID"
+// @Success 204
+// @Failure 400,401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags Sesiones
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/sessions [delete]
+func (sc *SessionController) RevokeUserSessions(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		sc.respondInvalidID(c)
+		return
+	}
+
+	if err := sc.SessionFacade.RevokeUserSessions(c.Request.Context(), uint(userID)); err != nil {
+		sc.respondError(c, err, problems.CodeSessionRevokeFailed, sc.messages(c).MessageErrorRevokeSession)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
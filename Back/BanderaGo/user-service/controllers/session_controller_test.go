@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"application/apperrors"
+	"application/dtos/output"
+	"application/problems"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSessionFacade simula la fachada de sesiones
+type MockSessionFacade struct {
+	mock.Mock
+}
+
+func (m *MockSessionFacade) GetUserSessions(ctx context.Context, userID uint) ([]output.SessionOut, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]output.SessionOut), args.Error(1)
+}
+
+func (m *MockSessionFacade) RevokeSession(ctx context.Context, userID, sessionID uint) error {
+	return m.Called(userID, sessionID).Error(0)
+}
+
+func (m *MockSessionFacade) RevokeUserSessions(ctx context.Context, userID uint) error {
+	return m.Called(userID).Error(0)
+}
+
+// Caso de prueba: las sesiones se listan con su cliente y se marca la actual
+func TestGetUserSessions(t *testing.T) {
+	sessionFacade := new(MockSessionFacade)
+	sessionController := NewSessionController(sessionFacade, testCatalog)
+	at := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	sessionFacade.On("GetUserSessions", uint(1)).Return([]output.SessionOut{
+		{ID: 3, UserAgent: "Firefox", IP: "10.0.0.1", CreatedAt: at, LastUsedAt: at, ExpiresAt: at.Add(time.Hour), Current: true},
+	}, nil)
+
+	w := authRequest(sessionController.GetUserSessions, "GET", "/api/users/1/sessions", "1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"id":3,"user_agent":"Firefox","ip":"10.0.0.1","created_at":"2024-03-01T00:00:00Z","last_used_at":"2024-03-01T00:00:00Z","expires_at":"2024-03-01T01:00:00Z","current":true}]`, w.Body.String())
+
+	w = authRequest(sessionController.GetUserSessions, "GET", "/api/users/abc/sessions", "abc", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertProblem(t, w, problems.CodeInvalidUserID, testMessages.MessageErrorID)
+	sessionFacade.AssertNumberOfCalls(t, "GetUserSessions", 1)
+}
+
+// Caso de prueba: revocar una sesión inexistente responde SESSION_NOT_FOUND
+func TestRevokeSession(t *testing.T) {
+	sessionFacade := new(MockSessionFacade)
+	sessionController := NewSessionController(sessionFacade, testCatalog)
+	sessionFacade.On("RevokeSession", uint(1), uint(3)).Return(nil)
+	sessionFacade.On("RevokeSession", uint(1), uint(9)).Return(apperrors.NotFoundField("session", errors.New("no existe")))
+	sessionFacade.On("RevokeUserSessions", uint(1)).Return(nil)
+
+	w := authRequest(withParams(sessionController.RevokeSession, gin.Param{Key: "session", Value: "3"}), "DELETE", "/api/users/1/sessions/3", "1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = authRequest(withParams(sessionController.RevokeSession, gin.Param{Key: "session", Value: "9"}), "DELETE", "/api/users/1/sessions/9", "1", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assertProblem(t, w, problems.CodeSessionNotFound, testMessages.MessageErrorSessionNotFound)
+
+	w = authRequest(withParams(sessionController.RevokeSession, gin.Param{Key: "session", Value: "x"}), "DELETE", "/api/users/1/sessions/x", "1", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertProblem(t, w, problems.CodeInvalidSessionID, testMessages.MessageErrorSessionID)
+
+	w = authRequest(sessionController.RevokeUserSessions, "DELETE", "/api/users/1/sessions", "1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	sessionFacade.AssertExpectations(t)
+}
@@ -2,8 +2,12 @@ package controllers
 
 import (
 	"application/dtos/input"
+	"application/dtos/output"
 	"application/facade"
-	"application/utils"
+	"application/i18n"
+	"application/problems"
+	"encoding/json"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -11,12 +15,12 @@ import (
 )
 
 type UserController struct {
+	responder
 	UserFacade facade.UserFacade
-	constants  utils.Constants
 }
 
-func NewUserController(facade facade.UserFacade) *UserController {
-	return &UserController{UserFacade: facade, constants: utils.DefaultConstants}
+func NewUserController(facade facade.UserFacade, catalog *i18n.Catalog) *UserController {
+	return &UserController{responder: responder{catalog: catalog}, UserFacade: facade}
 }
 
 // @Summary Create a user
@@ -25,19 +29,22 @@ func NewUserController(facade facade.UserFacade) *UserController {
 // @Produce json
 // @Param user body input.CreateUserIn true "Datos del usuario a crear"
 // @Success 201 {object} output.CreateUserOut
+// @Failure 400,401,403,409,422,500,503,504 {object} output.ProblemOut
 // @Tags Usuarios
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /api/users [post]
 func (uc *UserController) CreateUser(c *gin.Context) {
 	var userIn input.CreateUserIn
 
 	if err := c.ShouldBindJSON(&userIn); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": uc.constants.MessageErrorJson})
+		uc.respondBindingError(c, err)
 		return
 	}
 
-	userOut, err := uc.UserFacade.CreateUser(userIn)
+	userOut, err := uc.UserFacade.CreateUser(c.Request.Context(), userIn)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": uc.constants.MessageErrorCreation})
+		uc.respondError(c, err, problems.CodeUserCreateFailed, uc.messages(c).MessageErrorCreation)
 		return
 	}
 
@@ -45,40 +52,90 @@ func (uc *UserController) CreateUser(c *gin.Context) {
 }
 
 // @Summary Get all users
-// @Description Get a list of all users
+// @Description Get a page of users, optionally filtered and sorted. Use page/page_size or the opaque cursor returned in next_cursor/prev_cursor
 // @Produce json
-// @Success 200 {array} output.GetUsersOut
+// @Param page query int false "Page number (starts at 1)"
+// @Param page_size query int false "Page size (max 100)" default(20)
+// @Param cursor query string false "Opaque cursor from a previous response"
+// @Param sort query string false "Comma separated fields, '-' for descending (e.g. last_name,-created_at)"
+// @Param name query string false "Name starts with"
+// @Param last_name query string false "Last name starts with"
+// @Param email query string false "Email equals, case insensitive"
+// @Param username query string false "Username equals, case insensitive"
+// @Param phone query string false "Phone number equals, ignoring separators"
+// @Param created_after query string false "Created after (RFC3339)"
+// @Param created_before query string false "Created before (RFC3339)"
+// @Param deleted query string false "Include soft deleted users (include) or list only them (only)" Enums(include, only)
+// @Success 200 {object} output.GetUsersPageOut
+// @Failure 400,401,403,422,500,503,504 {object} output.ProblemOut
 // @Tags Usuarios
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /api/users [get]
 func (uc *UserController) GetAllUsers(c *gin.Context) {
-	usersOut, err := uc.UserFacade.GetAllUsers()
+	var listIn input.ListUsersIn
+	if err := c.ShouldBindQuery(&listIn); err != nil {
+		uc.respondQueryError(c, err)
+		return
+	}
+
+	pageOut, err := uc.UserFacade.GetAllUsers(c.Request.Context(), listIn)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": uc.constants.MessageErrorGetUsers})
+		uc.respondError(c, err, problems.CodeUserListFailed, uc.messages(c).MessageErrorGetUsers)
 		return
 	}
 
-	c.JSON(http.StatusOK, usersOut)
+	pageOut.Links = pageLinks(c.Request.URL, pageOut)
+	c.JSON(http.StatusOK, pageOut)
 }
 
 // @Summary Get a single user
-// @Description Get details of a single user by ID
+// @Description Get details of a single user by ID. With as_of, get the user as it was at that instant (without ETag)
 // @Produce json
 // @Param id path int true "User ID"
+// @Param as_of query string false "Instant to reconstruct the user at (RFC3339)"
+// @Param If-None-Match header string false "ETag of the cached representation"
 // @Success 200 {object} output.GetUserOut
+// @Header 200 {string} ETag "Current version of the user"
+// @Success 304 "The cached representation is still current"
+// @Failure 400,401,403,404,500,503,504 {object} output.ProblemOut
 // @Tags Usuarios
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /api/users/{id} [get]
 func (uc *UserController) GetSingleUser(c *gin.Context) {
 	userID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": uc.constants.MessageErrorID})
+		uc.respondInvalidID(c)
+		return
+	}
+
+	var getIn input.GetUserIn
+	if err := c.ShouldBindQuery(&getIn); err != nil {
+		uc.respondQueryError(c, err)
+		return
+	}
+	if getIn.AsOf != nil {
+		userOut, err := uc.UserFacade.GetUserAsOf(c.Request.Context(), uint(userID), *getIn.AsOf)
+		if err != nil {
+			uc.respondError(c, err, problems.CodeUserGetFailed, uc.messages(c).MessageErrorGetUser)
+			return
+		}
+		c.JSON(http.StatusOK, userOut)
 		return
 	}
-	userOut, err := uc.UserFacade.GetUserByID(uint(userID))
+
+	userOut, err := uc.UserFacade.GetUserByID(c.Request.Context(), uint(userID))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": uc.constants.MessageErrorUserNotFount})
+		uc.respondError(c, err, problems.CodeUserGetFailed, uc.messages(c).MessageErrorGetUser)
 		return
 	}
 
+	c.Header("ETag", formatETag(userOut.Version))
+	if ifNoneMatch(c, userOut.Version) {
+		c.AbortWithStatus(http.StatusNotModified)
+		return
+	}
 	c.JSON(http.StatusOK, userOut)
 }
 
@@ -87,51 +144,281 @@ func (uc *UserController) GetSingleUser(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
+// @Param If-Match header string false "ETag obtained from a previous GET"
 // @Param user body input.UpdateUserIn true "New user data"
 // @Success 200 {object} output.UpdateUserOut
+// @Header 200 {string} ETag "New version of the user"
+// @Failure 400,401,403,404,409,412,422,500,503,504 {object} output.ProblemOut
 // @Tags Usuarios
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /api/users/{id} [put]
 func (uc *UserController) UpdateUser(c *gin.Context) {
 	userID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": uc.constants.MessageErrorID})
+		uc.respondInvalidID(c)
 		return
 	}
 
 	var userIn input.UpdateUserIn
 	if err := c.ShouldBindJSON(&userIn); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": uc.constants.MessageErrorJson})
+		uc.respondBindingError(c, err)
 		return
 	}
+	userIn.IfMatch = ifMatch(c)
 
-	userOut, err := uc.UserFacade.UpdateUser(uint(userID), userIn)
+	userOut, err := uc.UserFacade.UpdateUser(c.Request.Context(), uint(userID), userIn)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": uc.constants.MessageErrorUpdateUser})
+		uc.respondError(c, err, problems.CodeUserUpdateFailed, uc.messages(c).MessageErrorUpdateUser)
 		return
 	}
 
+	c.Header("ETag", formatETag(userOut.Version))
+	c.JSON(http.StatusOK, userOut)
+}
+
+// @Summary Partially update a user
+// @Description Apply a JSON Merge Patch (RFC 7386) or a JSON Patch (RFC 6902) to the user document {"name", "last_name"}. Only the changed fields are written
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param If-Match header string false "ETag obtained from a previous GET"
+// @Param patch body object true "Merge patch object or array of JSON Patch operations"
+// @Success 200 {object} output.UpdateUserOut
+// @Header 200 {string} ETag "New version of the user"
+// @Failure 400,401,403,404,409,412,415,422,500,503,504 {object} output.ProblemOut
+// @Tags Usuarios
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id} [patch]
+func (uc *UserController) PatchUser(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		uc.respondInvalidID(c)
+		return
+	}
+
+	patchIn := input.PatchUserIn{Type: c.ContentType(), IfMatch: ifMatch(c)}
+	if patchIn.Type != input.MergePatchType && patchIn.Type != input.JSONPatchType {
+		c.Header("Accept-Patch", input.MergePatchType+", "+input.JSONPatchType)
+		problems.Respond(c, problems.New(http.StatusUnsupportedMediaType, problems.CodeUnsupportedMedia, uc.messages(c).MessageErrorMediaType))
+		return
+	}
+
+	patchIn.Patch, err = io.ReadAll(c.Request.Body)
+	if err != nil || !json.Valid(patchIn.Patch) {
+		problems.Respond(c, problems.New(http.StatusBadRequest, problems.CodeInvalidRequestBody, uc.messages(c).MessageErrorJson))
+		return
+	}
+
+	userOut, err := uc.UserFacade.PatchUser(c.Request.Context(), uint(userID), patchIn)
+	if err != nil {
+		uc.respondError(c, err, problems.CodeUserUpdateFailed, uc.messages(c).MessageErrorUpdateUser)
+		return
+	}
+
+	c.Header("ETag", formatETag(userOut.Version))
 	c.JSON(http.StatusOK, userOut)
 }
 
 // @Summary Delete a user
-// @Description Delete a user by ID
+// @Description Soft delete a user by ID. With hard=true the user is removed permanently, whether or not it was soft deleted; this also requires the users:purge permission
 // @Produce json
 // @Param id path int true "User ID"
+// @Param hard query bool false "Remove the user permanently"
 // @Success 200 {object} output.DeleteUserOut
+// @Failure 400,401,403,404,500,503,504 {object} output.ProblemOut
 // @Tags Usuarios
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /api/users/{id} [delete]
 func (uc *UserController) DeleteUser(c *gin.Context) {
 	userID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": uc.constants.MessageErrorID})
+		uc.respondInvalidID(c)
+		return
+	}
+
+	var deleteIn input.DeleteUserIn
+	if err := c.ShouldBindQuery(&deleteIn); err != nil {
+		uc.respondQueryError(c, err)
 		return
 	}
 
-	userOut, err := uc.UserFacade.DeleteUser(uint(userID))
+	var userOut output.DeleteUserOut
+	if deleteIn.Hard {
+		userOut, err = uc.UserFacade.PurgeUser(c.Request.Context(), uint(userID))
+	} else {
+		userOut, err = uc.UserFacade.DeleteUser(c.Request.Context(), uint(userID))
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": uc.constants.MessageErrorDeleteUser})
+		uc.respondError(c, err, problems.CodeUserDeleteFailed, uc.messages(c).MessageErrorDeleteUser)
 		return
 	}
 
 	c.JSON(http.StatusOK, userOut)
 }
+
+// @Summary Restore a deleted user
+// @Description Undo the soft delete of a user. Restoring a user that is not deleted has no effect
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} output.GetUserOut
+// @Header 200 {string} ETag "New version of the user"
+// @Failure 400,401,403,404,409,500,503,504 {object} output.ProblemOut
+// @Tags Usuarios
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/restore [post]
+func (uc *UserController) RestoreUser(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		uc.respondInvalidID(c)
+		return
+	}
+
+	userOut, err := uc.UserFacade.RestoreUser(c.Request.Context(), uint(userID))
+	if err != nil {
+		uc.respondError(c, err, problems.CodeUserRestoreFailed, uc.messages(c).MessageErrorRestoreUser)
+		return
+	}
+
+	c.Header("ETag", formatETag(userOut.Version))
+	c.JSON(http.StatusOK, userOut)
+}
+
+// @Summary Get the change history of a user
+// @Description Get the audit records of a user, newest first. The history is kept after the user is deleted
+// @Produce json
+// @Param id path int true "User ID"
+// @Param page query int false "Page number (starts at 1)"
+// @Param page_size query int false "Page size (max 100)" default(20)
+// @Param actor query string false "Only changes made by this actor"
+// @Param operation query string false "Only this operation" Enums(create, update, delete, restore, purge, password, roles, lock, unlock, verify_email)
+// @Param from query string false "Changes made at or after (RFC3339)"
+// @Param to query string false "Changes made before (RFC3339)"
+// @Success 200 {object} output.GetAuditPageOut
+// @Failure 400,401,403,422,500,503,504 {object} output.ProblemOut
+// @Tags Auditoría
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/history [get]
+func (uc *UserController) GetUserHistory(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		uc.respondInvalidID(c)
+		return
+	}
+
+	var listIn input.ListAuditIn
+	if err := c.ShouldBindQuery(&listIn); err != nil {
+		uc.respondQueryError(c, err)
+		return
+	}
+
+	pageOut, err := uc.UserFacade.GetUserHistory(c.Request.Context(), uint(userID), listIn)
+	if err != nil {
+		uc.respondError(c, err, problems.CodeAuditListFailed, uc.messages(c).MessageErrorGetAudit)
+		return
+	}
+
+	c.JSON(http.StatusOK, pageOut)
+}
+
+// @Summary Get a version of a user
+// @Description Get the user as it was at the given version, reconstructed from its stored snapshots
+// @Produce json
+// @Param id path int true "User ID"
+// @Param version path int true "Version number"
+// @Success 200 {object} output.UserVersionOut
+// @Failure 400,401,403,404,500,503,504 {object} output.ProblemOut
+// @Tags Versiones
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/versions/{version} [get]
+func (uc *UserController) GetUserVersion(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		uc.respondInvalidID(c)
+		return
+	}
+	version, err := strconv.ParseUint(c.Param("version"), 10, 0)
+	if err != nil || version == 0 {
+		uc.respondInvalidVersion(c)
+		return
+	}
+
+	versionOut, err := uc.UserFacade.GetUserVersion(c.Request.Context(), uint(userID), uint(version))
+	if err != nil {
+		uc.respondError(c, err, problems.CodeUserGetFailed, uc.messages(c).MessageErrorGetUser)
+		return
+	}
+
+	c.JSON(http.StatusOK, versionOut)
+}
+
+// @Summary Compare two versions of a user
+// @Description Get the fields that changed between two versions of the user
+// @Produce json
+// @Param id path int true "User ID"
+// @Param from query int true "Version to compare from"
+// @Param to query int true "Version to compare to"
+// @Success 200 {object} output.UserDiffOut
+// @Failure 400,401,403,404,422,500,503,504 {object} output.ProblemOut
+// @Tags Versiones
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/users/{id}/diff [get]
+func (uc *UserController) DiffUserVersions(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		uc.respondInvalidID(c)
+		return
+	}
+
+	var diffIn input.DiffUserIn
+	if err := c.ShouldBindQuery(&diffIn); err != nil {
+		uc.respondQueryError(c, err)
+		return
+	}
+
+	diffOut, err := uc.UserFacade.DiffUserVersions(c.Request.Context(), uint(userID), diffIn.From, diffIn.To)
+	if err != nil {
+		uc.respondError(c, err, problems.CodeUserGetFailed, uc.messages(c).MessageErrorGetUser)
+		return
+	}
+
+	c.JSON(http.StatusOK, diffOut)
+}
+
+// @Summary Get the audit trail
+// @Description Get the audit records of all users, newest first
+// @Produce json
+// @Param page query int false "Page number (starts at 1)"
+// @Param page_size query int false "Page size (max 100)" default(20)
+// @Param actor query string false "Only changes made by this actor"
+// @Param operation query string false "Only this operation" Enums(create, update, delete, restore, purge, password, roles, lock, unlock, verify_email)
+// @Param from query string false "Changes made at or after (RFC3339)"
+// @Param to query string false "Changes made before (RFC3339)"
+// @Success 200 {object} output.GetAuditPageOut
+// @Failure 400,401,403,422,500,503,504 {object} output.ProblemOut
+// @Tags Auditoría
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api/audit [get]
+func (uc *UserController) GetAuditRecords(c *gin.Context) {
+	var listIn input.ListAuditIn
+	if err := c.ShouldBindQuery(&listIn); err != nil {
+		uc.respondQueryError(c, err)
+		return
+	}
+
+	pageOut, err := uc.UserFacade.GetAuditRecords(c.Request.Context(), listIn)
+	if err != nil {
+		uc.respondError(c, err, problems.CodeAuditListFailed, uc.messages(c).MessageErrorGetAudit)
+		return
+	}
+
+	c.JSON(http.StatusOK, pageOut)
+}
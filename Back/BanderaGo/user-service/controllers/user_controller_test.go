@@ -1,24 +1,36 @@
 package controllers
 
 import (
+	"application/apperrors"
 	"application/dtos/input"
 	"application/dtos/output"
+	"application/facade"
+	"application/i18n"
+	"application/problems"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"bytes"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/stretchr/testify/assert"
 )
 
+// Catálogo de mensajes usado por las pruebas, con español como idioma por defecto
+var testCatalog, _ = i18n.NewCatalog("es")
+var testMessages = testCatalog.Default()
+
 // MockUserFacade es una implementación simulada de la interfaz UserFacade que devuelve objetos correctos
 type MockUserFacade struct{}
 
-func (m *MockUserFacade) CreateUser(userIn input.CreateUserIn) (output.CreateUserOut, error) {
+func (m *MockUserFacade) CreateUser(ctx context.Context, userIn input.CreateUserIn) (output.CreateUserOut, error) {
 	userOut := output.CreateUserOut{
 		ID:       1,
 		Name:     userIn.Name,
@@ -27,53 +39,185 @@ func (m *MockUserFacade) CreateUser(userIn input.CreateUserIn) (output.CreateUse
 	}
 	return userOut, nil
 }
-func (m *MockUserFacade) GetAllUsers() ([]output.GetUsersOut, error) {
+func (m *MockUserFacade) GetAllUsers(ctx context.Context, listIn input.ListUsersIn) (output.GetUsersPageOut, error) {
 	usersOut := []output.GetUsersOut{
 		{ID: 1, Name: "John", LastName: "Doe"},
 		{ID: 2, Name: "Jane", LastName: "Smith"},
 	}
-	return usersOut, nil
+	pageSize := listIn.PageSize
+	if pageSize == 0 {
+		pageSize = 2
+	}
+	return output.GetUsersPageOut{Data: usersOut, Total: 5, Page: max(listIn.Page, 1), PageSize: pageSize, NextCursor: "next"}, nil
 }
-func (m *MockUserFacade) GetUserByID(id uint) (output.GetUserOut, error) {
-	return output.GetUserOut{ID: 1, Name: "John", LastName: "Doe"}, nil
+func (m *MockUserFacade) GetUserByID(ctx context.Context, id uint) (output.GetUserOut, error) {
+	return output.GetUserOut{ID: 1, Name: "John", LastName: "Doe", Version: 3}, nil
 }
-func (m *MockUserFacade) UpdateUser(id uint, userIn input.UpdateUserIn) (output.UpdateUserOut, error) {
+func (m *MockUserFacade) UpdateUser(ctx context.Context, id uint, userIn input.UpdateUserIn) (output.UpdateUserOut, error) {
+	// El usuario simulado está en la versión 3
+	if !userIn.IfMatch.Matches(3) {
+		return output.UpdateUserOut{}, apperrors.PreconditionFailed(errors.New("stale version"))
+	}
 	userOut := output.UpdateUserOut{
 		ID:       id,
 		Name:     userIn.Name,
 		LastName: userIn.LastName,
+		Version:  4,
 		// UpdatedAt: time.Now(),
 	}
 	return userOut, nil
 }
-func (m *MockUserFacade) DeleteUser(id uint) (output.DeleteUserOut, error) {
+func (m *MockUserFacade) PatchUser(ctx context.Context, id uint, patchIn input.PatchUserIn) (output.UpdateUserOut, error) {
+	return output.UpdateUserOut{ID: id, Name: "Patched", LastName: patchIn.Type}, nil
+}
+func (m *MockUserFacade) DeleteUser(ctx context.Context, id uint) (output.DeleteUserOut, error) {
 	return output.DeleteUserOut{Success: true}, nil
 }
+func (m *MockUserFacade) RestoreUser(ctx context.Context, id uint) (output.GetUserOut, error) {
+	return output.GetUserOut{ID: id, Name: "John", LastName: "Doe", Version: 4}, nil
+}
+func (m *MockUserFacade) PurgeUser(ctx context.Context, id uint) (output.DeleteUserOut, error) {
+	return output.DeleteUserOut{Success: true}, nil
+}
+func (m *MockUserFacade) GetUserAsOf(ctx context.Context, id uint, at time.Time) (output.GetUserOut, error) {
+	return output.GetUserOut{ID: id, Name: "Jon", LastName: "Doe", Version: 1}, nil
+}
+func (m *MockUserFacade) GetUserVersion(ctx context.Context, id, version uint) (output.UserVersionOut, error) {
+	return output.UserVersionOut{ID: id, Version: version, Name: "Jon", LastName: "Doe", ValidFrom: time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)}, nil
+}
+func (m *MockUserFacade) DiffUserVersions(ctx context.Context, id, from, to uint) (output.UserDiffOut, error) {
+	return output.UserDiffOut{ID: id, From: from, To: to, Changes: map[string]output.FieldChangeOut{"name": {Before: "Jon", After: "John"}}}, nil
+}
+func (m *MockUserFacade) GetUserHistory(ctx context.Context, id uint, listIn input.ListAuditIn) (output.GetAuditPageOut, error) {
+	record := output.AuditRecordOut{ID: 1, UserID: id, Actor: "alice", RequestID: "req-1", Operation: "update",
+		Changes: map[string]output.FieldChangeOut{"name": {Before: "Jon", After: "John"}}}
+	return output.GetAuditPageOut{Data: []output.AuditRecordOut{record}, Total: 1, Page: 1, PageSize: 20}, nil
+}
+func (m *MockUserFacade) GetAuditRecords(ctx context.Context, listIn input.ListAuditIn) (output.GetAuditPageOut, error) {
+	return output.GetAuditPageOut{Data: []output.AuditRecordOut{}, Total: 0, Page: 1, PageSize: 20}, nil
+}
 
 // MockUserFacadeError es una implementación simulada de la interfaz UserFacade que devuelve errores
 type MockUserFacadeError struct{}
 
-func (m *MockUserFacadeError) CreateUser(userIn input.CreateUserIn) (output.CreateUserOut, error) {
+func (m *MockUserFacadeError) CreateUser(ctx context.Context, userIn input.CreateUserIn) (output.CreateUserOut, error) {
 	return output.CreateUserOut{}, errors.New("create error")
 }
-func (m *MockUserFacadeError) GetAllUsers() ([]output.GetUsersOut, error) {
-	return []output.GetUsersOut{}, errors.New("get list error")
+func (m *MockUserFacadeError) GetAllUsers(ctx context.Context, listIn input.ListUsersIn) (output.GetUsersPageOut, error) {
+	return output.GetUsersPageOut{}, errors.New("get list error")
 }
-func (m *MockUserFacadeError) GetUserByID(id uint) (output.GetUserOut, error) {
+func (m *MockUserFacadeError) GetUserByID(ctx context.Context, id uint) (output.GetUserOut, error) {
 	return output.GetUserOut{}, errors.New("get first error")
 }
-func (m *MockUserFacadeError) UpdateUser(id uint, userIn input.UpdateUserIn) (output.UpdateUserOut, error) {
+func (m *MockUserFacadeError) UpdateUser(ctx context.Context, id uint, userIn input.UpdateUserIn) (output.UpdateUserOut, error) {
 	return output.UpdateUserOut{}, errors.New("update error")
 }
-func (m *MockUserFacadeError) DeleteUser(id uint) (output.DeleteUserOut, error) {
+func (m *MockUserFacadeError) PatchUser(ctx context.Context, id uint, patchIn input.PatchUserIn) (output.UpdateUserOut, error) {
+	return output.UpdateUserOut{}, errors.New("patch error")
+}
+func (m *MockUserFacadeError) DeleteUser(ctx context.Context, id uint) (output.DeleteUserOut, error) {
 	return output.DeleteUserOut{}, errors.New("delete error")
 }
+func (m *MockUserFacadeError) RestoreUser(ctx context.Context, id uint) (output.GetUserOut, error) {
+	return output.GetUserOut{}, errors.New("restore error")
+}
+func (m *MockUserFacadeError) PurgeUser(ctx context.Context, id uint) (output.DeleteUserOut, error) {
+	return output.DeleteUserOut{}, errors.New("purge error")
+}
+func (m *MockUserFacadeError) GetUserAsOf(ctx context.Context, id uint, at time.Time) (output.GetUserOut, error) {
+	return output.GetUserOut{}, errors.New("as of error")
+}
+func (m *MockUserFacadeError) GetUserVersion(ctx context.Context, id, version uint) (output.UserVersionOut, error) {
+	return output.UserVersionOut{}, errors.New("version error")
+}
+func (m *MockUserFacadeError) DiffUserVersions(ctx context.Context, id, from, to uint) (output.UserDiffOut, error) {
+	return output.UserDiffOut{}, errors.New("diff error")
+}
+func (m *MockUserFacadeError) GetUserHistory(ctx context.Context, id uint, listIn input.ListAuditIn) (output.GetAuditPageOut, error) {
+	return output.GetAuditPageOut{}, errors.New("history error")
+}
+func (m *MockUserFacadeError) GetAuditRecords(ctx context.Context, listIn input.ListAuditIn) (output.GetAuditPageOut, error) {
+	return output.GetAuditPageOut{}, errors.New("audit error")
+}
+
+// MockUserFacadeTimeout es una implementación simulada de la interfaz UserFacade que excede el tiempo límite
+type MockUserFacadeTimeout struct {
+	MockUserFacadeError
+}
+
+func (m *MockUserFacadeTimeout) GetUserByID(ctx context.Context, id uint) (output.GetUserOut, error) {
+	<-ctx.Done()
+	return output.GetUserOut{}, ctx.Err()
+}
+
+// MockUserFacadeDomainError es una implementación simulada de la interfaz UserFacade que devuelve un error del dominio
+type MockUserFacadeDomainError struct {
+	err error
+}
+
+func (m *MockUserFacadeDomainError) CreateUser(ctx context.Context, userIn input.CreateUserIn) (output.CreateUserOut, error) {
+	return output.CreateUserOut{}, m.err
+}
+func (m *MockUserFacadeDomainError) GetAllUsers(ctx context.Context, listIn input.ListUsersIn) (output.GetUsersPageOut, error) {
+	return output.GetUsersPageOut{}, m.err
+}
+func (m *MockUserFacadeDomainError) GetUserByID(ctx context.Context, id uint) (output.GetUserOut, error) {
+	return output.GetUserOut{}, m.err
+}
+func (m *MockUserFacadeDomainError) UpdateUser(ctx context.Context, id uint, userIn input.UpdateUserIn) (output.UpdateUserOut, error) {
+	return output.UpdateUserOut{}, m.err
+}
+func (m *MockUserFacadeDomainError) PatchUser(ctx context.Context, id uint, patchIn input.PatchUserIn) (output.UpdateUserOut, error) {
+	return output.UpdateUserOut{}, m.err
+}
+func (m *MockUserFacadeDomainError) DeleteUser(ctx context.Context, id uint) (output.DeleteUserOut, error) {
+	return output.DeleteUserOut{}, m.err
+}
+func (m *MockUserFacadeDomainError) RestoreUser(ctx context.Context, id uint) (output.GetUserOut, error) {
+	return output.GetUserOut{}, m.err
+}
+func (m *MockUserFacadeDomainError) PurgeUser(ctx context.Context, id uint) (output.DeleteUserOut, error) {
+	return output.DeleteUserOut{}, m.err
+}
+func (m *MockUserFacadeDomainError) GetUserAsOf(ctx context.Context, id uint, at time.Time) (output.GetUserOut, error) {
+	return output.GetUserOut{}, m.err
+}
+func (m *MockUserFacadeDomainError) GetUserVersion(ctx context.Context, id, version uint) (output.UserVersionOut, error) {
+	return output.UserVersionOut{}, m.err
+}
+func (m *MockUserFacadeDomainError) DiffUserVersions(ctx context.Context, id, from, to uint) (output.UserDiffOut, error) {
+	return output.UserDiffOut{}, m.err
+}
+func (m *MockUserFacadeDomainError) GetUserHistory(ctx context.Context, id uint, listIn input.ListAuditIn) (output.GetAuditPageOut, error) {
+	return output.GetAuditPageOut{}, m.err
+}
+func (m *MockUserFacadeDomainError) GetAuditRecords(ctx context.Context, listIn input.ListAuditIn) (output.GetAuditPageOut, error) {
+	return output.GetAuditPageOut{}, m.err
+}
+
+// assertProblem verifica que la respuesta sea un problema RFC 7807 con el código y detalle esperados
+func assertProblem(t *testing.T, w *httptest.ResponseRecorder, code, detail string) output.ProblemOut {
+	t.Helper()
+	assert.Equal(t, problems.ContentType, w.Header().Get("Content-Type"))
+
+	var problem output.ProblemOut
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, w.Code, problem.Status)
+	assert.Equal(t, problems.TypeURI(code), problem.Type)
+	assert.Equal(t, code, problem.Code)
+	assert.Equal(t, detail, problem.Detail)
+	assert.Equal(t, http.StatusText(w.Code), problem.Title)
+	assert.NotEmpty(t, problem.Instance)
+	return problem
+}
 
 // ---------------------Tests para CreateUser ---------------------
 func TestCreateUser(t *testing.T) {
 	// Configurar el controlador y la fachada para las pruebas
 	facadeMock := &MockUserFacade{} // Implementa tu propio mock de UserFacade
-	userController := NewUserController(facadeMock)
+	userController := NewUserController(facadeMock, testCatalog)
 
 	// Crear un usuario de prueba
 	userIn := input.CreateUserIn{Name: "John", LastName: "Doe"}
@@ -105,7 +249,7 @@ func TestCreateUser(t *testing.T) {
 
 func TestCreateUserErrorJson(t *testing.T) {
 	facadeMock := &MockUserFacade{}
-	userController := NewUserController(facadeMock)
+	userController := NewUserController(facadeMock, testCatalog)
 
 	// Crear un usuario de prueba erroneo
 	userInIncorrect := output.DeleteUserOut{Success: true}
@@ -129,14 +273,66 @@ func TestCreateUserErrorJson(t *testing.T) {
 	userController.CreateUser(c)
 
 	// Verificar el código de estado y el1 cuerpo de la respuesta
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	problem := assertProblem(t, w, problems.CodeValidationFailed, testMessages.MessageErrorValidation)
+	assert.ElementsMatch(t, []output.FieldErrorOut{
+		{Field: "name", Code: "required", Message: testMessages.Field("required", "")},
+		{Field: "last_name", Code: "required", Message: testMessages.Field("required", "")},
+	}, problem.Errors)
+}
+
+func TestCreateUserMalformedJson(t *testing.T) {
+	facadeMock := &MockUserFacade{}
+	userController := NewUserController(facadeMock, testCatalog)
+
+	// Crear una solicitud HTTP simulada con un JSON mal formado
+	req, err := http.NewRequest("POST", "/api/users", bytes.NewBufferString(`{"name": "John",`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Crear un contexto de Gin con un grabador de respuesta simulado
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	// Ejecutar la función de controlador CreateUser
+	userController.CreateUser(c)
+
+	// Verificar el código de estado y el cuerpo de la respuesta
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	expectedBody := "{\"error\":\"" + userController.constants.MessageErrorJson + "\"}"
-	assert.Equal(t, string(expectedBody), w.Body.String())
+	assertProblem(t, w, problems.CodeInvalidRequestBody, testMessages.MessageErrorJson)
+}
+
+func TestCreateUserWrongFieldType(t *testing.T) {
+	facadeMock := &MockUserFacade{}
+	userController := NewUserController(facadeMock, testCatalog)
+
+	// Crear una solicitud HTTP simulada con un tipo de dato incorrecto
+	req, err := http.NewRequest("POST", "/api/users", bytes.NewBufferString(`{"name": 10, "last_name": "Doe"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Crear un contexto de Gin con un grabador de respuesta simulado
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	// Ejecutar la función de controlador CreateUser
+	userController.CreateUser(c)
+
+	// Verificar el código de estado y el detalle del campo
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	problem := assertProblem(t, w, problems.CodeInvalidRequestBody, testMessages.MessageErrorJson)
+	assert.Equal(t, []output.FieldErrorOut{{Field: "name", Code: "type", Message: testMessages.Field("type", "")}}, problem.Errors)
 }
 
 func TestCreateUserErrorCreation(t *testing.T) {
 	facadeMock := &MockUserFacadeError{}
-	userController := NewUserController(facadeMock)
+	userController := NewUserController(facadeMock, testCatalog)
 
 	// Convertir el usuario de entrada a JSON
 	userIn := input.CreateUserIn{Name: "John", LastName: "Doe"}
@@ -161,15 +357,14 @@ func TestCreateUserErrorCreation(t *testing.T) {
 
 	// Verificar el código de estado y el1 cuerpo de la respuesta
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	expectedBody := "{\"error\":\"" + userController.constants.MessageErrorCreation + "\"}"
-	assert.Equal(t, string(expectedBody), w.Body.String())
+	assertProblem(t, w, problems.CodeUserCreateFailed, testMessages.MessageErrorCreation)
 }
 
 // ---------------------Tests para GetAllUsers ---------------------
 func TestGetAllUsers(t *testing.T) {
 	// Configurar el controlador y la fachada para las pruebas
 	facadeMock := &MockUserFacade{}
-	userController := NewUserController(facadeMock)
+	userController := NewUserController(facadeMock, testCatalog)
 
 	// Crear una solicitud HTTP simulada
 	req, err := http.NewRequest("GET", "/api/users", nil)
@@ -187,14 +382,112 @@ func TestGetAllUsers(t *testing.T) {
 
 	// Verificar el código de estado y el cuerpo de la respuesta
 	assert.Equal(t, http.StatusOK, w.Code)
-	expectedBody := `[{"id":1,"name":"John","last_name":"Doe"},{"id":2,"name":"Jane","last_name":"Smith"}]`
+	expectedBody := `{
+		"data": [{"id":1,"name":"John","last_name":"Doe"},{"id":2,"name":"Jane","last_name":"Smith"}],
+		"total": 5,
+		"page": 1,
+		"page_size": 2,
+		"next_cursor": "next",
+		"links": {"self": "/api/users", "next": "/api/users?page=2"}
+	}`
 	assert.JSONEq(t, expectedBody, w.Body.String())
 }
 
+func TestGetAllUsersLinks(t *testing.T) {
+	// Configurar el controlador y la fachada para las pruebas
+	facadeMock := &MockUserFacade{}
+	userController := NewUserController(facadeMock, testCatalog)
+
+	// Crear una solicitud HTTP simulada con filtros y ordenamiento
+	req, err := http.NewRequest("GET", "/api/users?page=2&page_size=2&sort=-name&last_name=Do", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Crear un contexto de Gin con un grabador de respuesta simulado
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	// Ejecutar la función de controlador GetAllUsers
+	userController.GetAllUsers(c)
+
+	// Verificar que los enlaces conserven los filtros y cambien solo la página
+	assert.Equal(t, http.StatusOK, w.Code)
+	var pageOut output.GetUsersPageOut
+	if err := json.Unmarshal(w.Body.Bytes(), &pageOut); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "/api/users?page=2&page_size=2&sort=-name&last_name=Do", pageOut.Links.Self)
+	assert.Equal(t, "/api/users?last_name=Do&page=3&page_size=2&sort=-name", pageOut.Links.Next)
+	assert.Equal(t, "/api/users?last_name=Do&page=1&page_size=2&sort=-name", pageOut.Links.Prev)
+}
+
+func TestGetAllUsersInvalidQuery(t *testing.T) {
+	// Configurar el controlador y la fachada para las pruebas
+	facadeMock := &MockUserFacade{}
+	userController := NewUserController(facadeMock, testCatalog)
+
+	cases := []struct {
+		query  string
+		status int
+		code   string
+	}{
+		{"page=abc", http.StatusBadRequest, problems.CodeInvalidQuery},
+		{"created_after=yesterday", http.StatusBadRequest, problems.CodeInvalidQuery},
+		{"page_size=500", http.StatusUnprocessableEntity, problems.CodeValidationFailed},
+		{"page=0", http.StatusOK, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.query, func(t *testing.T) {
+			// Crear una solicitud HTTP simulada
+			req, err := http.NewRequest("GET", "/api/users?"+tc.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Crear un contexto de Gin con un grabador de respuesta simulado
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			// Ejecutar la función de controlador GetAllUsers
+			userController.GetAllUsers(c)
+
+			// Verificar el código de estado y el problema devuelto
+			assert.Equal(t, tc.status, w.Code)
+			if tc.code != "" {
+				problem := assertProblem(t, w, tc.code, problemDetail(tc.code))
+				if tc.code == problems.CodeValidationFailed {
+					assert.Equal(t, []output.FieldErrorOut{{Field: "page_size", Code: "max", Message: testMessages.Field("max", "100")}}, problem.Errors)
+				}
+			}
+		})
+	}
+}
+
+// problemDetail devuelve el detalle esperado para los códigos de parámetros inválidos
+func problemDetail(code string) string {
+	if code == problems.CodeValidationFailed {
+		return testMessages.MessageErrorValidation
+	}
+	return testMessages.MessageErrorQuery
+}
+
+func TestPageLinksCursor(t *testing.T) {
+	requestURL, _ := url.Parse("/api/users?cursor=abc&sort=name")
+	links := pageLinks(requestURL, output.GetUsersPageOut{NextCursor: "def", PrevCursor: "xyz"})
+
+	assert.Equal(t, "/api/users?cursor=abc&sort=name", links.Self)
+	assert.Equal(t, "/api/users?cursor=def&sort=name", links.Next)
+	assert.Equal(t, "/api/users?cursor=xyz&sort=name", links.Prev)
+}
+
 func TestGetAllUsersErrorGetList(t *testing.T) {
 	// Configurar el controlador y la fachada para las pruebas
 	facadeMock := &MockUserFacadeError{}
-	userController := NewUserController(facadeMock)
+	userController := NewUserController(facadeMock, testCatalog)
 
 	// Crear una solicitud HTTP simulada
 	req, err := http.NewRequest("GET", "/api/users", nil)
@@ -212,15 +505,14 @@ func TestGetAllUsersErrorGetList(t *testing.T) {
 
 	// Verificar el código de estado y el cuerpo de la respuesta
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	expectedBody := "{\"error\":\"" + userController.constants.MessageErrorGetUsers + "\"}"
-	assert.Equal(t, string(expectedBody), w.Body.String())
+	assertProblem(t, w, problems.CodeUserListFailed, testMessages.MessageErrorGetUsers)
 }
 
 // ---------------------Tests para GetSingleUser ---------------------
 func TestGetUserById(t *testing.T) {
 	// Configurar el controlador y la fachada para las pruebas
 	facadeMock := &MockUserFacade{}
-	userController := NewUserController(facadeMock)
+	userController := NewUserController(facadeMock, testCatalog)
 
 	// Crear una solicitud HTTP simulada
 	req, err := http.NewRequest("GET", "/api/users/1", nil)
@@ -246,7 +538,7 @@ func TestGetUserById(t *testing.T) {
 func TestGetUserByIdErrorInvalidID(t *testing.T) {
 	// Configurar el controlador y la fachada para las pruebas
 	facadeMock := &MockUserFacade{}
-	userController := NewUserController(facadeMock)
+	userController := NewUserController(facadeMock, testCatalog)
 
 	// Crear una solicitud HTTP simulada
 	req, err := http.NewRequest("GET", "/api/users/notANumber", nil)
@@ -265,14 +557,13 @@ func TestGetUserByIdErrorInvalidID(t *testing.T) {
 
 	// Verificar el código de estado y el cuerpo de la respuesta
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	expectedBody := "{\"error\":\"" + userController.constants.MessageErrorID + "\"}"
-	assert.Equal(t, string(expectedBody), w.Body.String())
+	assertProblem(t, w, problems.CodeInvalidUserID, testMessages.MessageErrorID)
 }
 
 func TestGetUserByIdErrorGetFirstError(t *testing.T) {
 	// Configurar el controlador y la fachada para las pruebas
 	facadeMock := &MockUserFacadeError{}
-	userController := NewUserController(facadeMock)
+	userController := NewUserController(facadeMock, testCatalog)
 
 	// Crear una solicitud HTTP simulada
 	req, err := http.NewRequest("GET", "/api/users/1", nil)
@@ -290,16 +581,15 @@ func TestGetUserByIdErrorGetFirstError(t *testing.T) {
 	userController.GetSingleUser(c)
 
 	// Verificar el código de estado y el cuerpo de la respuesta
-	assert.Equal(t, http.StatusNotFound, w.Code)
-	expectedBody := "{\"error\":\"" + userController.constants.MessageErrorUserNotFount + "\"}"
-	assert.Equal(t, string(expectedBody), w.Body.String())
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assertProblem(t, w, problems.CodeUserGetFailed, testMessages.MessageErrorGetUser)
 }
 
 // ---------------------Tests para UpdateUser ---------------------
 func TestUpdateUser(t *testing.T) {
 	// Configurar el controlador y la fachada para las pruebas
 	facadeMock := &MockUserFacade{}
-	userController := NewUserController(facadeMock)
+	userController := NewUserController(facadeMock, testCatalog)
 
 	// Crear una solicitud HTTP simulada
 	userIn := input.UpdateUserIn{
@@ -335,7 +625,7 @@ func TestUpdateUser(t *testing.T) {
 func TestUpdateUserInvalidId(t *testing.T) {
 	// Configurar el controlador y la fachada para las pruebas
 	facadeMock := &MockUserFacade{}
-	userController := NewUserController(facadeMock)
+	userController := NewUserController(facadeMock, testCatalog)
 
 	// Crear una solicitud HTTP simulada
 	userIn := input.UpdateUserIn{
@@ -363,14 +653,13 @@ func TestUpdateUserInvalidId(t *testing.T) {
 
 	// Verificar el código de estado y el cuerpo de la respuesta
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	expectedBody := "{\"error\":\"" + userController.constants.MessageErrorID + "\"}"
-	assert.Equal(t, string(expectedBody), w.Body.String())
+	assertProblem(t, w, problems.CodeInvalidUserID, testMessages.MessageErrorID)
 }
 
 func TestUpdateUserErrorJson(t *testing.T) {
 	// Configurar el controlador y la fachada para las pruebas
 	facadeMock := &MockUserFacade{}
-	userController := NewUserController(facadeMock)
+	userController := NewUserController(facadeMock, testCatalog)
 
 	// Crear una solicitud HTTP simulada
 	userInIncorrect := output.DeleteUserOut{Success: false}
@@ -395,15 +684,18 @@ func TestUpdateUserErrorJson(t *testing.T) {
 	userController.UpdateUser(c)
 
 	// Verificar el código de estado y el1 cuerpo de la respuesta
-	assert.Equal(t, http.StatusBadRequest, w.Code)
-	expectedBody := "{\"error\":\"" + userController.constants.MessageErrorJson + "\"}"
-	assert.Equal(t, string(expectedBody), w.Body.String())
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	problem := assertProblem(t, w, problems.CodeValidationFailed, testMessages.MessageErrorValidation)
+	assert.ElementsMatch(t, []output.FieldErrorOut{
+		{Field: "name", Code: "required", Message: testMessages.Field("required", "")},
+		{Field: "last_name", Code: "required", Message: testMessages.Field("required", "")},
+	}, problem.Errors)
 }
 
 func TestUpdateUserUpdateError(t *testing.T) {
 	// Configurar el controlador y la fachada para las pruebas
 	facadeMock := &MockUserFacadeError{}
-	userController := NewUserController(facadeMock)
+	userController := NewUserController(facadeMock, testCatalog)
 
 	// Crear una solicitud HTTP simulada
 	userIn := input.UpdateUserIn{
@@ -431,15 +723,88 @@ func TestUpdateUserUpdateError(t *testing.T) {
 
 	// Verificar el código de estado y el1 cuerpo de la respuesta
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	expectedBody := "{\"error\":\"" + userController.constants.MessageErrorUpdateUser + "\"}"
-	assert.Equal(t, string(expectedBody), w.Body.String())
+	assertProblem(t, w, problems.CodeUserUpdateFailed, testMessages.MessageErrorUpdateUser)
+}
+
+// ---------------------Tests para PatchUser ---------------------
+// patchRequest ejecuta PatchUser con el cuerpo y tipo de contenido indicados
+func patchRequest(t *testing.T, facadeMock facade.UserFacade, contentType, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	userController := NewUserController(facadeMock, testCatalog)
+
+	// Crear una solicitud HTTP simulada
+	req, err := http.NewRequest("PATCH", "/api/users/1", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	// Crear un contexto de Gin con un grabador de respuesta simulado
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request = req
+
+	// Ejecutar la función de controlador PatchUser
+	userController.PatchUser(c)
+	return w
+}
+
+func TestPatchUser(t *testing.T) {
+	// Caso de prueba: los dos tipos de documento llegan a la fachada con su tipo
+	for _, contentType := range []string{input.MergePatchType, input.JSONPatchType + "; charset=utf-8"} {
+		w := patchRequest(t, &MockUserFacade{}, contentType, `{"name":"Patched"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var userOut output.UpdateUserOut
+		if err := json.Unmarshal(w.Body.Bytes(), &userOut); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, uint(1), userOut.ID)
+		assert.Contains(t, contentType, userOut.LastName)
+	}
+}
+
+func TestPatchUserUnsupportedMediaType(t *testing.T) {
+	// Caso de prueba: un cuerpo application/json no es un documento de cambios
+	w := patchRequest(t, &MockUserFacade{}, "application/json", `{"name":"Patched"}`)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, input.MergePatchType+", "+input.JSONPatchType, w.Header().Get("Accept-Patch"))
+	assertProblem(t, w, problems.CodeUnsupportedMedia, testMessages.MessageErrorMediaType)
+}
+
+func TestPatchUserMalformedJson(t *testing.T) {
+	// Caso de prueba: el documento no es JSON válido
+	w := patchRequest(t, &MockUserFacade{}, input.MergePatchType, `{"name":`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertProblem(t, w, problems.CodeInvalidRequestBody, testMessages.MessageErrorJson)
+}
+
+func TestPatchUserValidationError(t *testing.T) {
+	// Caso de prueba: el usuario resultante no cumple las reglas de binding
+	validationErr := binding.Validator.ValidateStruct(&input.UpdateUserIn{Name: "John"})
+	facadeMock := &MockUserFacadeDomainError{err: apperrors.Validation("", validationErr)}
+	w := patchRequest(t, facadeMock, input.MergePatchType, `{"last_name":null}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	problem := assertProblem(t, w, problems.CodeValidationFailed, testMessages.MessageErrorValidation)
+	assert.Equal(t, []output.FieldErrorOut{{Field: "last_name", Code: "required", Message: testMessages.Field("required", "")}}, problem.Errors)
+}
+
+func TestPatchUserError(t *testing.T) {
+	w := patchRequest(t, &MockUserFacadeError{}, input.JSONPatchType, `[{"op":"remove","path":"/name"}]`)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assertProblem(t, w, problems.CodeUserUpdateFailed, testMessages.MessageErrorUpdateUser)
 }
 
 // ---------------------Tests para DeleteUser ---------------------
 func TestDeleteUser(t *testing.T) {
 	// Configurar el controlador y la fachada para las pruebas
 	facadeMock := &MockUserFacade{}
-	userController := NewUserController(facadeMock)
+	userController := NewUserController(facadeMock, testCatalog)
 
 	// Crear una solicitud HTTP simulada
 	req, err := http.NewRequest("DELETE", "/api/users/1", nil)
@@ -465,7 +830,7 @@ func TestDeleteUser(t *testing.T) {
 func TestDeleteUserErrorId(t *testing.T) {
 	// Configurar el controlador y la fachada para las pruebas
 	facadeMock := &MockUserFacade{}
-	userController := NewUserController(facadeMock)
+	userController := NewUserController(facadeMock, testCatalog)
 
 	// Crear una solicitud HTTP simulada
 	req, err := http.NewRequest("DELETE", "/api/users/notANumber", nil)
@@ -484,14 +849,13 @@ func TestDeleteUserErrorId(t *testing.T) {
 
 	// Verificar el código de estado y el cuerpo de la respuesta
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	expectedBody := "{\"error\":\"" + userController.constants.MessageErrorID + "\"}"
-	assert.Equal(t, string(expectedBody), w.Body.String())
+	assertProblem(t, w, problems.CodeInvalidUserID, testMessages.MessageErrorID)
 }
 
 func TestDeleteUserDeleteError(t *testing.T) {
 	// Configurar el controlador y la fachada para las pruebas
 	facadeMock := &MockUserFacadeError{}
-	userController := NewUserController(facadeMock)
+	userController := NewUserController(facadeMock, testCatalog)
 
 	// Crear una solicitud HTTP simulada
 	req, err := http.NewRequest("DELETE", "/api/users/1", nil)
@@ -510,6 +874,467 @@ func TestDeleteUserDeleteError(t *testing.T) {
 
 	// Verificar el código de estado y el1 cuerpo de la respuesta
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	expectedBody := "{\"error\":\"" + userController.constants.MessageErrorDeleteUser + "\"}"
-	assert.Equal(t, string(expectedBody), w.Body.String())
+	assertProblem(t, w, problems.CodeUserDeleteFailed, testMessages.MessageErrorDeleteUser)
+}
+
+// ---------------------Tests para ETag ---------------------
+// conditionalRequest ejecuta el controlador indicado con los encabezados condicionales recibidos
+// Caso de prueba: restaurar un usuario devuelve su representación con la nueva versión
+func TestRestoreUser(t *testing.T) {
+	w := conditionalRequest(t, (*UserController).RestoreUser, "POST", nil, nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	assert.JSONEq(t, `{"id":1,"name":"John","last_name":"Doe"}`, w.Body.String())
+}
+
+// Caso de prueba: un error al restaurar responde con el código propio de la operación
+func TestRestoreUserError(t *testing.T) {
+	userController := NewUserController(&MockUserFacadeError{}, testCatalog)
+
+	req, _ := http.NewRequest("POST", "/api/users/1/restore", nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request = req
+
+	userController.RestoreUser(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assertProblem(t, w, problems.CodeUserRestoreFailed, testMessages.MessageErrorRestoreUser)
+}
+
+// Caso de prueba: con as_of se devuelve el usuario en ese instante y sin ETag
+func TestGetSingleUserAsOf(t *testing.T) {
+	userController := NewUserController(&MockUserFacade{}, testCatalog)
+
+	req, _ := http.NewRequest("GET", "/api/users/1?as_of=2024-03-03T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request = req
+
+	userController.GetSingleUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+	assert.JSONEq(t, `{"id":1,"name":"Jon","last_name":"Doe"}`, w.Body.String())
+
+	// Caso de prueba: un instante que no es RFC 3339 se rechaza
+	req, _ = http.NewRequest("GET", "/api/users/1?as_of=ayer", nil)
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request = req
+
+	userController.GetSingleUser(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertProblem(t, w, problems.CodeInvalidQuery, testMessages.MessageErrorQuery)
+}
+
+// Caso de prueba: obtener una versión concreta del usuario
+func TestGetUserVersion(t *testing.T) {
+	userController := NewUserController(&MockUserFacade{}, testCatalog)
+
+	req, _ := http.NewRequest("GET", "/api/users/1/versions/2", nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}, {Key: "version", Value: "2"}}
+	c.Request = req
+
+	userController.GetUserVersion(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":1,"version":2,"name":"Jon","last_name":"Doe","valid_from":"2024-03-03T00:00:00Z"}`, w.Body.String())
+}
+
+// Caso de prueba: números de versión inválidos
+func TestGetUserVersionInvalid(t *testing.T) {
+	userController := NewUserController(&MockUserFacade{}, testCatalog)
+
+	for _, version := range []string{"abc", "0", "-1"} {
+		t.Run(version, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/users/1/versions/"+version, nil)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: "1"}, {Key: "version", Value: version}}
+			c.Request = req
+
+			userController.GetUserVersion(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assertProblem(t, w, problems.CodeInvalidVersion, testMessages.MessageErrorVersion)
+		})
+	}
+}
+
+// Caso de prueba: diferencias entre dos versiones
+func TestDiffUserVersions(t *testing.T) {
+	userController := NewUserController(&MockUserFacade{}, testCatalog)
+
+	req, _ := http.NewRequest("GET", "/api/users/1/diff?from=1&to=2", nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request = req
+
+	userController.DiffUserVersions(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":1,"from":1,"to":2,"changes":{"name":{"before":"Jon","after":"John"}}}`, w.Body.String())
+
+	// Caso de prueba: ambas versiones son obligatorias
+	req, _ = http.NewRequest("GET", "/api/users/1/diff?from=1", nil)
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request = req
+
+	userController.DiffUserVersions(c)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	problem := assertProblem(t, w, problems.CodeValidationFailed, testMessages.MessageErrorValidation)
+	assert.Equal(t, "to", problem.Errors[0].Field)
+}
+
+// Caso de prueba: historial de cambios de un usuario
+func TestGetUserHistory(t *testing.T) {
+	userController := NewUserController(&MockUserFacade{}, testCatalog)
+
+	req, _ := http.NewRequest("GET", "/api/users/1/history?operation=update", nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request = req
+
+	userController.GetUserHistory(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	expectedBody := `{
+		"data": [{"id":1,"user_id":1,"actor":"alice","request_id":"req-1","operation":"update",
+			"changes":{"name":{"before":"Jon","after":"John"}},"created_at":"0001-01-01T00:00:00Z"}],
+		"total": 1,
+		"page": 1,
+		"page_size": 20
+	}`
+	assert.JSONEq(t, expectedBody, w.Body.String())
+}
+
+// Caso de prueba: parámetros inválidos del historial y de la auditoría
+func TestAuditInvalidQuery(t *testing.T) {
+	userController := NewUserController(&MockUserFacade{}, testCatalog)
+
+	cases := []struct {
+		query  string
+		status int
+		code   string
+	}{
+		{"operation=rename", http.StatusUnprocessableEntity, problems.CodeValidationFailed},
+		{"from=yesterday", http.StatusBadRequest, problems.CodeInvalidQuery},
+		{"page_size=500", http.StatusUnprocessableEntity, problems.CodeValidationFailed},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.query, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/audit?"+tc.query, nil)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			userController.GetAuditRecords(c)
+
+			assert.Equal(t, tc.status, w.Code)
+			assertProblem(t, w, tc.code, problemDetail(tc.code))
+		})
+	}
+
+	// Caso de prueba: identificador inválido en el historial
+	req, _ := http.NewRequest("GET", "/api/users/abc/history", nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+	c.Request = req
+
+	userController.GetUserHistory(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// Caso de prueba: un error al listar la auditoría responde con el código propio de la operación
+func TestGetAuditRecordsError(t *testing.T) {
+	userController := NewUserController(&MockUserFacadeError{}, testCatalog)
+
+	req, _ := http.NewRequest("GET", "/api/audit", nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	userController.GetAuditRecords(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assertProblem(t, w, problems.CodeAuditListFailed, testMessages.MessageErrorGetAudit)
+}
+
+// Caso de prueba: el borrado definitivo rechaza un valor de hard que no es booleano
+func TestDeleteUserInvalidHard(t *testing.T) {
+	userController := NewUserController(&MockUserFacade{}, testCatalog)
+
+	req, _ := http.NewRequest("DELETE", "/api/users/1?hard=maybe", nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request = req
+
+	userController.DeleteUser(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertProblem(t, w, problems.CodeInvalidQuery, testMessages.MessageErrorQuery)
+}
+
+func conditionalRequest(t *testing.T, run func(*UserController, *gin.Context), method string, body []byte, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	userController := NewUserController(&MockUserFacade{}, testCatalog)
+
+	// Crear una solicitud HTTP simulada
+	req, err := http.NewRequest(method, "/api/users/1", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	// Crear un contexto de Gin con un grabador de respuesta simulado
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request = req
+
+	run(userController, c)
+	return w
+}
+
+func TestGetUserByIdETag(t *testing.T) {
+	// Caso de prueba: la respuesta incluye la versión actual
+	w := conditionalRequest(t, (*UserController).GetSingleUser, "GET", nil, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	// Caso de prueba: el cliente ya tiene la versión actual, con comparación débil
+	for _, ifNoneMatch := range []string{`"3"`, `W/"3"`, `"1", "3"`, `*`} {
+		w = conditionalRequest(t, (*UserController).GetSingleUser, "GET", nil, map[string]string{"If-None-Match": ifNoneMatch})
+		assert.Equal(t, http.StatusNotModified, w.Code, ifNoneMatch)
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+		assert.Empty(t, w.Body.String())
+	}
+
+	// Caso de prueba: la versión del cliente es anterior
+	w = conditionalRequest(t, (*UserController).GetSingleUser, "GET", nil, map[string]string{"If-None-Match": `"2"`})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestUpdateUserIfMatch(t *testing.T) {
+	userIn, _ := json.Marshal(input.UpdateUserIn{Name: "John", LastName: "Doe"})
+
+	// Caso de prueba: sin If-Match, o con la versión actual, se actualiza y se devuelve la nueva versión
+	for _, headers := range []map[string]string{nil, {"If-Match": `"3"`}, {"If-Match": `"2", "3"`}, {"If-Match": "*"}} {
+		w := conditionalRequest(t, (*UserController).UpdateUser, "PUT", userIn, headers)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	}
+
+	// Caso de prueba: versión anterior o etiqueta débil, que nunca coincide en If-Match
+	for _, ifMatch := range []string{`"2"`, `W/"3"`, `invalid`} {
+		w := conditionalRequest(t, (*UserController).UpdateUser, "PUT", userIn, map[string]string{"If-Match": ifMatch})
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code, ifMatch)
+		assertProblem(t, w, problems.CodePreconditionFailed, testMessages.MessageErrorPrecondition)
+	}
+}
+
+func TestIfMatchMultipleHeaders(t *testing.T) {
+	// Crear una solicitud HTTP simulada con If-Match en varias líneas
+	req, err := http.NewRequest("PATCH", "/api/users/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("If-Match", `"1"`)
+	req.Header.Add("If-Match", `W/"2", "3"`)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = req
+
+	// Verificar que se junten las etiquetas fuertes de todas las líneas
+	assert.Equal(t, &input.Precondition{Versions: []uint{1, 3}}, ifMatch(c))
+}
+
+// ---------------------Tests para tiempo límite ---------------------
+func TestGetUserByIdTimeout(t *testing.T) {
+	// Configurar el controlador y la fachada para las pruebas
+	facadeMock := &MockUserFacadeTimeout{}
+	userController := NewUserController(facadeMock, testCatalog)
+
+	// Crear una solicitud HTTP simulada con un tiempo límite muy corto
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", "/api/users/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Crear un contexto de Gin con un grabador de respuesta simulado
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request = req
+
+	// Ejecutar la función de controlador GetSingleUser
+	userController.GetSingleUser(c)
+
+	// Verificar el código de estado y el cuerpo de la respuesta
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assertProblem(t, w, problems.CodeRequestTimeout, testMessages.MessageErrorTimeout)
+}
+
+// ---------------------Tests para errores del dominio ---------------------
+func TestDomainErrorStatus(t *testing.T) {
+	constants := testMessages
+	cases := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{"no encontrado", apperrors.NotFound(errors.New("record not found")), http.StatusNotFound, problems.CodeUserNotFound, constants.MessageErrorUserNotFound},
+		{"conflicto", apperrors.Conflict("name", errors.New("duplicate")), http.StatusConflict, problems.CodeUserConflict, constants.MessageErrorConflict},
+		{"validación", apperrors.Validation("name", errors.New("too long")), http.StatusUnprocessableEntity, problems.CodeValidationFailed, constants.MessageErrorValidation},
+		{"no disponible", apperrors.Unavailable(errors.New("bad connection")), http.StatusServiceUnavailable, problems.CodeServiceUnavailable, constants.MessageErrorUnavailable},
+		{"versión desactualizada", apperrors.PreconditionFailed(errors.New("stale version")), http.StatusPreconditionFailed, problems.CodePreconditionFailed, constants.MessageErrorPrecondition},
+	}
+
+	userIn, _ := json.Marshal(input.UpdateUserIn{Name: "John", LastName: "Doe"})
+	handlers := []struct {
+		method string
+		body   []byte
+		run    func(*UserController, *gin.Context)
+	}{
+		{"GET", nil, (*UserController).GetSingleUser},
+		{"PUT", userIn, (*UserController).UpdateUser},
+		{"DELETE", nil, (*UserController).DeleteUser},
+		{"POST", nil, (*UserController).RestoreUser},
+		{"GET", nil, (*UserController).GetUserVersion},
+	}
+
+	for _, tc := range cases {
+		for _, handler := range handlers {
+			t.Run(tc.name+" "+handler.method, func(t *testing.T) {
+				userController := NewUserController(&MockUserFacadeDomainError{err: tc.err}, testCatalog)
+
+				// Crear una solicitud HTTP simulada
+				req, err := http.NewRequest(handler.method, "/api/users/1", bytes.NewBuffer(handler.body))
+				if err != nil {
+					t.Fatal(err)
+				}
+				req.Header.Set("Content-Type", "application/json")
+
+				// Crear un contexto de Gin con un grabador de respuesta simulado
+				w := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(w)
+				c.Params = gin.Params{{Key: "id", Value: "1"}, {Key: "version", Value: "1"}}
+				c.Request = req
+
+				handler.run(userController, c)
+
+				// Verificar el código de estado y el cuerpo de la respuesta
+				assert.Equal(t, tc.status, w.Code)
+				assertProblem(t, w, tc.code, tc.message)
+			})
+		}
+	}
+}
+
+// Caso de prueba: el conflicto nombra el campo cuyo valor ya está registrado
+func TestConflictNamesField(t *testing.T) {
+	userController := NewUserController(&MockUserFacadeDomainError{err: apperrors.Conflict("email", errors.New("duplicate"))}, testCatalog)
+
+	body, _ := json.Marshal(input.UpdateUserIn{Name: "John", LastName: "Doe", Email: "john@example.com"})
+	req, _ := http.NewRequest("PUT", "/api/users/1", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request = req
+
+	userController.UpdateUser(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	problem := assertProblem(t, w, problems.CodeUserConflict, testMessages.MessageErrorConflict)
+	assert.Equal(t, []output.FieldErrorOut{{Field: "email", Code: "unique", Message: testMessages.Field("unique", "")}}, problem.Errors)
+}
+
+// Caso de prueba: las reglas que incumple el usuario según el servicio se reportan juntas
+func TestServiceViolations(t *testing.T) {
+	violations := apperrors.Violations{{Field: "name", Rule: "required"}, {Field: "last_name", Rule: "max", Param: "100"}, {Field: "phone", Rule: "phone"}}
+	userController := NewUserController(&MockUserFacadeDomainError{err: apperrors.Invalid(violations)}, testCatalog)
+
+	body, _ := json.Marshal(input.CreateUserIn{Name: " ", LastName: "Doe", Phone: "123"})
+	req, _ := http.NewRequest("POST", "/api/users", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	userController.CreateUser(c)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	problem := assertProblem(t, w, problems.CodeValidationFailed, testMessages.MessageErrorValidation)
+	assert.Equal(t, []output.FieldErrorOut{
+		{Field: "name", Code: "required", Message: testMessages.Field("required", "")},
+		{Field: "last_name", Code: "max", Message: testMessages.Field("max", "100")},
+		{Field: "phone", Code: "phone", Message: testMessages.Field("phone", "")},
+	}, problem.Errors)
+}
+
+// ---------------------Tests para idioma de los mensajes ---------------------
+func TestErrorMessagesAcceptLanguage(t *testing.T) {
+	english := testCatalog.Match("en")
+	cases := []struct {
+		acceptLanguage string
+		language       string
+		detail         string
+		field          string
+	}{
+		{"", "es", testMessages.MessageErrorValidation, testMessages.Field("required", "")},
+		{"en-US,en;q=0.9", "en", english.MessageErrorValidation, english.Field("required", "")},
+		{"fr", "es", testMessages.MessageErrorValidation, testMessages.Field("required", "")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.language+" "+tc.acceptLanguage, func(t *testing.T) {
+			userController := NewUserController(&MockUserFacade{}, testCatalog)
+
+			// Crear una solicitud HTTP simulada con un campo faltante
+			req, err := http.NewRequest("POST", "/api/users", bytes.NewBufferString(`{"name": "John"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept-Language", tc.acceptLanguage)
+
+			// Crear un contexto de Gin con un grabador de respuesta simulado
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			userController.CreateUser(c)
+
+			// Verificar el idioma del detalle y de los mensajes por campo
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+			assert.Equal(t, tc.language, w.Header().Get("Content-Language"))
+			problem := assertProblem(t, w, problems.CodeValidationFailed, tc.detail)
+			assert.Equal(t, []output.FieldErrorOut{{Field: "last_name", Code: "required", Message: tc.field}}, problem.Errors)
+		})
+	}
 }
@@ -0,0 +1,10 @@
+package input
+
+import "time"
+
+// APIKeyIn crea una llave de API con los permisos indicados; sin expires_at no vence
+type APIKeyIn struct {
+	Name        string     `json:"name" binding:"required"`
+	Permissions []string   `json:"permissions" binding:"required"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
@@ -1,6 +1,10 @@
 package input
 
 type CreateUserIn struct {
-	Name     string `json:"name" binding:"required"`
-	LastName string `json:"last_name" binding:"required"`
+	Name        string `json:"name" binding:"required"`
+	LastName    string `json:"last_name" binding:"required"`
+	Email       string `json:"email"`
+	Username    string `json:"username"`
+	Phone       string `json:"phone"`
+	DisplayName string `json:"display_name"`
 }
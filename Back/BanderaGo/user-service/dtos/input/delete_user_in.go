@@ -0,0 +1,6 @@
+package input
+
+type DeleteUserIn struct {
+	// Hard elimina definitivamente al usuario en lugar de hacer un borrado lógico
+	Hard bool `form:"hard"`
+}
@@ -0,0 +1,17 @@
+package input
+
+// VerifyEmailIn confirma el correo del usuario con el token del enlace enviado
+type VerifyEmailIn struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ForgotPasswordIn pide el enlace para restablecer la contraseña
+type ForgotPasswordIn struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordIn asigna una contraseña nueva con el token del enlace enviado
+type ResetPasswordIn struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
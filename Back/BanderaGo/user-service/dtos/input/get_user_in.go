@@ -0,0 +1,12 @@
+package input
+
+import "time"
+
+type GetUserIn struct {
+	AsOf *time.Time `form:"as_of" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type DiffUserIn struct {
+	From uint `form:"from" binding:"required,min=1"`
+	To   uint `form:"to" binding:"required,min=1"`
+}
@@ -0,0 +1,12 @@
+package input
+
+import "time"
+
+type ListAuditIn struct {
+	Page      int        `form:"page" binding:"omitempty,min=1"`
+	PageSize  int        `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Actor     string     `form:"actor"`
+	Operation string     `form:"operation" binding:"omitempty,oneof=create update delete restore purge password roles lock unlock verify_email"`
+	From      *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To        *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
@@ -0,0 +1,18 @@
+package input
+
+import "time"
+
+type ListUsersIn struct {
+	Page          int        `form:"page" binding:"omitempty,min=1"`
+	PageSize      int        `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Cursor        string     `form:"cursor"`
+	Sort          string     `form:"sort"`
+	Name          string     `form:"name"`
+	LastName      string     `form:"last_name"`
+	Email         string     `form:"email"`
+	Username      string     `form:"username"`
+	Phone         string     `form:"phone"`
+	CreatedAfter  *time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore *time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Deleted       string     `form:"deleted" binding:"omitempty,oneof=only include"`
+}
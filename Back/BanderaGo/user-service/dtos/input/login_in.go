@@ -0,0 +1,9 @@
+package input
+
+type LoginIn struct {
+	// Login es el correo o el nombre de usuario
+	Login    string `json:"login" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	// OTP es el código TOTP o un código de recuperación; se exige si el usuario tiene MFA
+	OTP string `json:"otp"`
+}
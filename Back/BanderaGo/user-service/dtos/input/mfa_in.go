@@ -0,0 +1,6 @@
+package input
+
+// ConfirmTOTPIn lleva el código que muestra la aplicación de autenticación recién configurada
+type ConfirmTOTPIn struct {
+	Code string `json:"code" binding:"required"`
+}
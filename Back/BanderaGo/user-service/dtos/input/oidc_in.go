@@ -0,0 +1,43 @@
+package input
+
+// OIDCClientIn registra una aplicación que inicia sesión con el proveedor OpenID Connect.
+// Un cliente público, como una SPA o una aplicación móvil, no recibe secreto.
+type OIDCClientIn struct {
+	Name         string   `json:"name" binding:"required"`
+	RedirectURIs []string `json:"redirect_uris" binding:"required"`
+	Public       bool     `json:"public"`
+}
+
+// UpdateOIDCClientIn reemplaza el nombre y las URIs de redirección de un cliente
+type UpdateOIDCClientIn struct {
+	Name         string   `json:"name" binding:"required"`
+	RedirectURIs []string `json:"redirect_uris" binding:"required"`
+}
+
+// AuthorizeIn son los parámetros de la solicitud de autorización (OIDC Core, sección
+// 3.1.2.1). Llegan en la query, en un formulario o en JSON; los valida el servicio para
+// poder informar los errores en la URI de redirección.
+type AuthorizeIn struct {
+	ResponseType        string `form:"response_type" json:"response_type"`
+	ClientID            string `form:"client_id" json:"client_id"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri"`
+	Scope               string `form:"scope" json:"scope"`
+	State               string `form:"state" json:"state"`
+	Nonce               string `form:"nonce" json:"nonce"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+	// Prompt solo admite none, que responde login_required en lugar de pedir el inicio de sesión
+	Prompt string `form:"prompt" json:"prompt"`
+}
+
+// TokenRequestIn es la solicitud al endpoint de tokens (RFC 6749, sección 4.1.3). El
+// cliente se autentica con Basic o con client_id y client_secret en el formulario; un
+// cliente público solo envía client_id.
+type TokenRequestIn struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+	CodeVerifier string `form:"code_verifier"`
+}
@@ -0,0 +1,12 @@
+package input
+
+// SetPasswordIn asigna una contraseña al usuario sin pedir la actual
+type SetPasswordIn struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// ChangePasswordIn cambia la contraseña del usuario comprobando la actual
+type ChangePasswordIn struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
@@ -0,0 +1,15 @@
+package input
+
+// Tipos de documento aceptados por PATCH /api/users/:id
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// PatchUserIn contiene el documento de cambios tal como llegó en el cuerpo y su tipo.
+// El documento se aplica sobre la representación editable del usuario ({"name", "last_name"}).
+type PatchUserIn struct {
+	Type    string
+	Patch   []byte
+	IfMatch *Precondition
+}
@@ -0,0 +1,14 @@
+package input
+
+import "slices"
+
+// Precondition es la condición del encabezado If-Match sobre la versión del usuario.
+// Un valor nulo no exige ninguna versión y Any corresponde a "*".
+type Precondition struct {
+	Any      bool
+	Versions []uint
+}
+
+func (p *Precondition) Matches(version uint) bool {
+	return p == nil || p.Any || slices.Contains(p.Versions, version)
+}
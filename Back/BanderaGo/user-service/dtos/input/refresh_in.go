@@ -0,0 +1,7 @@
+package input
+
+// RefreshIn lleva el refresh token que se renueva o se revoca. Se acepta como JSON o
+// como application/x-www-form-urlencoded.
+type RefreshIn struct {
+	RefreshToken string `json:"refresh_token" form:"refresh_token" binding:"required"`
+}
@@ -0,0 +1,14 @@
+package input
+
+// RoleIn crea un rol con los permisos indicados
+type RoleIn struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// UpdateRoleIn reemplaza la descripción y los permisos de un rol
+type UpdateRoleIn struct {
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
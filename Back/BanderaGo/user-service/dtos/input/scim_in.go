@@ -0,0 +1,63 @@
+package input
+
+import "encoding/json"
+
+// SCIMUserIn es un usuario en el esquema core de SCIM (RFC 7643, sección 4.1). Los
+// atributos que el servicio no guarda, como externalId o password, se ignoran.
+type SCIMUserIn struct {
+	Schemas      []string      `json:"schemas"`
+	UserName     string        `json:"userName"`
+	Name         *SCIMNameIn   `json:"name"`
+	DisplayName  string        `json:"displayName"`
+	Emails       []SCIMValueIn `json:"emails"`
+	PhoneNumbers []SCIMValueIn `json:"phoneNumbers"`
+	// Active nil conserva el estado actual; un usuario nuevo se crea activo
+	Active *bool `json:"active"`
+}
+
+type SCIMNameIn struct {
+	GivenName  string `json:"givenName"`
+	FamilyName string `json:"familyName"`
+}
+
+// SCIMValueIn es un valor de un atributo multivaluado como emails o phoneNumbers. El
+// servicio guarda uno solo: el primario o, si ninguno lo es, el primero.
+type SCIMValueIn struct {
+	Value   string `json:"value"`
+	Type    string `json:"type"`
+	Primary bool   `json:"primary"`
+}
+
+// SCIMGroupIn es un grupo de SCIM, que corresponde a un rol con el mismo nombre
+type SCIMGroupIn struct {
+	Schemas     []string       `json:"schemas"`
+	DisplayName string         `json:"displayName"`
+	Members     []SCIMMemberIn `json:"members"`
+}
+
+// SCIMMemberIn es un miembro de un grupo; Value es el id SCIM del usuario
+type SCIMMemberIn struct {
+	Value string `json:"value"`
+}
+
+// SCIMPatchIn es una solicitud PATCH de SCIM (RFC 7644, sección 3.5.2)
+type SCIMPatchIn struct {
+	Schemas    []string          `json:"schemas"`
+	Operations []SCIMOperationIn `json:"Operations"`
+}
+
+// SCIMOperationIn es una operación add, remove o replace. Sin Path, Value es un objeto
+// cuyos atributos se modifican.
+type SCIMOperationIn struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value" swaggertype:"object"`
+}
+
+// SCIMListIn son los parámetros de una consulta de recursos (RFC 7644, sección 3.4.2).
+// StartIndex empieza en 1 y Count nil usa el tamaño de página por defecto.
+type SCIMListIn struct {
+	Filter     string `form:"filter"`
+	StartIndex int    `form:"startIndex"`
+	Count      *int   `form:"count"`
+}
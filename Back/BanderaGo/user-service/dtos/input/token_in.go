@@ -0,0 +1,12 @@
+package input
+
+// TokenIn es la solicitud de token de OAuth 2.0 con la concesión password. Se acepta
+// como JSON o como application/x-www-form-urlencoded.
+type TokenIn struct {
+	GrantType string `json:"grant_type" form:"grant_type" binding:"required,oneof=password"`
+	// Username es el correo o el nombre de usuario
+	Username string `json:"username" form:"username" binding:"required"`
+	Password string `json:"password" form:"password" binding:"required"`
+	// OTP es el código TOTP o un código de recuperación; se exige si el usuario tiene MFA
+	OTP string `json:"otp" form:"otp"`
+}
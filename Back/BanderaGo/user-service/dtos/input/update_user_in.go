@@ -1,6 +1,12 @@
 package input
 
 type UpdateUserIn struct {
-	Name     string `json:"name" binding:"required"`
-	LastName string `json:"last_name" binding:"required"`
-}
\ No newline at end of file
+	Name        string `json:"name" binding:"required"`
+	LastName    string `json:"last_name" binding:"required"`
+	Email       string `json:"email"`
+	Username    string `json:"username"`
+	Phone       string `json:"phone"`
+	DisplayName string `json:"display_name"`
+
+	IfMatch *Precondition `json:"-"`
+}
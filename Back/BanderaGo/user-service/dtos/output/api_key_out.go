@@ -0,0 +1,21 @@
+package output
+
+import "time"
+
+// APIKeyOut describe una llave de API sin su secreto
+type APIKeyOut struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Permissions []string   `json:"permissions"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedBy   string     `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// APIKeyCreatedOut incluye la llave completa, que solo se muestra al crearla o rotarla
+type APIKeyCreatedOut struct {
+	APIKeyOut
+	Key string `json:"key"`
+}
@@ -3,8 +3,12 @@ package output
 import "time"
 
 type CreateUserOut struct {
-	ID        uint      `json:"id"`
-	Name      string    `json:"name"`
-	LastName  string    `json:"last_name"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	LastName    string    `json:"last_name"`
+	Email       string    `json:"email,omitempty"`
+	Username    string    `json:"username,omitempty"`
+	Phone       string    `json:"phone,omitempty"`
+	DisplayName string    `json:"display_name,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
 }
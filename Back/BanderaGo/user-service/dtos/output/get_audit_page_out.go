@@ -0,0 +1,25 @@
+package output
+
+import "time"
+
+type FieldChangeOut struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type AuditRecordOut struct {
+	ID        uint                      `json:"id"`
+	UserID    uint                      `json:"user_id"`
+	Actor     string                    `json:"actor"`
+	RequestID string                    `json:"request_id"`
+	Operation string                    `json:"operation"`
+	Changes   map[string]FieldChangeOut `json:"changes"`
+	CreatedAt time.Time                 `json:"created_at"`
+}
+
+type GetAuditPageOut struct {
+	Data     []AuditRecordOut `json:"data"`
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+}
@@ -1,7 +1,13 @@
 package output
 
 type GetUserOut struct {
-	ID       uint   `json:"id"`
-	Name     string `json:"name"`
-	LastName string `json:"last_name"`
+	ID            uint   `json:"id"`
+	Name          string `json:"name"`
+	LastName      string `json:"last_name"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"email_verified,omitempty"`
+	Username      string `json:"username,omitempty"`
+	Phone         string `json:"phone,omitempty"`
+	DisplayName   string `json:"display_name,omitempty"`
+	Version       uint   `json:"-"`
 }
@@ -1,7 +1,14 @@
 package output
 
+import "time"
+
 type GetUsersOut struct {
-	ID       uint   `json:"id"`
-	Name     string `json:"name"`
-	LastName string `json:"last_name"`
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	LastName    string     `json:"last_name"`
+	Email       string     `json:"email,omitempty"`
+	Username    string     `json:"username,omitempty"`
+	Phone       string     `json:"phone,omitempty"`
+	DisplayName string     `json:"display_name,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
 }
@@ -0,0 +1,17 @@
+package output
+
+type GetUsersPageOut struct {
+	Data       []GetUsersOut `json:"data"`
+	Total      int64         `json:"total"`
+	Page       int           `json:"page,omitempty"`
+	PageSize   int           `json:"page_size"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+	PrevCursor string        `json:"prev_cursor,omitempty"`
+	Links      PageLinksOut  `json:"links"`
+}
+
+type PageLinksOut struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
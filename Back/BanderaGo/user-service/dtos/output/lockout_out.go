@@ -0,0 +1,10 @@
+package output
+
+import "time"
+
+// LockoutOut describe los intentos fallidos recientes de inicio de sesión de un usuario
+type LockoutOut struct {
+	Locked         bool       `json:"locked"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty"`
+	FailedAttempts int        `json:"failed_attempts"`
+}
@@ -0,0 +1,5 @@
+package output
+
+type LoginOut struct {
+	User GetUserOut `json:"user"`
+}
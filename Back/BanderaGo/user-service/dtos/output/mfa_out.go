@@ -0,0 +1,23 @@
+package output
+
+import "time"
+
+// MFAOut describe el segundo factor del usuario sin revelar el secreto
+type MFAOut struct {
+	Enabled                bool       `json:"enabled"`
+	ConfirmedAt            *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// TOTPEnrollmentOut es la única vez que se devuelve el secreto TOTP. QRCode es una imagen
+// PNG como data URI con el mismo contenido que OTPAuthURI.
+type TOTPEnrollmentOut struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	QRCode     string `json:"qr_code"`
+}
+
+// RecoveryCodesOut es la única vez que se devuelven los códigos de recuperación
+type RecoveryCodesOut struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
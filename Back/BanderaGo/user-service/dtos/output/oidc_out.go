@@ -0,0 +1,92 @@
+package output
+
+import "time"
+
+// DiscoveryOut son los metadatos del proveedor (OpenID Connect Discovery 1.0, sección 3)
+type DiscoveryOut struct {
+	Issuer                                     string   `json:"issuer"`
+	AuthorizationEndpoint                      string   `json:"authorization_endpoint"`
+	TokenEndpoint                              string   `json:"token_endpoint"`
+	UserinfoEndpoint                           string   `json:"userinfo_endpoint"`
+	JWKSURI                                    string   `json:"jwks_uri"`
+	ScopesSupported                            []string `json:"scopes_supported"`
+	ResponseTypesSupported                     []string `json:"response_types_supported"`
+	ResponseModesSupported                     []string `json:"response_modes_supported"`
+	GrantTypesSupported                        []string `json:"grant_types_supported"`
+	SubjectTypesSupported                      []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported           []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported          []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported              []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                            []string `json:"claims_supported"`
+	AuthorizationResponseIssParameterSupported bool     `json:"authorization_response_iss_parameter_supported"`
+}
+
+// AuthorizeOut es la dirección a la que la página de inicio de sesión envía al navegador
+type AuthorizeOut struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// OIDCTokenOut es la respuesta del endpoint de tokens con el ID token (OIDC Core, sección 3.1.3.3)
+type OIDCTokenOut struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	// ExpiresIn son los segundos de vida del token de acceso
+	ExpiresIn int64  `json:"expires_in"`
+	IDToken   string `json:"id_token"`
+	Scope     string `json:"scope"`
+}
+
+// OAuthErrorOut es la respuesta de error del endpoint de tokens (RFC 6749, sección 5.2)
+type OAuthErrorOut struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// UserClaimsOut son los claims estándar del usuario (OIDC Core, sección 5.1); solo se
+// incluyen los de los scopes concedidos
+type UserClaimsOut struct {
+	Name              string `json:"name,omitempty"`
+	GivenName         string `json:"given_name,omitempty"`
+	FamilyName        string `json:"family_name,omitempty"`
+	Nickname          string `json:"nickname,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	// UpdatedAt es la fecha de la última modificación del usuario en segundos Unix
+	UpdatedAt     int64  `json:"updated_at,omitempty"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified *bool  `json:"email_verified,omitempty"`
+	PhoneNumber   string `json:"phone_number,omitempty"`
+}
+
+// UserInfoOut es la respuesta del endpoint userinfo; sub es el ID del usuario
+type UserInfoOut struct {
+	Subject string `json:"sub"`
+	UserClaimsOut
+}
+
+// OIDCClientOut describe un cliente registrado sin su secreto
+type OIDCClientOut struct {
+	ClientID     string    `json:"client_id"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Public       bool      `json:"public"`
+	CreatedBy    string    `json:"created_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// OIDCClientCreatedOut incluye el secreto, que solo se muestra al crear el cliente o
+// rotar el secreto; los clientes públicos no tienen
+type OIDCClientCreatedOut struct {
+	OIDCClientOut
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// SigningKeyOut describe una llave de firma publicada en el JWKS
+type SigningKeyOut struct {
+	Kid       string `json:"kid"`
+	Algorithm string `json:"alg"`
+	// Active indica si es la llave con la que se firman los tokens nuevos
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"created_at"`
+	RetiredAt *time.Time `json:"retired_at,omitempty"`
+}
@@ -0,0 +1,18 @@
+package output
+
+// ProblemOut representa una respuesta de error según RFC 7807 (application/problem+json)
+type ProblemOut struct {
+	Type     string          `json:"type"`
+	Title    string          `json:"title"`
+	Status   int             `json:"status"`
+	Detail   string          `json:"detail,omitempty"`
+	Instance string          `json:"instance,omitempty"`
+	Code     string          `json:"code"`
+	Errors   []FieldErrorOut `json:"errors,omitempty"`
+}
+
+type FieldErrorOut struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,14 @@
+package output
+
+type RoleOut struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+// UserRolesOut son los roles asignados al usuario y los permisos que le otorgan
+type UserRolesOut struct {
+	UserID      uint     `json:"user_id"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+}
@@ -0,0 +1,172 @@
+package output
+
+import "time"
+
+// SCIMUserOut es un usuario en el esquema core de SCIM (RFC 7643, sección 4.1). Un
+// usuario eliminado lógicamente aparece con active en false.
+type SCIMUserOut struct {
+	Schemas      []string        `json:"schemas"`
+	ID           string          `json:"id"`
+	UserName     string          `json:"userName,omitempty"`
+	Name         SCIMNameOut     `json:"name"`
+	DisplayName  string          `json:"displayName,omitempty"`
+	Emails       []SCIMValueOut  `json:"emails,omitempty"`
+	PhoneNumbers []SCIMValueOut  `json:"phoneNumbers,omitempty"`
+	Active       bool            `json:"active"`
+	Groups       []SCIMMemberOut `json:"groups"`
+	Meta         SCIMMetaOut     `json:"meta"`
+
+	// Version se envía en el encabezado ETag
+	Version uint `json:"-"`
+}
+
+type SCIMNameOut struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type SCIMValueOut struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMMemberOut referencia a un grupo del usuario o a un usuario del grupo
+type SCIMMemberOut struct {
+	Value   string `json:"value"`
+	Ref     string `json:"$ref,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+// SCIMMetaOut son los metadatos del recurso; Location es relativa al servicio
+type SCIMMetaOut struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Version      string     `json:"version,omitempty"`
+	Location     string     `json:"location,omitempty"`
+}
+
+// SCIMGroupOut es un grupo de SCIM; su id y displayName son el nombre del rol
+type SCIMGroupOut struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id"`
+	DisplayName string          `json:"displayName"`
+	Members     []SCIMMemberOut `json:"members"`
+	Meta        SCIMMetaOut     `json:"meta"`
+}
+
+// SCIMUserListOut es una página de usuarios (RFC 7644, sección 3.4.2). StartIndex
+// empieza en 1.
+type SCIMUserListOut struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int64         `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []SCIMUserOut `json:"Resources"`
+}
+
+type SCIMGroupListOut struct {
+	Schemas      []string       `json:"schemas"`
+	TotalResults int64          `json:"totalResults"`
+	StartIndex   int            `json:"startIndex"`
+	ItemsPerPage int            `json:"itemsPerPage"`
+	Resources    []SCIMGroupOut `json:"Resources"`
+}
+
+// SCIMErrorOut es la respuesta de error de SCIM (RFC 7644, sección 3.12); Status es el
+// código HTTP como texto
+type SCIMErrorOut struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// SCIMServiceProviderConfigOut describe las funciones de SCIM que admite el servicio
+// (RFC 7643, sección 5)
+type SCIMServiceProviderConfigOut struct {
+	Schemas               []string                      `json:"schemas"`
+	Patch                 SCIMSupportedOut              `json:"patch"`
+	Bulk                  SCIMBulkOut                   `json:"bulk"`
+	Filter                SCIMFilterOut                 `json:"filter"`
+	ChangePassword        SCIMSupportedOut              `json:"changePassword"`
+	Sort                  SCIMSupportedOut              `json:"sort"`
+	ETag                  SCIMSupportedOut              `json:"etag"`
+	AuthenticationSchemes []SCIMAuthenticationSchemeOut `json:"authenticationSchemes"`
+	Meta                  SCIMMetaOut                   `json:"meta"`
+}
+
+type SCIMSupportedOut struct {
+	Supported bool `json:"supported"`
+}
+
+type SCIMBulkOut struct {
+	Supported      bool `json:"supported"`
+	MaxOperations  int  `json:"maxOperations"`
+	MaxPayloadSize int  `json:"maxPayloadSize"`
+}
+
+type SCIMFilterOut struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+type SCIMAuthenticationSchemeOut struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Primary     bool   `json:"primary"`
+}
+
+// SCIMResourceTypeOut describe un tipo de recurso y su esquema (RFC 7643, sección 6)
+type SCIMResourceTypeOut struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Endpoint    string      `json:"endpoint"`
+	Description string      `json:"description"`
+	Schema      string      `json:"schema"`
+	Meta        SCIMMetaOut `json:"meta"`
+}
+
+type SCIMResourceTypeListOut struct {
+	Schemas      []string              `json:"schemas"`
+	TotalResults int64                 `json:"totalResults"`
+	StartIndex   int                   `json:"startIndex"`
+	ItemsPerPage int                   `json:"itemsPerPage"`
+	Resources    []SCIMResourceTypeOut `json:"Resources"`
+}
+
+// SCIMSchemaOut describe los atributos de un esquema (RFC 7643, sección 7)
+type SCIMSchemaOut struct {
+	Schemas     []string           `json:"schemas"`
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Attributes  []SCIMAttributeOut `json:"attributes"`
+	Meta        SCIMMetaOut        `json:"meta"`
+}
+
+type SCIMAttributeOut struct {
+	Name           string             `json:"name"`
+	Type           string             `json:"type"`
+	MultiValued    bool               `json:"multiValued"`
+	Description    string             `json:"description"`
+	Required       bool               `json:"required"`
+	CaseExact      bool               `json:"caseExact"`
+	Mutability     string             `json:"mutability"`
+	Returned       string             `json:"returned"`
+	Uniqueness     string             `json:"uniqueness"`
+	ReferenceTypes []string           `json:"referenceTypes,omitempty"`
+	SubAttributes  []SCIMAttributeOut `json:"subAttributes,omitempty"`
+}
+
+type SCIMSchemaListOut struct {
+	Schemas      []string        `json:"schemas"`
+	TotalResults int64           `json:"totalResults"`
+	StartIndex   int             `json:"startIndex"`
+	ItemsPerPage int             `json:"itemsPerPage"`
+	Resources    []SCIMSchemaOut `json:"Resources"`
+}
@@ -0,0 +1,15 @@
+package output
+
+import "time"
+
+// SessionOut describe una sesión activa y el cliente que la usó por última vez
+type SessionOut struct {
+	ID         uint      `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// Current indica que es la sesión del token con el que se hace la solicitud
+	Current bool `json:"current"`
+}
@@ -0,0 +1,11 @@
+package output
+
+// TokenOut es la respuesta de token de OAuth 2.0 (RFC 6749, sección 5.1)
+type TokenOut struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	// ExpiresIn son los segundos de vida del token
+	ExpiresIn int64 `json:"expires_in"`
+	// RefreshToken renueva el token de acceso una sola vez; cada renovación devuelve otro
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
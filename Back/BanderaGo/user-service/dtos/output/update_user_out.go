@@ -3,8 +3,13 @@ package output
 import "time"
 
 type UpdateUserOut struct {
-	ID        uint      `json:"id"`
-	Name      string    `json:"name"`
-	LastName  string    `json:"last_name"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	LastName    string    `json:"last_name"`
+	Email       string    `json:"email,omitempty"`
+	Username    string    `json:"username,omitempty"`
+	Phone       string    `json:"phone,omitempty"`
+	DisplayName string    `json:"display_name,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Version     uint      `json:"-"`
 }
@@ -0,0 +1,23 @@
+package output
+
+import "time"
+
+type UserVersionOut struct {
+	ID          uint       `json:"id"`
+	Version     uint       `json:"version"`
+	Name        string     `json:"name"`
+	LastName    string     `json:"last_name"`
+	Email       string     `json:"email,omitempty"`
+	Username    string     `json:"username,omitempty"`
+	Phone       string     `json:"phone,omitempty"`
+	DisplayName string     `json:"display_name,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	ValidFrom   time.Time  `json:"valid_from"`
+}
+
+type UserDiffOut struct {
+	ID      uint                      `json:"id"`
+	From    uint                      `json:"from"`
+	To      uint                      `json:"to"`
+	Changes map[string]FieldChangeOut `json:"changes"`
+}
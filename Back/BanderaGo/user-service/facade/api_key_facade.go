@@ -0,0 +1,15 @@
+package facade
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"context"
+)
+
+type APIKeyFacade interface {
+	GetAPIKeys(ctx context.Context) ([]output.APIKeyOut, error)
+	GetAPIKey(ctx context.Context, id uint) (output.APIKeyOut, error)
+	CreateAPIKey(ctx context.Context, apiKeyIn input.APIKeyIn) (output.APIKeyCreatedOut, error)
+	RotateAPIKey(ctx context.Context, id uint) (output.APIKeyCreatedOut, error)
+	RevokeAPIKey(ctx context.Context, id uint) error
+}
@@ -0,0 +1,36 @@
+package impl
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/services"
+	"context"
+)
+
+type APIKeyFacadeImpl struct {
+	APIKeyService services.APIKeyService
+}
+
+func NewAPIKeyFacade(service services.APIKeyService) *APIKeyFacadeImpl {
+	return &APIKeyFacadeImpl{APIKeyService: service}
+}
+
+func (f *APIKeyFacadeImpl) GetAPIKeys(ctx context.Context) ([]output.APIKeyOut, error) {
+	return f.APIKeyService.GetAPIKeys(ctx)
+}
+
+func (f *APIKeyFacadeImpl) GetAPIKey(ctx context.Context, id uint) (output.APIKeyOut, error) {
+	return f.APIKeyService.GetAPIKey(ctx, id)
+}
+
+func (f *APIKeyFacadeImpl) CreateAPIKey(ctx context.Context, apiKeyIn input.APIKeyIn) (output.APIKeyCreatedOut, error) {
+	return f.APIKeyService.CreateAPIKey(ctx, apiKeyIn)
+}
+
+func (f *APIKeyFacadeImpl) RotateAPIKey(ctx context.Context, id uint) (output.APIKeyCreatedOut, error) {
+	return f.APIKeyService.RotateAPIKey(ctx, id)
+}
+
+func (f *APIKeyFacadeImpl) RevokeAPIKey(ctx context.Context, id uint) error {
+	return f.APIKeyService.RevokeAPIKey(ctx, id)
+}
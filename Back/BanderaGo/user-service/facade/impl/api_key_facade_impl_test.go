@@ -0,0 +1,74 @@
+package impl
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/requestctx"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock de APIKeyService para pruebas
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) GetAPIKeys(ctx context.Context) ([]output.APIKeyOut, error) {
+	args := m.Called()
+	return args.Get(0).([]output.APIKeyOut), args.Error(1)
+}
+
+func (m *MockAPIKeyService) GetAPIKey(ctx context.Context, id uint) (output.APIKeyOut, error) {
+	args := m.Called(id)
+	return args.Get(0).(output.APIKeyOut), args.Error(1)
+}
+
+func (m *MockAPIKeyService) CreateAPIKey(ctx context.Context, apiKeyIn input.APIKeyIn) (output.APIKeyCreatedOut, error) {
+	args := m.Called(apiKeyIn)
+	return args.Get(0).(output.APIKeyCreatedOut), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RotateAPIKey(ctx context.Context, id uint) (output.APIKeyCreatedOut, error) {
+	args := m.Called(id)
+	return args.Get(0).(output.APIKeyCreatedOut), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RevokeAPIKey(ctx context.Context, id uint) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockAPIKeyService) VerifyAPIKey(ctx context.Context, key string) (requestctx.Identity, error) {
+	args := m.Called(key)
+	return args.Get(0).(requestctx.Identity), args.Error(1)
+}
+
+func TestAPIKeyFacadeDelegates(t *testing.T) {
+	mockAPIKeyService := new(MockAPIKeyService)
+	apiKeyFacade := NewAPIKeyFacade(mockAPIKeyService)
+	ctx := context.Background()
+
+	apiKeyIn := input.APIKeyIn{Name: "batch", Permissions: []string{"users:read"}}
+	mockAPIKeyService.On("GetAPIKeys").Return([]output.APIKeyOut{{ID: 1}}, nil)
+	mockAPIKeyService.On("GetAPIKey", uint(1)).Return(output.APIKeyOut{ID: 1}, nil)
+	mockAPIKeyService.On("CreateAPIKey", apiKeyIn).Return(output.APIKeyCreatedOut{Key: "usk_a"}, nil)
+	mockAPIKeyService.On("RotateAPIKey", uint(1)).Return(output.APIKeyCreatedOut{Key: "usk_b"}, nil)
+	mockAPIKeyService.On("RevokeAPIKey", uint(1)).Return(nil)
+
+	keys, err := apiKeyFacade.GetAPIKeys(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+	key, err := apiKeyFacade.GetAPIKey(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), key.ID)
+	created, err := apiKeyFacade.CreateAPIKey(ctx, apiKeyIn)
+	assert.NoError(t, err)
+	assert.Equal(t, "usk_a", created.Key)
+	rotated, err := apiKeyFacade.RotateAPIKey(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "usk_b", rotated.Key)
+	assert.NoError(t, apiKeyFacade.RevokeAPIKey(ctx, 1))
+	mockAPIKeyService.AssertExpectations(t)
+}
@@ -0,0 +1,48 @@
+package impl
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/services"
+	"context"
+)
+
+type AuthFacadeImpl struct {
+	AuthService services.AuthService
+}
+
+func NewAuthFacade(service services.AuthService) *AuthFacadeImpl {
+	return &AuthFacadeImpl{AuthService: service}
+}
+
+func (f *AuthFacadeImpl) SetPassword(ctx context.Context, id uint, passwordIn input.SetPasswordIn) error {
+	return f.AuthService.SetPassword(ctx, id, passwordIn)
+}
+
+func (f *AuthFacadeImpl) ChangePassword(ctx context.Context, id uint, passwordIn input.ChangePasswordIn) error {
+	return f.AuthService.ChangePassword(ctx, id, passwordIn)
+}
+
+func (f *AuthFacadeImpl) Login(ctx context.Context, loginIn input.LoginIn) (output.LoginOut, error) {
+	return f.AuthService.Login(ctx, loginIn)
+}
+
+func (f *AuthFacadeImpl) IssueToken(ctx context.Context, tokenIn input.TokenIn) (output.TokenOut, error) {
+	return f.AuthService.IssueToken(ctx, tokenIn)
+}
+
+func (f *AuthFacadeImpl) Refresh(ctx context.Context, refreshIn input.RefreshIn) (output.TokenOut, error) {
+	return f.AuthService.Refresh(ctx, refreshIn)
+}
+
+func (f *AuthFacadeImpl) Logout(ctx context.Context, refreshIn input.RefreshIn) error {
+	return f.AuthService.Logout(ctx, refreshIn)
+}
+
+func (f *AuthFacadeImpl) GetLockout(ctx context.Context, userID uint) (output.LockoutOut, error) {
+	return f.AuthService.GetLockout(ctx, userID)
+}
+
+func (f *AuthFacadeImpl) Unlock(ctx context.Context, userID uint) error {
+	return f.AuthService.Unlock(ctx, userID)
+}
@@ -0,0 +1,87 @@
+package impl
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock de AuthService para pruebas
+type MockAuthService struct {
+	mock.Mock
+}
+
+func (m *MockAuthService) SetPassword(ctx context.Context, id uint, passwordIn input.SetPasswordIn) error {
+	return m.Called(id, passwordIn).Error(0)
+}
+
+func (m *MockAuthService) ChangePassword(ctx context.Context, id uint, passwordIn input.ChangePasswordIn) error {
+	return m.Called(id, passwordIn).Error(0)
+}
+
+func (m *MockAuthService) Login(ctx context.Context, loginIn input.LoginIn) (output.LoginOut, error) {
+	args := m.Called(loginIn)
+	return args.Get(0).(output.LoginOut), args.Error(1)
+}
+
+func (m *MockAuthService) IssueToken(ctx context.Context, tokenIn input.TokenIn) (output.TokenOut, error) {
+	args := m.Called(tokenIn)
+	return args.Get(0).(output.TokenOut), args.Error(1)
+}
+
+func (m *MockAuthService) Refresh(ctx context.Context, refreshIn input.RefreshIn) (output.TokenOut, error) {
+	args := m.Called(refreshIn)
+	return args.Get(0).(output.TokenOut), args.Error(1)
+}
+
+func (m *MockAuthService) Logout(ctx context.Context, refreshIn input.RefreshIn) error {
+	args := m.Called(refreshIn)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) GetLockout(ctx context.Context, userID uint) (output.LockoutOut, error) {
+	args := m.Called(userID)
+	return args.Get(0).(output.LockoutOut), args.Error(1)
+}
+
+func (m *MockAuthService) Unlock(ctx context.Context, userID uint) error {
+	return m.Called(userID).Error(0)
+}
+
+func TestAuthFacadeDelegates(t *testing.T) {
+	mockAuthService := new(MockAuthService)
+	authFacade := NewAuthFacade(mockAuthService)
+	ctx := context.Background()
+
+	loginIn := input.LoginIn{Login: "ana", Password: "caballo correcto"}
+	mockAuthService.On("SetPassword", uint(1), input.SetPasswordIn{Password: "caballo correcto"}).Return(nil)
+	mockAuthService.On("ChangePassword", uint(1), mock.Anything).Return(nil)
+	mockAuthService.On("Login", loginIn).Return(output.LoginOut{User: output.GetUserOut{ID: 1}}, nil)
+	mockAuthService.On("IssueToken", mock.Anything).Return(output.TokenOut{AccessToken: "abc"}, nil)
+	mockAuthService.On("Refresh", input.RefreshIn{RefreshToken: "r1"}).Return(output.TokenOut{AccessToken: "def", RefreshToken: "r2"}, nil)
+	mockAuthService.On("Logout", input.RefreshIn{RefreshToken: "r2"}).Return(nil)
+	mockAuthService.On("GetLockout", uint(1)).Return(output.LockoutOut{FailedAttempts: 2}, nil)
+	mockAuthService.On("Unlock", uint(1)).Return(nil)
+
+	assert.NoError(t, authFacade.SetPassword(ctx, 1, input.SetPasswordIn{Password: "caballo correcto"}))
+	assert.NoError(t, authFacade.ChangePassword(ctx, 1, input.ChangePasswordIn{CurrentPassword: "a", NewPassword: "b"}))
+	loginOut, err := authFacade.Login(ctx, loginIn)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), loginOut.User.ID)
+	tokenOut, err := authFacade.IssueToken(ctx, input.TokenIn{GrantType: "password", Username: "ana", Password: "caballo correcto"})
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", tokenOut.AccessToken)
+	tokenOut, err = authFacade.Refresh(ctx, input.RefreshIn{RefreshToken: "r1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "r2", tokenOut.RefreshToken)
+	assert.NoError(t, authFacade.Logout(ctx, input.RefreshIn{RefreshToken: "r2"}))
+	lockoutOut, err := authFacade.GetLockout(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, lockoutOut.FailedAttempts)
+	assert.NoError(t, authFacade.Unlock(ctx, 1))
+	mockAuthService.AssertExpectations(t)
+}
@@ -0,0 +1,31 @@
+package impl
+
+import (
+	"application/dtos/input"
+	"application/services"
+	"context"
+)
+
+type EmailFacadeImpl struct {
+	EmailService services.EmailService
+}
+
+func NewEmailFacade(service services.EmailService) *EmailFacadeImpl {
+	return &EmailFacadeImpl{EmailService: service}
+}
+
+func (f *EmailFacadeImpl) RequestVerification(ctx context.Context, userID uint) error {
+	return f.EmailService.RequestVerification(ctx, userID)
+}
+
+func (f *EmailFacadeImpl) VerifyEmail(ctx context.Context, verifyIn input.VerifyEmailIn) error {
+	return f.EmailService.VerifyEmail(ctx, verifyIn)
+}
+
+func (f *EmailFacadeImpl) RequestPasswordReset(ctx context.Context, forgotIn input.ForgotPasswordIn) error {
+	return f.EmailService.RequestPasswordReset(ctx, forgotIn)
+}
+
+func (f *EmailFacadeImpl) ResetPassword(ctx context.Context, resetIn input.ResetPasswordIn) error {
+	return f.EmailService.ResetPassword(ctx, resetIn)
+}
@@ -0,0 +1,49 @@
+package impl
+
+import (
+	"application/dtos/input"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock de EmailService para pruebas
+type MockEmailService struct {
+	mock.Mock
+}
+
+func (m *MockEmailService) RequestVerification(ctx context.Context, userID uint) error {
+	return m.Called(userID).Error(0)
+}
+
+func (m *MockEmailService) VerifyEmail(ctx context.Context, verifyIn input.VerifyEmailIn) error {
+	return m.Called(verifyIn).Error(0)
+}
+
+func (m *MockEmailService) RequestPasswordReset(ctx context.Context, forgotIn input.ForgotPasswordIn) error {
+	return m.Called(forgotIn).Error(0)
+}
+
+func (m *MockEmailService) ResetPassword(ctx context.Context, resetIn input.ResetPasswordIn) error {
+	return m.Called(resetIn).Error(0)
+}
+
+func TestEmailFacadeDelegates(t *testing.T) {
+	mockEmailService := new(MockEmailService)
+	emailFacade := NewEmailFacade(mockEmailService)
+	ctx := context.Background()
+	resetIn := input.ResetPasswordIn{Token: "abc.def", Password: "otra clave segura 123"}
+
+	mockEmailService.On("RequestVerification", uint(1)).Return(nil)
+	mockEmailService.On("VerifyEmail", input.VerifyEmailIn{Token: "abc.def"}).Return(nil)
+	mockEmailService.On("RequestPasswordReset", input.ForgotPasswordIn{Email: "ana@example.com"}).Return(nil)
+	mockEmailService.On("ResetPassword", resetIn).Return(nil)
+
+	assert.NoError(t, emailFacade.RequestVerification(ctx, 1))
+	assert.NoError(t, emailFacade.VerifyEmail(ctx, input.VerifyEmailIn{Token: "abc.def"}))
+	assert.NoError(t, emailFacade.RequestPasswordReset(ctx, input.ForgotPasswordIn{Email: "ana@example.com"}))
+	assert.NoError(t, emailFacade.ResetPassword(ctx, resetIn))
+	mockEmailService.AssertExpectations(t)
+}
@@ -0,0 +1,36 @@
+package impl
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/services"
+	"context"
+)
+
+type MFAFacadeImpl struct {
+	MFAService services.MFAService
+}
+
+func NewMFAFacade(service services.MFAService) *MFAFacadeImpl {
+	return &MFAFacadeImpl{MFAService: service}
+}
+
+func (f *MFAFacadeImpl) GetMFA(ctx context.Context, userID uint) (output.MFAOut, error) {
+	return f.MFAService.GetMFA(ctx, userID)
+}
+
+func (f *MFAFacadeImpl) EnrollTOTP(ctx context.Context, userID uint) (output.TOTPEnrollmentOut, error) {
+	return f.MFAService.EnrollTOTP(ctx, userID)
+}
+
+func (f *MFAFacadeImpl) ConfirmTOTP(ctx context.Context, userID uint, confirmIn input.ConfirmTOTPIn) (output.RecoveryCodesOut, error) {
+	return f.MFAService.ConfirmTOTP(ctx, userID, confirmIn)
+}
+
+func (f *MFAFacadeImpl) RegenerateRecoveryCodes(ctx context.Context, userID uint) (output.RecoveryCodesOut, error) {
+	return f.MFAService.RegenerateRecoveryCodes(ctx, userID)
+}
+
+func (f *MFAFacadeImpl) DisableMFA(ctx context.Context, userID uint) error {
+	return f.MFAService.DisableMFA(ctx, userID)
+}
@@ -0,0 +1,69 @@
+package impl
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock de MFAService para pruebas
+type MockMFAService struct {
+	mock.Mock
+}
+
+func (m *MockMFAService) GetMFA(ctx context.Context, userID uint) (output.MFAOut, error) {
+	args := m.Called(userID)
+	return args.Get(0).(output.MFAOut), args.Error(1)
+}
+
+func (m *MockMFAService) EnrollTOTP(ctx context.Context, userID uint) (output.TOTPEnrollmentOut, error) {
+	args := m.Called(userID)
+	return args.Get(0).(output.TOTPEnrollmentOut), args.Error(1)
+}
+
+func (m *MockMFAService) ConfirmTOTP(ctx context.Context, userID uint, confirmIn input.ConfirmTOTPIn) (output.RecoveryCodesOut, error) {
+	args := m.Called(userID, confirmIn)
+	return args.Get(0).(output.RecoveryCodesOut), args.Error(1)
+}
+
+func (m *MockMFAService) RegenerateRecoveryCodes(ctx context.Context, userID uint) (output.RecoveryCodesOut, error) {
+	args := m.Called(userID)
+	return args.Get(0).(output.RecoveryCodesOut), args.Error(1)
+}
+
+func (m *MockMFAService) DisableMFA(ctx context.Context, userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func TestMFAFacadeDelegates(t *testing.T) {
+	mockMFAService := new(MockMFAService)
+	mfaFacade := NewMFAFacade(mockMFAService)
+	ctx := context.Background()
+	codes := output.RecoveryCodesOut{RecoveryCodes: []string{"abcd-efgh"}}
+
+	mockMFAService.On("GetMFA", uint(1)).Return(output.MFAOut{Enabled: true}, nil)
+	mockMFAService.On("EnrollTOTP", uint(1)).Return(output.TOTPEnrollmentOut{Secret: "JBSWY3DPEHPK3PXP"}, nil)
+	mockMFAService.On("ConfirmTOTP", uint(1), input.ConfirmTOTPIn{Code: "123456"}).Return(codes, nil)
+	mockMFAService.On("RegenerateRecoveryCodes", uint(1)).Return(codes, nil)
+	mockMFAService.On("DisableMFA", uint(1)).Return(nil)
+
+	mfaOut, err := mfaFacade.GetMFA(ctx, 1)
+	assert.NoError(t, err)
+	assert.True(t, mfaOut.Enabled)
+	enrollment, err := mfaFacade.EnrollTOTP(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", enrollment.Secret)
+	confirmed, err := mfaFacade.ConfirmTOTP(ctx, 1, input.ConfirmTOTPIn{Code: "123456"})
+	assert.NoError(t, err)
+	assert.Equal(t, codes, confirmed)
+	regenerated, err := mfaFacade.RegenerateRecoveryCodes(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, codes, regenerated)
+	assert.NoError(t, mfaFacade.DisableMFA(ctx, 1))
+	mockMFAService.AssertExpectations(t)
+}
@@ -0,0 +1,40 @@
+package impl
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/services"
+	"context"
+)
+
+type OIDCClientFacadeImpl struct {
+	OIDCClientService services.OIDCClientService
+}
+
+func NewOIDCClientFacade(service services.OIDCClientService) *OIDCClientFacadeImpl {
+	return &OIDCClientFacadeImpl{OIDCClientService: service}
+}
+
+func (f *OIDCClientFacadeImpl) GetClients(ctx context.Context) ([]output.OIDCClientOut, error) {
+	return f.OIDCClientService.GetClients(ctx)
+}
+
+func (f *OIDCClientFacadeImpl) GetClient(ctx context.Context, id string) (output.OIDCClientOut, error) {
+	return f.OIDCClientService.GetClient(ctx, id)
+}
+
+func (f *OIDCClientFacadeImpl) CreateClient(ctx context.Context, clientIn input.OIDCClientIn) (output.OIDCClientCreatedOut, error) {
+	return f.OIDCClientService.CreateClient(ctx, clientIn)
+}
+
+func (f *OIDCClientFacadeImpl) UpdateClient(ctx context.Context, id string, clientIn input.UpdateOIDCClientIn) (output.OIDCClientOut, error) {
+	return f.OIDCClientService.UpdateClient(ctx, id, clientIn)
+}
+
+func (f *OIDCClientFacadeImpl) RotateClientSecret(ctx context.Context, id string) (output.OIDCClientCreatedOut, error) {
+	return f.OIDCClientService.RotateClientSecret(ctx, id)
+}
+
+func (f *OIDCClientFacadeImpl) DeleteClient(ctx context.Context, id string) error {
+	return f.OIDCClientService.DeleteClient(ctx, id)
+}
@@ -0,0 +1,80 @@
+package impl
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock de OIDCClientService para pruebas
+type MockOIDCClientService struct {
+	mock.Mock
+}
+
+func (m *MockOIDCClientService) GetClients(ctx context.Context) ([]output.OIDCClientOut, error) {
+	args := m.Called()
+	return args.Get(0).([]output.OIDCClientOut), args.Error(1)
+}
+
+func (m *MockOIDCClientService) GetClient(ctx context.Context, id string) (output.OIDCClientOut, error) {
+	args := m.Called(id)
+	return args.Get(0).(output.OIDCClientOut), args.Error(1)
+}
+
+func (m *MockOIDCClientService) CreateClient(ctx context.Context, clientIn input.OIDCClientIn) (output.OIDCClientCreatedOut, error) {
+	args := m.Called(clientIn)
+	return args.Get(0).(output.OIDCClientCreatedOut), args.Error(1)
+}
+
+func (m *MockOIDCClientService) UpdateClient(ctx context.Context, id string, clientIn input.UpdateOIDCClientIn) (output.OIDCClientOut, error) {
+	args := m.Called(id, clientIn)
+	return args.Get(0).(output.OIDCClientOut), args.Error(1)
+}
+
+func (m *MockOIDCClientService) RotateClientSecret(ctx context.Context, id string) (output.OIDCClientCreatedOut, error) {
+	args := m.Called(id)
+	return args.Get(0).(output.OIDCClientCreatedOut), args.Error(1)
+}
+
+func (m *MockOIDCClientService) DeleteClient(ctx context.Context, id string) error {
+	return m.Called(id).Error(0)
+}
+
+func TestOIDCClientFacadeDelegates(t *testing.T) {
+	mockOIDCClientService := new(MockOIDCClientService)
+	oidcClientFacade := NewOIDCClientFacade(mockOIDCClientService)
+	ctx := context.Background()
+	clientIn := input.OIDCClientIn{Name: "Portal", RedirectURIs: []string{"https://portal.example.com/callback"}}
+	updateIn := input.UpdateOIDCClientIn{Name: "Portal interno", RedirectURIs: clientIn.RedirectURIs}
+	clientOut := output.OIDCClientOut{ClientID: "portal", Name: "Portal"}
+	createdOut := output.OIDCClientCreatedOut{OIDCClientOut: clientOut, ClientSecret: "secreto"}
+
+	mockOIDCClientService.On("GetClients").Return([]output.OIDCClientOut{clientOut}, nil)
+	mockOIDCClientService.On("GetClient", "portal").Return(clientOut, nil)
+	mockOIDCClientService.On("CreateClient", clientIn).Return(createdOut, nil)
+	mockOIDCClientService.On("UpdateClient", "portal", updateIn).Return(clientOut, nil)
+	mockOIDCClientService.On("RotateClientSecret", "portal").Return(createdOut, nil)
+	mockOIDCClientService.On("DeleteClient", "portal").Return(nil)
+
+	clients, err := oidcClientFacade.GetClients(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []output.OIDCClientOut{clientOut}, clients)
+	client, err := oidcClientFacade.GetClient(ctx, "portal")
+	assert.NoError(t, err)
+	assert.Equal(t, clientOut, client)
+	created, err := oidcClientFacade.CreateClient(ctx, clientIn)
+	assert.NoError(t, err)
+	assert.Equal(t, createdOut, created)
+	updated, err := oidcClientFacade.UpdateClient(ctx, "portal", updateIn)
+	assert.NoError(t, err)
+	assert.Equal(t, clientOut, updated)
+	rotated, err := oidcClientFacade.RotateClientSecret(ctx, "portal")
+	assert.NoError(t, err)
+	assert.Equal(t, "secreto", rotated.ClientSecret)
+	assert.NoError(t, oidcClientFacade.DeleteClient(ctx, "portal"))
+	mockOIDCClientService.AssertExpectations(t)
+}
@@ -0,0 +1,45 @@
+package impl
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/services"
+	"application/tokens"
+	"context"
+)
+
+type OIDCFacadeImpl struct {
+	OIDCService services.OIDCService
+}
+
+func NewOIDCFacade(service services.OIDCService) *OIDCFacadeImpl {
+	return &OIDCFacadeImpl{OIDCService: service}
+}
+
+func (f *OIDCFacadeImpl) Discovery() output.DiscoveryOut {
+	return f.OIDCService.Discovery()
+}
+
+func (f *OIDCFacadeImpl) Authorize(ctx context.Context, authorizeIn input.AuthorizeIn) (string, error) {
+	return f.OIDCService.Authorize(ctx, authorizeIn)
+}
+
+func (f *OIDCFacadeImpl) Token(ctx context.Context, tokenIn input.TokenRequestIn) (output.OIDCTokenOut, error) {
+	return f.OIDCService.Token(ctx, tokenIn)
+}
+
+func (f *OIDCFacadeImpl) UserInfo(ctx context.Context, accessToken string) (output.UserInfoOut, error) {
+	return f.OIDCService.UserInfo(ctx, accessToken)
+}
+
+func (f *OIDCFacadeImpl) PublicKeys(ctx context.Context) (tokens.JWKS, error) {
+	return f.OIDCService.PublicKeys(ctx)
+}
+
+func (f *OIDCFacadeImpl) GetSigningKeys(ctx context.Context) ([]output.SigningKeyOut, error) {
+	return f.OIDCService.GetSigningKeys(ctx)
+}
+
+func (f *OIDCFacadeImpl) RotateSigningKey(ctx context.Context) (output.SigningKeyOut, error) {
+	return f.OIDCService.RotateSigningKey(ctx)
+}
@@ -0,0 +1,95 @@
+package impl
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/tokens"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock de OIDCService para pruebas
+type MockOIDCService struct {
+	mock.Mock
+}
+
+func (m *MockOIDCService) Discovery() output.DiscoveryOut {
+	return m.Called().Get(0).(output.DiscoveryOut)
+}
+
+func (m *MockOIDCService) Authorize(ctx context.Context, authorizeIn input.AuthorizeIn) (string, error) {
+	args := m.Called(authorizeIn)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockOIDCService) Token(ctx context.Context, tokenIn input.TokenRequestIn) (output.OIDCTokenOut, error) {
+	args := m.Called(tokenIn)
+	return args.Get(0).(output.OIDCTokenOut), args.Error(1)
+}
+
+func (m *MockOIDCService) UserInfo(ctx context.Context, accessToken string) (output.UserInfoOut, error) {
+	args := m.Called(accessToken)
+	return args.Get(0).(output.UserInfoOut), args.Error(1)
+}
+
+func (m *MockOIDCService) PublicKeys(ctx context.Context) (tokens.JWKS, error) {
+	args := m.Called()
+	return args.Get(0).(tokens.JWKS), args.Error(1)
+}
+
+func (m *MockOIDCService) GetSigningKeys(ctx context.Context) ([]output.SigningKeyOut, error) {
+	args := m.Called()
+	return args.Get(0).([]output.SigningKeyOut), args.Error(1)
+}
+
+func (m *MockOIDCService) RotateSigningKey(ctx context.Context) (output.SigningKeyOut, error) {
+	args := m.Called()
+	return args.Get(0).(output.SigningKeyOut), args.Error(1)
+}
+
+func (m *MockOIDCService) RotateExpiredSigningKey(ctx context.Context, maxAge time.Duration) (bool, error) {
+	args := m.Called(maxAge)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestOIDCFacadeDelegates(t *testing.T) {
+	mockOIDCService := new(MockOIDCService)
+	oidcFacade := NewOIDCFacade(mockOIDCService)
+	ctx := context.Background()
+	authorizeIn := input.AuthorizeIn{ClientID: "portal"}
+	tokenIn := input.TokenRequestIn{GrantType: "authorization_code", Code: "abc"}
+	keys := []output.SigningKeyOut{{Kid: "k1", Active: true}}
+
+	mockOIDCService.On("Discovery").Return(output.DiscoveryOut{Issuer: "https://sso.example.com"})
+	mockOIDCService.On("Authorize", authorizeIn).Return("https://portal.example.com/callback?code=abc", nil)
+	mockOIDCService.On("Token", tokenIn).Return(output.OIDCTokenOut{AccessToken: "at"}, nil)
+	mockOIDCService.On("UserInfo", "at").Return(output.UserInfoOut{Subject: "1"}, nil)
+	mockOIDCService.On("PublicKeys").Return(tokens.JWKS{Keys: []tokens.JWK{{Kid: "k1"}}}, nil)
+	mockOIDCService.On("GetSigningKeys").Return(keys, nil)
+	mockOIDCService.On("RotateSigningKey").Return(output.SigningKeyOut{Kid: "k2"}, nil)
+
+	assert.Equal(t, "https://sso.example.com", oidcFacade.Discovery().Issuer)
+	redirectTo, err := oidcFacade.Authorize(ctx, authorizeIn)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://portal.example.com/callback?code=abc", redirectTo)
+	tokenOut, err := oidcFacade.Token(ctx, tokenIn)
+	assert.NoError(t, err)
+	assert.Equal(t, "at", tokenOut.AccessToken)
+	userInfo, err := oidcFacade.UserInfo(ctx, "at")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", userInfo.Subject)
+	set, err := oidcFacade.PublicKeys(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, set.Keys, 1)
+	keysOut, err := oidcFacade.GetSigningKeys(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, keys, keysOut)
+	rotated, err := oidcFacade.RotateSigningKey(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "k2", rotated.Kid)
+	mockOIDCService.AssertExpectations(t)
+}
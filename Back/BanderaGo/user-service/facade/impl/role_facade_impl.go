@@ -0,0 +1,48 @@
+package impl
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/services"
+	"context"
+)
+
+type RoleFacadeImpl struct {
+	RoleService services.RoleService
+}
+
+func NewRoleFacade(service services.RoleService) *RoleFacadeImpl {
+	return &RoleFacadeImpl{RoleService: service}
+}
+
+func (f *RoleFacadeImpl) GetRoles(ctx context.Context) ([]output.RoleOut, error) {
+	return f.RoleService.GetRoles(ctx)
+}
+
+func (f *RoleFacadeImpl) GetRole(ctx context.Context, name string) (output.RoleOut, error) {
+	return f.RoleService.GetRole(ctx, name)
+}
+
+func (f *RoleFacadeImpl) CreateRole(ctx context.Context, roleIn input.RoleIn) (output.RoleOut, error) {
+	return f.RoleService.CreateRole(ctx, roleIn)
+}
+
+func (f *RoleFacadeImpl) UpdateRole(ctx context.Context, name string, roleIn input.UpdateRoleIn) (output.RoleOut, error) {
+	return f.RoleService.UpdateRole(ctx, name, roleIn)
+}
+
+func (f *RoleFacadeImpl) DeleteRole(ctx context.Context, name string) error {
+	return f.RoleService.DeleteRole(ctx, name)
+}
+
+func (f *RoleFacadeImpl) GetUserRoles(ctx context.Context, userID uint) (output.UserRolesOut, error) {
+	return f.RoleService.GetUserRoles(ctx, userID)
+}
+
+func (f *RoleFacadeImpl) AssignRole(ctx context.Context, userID uint, role string) error {
+	return f.RoleService.AssignRole(ctx, userID, role)
+}
+
+func (f *RoleFacadeImpl) RevokeRole(ctx context.Context, userID uint, role string) error {
+	return f.RoleService.RevokeRole(ctx, userID, role)
+}
@@ -0,0 +1,94 @@
+package impl
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/requestctx"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock de RoleService para pruebas
+type MockRoleService struct {
+	mock.Mock
+}
+
+func (m *MockRoleService) GetRoles(ctx context.Context) ([]output.RoleOut, error) {
+	args := m.Called()
+	return args.Get(0).([]output.RoleOut), args.Error(1)
+}
+
+func (m *MockRoleService) GetRole(ctx context.Context, name string) (output.RoleOut, error) {
+	args := m.Called(name)
+	return args.Get(0).(output.RoleOut), args.Error(1)
+}
+
+func (m *MockRoleService) CreateRole(ctx context.Context, roleIn input.RoleIn) (output.RoleOut, error) {
+	args := m.Called(roleIn)
+	return args.Get(0).(output.RoleOut), args.Error(1)
+}
+
+func (m *MockRoleService) UpdateRole(ctx context.Context, name string, roleIn input.UpdateRoleIn) (output.RoleOut, error) {
+	args := m.Called(name, roleIn)
+	return args.Get(0).(output.RoleOut), args.Error(1)
+}
+
+func (m *MockRoleService) DeleteRole(ctx context.Context, name string) error {
+	return m.Called(name).Error(0)
+}
+
+func (m *MockRoleService) GetUserRoles(ctx context.Context, userID uint) (output.UserRolesOut, error) {
+	args := m.Called(userID)
+	return args.Get(0).(output.UserRolesOut), args.Error(1)
+}
+
+func (m *MockRoleService) AssignRole(ctx context.Context, userID uint, role string) error {
+	return m.Called(userID, role).Error(0)
+}
+
+func (m *MockRoleService) RevokeRole(ctx context.Context, userID uint, role string) error {
+	return m.Called(userID, role).Error(0)
+}
+
+func (m *MockRoleService) Permissions(ctx context.Context, identity requestctx.Identity) ([]string, error) {
+	args := m.Called(identity)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func TestRoleFacadeDelegates(t *testing.T) {
+	mockRoleService := new(MockRoleService)
+	roleFacade := NewRoleFacade(mockRoleService)
+	ctx := context.Background()
+
+	roleIn := input.RoleIn{Name: "support", Permissions: []string{"users:read"}}
+	updateIn := input.UpdateRoleIn{Permissions: []string{"users:update"}}
+	mockRoleService.On("GetRoles").Return([]output.RoleOut{{Name: "admin"}}, nil)
+	mockRoleService.On("GetRole", "admin").Return(output.RoleOut{Name: "admin"}, nil)
+	mockRoleService.On("CreateRole", roleIn).Return(output.RoleOut{Name: "support"}, nil)
+	mockRoleService.On("UpdateRole", "support", updateIn).Return(output.RoleOut{Name: "support"}, nil)
+	mockRoleService.On("DeleteRole", "support").Return(nil)
+	mockRoleService.On("GetUserRoles", uint(1)).Return(output.UserRolesOut{UserID: 1, Roles: []string{"admin"}}, nil)
+	mockRoleService.On("AssignRole", uint(1), "admin").Return(nil)
+	mockRoleService.On("RevokeRole", uint(1), "admin").Return(nil)
+
+	roles, err := roleFacade.GetRoles(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, roles, 1)
+	role, err := roleFacade.GetRole(ctx, "admin")
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", role.Name)
+	_, err = roleFacade.CreateRole(ctx, roleIn)
+	assert.NoError(t, err)
+	_, err = roleFacade.UpdateRole(ctx, "support", updateIn)
+	assert.NoError(t, err)
+	assert.NoError(t, roleFacade.DeleteRole(ctx, "support"))
+	userRoles, err := roleFacade.GetUserRoles(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"admin"}, userRoles.Roles)
+	assert.NoError(t, roleFacade.AssignRole(ctx, 1, "admin"))
+	assert.NoError(t, roleFacade.RevokeRole(ctx, 1, "admin"))
+	mockRoleService.AssertExpectations(t)
+}
@@ -0,0 +1,76 @@
+package impl
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/services"
+	"context"
+)
+
+type SCIMFacadeImpl struct {
+	SCIMService services.SCIMService
+}
+
+func NewSCIMFacade(service services.SCIMService) *SCIMFacadeImpl {
+	return &SCIMFacadeImpl{SCIMService: service}
+}
+
+func (f *SCIMFacadeImpl) GetUsers(ctx context.Context, listIn input.SCIMListIn) (output.SCIMUserListOut, error) {
+	return f.SCIMService.GetUsers(ctx, listIn)
+}
+
+func (f *SCIMFacadeImpl) GetUser(ctx context.Context, id string) (output.SCIMUserOut, error) {
+	return f.SCIMService.GetUser(ctx, id)
+}
+
+func (f *SCIMFacadeImpl) CreateUser(ctx context.Context, userIn input.SCIMUserIn) (output.SCIMUserOut, error) {
+	return f.SCIMService.CreateUser(ctx, userIn)
+}
+
+func (f *SCIMFacadeImpl) ReplaceUser(ctx context.Context, id string, userIn input.SCIMUserIn, ifMatch *input.Precondition) (output.SCIMUserOut, error) {
+	return f.SCIMService.ReplaceUser(ctx, id, userIn, ifMatch)
+}
+
+func (f *SCIMFacadeImpl) PatchUser(ctx context.Context, id string, patchIn input.SCIMPatchIn, ifMatch *input.Precondition) (output.SCIMUserOut, error) {
+	return f.SCIMService.PatchUser(ctx, id, patchIn, ifMatch)
+}
+
+func (f *SCIMFacadeImpl) DeleteUser(ctx context.Context, id string) error {
+	return f.SCIMService.DeleteUser(ctx, id)
+}
+
+func (f *SCIMFacadeImpl) GetGroups(ctx context.Context, listIn input.SCIMListIn) (output.SCIMGroupListOut, error) {
+	return f.SCIMService.GetGroups(ctx, listIn)
+}
+
+func (f *SCIMFacadeImpl) GetGroup(ctx context.Context, id string) (output.SCIMGroupOut, error) {
+	return f.SCIMService.GetGroup(ctx, id)
+}
+
+func (f *SCIMFacadeImpl) CreateGroup(ctx context.Context, groupIn input.SCIMGroupIn) (output.SCIMGroupOut, error) {
+	return f.SCIMService.CreateGroup(ctx, groupIn)
+}
+
+func (f *SCIMFacadeImpl) ReplaceGroup(ctx context.Context, id string, groupIn input.SCIMGroupIn) (output.SCIMGroupOut, error) {
+	return f.SCIMService.ReplaceGroup(ctx, id, groupIn)
+}
+
+func (f *SCIMFacadeImpl) PatchGroup(ctx context.Context, id string, patchIn input.SCIMPatchIn) (output.SCIMGroupOut, error) {
+	return f.SCIMService.PatchGroup(ctx, id, patchIn)
+}
+
+func (f *SCIMFacadeImpl) DeleteGroup(ctx context.Context, id string) error {
+	return f.SCIMService.DeleteGroup(ctx, id)
+}
+
+func (f *SCIMFacadeImpl) ServiceProviderConfig() output.SCIMServiceProviderConfigOut {
+	return f.SCIMService.ServiceProviderConfig()
+}
+
+func (f *SCIMFacadeImpl) ResourceTypes() output.SCIMResourceTypeListOut {
+	return f.SCIMService.ResourceTypes()
+}
+
+func (f *SCIMFacadeImpl) Schemas() output.SCIMSchemaListOut {
+	return f.SCIMService.Schemas()
+}
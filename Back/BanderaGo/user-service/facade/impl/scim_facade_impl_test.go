@@ -0,0 +1,150 @@
+package impl
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock de SCIMService para pruebas
+type MockSCIMService struct {
+	mock.Mock
+}
+
+func (m *MockSCIMService) GetUsers(ctx context.Context, listIn input.SCIMListIn) (output.SCIMUserListOut, error) {
+	args := m.Called(listIn)
+	return args.Get(0).(output.SCIMUserListOut), args.Error(1)
+}
+
+func (m *MockSCIMService) GetUser(ctx context.Context, id string) (output.SCIMUserOut, error) {
+	args := m.Called(id)
+	return args.Get(0).(output.SCIMUserOut), args.Error(1)
+}
+
+func (m *MockSCIMService) CreateUser(ctx context.Context, userIn input.SCIMUserIn) (output.SCIMUserOut, error) {
+	args := m.Called(userIn)
+	return args.Get(0).(output.SCIMUserOut), args.Error(1)
+}
+
+func (m *MockSCIMService) ReplaceUser(ctx context.Context, id string, userIn input.SCIMUserIn, ifMatch *input.Precondition) (output.SCIMUserOut, error) {
+	args := m.Called(id, userIn, ifMatch)
+	return args.Get(0).(output.SCIMUserOut), args.Error(1)
+}
+
+func (m *MockSCIMService) PatchUser(ctx context.Context, id string, patchIn input.SCIMPatchIn, ifMatch *input.Precondition) (output.SCIMUserOut, error) {
+	args := m.Called(id, patchIn, ifMatch)
+	return args.Get(0).(output.SCIMUserOut), args.Error(1)
+}
+
+func (m *MockSCIMService) DeleteUser(ctx context.Context, id string) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockSCIMService) GetGroups(ctx context.Context, listIn input.SCIMListIn) (output.SCIMGroupListOut, error) {
+	args := m.Called(listIn)
+	return args.Get(0).(output.SCIMGroupListOut), args.Error(1)
+}
+
+func (m *MockSCIMService) GetGroup(ctx context.Context, id string) (output.SCIMGroupOut, error) {
+	args := m.Called(id)
+	return args.Get(0).(output.SCIMGroupOut), args.Error(1)
+}
+
+func (m *MockSCIMService) CreateGroup(ctx context.Context, groupIn input.SCIMGroupIn) (output.SCIMGroupOut, error) {
+	args := m.Called(groupIn)
+	return args.Get(0).(output.SCIMGroupOut), args.Error(1)
+}
+
+func (m *MockSCIMService) ReplaceGroup(ctx context.Context, id string, groupIn input.SCIMGroupIn) (output.SCIMGroupOut, error) {
+	args := m.Called(id, groupIn)
+	return args.Get(0).(output.SCIMGroupOut), args.Error(1)
+}
+
+func (m *MockSCIMService) PatchGroup(ctx context.Context, id string, patchIn input.SCIMPatchIn) (output.SCIMGroupOut, error) {
+	args := m.Called(id, patchIn)
+	return args.Get(0).(output.SCIMGroupOut), args.Error(1)
+}
+
+func (m *MockSCIMService) DeleteGroup(ctx context.Context, id string) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockSCIMService) ServiceProviderConfig() output.SCIMServiceProviderConfigOut {
+	return m.Called().Get(0).(output.SCIMServiceProviderConfigOut)
+}
+
+func (m *MockSCIMService) ResourceTypes() output.SCIMResourceTypeListOut {
+	return m.Called().Get(0).(output.SCIMResourceTypeListOut)
+}
+
+func (m *MockSCIMService) Schemas() output.SCIMSchemaListOut {
+	return m.Called().Get(0).(output.SCIMSchemaListOut)
+}
+
+func TestSCIMFacadeDelegates(t *testing.T) {
+	mockSCIMService := new(MockSCIMService)
+	scimFacade := NewSCIMFacade(mockSCIMService)
+	ctx := context.Background()
+	listIn := input.SCIMListIn{Filter: `userName eq "ana"`}
+	userIn := input.SCIMUserIn{UserName: "ana"}
+	patchIn := input.SCIMPatchIn{Operations: []input.SCIMOperationIn{{Op: "remove", Path: "displayName"}}}
+	groupIn := input.SCIMGroupIn{DisplayName: "support"}
+	ifMatch := &input.Precondition{Versions: []uint{2}}
+	userOut := output.SCIMUserOut{ID: "1", UserName: "ana"}
+	groupOut := output.SCIMGroupOut{ID: "support", DisplayName: "support"}
+
+	mockSCIMService.On("GetUsers", listIn).Return(output.SCIMUserListOut{TotalResults: 1, Resources: []output.SCIMUserOut{userOut}}, nil)
+	mockSCIMService.On("GetUser", "1").Return(userOut, nil)
+	mockSCIMService.On("CreateUser", userIn).Return(userOut, nil)
+	mockSCIMService.On("ReplaceUser", "1", userIn, ifMatch).Return(userOut, nil)
+	mockSCIMService.On("PatchUser", "1", patchIn, ifMatch).Return(userOut, nil)
+	mockSCIMService.On("DeleteUser", "1").Return(nil)
+	mockSCIMService.On("GetGroups", listIn).Return(output.SCIMGroupListOut{TotalResults: 1}, nil)
+	mockSCIMService.On("GetGroup", "support").Return(groupOut, nil)
+	mockSCIMService.On("CreateGroup", groupIn).Return(groupOut, nil)
+	mockSCIMService.On("ReplaceGroup", "support", groupIn).Return(groupOut, nil)
+	mockSCIMService.On("PatchGroup", "support", patchIn).Return(groupOut, nil)
+	mockSCIMService.On("DeleteGroup", "support").Return(nil)
+	mockSCIMService.On("ServiceProviderConfig").Return(output.SCIMServiceProviderConfigOut{Patch: output.SCIMSupportedOut{Supported: true}})
+	mockSCIMService.On("ResourceTypes").Return(output.SCIMResourceTypeListOut{TotalResults: 2})
+	mockSCIMService.On("Schemas").Return(output.SCIMSchemaListOut{TotalResults: 2})
+
+	users, err := scimFacade.GetUsers(ctx, listIn)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), users.TotalResults)
+	for _, call := range []func() (output.SCIMUserOut, error){
+		func() (output.SCIMUserOut, error) { return scimFacade.GetUser(ctx, "1") },
+		func() (output.SCIMUserOut, error) { return scimFacade.CreateUser(ctx, userIn) },
+		func() (output.SCIMUserOut, error) { return scimFacade.ReplaceUser(ctx, "1", userIn, ifMatch) },
+		func() (output.SCIMUserOut, error) { return scimFacade.PatchUser(ctx, "1", patchIn, ifMatch) },
+	} {
+		user, err := call()
+		assert.NoError(t, err)
+		assert.Equal(t, userOut, user)
+	}
+	assert.NoError(t, scimFacade.DeleteUser(ctx, "1"))
+
+	groups, err := scimFacade.GetGroups(ctx, listIn)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), groups.TotalResults)
+	for _, call := range []func() (output.SCIMGroupOut, error){
+		func() (output.SCIMGroupOut, error) { return scimFacade.GetGroup(ctx, "support") },
+		func() (output.SCIMGroupOut, error) { return scimFacade.CreateGroup(ctx, groupIn) },
+		func() (output.SCIMGroupOut, error) { return scimFacade.ReplaceGroup(ctx, "support", groupIn) },
+		func() (output.SCIMGroupOut, error) { return scimFacade.PatchGroup(ctx, "support", patchIn) },
+	} {
+		group, err := call()
+		assert.NoError(t, err)
+		assert.Equal(t, groupOut, group)
+	}
+	assert.NoError(t, scimFacade.DeleteGroup(ctx, "support"))
+
+	assert.True(t, scimFacade.ServiceProviderConfig().Patch.Supported)
+	assert.Equal(t, int64(2), scimFacade.ResourceTypes().TotalResults)
+	assert.Equal(t, int64(2), scimFacade.Schemas().TotalResults)
+	mockSCIMService.AssertExpectations(t)
+}
@@ -0,0 +1,27 @@
+package impl
+
+import (
+	"application/dtos/output"
+	"application/services"
+	"context"
+)
+
+type SessionFacadeImpl struct {
+	SessionService services.SessionService
+}
+
+func NewSessionFacade(service services.SessionService) *SessionFacadeImpl {
+	return &SessionFacadeImpl{SessionService: service}
+}
+
+func (f *SessionFacadeImpl) GetUserSessions(ctx context.Context, userID uint) ([]output.SessionOut, error) {
+	return f.SessionService.GetUserSessions(ctx, userID)
+}
+
+func (f *SessionFacadeImpl) RevokeSession(ctx context.Context, userID, sessionID uint) error {
+	return f.SessionService.RevokeSession(ctx, userID, sessionID)
+}
+
+func (f *SessionFacadeImpl) RevokeUserSessions(ctx context.Context, userID uint) error {
+	return f.SessionService.RevokeUserSessions(ctx, userID)
+}
@@ -0,0 +1,62 @@
+package impl
+
+import (
+	"application/dtos/output"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock de SessionService para pruebas
+type MockSessionService struct {
+	mock.Mock
+}
+
+func (m *MockSessionService) GetUserSessions(ctx context.Context, userID uint) ([]output.SessionOut, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]output.SessionOut), args.Error(1)
+}
+
+func (m *MockSessionService) RevokeSession(ctx context.Context, userID, sessionID uint) error {
+	args := m.Called(userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) RevokeUserSessions(ctx context.Context, userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) EndSession(ctx context.Context, sessionID uint) error {
+	args := m.Called(sessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) EndUserSessions(ctx context.Context, userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) VerifySession(ctx context.Context, sessionID string) error {
+	args := m.Called(sessionID)
+	return args.Error(0)
+}
+
+func TestSessionFacadeDelegates(t *testing.T) {
+	mockSessionService := new(MockSessionService)
+	sessionFacade := NewSessionFacade(mockSessionService)
+	ctx := context.Background()
+
+	mockSessionService.On("GetUserSessions", uint(1)).Return([]output.SessionOut{{ID: 3, Current: true}}, nil)
+	mockSessionService.On("RevokeSession", uint(1), uint(3)).Return(nil)
+	mockSessionService.On("RevokeUserSessions", uint(1)).Return(nil)
+
+	sessions, err := sessionFacade.GetUserSessions(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []output.SessionOut{{ID: 3, Current: true}}, sessions)
+	assert.NoError(t, sessionFacade.RevokeSession(ctx, 1, 3))
+	assert.NoError(t, sessionFacade.RevokeUserSessions(ctx, 1))
+	mockSessionService.AssertExpectations(t)
+}
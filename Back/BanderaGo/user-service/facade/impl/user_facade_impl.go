@@ -4,6 +4,8 @@ import (
 	"application/dtos/input"
 	"application/dtos/output"
 	"application/services"
+	"context"
+	"time"
 )
 
 type UserFacadeImpl struct {
@@ -14,22 +16,54 @@ func NewUserFacade(service services.UserService) *UserFacadeImpl {
 	return &UserFacadeImpl{UserService: service}
 }
 
-func (f *UserFacadeImpl) CreateUser(userIn input.CreateUserIn) (output.CreateUserOut, error) {
-	return f.UserService.CreateUser(userIn)
+func (f *UserFacadeImpl) CreateUser(ctx context.Context, userIn input.CreateUserIn) (output.CreateUserOut, error) {
+	return f.UserService.CreateUser(ctx, userIn)
 }
 
-func (f *UserFacadeImpl) GetUserByID(id uint) (output.GetUserOut, error) {
-	return f.UserService.GetUserByID(id)
+func (f *UserFacadeImpl) GetUserByID(ctx context.Context, id uint) (output.GetUserOut, error) {
+	return f.UserService.GetUserByID(ctx, id)
 }
 
-func (f *UserFacadeImpl) GetAllUsers() ([]output.GetUsersOut, error) {
-	return f.UserService.GetAllUsers()
+func (f *UserFacadeImpl) GetUserAsOf(ctx context.Context, id uint, at time.Time) (output.GetUserOut, error) {
+	return f.UserService.GetUserAsOf(ctx, id, at)
 }
 
-func (f *UserFacadeImpl) UpdateUser(id uint, userIn input.UpdateUserIn) (output.UpdateUserOut, error) {
-	return f.UserService.UpdateUser(id, userIn)
+func (f *UserFacadeImpl) GetUserVersion(ctx context.Context, id, version uint) (output.UserVersionOut, error) {
+	return f.UserService.GetUserVersion(ctx, id, version)
 }
 
-func (f *UserFacadeImpl) DeleteUser(id uint) (output.DeleteUserOut, error) {
-	return f.UserService.DeleteUser(id)
+func (f *UserFacadeImpl) DiffUserVersions(ctx context.Context, id, from, to uint) (output.UserDiffOut, error) {
+	return f.UserService.DiffUserVersions(ctx, id, from, to)
+}
+
+func (f *UserFacadeImpl) GetAllUsers(ctx context.Context, listIn input.ListUsersIn) (output.GetUsersPageOut, error) {
+	return f.UserService.GetAllUsers(ctx, listIn)
+}
+
+func (f *UserFacadeImpl) UpdateUser(ctx context.Context, id uint, userIn input.UpdateUserIn) (output.UpdateUserOut, error) {
+	return f.UserService.UpdateUser(ctx, id, userIn)
+}
+
+func (f *UserFacadeImpl) PatchUser(ctx context.Context, id uint, patchIn input.PatchUserIn) (output.UpdateUserOut, error) {
+	return f.UserService.PatchUser(ctx, id, patchIn)
+}
+
+func (f *UserFacadeImpl) DeleteUser(ctx context.Context, id uint) (output.DeleteUserOut, error) {
+	return f.UserService.DeleteUser(ctx, id)
+}
+
+func (f *UserFacadeImpl) RestoreUser(ctx context.Context, id uint) (output.GetUserOut, error) {
+	return f.UserService.RestoreUser(ctx, id)
+}
+
+func (f *UserFacadeImpl) PurgeUser(ctx context.Context, id uint) (output.DeleteUserOut, error) {
+	return f.UserService.PurgeUser(ctx, id)
+}
+
+func (f *UserFacadeImpl) GetUserHistory(ctx context.Context, id uint, listIn input.ListAuditIn) (output.GetAuditPageOut, error) {
+	return f.UserService.GetUserHistory(ctx, id, listIn)
+}
+
+func (f *UserFacadeImpl) GetAuditRecords(ctx context.Context, listIn input.ListAuditIn) (output.GetAuditPageOut, error) {
+	return f.UserService.GetAuditRecords(ctx, listIn)
 }
@@ -3,7 +3,9 @@ package impl
 import (
 	"application/dtos/input"
 	"application/dtos/output"
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -14,31 +16,76 @@ type MockUserService struct {
 	mock.Mock
 }
 
-func (m *MockUserService) CreateUser(userIn input.CreateUserIn) (output.CreateUserOut, error) {
+func (m *MockUserService) CreateUser(ctx context.Context, userIn input.CreateUserIn) (output.CreateUserOut, error) {
 	args := m.Called(userIn)
 	return args.Get(0).(output.CreateUserOut), args.Error(1)
 }
 
-func (m *MockUserService) GetUserByID(id uint) (output.GetUserOut, error) {
+func (m *MockUserService) GetUserByID(ctx context.Context, id uint) (output.GetUserOut, error) {
 	args := m.Called(id)
 	return args.Get(0).(output.GetUserOut), args.Error(1)
 }
 
-func (m *MockUserService) GetAllUsers() ([]output.GetUsersOut, error) {
-	args := m.Called()
-	return args.Get(0).([]output.GetUsersOut), args.Error(1)
+func (m *MockUserService) GetAllUsers(ctx context.Context, listIn input.ListUsersIn) (output.GetUsersPageOut, error) {
+	args := m.Called(listIn)
+	return args.Get(0).(output.GetUsersPageOut), args.Error(1)
 }
 
-func (m *MockUserService) UpdateUser(id uint, userIn input.UpdateUserIn) (output.UpdateUserOut, error) {
+func (m *MockUserService) UpdateUser(ctx context.Context, id uint, userIn input.UpdateUserIn) (output.UpdateUserOut, error) {
 	args := m.Called(id, userIn)
 	return args.Get(0).(output.UpdateUserOut), args.Error(1)
 }
 
-func (m *MockUserService) DeleteUser(id uint) (output.DeleteUserOut, error) {
+func (m *MockUserService) PatchUser(ctx context.Context, id uint, patchIn input.PatchUserIn) (output.UpdateUserOut, error) {
+	args := m.Called(id, patchIn)
+	return args.Get(0).(output.UpdateUserOut), args.Error(1)
+}
+
+func (m *MockUserService) DeleteUser(ctx context.Context, id uint) (output.DeleteUserOut, error) {
+	args := m.Called(id)
+	return args.Get(0).(output.DeleteUserOut), args.Error(1)
+}
+
+func (m *MockUserService) RestoreUser(ctx context.Context, id uint) (output.GetUserOut, error) {
+	args := m.Called(id)
+	return args.Get(0).(output.GetUserOut), args.Error(1)
+}
+
+func (m *MockUserService) PurgeUser(ctx context.Context, id uint) (output.DeleteUserOut, error) {
 	args := m.Called(id)
 	return args.Get(0).(output.DeleteUserOut), args.Error(1)
 }
 
+func (m *MockUserService) GetUserAsOf(ctx context.Context, id uint, at time.Time) (output.GetUserOut, error) {
+	args := m.Called(id, at)
+	return args.Get(0).(output.GetUserOut), args.Error(1)
+}
+
+func (m *MockUserService) GetUserVersion(ctx context.Context, id, version uint) (output.UserVersionOut, error) {
+	args := m.Called(id, version)
+	return args.Get(0).(output.UserVersionOut), args.Error(1)
+}
+
+func (m *MockUserService) DiffUserVersions(ctx context.Context, id, from, to uint) (output.UserDiffOut, error) {
+	args := m.Called(id, from, to)
+	return args.Get(0).(output.UserDiffOut), args.Error(1)
+}
+
+func (m *MockUserService) GetUserHistory(ctx context.Context, id uint, listIn input.ListAuditIn) (output.GetAuditPageOut, error) {
+	args := m.Called(id, listIn)
+	return args.Get(0).(output.GetAuditPageOut), args.Error(1)
+}
+
+func (m *MockUserService) GetAuditRecords(ctx context.Context, listIn input.ListAuditIn) (output.GetAuditPageOut, error) {
+	args := m.Called(listIn)
+	return args.Get(0).(output.GetAuditPageOut), args.Error(1)
+}
+
+func (m *MockUserService) PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func TestCreateUser(t *testing.T) {
 	mockUserService := new(MockUserService)
 	userFacade := NewUserFacade(mockUserService)
@@ -47,7 +94,7 @@ func TestCreateUser(t *testing.T) {
 	mockUserService.On("CreateUser", mock.Anything).Return(output.CreateUserOut{}, nil)
 
 	// Ejecutar la función a probar
-	result, err := userFacade.CreateUser(input.CreateUserIn{})
+	result, err := userFacade.CreateUser(context.Background(), input.CreateUserIn{})
 
 	// Verificar resultado y expectativas en el mock
 	assert.NotNil(t, result)
@@ -63,7 +110,7 @@ func TestGetUserByID(t *testing.T) {
 	mockUserService.On("GetUserByID", uint(1)).Return(output.GetUserOut{}, nil)
 
 	// Ejecutar la función a probar
-	result, err := userFacade.GetUserByID(1)
+	result, err := userFacade.GetUserByID(context.Background(), 1)
 
 	// Verificar resultado y expectativas en el mock
 	assert.NotNil(t, result)
@@ -82,16 +129,17 @@ func TestGetAllUsers(t *testing.T) {
 	}
 
 	// Configurar expectativas en el mock
-	mockUserService.On("GetAllUsers").Return(mockUsers, nil)
+	listIn := input.ListUsersIn{Page: 1, PageSize: 2}
+	mockUserService.On("GetAllUsers", listIn).Return(output.GetUsersPageOut{Data: mockUsers, Total: 2}, nil)
 
 	// Ejecutar la función a probar
-	usersOut, err := userFacade.GetAllUsers()
+	pageOut, err := userFacade.GetAllUsers(context.Background(), listIn)
 
 	// Verificar resultado y expectativas en el mock
-	assert.NotNil(t, usersOut)
+	assert.NotNil(t, pageOut)
 	assert.NoError(t, err)
-	assert.Equal(t, len(mockUsers), len(usersOut))
-	for i, user := range usersOut {
+	assert.Equal(t, len(mockUsers), len(pageOut.Data))
+	for i, user := range pageOut.Data {
 		assert.Equal(t, mockUsers[i].ID, user.ID)
 		assert.Equal(t, mockUsers[i].Name, user.Name)
 	}
@@ -108,7 +156,7 @@ func TestUpdateUser(t *testing.T) {
 	mockUserService.On("UpdateUser", mockUserID, mockUserIn).Return(output.UpdateUserOut{}, nil)
 
 	// Ejecutar la función a probar
-	result, err := userFacade.UpdateUser(mockUserID, mockUserIn)
+	result, err := userFacade.UpdateUser(context.Background(), mockUserID, mockUserIn)
 
 	// Verificar resultado y expectativas en el mock
 	assert.NotNil(t, result)
@@ -116,6 +164,24 @@ func TestUpdateUser(t *testing.T) {
 	mockUserService.AssertExpectations(t)
 }
 
+func TestPatchUser(t *testing.T) {
+	mockUserService := new(MockUserService)
+	userFacade := NewUserFacade(mockUserService)
+
+	// Configurar expectativas en el mock
+	mockUserID := uint(1)
+	mockPatchIn := input.PatchUserIn{Type: input.MergePatchType, Patch: []byte(`{"name":"Patched"}`)}
+	mockUserService.On("PatchUser", mockUserID, mockPatchIn).Return(output.UpdateUserOut{ID: 1, Name: "Patched"}, nil)
+
+	// Ejecutar la función a probar
+	result, err := userFacade.PatchUser(context.Background(), mockUserID, mockPatchIn)
+
+	// Verificar resultado y expectativas en el mock
+	assert.Equal(t, "Patched", result.Name)
+	assert.NoError(t, err)
+	mockUserService.AssertExpectations(t)
+}
+
 func TestDeleteUser(t *testing.T) {
 	mockUserService := new(MockUserService)
 	userFacade := NewUserFacade(mockUserService)
@@ -124,7 +190,7 @@ func TestDeleteUser(t *testing.T) {
 	mockUserService.On("DeleteUser", uint(1)).Return(output.DeleteUserOut{Success: true}, nil)
 
 	// Ejecutar la función a probar
-	result, err := userFacade.DeleteUser(1)
+	result, err := userFacade.DeleteUser(context.Background(), 1)
 
 	// Verificar resultado y expectativas en el mock
 	assert.NotNil(t, result)
@@ -132,3 +198,118 @@ func TestDeleteUser(t *testing.T) {
 	assert.True(t, result.Success)
 	mockUserService.AssertExpectations(t)
 }
+
+func TestRestoreUser(t *testing.T) {
+	mockUserService := new(MockUserService)
+	userFacade := NewUserFacade(mockUserService)
+
+	// Configurar expectativas en el mock
+	mockUserService.On("RestoreUser", uint(1)).Return(output.GetUserOut{ID: 1, Version: 2}, nil)
+
+	// Ejecutar la función a probar
+	result, err := userFacade.RestoreUser(context.Background(), 1)
+
+	// Verificar resultado y expectativas en el mock
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), result.Version)
+	mockUserService.AssertExpectations(t)
+}
+
+func TestGetUserAsOf(t *testing.T) {
+	mockUserService := new(MockUserService)
+	userFacade := NewUserFacade(mockUserService)
+
+	// Configurar expectativas en el mock
+	at := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+	mockUserService.On("GetUserAsOf", uint(1), at).Return(output.GetUserOut{ID: 1, Name: "Jon"}, nil)
+
+	// Ejecutar la función a probar
+	result, err := userFacade.GetUserAsOf(context.Background(), 1, at)
+
+	// Verificar resultado y expectativas en el mock
+	assert.NoError(t, err)
+	assert.Equal(t, "Jon", result.Name)
+	mockUserService.AssertExpectations(t)
+}
+
+func TestGetUserVersion(t *testing.T) {
+	mockUserService := new(MockUserService)
+	userFacade := NewUserFacade(mockUserService)
+
+	// Configurar expectativas en el mock
+	mockUserService.On("GetUserVersion", uint(1), uint(2)).Return(output.UserVersionOut{ID: 1, Version: 2}, nil)
+
+	// Ejecutar la función a probar
+	result, err := userFacade.GetUserVersion(context.Background(), 1, 2)
+
+	// Verificar resultado y expectativas en el mock
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), result.Version)
+	mockUserService.AssertExpectations(t)
+}
+
+func TestDiffUserVersions(t *testing.T) {
+	mockUserService := new(MockUserService)
+	userFacade := NewUserFacade(mockUserService)
+
+	// Configurar expectativas en el mock
+	mockUserService.On("DiffUserVersions", uint(1), uint(1), uint(3)).Return(output.UserDiffOut{ID: 1, From: 1, To: 3}, nil)
+
+	// Ejecutar la función a probar
+	result, err := userFacade.DiffUserVersions(context.Background(), 1, 1, 3)
+
+	// Verificar resultado y expectativas en el mock
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), result.To)
+	mockUserService.AssertExpectations(t)
+}
+
+func TestGetUserHistory(t *testing.T) {
+	mockUserService := new(MockUserService)
+	userFacade := NewUserFacade(mockUserService)
+
+	// Configurar expectativas en el mock
+	listIn := input.ListAuditIn{Operation: "update"}
+	mockUserService.On("GetUserHistory", uint(1), listIn).Return(output.GetAuditPageOut{Total: 2}, nil)
+
+	// Ejecutar la función a probar
+	result, err := userFacade.GetUserHistory(context.Background(), 1, listIn)
+
+	// Verificar resultado y expectativas en el mock
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.Total)
+	mockUserService.AssertExpectations(t)
+}
+
+func TestGetAuditRecords(t *testing.T) {
+	mockUserService := new(MockUserService)
+	userFacade := NewUserFacade(mockUserService)
+
+	// Configurar expectativas en el mock
+	listIn := input.ListAuditIn{Actor: "alice"}
+	mockUserService.On("GetAuditRecords", listIn).Return(output.GetAuditPageOut{Total: 1}, nil)
+
+	// Ejecutar la función a probar
+	result, err := userFacade.GetAuditRecords(context.Background(), listIn)
+
+	// Verificar resultado y expectativas en el mock
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), result.Total)
+	mockUserService.AssertExpectations(t)
+}
+
+func TestPurgeUser(t *testing.T) {
+	mockUserService := new(MockUserService)
+	userFacade := NewUserFacade(mockUserService)
+
+	// Configurar expectativas en el mock
+	mockUserService.On("PurgeUser", uint(1)).Return(output.DeleteUserOut{Success: true}, nil)
+
+	// Ejecutar la función a probar
+	result, err := userFacade.PurgeUser(context.Background(), 1)
+
+	// Verificar resultado y expectativas en el mock
+	assert.NoError(t, err)
+	assert.True(t, result.Success)
+	mockUserService.AssertExpectations(t)
+}
@@ -0,0 +1,15 @@
+package facade
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"context"
+)
+
+type MFAFacade interface {
+	GetMFA(ctx context.Context, userID uint) (output.MFAOut, error)
+	EnrollTOTP(ctx context.Context, userID uint) (output.TOTPEnrollmentOut, error)
+	ConfirmTOTP(ctx context.Context, userID uint, confirmIn input.ConfirmTOTPIn) (output.RecoveryCodesOut, error)
+	RegenerateRecoveryCodes(ctx context.Context, userID uint) (output.RecoveryCodesOut, error)
+	DisableMFA(ctx context.Context, userID uint) error
+}
@@ -0,0 +1,16 @@
+package facade
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"context"
+)
+
+type OIDCClientFacade interface {
+	GetClients(ctx context.Context) ([]output.OIDCClientOut, error)
+	GetClient(ctx context.Context, id string) (output.OIDCClientOut, error)
+	CreateClient(ctx context.Context, clientIn input.OIDCClientIn) (output.OIDCClientCreatedOut, error)
+	UpdateClient(ctx context.Context, id string, clientIn input.UpdateOIDCClientIn) (output.OIDCClientOut, error)
+	RotateClientSecret(ctx context.Context, id string) (output.OIDCClientCreatedOut, error)
+	DeleteClient(ctx context.Context, id string) error
+}
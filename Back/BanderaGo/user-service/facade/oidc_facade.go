@@ -0,0 +1,18 @@
+package facade
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/tokens"
+	"context"
+)
+
+type OIDCFacade interface {
+	Discovery() output.DiscoveryOut
+	Authorize(ctx context.Context, authorizeIn input.AuthorizeIn) (string, error)
+	Token(ctx context.Context, tokenIn input.TokenRequestIn) (output.OIDCTokenOut, error)
+	UserInfo(ctx context.Context, accessToken string) (output.UserInfoOut, error)
+	PublicKeys(ctx context.Context) (tokens.JWKS, error)
+	GetSigningKeys(ctx context.Context) ([]output.SigningKeyOut, error)
+	RotateSigningKey(ctx context.Context) (output.SigningKeyOut, error)
+}
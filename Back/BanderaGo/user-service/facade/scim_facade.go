@@ -0,0 +1,25 @@
+package facade
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"context"
+)
+
+type SCIMFacade interface {
+	GetUsers(ctx context.Context, listIn input.SCIMListIn) (output.SCIMUserListOut, error)
+	GetUser(ctx context.Context, id string) (output.SCIMUserOut, error)
+	CreateUser(ctx context.Context, userIn input.SCIMUserIn) (output.SCIMUserOut, error)
+	ReplaceUser(ctx context.Context, id string, userIn input.SCIMUserIn, ifMatch *input.Precondition) (output.SCIMUserOut, error)
+	PatchUser(ctx context.Context, id string, patchIn input.SCIMPatchIn, ifMatch *input.Precondition) (output.SCIMUserOut, error)
+	DeleteUser(ctx context.Context, id string) error
+	GetGroups(ctx context.Context, listIn input.SCIMListIn) (output.SCIMGroupListOut, error)
+	GetGroup(ctx context.Context, id string) (output.SCIMGroupOut, error)
+	CreateGroup(ctx context.Context, groupIn input.SCIMGroupIn) (output.SCIMGroupOut, error)
+	ReplaceGroup(ctx context.Context, id string, groupIn input.SCIMGroupIn) (output.SCIMGroupOut, error)
+	PatchGroup(ctx context.Context, id string, patchIn input.SCIMPatchIn) (output.SCIMGroupOut, error)
+	DeleteGroup(ctx context.Context, id string) error
+	ServiceProviderConfig() output.SCIMServiceProviderConfigOut
+	ResourceTypes() output.SCIMResourceTypeListOut
+	Schemas() output.SCIMSchemaListOut
+}
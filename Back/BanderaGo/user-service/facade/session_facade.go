@@ -0,0 +1,12 @@
+package facade
+
+import (
+	"application/dtos/output"
+	"context"
+)
+
+type SessionFacade interface {
+	GetUserSessions(ctx context.Context, userID uint) ([]output.SessionOut, error)
+	RevokeSession(ctx context.Context, userID, sessionID uint) error
+	RevokeUserSessions(ctx context.Context, userID uint) error
+}
@@ -3,12 +3,22 @@ package facade
 import (
 	"application/dtos/input"
 	"application/dtos/output"
+	"context"
+	"time"
 )
 
 type UserFacade interface {
-	CreateUser(userIn input.CreateUserIn) (output.CreateUserOut, error)
-	GetUserByID(id uint) (output.GetUserOut, error)
-	GetAllUsers() ([]output.GetUsersOut, error)
-	UpdateUser(id uint, userIn input.UpdateUserIn) (output.UpdateUserOut, error)
-	DeleteUser(id uint) (output.DeleteUserOut, error)
+	CreateUser(ctx context.Context, userIn input.CreateUserIn) (output.CreateUserOut, error)
+	GetUserByID(ctx context.Context, id uint) (output.GetUserOut, error)
+	GetUserAsOf(ctx context.Context, id uint, at time.Time) (output.GetUserOut, error)
+	GetUserVersion(ctx context.Context, id, version uint) (output.UserVersionOut, error)
+	DiffUserVersions(ctx context.Context, id, from, to uint) (output.UserDiffOut, error)
+	GetAllUsers(ctx context.Context, listIn input.ListUsersIn) (output.GetUsersPageOut, error)
+	UpdateUser(ctx context.Context, id uint, userIn input.UpdateUserIn) (output.UpdateUserOut, error)
+	PatchUser(ctx context.Context, id uint, patchIn input.PatchUserIn) (output.UpdateUserOut, error)
+	DeleteUser(ctx context.Context, id uint) (output.DeleteUserOut, error)
+	RestoreUser(ctx context.Context, id uint) (output.GetUserOut, error)
+	PurgeUser(ctx context.Context, id uint) (output.DeleteUserOut, error)
+	GetUserHistory(ctx context.Context, id uint, listIn input.ListAuditIn) (output.GetAuditPageOut, error)
+	GetAuditRecords(ctx context.Context, listIn input.ListAuditIn) (output.GetAuditPageOut, error)
 }
@@ -0,0 +1,169 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Messages contiene los textos de un idioma. Cada archivo en locales/ debe definir todas
+// las llaves; los mensajes de validación se indexan por la etiqueta de binding.
+type Messages struct {
+	Language string `json:"-"`
+
+	MessageErrorID                 string `json:"error_id"`
+	MessageErrorVersion            string `json:"error_version"`
+	MessageErrorJson               string `json:"error_json"`
+	MessageErrorQuery              string `json:"error_query"`
+	MessageErrorMediaType          string `json:"error_media_type"`
+	MessageErrorCreation           string `json:"error_creation"`
+	MessageErrorGetUsers           string `json:"error_get_users"`
+	MessageErrorGetUser            string `json:"error_get_user"`
+	MessageErrorUserNotFound       string `json:"error_user_not_found"`
+	MessageErrorUpdateUser         string `json:"error_update_user"`
+	MessageErrorDeleteUser         string `json:"error_delete_user"`
+	MessageErrorRestoreUser        string `json:"error_restore_user"`
+	MessageErrorGetAudit           string `json:"error_get_audit"`
+	MessageErrorTimeout            string `json:"error_timeout"`
+	MessageErrorConflict           string `json:"error_conflict"`
+	MessageErrorValidation         string `json:"error_validation"`
+	MessageErrorPrecondition       string `json:"error_precondition"`
+	MessageErrorUnavailable        string `json:"error_unavailable"`
+	MessageErrorCredentials        string `json:"error_credentials"`
+	MessageErrorPassword           string `json:"error_password"`
+	MessageErrorLogin              string `json:"error_login"`
+	MessageErrorAuthRequired       string `json:"error_auth_required"`
+	MessageErrorToken              string `json:"error_token"`
+	MessageErrorForbidden          string `json:"error_forbidden"`
+	MessageErrorRoleNotFound       string `json:"error_role_not_found"`
+	MessageErrorRoleConflict       string `json:"error_role_conflict"`
+	MessageErrorGetRoles           string `json:"error_get_roles"`
+	MessageErrorUpdateRole         string `json:"error_update_role"`
+	MessageErrorAPIKey             string `json:"error_api_key"`
+	MessageErrorAPIKeyID           string `json:"error_api_key_id"`
+	MessageErrorAPIKeyNotFound     string `json:"error_api_key_not_found"`
+	MessageErrorGetAPIKeys         string `json:"error_get_api_keys"`
+	MessageErrorUpdateAPIKey       string `json:"error_update_api_key"`
+	MessageErrorRefreshToken       string `json:"error_refresh_token"`
+	MessageErrorSessionID          string `json:"error_session_id"`
+	MessageErrorSessionNotFound    string `json:"error_session_not_found"`
+	MessageErrorGetSessions        string `json:"error_get_sessions"`
+	MessageErrorRevokeSession      string `json:"error_revoke_session"`
+	MessageErrorMFARequired        string `json:"error_mfa_required"`
+	MessageErrorMFANotFound        string `json:"error_mfa_not_found"`
+	MessageErrorMFAConflict        string `json:"error_mfa_conflict"`
+	MessageErrorGetMFA             string `json:"error_get_mfa"`
+	MessageErrorUpdateMFA          string `json:"error_update_mfa"`
+	MessageErrorAccountLocked      string `json:"error_account_locked"`
+	MessageErrorLoginThrottled     string `json:"error_login_throttled"`
+	MessageErrorGetLockout         string `json:"error_get_lockout"`
+	MessageErrorUnlock             string `json:"error_unlock"`
+	MessageErrorEmailToken         string `json:"error_email_token"`
+	MessageErrorEmailVerified      string `json:"error_email_verified"`
+	MessageErrorEmailVerify        string `json:"error_email_verification"`
+	MessageErrorPasswordReset      string `json:"error_password_reset"`
+	MessageErrorResetThrottled     string `json:"error_reset_throttled"`
+	MessageErrorOIDCClientNotFound string `json:"error_oidc_client_not_found"`
+	MessageErrorGetOIDCClients     string `json:"error_get_oidc_clients"`
+	MessageErrorUpdateOIDCClient   string `json:"error_update_oidc_client"`
+	MessageErrorOIDCPublicClient   string `json:"error_oidc_public_client"`
+	MessageErrorAuthorizeRequest   string `json:"error_authorization_request"`
+	MessageErrorAuthorize          string `json:"error_authorize"`
+	MessageErrorGetSigningKeys     string `json:"error_get_signing_keys"`
+	MessageErrorRotateSigningKey   string `json:"error_rotate_signing_key"`
+	MessageErrorSCIMSyntax         string `json:"error_scim_syntax"`
+	MessageErrorSCIMFilter         string `json:"error_scim_filter"`
+	MessageErrorSCIMPath           string `json:"error_scim_path"`
+	MessageErrorSCIMValue          string `json:"error_scim_value"`
+	MessageErrorSCIMNoTarget       string `json:"error_scim_no_target"`
+	MessageErrorSCIMMutability     string `json:"error_scim_mutability"`
+
+	Validation map[string]string `json:"validation"`
+}
+
+// Field devuelve el mensaje de validación para la etiqueta indicada, sustituyendo
+// {param} por el parámetro de la regla, o el mensaje por defecto si no existe.
+func (m *Messages) Field(tag, param string) string {
+	message, ok := m.Validation[tag]
+	if !ok {
+		message = m.Validation["default"]
+	}
+	return strings.ReplaceAll(message, "{param}", param)
+}
+
+type Catalog struct {
+	messages []*Messages
+	matcher  language.Matcher
+}
+
+// NewCatalog carga los idiomas embebidos y usa defaultLanguage cuando el cliente no
+// envía Accept-Language o ninguno de sus idiomas está disponible.
+func NewCatalog(defaultLanguage string) (*Catalog, error) {
+	defaultTag, err := language.Parse(defaultLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("idioma por defecto inválido '%s': %v", defaultLanguage, err)
+	}
+
+	files, err := locales.ReadDir("locales")
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []language.Tag
+	var messages []*Messages
+	for _, file := range files {
+		name := strings.TrimSuffix(file.Name(), path.Ext(file.Name()))
+		tag, err := language.Parse(name)
+		if err != nil {
+			return nil, fmt.Errorf("archivo de idioma inválido '%s': %v", file.Name(), err)
+		}
+
+		data, err := locales.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		localized := &Messages{Language: tag.String()}
+		if err := json.Unmarshal(data, localized); err != nil {
+			return nil, fmt.Errorf("error al leer el archivo de idioma '%s': %v", file.Name(), err)
+		}
+
+		// El primer idioma del matcher es el que se usa como respaldo
+		if tag == defaultTag {
+			tags = append([]language.Tag{tag}, tags...)
+			messages = append([]*Messages{localized}, messages...)
+			continue
+		}
+		tags = append(tags, tag)
+		messages = append(messages, localized)
+	}
+
+	if len(tags) == 0 || tags[0] != defaultTag {
+		return nil, fmt.Errorf("el idioma por defecto '%s' no tiene archivo de mensajes", defaultLanguage)
+	}
+
+	return &Catalog{messages: messages, matcher: language.NewMatcher(tags)}, nil
+}
+
+// Match selecciona los mensajes que mejor coinciden con un encabezado Accept-Language.
+func (c *Catalog) Match(acceptLanguage string) *Messages {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return c.Default()
+	}
+	_, index, confidence := c.matcher.Match(tags...)
+	if confidence == language.No {
+		return c.Default()
+	}
+	return c.messages[index]
+}
+
+func (c *Catalog) Default() *Messages {
+	return c.messages[0]
+}
@@ -0,0 +1,77 @@
+package i18n
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: todos los idiomas definen todos los mensajes
+func TestCatalogComplete(t *testing.T) {
+	catalog, err := NewCatalog("es")
+	require.NoError(t, err)
+
+	validationKeys := catalog.Default().Validation
+	for _, messages := range catalog.messages {
+		value := reflect.ValueOf(*messages)
+		for i := 0; i < value.NumField(); i++ {
+			if value.Field(i).Kind() == reflect.String {
+				assert.NotEmpty(t, value.Field(i).String(), "%s: missing %s", messages.Language, value.Type().Field(i).Name)
+			}
+		}
+		for key := range validationKeys {
+			assert.NotEmpty(t, messages.Validation[key], "%s: missing validation message %s", messages.Language, key)
+		}
+	}
+}
+
+// Caso de prueba: selección del idioma a partir de Accept-Language
+func TestCatalogMatch(t *testing.T) {
+	catalog, err := NewCatalog("es")
+	require.NoError(t, err)
+
+	cases := map[string]string{
+		"":                          "es",
+		"en":                        "en",
+		"en-US,en;q=0.9":            "en",
+		"es-MX":                     "es",
+		"fr-FR, en;q=0.5":           "en",
+		"de":                        "es",
+		"en;q=0.2, es;q=0.8":        "es",
+		"not a valid header ;;;q=x": "es",
+	}
+	for header, expected := range cases {
+		assert.Equal(t, expected, catalog.Match(header).Language, "Accept-Language: %q", header)
+	}
+}
+
+// Caso de prueba: el idioma por defecto es configurable
+func TestCatalogDefaultLanguage(t *testing.T) {
+	catalog, err := NewCatalog("en")
+	require.NoError(t, err)
+
+	assert.Equal(t, "en", catalog.Default().Language)
+	assert.Equal(t, "en", catalog.Match("de").Language)
+}
+
+// Caso de prueba: idioma por defecto sin archivo de mensajes
+func TestCatalogMissingDefaultLanguage(t *testing.T) {
+	_, err := NewCatalog("fr")
+	assert.Error(t, err)
+
+	_, err = NewCatalog("???")
+	assert.Error(t, err)
+}
+
+// Caso de prueba: mensajes de validación con parámetro y respaldo
+func TestMessagesField(t *testing.T) {
+	catalog, err := NewCatalog("es")
+	require.NoError(t, err)
+	english := catalog.Match("en")
+
+	assert.Equal(t, "This field is required", english.Field("required", ""))
+	assert.Equal(t, "Must be at most 255 characters long", english.Field("max", "255"))
+	assert.Equal(t, "The value of this field is not valid", english.Field("unknown_tag", ""))
+}
@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"application/services"
+	"context"
+	"log"
+	"time"
+)
+
+// PurgeDeletedUsersJob elimina definitivamente, cada cierto intervalo, a los usuarios que
+// llevan eliminados lógicamente más tiempo que el de retención
+type PurgeDeletedUsersJob struct {
+	service   services.UserService
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewPurgeDeletedUsersJob(service services.UserService, retention, interval time.Duration) *PurgeDeletedUsersJob {
+	return &PurgeDeletedUsersJob{service: service, retention: retention, interval: interval}
+}
+
+// Run purga al iniciar y luego en cada intervalo hasta que se cancele ctx
+func (j *PurgeDeletedUsersJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := j.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("No se pudieron purgar los usuarios eliminados: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce purga una vez y devuelve cuántos usuarios se eliminaron
+func (j *PurgeDeletedUsersJob) RunOnce(ctx context.Context) (int64, error) {
+	purged, err := j.service.PurgeDeletedUsers(ctx, time.Now().Add(-j.retention))
+	if err != nil {
+		return 0, err
+	}
+	if purged > 0 {
+		log.Printf("Se purgaron %d usuarios eliminados hace más de %s", purged, j.retention)
+	}
+	return purged, nil
+}
@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"application/models"
+	"application/persistence/repositories/impl"
+	serviceImpl "application/services/impl"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: solo se purgan los usuarios eliminados antes del tiempo de retención
+func TestPurgeDeletedUsersJobRunOnce(t *testing.T) {
+	repo := impl.NewMemoryUserRepository()
+	ctx := context.Background()
+	require.NoError(t, repo.CreateUser(ctx, &models.User{Name: "John"}))
+	require.NoError(t, repo.DeleteUser(ctx, 1))
+	service := serviceImpl.NewUserService(repo, impl.NewMemoryUserVersionRepository(), impl.NewMemoryAuditRepository(), impl.NewMemoryTransactor())
+
+	purged, err := NewPurgeDeletedUsersJob(service, time.Hour, time.Minute).RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+
+	time.Sleep(time.Millisecond)
+	purged, err = NewPurgeDeletedUsersJob(service, time.Nanosecond, time.Minute).RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+}
+
+// Caso de prueba: el trabajo purga al iniciar y termina al cancelar el contexto
+func TestPurgeDeletedUsersJobRun(t *testing.T) {
+	repo := impl.NewMemoryUserRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, repo.CreateUser(ctx, &models.User{Name: "John"}))
+	require.NoError(t, repo.DeleteUser(ctx, 1))
+	time.Sleep(time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		NewPurgeDeletedUsersJob(serviceImpl.NewUserService(repo, impl.NewMemoryUserVersionRepository(), impl.NewMemoryAuditRepository(), impl.NewMemoryTransactor()), time.Nanosecond, time.Hour).Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		_, err := repo.RestoreUser(context.Background(), 1)
+		return err != nil
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("el trabajo no terminó al cancelar el contexto")
+	}
+}
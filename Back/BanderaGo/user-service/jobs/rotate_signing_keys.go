@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"application/services"
+	"context"
+	"log"
+	"time"
+)
+
+// RotateSigningKeysJob rota la llave de firma de OpenID Connect cuando tiene más
+// antigüedad que la configurada; revisa cada cierto intervalo
+type RotateSigningKeysJob struct {
+	service  services.OIDCService
+	maxAge   time.Duration
+	interval time.Duration
+}
+
+func NewRotateSigningKeysJob(service services.OIDCService, maxAge, interval time.Duration) *RotateSigningKeysJob {
+	return &RotateSigningKeysJob{service: service, maxAge: maxAge, interval: interval}
+}
+
+// Run revisa al iniciar y luego en cada intervalo hasta que se cancele ctx
+func (j *RotateSigningKeysJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := j.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("No se pudo rotar la llave de firma de OpenID Connect: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce rota la llave si venció o si aún no hay ninguna y devuelve si la rotó
+func (j *RotateSigningKeysJob) RunOnce(ctx context.Context) (bool, error) {
+	return j.service.RotateExpiredSigningKey(ctx, j.maxAge)
+}
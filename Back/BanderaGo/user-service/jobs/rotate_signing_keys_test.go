@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"application/config"
+	"application/oidc"
+	"application/persistence/repositories/impl"
+	serviceImpl "application/services/impl"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: la primera ejecución crea la llave y solo se vuelve a rotar cuando vence
+func TestRotateSigningKeysJobRunOnce(t *testing.T) {
+	ctx := context.Background()
+	store := impl.NewMemoryOIDCRepository()
+	sealer, err := oidc.NewSealer("0123456789abcdef0123456789abcdef")
+	require.NoError(t, err)
+	service := serviceImpl.NewOIDCService(store, impl.NewMemoryUserRepository(), impl.NewMemorySessionRepository(), sealer,
+		config.OIDCConfig{Issuer: "https://sso.example.com", TokenTTL: time.Hour, KeyRetention: time.Hour})
+
+	rotated, err := NewRotateSigningKeysJob(service, time.Hour, time.Minute).RunOnce(ctx)
+	require.NoError(t, err)
+	assert.True(t, rotated)
+	rotated, err = NewRotateSigningKeysJob(service, time.Hour, time.Minute).RunOnce(ctx)
+	require.NoError(t, err)
+	assert.False(t, rotated)
+
+	time.Sleep(time.Millisecond)
+	rotated, err = NewRotateSigningKeysJob(service, time.Nanosecond, time.Minute).RunOnce(ctx)
+	require.NoError(t, err)
+	assert.True(t, rotated)
+	keys, err := store.GetSigningKeys(ctx)
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+}
@@ -0,0 +1,196 @@
+package lockout
+
+import (
+	"application/config"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// LockedError indica que hay que esperar RetryAfter antes de volver a intentar
+type LockedError struct {
+	// Locked distingue un bloqueo temporal de la espera progresiva entre intentos
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("bloqueado por intentos fallidos; reintentar en %s", e.RetryAfter)
+	}
+	return fmt.Sprintf("demasiados intentos seguidos; reintentar en %s", e.RetryAfter)
+}
+
+// UserKey es la llave de los intentos contra un usuario existente
+func UserKey(id uint) string {
+	return "user:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// LoginKey es la llave de los intentos con un correo o nombre de usuario que no existe,
+// para que se bloquee igual que una cuenta existente
+func LoginKey(login string) string {
+	return "login:" + login
+}
+
+// ResetKey es la llave de las solicitudes para restablecer la contraseña de un correo,
+// exista o no
+func ResetKey(email string) string {
+	return "reset:" + email
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Limiter aplica la política de intentos fallidos de inicio de sesión. Cada fallo de una
+// cuenta alarga la espera hasta el siguiente intento y, al llegar al máximo, la bloquea;
+// los fallos desde una IP solo la bloquean, porque varias personas pueden compartirla.
+type Limiter struct {
+	store  Store
+	config config.LockoutConfig
+	now    func() time.Time
+}
+
+func NewLimiter(store Store, lockoutConfig config.LockoutConfig) *Limiter {
+	return &Limiter{store: store, config: lockoutConfig, now: time.Now}
+}
+
+// Attempt es un intento de inicio de sesión reservado con Reserve. Cuenta como fallo
+// hasta que se libera con Release o Succeed.
+type Attempt struct {
+	account, ip string
+	at          time.Time
+	// AccountUntil e IPUntil son hasta cuándo quedan bloqueadas la cuenta y la IP si
+	// este intento las bloqueó, o el instante cero
+	AccountUntil, IPUntil time.Time
+	accountBefore         Entry
+	ipBefore              Entry
+}
+
+// Reserve cuenta el intento de la cuenta desde la IP antes de comprobar las credenciales.
+// Comprobar la espera y contar el intento ocurren en la misma actualización atómica del
+// Store, así que los intentos simultáneos no pueden superar el máximo. Devuelve un
+// *LockedError si la cuenta o la IP deben esperar. Una IP vacía no se limita.
+func (l *Limiter) Reserve(ctx context.Context, account, ip string) (*Attempt, error) {
+	attempt := &Attempt{account: account, ip: ip, at: l.now()}
+	var err error
+	attempt.accountBefore, attempt.AccountUntil, err = l.reserve(ctx, account, l.config.MaxUserFailures, true, attempt.at)
+	if err != nil {
+		return nil, err
+	}
+	if ip == "" {
+		return attempt, nil
+	}
+	attempt.ipBefore, attempt.IPUntil, err = l.reserve(ctx, ipKey(ip), l.config.MaxIPFailures, false, attempt.at)
+	if err != nil {
+		if releaseErr := l.release(ctx, account, attempt.accountBefore, attempt.at); releaseErr != nil {
+			return nil, errors.Join(err, releaseErr)
+		}
+		return nil, err
+	}
+	return attempt, nil
+}
+
+// reserve comprueba y cuenta un intento de la llave en una sola actualización. Devuelve
+// la entrada anterior al intento y hasta cuándo queda bloqueada si este intento la bloqueó.
+func (l *Limiter) reserve(ctx context.Context, key string, maxFailures int, progressive bool, now time.Time) (Entry, time.Time, error) {
+	var before Entry
+	var lockedUntil time.Time
+	var locked *LockedError
+	_, err := l.store.Update(ctx, key, now.Add(max(l.config.Window, l.config.Duration)), func(entry *Entry) {
+		if now.Sub(entry.LastFailure) >= l.config.Window {
+			entry.Failures = 0
+		}
+		if entry.Locked(now) {
+			locked = &LockedError{Locked: true, RetryAfter: entry.LockedUntil.Sub(now)}
+			return
+		}
+		if progressive && entry.Failures > 0 {
+			if next := entry.LastFailure.Add(l.delay(entry.Failures)); now.Before(next) {
+				locked = &LockedError{RetryAfter: next.Sub(now)}
+				return
+			}
+		}
+		before = *entry
+		entry.Failures++
+		entry.LastFailure = now
+		// Al bloquear se reinicia la cuenta para que, al vencer el bloqueo, no se
+		// arrastre la espera de los intentos anteriores
+		if maxFailures > 0 && entry.Failures >= maxFailures {
+			entry.Failures = 0
+			entry.LockedUntil = now.Add(l.config.Duration)
+			lockedUntil = entry.LockedUntil
+		}
+	})
+	if err != nil {
+		return Entry{}, time.Time{}, err
+	}
+	if locked != nil {
+		return Entry{}, time.Time{}, locked
+	}
+	return before, lockedUntil, nil
+}
+
+// Release devuelve un intento reservado que no fue un fallo, como pedir el segundo factor
+func (l *Limiter) Release(ctx context.Context, attempt *Attempt) error {
+	err := l.release(ctx, attempt.account, attempt.accountBefore, attempt.at)
+	if attempt.ip != "" {
+		err = errors.Join(err, l.release(ctx, ipKey(attempt.ip), attempt.ipBefore, attempt.at))
+	}
+	return err
+}
+
+// release deshace el intento de la llave. Si nadie más intentó después se restaura la
+// entrada anterior; si no, solo se descuenta el intento.
+func (l *Limiter) release(ctx context.Context, key string, before Entry, at time.Time) error {
+	_, err := l.store.Update(ctx, key, at.Add(max(l.config.Window, l.config.Duration)), func(entry *Entry) {
+		if entry.LastFailure.Equal(at) {
+			*entry = before
+		} else if entry.Failures > 0 {
+			entry.Failures--
+		}
+	})
+	return err
+}
+
+// Succeed olvida los intentos fallidos de la cuenta y devuelve el intento a la IP; los
+// fallos anteriores de la IP se conservan
+func (l *Limiter) Succeed(ctx context.Context, attempt *Attempt) error {
+	err := l.store.Delete(ctx, attempt.account)
+	if attempt.ip != "" {
+		err = errors.Join(err, l.release(ctx, ipKey(attempt.ip), attempt.ipBefore, attempt.at))
+	}
+	return err
+}
+
+// Status devuelve los intentos fallidos recientes y el bloqueo de la cuenta
+func (l *Limiter) Status(ctx context.Context, account string) (Entry, error) {
+	entry, err := l.store.Get(ctx, account)
+	if err != nil {
+		return Entry{}, err
+	}
+	now := l.now()
+	if now.Sub(entry.LastFailure) >= l.config.Window {
+		entry.Failures = 0
+	}
+	if !entry.Locked(now) {
+		entry.LockedUntil = time.Time{}
+	}
+	return entry, nil
+}
+
+// Unlock desbloquea la cuenta y olvida sus intentos fallidos
+func (l *Limiter) Unlock(ctx context.Context, account string) error {
+	return l.store.Delete(ctx, account)
+}
+
+// delay es la espera tras el fallo número failures: Delay, 2·Delay, 4·Delay... hasta MaxDelay
+func (l *Limiter) delay(failures int) time.Duration {
+	delay := l.config.Delay
+	for i := 1; i < failures && delay < l.config.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, l.config.MaxDelay)
+}
@@ -0,0 +1,186 @@
+package lockout
+
+import (
+	"application/config"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = config.LockoutConfig{MaxUserFailures: 3, MaxIPFailures: 5, Window: 10 * time.Minute, Duration: 15 * time.Minute,
+	Delay: time.Second, MaxDelay: 3 * time.Second}
+
+// newTestLimiter devuelve un limitador con un reloj que se adelanta con advance
+func newTestLimiter(lockoutConfig config.LockoutConfig) (*Limiter, func(time.Duration)) {
+	limiter := NewLimiter(NewMemoryStore(), lockoutConfig)
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+	return limiter, func(d time.Duration) { now = now.Add(d) }
+}
+
+func lockedError(t *testing.T, err error) *LockedError {
+	t.Helper()
+	var locked *LockedError
+	require.True(t, errors.As(err, &locked), "se esperaba un *LockedError: %v", err)
+	return locked
+}
+
+// Caso de prueba: cada fallo duplica la espera hasta el máximo y al llegar al límite se bloquea la cuenta
+func TestLimiterAccount(t *testing.T) {
+	limiter, advance := newTestLimiter(testConfig)
+	ctx := context.Background()
+	account := UserKey(7)
+
+	attempt, err := limiter.Reserve(ctx, account, "10.0.0.1")
+	require.NoError(t, err)
+	assert.True(t, attempt.AccountUntil.IsZero())
+	_, err = limiter.Reserve(ctx, account, "10.0.0.1")
+	locked := lockedError(t, err)
+	assert.False(t, locked.Locked)
+	assert.Equal(t, time.Second, locked.RetryAfter)
+
+	advance(time.Second)
+	_, err = limiter.Reserve(ctx, account, "10.0.0.1")
+	require.NoError(t, err)
+	_, err = limiter.Reserve(ctx, account, "")
+	assert.Equal(t, 2*time.Second, lockedError(t, err).RetryAfter)
+	entry, err := limiter.Status(ctx, account)
+	require.NoError(t, err)
+	assert.Equal(t, 2, entry.Failures)
+
+	advance(2 * time.Second)
+	attempt, err = limiter.Reserve(ctx, account, "10.0.0.1")
+	require.NoError(t, err)
+	assert.False(t, attempt.AccountUntil.IsZero())
+	_, err = limiter.Reserve(ctx, account, "")
+	locked = lockedError(t, err)
+	assert.True(t, locked.Locked)
+	assert.Equal(t, 15*time.Minute, locked.RetryAfter)
+
+	// Otra cuenta no se ve afectada y al vencer el bloqueo se vuelve a intentar sin espera
+	_, err = limiter.Reserve(ctx, UserKey(8), "")
+	require.NoError(t, err)
+	advance(15 * time.Minute)
+	_, err = limiter.Reserve(ctx, account, "")
+	require.NoError(t, err)
+
+	// Desbloquear olvida los intentos
+	require.NoError(t, limiter.Unlock(ctx, account))
+	entry, err = limiter.Status(ctx, account)
+	require.NoError(t, err)
+	assert.Equal(t, Entry{}, entry)
+}
+
+// Caso de prueba: liberar un intento lo descuenta y acertar olvida los fallos de la cuenta pero no los de la IP
+func TestLimiterReleaseAndSucceed(t *testing.T) {
+	limiter, advance := newTestLimiter(testConfig)
+	ctx := context.Background()
+	account := UserKey(7)
+
+	_, err := limiter.Reserve(ctx, account, "10.0.0.1")
+	require.NoError(t, err)
+	advance(time.Second)
+	attempt, err := limiter.Reserve(ctx, account, "10.0.0.1")
+	require.NoError(t, err)
+	require.NoError(t, limiter.Release(ctx, attempt))
+	entry, err := limiter.Status(ctx, account)
+	require.NoError(t, err)
+	assert.Equal(t, 1, entry.Failures)
+	ipEntry, err := limiter.store.Get(ctx, ipKey("10.0.0.1"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, ipEntry.Failures)
+
+	// El intento liberado no alarga la espera: basta la del primer fallo
+	attempt, err = limiter.Reserve(ctx, account, "10.0.0.1")
+	require.NoError(t, err)
+	require.NoError(t, limiter.Succeed(ctx, attempt))
+	entry, err = limiter.Status(ctx, account)
+	require.NoError(t, err)
+	assert.Equal(t, Entry{}, entry)
+	ipEntry, err = limiter.store.Get(ctx, ipKey("10.0.0.1"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, ipEntry.Failures)
+}
+
+// Caso de prueba: los intentos simultáneos contra una cuenta no pasan del máximo antes del bloqueo
+func TestLimiterConcurrentAttempts(t *testing.T) {
+	lockoutConfig := testConfig
+	lockoutConfig.Delay, lockoutConfig.MaxDelay = 0, 0
+	limiter := NewLimiter(NewMemoryStore(), lockoutConfig)
+	ctx := context.Background()
+	account := UserKey(7)
+
+	const attempts = 20
+	var wg sync.WaitGroup
+	var reserved, rejected atomic.Int32
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := limiter.Reserve(ctx, account, "")
+			if err == nil {
+				reserved.Add(1)
+				return
+			}
+			var locked *LockedError
+			if errors.As(err, &locked) && locked.Locked {
+				rejected.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(lockoutConfig.MaxUserFailures), reserved.Load())
+	assert.Equal(t, int32(attempts-lockoutConfig.MaxUserFailures), rejected.Load())
+	entry, err := limiter.Status(ctx, account)
+	require.NoError(t, err)
+	assert.False(t, entry.LockedUntil.IsZero())
+}
+
+// Caso de prueba: los fallos desde una IP contra varias cuentas bloquean la IP sin espera progresiva
+func TestLimiterIP(t *testing.T) {
+	limiter, advance := newTestLimiter(testConfig)
+	ctx := context.Background()
+	for i := uint(1); i <= 4; i++ {
+		attempt, err := limiter.Reserve(ctx, UserKey(i), "10.0.0.1")
+		require.NoError(t, err)
+		assert.True(t, attempt.IPUntil.IsZero())
+	}
+	attempt, err := limiter.Reserve(ctx, UserKey(5), "10.0.0.1")
+	require.NoError(t, err)
+	assert.False(t, attempt.IPUntil.IsZero())
+
+	// El intento rechazado por la IP no cuenta para la cuenta
+	_, err = limiter.Reserve(ctx, UserKey(6), "10.0.0.1")
+	assert.True(t, lockedError(t, err).Locked)
+	entry, err := limiter.Status(ctx, UserKey(6))
+	require.NoError(t, err)
+	assert.Equal(t, 0, entry.Failures)
+	_, err = limiter.Reserve(ctx, UserKey(6), "10.0.0.2")
+	require.NoError(t, err)
+
+	// Tras la ventana sin fallos se olvidan los anteriores
+	advance(15 * time.Minute)
+	attempt, err = limiter.Reserve(ctx, UserKey(6), "10.0.0.1")
+	require.NoError(t, err)
+	assert.True(t, attempt.IPUntil.IsZero())
+}
+
+// Caso de prueba: sin espera configurada solo se bloquea al llegar al límite
+func TestLimiterWithoutDelay(t *testing.T) {
+	lockoutConfig := testConfig
+	lockoutConfig.Delay, lockoutConfig.MaxDelay = 0, 0
+	limiter, _ := newTestLimiter(lockoutConfig)
+	ctx := context.Background()
+
+	_, err := limiter.Reserve(ctx, LoginKey("nadie"), "")
+	require.NoError(t, err)
+	_, err = limiter.Reserve(ctx, LoginKey("nadie"), "")
+	assert.NoError(t, err)
+}
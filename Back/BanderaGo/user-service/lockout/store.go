@@ -0,0 +1,100 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Entry son los intentos fallidos de una llave, una cuenta o una IP
+type Entry struct {
+	Failures    int
+	LastFailure time.Time
+	LockedUntil time.Time
+}
+
+// Locked indica si la llave sigue bloqueada en el instante indicado
+func (e Entry) Locked(now time.Time) bool {
+	return now.Before(e.LockedUntil)
+}
+
+// Store guarda los contadores de intentos fallidos. Una entrada que no existe o ya
+// expiró se devuelve vacía.
+type Store interface {
+	Get(ctx context.Context, key string) (Entry, error)
+	// Update aplica fn a la entrada de la llave de forma atómica y la conserva hasta expiresAt
+	Update(ctx context.Context, key string, expiresAt time.Time, fn func(entry *Entry)) (Entry, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// pruneInterval es cada cuánto MemoryStore elimina las entradas expiradas
+const pruneInterval = time.Minute
+
+// MemoryStore guarda los contadores en memoria. Cada instancia lleva sus propios
+// contadores, así que con varias instancias se necesita un Store compartido.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastPrune time.Time
+}
+
+type memoryEntry struct {
+	Entry
+	expiresAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]memoryEntry{}}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) (Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return Entry{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || !time.Now().Before(entry.expiresAt) {
+		return Entry{}, nil
+	}
+	return entry.Entry, nil
+}
+
+func (s *MemoryStore) Update(ctx context.Context, key string, expiresAt time.Time, fn func(entry *Entry)) (Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return Entry{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastPrune) >= pruneInterval {
+		for k, entry := range s.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastPrune = now
+	}
+
+	entry, ok := s.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		entry = memoryEntry{}
+	}
+	fn(&entry.Entry)
+	entry.expiresAt = expiresAt
+	s.entries[key] = entry
+	return entry.Entry, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
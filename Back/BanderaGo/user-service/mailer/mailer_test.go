@@ -0,0 +1,189 @@
+package mailer
+
+import (
+	"application/config"
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testData = LinkData{Name: "Ana <b>", Email: "ana@example.com", Link: "https://app.example.com/verify-email?token=abc&x=1",
+	ExpiresAt: time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)}
+
+// readMessage decodifica el correo y devuelve su asunto y sus partes por tipo de contenido
+func readMessage(t *testing.T, raw []byte) (string, map[string]string) {
+	t.Helper()
+	parsed, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	parts := map[string]string{}
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		// multipart decodifica quoted-printable al leer la parte
+		content, err := io.ReadAll(part)
+		require.NoError(t, err)
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(content)
+	}
+	return subject, parts
+}
+
+// Caso de prueba: las plantillas se arman en el idioma pedido o en el idioma por defecto, y el HTML se escapa
+func TestTemplates(t *testing.T) {
+	templates, err := NewTemplates("es")
+	require.NoError(t, err)
+
+	message, err := templates.Render("en", TemplateVerifyEmail, testData)
+	require.NoError(t, err)
+	assert.Equal(t, "Confirm your email", message.Subject)
+	assert.Contains(t, message.Text, "Hi Ana <b>,")
+	assert.Contains(t, message.Text, testData.Link)
+	assert.Contains(t, message.Text, "May 1, 2024 at 12:30 UTC")
+	assert.Contains(t, message.HTML, "Ana &lt;b&gt;")
+	assert.Contains(t, message.HTML, `href="https://app.example.com/verify-email?token=abc&amp;x=1"`)
+
+	message, err = templates.Render("fr", TemplateResetPassword, testData)
+	require.NoError(t, err)
+	assert.Equal(t, "Restablece tu contraseña", message.Subject)
+	assert.Contains(t, message.Text, "01/05/2024 a las 12:30 UTC")
+
+	_, err = templates.Render("es", "bienvenida", testData)
+	assert.Error(t, err)
+	_, err = NewTemplates("fr")
+	assert.Error(t, err)
+}
+
+// Caso de prueba: el correo guardado en un archivo .eml trae ambas versiones y el asunto codificado
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	mailer := NewFileMailer(dir, "Bandera <no-reply@example.com>")
+	message := Message{To: "ana@example.com", Subject: "Restablece tu contraseña", Text: "Hola Ana:\nabre el enlace\n", HTML: "<p>Hola Ana</p>"}
+
+	require.NoError(t, mailer.Send(context.Background(), message))
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	raw, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+
+	subject, parts := readMessage(t, raw)
+	assert.Equal(t, message.Subject, subject)
+	assert.Equal(t, "Hola Ana:\r\nabre el enlace\r\n", parts["text/plain"])
+	assert.Equal(t, message.HTML, parts["text/html"])
+	assert.Contains(t, string(raw), "From: \"Bandera\" <no-reply@example.com>\r\n")
+	assert.Contains(t, string(raw), "@example.com>\r\n")
+
+	assert.Error(t, mailer.Send(context.Background(), Message{To: "no es un correo"}))
+}
+
+// Caso de prueba: el mailer en memoria conserva los correos en orden
+func TestMemoryMailer(t *testing.T) {
+	mailer := NewMemoryMailer()
+	ctx := context.Background()
+	require.NoError(t, mailer.Send(ctx, Message{To: "ana@example.com", Subject: "uno"}))
+	require.NoError(t, mailer.Send(ctx, Message{To: "luis@example.com", Subject: "dos"}))
+	require.NoError(t, mailer.Send(ctx, Message{To: "ana@example.com", Subject: "tres"}))
+
+	assert.Len(t, mailer.Messages(), 3)
+	last, ok := mailer.Last("ana@example.com")
+	require.True(t, ok)
+	assert.Equal(t, "tres", last.Subject)
+	_, ok = mailer.Last("eva@example.com")
+	assert.False(t, ok)
+}
+
+// Caso de prueba: el mailer SMTP entrega el correo al servidor con el remitente y el destinatario
+func TestSMTPMailer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go serveSMTP(listener, received)
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	mailer := NewSMTPMailer(config.MailConfig{SMTPHost: "127.0.0.1", SMTPPort: port, From: "no-reply@example.com"})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, mailer.Send(ctx, Message{To: "Ana <ana@example.com>", Subject: "Hola", Text: "texto", HTML: "<p>html</p>"}))
+
+	commands := <-received
+	assert.Contains(t, commands, "MAIL FROM:<no-reply@example.com>")
+	assert.Contains(t, commands, "RCPT TO:<ana@example.com>")
+	data := commands[len(commands)-1]
+	subject, parts := readMessage(t, []byte(data))
+	assert.Equal(t, "Hola", subject)
+	assert.Equal(t, "texto", parts["text/plain"])
+
+	// Sin servidor el envío falla
+	listener.Close()
+	mailer = NewSMTPMailer(config.MailConfig{SMTPHost: "127.0.0.1", SMTPPort: port, From: "no-reply@example.com"})
+	assert.Error(t, mailer.Send(ctx, Message{To: "ana@example.com"}))
+}
+
+// serveSMTP atiende una conversación SMTP mínima y envía los comandos recibidos, con el
+// contenido de DATA al final
+func serveSMTP(listener net.Listener, received chan<- []string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	var commands []string
+	var data strings.Builder
+	reply("220 localhost ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		commands = append(commands, line)
+		switch {
+		case strings.HasPrefix(line, "EHLO"):
+			reply("250 localhost")
+		case line == "DATA":
+			reply("354 continuar")
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil || dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			reply("250 " + strconv.Itoa(data.Len()))
+		case line == "QUIT":
+			reply("221 adiós")
+			received <- append(commands, data.String())
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
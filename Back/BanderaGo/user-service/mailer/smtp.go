@@ -0,0 +1,94 @@
+package mailer
+
+import (
+	"application/config"
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPMailer envía los correos a un servidor SMTP. Usa STARTTLS si el servidor lo ofrece
+// y lo exige para autenticarse con usuario y contraseña.
+type SMTPMailer struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPMailer(mailConfig config.MailConfig) *SMTPMailer {
+	return &SMTPMailer{
+		addr:     net.JoinHostPort(mailConfig.SMTPHost, strconv.Itoa(mailConfig.SMTPPort)),
+		host:     mailConfig.SMTPHost,
+		username: mailConfig.SMTPUsername,
+		password: mailConfig.SMTPPassword,
+		from:     mailConfig.From,
+	}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, message Message) error {
+	raw, err := build(m.from, message, time.Now())
+	if err != nil {
+		return err
+	}
+	sender, _ := mail.ParseAddress(m.from)
+	recipient, _ := mail.ParseAddress(message.To)
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	// El plazo de la solicitud también limita la conversación con el servidor
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host, MinVersion: tls.VersionTLS12}); err != nil {
+			return err
+		}
+	}
+	if m.username != "" {
+		// PlainAuth se niega a enviar la contraseña sin TLS salvo a localhost
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(sender.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(recipient.Address); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(raw); err != nil {
+		writer.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	if err := client.Quit(); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	return nil
+}
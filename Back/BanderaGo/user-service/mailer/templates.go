@@ -0,0 +1,108 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// Nombres de las plantillas de correo
+const (
+	TemplateVerifyEmail   = "verify_email"
+	TemplateResetPassword = "reset_password"
+)
+
+// LinkData son los datos de los correos que llevan un enlace de un solo uso
+type LinkData struct {
+	Name      string
+	Email     string
+	Link      string
+	ExpiresAt time.Time
+}
+
+// Templates arma los correos a partir de las plantillas de cada idioma. Cada plantilla
+// tiene una versión <nombre>.txt, que además define el asunto en el bloque "subject", y
+// otra <nombre>.html.
+type Templates struct {
+	defaultLanguage string
+	text            map[string]*texttemplate.Template
+	html            map[string]*htmltemplate.Template
+}
+
+// NewTemplates carga las plantillas embebidas. Cada carpeta de templates/ es un idioma y
+// debe tener las mismas plantillas que la del idioma por defecto.
+func NewTemplates(defaultLanguage string) (*Templates, error) {
+	templates := &Templates{
+		defaultLanguage: defaultLanguage,
+		text:            map[string]*texttemplate.Template{},
+		html:            map[string]*htmltemplate.Template{},
+	}
+	languages, err := templateFiles.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, language := range languages {
+		dir := path.Join("templates", language.Name())
+		names, err := fs.Glob(templateFiles, path.Join(dir, "*.txt"))
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			name = strings.TrimSuffix(path.Base(name), ".txt")
+			key := language.Name() + "/" + name
+			if templates.text[key], err = texttemplate.ParseFS(templateFiles, path.Join(dir, name+".txt")); err != nil {
+				return nil, fmt.Errorf("plantilla de correo inválida '%s': %v", key, err)
+			}
+			if templates.text[key].Lookup("subject") == nil {
+				return nil, fmt.Errorf("la plantilla de correo '%s' no define el asunto", key)
+			}
+			if templates.html[key], err = htmltemplate.ParseFS(templateFiles, path.Join(dir, name+".html")); err != nil {
+				return nil, fmt.Errorf("plantilla de correo inválida '%s': %v", key, err)
+			}
+		}
+	}
+	for _, name := range []string{TemplateVerifyEmail, TemplateResetPassword} {
+		if _, ok := templates.text[defaultLanguage+"/"+name]; !ok {
+			return nil, fmt.Errorf("el idioma por defecto '%s' no tiene la plantilla de correo '%s'", defaultLanguage, name)
+		}
+	}
+	return templates, nil
+}
+
+// Render arma el correo con la plantilla en el idioma indicado, o en el idioma por
+// defecto si no existe. El destinatario lo completa quien lo envía.
+func (t *Templates) Render(language, name string, data any) (Message, error) {
+	key := language + "/" + name
+	if _, ok := t.text[key]; !ok {
+		key = t.defaultLanguage + "/" + name
+	}
+	text, ok := t.text[key]
+	if !ok {
+		return Message{}, fmt.Errorf("no existe la plantilla de correo '%s'", name)
+	}
+
+	var subject, textBody, htmlBody bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := text.Execute(&textBody, data); err != nil {
+		return Message{}, err
+	}
+	if err := t.html[key].Execute(&htmlBody, data); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(textBody.String()) + "\n",
+		HTML:    htmlBody.String(),
+	}, nil
+}
@@ -5,20 +5,46 @@ import (
 	"application/config"
 	"application/controllers"
 	facadeImpl "application/facade/impl"
+	"application/i18n"
+	"application/jobs"
+	"application/lockout"
+	"application/mailer"
+	"application/middlewares"
+	"application/models"
+	"application/oidc"
+	"application/passwords"
 	"application/persistence/contexts"
+	"application/persistence/migrations"
 	"application/persistence/repositories"
 	repoImpl "application/persistence/repositories/impl"
+	"application/scim"
 	serviceImpl "application/services/impl"
+	"application/tokens"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"log"
+	"os"
+	"time"
 
 	docs "application/docs"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"gorm.io/gorm"
 )
 
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Access token with the Bearer prefix, issued by POST /api/auth/token
+//
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key for service-to-service callers, created with POST /api/api-keys
 func main() {
 	// Cargar las variables de entorno desde el archivo .env
 	if err := config.LoadEnvVariables(); err != nil {
@@ -30,9 +56,6 @@ func main() {
 		log.Fatalf("No se pudo obtener el valor del puerto: %v", err)
 	}
 
-	// Configurar el enrutador Gin
-	router := gin.Default()
-
 	docs.SwaggerInfo.Title = "GoLang"
 
 	// Inicializar la conexión a la base de datos
@@ -41,39 +64,399 @@ func main() {
 		panic(err)
 	}
 
+	// "migrate" administra el esquema de la base de datos en lugar de levantar el servidor
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), os.Stdout, userConfig, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// "token" firma un token de acceso para un sujeto en lugar de levantar el servidor
+	if len(os.Args) > 1 && os.Args[1] == "token" {
+		if err := runToken(os.Stdout, userConfig, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Preparar las llaves con las que se emiten y verifican los tokens de acceso
+	tokenManager, err := tokens.NewManager(userConfig.Auth)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Crear los repositorios según el motor configurado en DB_DRIVER
+	store, err := newStorage(userConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Cargar el catálogo de mensajes con el idioma por defecto configurado
+	catalog, err := i18n.NewCatalog(userConfig.DefaultLanguage)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Purgar en segundo plano a los usuarios eliminados hace más de DELETED_RETENTION
+	if userConfig.DeletedRetention > 0 {
+		purgeJob := jobs.NewPurgeDeletedUsersJob(store.userService(), userConfig.DeletedRetention, userConfig.PurgeInterval)
+		go purgeJob.Run(context.Background())
+	}
+
+	// Las llaves de firma de OpenID Connect se guardan cifradas con OIDC_KEY_SECRET
+	sealer, err := oidcSealer(userConfig.OIDC)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Rotar en segundo plano la llave de firma de OpenID Connect cada OIDC_KEY_ROTATION
+	if userConfig.OIDC.KeyRotation > 0 {
+		rotateJob := jobs.NewRotateSigningKeysJob(store.oidcService(sealer, userConfig.OIDC), userConfig.OIDC.KeyRotation, time.Hour)
+		go rotateJob.Run(context.Background())
+	}
+
+	router := newRouter(store, userConfig, catalog, tokenManager, newMailer(userConfig.Mail), sealer)
+
+	log.Printf("Servidor escuchando en el puerto %s", port)
+	log.Fatal(router.Run(fmt.Sprintf(":%v", port)))
+}
+
+// storage agrupa los repositorios del motor configurado
+type storage struct {
+	users       repositories.UserRepository
+	versions    repositories.UserVersionRepository
+	audit       repositories.AuditRepository
+	roles       repositories.RoleRepository
+	apiKeys     repositories.APIKeyRepository
+	sessions    repositories.SessionRepository
+	mfa         repositories.MFARepository
+	tx          repositories.Transactor
+	emailTokens repositories.EmailTokenRepository
+	oidc        repositories.OIDCRepository
+}
+
+func (s *storage) userService() *serviceImpl.UserServiceImpl {
+	return serviceImpl.NewUserService(s.users, s.versions, s.audit, s.tx)
+}
+
+func (s *storage) oidcService(sealer *oidc.Sealer, oidcConfig config.OIDCConfig) *serviceImpl.OIDCServiceImpl {
+	return serviceImpl.NewOIDCService(s.oidc, s.users, s.sessions, sealer, oidcConfig)
+}
+
+func newStorage(userConfig *config.UserConfig) (*storage, error) {
+	if userConfig.DBDriver == config.DriverMemory {
+		return &storage{
+			users:       repoImpl.NewMemoryUserRepository(),
+			versions:    repoImpl.NewMemoryUserVersionRepository(),
+			audit:       repoImpl.NewMemoryAuditRepository(),
+			roles:       repoImpl.NewMemoryRoleRepository(),
+			apiKeys:     repoImpl.NewMemoryAPIKeyRepository(),
+			sessions:    repoImpl.NewMemorySessionRepository(),
+			mfa:         repoImpl.NewMemoryMFARepository(),
+			tx:          repoImpl.NewMemoryTransactor(),
+			emailTokens: repoImpl.NewMemoryEmailTokenRepository(),
+			oidc:        repoImpl.NewMemoryOIDCRepository(),
+		}, nil
+	}
+
+	db, err := openDatabase(userConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if userConfig.MigrateOnStartup {
+		migrator, err := migrations.NewMigrator(db, userConfig.DBTable)
+		if err != nil {
+			return nil, err
+		}
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for _, migration := range applied {
+			log.Printf("Migración %04d_%s aplicada", migration.Version, migration.Name)
+		}
+	}
+
+	gormDB := repositories.NewGormDB(db)
+	return &storage{
+		users:       repoImpl.NewUserRepository(gormDB),
+		versions:    repoImpl.NewUserVersionRepository(gormDB),
+		audit:       repoImpl.NewAuditRepository(gormDB),
+		roles:       repoImpl.NewRoleRepository(gormDB),
+		apiKeys:     repoImpl.NewAPIKeyRepository(gormDB),
+		sessions:    repoImpl.NewSessionRepository(gormDB),
+		mfa:         repoImpl.NewMFARepository(gormDB),
+		tx:          repoImpl.NewGormTransactor(gormDB),
+		emailTokens: repoImpl.NewEmailTokenRepository(gormDB),
+		oidc:        repoImpl.NewOIDCRepository(gormDB),
+	}, nil
+}
+
+// openDatabase abre la conexión del motor configurado en DB_DRIVER
+func openDatabase(userConfig *config.UserConfig) (*gorm.DB, error) {
+	switch userConfig.DBDriver {
+	case config.DriverMemory:
+		return nil, fmt.Errorf("el motor '%s' no usa base de datos", config.DriverMemory)
+	case config.DriverPostgres:
+		postgresDB, err := contexts.NewPostgresDB(userConfig)
+		if err != nil {
+			return nil, err
+		}
+		return postgresDB.DB, nil
+	case config.DriverSQLite:
+		sqliteDB, err := contexts.NewSQLiteDB(userConfig)
+		if err != nil {
+			return nil, err
+		}
+		return sqliteDB.DB, nil
+	}
+
 	mySQLDB, err := contexts.NewMySQLDB(userConfig)
 	if err != nil {
+		return nil, err
+	}
+	return mySQLDB.DB, nil
+}
+
+// newMailer crea el medio de envío de correos configurado en MAIL_DRIVER
+func newMailer(mailConfig config.MailConfig) mailer.Mailer {
+	switch mailConfig.Driver {
+	case config.MailSMTP:
+		return mailer.NewSMTPMailer(mailConfig)
+	case config.MailMemory:
+		return mailer.NewMemoryMailer()
+	}
+	return mailer.NewFileMailer(mailConfig.Dir, mailConfig.From)
+}
+
+// linkSigner firma los enlaces de los correos con MAIL_TOKEN_SECRET. Sin secreto, con
+// llaves RS256 y sin JWT_SECRET, se genera uno al iniciar y los enlaces enviados dejan de
+// valer al reiniciar.
+func linkSigner(mailConfig config.MailConfig) (*tokens.LinkSigner, error) {
+	if mailConfig.TokenSecret != "" {
+		return tokens.NewLinkSigner([]byte(mailConfig.TokenSecret)), nil
+	}
+	log.Printf("MAIL_TOKEN_SECRET no está configurado; los enlaces de los correos dejarán de valer al reiniciar")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return tokens.NewLinkSigner(secret), nil
+}
+
+// oidcSealer cifra las llaves de firma de OpenID Connect con OIDC_KEY_SECRET. Sin secreto,
+// con llaves RS256 y sin JWT_SECRET, se genera uno al iniciar y al reiniciar se rota la
+// llave porque la anterior ya no se puede descifrar.
+func oidcSealer(oidcConfig config.OIDCConfig) (*oidc.Sealer, error) {
+	if oidcConfig.KeySecret != "" {
+		return oidc.NewSealer(oidcConfig.KeySecret)
+	}
+	log.Printf("OIDC_KEY_SECRET no está configurado; la llave de firma de OpenID Connect se rotará al reiniciar")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return oidc.NewSealer(hex.EncodeToString(secret))
+}
+
+// newRouter arma la aplicación completa sobre los repositorios indicados
+func newRouter(store *storage, userConfig *config.UserConfig, catalog *i18n.Catalog, tokenManager *tokens.Manager, mail mailer.Mailer, sealer *oidc.Sealer) *gin.Engine {
+	// Configurar el enrutador Gin
+	router := gin.Default()
+	// Sin proxies de confianza gin ignora X-Forwarded-For, que cualquiera puede falsificar
+	// para evadir los límites por IP
+	if err := router.SetTrustedProxies(userConfig.TrustedProxies); err != nil {
 		log.Fatal(err)
 	}
 
-	var myGormDB repositories.GormDB = mySQLDB.DB
+	// Identificar la solicitud para la auditoría y los registros
+	router.Use(middlewares.RequestID())
 
-	// Crear instancia de UserRepositoryImpl
-	userRepo := repoImpl.NewUserRepository(myGormDB)
+	// Las rutas de usuarios y de auditoría exigen un token de acceso o una llave de API;
+	// su sujeto es el actor que queda en la auditoría
+	apiKeyService := serviceImpl.NewAPIKeyService(store.apiKeys)
+	sessionService := serviceImpl.NewSessionService(store.sessions, store.users, userConfig.Auth.SessionCacheTTL)
+	authenticate := middlewares.Authenticate(tokenManager, apiKeyService, sessionService, catalog)
 
-	// Crear instancia de UserServiceImpl usando UserRepository
-	userService := serviceImpl.NewUserService(userRepo)
+	// Crear instancia de UserServiceImpl usando los repositorios
+	userService := store.userService()
 
 	// Crear instancia de UserFacadeImpl usando UserService
 	userFacade := facadeImpl.NewUserFacade(userService)
 
 	// Crear instancia de UserController usando UserFacade
-	userController := controllers.NewUserController(userFacade)
+	userController := controllers.NewUserController(userFacade, catalog)
+
+	// Las contraseñas y el inicio de sesión siguen la misma cadena servicio -> fachada -> controlador
+	hasher, policy := passwords.NewHasher(userConfig.Password), passwords.NewPolicy(userConfig.Password)
+	limiter := lockout.NewLimiter(lockout.NewMemoryStore(), userConfig.Lockout)
+	authService := serviceImpl.NewAuthService(store.users, store.sessions, sessionService, store.mfa, store.audit, store.tx,
+		hasher, policy, tokenManager, limiter)
+	authController := controllers.NewAuthController(facadeImpl.NewAuthFacade(authService), catalog)
+
+	// Los enlaces para verificar el correo y restablecer la contraseña se envían por correo
+	templates, err := mailer.NewTemplates(userConfig.DefaultLanguage)
+	if err != nil {
+		log.Fatal(err)
+	}
+	signer, err := linkSigner(userConfig.Mail)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Cada solicitud de restablecimiento cuenta; al llegar al máximo por correo o por IP se
+	// rechazan las siguientes durante PASSWORD_RESET_WINDOW
+	resetLimiter := lockout.NewLimiter(lockout.NewMemoryStore(), config.LockoutConfig{
+		MaxUserFailures: userConfig.Mail.ResetMaxPerEmail, MaxIPFailures: userConfig.Mail.ResetMaxPerIP,
+		Window: userConfig.Mail.ResetWindow, Duration: userConfig.Mail.ResetWindow})
+	emailService := serviceImpl.NewEmailService(store.users, store.emailTokens, sessionService, store.audit, store.tx,
+		hasher, policy, limiter, resetLimiter, mail, templates, signer, userConfig.Mail)
+	emailController := controllers.NewEmailController(facadeImpl.NewEmailFacade(emailService), catalog)
+
+	// Los roles también resuelven los permisos de cada solicitud autenticada
+	roleService := serviceImpl.NewRoleService(store.roles, store.users, store.audit, store.tx, userConfig.MFA.RequiredRoles)
+	roleController := controllers.NewRoleController(facadeImpl.NewRoleFacade(roleService), catalog)
+	sessionController := controllers.NewSessionController(facadeImpl.NewSessionFacade(sessionService), catalog)
+	mfaService := serviceImpl.NewMFAService(store.mfa, store.users, userConfig.MFA.Issuer)
+	mfaController := controllers.NewMFAController(facadeImpl.NewMFAFacade(mfaService), catalog)
+	authorize := middlewares.Authorize(roleService, catalog)
+
+	// Cada ruta exige su permiso; las marcadas con self también se permiten sobre el
+	// propio usuario
+	require := func(permission string) gin.HandlerFunc { return middlewares.Require(catalog, permission) }
+	self := func(permission string) gin.HandlerFunc { return middlewares.RequireSelfOr(catalog, permission) }
 
 	// Ruta base para el grupo de endpoints de usuarios
-	userGroup := router.Group("/api/users")
+	userGroup := router.Group("/api/users", authenticate, middlewares.Timeout(userConfig.RequestTimeout, catalog), authorize)
 	{
 		// Definir endpoints CRUD para usuarios dentro del grupo
-		userGroup.POST("", userController.CreateUser)
-		userGroup.GET("", userController.GetAllUsers)
-		userGroup.GET("/:id", userController.GetSingleUser)
-		userGroup.PUT("/:id", userController.UpdateUser)
-		userGroup.DELETE("/:id", userController.DeleteUser)
+		userGroup.POST("", require(models.PermissionUsersCreate), userController.CreateUser)
+		userGroup.GET("", require(models.PermissionUsersRead), userController.GetAllUsers)
+		userGroup.GET("/:id", self(models.PermissionUsersRead), userController.GetSingleUser)
+		userGroup.PUT("/:id", self(models.PermissionUsersUpdate), userController.UpdateUser)
+		userGroup.PATCH("/:id", self(models.PermissionUsersUpdate), userController.PatchUser)
+		userGroup.DELETE("/:id", require(models.PermissionUsersDelete), userController.DeleteUser)
+		userGroup.POST("/:id/restore", require(models.PermissionUsersDelete), userController.RestoreUser)
+		userGroup.GET("/:id/history", self(models.PermissionUsersRead), userController.GetUserHistory)
+		userGroup.GET("/:id/versions/:version", self(models.PermissionUsersRead), userController.GetUserVersion)
+		userGroup.GET("/:id/diff", self(models.PermissionUsersRead), userController.DiffUserVersions)
+		userGroup.PUT("/:id/password", require(models.PermissionUsersPassword), authController.SetPassword)
+		userGroup.POST("/:id/password/change", self(models.PermissionUsersPassword), authController.ChangePassword)
+		userGroup.GET("/:id/lockout", require(models.PermissionUsersUnlock), authController.GetLockout)
+		userGroup.DELETE("/:id/lockout", require(models.PermissionUsersUnlock), authController.Unlock)
+		userGroup.POST("/:id/email/verification", self(models.PermissionUsersUpdate), emailController.RequestVerification)
+		userGroup.GET("/:id/roles", self(models.PermissionRolesManage), roleController.GetUserRoles)
+		userGroup.PUT("/:id/roles/:role", require(models.PermissionRolesManage), roleController.AssignRole)
+		userGroup.DELETE("/:id/roles/:role", require(models.PermissionRolesManage), roleController.RevokeRole)
+		userGroup.GET("/:id/sessions", self(models.PermissionSessionsManage), sessionController.GetUserSessions)
+		userGroup.DELETE("/:id/sessions", self(models.PermissionSessionsManage), sessionController.RevokeUserSessions)
+		userGroup.DELETE("/:id/sessions/:session", self(models.PermissionSessionsManage), sessionController.RevokeSession)
+		userGroup.GET("/:id/mfa", self(models.PermissionMFAManage), mfaController.GetMFA)
+		userGroup.DELETE("/:id/mfa", self(models.PermissionMFAManage), mfaController.DisableMFA)
+		userGroup.POST("/:id/mfa/totp", self(models.PermissionMFAManage), mfaController.EnrollTOTP)
+		userGroup.POST("/:id/mfa/totp/confirm", self(models.PermissionMFAManage), mfaController.ConfirmTOTP)
+		userGroup.POST("/:id/mfa/recovery-codes", self(models.PermissionMFAManage), mfaController.RegenerateRecoveryCodes)
+	}
+
+	// Administración de roles y sus permisos
+	roleGroup := router.Group("/api/roles", authenticate, middlewares.Timeout(userConfig.RequestTimeout, catalog), authorize, require(models.PermissionRolesManage))
+	{
+		roleGroup.GET("", roleController.GetRoles)
+		roleGroup.GET("/:name", roleController.GetRole)
+		roleGroup.POST("", roleController.CreateRole)
+		roleGroup.PUT("/:name", roleController.UpdateRole)
+		roleGroup.DELETE("/:name", roleController.DeleteRole)
+	}
+
+	// Inicio de sesión y emisión de tokens; son públicos
+	authGroup := router.Group("/api/auth", middlewares.Timeout(userConfig.RequestTimeout, catalog))
+	{
+		authGroup.POST("/login", authController.Login)
+		authGroup.POST("/token", authController.IssueToken)
+		authGroup.POST("/refresh", authController.Refresh)
+		authGroup.POST("/logout", authController.Logout)
+		authGroup.POST("/email/verify", emailController.VerifyEmail)
+		authGroup.POST("/password/forgot", emailController.RequestPasswordReset)
+		authGroup.POST("/password/reset", emailController.ResetPassword)
+	}
+
+	// Proveedor de OpenID Connect: las aplicaciones registradas inician sesión con el flujo
+	// del código de autorización; la autorización reconoce al usuario por su token de acceso
+	oidcFacade := facadeImpl.NewOIDCFacade(store.oidcService(sealer, userConfig.OIDC))
+	oidcController := controllers.NewOIDCController(oidcFacade, catalog)
+	oidcClientController := controllers.NewOIDCClientController(facadeImpl.NewOIDCClientFacade(serviceImpl.NewOIDCClientService(store.oidc)), catalog)
+	router.GET(oidc.DiscoveryPath, oidcController.Discovery)
+	oauthGroup := router.Group("/oauth2", middlewares.Timeout(userConfig.RequestTimeout, catalog))
+	{
+		oauthGroup.GET("/authorize", middlewares.AuthenticateOptional(tokenManager, sessionService), oidcController.Authorize)
+		oauthGroup.POST("/authorize", middlewares.AuthenticateOptional(tokenManager, sessionService), oidcController.AuthorizeJSON)
+		oauthGroup.POST("/token", oidcController.Token)
+		oauthGroup.GET("/userinfo", oidcController.UserInfo)
+		oauthGroup.POST("/userinfo", oidcController.UserInfo)
+	}
+	oidcGroup := router.Group("/api/oidc", authenticate, middlewares.Timeout(userConfig.RequestTimeout, catalog), authorize, require(models.PermissionOIDCManage))
+	{
+		oidcGroup.GET("/clients", oidcClientController.GetClients)
+		oidcGroup.GET("/clients/:id", oidcClientController.GetClient)
+		oidcGroup.POST("/clients", oidcClientController.CreateClient)
+		oidcGroup.PUT("/clients/:id", oidcClientController.UpdateClient)
+		oidcGroup.POST("/clients/:id/secret", oidcClientController.RotateClientSecret)
+		oidcGroup.DELETE("/clients/:id", oidcClientController.DeleteClient)
+		oidcGroup.GET("/keys", oidcController.GetSigningKeys)
+		oidcGroup.POST("/keys/rotate", oidcController.RotateSigningKey)
+	}
+
+	// Llaves públicas para que otros servicios verifiquen los tokens de acceso y los
+	// clientes de OpenID Connect los ID tokens
+	router.GET(oidc.JWKSPath, controllers.NewJWKSController(tokenManager, oidcFacade, catalog).GetJWKS)
+
+	// Llaves de API para otros servicios
+	apiKeyController := controllers.NewAPIKeyController(facadeImpl.NewAPIKeyFacade(apiKeyService), catalog)
+	apiKeyGroup := router.Group("/api/api-keys", authenticate, middlewares.Timeout(userConfig.RequestTimeout, catalog), authorize, require(models.PermissionAPIKeysManage))
+	{
+		apiKeyGroup.GET("", apiKeyController.GetAPIKeys)
+		apiKeyGroup.GET("/:id", apiKeyController.GetAPIKey)
+		apiKeyGroup.POST("", apiKeyController.CreateAPIKey)
+		apiKeyGroup.POST("/:id/rotate", apiKeyController.RotateAPIKey)
+		apiKeyGroup.DELETE("/:id", apiKeyController.RevokeAPIKey)
+	}
+
+	// Auditoría de todos los usuarios
+	router.GET("/api/audit", authenticate, middlewares.Timeout(userConfig.RequestTimeout, catalog), authorize,
+		require(models.PermissionAuditRead), userController.GetAuditRecords)
+
+	// Aprovisionamiento con SCIM 2.0: los grupos son los roles. La descripción del
+	// proveedor es pública para que los proveedores de identidad la consulten al conectarse
+	scimService := serviceImpl.NewSCIMService(userService, roleService, store.users, store.roles, store.tx)
+	scimController := controllers.NewSCIMController(facadeImpl.NewSCIMFacade(scimService), catalog)
+	scimGroup := router.Group(scim.BasePath, middlewares.Timeout(userConfig.RequestTimeout, catalog))
+	{
+		scimGroup.GET("/ServiceProviderConfig", scimController.ServiceProviderConfig)
+		scimGroup.GET("/ResourceTypes", scimController.ResourceTypes)
+		scimGroup.GET("/Schemas", scimController.Schemas)
+	}
+	scimUsers := scimGroup.Group("/Users", authenticate, authorize)
+	{
+		scimUsers.GET("", require(models.PermissionUsersRead), scimController.GetUsers)
+		scimUsers.GET("/:id", require(models.PermissionUsersRead), scimController.GetUser)
+		scimUsers.POST("", require(models.PermissionUsersCreate), scimController.CreateUser)
+		scimUsers.PUT("/:id", require(models.PermissionUsersUpdate), scimController.ReplaceUser)
+		scimUsers.PATCH("/:id", require(models.PermissionUsersUpdate), scimController.PatchUser)
+		scimUsers.DELETE("/:id", require(models.PermissionUsersDelete), scimController.DeleteUser)
+	}
+	scimGroups := scimGroup.Group("/Groups", authenticate, authorize, require(models.PermissionRolesManage))
+	{
+		scimGroups.GET("", scimController.GetGroups)
+		scimGroups.GET("/:id", scimController.GetGroup)
+		scimGroups.POST("", scimController.CreateGroup)
+		scimGroups.PUT("/:id", scimController.ReplaceGroup)
+		scimGroups.PATCH("/:id", scimController.PatchGroup)
+		scimGroups.DELETE("/:id", scimController.DeleteGroup)
 	}
 
 	// Configurar middleware de Swagger
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	log.Printf("Servidor escuchando en el puerto %s", port)
-	log.Fatal(router.Run(fmt.Sprintf(":%v", port)))
+	return router
 }
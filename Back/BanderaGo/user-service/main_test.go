@@ -0,0 +1,992 @@
+package main
+
+import (
+	"application/config"
+	"application/dtos/output"
+	"application/i18n"
+	"application/mailer"
+	"application/mfa"
+	"application/models"
+	"application/oidc"
+	"application/persistence/migrations"
+	"application/persistence/repositories"
+	"application/problems"
+	"application/scim"
+	"application/tokens"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bearer firma un token verificado con segundo factor para el sujeto con los roles
+// indicados y devuelve el encabezado que lo envía
+func bearer(t *testing.T, tokenManager *tokens.Manager, subject string, roles ...string) map[string]string {
+	token, _, err := tokenManager.Issue(subject, "", roles, []string{tokens.AMRMultiFactor})
+	require.NoError(t, err)
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+// withAuth agrega un encabezado a los de autenticación
+func withAuth(auth map[string]string, key, value string) map[string]string {
+	headers := map[string]string{key: value}
+	for k, v := range auth {
+		headers[k] = v
+	}
+	return headers
+}
+
+// doRequest envía una solicitud a la aplicación completa y devuelve la respuesta grabada
+func doRequest(router *gin.Engine, method, path, contentType, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// Caso de prueba: el flujo completo de la API sobre los motores que no requieren servicios externos
+func TestUserAPIIntegration(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("DB_TABLE", "users")
+
+	catalog, err := i18n.NewCatalog("es")
+	require.NoError(t, err)
+
+	for _, driver := range []string{config.DriverMemory, config.DriverSQLite} {
+		t.Run(driver, func(t *testing.T) {
+			userConfig := &config.UserConfig{DBDriver: driver, DBPath: ":memory:", DBTable: "users", MigrateOnStartup: true, RequestTimeout: 5 * time.Second, DefaultLanguage: "es",
+				Password: config.PasswordConfig{CheckBreached: true, Argon2Memory: 1024, Argon2Iterations: 1},
+				Auth: config.AuthConfig{Issuer: config.DefaultTokenIssuer, Audience: config.DefaultTokenAudience,
+					Secret: "0123456789abcdef0123456789abcdef", TokenTTL: time.Minute},
+				MFA:     config.MFAConfig{Issuer: config.DefaultMFAIssuer, RequiredRoles: []string{models.RoleAdmin}},
+				Lockout: config.LockoutConfig{MaxUserFailures: 3, MaxIPFailures: 50, Window: time.Minute, Duration: time.Minute},
+				Mail: config.MailConfig{BaseURL: "https://app.example.com", TokenSecret: "fedcba9876543210fedcba9876543210",
+					VerificationTTL: time.Hour, ResetTTL: time.Hour},
+				OIDC: config.OIDCConfig{Issuer: "https://sso.example.com", LoginURL: "https://app.example.com/login",
+					CodeTTL: time.Minute, TokenTTL: time.Hour, KeyRetention: time.Hour}}
+			store, err := newStorage(userConfig)
+			require.NoError(t, err)
+			tokenManager, err := tokens.NewManager(userConfig.Auth)
+			require.NoError(t, err)
+			mail := mailer.NewMemoryMailer()
+			sealer, err := oidc.NewSealer("00112233445566778899aabbccddeeff")
+			require.NoError(t, err)
+			router := newRouter(store, userConfig, catalog, tokenManager, mail, sealer)
+
+			runUserAPIScenario(t, router, store.users, tokenManager, mail)
+		})
+	}
+}
+
+func runUserAPIScenario(t *testing.T, router *gin.Engine, userRepo repositories.UserRepository, tokenManager *tokens.Manager, mail *mailer.MemoryMailer) {
+	// Sin token las rutas de usuarios y de auditoría responden 401
+	w := doRequest(router, "GET", "/api/users", "", "", nil)
+	require.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	assert.Equal(t, `Bearer realm="user-service"`, w.Header().Get("WWW-Authenticate"))
+	w = doRequest(router, "GET", "/api/audit", "", "", map[string]string{"Authorization": "Bearer abc.def.ghi"})
+	require.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+
+	admin, alice := bearer(t, tokenManager, "admin", models.RoleAdmin), bearer(t, tokenManager, "alice", models.RoleAdmin)
+
+	// Crear usuarios
+	for _, body := range []string{
+		`{"name":"Ana","last_name":"Díaz"}`,
+		`{"name":"Andrés","last_name":"Báez"}`,
+		`{"name":"Carlos","last_name":"Báez"}`,
+	} {
+		w := doRequest(router, "POST", "/api/users", "application/json", body, admin)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	// Listar ordenando y recorrer las páginas con cursores
+	var page output.GetUsersPageOut
+	w = doRequest(router, "GET", "/api/users?sort=last_name,-id&page_size=2", "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, []uint{3, 2}, userIDs(page))
+	require.NotEmpty(t, page.Links.Next)
+
+	w = doRequest(router, "GET", page.Links.Next, "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	page = output.GetUsersPageOut{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, []uint{1}, userIDs(page))
+	assert.Empty(t, page.NextCursor)
+	require.NotEmpty(t, page.Links.Prev)
+
+	w = doRequest(router, "GET", page.Links.Prev, "", "", admin)
+	page = output.GetUsersPageOut{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, []uint{3, 2}, userIDs(page))
+	assert.Empty(t, page.PrevCursor)
+
+	// Filtrar por prefijo sin distinguir mayúsculas y paginar por número de página
+	w = doRequest(router, "GET", "/api/users?name=an&page=2&page_size=1&sort=-id", "", "", admin)
+	page = output.GetUsersPageOut{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, int64(2), page.Total)
+	assert.Equal(t, []uint{1}, userIDs(page))
+	prevURL, err := url.Parse(page.Links.Prev)
+	require.NoError(t, err)
+	assert.Equal(t, "1", prevURL.Query().Get("page"))
+
+	// Obtener el usuario con su versión y validar la caché
+	w = doRequest(router, "GET", "/api/users/1", "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+	w = doRequest(router, "GET", "/api/users/1", "", "", withAuth(admin, "If-None-Match", `"1"`))
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// Modificar con la versión leída y rechazar una segunda escritura con la versión anterior
+	w = doRequest(router, "PATCH", "/api/users/1", "application/merge-patch+json", `{"last_name":"Díaz Pérez"}`, withAuth(admin, "If-Match", `"1"`))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	w = doRequest(router, "PUT", "/api/users/1", "application/json", `{"name":"Ana","last_name":"Ruiz"}`, withAuth(admin, "If-Match", `"1"`))
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	w = doRequest(router, "PUT", "/api/users/1", "application/json", `{"name":"Ana","last_name":"Ruiz"}`, withAuth(admin, "If-Match", `"2"`))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	stored, err := userRepo.GetUserByID(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Ruiz", stored.LastName)
+	assert.Equal(t, uint(3), stored.Version)
+
+	// Eliminar y verificar que el usuario ya no se encuentre ni se liste
+	w = doRequest(router, "DELETE", "/api/users/1", "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = doRequest(router, "GET", "/api/users/1", "", "", admin)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doRequest(router, "GET", "/api/users", "", "", admin)
+	page = output.GetUsersPageOut{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, int64(2), page.Total)
+
+	// Listar solo los eliminados y restaurar con una nueva versión
+	w = doRequest(router, "GET", "/api/users?deleted=only", "", "", admin)
+	page = output.GetUsersPageOut{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Equal(t, []uint{1}, userIDs(page))
+	assert.NotNil(t, page.Data[0].DeletedAt)
+	w = doRequest(router, "GET", "/api/users?deleted=include", "", "", admin)
+	page = output.GetUsersPageOut{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, int64(3), page.Total)
+
+	w = doRequest(router, "POST", "/api/users/1/restore", "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"5"`, w.Header().Get("ETag"))
+	w = doRequest(router, "GET", "/api/users/1", "", "", admin)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Eliminar definitivamente exige users:purge además de users:delete
+	w = doRequest(router, "POST", "/api/roles", "application/json", `{"name":"cleanup","permissions":["users:delete"]}`, admin)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	cleanup := bearer(t, tokenManager, "cleanup-bot", "cleanup")
+	w = doRequest(router, "DELETE", "/api/users/1?hard=true", "", "", cleanup)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	w = doRequest(router, "GET", "/api/users/1", "", "", admin)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Eliminar definitivamente: ya no se puede restaurar
+	w = doRequest(router, "DELETE", "/api/users/1?hard=true", "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doRequest(router, "POST", "/api/users/1/restore", "", "", admin)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doRequest(router, "GET", "/api/users?deleted=include", "", "", admin)
+	page = output.GetUsersPageOut{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, int64(2), page.Total)
+
+	// El historial conserva todas las operaciones aunque el usuario ya no exista
+	var history output.GetAuditPageOut
+	w = doRequest(router, "GET", "/api/users/1/history", "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	operations := make([]string, 0, len(history.Data))
+	for _, record := range history.Data {
+		operations = append(operations, record.Operation)
+	}
+	assert.Equal(t, []string{"purge", "restore", "delete", "update", "update", "create"}, operations)
+
+	// Las solicitudes identifican al actor y se correlacionan con X-Request-ID
+	beforePatch := time.Now().UTC().Format(time.RFC3339Nano)
+	time.Sleep(time.Millisecond)
+	w = doRequest(router, "PATCH", "/api/users/2", "application/merge-patch+json", `{"name":"Andrea"}`, withAuth(alice, "X-Request-ID", "req-42"))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "req-42", w.Header().Get("X-Request-ID"))
+	w = doRequest(router, "GET", "/api/audit?actor=alice", "", "", admin)
+	history = output.GetAuditPageOut{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	require.Len(t, history.Data, 1)
+	assert.Equal(t, "req-42", history.Data[0].RequestID)
+	assert.Equal(t, output.FieldChangeOut{Before: "Andrés", After: "Andrea"}, history.Data[0].Changes["name"])
+
+	// Todas las reglas incumplidas se reportan juntas
+	w = doRequest(router, "POST", "/api/users", "application/json", `{"name":"  ","last_name":"`+strings.Repeat("a", 10000)+`","email":"no-es-correo","display_name":"Ana\u0007"}`, admin)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	var invalid output.ProblemOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &invalid))
+	var invalidFields []string
+	for _, fieldErr := range invalid.Errors {
+		invalidFields = append(invalidFields, fieldErr.Field+":"+fieldErr.Code)
+	}
+	assert.Equal(t, []string{"name:required", "last_name:max", "email:email", "display_name:printable"}, invalidFields)
+
+	// Los datos de contacto se normalizan y no pueden repetirse
+	w = doRequest(router, "POST", "/api/users", "application/json", `{"name":"Eva","last_name":"Ruiz","email":"Eva@Example.com","username":"Eva.R","phone":"+52 55 1234 5678"}`, admin)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created output.CreateUserOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "eva@example.com", created.Email)
+	assert.Equal(t, "eva.r", created.Username)
+	assert.Equal(t, "+525512345678", created.Phone)
+
+	for _, tc := range []struct{ body, field string }{
+		{`{"name":"Eva","last_name":"Díaz","email":"EVA@example.com"}`, "email"},
+		{`{"name":"Eva","last_name":"Díaz","username":"eva.r"}`, "username"},
+		{`{"name":"Eva","last_name":"Díaz","phone":"0052 55 1234 5678"}`, "phone"},
+	} {
+		w = doRequest(router, "POST", "/api/users", "application/json", tc.body, admin)
+		require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+		var problem output.ProblemOut
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		require.Len(t, problem.Errors, 1)
+		assert.Equal(t, tc.field, problem.Errors[0].Field)
+	}
+	w = doRequest(router, "PATCH", "/api/users/3", "application/merge-patch+json", `{"email":"eva@example.com"}`, admin)
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	w = doRequest(router, "PATCH", "/api/users/3", "application/merge-patch+json", `{"phone":"5512345678"}`, admin)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+
+	// Los filtros exactos se normalizan igual que los datos guardados
+	for _, query := range []string{"email=EVA@example.com", "username=Eva.R", "phone=" + url.QueryEscape("0052 55 1234 5678")} {
+		w = doRequest(router, "GET", "/api/users?"+query, "", "", admin)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		page = output.GetUsersPageOut{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.Equal(t, []uint{created.ID}, userIDs(page), query)
+	}
+	w = doRequest(router, "GET", "/api/users?email=eva@example.com&username=otra", "", "", admin)
+	page = output.GetUsersPageOut{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Zero(t, page.Total)
+
+	// Contraseña e inicio de sesión con el correo o el nombre de usuario
+	evaPath := fmt.Sprintf("/api/users/%d/password", created.ID)
+	w = doRequest(router, "PUT", evaPath, "application/json", `{"password":"password1234"}`, admin)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"breached"`)
+	w = doRequest(router, "PUT", evaPath, "application/json", `{"password":"caballo correcto"}`, admin)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = doRequest(router, "POST", "/api/auth/login", "application/json", `{"login":"EVA@example.com","password":"caballo correcto"}`, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var loginOut output.LoginOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &loginOut))
+	assert.Equal(t, created.ID, loginOut.User.ID)
+	assert.NotContains(t, w.Body.String(), "argon2")
+
+	// El token emitido con la contraseña autentica al usuario, que queda como actor
+	w = doRequest(router, "POST", "/api/auth/token", "application/x-www-form-urlencoded", "grant_type=password&username=eva.r&password=caballo+correcto", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var tokenOut output.TokenOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokenOut))
+	assert.Equal(t, "Bearer", tokenOut.TokenType)
+	eva := map[string]string{"Authorization": "Bearer " + tokenOut.AccessToken}
+	w = doRequest(router, "PATCH", fmt.Sprintf("/api/users/%d", created.ID), "application/merge-patch+json", `{"display_name":"Eva"}`, eva)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doRequest(router, "GET", fmt.Sprintf("/api/audit?actor=%d", created.ID), "", "", eva)
+	require.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	w = doRequest(router, "GET", fmt.Sprintf("/api/audit?actor=%d", created.ID), "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	history = output.GetAuditPageOut{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	assert.Len(t, history.Data, 1)
+	w = doRequest(router, "POST", "/api/auth/token", "application/json", `{"grant_type":"password","username":"eva.r","password":"otra contraseña"}`, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+
+	// Sin roles un usuario solo opera sobre sí mismo y un auditor lista sin modificar
+	evaRoles := fmt.Sprintf("/api/users/%d/roles", created.ID)
+	auditor := bearer(t, tokenManager, "auditor-bot", models.RoleAuditor)
+	for _, tc := range []struct {
+		method, path string
+		auth         map[string]string
+	}{
+		{"GET", "/api/users", eva},
+		{"PATCH", "/api/users/2", eva},
+		{"DELETE", fmt.Sprintf("/api/users/%d", created.ID), eva},
+		{"PUT", evaRoles + "/admin", eva},
+		{"PATCH", "/api/users/2", auditor},
+		{"DELETE", "/api/users/2", auditor},
+		{"GET", "/api/roles", auditor},
+	} {
+		w = doRequest(router, tc.method, tc.path, "application/merge-patch+json", `{"name":"Eve"}`, tc.auth)
+		require.Equal(t, http.StatusForbidden, w.Code, tc.method+" "+tc.path)
+		assert.Contains(t, w.Body.String(), `"FORBIDDEN"`)
+	}
+	w = doRequest(router, "GET", "/api/users", "", "", auditor)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	// El rol admin del token exige segundo factor como el asignado en la base
+	token, _, err := tokenManager.Issue("deploy-bot", "", []string{models.RoleAdmin}, nil)
+	require.NoError(t, err)
+	w = doRequest(router, "DELETE", "/api/users/2", "", "", map[string]string{"Authorization": "Bearer " + token})
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	w = doRequest(router, "GET", "/api/audit", "", "", auditor)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// Los roles asignados por API se aplican a la siguiente solicitud con el mismo token
+	w = doRequest(router, "PUT", evaRoles+"/superuser", "", "", admin)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"ROLE_NOT_FOUND"`)
+	w = doRequest(router, "PUT", evaRoles+"/auditor", "", "", admin)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = doRequest(router, "GET", "/api/users", "", "", eva)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var userRoles output.UserRolesOut
+	w = doRequest(router, "GET", evaRoles, "", "", eva)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &userRoles))
+	assert.Equal(t, []string{models.RoleAuditor}, userRoles.Roles)
+	assert.Equal(t, []string{models.PermissionAuditRead, models.PermissionUsersRead}, userRoles.Permissions)
+	w = doRequest(router, "GET", fmt.Sprintf("/api/users/%d/history?operation=roles", created.ID), "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	history = output.GetAuditPageOut{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	require.Len(t, history.Data, 1)
+	assert.Equal(t, "admin", history.Data[0].Actor)
+
+	// Un rol nuevo con sus permisos; al eliminarlo se revoca a quien lo tenga
+	w = doRequest(router, "POST", "/api/roles", "application/json", `{"name":"support","permissions":["users:update","users:read"]}`, admin)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = doRequest(router, "POST", "/api/roles", "application/json", `{"name":"support","permissions":["users:read"]}`, admin)
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"ROLE_CONFLICT"`)
+	w = doRequest(router, "POST", "/api/roles", "application/json", `{"name":"x","permissions":["users:fly"]}`, admin)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	w = doRequest(router, "PUT", evaRoles+"/support", "", "", admin)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = doRequest(router, "PATCH", "/api/users/2", "application/merge-patch+json", `{"display_name":"Andrea B."}`, eva)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doRequest(router, "DELETE", "/api/roles/support", "", "", admin)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = doRequest(router, "PATCH", "/api/users/2", "application/merge-patch+json", `{"display_name":"Andrea"}`, eva)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	w = doRequest(router, "DELETE", evaRoles+"/auditor", "", "", admin)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = doRequest(router, "GET", "/api/users", "", "", eva)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	w = doRequest(router, "POST", evaPath+"/change", "application/json", `{"current_password":"otra contraseña","new_password":"batería grapa"}`, admin)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	w = doRequest(router, "POST", evaPath+"/change", "application/json", `{"current_password":"caballo correcto","new_password":"batería grapa"}`, admin)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = doRequest(router, "POST", "/api/auth/login", "application/json", `{"login":"eva.r","password":"caballo correcto"}`, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	w = doRequest(router, "POST", "/api/auth/login", "application/json", `{"login":"nadie","password":"batería grapa"}`, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	w = doRequest(router, "POST", "/api/auth/login", "application/json", `{"login":"eva.r","password":"batería grapa"}`, nil)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doRequest(router, "GET", fmt.Sprintf("/api/users/%d/history?operation=password", created.ID), "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), "argon2")
+
+	// Llaves de API: solo se guarda el hash, autentican con sus permisos y se rotan o revocan
+	w = doRequest(router, "POST", "/api/api-keys", "application/json", `{"name":"batch","permissions":["users:read","users:create"]}`, admin)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var apiKeyOut output.APIKeyCreatedOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiKeyOut))
+	assert.Equal(t, "admin", apiKeyOut.CreatedBy)
+	batch := map[string]string{"X-API-Key": apiKeyOut.Key}
+	w = doRequest(router, "GET", "/api/api-keys", "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), apiKeyOut.Prefix)
+	assert.NotContains(t, w.Body.String(), apiKeyOut.Key)
+	w = doRequest(router, "POST", "/api/api-keys", "application/json", `{"name":"corta","permissions":["users:read"],"expires_at":"2000-01-01T00:00:00Z"}`, admin)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	w = doRequest(router, "GET", "/api/api-keys", "", "", eva)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	w = doRequest(router, "GET", "/api/users", "", "", batch)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doRequest(router, "DELETE", "/api/users/2", "", "", batch)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	w = doRequest(router, "GET", "/api/api-keys", "", "", batch)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	w = doRequest(router, "POST", "/api/users", "application/json", `{"name":"Iris","last_name":"Vega"}`, batch)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = doRequest(router, "GET", fmt.Sprintf("/api/audit?actor=apikey:%d", apiKeyOut.ID), "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	history = output.GetAuditPageOut{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	assert.Len(t, history.Data, 1)
+
+	w = doRequest(router, "POST", fmt.Sprintf("/api/api-keys/%d/rotate", apiKeyOut.ID), "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var rotatedOut output.APIKeyCreatedOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotatedOut))
+	w = doRequest(router, "GET", "/api/users", "", "", batch)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"INVALID_API_KEY"`)
+	batch = map[string]string{"X-API-Key": rotatedOut.Key}
+	w = doRequest(router, "GET", "/api/users", "", "", batch)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doRequest(router, "DELETE", fmt.Sprintf("/api/api-keys/%d", apiKeyOut.ID), "", "", admin)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = doRequest(router, "GET", "/api/users", "", "", batch)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	w = doRequest(router, "GET", fmt.Sprintf("/api/api-keys/%d", apiKeyOut.ID), "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"revoked_at"`)
+
+	// Sesiones: el refresh token se usa una vez, reutilizarlo revoca la sesión y se puede
+	// cerrar una sesión o todas
+	evaSessions := fmt.Sprintf("/api/users/%d/sessions", created.ID)
+	require.NotEmpty(t, tokenOut.RefreshToken)
+	w = doRequest(router, "POST", "/api/auth/refresh", "application/json", `{"refresh_token":"`+tokenOut.RefreshToken+`"}`, map[string]string{"User-Agent": "Firefox"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var refreshedOut output.TokenOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &refreshedOut))
+	assert.NotEqual(t, tokenOut.RefreshToken, refreshedOut.RefreshToken)
+	eva = map[string]string{"Authorization": "Bearer " + refreshedOut.AccessToken}
+	w = doRequest(router, "POST", "/api/auth/token", "application/json", `{"grant_type":"password","username":"eva.r","password":"batería grapa"}`, map[string]string{"User-Agent": "Android"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var phoneOut output.TokenOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &phoneOut))
+
+	w = doRequest(router, "GET", evaSessions, "", "", eva)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var sessionsOut []output.SessionOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sessionsOut))
+	require.Len(t, sessionsOut, 2)
+	assert.Equal(t, "Android", sessionsOut[0].UserAgent)
+	assert.False(t, sessionsOut[0].Current)
+	assert.Equal(t, "Firefox", sessionsOut[1].UserAgent)
+	assert.True(t, sessionsOut[1].Current)
+	w = doRequest(router, "GET", "/api/users/2/sessions", "", "", eva)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	w = doRequest(router, "POST", "/api/auth/refresh", "application/x-www-form-urlencoded", "refresh_token="+tokenOut.RefreshToken, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"INVALID_REFRESH_TOKEN"`)
+	w = doRequest(router, "POST", "/api/auth/refresh", "application/json", `{"refresh_token":"`+refreshedOut.RefreshToken+`"}`, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	// El token de acceso de la sesión revocada deja de valer aunque no haya vencido
+	w = doRequest(router, "GET", evaSessions, "", "", eva)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"INVALID_TOKEN"`)
+	w = doRequest(router, "GET", evaSessions, "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	sessionsOut = nil
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sessionsOut))
+	require.Len(t, sessionsOut, 1)
+	w = doRequest(router, "DELETE", fmt.Sprintf("%s/%d", evaSessions, sessionsOut[0].ID+100), "", "", admin)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+
+	w = doRequest(router, "POST", "/api/auth/logout", "application/json", `{"refresh_token":"`+phoneOut.RefreshToken+`"}`, nil)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = doRequest(router, "POST", "/api/auth/refresh", "application/json", `{"refresh_token":"`+phoneOut.RefreshToken+`"}`, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	w = doRequest(router, "POST", "/api/auth/token", "application/json", `{"grant_type":"password","username":"eva.r","password":"batería grapa"}`, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doRequest(router, "DELETE", evaSessions, "", "", admin)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = doRequest(router, "GET", evaSessions, "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `[]`, w.Body.String())
+
+	// Segundo factor: el rol admin asignado solo cuenta en sesiones que verificaron el código
+	evaMFA := fmt.Sprintf("/api/users/%d/mfa", created.ID)
+	evaLogin := `{"grant_type":"password","username":"eva.r","password":"batería grapa"`
+	issue := func(otp string) *httptest.ResponseRecorder {
+		body := evaLogin + "}"
+		if otp != "" {
+			body = evaLogin + `,"otp":"` + otp + `"}`
+		}
+		return doRequest(router, "POST", "/api/auth/token", "application/json", body, nil)
+	}
+	bearerOf := func(w *httptest.ResponseRecorder) map[string]string {
+		var out output.TokenOut
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &out))
+		return map[string]string{"Authorization": "Bearer " + out.AccessToken}
+	}
+	w = doRequest(router, "PUT", evaRoles+"/admin", "", "", admin)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = issue("")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	eva = bearerOf(w)
+	w = doRequest(router, "DELETE", "/api/users/999", "", "", eva)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	w = doRequest(router, "POST", evaMFA+"/totp", "", "", admin)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	w = doRequest(router, "POST", evaMFA+"/totp", "", "", eva)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var enrollmentOut output.TOTPEnrollmentOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &enrollmentOut))
+	assert.Contains(t, enrollmentOut.OTPAuthURI, "otpauth://totp/user-service:eva.r?")
+	w = doRequest(router, "POST", evaMFA+"/totp/confirm", "application/json", `{"code":"12345"}`, eva)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	now := time.Now()
+	code, err := mfa.Code(enrollmentOut.Secret, mfa.Step(now))
+	require.NoError(t, err)
+	w = doRequest(router, "POST", evaMFA+"/totp/confirm", "application/json", `{"code":"`+code+`"}`, eva)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var codesOut output.RecoveryCodesOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &codesOut))
+	require.Len(t, codesOut.RecoveryCodes, mfa.RecoveryCodeCount)
+
+	w = issue("")
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"MFA_REQUIRED"`)
+	w = issue(code)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"INVALID_CREDENTIALS"`)
+	next, err := mfa.Code(enrollmentOut.Secret, mfa.Step(now)+1)
+	require.NoError(t, err)
+	w = issue(next)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	evaMFAToken := bearerOf(w)
+	w = doRequest(router, "DELETE", "/api/users/999", "", "", evaMFAToken)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+
+	// Cada código de recuperación vale una vez; quien tiene mfa:manage desactiva el segundo factor
+	w = issue(codesOut.RecoveryCodes[0])
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = issue(codesOut.RecoveryCodes[0])
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	w = doRequest(router, "GET", evaMFA, "", "", eva)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var mfaOut output.MFAOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &mfaOut))
+	assert.True(t, mfaOut.Enabled)
+	assert.Equal(t, int64(mfa.RecoveryCodeCount-1), mfaOut.RecoveryCodesRemaining)
+	w = doRequest(router, "POST", evaMFA+"/recovery-codes", "", "", eva)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	w = doRequest(router, "POST", evaMFA+"/recovery-codes", "", "", evaMFAToken)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doRequest(router, "DELETE", evaMFA, "", "", admin)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = issue("")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doRequest(router, "DELETE", evaRoles+"/admin", "", "", admin)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	// Tras tres intentos fallidos la cuenta se bloquea aunque la contraseña sea correcta; el
+	// bloqueo se audita y quien tiene users:unlock lo levanta
+	evaLockout := fmt.Sprintf("/api/users/%d/lockout", created.ID)
+	for i := 0; i < 3; i++ {
+		w = doRequest(router, "POST", "/api/auth/login", "application/json", `{"login":"eva.r","password":"otra contraseña"}`, nil)
+		require.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	}
+	w = issue("")
+	require.Equal(t, http.StatusTooManyRequests, w.Code, w.Body.String())
+	var locked output.ProblemOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &locked))
+	assert.Equal(t, problems.CodeAccountLocked, locked.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	w = doRequest(router, "GET", evaLockout, "", "", eva)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	w = doRequest(router, "GET", evaLockout, "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var lockoutOut output.LockoutOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &lockoutOut))
+	assert.True(t, lockoutOut.Locked)
+	w = doRequest(router, "GET", "/api/audit?operation=lock", "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	history = output.GetAuditPageOut{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	assert.Len(t, history.Data, 1)
+	w = doRequest(router, "DELETE", evaLockout, "", "", admin)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = issue("")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// El enlace enviado por correo verifica el correo una sola vez; otro enlace restablece
+	// la contraseña y cierra las sesiones abiertas
+	linkToken := func(link string) string {
+		message, ok := mail.Last("eva@example.com")
+		require.True(t, ok)
+		_, query, found := strings.Cut(message.Text, link+"?token=")
+		require.True(t, found, message.Text)
+		token, err := url.QueryUnescape(strings.Fields(query)[0])
+		require.NoError(t, err)
+		return token
+	}
+	evaVerification := fmt.Sprintf("/api/users/%d/email/verification", created.ID)
+	w = doRequest(router, "POST", evaVerification, "", "", map[string]string{"Authorization": eva["Authorization"], "Accept-Language": "en"})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	message, ok := mail.Last("eva@example.com")
+	require.True(t, ok)
+	assert.Equal(t, "Confirm your email", message.Subject)
+	verification := `{"token":"` + linkToken("https://app.example.com/verify-email") + `"}`
+	w = doRequest(router, "POST", "/api/auth/email/verify", "application/json", verification, nil)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = doRequest(router, "POST", "/api/auth/email/verify", "application/json", verification, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"INVALID_EMAIL_TOKEN"`)
+	w = doRequest(router, "GET", fmt.Sprintf("/api/users/%d", created.ID), "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"email_verified":true`)
+	w = doRequest(router, "POST", evaVerification, "", "", eva)
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	w = doRequest(router, "GET", "/api/audit?operation=verify_email", "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	history = output.GetAuditPageOut{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	assert.Len(t, history.Data, 1)
+
+	sent := len(mail.Messages())
+	w = doRequest(router, "POST", "/api/auth/password/forgot", "application/json", `{"email":"nadie@example.com"}`, nil)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	w = doRequest(router, "POST", "/api/auth/password/forgot", "application/json", `{"email":"eva@example.com"}`, nil)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	// El enlace se envía en segundo plano; el correo desconocido no recibe nada
+	require.Eventually(t, func() bool { return len(mail.Messages()) == sent+1 }, 5*time.Second, 10*time.Millisecond)
+	last := mail.Messages()[sent]
+	assert.Equal(t, "eva@example.com", last.To)
+	reset := linkToken("https://app.example.com/reset-password")
+	w = doRequest(router, "POST", "/api/auth/password/reset", "application/json", `{"token":"`+reset+`","password":"caballo de troya"}`, nil)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = issue("")
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	w = doRequest(router, "POST", "/api/auth/token", "application/json", `{"grant_type":"password","username":"eva.r","password":"caballo de troya"}`, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resetOut output.TokenOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resetOut))
+	w = doRequest(router, "GET", evaSessions, "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	sessionsOut = nil
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sessionsOut))
+	assert.Len(t, sessionsOut, 1)
+
+	// OpenID Connect: el administrador registra un cliente y Eva, con su sesión, autoriza
+	// al cliente, que canjea el código por tokens y consulta sus datos
+	runOIDCScenario(t, router, admin, map[string]string{"Authorization": "Bearer " + resetOut.AccessToken})
+
+	// SCIM: un proveedor de identidad aprovisiona un usuario, lo agrega a un grupo y lo desactiva
+	runSCIMScenario(t, router, admin, map[string]string{"Authorization": "Bearer " + resetOut.AccessToken})
+
+	// Consultar el usuario en un instante anterior, en una versión y comparar versiones
+	var userOut output.GetUserOut
+	w = doRequest(router, "GET", "/api/users/2?as_of="+beforePatch, "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &userOut))
+	assert.Equal(t, "Andrés", userOut.Name)
+
+	var versionOut output.UserVersionOut
+	w = doRequest(router, "GET", "/api/users/2/versions/2", "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &versionOut))
+	assert.Equal(t, "Andrea", versionOut.Name)
+
+	var diffOut output.UserDiffOut
+	w = doRequest(router, "GET", "/api/users/2/diff?from=1&to=2", "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &diffOut))
+	assert.Equal(t, map[string]output.FieldChangeOut{"name": {Before: "Andrés", After: "Andrea"}}, diffOut.Changes)
+
+	// Las versiones de un usuario eliminado definitivamente se conservan; la eliminación
+	// lógica (versión 4), la restauración (5) y la eliminación definitiva (6) tienen la suya
+	w = doRequest(router, "GET", "/api/users/1/versions/3", "", "", admin)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	for version, deleted := range map[int]bool{4: true, 5: false, 6: true} {
+		versionOut = output.UserVersionOut{}
+		w = doRequest(router, "GET", fmt.Sprintf("/api/users/1/versions/%d", version), "", "", admin)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &versionOut))
+		assert.Equal(t, deleted, versionOut.DeletedAt != nil, version)
+	}
+	w = doRequest(router, "GET", "/api/users/1/diff?from=3&to=4", "", "", admin)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doRequest(router, "GET", "/api/users/1?as_of="+time.Now().UTC().Format(time.RFC3339Nano), "", "", admin)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func runOIDCScenario(t *testing.T, router *gin.Engine, admin, eva map[string]string) {
+	w := doRequest(router, "GET", "/.well-known/openid-configuration", "", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var discoveryOut output.DiscoveryOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &discoveryOut))
+	assert.Equal(t, "https://sso.example.com", discoveryOut.Issuer)
+	assert.Equal(t, "https://sso.example.com/oauth2/token", discoveryOut.TokenEndpoint)
+
+	w = doRequest(router, "POST", "/api/oidc/clients", "application/json", `{"name":"Portal","redirect_uris":["https://portal.example.com/callback"]}`, eva)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	w = doRequest(router, "POST", "/api/oidc/clients", "application/json", `{"name":"Portal","redirect_uris":["http://portal.example.com/callback"]}`, admin)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	w = doRequest(router, "POST", "/api/oidc/clients", "application/json", `{"name":"Portal","redirect_uris":["https://portal.example.com/callback"]}`, admin)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var clientOut output.OIDCClientCreatedOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &clientOut))
+	require.NotEmpty(t, clientOut.ClientSecret)
+
+	verifier := strings.Repeat("v", 43)
+	sum := sha256.Sum256([]byte(verifier))
+	authorize := url.Values{"response_type": {"code"}, "client_id": {clientOut.ClientID}, "redirect_uri": {"https://portal.example.com/callback"},
+		"scope": {"openid email"}, "state": {"xyz"}, "nonce": {"n-1"},
+		"code_challenge": {base64.RawURLEncoding.EncodeToString(sum[:])}, "code_challenge_method": {"S256"}}
+
+	// Sin sesión se redirige al inicio de sesión, y a otra URI no se redirige
+	w = doRequest(router, "GET", "/oauth2/authorize?"+authorize.Encode(), "", "", nil)
+	require.Equal(t, http.StatusFound, w.Code, w.Body.String())
+	assert.True(t, strings.HasPrefix(w.Header().Get("Location"), "https://app.example.com/login?return_to="), w.Header().Get("Location"))
+	w = doRequest(router, "GET", "/oauth2/authorize?"+strings.ReplaceAll(authorize.Encode(), "portal.example.com", "evil.example.com"), "", "", eva)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"INVALID_AUTHORIZATION_REQUEST"`)
+
+	w = doRequest(router, "POST", "/oauth2/authorize", "application/x-www-form-urlencoded", authorize.Encode(), eva)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var authorizeOut output.AuthorizeOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &authorizeOut))
+	redirectTo, err := url.Parse(authorizeOut.RedirectTo)
+	require.NoError(t, err)
+	assert.Equal(t, "xyz", redirectTo.Query().Get("state"))
+	code := redirectTo.Query().Get("code")
+	require.NotEmpty(t, code)
+
+	exchange := url.Values{"grant_type": {"authorization_code"}, "code": {code}, "redirect_uri": {"https://portal.example.com/callback"}, "code_verifier": {verifier}}
+	basic := map[string]string{"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(clientOut.ClientID+":otro"))}
+	w = doRequest(router, "POST", "/oauth2/token", "application/x-www-form-urlencoded", exchange.Encode(), basic)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"invalid_client"`)
+	basic["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(clientOut.ClientID+":"+url.QueryEscape(clientOut.ClientSecret)))
+	w = doRequest(router, "POST", "/oauth2/token", "application/x-www-form-urlencoded", exchange.Encode(), basic)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var oidcTokenOut output.OIDCTokenOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &oidcTokenOut))
+	require.NotEmpty(t, oidcTokenOut.IDToken)
+	w = doRequest(router, "POST", "/oauth2/token", "application/x-www-form-urlencoded", exchange.Encode(), basic)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"invalid_grant"`)
+
+	// El token de acceso del cliente sirve en userinfo pero no en la API, y al revés
+	w = doRequest(router, "GET", "/oauth2/userinfo", "", "", map[string]string{"Authorization": "Bearer " + oidcTokenOut.AccessToken})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var userInfoOut output.UserInfoOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &userInfoOut))
+	assert.Equal(t, "eva@example.com", userInfoOut.Email)
+	w = doRequest(router, "GET", "/oauth2/userinfo", "", "", eva)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	w = doRequest(router, "GET", "/api/users", "", "", map[string]string{"Authorization": "Bearer " + oidcTokenOut.AccessToken})
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+
+	// El JWKS publica la llave que firma los ID tokens; tras rotarla sigue publicando ambas
+	var keysOut []output.SigningKeyOut
+	w = doRequest(router, "POST", "/api/oidc/keys/rotate", "", "", admin)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = doRequest(router, "GET", "/api/oidc/keys", "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &keysOut))
+	require.Len(t, keysOut, 2)
+	w = doRequest(router, "GET", "/.well-known/jwks.json", "", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	for _, key := range keysOut {
+		assert.Contains(t, w.Body.String(), `"kid":"`+key.Kid+`"`)
+	}
+
+	// Al eliminar el cliente su token de acceso deja de valer
+	w = doRequest(router, "DELETE", "/api/oidc/clients/"+clientOut.ClientID, "", "", admin)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = doRequest(router, "GET", "/oauth2/userinfo", "", "", map[string]string{"Authorization": "Bearer " + oidcTokenOut.AccessToken})
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+}
+
+func runSCIMScenario(t *testing.T, router *gin.Engine, admin, eva map[string]string) {
+	w := doRequest(router, "GET", "/scim/v2/ServiceProviderConfig", "", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Type"), scim.MediaType)
+	w = doRequest(router, "GET", "/scim/v2/Users", "", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	w = doRequest(router, "GET", "/scim/v2/Users", "", "", eva)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	w = doRequest(router, "POST", "/scim/v2/Users", scim.MediaType, `{"schemas":["`+scim.SchemaUser+`"],"userName":"Luis.Mora",
+		"name":{"givenName":"Luis","familyName":"Mora"},"emails":[{"value":"luis@example.com","type":"work","primary":true}]}`, admin)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Type"), scim.MediaType)
+	var userOut output.SCIMUserOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &userOut))
+	assert.Equal(t, scim.UsersPath+"/"+userOut.ID, w.Header().Get("Location"))
+	assert.Equal(t, "luis.mora", userOut.UserName)
+	etag := w.Header().Get("ETag")
+
+	var listOut output.SCIMUserListOut
+	w = doRequest(router, "GET", "/scim/v2/Users?filter="+url.QueryEscape(`userName eq "luis.mora"`), "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listOut))
+	require.Equal(t, int64(1), listOut.TotalResults)
+	assert.Equal(t, userOut.ID, listOut.Resources[0].ID)
+	w = doRequest(router, "GET", "/scim/v2/Users?filter="+url.QueryEscape(`title eq "CTO"`), "", "", admin)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"scimType":"invalidFilter"`)
+
+	w = doRequest(router, "POST", "/scim/v2/Groups", scim.MediaType, `{"displayName":"ventas","members":[{"value":"`+userOut.ID+`"}]}`, admin)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = doRequest(router, "GET", "/scim/v2/Users/"+userOut.ID, "", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"value":"ventas"`)
+
+	// Desactivar al usuario lo elimina lógicamente; con una versión anterior responde 412
+	deactivate := `{"schemas":["` + scim.SchemaPatchOp + `"],"Operations":[{"op":"replace","path":"active","value":false}]}`
+	w = doRequest(router, "PATCH", "/scim/v2/Users/"+userOut.ID, scim.MediaType, deactivate, withAuth(admin, "If-Match", `"99"`))
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code, w.Body.String())
+	w = doRequest(router, "PATCH", "/scim/v2/Users/"+userOut.ID, scim.MediaType, deactivate, withAuth(admin, "If-Match", etag))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"active":false`)
+	w = doRequest(router, "GET", "/api/users/"+userOut.ID, "", "", admin)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+
+	w = doRequest(router, "DELETE", "/scim/v2/Users/"+userOut.ID, "", "", admin)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = doRequest(router, "DELETE", "/scim/v2/Groups/ventas", "", "", admin)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = doRequest(router, "GET", "/scim/v2/Groups/ventas", "", "", admin)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
+
+// Caso de prueba: el subcomando migrate aplica, informa y revierte el esquema
+func TestRunMigrate(t *testing.T) {
+	userConfig := &config.UserConfig{DBDriver: config.DriverSQLite, DBPath: filepath.Join(t.TempDir(), "users.db"), DBTable: "users"}
+	ctx := context.Background()
+
+	// Nombres de las migraciones tal como los imprime el subcomando, en orden de aplicación
+	all, err := migrations.Load(config.DriverSQLite, "users")
+	require.NoError(t, err)
+	names := make([]string, 0, len(all))
+	for _, migration := range all {
+		names = append(names, fmt.Sprintf("%04d_%s\n", migration.Version, migration.Name))
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, runMigrate(ctx, &out, userConfig, []string{"status"}))
+	assert.Regexp(t, `0001\s+create_users\s+pendiente`, out.String())
+
+	out.Reset()
+	require.NoError(t, runMigrate(ctx, &out, userConfig, []string{"up"}))
+	assert.Equal(t, strings.Join(names, ""), out.String())
+
+	out.Reset()
+	require.NoError(t, runMigrate(ctx, &out, userConfig, []string{"up"}))
+	assert.Equal(t, "Sin cambios\n", out.String())
+
+	out.Reset()
+	require.NoError(t, runMigrate(ctx, &out, userConfig, []string{"status"}))
+	assert.NotContains(t, out.String(), "pendiente")
+
+	out.Reset()
+	require.NoError(t, runMigrate(ctx, &out, userConfig, []string{"down"}))
+	assert.Equal(t, names[len(names)-1], out.String())
+
+	out.Reset()
+	require.NoError(t, runMigrate(ctx, &out, userConfig, []string{"to", "0"}))
+	slices.Reverse(names)
+	assert.Equal(t, strings.Join(names[1:], ""), out.String())
+
+	assert.Error(t, runMigrate(ctx, &out, userConfig, nil))
+	assert.Error(t, runMigrate(ctx, &out, userConfig, []string{"to", "uno"}))
+	assert.Error(t, runMigrate(ctx, &out, userConfig, []string{"sideways"}))
+	assert.Error(t, runMigrate(ctx, &out, &config.UserConfig{DBDriver: config.DriverMemory}, []string{"up"}))
+}
+
+// Caso de prueba: sin proxies de confianza un X-Forwarded-For falso no cambia la IP con la
+// que se cuentan los intentos fallidos; con el proxy de confianza sí se usa el encabezado
+func TestTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("DB_TABLE", "users")
+	catalog, err := i18n.NewCatalog("es")
+	require.NoError(t, err)
+
+	newTestRouter := func(trustedProxies []string) *gin.Engine {
+		userConfig := &config.UserConfig{DBDriver: config.DriverMemory, DBTable: "users", DefaultLanguage: "es", TrustedProxies: trustedProxies,
+			Password: config.PasswordConfig{Argon2Memory: 1024, Argon2Iterations: 1},
+			Auth: config.AuthConfig{Issuer: config.DefaultTokenIssuer, Audience: config.DefaultTokenAudience,
+				Secret: "0123456789abcdef0123456789abcdef", TokenTTL: time.Minute},
+			Lockout: config.LockoutConfig{MaxUserFailures: 10, MaxIPFailures: 2, Window: time.Minute, Duration: time.Minute},
+			Mail: config.MailConfig{BaseURL: "https://app.example.com", TokenSecret: "fedcba9876543210fedcba9876543210",
+				VerificationTTL: time.Hour, ResetTTL: time.Hour},
+			OIDC: config.OIDCConfig{Issuer: "https://sso.example.com", LoginURL: "https://app.example.com/login",
+				CodeTTL: time.Minute, TokenTTL: time.Hour, KeyRetention: time.Hour}}
+		store, err := newStorage(userConfig)
+		require.NoError(t, err)
+		tokenManager, err := tokens.NewManager(userConfig.Auth)
+		require.NoError(t, err)
+		sealer, err := oidc.NewSealer("00112233445566778899aabbccddeeff")
+		require.NoError(t, err)
+		return newRouter(store, userConfig, catalog, tokenManager, mailer.NewMemoryMailer(), sealer)
+	}
+	login := func(router *gin.Engine, i int) int {
+		forwarded := map[string]string{"X-Forwarded-For": fmt.Sprintf("203.0.113.%d", i)}
+		body := fmt.Sprintf(`{"login":"nadie%d","password":"batería grapa"}`, i)
+		return doRequest(router, "POST", "/api/auth/login", "application/json", body, forwarded).Code
+	}
+
+	// httptest conecta desde 192.0.2.1, que no es un proxy de confianza
+	router := newTestRouter(nil)
+	assert.Equal(t, http.StatusUnauthorized, login(router, 1))
+	assert.Equal(t, http.StatusUnauthorized, login(router, 2))
+	assert.Equal(t, http.StatusTooManyRequests, login(router, 3))
+
+	router = newTestRouter([]string{"192.0.2.1"})
+	for i := 1; i <= 3; i++ {
+		assert.Equal(t, http.StatusUnauthorized, login(router, i))
+	}
+}
+
+// Caso de prueba: el subcomando token firma un token que la API acepta para el sujeto
+func TestRunToken(t *testing.T) {
+	userConfig := &config.UserConfig{Auth: config.AuthConfig{Issuer: config.DefaultTokenIssuer, Audience: config.DefaultTokenAudience,
+		Secret: "0123456789abcdef0123456789abcdef", TokenTTL: time.Minute}}
+
+	var out bytes.Buffer
+	require.NoError(t, runToken(&out, userConfig, []string{"-roles", "admin, auditor", "deploy-bot", "bot"}))
+	tokenManager, err := tokens.NewManager(userConfig.Auth)
+	require.NoError(t, err)
+	claims, err := tokenManager.Verify(strings.TrimSpace(out.String()))
+	require.NoError(t, err)
+	assert.Equal(t, "deploy-bot", claims.Subject)
+	assert.Equal(t, "bot", claims.Username)
+	assert.Equal(t, []string{"admin", "auditor"}, claims.Roles)
+	assert.False(t, claims.MultiFactor())
+
+	out.Reset()
+	require.NoError(t, runToken(&out, userConfig, []string{"-roles", "admin", "-mfa", "deploy-bot"}))
+	claims, err = tokenManager.Verify(strings.TrimSpace(out.String()))
+	require.NoError(t, err)
+	assert.True(t, claims.MultiFactor())
+
+	assert.Error(t, runToken(&out, userConfig, nil))
+	assert.Error(t, runToken(&out, userConfig, []string{"-scopes", "x", "deploy-bot"}))
+	assert.Error(t, runToken(&out, &config.UserConfig{}, []string{"deploy-bot"}))
+}
+
+func userIDs(page output.GetUsersPageOut) []uint {
+	ids := make([]uint, 0, len(page.Data))
+	for _, user := range page.Data {
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
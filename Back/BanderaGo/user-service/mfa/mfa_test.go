@@ -0,0 +1,73 @@
+package mfa
+
+import (
+	"bytes"
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Secreto de los vectores de prueba de RFC 6238 para SHA-1
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+// Caso de prueba: los códigos coinciden con los vectores de RFC 6238 (últimos 6 dígitos)
+func TestCode(t *testing.T) {
+	for unix, expected := range map[int64]string{59: "287082", 1111111109: "081804", 1234567890: "005924", 2000000000: "279037"} {
+		code, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, expected, code, unix)
+	}
+	_, err := Code("no es base32!", 1)
+	assert.Error(t, err)
+}
+
+// Caso de prueba: se acepta el código del periodo actual y de los adyacentes
+func TestVerify(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	now := time.Now()
+	code, err := Code(secret, Step(now))
+	require.NoError(t, err)
+
+	step, ok := Verify(secret, code, now)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now), step)
+	_, ok = Verify(secret, code[:3]+" "+code[3:], now.Add(Period))
+	assert.True(t, ok, "periodo siguiente y con espacio")
+	_, ok = Verify(secret, code, now.Add(3*Period))
+	assert.False(t, ok, "fuera de la tolerancia")
+	_, ok = Verify(secret, "12345", now)
+	assert.False(t, ok)
+}
+
+// Caso de prueba: el enlace otpauth lleva el emisor, la cuenta y el secreto, y el QR es un PNG
+func TestURIAndQRCode(t *testing.T) {
+	uri := URI("user-service", "ana@example.com", "JBSWY3DPEHPK3PXP")
+	parsed, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", parsed.Scheme)
+	assert.Equal(t, "totp", parsed.Host)
+	assert.Equal(t, "/user-service:ana@example.com", parsed.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", parsed.Query().Get("secret"))
+	assert.Equal(t, "user-service", parsed.Query().Get("issuer"))
+
+	png, err := QRCode(uri)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(png, []byte("\x89PNG")))
+}
+
+// Caso de prueba: los códigos de recuperación son distintos y su hash ignora el formato
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := NewRecoveryCodes(RecoveryCodeCount)
+	require.NoError(t, err)
+	require.Len(t, codes, RecoveryCodeCount)
+	require.Len(t, hashes, RecoveryCodeCount)
+	assert.Regexp(t, `^[a-z2-7]{4}-[a-z2-7]{4}$`, codes[0])
+	assert.NotEqual(t, codes[0], codes[1])
+	assert.Equal(t, hashes[0], HashRecoveryCode(codes[0]))
+	assert.Equal(t, hashes[0], HashRecoveryCode(" "+string(bytes.ToUpper([]byte(codes[0])))))
+}
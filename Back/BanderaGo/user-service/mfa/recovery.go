@@ -0,0 +1,40 @@
+package mfa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+)
+
+// RecoveryCodeCount es la cantidad de códigos de recuperación que se emiten juntos
+const RecoveryCodeCount = 10
+
+const recoveryCodeBytes = 5
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewRecoveryCodes genera códigos de un solo uso de la forma xxxx-xxxx. Devuelve los
+// códigos, que solo se muestran una vez, y sus hashes en el mismo orden.
+func NewRecoveryCodes(n int) (codes, hashes []string, err error) {
+	for i := 0; i < n; i++ {
+		value := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(value); err != nil {
+			return nil, nil, err
+		}
+		encoded := strings.ToLower(recoveryEncoding.EncodeToString(value))
+		code := encoded[:4] + "-" + encoded[4:]
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode calcula el hash con el que se guarda el código. Ignora mayúsculas,
+// espacios y guiones para aceptar el código como lo escriba el usuario.
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
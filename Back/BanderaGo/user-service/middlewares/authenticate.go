@@ -0,0 +1,141 @@
+package middlewares
+
+import (
+	"application/apperrors"
+	"application/i18n"
+	"application/problems"
+	"application/requestctx"
+	"application/tokens"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Realm con el que se anuncia el esquema Bearer en WWW-Authenticate
+const AuthRealm = "user-service"
+
+// APIKeyHeader es el encabezado con el que otros servicios envían su llave de API
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyVerifier devuelve la identidad de una llave de API activa
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, key string) (requestctx.Identity, error)
+}
+
+// SessionVerifier comprueba que la sesión con la que se emitió un token siga activa
+type SessionVerifier interface {
+	VerifySession(ctx context.Context, sessionID string) error
+}
+
+// Authenticate exige un token de acceso Bearer válido (RFC 6750) o una llave de API en
+// X-API-Key. La identidad queda en el contexto y su sujeto es el actor de la auditoría;
+// sin credenciales o con unas inválidas se responde 401 con WWW-Authenticate. Un token
+// emitido para una sesión revocada o vencida se rechaza como inválido.
+func Authenticate(manager *tokens.Manager, apiKeys APIKeyVerifier, sessions SessionVerifier, catalog *i18n.Catalog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		messages := catalog.Match(c.GetHeader("Accept-Language"))
+
+		if key := strings.TrimSpace(c.GetHeader(APIKeyHeader)); key != "" {
+			identity, err := apiKeys.VerifyAPIKey(c.Request.Context(), key)
+			switch {
+			case errors.Is(err, apperrors.ErrUnauthorized):
+				c.Header("WWW-Authenticate", `Bearer realm="`+AuthRealm+`"`)
+				respondUnauthorized(c, messages, problems.CodeInvalidAPIKey, messages.MessageErrorAPIKey)
+				return
+			case err != nil:
+				respondUnavailable(c, messages)
+				return
+			}
+			authenticated(c, identity)
+			return
+		}
+
+		raw, found := bearerToken(c.GetHeader("Authorization"))
+		if !found {
+			c.Header("WWW-Authenticate", `Bearer realm="`+AuthRealm+`"`)
+			respondUnauthorized(c, messages, problems.CodeAuthRequired, messages.MessageErrorAuthRequired)
+			return
+		}
+
+		claims, err := manager.Verify(raw)
+		if err != nil {
+			respondInvalidToken(c, messages)
+			return
+		}
+		err = verifySession(c.Request.Context(), sessions, claims)
+		switch {
+		case errors.Is(err, apperrors.ErrUnauthorized):
+			respondInvalidToken(c, messages)
+			return
+		case err != nil:
+			respondUnavailable(c, messages)
+			return
+		}
+
+		authenticated(c, identityOf(claims))
+	}
+}
+
+// AuthenticateOptional deja en el contexto la identidad de un token de acceso Bearer
+// válido, sin exigirlo: sin token o con uno inválido la solicitud sigue como anónima. La
+// usa la autorización OIDC para saber si el usuario ya inició sesión; no admite llaves
+// de API porque no representan a un usuario.
+func AuthenticateOptional(manager *tokens.Manager, sessions SessionVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if raw, found := bearerToken(c.GetHeader("Authorization")); found {
+			if claims, err := manager.Verify(raw); err == nil && verifySession(c.Request.Context(), sessions, claims) == nil {
+				authenticated(c, identityOf(claims))
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// verifySession comprueba la sesión del token; los tokens sin sesión no se comprueban
+func verifySession(ctx context.Context, sessions SessionVerifier, claims *tokens.Claims) error {
+	if claims.SessionID == "" {
+		return nil
+	}
+	return sessions.VerifySession(ctx, claims.SessionID)
+}
+
+func identityOf(claims *tokens.Claims) requestctx.Identity {
+	return requestctx.Identity{Subject: claims.Subject, Username: claims.Username, Roles: claims.Roles, SessionID: claims.SessionID, MFA: claims.MultiFactor()}
+}
+
+// authenticated deja la identidad y el actor en el contexto y sigue con la solicitud
+func authenticated(c *gin.Context, identity requestctx.Identity) {
+	ctx := requestctx.WithIdentity(c.Request.Context(), identity)
+	c.Request = c.Request.WithContext(requestctx.WithActor(ctx, identity.Subject))
+	c.Next()
+}
+
+// bearerToken extrae el token del encabezado Authorization; el esquema no distingue
+// mayúsculas de minúsculas
+func bearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func respondUnauthorized(c *gin.Context, messages *i18n.Messages, code, detail string) {
+	c.Header("Content-Language", messages.Language)
+	problems.Respond(c, problems.New(http.StatusUnauthorized, code, detail))
+}
+
+func respondInvalidToken(c *gin.Context, messages *i18n.Messages) {
+	c.Header("WWW-Authenticate", `Bearer realm="`+AuthRealm+`", error="invalid_token", error_description="the access token is invalid or expired"`)
+	respondUnauthorized(c, messages, problems.CodeInvalidToken, messages.MessageErrorToken)
+}
+
+func respondUnavailable(c *gin.Context, messages *i18n.Messages) {
+	c.Header("Content-Language", messages.Language)
+	problems.Respond(c, problems.New(http.StatusServiceUnavailable, problems.CodeServiceUnavailable, messages.MessageErrorUnavailable))
+}
@@ -0,0 +1,253 @@
+package middlewares
+
+import (
+	"application/apperrors"
+	"application/config"
+	"application/dtos/output"
+	"application/problems"
+	"application/requestctx"
+	"application/tokens"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManager(t *testing.T) *tokens.Manager {
+	manager, err := tokens.NewManager(config.AuthConfig{
+		Issuer:   config.DefaultTokenIssuer,
+		Audience: config.DefaultTokenAudience,
+		Secret:   "0123456789abcdef0123456789abcdef",
+		TokenTTL: time.Minute,
+	})
+	require.NoError(t, err)
+	return manager
+}
+
+// apiKeyVerifierFunc adapta una función a APIKeyVerifier
+type apiKeyVerifierFunc func(ctx context.Context, key string) (requestctx.Identity, error)
+
+func (f apiKeyVerifierFunc) VerifyAPIKey(ctx context.Context, key string) (requestctx.Identity, error) {
+	return f(ctx, key)
+}
+
+// testAPIKeys acepta solo la llave usk_valida y falla con usk_caida
+var testAPIKeys = apiKeyVerifierFunc(func(ctx context.Context, key string) (requestctx.Identity, error) {
+	switch key {
+	case "usk_valida":
+		return requestctx.Identity{Subject: "apikey:1", Username: "batch", Permissions: []string{"users:read"}}, nil
+	case "usk_caida":
+		return requestctx.Identity{}, errors.New("database is down")
+	}
+	return requestctx.Identity{}, apperrors.Unauthorized(errors.New("invalid key"))
+})
+
+// sessionVerifierFunc adapta una función a SessionVerifier
+type sessionVerifierFunc func(ctx context.Context, sessionID string) error
+
+func (f sessionVerifierFunc) VerifySession(ctx context.Context, sessionID string) error {
+	return f(ctx, sessionID)
+}
+
+// testSessions da por revocada la sesión 4 y falla con la 5
+var testSessions = sessionVerifierFunc(func(ctx context.Context, sessionID string) error {
+	switch sessionID {
+	case "4":
+		return apperrors.Unauthorized(errors.New("revoked session"))
+	case "5":
+		return errors.New("database is down")
+	}
+	return nil
+})
+
+func newAuthRouter(manager *tokens.Manager, captured *requestctx.Identity, actor *string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Authenticate(manager, testAPIKeys, testSessions, testCatalog))
+	router.GET("/", func(c *gin.Context) {
+		*captured, _ = requestctx.IdentityFrom(c.Request.Context())
+		*actor = requestctx.Actor(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+// Caso de prueba: un token válido deja la identidad y el actor en el contexto
+func TestAuthenticateValidToken(t *testing.T) {
+	manager := newTestManager(t)
+	var identity requestctx.Identity
+	var actor string
+	router := newAuthRouter(manager, &identity, &actor)
+
+	token, _, err := manager.Issue("7", "ana", []string{"auditor"}, nil)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "bearer "+token)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, requestctx.Identity{Subject: "7", Username: "ana", Roles: []string{"auditor"}}, identity)
+	assert.Equal(t, "7", actor)
+
+	// El token de una sesión con segundo factor lo indica en la identidad
+	token, _, err = manager.IssueForSession("7", "ana", "3", []string{tokens.AMRPassword, tokens.AMROTP, tokens.AMRMultiFactor})
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, requestctx.Identity{Subject: "7", Username: "ana", SessionID: "3", MFA: true}, identity)
+}
+
+// Caso de prueba: sin token o con uno inválido se responde 401 con WWW-Authenticate
+func TestAuthenticateRejects(t *testing.T) {
+	manager := newTestManager(t)
+	var identity requestctx.Identity
+	var actor string
+	router := newAuthRouter(manager, &identity, &actor)
+
+	cases := []struct {
+		name          string
+		authorization string
+		code          string
+		challenge     string
+	}{
+		{"sin encabezado", "", problems.CodeAuthRequired, `Bearer realm="user-service"`},
+		{"otro esquema", "Basic YW5hOnNlY3JldA==", problems.CodeAuthRequired, `Bearer realm="user-service"`},
+		{"token inválido", "Bearer abc.def.ghi", problems.CodeInvalidToken,
+			`Bearer realm="user-service", error="invalid_token", error_description="the access token is invalid or expired"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, tc.challenge, w.Header().Get("WWW-Authenticate"))
+			assert.Equal(t, problems.ContentType, w.Header().Get("Content-Type"))
+
+			var problem output.ProblemOut
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tc.code, problem.Code)
+		})
+	}
+}
+
+// Caso de prueba: el token de una sesión revocada se rechaza como inválido y si no se puede
+// consultar la sesión se responde 503
+func TestAuthenticateRevokedSession(t *testing.T) {
+	manager := newTestManager(t)
+	var identity requestctx.Identity
+	var actor string
+	router := newAuthRouter(manager, &identity, &actor)
+
+	cases := []struct {
+		sessionID string
+		status    int
+		code      string
+	}{
+		{"4", http.StatusUnauthorized, problems.CodeInvalidToken},
+		{"5", http.StatusServiceUnavailable, problems.CodeServiceUnavailable},
+	}
+	for _, tc := range cases {
+		token, _, err := manager.IssueForSession("7", "ana", tc.sessionID, []string{tokens.AMRPassword})
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tc.status, w.Code, tc.sessionID)
+		var problem output.ProblemOut
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		assert.Equal(t, tc.code, problem.Code)
+	}
+	assert.Empty(t, actor)
+}
+
+// Caso de prueba: una llave de API válida autentica y una inválida responde 401
+func TestAuthenticateAPIKey(t *testing.T) {
+	manager := newTestManager(t)
+	var identity requestctx.Identity
+	var actor string
+	router := newAuthRouter(manager, &identity, &actor)
+
+	cases := []struct {
+		key    string
+		status int
+		code   string
+	}{
+		{"usk_valida", http.StatusNoContent, ""},
+		{"usk_otra", http.StatusUnauthorized, problems.CodeInvalidAPIKey},
+		{"usk_caida", http.StatusServiceUnavailable, problems.CodeServiceUnavailable},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set(APIKeyHeader, tc.key)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tc.status, w.Code, tc.key)
+		if tc.code != "" {
+			var problem output.ProblemOut
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tc.code, problem.Code)
+		}
+	}
+	assert.Equal(t, requestctx.Identity{Subject: "apikey:1", Username: "batch", Permissions: []string{"users:read"}}, identity)
+	assert.Equal(t, "apikey:1", actor)
+}
+
+// Caso de prueba: la autenticación opcional deja la identidad de un token válido y deja
+// pasar sin identidad las solicitudes sin token, con uno inválido, de una sesión revocada
+// o con llave de API
+func TestAuthenticateOptional(t *testing.T) {
+	manager := newTestManager(t)
+	var identity requestctx.Identity
+	var found bool
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(AuthenticateOptional(manager, testSessions))
+	router.GET("/", func(c *gin.Context) {
+		identity, found = requestctx.IdentityFrom(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+
+	token, _, err := manager.IssueForSession("7", "ana", "3", []string{tokens.AMRPassword})
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.True(t, found)
+	assert.Equal(t, requestctx.Identity{Subject: "7", Username: "ana", SessionID: "3"}, identity)
+
+	revoked, _, err := manager.IssueForSession("7", "ana", "4", []string{tokens.AMRPassword})
+	require.NoError(t, err)
+	for _, header := range []string{"", "Bearer abc.def.ghi", "Basic YW5hOnNlY3JldA==", "Bearer " + revoked} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		req.Header.Set(APIKeyHeader, "usk_valida")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code, header)
+		assert.False(t, found, header)
+	}
+}
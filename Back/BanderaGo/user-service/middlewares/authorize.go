@@ -0,0 +1,82 @@
+package middlewares
+
+import (
+	"application/i18n"
+	"application/problems"
+	"application/requestctx"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionResolver calcula los permisos efectivos de una identidad
+type PermissionResolver interface {
+	Permissions(ctx context.Context, identity requestctx.Identity) ([]string, error)
+}
+
+// Authorize completa la identidad autenticada con los permisos de sus roles. Debe ir
+// después de Authenticate; los roles se consultan en cada solicitud para que una
+// revocación tenga efecto sin esperar a que venza el token.
+func Authorize(resolver PermissionResolver, catalog *i18n.Catalog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := requestctx.IdentityFrom(c.Request.Context())
+		if !ok {
+			c.Next()
+			return
+		}
+
+		permissions, err := resolver.Permissions(c.Request.Context(), identity)
+		if err != nil {
+			messages := catalog.Match(c.GetHeader("Accept-Language"))
+			c.Header("Content-Language", messages.Language)
+			if errors.Is(err, context.DeadlineExceeded) {
+				problems.Respond(c, problems.New(http.StatusGatewayTimeout, problems.CodeRequestTimeout, messages.MessageErrorTimeout))
+				return
+			}
+			problems.Respond(c, problems.New(http.StatusServiceUnavailable, problems.CodeServiceUnavailable, messages.MessageErrorUnavailable))
+			return
+		}
+
+		identity.Permissions = permissions
+		c.Request = c.Request.WithContext(requestctx.WithIdentity(c.Request.Context(), identity))
+		c.Next()
+	}
+}
+
+// Require responde 403 si la identidad no tiene el permiso indicado
+func Require(catalog *i18n.Catalog, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, _ := requestctx.IdentityFrom(c.Request.Context())
+		if !identity.Can(permission) {
+			respondForbidden(c, catalog)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSelfOr permite la operación sobre el propio usuario (parámetro :id) y, sobre
+// cualquier otro, solo con el permiso indicado
+func RequireSelfOr(catalog *i18n.Catalog, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, _ := requestctx.IdentityFrom(c.Request.Context())
+		if identity.Can(permission) {
+			c.Next()
+			return
+		}
+		if id, err := strconv.ParseUint(c.Param("id"), 10, 64); err == nil && identity.IsUser(uint(id)) {
+			c.Next()
+			return
+		}
+		respondForbidden(c, catalog)
+	}
+}
+
+func respondForbidden(c *gin.Context, catalog *i18n.Catalog) {
+	messages := catalog.Match(c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", messages.Language)
+	problems.Respond(c, problems.New(http.StatusForbidden, problems.CodeForbidden, messages.MessageErrorForbidden))
+}
@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"application/dtos/output"
+	"application/models"
+	"application/problems"
+	"application/requestctx"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resolverFunc adapta una función a PermissionResolver
+type resolverFunc func(ctx context.Context, identity requestctx.Identity) ([]string, error)
+
+func (f resolverFunc) Permissions(ctx context.Context, identity requestctx.Identity) ([]string, error) {
+	return f(ctx, identity)
+}
+
+func newAuthorizeRouter(subject string, resolver PermissionResolver) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(requestctx.WithIdentity(c.Request.Context(), requestctx.Identity{Subject: subject}))
+	})
+	router.Use(Authorize(resolver, testCatalog))
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	router.DELETE("/users/:id", Require(testCatalog, models.PermissionUsersDelete), ok)
+	router.PUT("/users/:id", RequireSelfOr(testCatalog, models.PermissionUsersUpdate), ok)
+	return router
+}
+
+func serve(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, nil)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// Caso de prueba: los permisos resueltos habilitan las rutas y sin ellos se responde 403
+func TestAuthorizePermissions(t *testing.T) {
+	admin := newAuthorizeRouter("1", resolverFunc(func(ctx context.Context, identity requestctx.Identity) ([]string, error) {
+		return models.Permissions, nil
+	}))
+	assert.Equal(t, http.StatusNoContent, serve(admin, "DELETE", "/users/2").Code)
+	assert.Equal(t, http.StatusNoContent, serve(admin, "PUT", "/users/2").Code)
+
+	user := newAuthorizeRouter("2", resolverFunc(func(ctx context.Context, identity requestctx.Identity) ([]string, error) {
+		return nil, nil
+	}))
+	assert.Equal(t, http.StatusNoContent, serve(user, "PUT", "/users/2").Code)
+
+	for _, w := range []*httptest.ResponseRecorder{serve(user, "PUT", "/users/3"), serve(user, "DELETE", "/users/2"), serve(user, "PUT", "/users/abc")} {
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		var problem output.ProblemOut
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		assert.Equal(t, problems.CodeForbidden, problem.Code)
+	}
+}
+
+// Caso de prueba: si no se pueden consultar los roles se responde 503 sin ejecutar la ruta
+func TestAuthorizeUnavailable(t *testing.T) {
+	router := newAuthorizeRouter("1", resolverFunc(func(ctx context.Context, identity requestctx.Identity) ([]string, error) {
+		return nil, errors.New("database is down")
+	}))
+
+	w := serve(router, "PUT", "/users/1")
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var problem output.ProblemOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, problems.CodeServiceUnavailable, problem.Code)
+}
@@ -0,0 +1,50 @@
+package middlewares
+
+import (
+	"application/requestctx"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newContextRouter(captured *string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/", func(c *gin.Context) {
+		*captured = requestctx.RequestID(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+// Caso de prueba: se conserva el identificador enviado por el cliente
+func TestRequestIDFromHeader(t *testing.T) {
+	var captured string
+	router := newContextRouter(&captured)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "abc-123", w.Header().Get(RequestIDHeader))
+	assert.Equal(t, "abc-123", captured)
+}
+
+// Caso de prueba: se genera un identificador si falta o no es válido
+func TestRequestIDGenerated(t *testing.T) {
+	var captured string
+	router := newContextRouter(&captured)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "bad id\n")
+	router.ServeHTTP(w, req)
+
+	assert.Len(t, w.Header().Get(RequestIDHeader), 32)
+	assert.Equal(t, w.Header().Get(RequestIDHeader), captured)
+}
@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"application/i18n"
+	"application/problems"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout limita la duración de cada solicitud propagando un contexto con fecha límite
+// hacia las capas inferiores. Si el plazo vence sin respuesta se devuelve 504.
+func Timeout(timeout time.Duration, catalog *i18n.Catalog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			messages := catalog.Match(c.GetHeader("Accept-Language"))
+			c.Header("Content-Language", messages.Language)
+			problems.Respond(c, problems.New(http.StatusGatewayTimeout, problems.CodeRequestTimeout, messages.MessageErrorTimeout))
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package middlewares
+
+import (
+	"application/dtos/output"
+	"application/i18n"
+	"application/problems"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testCatalog, _ = i18n.NewCatalog("es")
+
+func newTimeoutRouter(timeout time.Duration, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Timeout(timeout, testCatalog))
+	router.GET("/", handler)
+	return router
+}
+
+// Caso de prueba: la solicitud termina antes del tiempo límite
+func TestTimeoutOk(t *testing.T) {
+	router := newTimeoutRouter(time.Second, func(c *gin.Context) {
+		_, hasDeadline := c.Request.Context().Deadline()
+		assert.True(t, hasDeadline, "Expected request context to carry a deadline")
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+// Caso de prueba: la solicitud excede el tiempo límite sin escribir respuesta
+func TestTimeoutExceeded(t *testing.T) {
+	router := newTimeoutRouter(10*time.Millisecond, func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Language", "en")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Equal(t, "en", w.Header().Get("Content-Language"))
+	assert.Equal(t, problems.ContentType, w.Header().Get("Content-Type"))
+
+	var problem output.ProblemOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, problems.CodeRequestTimeout, problem.Code)
+	assert.Equal(t, testCatalog.Match("en").MessageErrorTimeout, problem.Detail)
+	assert.Equal(t, "/", problem.Instance)
+}
+
+// Caso de prueba: un tiempo límite en cero desactiva el middleware
+func TestTimeoutDisabled(t *testing.T) {
+	router := newTimeoutRouter(0, func(c *gin.Context) {
+		_, hasDeadline := c.Request.Context().Deadline()
+		assert.False(t, hasDeadline, "Expected request context without deadline")
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"application/config"
+	"application/persistence/migrations"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = "uso: migrate up|down|status|to <versión>"
+
+// runMigrate ejecuta el subcomando "migrate" sobre la base de datos configurada
+func runMigrate(ctx context.Context, out io.Writer, userConfig *config.UserConfig, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db, err := openDatabase(userConfig)
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	migrator, err := migrations.NewMigrator(db, userConfig.DBTable)
+	if err != nil {
+		return err
+	}
+
+	var changed []migrations.Migration
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		changed, err = migrator.Up(ctx)
+	case args[0] == "down" && len(args) == 1:
+		changed, err = migrator.Down(ctx)
+	case args[0] == "to" && len(args) == 2:
+		version, parseErr := strconv.ParseUint(args[1], 10, 64)
+		if parseErr != nil {
+			return fmt.Errorf("versión inválida '%s'", args[1])
+		}
+		changed, err = migrator.To(ctx, uint(version))
+	case args[0] == "status" && len(args) == 1:
+		return printMigrationStatus(ctx, out, migrator)
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	for _, migration := range changed {
+		fmt.Fprintf(out, "%04d_%s\n", migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		fmt.Fprintln(out, "Sin cambios")
+	}
+	return nil
+}
+
+func printMigrationStatus(ctx context.Context, out io.Writer, migrator *migrations.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSIÓN\tNOMBRE\tAPLICADA")
+	for _, status := range statuses {
+		applied := "pendiente"
+		if status.AppliedAt != nil {
+			applied = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, applied)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"application/config"
+	"log"
+	"time"
+)
+
+// APIKey autentica a otros servicios con un conjunto fijo de permisos. Solo se guarda
+// el hash de la llave; el prefijo es visible para reconocerla en los listados.
+type APIKey struct {
+	ID          uint     `gorm:"primaryKey"`
+	Name        string   `gorm:"size:100"`
+	Prefix      string   `gorm:"size:32;uniqueIndex"`
+	Hash        string   `gorm:"size:64"`
+	Permissions []string `gorm:"serializer:json"`
+	ExpiresAt   *time.Time
+	RevokedAt   *time.Time
+	CreatedBy   string `gorm:"size:255"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// TableName se deriva de la tabla de usuarios, p. ej. users -> users_api_keys
+func (APIKey) TableName() string {
+	tableName, err := config.GetEnvVariable("DB_TABLE")
+	if err != nil {
+		log.Fatalf("No se pudo obtener el valor del nombre de la tabla: %v", err)
+	}
+	return tableName + "_api_keys"
+}
+
+// Active indica si la llave puede usarse en el instante indicado
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
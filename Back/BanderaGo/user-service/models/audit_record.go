@@ -0,0 +1,47 @@
+package models
+
+import (
+	"application/config"
+	"log"
+	"time"
+)
+
+// Operaciones que se registran en la auditoría de usuarios
+const (
+	AuditCreate      = "create"
+	AuditUpdate      = "update"
+	AuditDelete      = "delete"
+	AuditRestore     = "restore"
+	AuditPurge       = "purge"
+	AuditPassword    = "password"
+	AuditRoles       = "roles"
+	AuditLock        = "lock"
+	AuditUnlock      = "unlock"
+	AuditVerifyEmail = "verify_email"
+)
+
+// FieldChange guarda el valor de un campo antes y después de una operación
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditRecord es un registro de solo inserción con un cambio hecho sobre un usuario
+type AuditRecord struct {
+	ID        uint `gorm:"primarykey"`
+	UserID    uint
+	Actor     string
+	RequestID string
+	Operation string
+	Changes   map[string]FieldChange `gorm:"serializer:json"`
+	CreatedAt time.Time
+}
+
+// TableName se deriva de la tabla de usuarios, p. ej. users -> users_audit
+func (AuditRecord) TableName() string {
+	tableName, err := config.GetEnvVariable("DB_TABLE")
+	if err != nil {
+		log.Fatalf("No se pudo obtener el valor del nombre de la tabla: %v", err)
+	}
+	return tableName + "_audit"
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"application/config"
+	"log"
+	"time"
+)
+
+// EmailToken guarda el hash de un token de un solo uso enviado por correo, para verificar
+// el correo o restablecer la contraseña. Email es la dirección a la que se envió.
+type EmailToken struct {
+	Hash      string `gorm:"primaryKey;size:64"`
+	UserID    uint   `gorm:"index"`
+	Purpose   string `gorm:"size:32"`
+	Email     string `gorm:"size:254"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// TableName se deriva de la tabla de usuarios, p. ej. users -> users_email_tokens
+func (EmailToken) TableName() string {
+	tableName, err := config.GetEnvVariable("DB_TABLE")
+	if err != nil {
+		log.Fatalf("No se pudo obtener el valor del nombre de la tabla: %v", err)
+	}
+	return tableName + "_email_tokens"
+}
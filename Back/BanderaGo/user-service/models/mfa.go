@@ -0,0 +1,50 @@
+package models
+
+import (
+	"application/config"
+	"log"
+	"time"
+)
+
+// UserMFA es el segundo factor TOTP de un usuario. Mientras ConfirmedAt sea nil la
+// inscripción está pendiente y no se pide al iniciar sesión.
+type UserMFA struct {
+	UserID uint   `gorm:"primaryKey;autoIncrement:false"`
+	Secret string `gorm:"size:64"`
+	// LastStep es el último periodo TOTP aceptado; un código no se acepta dos veces
+	LastStep    int64
+	ConfirmedAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// TableName se deriva de la tabla de usuarios, p. ej. users -> users_mfa
+func (UserMFA) TableName() string {
+	tableName, err := config.GetEnvVariable("DB_TABLE")
+	if err != nil {
+		log.Fatalf("No se pudo obtener el valor del nombre de la tabla: %v", err)
+	}
+	return tableName + "_mfa"
+}
+
+// Enabled indica si el usuario ya confirmó el segundo factor
+func (m *UserMFA) Enabled() bool {
+	return m.ConfirmedAt != nil
+}
+
+// RecoveryCode guarda el hash de un código de recuperación de un solo uso
+type RecoveryCode struct {
+	Hash      string `gorm:"primaryKey;size:64"`
+	UserID    uint   `gorm:"index"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// TableName se deriva de la tabla de usuarios, p. ej. users -> users_recovery_codes
+func (RecoveryCode) TableName() string {
+	tableName, err := config.GetEnvVariable("DB_TABLE")
+	if err != nil {
+		log.Fatalf("No se pudo obtener el valor del nombre de la tabla: %v", err)
+	}
+	return tableName + "_recovery_codes"
+}
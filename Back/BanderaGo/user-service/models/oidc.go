@@ -0,0 +1,80 @@
+package models
+
+import (
+	"application/config"
+	"log"
+	"time"
+)
+
+// OIDCClient es una aplicación registrada que inicia sesión con el proveedor OpenID
+// Connect. Solo se guarda el hash del secreto; un cliente sin secreto es público y se
+// autentica únicamente con PKCE.
+type OIDCClient struct {
+	ID           string   `gorm:"primaryKey;size:64"`
+	Name         string   `gorm:"size:100"`
+	SecretHash   *string  `gorm:"size:64"`
+	RedirectURIs []string `gorm:"serializer:json"`
+	CreatedBy    string   `gorm:"size:255"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// TableName se deriva de la tabla de usuarios, p. ej. users -> users_oidc_clients
+func (OIDCClient) TableName() string {
+	return oidcTableName("_oidc_clients")
+}
+
+// Public indica si el cliente no tiene secreto
+func (c *OIDCClient) Public() bool {
+	return c.SecretHash == nil
+}
+
+// AuthorizationCode guarda el hash de un código de autorización de un solo uso junto con
+// lo que el cliente pidió al obtenerlo
+type AuthorizationCode struct {
+	Hash          string `gorm:"primaryKey;size:64"`
+	ClientID      string `gorm:"size:64;index"`
+	UserID        uint   `gorm:"index"`
+	RedirectURI   string
+	Scope         string
+	Nonce         string
+	CodeChallenge string `gorm:"size:128"`
+	// AuthTime es el momento en que el usuario inició la sesión con la que se autorizó
+	AuthTime time.Time
+	// MultiFactor indica si esa sesión se inició con un segundo factor
+	MultiFactor bool
+	ExpiresAt   time.Time
+	UsedAt      *time.Time
+	CreatedAt   time.Time
+}
+
+// TableName se deriva de la tabla de usuarios, p. ej. users -> users_oidc_codes
+func (AuthorizationCode) TableName() string {
+	return oidcTableName("_oidc_codes")
+}
+
+// SigningKey es una llave RSA con la que el proveedor firma los tokens. La llave privada
+// se guarda cifrada; la pública se guarda en DER (PKIX) para publicarla sin descifrar.
+type SigningKey struct {
+	ID         string `gorm:"primaryKey;size:64"`
+	Algorithm  string `gorm:"size:16"`
+	PublicKey  []byte
+	PrivateKey []byte
+	CreatedAt  time.Time
+	// RetiredAt es el momento en que la llave dejó de firmar; sigue publicada hasta que
+	// pasa la retención
+	RetiredAt *time.Time
+}
+
+// TableName se deriva de la tabla de usuarios, p. ej. users -> users_oidc_keys
+func (SigningKey) TableName() string {
+	return oidcTableName("_oidc_keys")
+}
+
+func oidcTableName(suffix string) string {
+	tableName, err := config.GetEnvVariable("DB_TABLE")
+	if err != nil {
+		log.Fatalf("No se pudo obtener el valor del nombre de la tabla: %v", err)
+	}
+	return tableName + suffix
+}
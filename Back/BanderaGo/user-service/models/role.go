@@ -0,0 +1,82 @@
+package models
+
+import (
+	"application/config"
+	"log"
+	"time"
+)
+
+// Permisos que pueden otorgar los roles. Sin permisos un usuario solo puede consultar y
+// modificar sus propios datos.
+const (
+	PermissionUsersRead      = "users:read"
+	PermissionUsersCreate    = "users:create"
+	PermissionUsersUpdate    = "users:update"
+	PermissionUsersDelete    = "users:delete"
+	PermissionUsersPassword  = "users:password"
+	PermissionAuditRead      = "audit:read"
+	PermissionRolesManage    = "roles:manage"
+	PermissionAPIKeysManage  = "apikeys:manage"
+	PermissionSessionsManage = "sessions:manage"
+	PermissionMFAManage      = "mfa:manage"
+	PermissionUsersUnlock    = "users:unlock"
+	PermissionOIDCManage     = "oidc:manage"
+	PermissionUsersPurge     = "users:purge"
+)
+
+// Permissions lista todos los permisos válidos
+var Permissions = []string{
+	PermissionUsersRead,
+	PermissionUsersCreate,
+	PermissionUsersUpdate,
+	PermissionUsersDelete,
+	PermissionUsersPassword,
+	PermissionAuditRead,
+	PermissionRolesManage,
+	PermissionAPIKeysManage,
+	PermissionSessionsManage,
+	PermissionMFAManage,
+	PermissionUsersUnlock,
+	PermissionOIDCManage,
+	PermissionUsersPurge,
+}
+
+// Roles que crea la migración
+const (
+	RoleAdmin   = "admin"
+	RoleAuditor = "auditor"
+)
+
+// Role agrupa permisos que se asignan a los usuarios
+type Role struct {
+	Name        string   `gorm:"primaryKey;size:64"`
+	Description string   `gorm:"size:255"`
+	Permissions []string `gorm:"serializer:json"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// TableName se deriva de la tabla de usuarios, p. ej. users -> users_roles
+func (Role) TableName() string {
+	tableName, err := config.GetEnvVariable("DB_TABLE")
+	if err != nil {
+		log.Fatalf("No se pudo obtener el valor del nombre de la tabla: %v", err)
+	}
+	return tableName + "_roles"
+}
+
+// UserRole asigna un rol a un usuario
+type UserRole struct {
+	UserID    uint   `gorm:"primaryKey"`
+	Role      string `gorm:"primaryKey;size:64"`
+	CreatedAt time.Time
+}
+
+// TableName se deriva de la tabla de usuarios, p. ej. users -> users_user_roles
+func (UserRole) TableName() string {
+	tableName, err := config.GetEnvVariable("DB_TABLE")
+	if err != nil {
+		log.Fatalf("No se pudo obtener el valor del nombre de la tabla: %v", err)
+	}
+	return tableName + "_user_roles"
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"application/config"
+	"log"
+	"time"
+)
+
+// Session agrupa los refresh tokens emitidos a partir de un mismo inicio de sesión.
+// Al revocarla ya no se pueden renovar los tokens de acceso.
+type Session struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"index"`
+	UserAgent  string `gorm:"size:255"`
+	IP         string `gorm:"size:45"`
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+	// MFA indica que la sesión se abrió verificando un segundo factor
+	MFA bool
+}
+
+// TableName se deriva de la tabla de usuarios, p. ej. users -> users_sessions
+func (Session) TableName() string {
+	tableName, err := config.GetEnvVariable("DB_TABLE")
+	if err != nil {
+		log.Fatalf("No se pudo obtener el valor del nombre de la tabla: %v", err)
+	}
+	return tableName + "_sessions"
+}
+
+// Active indica si la sesión puede renovarse en el instante indicado
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// RefreshToken guarda el hash de un refresh token. Cada token se usa una sola vez: al
+// renovar se marca como usado y se emite otro para la misma sesión.
+type RefreshToken struct {
+	Hash      string `gorm:"primaryKey;size:64"`
+	SessionID uint   `gorm:"index"`
+	CreatedAt time.Time
+	UsedAt    *time.Time
+}
+
+// TableName se deriva de la tabla de usuarios, p. ej. users -> users_refresh_tokens
+func (RefreshToken) TableName() string {
+	tableName, err := config.GetEnvVariable("DB_TABLE")
+	if err != nil {
+		log.Fatalf("No se pudo obtener el valor del nombre de la tabla: %v", err)
+	}
+	return tableName + "_refresh_tokens"
+}
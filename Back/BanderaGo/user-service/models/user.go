@@ -3,6 +3,7 @@ package models
 import (
 	"application/config"
 	"log"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -11,6 +12,28 @@ type User struct {
 	gorm.Model
 	Name     string `gorm:"size:255"`
 	LastName string `gorm:"size:255"`
+	// Los datos de contacto son únicos y opcionales: nil se guarda como NULL, que no
+	// entra en conflicto con otros usuarios sin ese dato
+	Email       *string `gorm:"size:254;uniqueIndex"`
+	Username    *string `gorm:"size:64;uniqueIndex"`
+	Phone       *string `gorm:"size:16;uniqueIndex"`
+	DisplayName string  `gorm:"size:255"`
+	// PasswordHash incluye el algoritmo y sus parámetros; nil indica que el usuario no
+	// tiene contraseña y no puede iniciar sesión con una
+	PasswordHash      *string `gorm:"size:255"`
+	PasswordChangedAt *time.Time
+	// EmailVerifiedAt es cuándo el usuario confirmó que el correo es suyo; cambiar el
+	// correo lo vuelve nil
+	EmailVerifiedAt *time.Time
+	// Version se incrementa en cada actualización y se usa como ETag
+	Version uint `gorm:"not null;default:1"`
+}
+
+func (u *User) BeforeCreate(tx *gorm.DB) error {
+	if u.Version == 0 {
+		u.Version = 1
+	}
+	return nil
 }
 
 func (User) TableName() string {
@@ -3,6 +3,7 @@ package models
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -18,3 +19,67 @@ func TestUserTableName(t *testing.T) {
 	tableName := User{}.TableName()
 	assert.Equal(t, expectedTableName, tableName)
 }
+
+func TestUserBeforeCreate(t *testing.T) {
+	// Caso de prueba: un usuario nuevo inicia en la versión 1
+	user := User{}
+	assert.NoError(t, user.BeforeCreate(nil))
+	assert.Equal(t, uint(1), user.Version)
+}
+
+func TestAuditRecordTableName(t *testing.T) {
+	// Caso de prueba: la tabla de auditoría se deriva de la de usuarios
+	os.Setenv("DB_TABLE", "users")
+	defer os.Unsetenv("DB_TABLE")
+
+	assert.Equal(t, "users_audit", AuditRecord{}.TableName())
+}
+
+func TestRoleTableNames(t *testing.T) {
+	// Caso de prueba: las tablas de roles se derivan de la de usuarios
+	os.Setenv("DB_TABLE", "users")
+	defer os.Unsetenv("DB_TABLE")
+
+	assert.Equal(t, "users_roles", Role{}.TableName())
+	assert.Equal(t, "users_user_roles", UserRole{}.TableName())
+}
+
+func TestAPIKeyActive(t *testing.T) {
+	// Caso de prueba: una llave revocada o vencida deja de estar activa
+	os.Setenv("DB_TABLE", "users")
+	defer os.Unsetenv("DB_TABLE")
+	now := time.Now()
+	later, earlier := now.Add(time.Hour), now.Add(-time.Hour)
+
+	assert.Equal(t, "users_api_keys", APIKey{}.TableName())
+	assert.True(t, (&APIKey{}).Active(now))
+	assert.True(t, (&APIKey{ExpiresAt: &later}).Active(now))
+	assert.False(t, (&APIKey{ExpiresAt: &earlier}).Active(now))
+	assert.False(t, (&APIKey{RevokedAt: &earlier}).Active(now))
+}
+
+func TestSessionActive(t *testing.T) {
+	// Caso de prueba: una sesión revocada o vencida ya no se puede renovar
+	os.Setenv("DB_TABLE", "users")
+	defer os.Unsetenv("DB_TABLE")
+	now := time.Now()
+	later, earlier := now.Add(time.Hour), now.Add(-time.Hour)
+
+	assert.Equal(t, "users_sessions", Session{}.TableName())
+	assert.Equal(t, "users_refresh_tokens", RefreshToken{}.TableName())
+	assert.True(t, (&Session{ExpiresAt: later}).Active(now))
+	assert.False(t, (&Session{ExpiresAt: earlier}).Active(now))
+	assert.False(t, (&Session{ExpiresAt: later, RevokedAt: &earlier}).Active(now))
+}
+
+func TestUserMFAEnabled(t *testing.T) {
+	// Caso de prueba: el segundo factor solo está activo después de confirmarlo
+	os.Setenv("DB_TABLE", "users")
+	defer os.Unsetenv("DB_TABLE")
+	now := time.Now()
+
+	assert.Equal(t, "users_mfa", UserMFA{}.TableName())
+	assert.Equal(t, "users_recovery_codes", RecoveryCode{}.TableName())
+	assert.False(t, (&UserMFA{}).Enabled())
+	assert.True(t, (&UserMFA{ConfirmedAt: &now}).Enabled())
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"application/config"
+	"log"
+	"time"
+)
+
+// UserVersion es una instantánea del usuario vigente desde ValidFrom hasta la siguiente
+// instantánea del mismo usuario. Un borrado guarda una instantánea con DeletedAt y la
+// misma versión que la anterior, ya que no la incrementa.
+type UserVersion struct {
+	ID          uint `gorm:"primarykey"`
+	UserID      uint
+	Version     uint
+	Name        string
+	LastName    string
+	Email       *string
+	Username    *string
+	Phone       *string
+	DisplayName string
+	DeletedAt   *time.Time
+	ValidFrom   time.Time
+}
+
+// TableName se deriva de la tabla de usuarios, p. ej. users -> users_versions
+func (UserVersion) TableName() string {
+	tableName, err := config.GetEnvVariable("DB_TABLE")
+	if err != nil {
+		log.Fatalf("No se pudo obtener el valor del nombre de la tabla: %v", err)
+	}
+	return tableName + "_versions"
+}
@@ -0,0 +1,147 @@
+package oidc
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AlgRS256 es el algoritmo con el que se firman los ID tokens y los tokens de acceso;
+// es el que todo cliente de OpenID Connect debe soportar (OIDC Core, sección 15.1)
+const AlgRS256 = "RS256"
+
+const rsaKeyBits = 2048
+
+var errSealedKey = errors.New("la llave cifrada no es válida o se cifró con otro secreto")
+
+// Sealer cifra las llaves privadas de firma con AES-256-GCM antes de guardarlas
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+// NewSealer deriva la llave de cifrado del secreto
+func NewSealer(secret string) (*Sealer, error) {
+	sum := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Sealer{aead: aead}, nil
+}
+
+// Seal cifra el contenido; el nonce aleatorio va al inicio del resultado
+func (s *Sealer) Seal(plain []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+// Open descifra lo que cifró Seal con el mismo secreto
+func (s *Sealer) Open(sealed []byte) ([]byte, error) {
+	size := s.aead.NonceSize()
+	if len(sealed) < size {
+		return nil, errSealedKey
+	}
+	plain, err := s.aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return nil, errSealedKey
+	}
+	return plain, nil
+}
+
+// GenerateKey crea una llave RSA de firma. Devuelve su kid, la llave pública en DER
+// (PKIX) y la privada en DER (PKCS #8) cifrada con el sealer.
+func GenerateKey(sealer *Sealer) (kid string, public, sealedPrivate []byte, err error) {
+	private, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if public, err = x509.MarshalPKIXPublicKey(&private.PublicKey); err != nil {
+		return "", nil, nil, err
+	}
+	plain, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if sealedPrivate, err = sealer.Seal(plain); err != nil {
+		return "", nil, nil, err
+	}
+	// El kid se deriva de la llave pública para que sea estable y no revele nada más
+	sum := sha256.Sum256(public)
+	return hex.EncodeToString(sum[:16]), public, sealedPrivate, nil
+}
+
+// OpenPrivateKey descifra la llave privada guardada
+func OpenPrivateKey(sealer *Sealer, sealed []byte) (*rsa.PrivateKey, error) {
+	plain, err := sealer.Open(sealed)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(plain)
+	if err != nil {
+		return nil, err
+	}
+	private, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("la llave privada no es RSA")
+	}
+	return private, nil
+}
+
+// ParsePublicKey lee la llave pública guardada
+func ParsePublicKey(der []byte) (*rsa.PublicKey, error) {
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	public, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("la llave pública no es RSA")
+	}
+	return public, nil
+}
+
+// Sign firma los claims con la llave indicada; typ va en el encabezado cuando no es vacío
+func Sign(claims jwt.Claims, typ, kid string, private *rsa.PrivateKey) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	if typ != "" {
+		token.Header["typ"] = typ
+	}
+	signed, err := token.SignedString(private)
+	if err != nil {
+		return "", fmt.Errorf("no se pudo firmar el token: %w", err)
+	}
+	return signed, nil
+}
+
+// Verify comprueba la firma, el typ del encabezado, el emisor y el vencimiento del token.
+// keyFor devuelve la llave pública del kid o false si no se conoce.
+func Verify(raw, typ, issuer string, claims jwt.Claims, keyFor func(kid string) (*rsa.PublicKey, bool)) error {
+	_, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		if header, _ := token.Header["typ"].(string); header != typ {
+			return nil, fmt.Errorf("el token no es de tipo %s", typ)
+		}
+		kid, _ := token.Header["kid"].(string)
+		public, ok := keyFor(kid)
+		if !ok {
+			return nil, fmt.Errorf("llave desconocida '%s'", kid)
+		}
+		return public, nil
+	}, jwt.WithValidMethods([]string{AlgRS256}), jwt.WithIssuer(issuer), jwt.WithExpirationRequired())
+	return err
+}
@@ -0,0 +1,154 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"slices"
+	"strings"
+)
+
+// Rutas de los endpoints del proveedor, relativas al emisor
+const (
+	DiscoveryPath = "/.well-known/openid-configuration"
+	JWKSPath      = "/.well-known/jwks.json"
+	AuthorizePath = "/oauth2/authorize"
+	TokenPath     = "/oauth2/token"
+	UserInfoPath  = "/oauth2/userinfo"
+)
+
+// Scopes que entiende el proveedor; cada uno agrega los claims de OIDC Core, sección 5.4
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+	ScopePhone   = "phone"
+)
+
+// SupportedScopes lista los scopes en el orden en que se publican en el discovery
+var SupportedScopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail, ScopePhone}
+
+// Códigos de error de OAuth 2.0 (RFC 6749, secciones 4.1.2.1 y 5.2), de OpenID Connect
+// (OIDC Core, sección 3.1.2.6) y de los tokens Bearer (RFC 6750, sección 3.1)
+const (
+	ErrorInvalidRequest          = "invalid_request"
+	ErrorInvalidClient           = "invalid_client"
+	ErrorInvalidGrant            = "invalid_grant"
+	ErrorInvalidScope            = "invalid_scope"
+	ErrorUnsupportedGrantType    = "unsupported_grant_type"
+	ErrorUnsupportedResponseType = "unsupported_response_type"
+	ErrorLoginRequired           = "login_required"
+	ErrorInvalidToken            = "invalid_token"
+)
+
+// Métodos de PKCE (RFC 7636); solo se acepta S256
+const (
+	PKCEMethodS256 = "S256"
+	// ResponseTypeCode es el único response_type que se acepta: el flujo del código de autorización
+	ResponseTypeCode = "code"
+	// GrantTypeAuthorizationCode es el único grant_type que acepta el endpoint de tokens
+	GrantTypeAuthorizationCode = "authorization_code"
+	// TypeAccessToken es el typ del encabezado de los tokens de acceso (RFC 9068), que
+	// impide usar un ID token en su lugar
+	TypeAccessToken = "at+jwt"
+)
+
+// Error es un error de OAuth 2.0 que se devuelve tal cual al cliente. Description va en
+// inglés porque error_description solo admite ASCII (RFC 6749, sección 5.2).
+type Error struct {
+	Code        string
+	Description string
+}
+
+func NewError(code, description string) *Error {
+	return &Error{Code: code, Description: description}
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+const (
+	secretBytes = 32
+	idBytes     = 12
+)
+
+// NewID genera un identificador aleatorio para el client_id de un cliente o el jti de un token
+func NewID() (string, error) {
+	id := make([]byte, idBytes)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// NewSecret genera un secreto de cliente o un código de autorización de 256 bits.
+// Devuelve el valor, que solo recibe el cliente, y el hash que se guarda.
+func NewSecret() (raw, hash string, err error) {
+	value := make([]byte, secretBytes)
+	if _, err := rand.Read(value); err != nil {
+		return "", "", err
+	}
+	raw = base64.RawURLEncoding.EncodeToString(value)
+	return raw, Hash(raw), nil
+}
+
+// Hash calcula el hash con el que se guarda un secreto o un código. Son valores
+// aleatorios de 256 bits, por lo que basta SHA-256.
+func Hash(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// Matches compara el secreto con el hash guardado en tiempo constante
+func Matches(raw, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(Hash(raw)), []byte(hash)) == 1
+}
+
+// ParseScope separa los scopes pedidos, sin repetidos, y descarta los que el proveedor no
+// conoce (RFC 6749, sección 3.3)
+func ParseScope(scope string) []string {
+	var scopes []string
+	for _, value := range strings.Fields(scope) {
+		if slices.Contains(SupportedScopes, value) && !slices.Contains(scopes, value) {
+			scopes = append(scopes, value)
+		}
+	}
+	return scopes
+}
+
+// ValidChallenge indica si el code_challenge tiene la forma de un hash S256: 32 bytes en
+// base64url sin relleno
+func ValidChallenge(challenge string) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(challenge)
+	return err == nil && len(decoded) == sha256.Size
+}
+
+// VerifyPKCE comprueba que el code_verifier corresponde al code_challenge S256. El
+// verifier debe tener entre 43 y 128 caracteres no reservados (RFC 7636, sección 4.1).
+func VerifyPKCE(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	for _, r := range verifier {
+		if !isUnreserved(r) {
+			return false
+		}
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// AccessTokenHash calcula el claim at_hash del ID token con RS256: la mitad izquierda del
+// SHA-256 del token de acceso (OIDC Core, sección 3.1.3.6)
+func AccessTokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2])
+}
+
+func isUnreserved(r rune) bool {
+	return r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || strings.ContainsRune("-._~", r)
+}
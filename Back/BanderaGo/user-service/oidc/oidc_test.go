@@ -0,0 +1,65 @@
+package oidc
+
+import (
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: el verifier del ejemplo de RFC 7636 (apéndice B) corresponde a su challenge
+func TestVerifyPKCE(t *testing.T) {
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	assert.True(t, ValidChallenge(challenge))
+	assert.True(t, VerifyPKCE(verifier, challenge))
+	assert.False(t, VerifyPKCE(verifier+"x", challenge))
+	assert.False(t, VerifyPKCE("corto", challenge))
+	assert.False(t, VerifyPKCE(verifier[:42]+"!", challenge))
+	assert.False(t, ValidChallenge("plain-no-es-un-hash"))
+}
+
+// Caso de prueba: se ignoran los scopes desconocidos y los repetidos, y at_hash coincide con el de la especificación
+func TestParseScope(t *testing.T) {
+	assert.Equal(t, []string{"openid", "email"}, ParseScope(" openid  email offline_access openid "))
+	assert.Empty(t, ParseScope(""))
+	// Ejemplo del apéndice A.3 de OIDC Core
+	assert.Equal(t, "77QmUPtjPfzWtF2AnpK9RQ", AccessTokenHash("jHkWEdUXMU1BwAsC4vtUsZwnNvTIxEl0z9K3vx5KF0Y"))
+}
+
+// Caso de prueba: la llave guardada se descifra solo con el mismo secreto y firma tokens que verifica su parte pública
+func TestSigningKeys(t *testing.T) {
+	sealer, err := NewSealer("0123456789abcdef0123456789abcdef")
+	require.NoError(t, err)
+	kid, publicDER, sealed, err := GenerateKey(sealer)
+	require.NoError(t, err)
+	assert.Len(t, kid, 32)
+
+	other, err := NewSealer("otro secreto de al menos 32 bytes!!")
+	require.NoError(t, err)
+	_, err = OpenPrivateKey(other, sealed)
+	assert.Error(t, err)
+
+	private, err := OpenPrivateKey(sealer, sealed)
+	require.NoError(t, err)
+	public, err := ParsePublicKey(publicDER)
+	require.NoError(t, err)
+	assert.True(t, public.Equal(&private.PublicKey))
+
+	keyFor := func(id string) (*rsa.PublicKey, bool) { return public, id == kid }
+	claims := jwt.RegisteredClaims{Issuer: "https://sso.example.com", Subject: "1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}
+	raw, err := Sign(claims, TypeAccessToken, kid, private)
+	require.NoError(t, err)
+
+	assert.NoError(t, Verify(raw, TypeAccessToken, "https://sso.example.com", &jwt.RegisteredClaims{}, keyFor))
+	assert.Error(t, Verify(raw, "", "https://sso.example.com", &jwt.RegisteredClaims{}, keyFor), "otro typ")
+	assert.Error(t, Verify(raw, TypeAccessToken, "https://otro.example.com", &jwt.RegisteredClaims{}, keyFor), "otro emisor")
+
+	idToken, err := Sign(claims, "", "desconocida", private)
+	require.NoError(t, err)
+	assert.Error(t, Verify(idToken, "", "https://sso.example.com", &jwt.RegisteredClaims{}, keyFor), "otra llave")
+}
@@ -0,0 +1,143 @@
+package passwords
+
+import (
+	"application/config"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+var ErrInvalidHash = errors.New("el hash de la contraseña no tiene un formato reconocido")
+
+// Hasher calcula y verifica los hashes de las contraseñas. Los hashes se guardan en
+// formato PHC (argon2id) o modular crypt (bcrypt), por lo que incluyen el algoritmo y
+// sus parámetros y se pueden verificar aunque la configuración cambie.
+type Hasher struct {
+	config config.PasswordConfig
+	// dummy se verifica cuando el usuario no existe para que la respuesta tarde lo mismo
+	dummy string
+}
+
+func NewHasher(passwordConfig config.PasswordConfig) *Hasher {
+	hasher := &Hasher{config: withDefaults(passwordConfig)}
+	hasher.dummy, _ = hasher.Hash("dummy password")
+	return hasher
+}
+
+func withDefaults(passwordConfig config.PasswordConfig) config.PasswordConfig {
+	if passwordConfig.Algorithm == "" {
+		passwordConfig.Algorithm = config.DefaultPasswordAlgorithm
+	}
+	if passwordConfig.MinLength == 0 {
+		passwordConfig.MinLength = config.DefaultPasswordMinLength
+	}
+	if passwordConfig.MaxLength == 0 {
+		passwordConfig.MaxLength = config.DefaultPasswordMaxLength
+	}
+	if passwordConfig.Argon2Memory == 0 {
+		passwordConfig.Argon2Memory = config.DefaultArgon2Memory
+	}
+	if passwordConfig.Argon2Iterations == 0 {
+		passwordConfig.Argon2Iterations = config.DefaultArgon2Iterations
+	}
+	if passwordConfig.Argon2Parallelism == 0 {
+		passwordConfig.Argon2Parallelism = config.DefaultArgon2Parallelism
+	}
+	if passwordConfig.BcryptCost == 0 {
+		passwordConfig.BcryptCost = config.DefaultBcryptCost
+	}
+	return passwordConfig
+}
+
+// Hash calcula el hash de la contraseña con el algoritmo y los parámetros configurados.
+// La contraseña se normaliza a NFKC para que la misma contraseña escrita con otra
+// composición de caracteres produzca el mismo hash.
+func (h *Hasher) Hash(password string) (string, error) {
+	password = norm.NFKC.String(password)
+	if h.config.Algorithm == config.PasswordBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.config.BcryptCost)
+		return string(hash), err
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.config.Argon2Iterations, h.config.Argon2Memory, h.config.Argon2Parallelism, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+		h.config.Argon2Memory, h.config.Argon2Iterations, h.config.Argon2Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify compara la contraseña con el hash guardado. rehash indica que la contraseña es
+// correcta pero el hash se calculó con otro algoritmo o con otros parámetros, por lo que
+// conviene volver a calcularlo.
+func (h *Hasher) Verify(password, encoded string) (match, rehash bool, err error) {
+	password = norm.NFKC.String(password)
+	if strings.HasPrefix(encoded, "$argon2id$") {
+		return h.verifyArgon2id(password, encoded)
+	}
+
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return false, false, ErrInvalidHash
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) || errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return true, h.config.Algorithm != config.PasswordBcrypt || cost != h.config.BcryptCost, nil
+}
+
+// VerifyDummy consume el mismo tiempo que una verificación real
+func (h *Hasher) VerifyDummy(password string) {
+	_, _, _ = h.Verify(password, h.dummy)
+}
+
+func (h *Hasher) verifyArgon2id(password, encoded string) (match, rehash bool, err error) {
+	var version int
+	var memory, iterations uint32
+	var parallelism uint8
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return false, false, ErrInvalidHash
+	}
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false, ErrInvalidHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism); err != nil {
+		return false, false, ErrInvalidHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, ErrInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false, false, ErrInvalidHash
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, iterations, memory, parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return false, false, nil
+	}
+	rehash = h.config.Algorithm != config.PasswordArgon2id ||
+		memory != h.config.Argon2Memory || iterations != h.config.Argon2Iterations ||
+		parallelism != h.config.Argon2Parallelism || len(key) != argon2KeyLength
+	return true, rehash, nil
+}
@@ -0,0 +1,86 @@
+package passwords
+
+import (
+	"application/config"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testArgon2 = config.PasswordConfig{Algorithm: config.PasswordArgon2id, Argon2Memory: 1024, Argon2Iterations: 1, Argon2Parallelism: 1}
+
+// Caso de prueba: el hash incluye el algoritmo y sus parámetros y se verifica con cada algoritmo
+func TestHashAndVerify(t *testing.T) {
+	for _, passwordConfig := range []config.PasswordConfig{testArgon2, {Algorithm: config.PasswordBcrypt, BcryptCost: 4}} {
+		hasher := NewHasher(passwordConfig)
+
+		hash, err := hasher.Hash("correct horse")
+		require.NoError(t, err)
+		assert.NotContains(t, hash, "correct horse")
+		if passwordConfig.Algorithm == config.PasswordArgon2id {
+			assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"), hash)
+		} else {
+			assert.True(t, strings.HasPrefix(hash, "$2a$04$"), hash)
+		}
+
+		// Cada hash usa su propia sal
+		other, err := hasher.Hash("correct horse")
+		require.NoError(t, err)
+		assert.NotEqual(t, hash, other)
+
+		match, rehash, err := hasher.Verify("correct horse", hash)
+		require.NoError(t, err)
+		assert.True(t, match)
+		assert.False(t, rehash)
+
+		match, _, err = hasher.Verify("wrong horse", hash)
+		require.NoError(t, err)
+		assert.False(t, match)
+	}
+}
+
+// Caso de prueba: un hash con otros parámetros o con otro algoritmo pide calcularse de nuevo
+func TestVerifyRehash(t *testing.T) {
+	old := NewHasher(testArgon2)
+	hash, err := old.Hash("correct horse")
+	require.NoError(t, err)
+
+	stronger := testArgon2
+	stronger.Argon2Iterations = 2
+	match, rehash, err := NewHasher(stronger).Verify("correct horse", hash)
+	require.NoError(t, err)
+	assert.True(t, match)
+	assert.True(t, rehash)
+
+	bcryptHasher := NewHasher(config.PasswordConfig{Algorithm: config.PasswordBcrypt, BcryptCost: 4})
+	match, rehash, err = bcryptHasher.Verify("correct horse", hash)
+	require.NoError(t, err)
+	assert.True(t, match)
+	assert.True(t, rehash)
+
+	// Una contraseña incorrecta nunca pide rehash
+	_, rehash, _ = NewHasher(stronger).Verify("wrong horse", hash)
+	assert.False(t, rehash)
+}
+
+// Caso de prueba: la misma contraseña con otra composición Unicode coincide
+func TestVerifyNormalizesPassword(t *testing.T) {
+	hasher := NewHasher(testArgon2)
+	hash, err := hasher.Hash("contrase\u00f1a segura")
+	require.NoError(t, err)
+
+	match, _, err := hasher.Verify("contraseña segura", hash)
+	require.NoError(t, err)
+	assert.True(t, match)
+}
+
+// Caso de prueba: hashes con un formato desconocido
+func TestVerifyInvalidHash(t *testing.T) {
+	hasher := NewHasher(testArgon2)
+	for _, hash := range []string{"", "plain", "$argon2id$v=19$m=1024$salt$key", "$argon2id$v=18$m=1024,t=1,p=1$c2FsdA$a2V5"} {
+		_, _, err := hasher.Verify("password", hash)
+		assert.ErrorIs(t, err, ErrInvalidHash, hash)
+	}
+}
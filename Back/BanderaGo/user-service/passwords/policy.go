@@ -0,0 +1,69 @@
+package passwords
+
+import (
+	"application/apperrors"
+	"application/config"
+	"bufio"
+	_ "embed"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// breachedList contiene contraseñas comunes o filtradas, una por línea y en minúsculas
+//
+//go:embed breached.txt
+var breachedList string
+
+var breached = loadBreached(breachedList)
+
+func loadBreached(list string) map[string]struct{} {
+	passwords := make(map[string]struct{})
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			passwords[line] = struct{}{}
+		}
+	}
+	return passwords
+}
+
+// Policy revisa que las contraseñas nuevas cumplan la longitud configurada y no estén
+// en la lista de contraseñas filtradas
+type Policy struct {
+	config config.PasswordConfig
+}
+
+func NewPolicy(passwordConfig config.PasswordConfig) Policy {
+	return Policy{config: withDefaults(passwordConfig)}
+}
+
+// Check devuelve las reglas que incumple la contraseña, reportadas con el nombre de campo
+// indicado. La longitud se cuenta en caracteres.
+func (p Policy) Check(field, password string) apperrors.Violations {
+	password = norm.NFKC.String(password)
+	var violations apperrors.Violations
+	length := utf8.RuneCountInString(password)
+	if length < p.config.MinLength {
+		violations = append(violations, apperrors.Violation{Field: field, Rule: "min", Param: strconv.Itoa(p.config.MinLength)})
+	}
+	if length > p.config.MaxLength {
+		violations = append(violations, apperrors.Violation{Field: field, Rule: "max", Param: strconv.Itoa(p.config.MaxLength)})
+	}
+	// bcrypt ignora lo que sigue a los primeros 72 bytes
+	if p.config.Algorithm == config.PasswordBcrypt && len(password) > 72 {
+		violations = append(violations, apperrors.Violation{Field: field, Rule: "max_bytes", Param: "72"})
+	}
+	if p.config.CheckBreached && IsBreached(password) {
+		violations = append(violations, apperrors.Violation{Field: field, Rule: "breached"})
+	}
+	return violations
+}
+
+// IsBreached indica si la contraseña aparece en la lista embebida, sin distinguir mayúsculas
+func IsBreached(password string) bool {
+	_, ok := breached[strings.ToLower(password)]
+	return ok
+}
@@ -0,0 +1,36 @@
+package passwords
+
+import (
+	"application/apperrors"
+	"application/config"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Caso de prueba: longitud en caracteres y lista de contraseñas filtradas
+func TestPolicyCheck(t *testing.T) {
+	policy := NewPolicy(config.PasswordConfig{MinLength: 10, MaxLength: 20, CheckBreached: true})
+
+	assert.Empty(t, policy.Check("password", "ñandú veloz 42"))
+	assert.Equal(t, apperrors.Violations{{Field: "password", Rule: "min", Param: "10"}}, policy.Check("password", "corta"))
+	assert.Equal(t, apperrors.Violations{{Field: "new_password", Rule: "max", Param: "20"}}, policy.Check("new_password", strings.Repeat("ñ", 21)))
+	assert.Equal(t, apperrors.Violations{{Field: "password", Rule: "breached"}}, policy.Check("password", "Password1234"))
+	assert.Equal(t, apperrors.Violations{
+		{Field: "password", Rule: "min", Param: "10"},
+		{Field: "password", Rule: "breached"},
+	}, policy.Check("password", "qwerty"))
+
+	// Sin la revisión de contraseñas filtradas solo se revisa la longitud
+	policy = NewPolicy(config.PasswordConfig{MinLength: 10, MaxLength: 20})
+	assert.Empty(t, policy.Check("password", "password1234"))
+}
+
+// Caso de prueba: con bcrypt la contraseña no puede ocupar más de 72 bytes
+func TestPolicyCheckBcryptBytes(t *testing.T) {
+	policy := NewPolicy(config.PasswordConfig{Algorithm: config.PasswordBcrypt, MinLength: 8, MaxLength: 72})
+
+	assert.Empty(t, policy.Check("password", strings.Repeat("a", 72)))
+	assert.Equal(t, apperrors.Violations{{Field: "password", Rule: "max_bytes", Param: "72"}}, policy.Check("password", strings.Repeat("ñ", 40)))
+}
@@ -0,0 +1,39 @@
+package contexts
+
+import (
+	"application/config"
+	"net/url"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type PostgresDB struct {
+	UserConfig *config.UserConfig
+	DB         *gorm.DB
+}
+
+func NewPostgresDB(userConfig *config.UserConfig) (*PostgresDB, error) {
+	db, err := gorm.Open(postgres.Open(postgresDSN(userConfig)), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresDB{
+		UserConfig: userConfig,
+		DB:         db,
+	}, nil
+}
+
+// postgresDSN arma la URL de conexión. DB_PORT tiene el formato host:puerto, igual que
+// para MySQL, y las credenciales se escapan para admitir caracteres especiales.
+func postgresDSN(userConfig *config.UserConfig) string {
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(userConfig.DBUser, userConfig.DBPassword),
+		Host:     userConfig.DBPort,
+		Path:     "/" + userConfig.DBName,
+		RawQuery: url.Values{"sslmode": {userConfig.DBSSLMode}}.Encode(),
+	}
+	return dsn.String()
+}
@@ -0,0 +1,39 @@
+package contexts
+
+import (
+	"application/config"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+type SQLiteDB struct {
+	UserConfig *config.UserConfig
+	DB         *gorm.DB
+}
+
+// NewSQLiteDB abre la base SQLite de DB_PATH (un archivo o ":memory:") con un driver sin cgo
+func NewSQLiteDB(userConfig *config.UserConfig) (*SQLiteDB, error) {
+	db, err := gorm.Open(sqlite.Open(userConfig.DBPath), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Una sola conexión evita errores de bloqueo entre escritores y hace que ":memory:"
+	// sea la misma base para todas las consultas
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	// SQLite no aplica las llaves foráneas si no se activan en la conexión
+	if err := db.Exec("PRAGMA foreign_keys = ON").Error; err != nil {
+		return nil, err
+	}
+
+	return &SQLiteDB{
+		UserConfig: userConfig,
+		DB:         db,
+	}, nil
+}
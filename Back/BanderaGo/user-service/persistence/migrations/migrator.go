@@ -0,0 +1,395 @@
+package migrations
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tabla donde se registran las migraciones aplicadas
+const migrationsTable = "schema_migrations"
+
+// Tiempo máximo de espera por el bloqueo de migraciones en MySQL
+const lockTimeout = 60 * time.Second
+
+//go:embed sql
+var files embed.FS
+
+var (
+	filePattern       = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// MySQL y SQLite no admiten IF NOT EXISTS al agregar una columna; el migrador lo resuelve
+	addColumnPattern = regexp.MustCompile("(?is)^(?:--[^\\n]*\\s*)*ALTER TABLE [`\"]?(\\w+)[`\"]? ADD COLUMN IF NOT EXISTS (\\w+) (.+)$")
+)
+
+// Migration es un cambio de esquema con su SQL de aplicación y de reversión
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status indica si una migración está aplicada y desde cuándo
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrator aplica y revierte las migraciones embebidas del motor de la conexión.
+// Todas las operaciones se hacen en una sola conexión que mantiene un bloqueo consultivo,
+// de modo que varias instancias que arrancan a la vez no migran en paralelo.
+type Migrator struct {
+	db         *sql.DB
+	dialect    string
+	migrations []Migration
+}
+
+// NewMigrator carga las migraciones del motor de db para la tabla de usuarios indicada
+func NewMigrator(db *gorm.DB, table string) (*Migrator, error) {
+	dialect := db.Dialector.Name()
+	migrations, err := Load(dialect, table)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: sqlDB, dialect: dialect, migrations: migrations}, nil
+}
+
+// Load lee las migraciones del motor y reemplaza el nombre de la tabla en el SQL
+func Load(dialect, table string) ([]Migration, error) {
+	if !identifierPattern.MatchString(table) {
+		return nil, fmt.Errorf("el nombre de tabla '%s' no es un identificador válido", table)
+	}
+	dir := path.Join("sql", dialect)
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no hay migraciones para el motor '%s'", dialect)
+	}
+
+	funcs := template.FuncMap{"ident": func(name string) string { return quote(dialect, name) }}
+	data := struct{ Name, Table string }{Name: table, Table: quote(dialect, table)}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		matches := filePattern.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("nombre de migración inválido: %s", entry.Name())
+		}
+		version, err := strconv.ParseUint(matches[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := fs.ReadFile(files, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		tmpl, err := template.New(entry.Name()).Funcs(funcs).Parse(string(content))
+		if err != nil {
+			return nil, err
+		}
+		var rendered strings.Builder
+		if err := tmpl.Execute(&rendered, data); err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[uint(version)]
+		if !ok {
+			migration = &Migration{Version: uint(version), Name: matches[2]}
+			byVersion[uint(version)] = migration
+		}
+		if migration.Name != matches[2] {
+			return nil, fmt.Errorf("la migración %d tiene nombres distintos: %s y %s", version, migration.Name, matches[2])
+		}
+		if matches[3] == "up" {
+			migration.Up = rendered.String()
+		} else {
+			migration.Down = rendered.String()
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("la migración %d_%s debe tener archivos up y down", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+	return migrations, nil
+}
+
+// Latest devuelve la versión de la última migración disponible
+func (m *Migrator) Latest() uint {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up aplica todas las migraciones pendientes y devuelve las aplicadas
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.To(ctx, m.Latest())
+}
+
+// Down revierte la última migración aplicada y la devuelve
+func (m *Migrator) Down(ctx context.Context) ([]Migration, error) {
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			if _, ok := applied[m.migrations[i].Version]; ok {
+				if err := m.revert(ctx, conn, m.migrations[i]); err != nil {
+					return err
+				}
+				reverted = append(reverted, m.migrations[i])
+				return nil
+			}
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// To aplica o revierte migraciones hasta que la versión indicada sea la última aplicada.
+// Con la versión 0 se revierten todas.
+func (m *Migrator) To(ctx context.Context, version uint) ([]Migration, error) {
+	if version != 0 && !slices.ContainsFunc(m.migrations, func(migration Migration) bool { return migration.Version == version }) {
+		return nil, fmt.Errorf("no existe la migración %d", version)
+	}
+	var changed []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		changed, err = m.migrate(ctx, conn, applied, version)
+		return err
+	})
+	return changed, err
+}
+
+// Status devuelve todas las migraciones disponibles indicando cuáles están aplicadas
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			status := Status{Migration: migration}
+			if appliedAt, ok := applied[migration.Version]; ok {
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// migrate revierte las migraciones aplicadas posteriores a target, de la más reciente a
+// la más antigua, y luego aplica las pendientes hasta target en orden
+func (m *Migrator) migrate(ctx context.Context, conn *sql.Conn, applied map[uint]time.Time, target uint) ([]Migration, error) {
+	var changed []Migration
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok || migration.Version <= target {
+			continue
+		}
+		if err := m.revert(ctx, conn, migration); err != nil {
+			return changed, err
+		}
+		changed = append(changed, migration)
+	}
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok || migration.Version > target {
+			continue
+		}
+		err := m.exec(ctx, conn, migration.Up, "INSERT INTO "+migrationsTable+" (version, name, applied_at) VALUES (?, ?, ?)", migration.Version, migration.Name, time.Now().UTC())
+		if err != nil {
+			return changed, fmt.Errorf("aplicar la migración %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		changed = append(changed, migration)
+	}
+	return changed, nil
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	err := m.exec(ctx, conn, migration.Down, "DELETE FROM "+migrationsTable+" WHERE version = ?", migration.Version)
+	if err != nil {
+		return fmt.Errorf("revertir la migración %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// exec ejecuta el SQL de una migración y actualiza schema_migrations en una transacción.
+// MySQL confirma implícitamente las sentencias DDL, por lo que ahí una migración que falla
+// a la mitad puede quedar aplicada parcialmente.
+func (m *Migrator) exec(ctx context.Context, conn *sql.Conn, script, record string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range splitStatements(script) {
+		if err := m.execStatement(ctx, tx, statement); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, m.bind(record), args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// execStatement ejecuta una sentencia de una migración. En MySQL y SQLite un
+// "ALTER TABLE t ADD COLUMN IF NOT EXISTS c ..." se omite si la columna ya existe.
+func (m *Migrator) execStatement(ctx context.Context, tx *sql.Tx, statement string) error {
+	matches := addColumnPattern.FindStringSubmatch(statement)
+	if matches == nil || m.dialect == "postgres" {
+		_, err := tx.ExecContext(ctx, statement)
+		return err
+	}
+	table, column := matches[1], matches[2]
+	query := "SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?"
+	if m.dialect == "sqlite" {
+		query = "SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?"
+	}
+	var count int
+	if err := tx.QueryRowContext(ctx, query, table, column).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, "ALTER TABLE "+quote(m.dialect, table)+" ADD COLUMN "+column+" "+matches[3])
+	return err
+}
+
+// applied crea la tabla de control si no existe y devuelve las versiones aplicadas
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[uint]time.Time, error) {
+	_, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+migrationsTable+" ("+
+		"version BIGINT NOT NULL PRIMARY KEY, "+
+		"name VARCHAR(255) NOT NULL, "+
+		"applied_at TIMESTAMP NOT NULL)")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM "+migrationsTable)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[uint]time.Time)
+	for rows.Next() {
+		var version uint
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// withLock ejecuta fn en una conexión dedicada mientras se mantiene el bloqueo consultivo
+// del motor: GET_LOCK en MySQL y pg_advisory_lock en PostgreSQL. SQLite no tiene bloqueos
+// consultivos; su conexión única y el bloqueo del archivo serializan las escrituras.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	switch m.dialect {
+	case "mysql":
+		var acquired sql.NullInt64
+		err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationsTable, int(lockTimeout.Seconds())).Scan(&acquired)
+		if err != nil {
+			return err
+		}
+		if acquired.Int64 != 1 {
+			return errors.New("no se obtuvo el bloqueo de migraciones: otra instancia está migrando")
+		}
+		defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", migrationsTable)
+	case "postgres":
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey()); err != nil {
+			return err
+		}
+		defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey())
+	}
+
+	return fn(conn)
+}
+
+// lockKey es la llave numérica del bloqueo consultivo de PostgreSQL
+func lockKey() int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(migrationsTable))
+	return int64(hash.Sum64())
+}
+
+// bind adapta los marcadores "?" al formato posicional de PostgreSQL
+func (m *Migrator) bind(query string) string {
+	if m.dialect != "postgres" {
+		return query
+	}
+	var bound strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			bound.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		bound.WriteRune(r)
+	}
+	return bound.String()
+}
+
+// quote escribe un identificador con las comillas del motor
+func quote(dialect, name string) string {
+	if dialect == "mysql" {
+		return "`" + name + "`"
+	}
+	return `"` + name + `"`
+}
+
+// splitStatements separa un archivo en sentencias, ya que MySQL no acepta varias en una
+// misma ejecución. Las migraciones no deben usar ";" dentro de literales.
+func splitStatements(script string) []string {
+	var statements []string
+	for _, statement := range strings.Split(script, ";") {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
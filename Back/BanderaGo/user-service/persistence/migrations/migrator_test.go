@@ -0,0 +1,178 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestMigrator(t *testing.T) (*Migrator, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrator, err := NewMigrator(db, "users")
+	require.NoError(t, err)
+	return migrator, db
+}
+
+// Caso de prueba: todos los motores tienen las mismas migraciones con up y down
+func TestLoad(t *testing.T) {
+	var versions []uint
+	for _, dialect := range []string{"mysql", "postgres", "sqlite"} {
+		migrations, err := Load(dialect, "users")
+		require.NoError(t, err, dialect)
+		require.NotEmpty(t, migrations, dialect)
+
+		var dialectVersions []uint
+		for _, migration := range migrations {
+			dialectVersions = append(dialectVersions, migration.Version)
+			assert.NotContains(t, migration.Up, "{{", dialect)
+		}
+		if versions == nil {
+			versions = dialectVersions
+		}
+		assert.Equal(t, versions, dialectVersions, dialect)
+	}
+
+	migrations, err := Load("mysql", "users")
+	require.NoError(t, err)
+	assert.Contains(t, migrations[0].Up, "CREATE TABLE IF NOT EXISTS `users`")
+	statements := splitStatements(migrations[0].Up)
+	assert.Equal(t, []string{"users", "version"}, addColumnPattern.FindStringSubmatch(statements[len(statements)-1])[1:3])
+}
+
+// Caso de prueba: se rechazan nombres de tabla que no son identificadores y motores sin migraciones
+func TestLoadInvalid(t *testing.T) {
+	_, err := Load("mysql", "users; DROP TABLE x")
+	assert.Error(t, err)
+	_, err = Load("mysql", "")
+	assert.Error(t, err)
+	_, err = Load("oracle", "users")
+	assert.Error(t, err)
+}
+
+// Caso de prueba: aplicar, consultar y revertir migraciones
+func TestMigratorUpDownStatus(t *testing.T) {
+	migrator, db := newTestMigrator(t)
+	ctx := context.Background()
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	for _, status := range statuses {
+		assert.Nil(t, status.AppliedAt)
+	}
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(statuses))
+	assert.True(t, db.Migrator().HasTable("users"))
+	assert.True(t, db.Migrator().HasColumn("users", "version"))
+
+	// Una segunda ejecución no tiene nada pendiente
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	statuses, err = migrator.Status(ctx)
+	require.NoError(t, err)
+	for _, status := range statuses {
+		assert.NotNil(t, status.AppliedAt)
+	}
+
+	reverted, err := migrator.Down(ctx)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	assert.Equal(t, migrator.Latest(), reverted[0].Version)
+}
+
+// Caso de prueba: migrar a una versión concreta y revertir todo con la versión 0
+func TestMigratorTo(t *testing.T) {
+	migrator, db := newTestMigrator(t)
+	ctx := context.Background()
+
+	_, err := migrator.To(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, db.Migrator().HasTable("users"))
+
+	_, err = migrator.To(ctx, 0)
+	require.NoError(t, err)
+	assert.False(t, db.Migrator().HasTable("users"))
+
+	_, err = migrator.To(ctx, 9999)
+	assert.Error(t, err)
+}
+
+// Caso de prueba: la tabla de versiones se llena con el estado actual de los usuarios existentes
+func TestMigratorBackfillUserVersions(t *testing.T) {
+	migrator, db := newTestMigrator(t)
+	ctx := context.Background()
+
+	_, err := migrator.To(ctx, 2)
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("INSERT INTO users (created_at, updated_at, name, last_name, version) VALUES (?, ?, 'Ana', 'Díaz', 2)", time.Now(), time.Now()).Error)
+	require.NoError(t, db.Exec("INSERT INTO users (created_at, updated_at, deleted_at, name, last_name, version) VALUES (?, ?, ?, 'Luis', 'Báez', 1)", time.Now(), time.Now(), time.Now()).Error)
+
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	var versions []struct {
+		UserID  uint
+		Version uint
+		Deleted bool
+	}
+	require.NoError(t, db.Raw("SELECT user_id, version, deleted_at IS NOT NULL AS deleted FROM users_versions ORDER BY user_id, id").Scan(&versions).Error)
+	require.Len(t, versions, 3)
+	assert.Equal(t, uint(2), versions[0].Version)
+	assert.False(t, versions[0].Deleted)
+	assert.False(t, versions[1].Deleted)
+	assert.True(t, versions[2].Deleted)
+
+	// La instantánea de la eliminación pasa a la versión siguiente, que es la del usuario,
+	// y la versión de cada usuario es única
+	assert.Equal(t, uint(1), versions[1].Version)
+	assert.Equal(t, uint(2), versions[2].Version)
+	var version uint
+	require.NoError(t, db.Raw("SELECT version FROM users WHERE name = 'Luis'").Scan(&version).Error)
+	assert.Equal(t, uint(2), version)
+	assert.Error(t, db.Exec("INSERT INTO users_versions (user_id, version, valid_from) VALUES (?, 2, ?)", versions[2].UserID, time.Now()).Error)
+}
+
+// Caso de prueba: una tabla creada por AutoMigrate sin la columna version se completa al migrar
+func TestMigratorLegacyTable(t *testing.T) {
+	migrator, db := newTestMigrator(t)
+	ctx := context.Background()
+
+	require.NoError(t, db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, created_at DATETIME, updated_at DATETIME, deleted_at DATETIME, name TEXT, last_name TEXT)").Error)
+	require.NoError(t, db.Exec("INSERT INTO users (created_at, updated_at, name, last_name) VALUES (?, ?, 'Ana', 'Díaz')", time.Now(), time.Now()).Error)
+
+	_, err := migrator.Up(ctx)
+	require.NoError(t, err)
+
+	var version uint
+	require.NoError(t, db.Raw("SELECT version FROM users WHERE name = 'Ana'").Scan(&version).Error)
+	assert.Equal(t, uint(1), version)
+
+	var snapshots int
+	require.NoError(t, db.Raw("SELECT COUNT(*) FROM users_versions").Scan(&snapshots).Error)
+	assert.Equal(t, 1, snapshots)
+}
+
+// Caso de prueba: marcadores posicionales de PostgreSQL y separación de sentencias
+func TestBindAndSplit(t *testing.T) {
+	postgres := &Migrator{dialect: "postgres"}
+	mysql := &Migrator{dialect: "mysql"}
+	assert.Equal(t, "INSERT INTO t VALUES ($1, $2)", postgres.bind("INSERT INTO t VALUES (?, ?)"))
+	assert.Equal(t, "INSERT INTO t VALUES (?, ?)", mysql.bind("INSERT INTO t VALUES (?, ?)"))
+
+	assert.Equal(t, []string{"CREATE TABLE a (id INT)", "CREATE INDEX i ON a (id)"}, splitStatements("CREATE TABLE a (id INT);\n\nCREATE INDEX i ON a (id);\n"))
+}
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS {{.Table}} (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    name VARCHAR(255),
+    last_name VARCHAR(255),
+    version BIGINT UNSIGNED NOT NULL DEFAULT 1,
+    PRIMARY KEY (id),
+    INDEX {{ident (printf "idx_%s_deleted_at" .Name)}} (deleted_at)
+);
+
+-- Las tablas que creó AutoMigrate antes de las migraciones no tienen la columna version
+ALTER TABLE {{.Table}} ADD COLUMN IF NOT EXISTS version BIGINT UNSIGNED NOT NULL DEFAULT 1;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_audit" .Name)}} (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id BIGINT UNSIGNED NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    request_id VARCHAR(128) NOT NULL,
+    operation VARCHAR(16) NOT NULL,
+    changes JSON NOT NULL,
+    created_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    INDEX {{ident (printf "idx_%s_audit_user_id" .Name)}} (user_id, id),
+    INDEX {{ident (printf "idx_%s_audit_created_at" .Name)}} (created_at)
+);
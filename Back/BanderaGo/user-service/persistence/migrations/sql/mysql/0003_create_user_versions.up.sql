@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_versions" .Name)}} (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id BIGINT UNSIGNED NOT NULL,
+    version BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(255),
+    last_name VARCHAR(255),
+    deleted_at DATETIME(3) NULL,
+    valid_from DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    INDEX {{ident (printf "idx_%s_versions_user_version" .Name)}} (user_id, version),
+    INDEX {{ident (printf "idx_%s_versions_user_valid_from" .Name)}} (user_id, valid_from)
+);
+
+INSERT INTO {{ident (printf "%s_versions" .Name)}} (user_id, version, name, last_name, deleted_at, valid_from)
+SELECT id, version, name, last_name, NULL, COALESCE(updated_at, created_at, CURRENT_TIMESTAMP(3)) FROM {{.Table}};
+
+INSERT INTO {{ident (printf "%s_versions" .Name)}} (user_id, version, name, last_name, deleted_at, valid_from)
+SELECT id, version, name, last_name, deleted_at, deleted_at FROM {{.Table}} WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,11 @@
+ALTER TABLE {{ident (printf "%s_versions" .Name)}}
+    DROP COLUMN email,
+    DROP COLUMN username,
+    DROP COLUMN phone,
+    DROP COLUMN display_name;
+
+ALTER TABLE {{.Table}}
+    DROP COLUMN email,
+    DROP COLUMN username,
+    DROP COLUMN phone,
+    DROP COLUMN display_name;
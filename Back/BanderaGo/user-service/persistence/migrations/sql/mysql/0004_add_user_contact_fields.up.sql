@@ -0,0 +1,16 @@
+-- Los índices únicos se nombran como su columna para que el error de llave duplicada
+-- identifique el campo en conflicto
+ALTER TABLE {{.Table}}
+    ADD COLUMN email VARCHAR(254) NULL,
+    ADD COLUMN username VARCHAR(64) NULL,
+    ADD COLUMN phone VARCHAR(16) NULL,
+    ADD COLUMN display_name VARCHAR(255) NULL,
+    ADD UNIQUE INDEX email (email),
+    ADD UNIQUE INDEX username (username),
+    ADD UNIQUE INDEX phone (phone);
+
+ALTER TABLE {{ident (printf "%s_versions" .Name)}}
+    ADD COLUMN email VARCHAR(254) NULL,
+    ADD COLUMN username VARCHAR(64) NULL,
+    ADD COLUMN phone VARCHAR(16) NULL,
+    ADD COLUMN display_name VARCHAR(255) NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE {{.Table}}
+    DROP COLUMN password_hash,
+    DROP COLUMN password_changed_at;
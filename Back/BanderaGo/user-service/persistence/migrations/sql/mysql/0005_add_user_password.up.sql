@@ -0,0 +1,3 @@
+ALTER TABLE {{.Table}}
+    ADD COLUMN password_hash VARCHAR(255) NULL,
+    ADD COLUMN password_changed_at DATETIME(3) NULL;
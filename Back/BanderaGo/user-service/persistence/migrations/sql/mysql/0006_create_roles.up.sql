@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_roles" .Name)}} (
+    name VARCHAR(64) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    permissions JSON NOT NULL,
+    created_at DATETIME(3) NOT NULL,
+    updated_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (name)
+);
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_user_roles" .Name)}} (
+    user_id BIGINT UNSIGNED NOT NULL,
+    role VARCHAR(64) NOT NULL,
+    created_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (user_id, role),
+    INDEX {{ident (printf "idx_%s_user_roles_role" .Name)}} (role),
+    CONSTRAINT {{ident (printf "fk_%s_user_roles_user" .Name)}} FOREIGN KEY (user_id) REFERENCES {{.Table}} (id) ON DELETE CASCADE,
+    CONSTRAINT {{ident (printf "fk_%s_user_roles_role" .Name)}} FOREIGN KEY (role) REFERENCES {{ident (printf "%s_roles" .Name)}} (name) ON DELETE CASCADE
+);
+INSERT IGNORE INTO {{ident (printf "%s_roles" .Name)}} (name, description, permissions, created_at, updated_at) VALUES
+    ('admin', 'Administración completa de usuarios y roles', '["users:read","users:create","users:update","users:delete","users:password","audit:read","roles:manage"]', CURRENT_TIMESTAMP(3), CURRENT_TIMESTAMP(3)),
+    ('auditor', 'Consulta de usuarios y de la auditoría', '["users:read","audit:read"]', CURRENT_TIMESTAMP(3), CURRENT_TIMESTAMP(3));
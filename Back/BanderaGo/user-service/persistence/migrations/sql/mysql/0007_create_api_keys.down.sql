@@ -0,0 +1,3 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = JSON_REMOVE(permissions, JSON_UNQUOTE(JSON_SEARCH(permissions, 'one', 'apikeys:manage')))
+WHERE JSON_CONTAINS(permissions, '"apikeys:manage"');
+DROP TABLE IF EXISTS {{ident (printf "%s_api_keys" .Name)}};
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_api_keys" .Name)}} (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(32) NOT NULL,
+    hash CHAR(64) NOT NULL,
+    permissions JSON NOT NULL,
+    expires_at DATETIME(3) NULL,
+    revoked_at DATETIME(3) NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME(3) NOT NULL,
+    updated_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX {{ident (printf "idx_%s_api_keys_prefix" .Name)}} (prefix)
+);
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = JSON_ARRAY_APPEND(permissions, '$', 'apikeys:manage')
+WHERE name = 'admin' AND NOT JSON_CONTAINS(permissions, '"apikeys:manage"');
@@ -0,0 +1,4 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = JSON_REMOVE(permissions, JSON_UNQUOTE(JSON_SEARCH(permissions, 'one', 'sessions:manage')))
+WHERE JSON_CONTAINS(permissions, '"sessions:manage"');
+DROP TABLE IF EXISTS {{ident (printf "%s_refresh_tokens" .Name)}};
+DROP TABLE IF EXISTS {{ident (printf "%s_sessions" .Name)}};
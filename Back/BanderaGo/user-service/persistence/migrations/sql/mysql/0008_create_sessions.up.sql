@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_sessions" .Name)}} (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id BIGINT UNSIGNED NOT NULL,
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    created_at DATETIME(3) NOT NULL,
+    last_used_at DATETIME(3) NOT NULL,
+    expires_at DATETIME(3) NOT NULL,
+    revoked_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX {{ident (printf "idx_%s_sessions_user_id" .Name)}} (user_id),
+    CONSTRAINT {{ident (printf "fk_%s_sessions_user" .Name)}} FOREIGN KEY (user_id) REFERENCES {{.Table}} (id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_refresh_tokens" .Name)}} (
+    hash CHAR(64) NOT NULL,
+    session_id BIGINT UNSIGNED NOT NULL,
+    created_at DATETIME(3) NOT NULL,
+    used_at DATETIME(3) NULL,
+    PRIMARY KEY (hash),
+    INDEX {{ident (printf "idx_%s_refresh_tokens_session_id" .Name)}} (session_id),
+    CONSTRAINT {{ident (printf "fk_%s_refresh_tokens_session" .Name)}} FOREIGN KEY (session_id) REFERENCES {{ident (printf "%s_sessions" .Name)}} (id) ON DELETE CASCADE
+);
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = JSON_ARRAY_APPEND(permissions, '$', 'sessions:manage')
+WHERE name = 'admin' AND NOT JSON_CONTAINS(permissions, '"sessions:manage"');
@@ -0,0 +1,5 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = JSON_REMOVE(permissions, JSON_UNQUOTE(JSON_SEARCH(permissions, 'one', 'mfa:manage')))
+WHERE JSON_CONTAINS(permissions, '"mfa:manage"');
+ALTER TABLE {{ident (printf "%s_sessions" .Name)}} DROP COLUMN mfa;
+DROP TABLE IF EXISTS {{ident (printf "%s_recovery_codes" .Name)}};
+DROP TABLE IF EXISTS {{ident (printf "%s_mfa" .Name)}};
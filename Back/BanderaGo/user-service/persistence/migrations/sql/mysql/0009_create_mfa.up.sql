@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_mfa" .Name)}} (
+    user_id BIGINT UNSIGNED NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    last_step BIGINT NOT NULL DEFAULT 0,
+    confirmed_at DATETIME(3) NULL,
+    created_at DATETIME(3) NOT NULL,
+    updated_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (user_id),
+    CONSTRAINT {{ident (printf "fk_%s_mfa_user" .Name)}} FOREIGN KEY (user_id) REFERENCES {{.Table}} (id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_recovery_codes" .Name)}} (
+    hash CHAR(64) NOT NULL,
+    user_id BIGINT UNSIGNED NOT NULL,
+    used_at DATETIME(3) NULL,
+    created_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (hash),
+    INDEX {{ident (printf "idx_%s_recovery_codes_user_id" .Name)}} (user_id),
+    CONSTRAINT {{ident (printf "fk_%s_recovery_codes_user" .Name)}} FOREIGN KEY (user_id) REFERENCES {{.Table}} (id) ON DELETE CASCADE
+);
+ALTER TABLE {{ident (printf "%s_sessions" .Name)}} ADD COLUMN mfa BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = JSON_ARRAY_APPEND(permissions, '$', 'mfa:manage')
+WHERE name = 'admin' AND NOT JSON_CONTAINS(permissions, '"mfa:manage"');
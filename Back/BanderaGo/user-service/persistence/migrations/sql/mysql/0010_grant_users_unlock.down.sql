@@ -0,0 +1,2 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = JSON_REMOVE(permissions, JSON_UNQUOTE(JSON_SEARCH(permissions, 'one', 'users:unlock')))
+WHERE JSON_CONTAINS(permissions, '"users:unlock"');
@@ -0,0 +1,2 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = JSON_ARRAY_APPEND(permissions, '$', 'users:unlock')
+WHERE name = 'admin' AND NOT JSON_CONTAINS(permissions, '"users:unlock"');
@@ -0,0 +1,13 @@
+ALTER TABLE {{.Table}} ADD COLUMN email_verified_at DATETIME(3) NULL;
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_email_tokens" .Name)}} (
+    hash CHAR(64) NOT NULL,
+    user_id BIGINT UNSIGNED NOT NULL,
+    purpose VARCHAR(32) NOT NULL,
+    email VARCHAR(254) NOT NULL,
+    expires_at DATETIME(3) NOT NULL,
+    used_at DATETIME(3) NULL,
+    created_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (hash),
+    INDEX {{ident (printf "idx_%s_email_tokens_user_id" .Name)}} (user_id),
+    CONSTRAINT {{ident (printf "fk_%s_email_tokens_user" .Name)}} FOREIGN KEY (user_id) REFERENCES {{.Table}} (id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = JSON_REMOVE(permissions, JSON_UNQUOTE(JSON_SEARCH(permissions, 'one', 'oidc:manage')))
+WHERE JSON_CONTAINS(permissions, '"oidc:manage"');
+DROP TABLE IF EXISTS {{ident (printf "%s_oidc_keys" .Name)}};
+DROP TABLE IF EXISTS {{ident (printf "%s_oidc_codes" .Name)}};
+DROP TABLE IF EXISTS {{ident (printf "%s_oidc_clients" .Name)}};
@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_oidc_clients" .Name)}} (
+    id VARCHAR(64) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    secret_hash CHAR(64) NULL,
+    redirect_uris JSON NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME(3) NOT NULL,
+    updated_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_oidc_codes" .Name)}} (
+    hash CHAR(64) NOT NULL,
+    client_id VARCHAR(64) NOT NULL,
+    user_id BIGINT UNSIGNED NOT NULL,
+    redirect_uri TEXT NOT NULL,
+    scope TEXT NOT NULL,
+    nonce TEXT NOT NULL,
+    code_challenge VARCHAR(128) NOT NULL,
+    auth_time DATETIME(3) NOT NULL,
+    multi_factor BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at DATETIME(3) NOT NULL,
+    used_at DATETIME(3) NULL,
+    created_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (hash),
+    INDEX {{ident (printf "idx_%s_oidc_codes_client_id" .Name)}} (client_id),
+    INDEX {{ident (printf "idx_%s_oidc_codes_user_id" .Name)}} (user_id),
+    CONSTRAINT {{ident (printf "fk_%s_oidc_codes_client" .Name)}} FOREIGN KEY (client_id) REFERENCES {{ident (printf "%s_oidc_clients" .Name)}} (id) ON DELETE CASCADE,
+    CONSTRAINT {{ident (printf "fk_%s_oidc_codes_user" .Name)}} FOREIGN KEY (user_id) REFERENCES {{.Table}} (id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_oidc_keys" .Name)}} (
+    id VARCHAR(64) NOT NULL,
+    algorithm VARCHAR(16) NOT NULL,
+    public_key BLOB NOT NULL,
+    private_key BLOB NOT NULL,
+    created_at DATETIME(3) NOT NULL,
+    retired_at DATETIME(3) NULL,
+    PRIMARY KEY (id)
+);
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = JSON_ARRAY_APPEND(permissions, '$', 'oidc:manage')
+WHERE name = 'admin' AND NOT JSON_CONTAINS(permissions, '"oidc:manage"');
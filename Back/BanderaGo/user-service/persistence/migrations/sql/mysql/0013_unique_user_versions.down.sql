@@ -0,0 +1 @@
+ALTER TABLE {{ident (printf "%s_versions" .Name)}} DROP INDEX {{ident (printf "idx_%s_versions_user_version" .Name)}}, ADD INDEX {{ident (printf "idx_%s_versions_user_version" .Name)}} (user_id, version);
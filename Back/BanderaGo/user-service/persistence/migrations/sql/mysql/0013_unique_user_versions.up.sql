@@ -0,0 +1,13 @@
+UPDATE {{ident (printf "%s_versions" .Name)}} versions JOIN (
+    SELECT snapshot.id, COUNT(*) AS deletions FROM {{ident (printf "%s_versions" .Name)}} snapshot
+    JOIN {{ident (printf "%s_versions" .Name)}} deleted ON deleted.user_id = snapshot.user_id AND deleted.deleted_at IS NOT NULL AND deleted.id <= snapshot.id
+    GROUP BY snapshot.id
+) shifted ON shifted.id = versions.id
+SET versions.version = versions.version + shifted.deletions;
+
+UPDATE {{.Table}} users JOIN (
+    SELECT user_id, COUNT(*) AS deletions FROM {{ident (printf "%s_versions" .Name)}} WHERE deleted_at IS NOT NULL GROUP BY user_id
+) shifted ON shifted.user_id = users.id
+SET users.version = users.version + shifted.deletions;
+
+ALTER TABLE {{ident (printf "%s_versions" .Name)}} DROP INDEX {{ident (printf "idx_%s_versions_user_version" .Name)}}, ADD UNIQUE INDEX {{ident (printf "idx_%s_versions_user_version" .Name)}} (user_id, version);
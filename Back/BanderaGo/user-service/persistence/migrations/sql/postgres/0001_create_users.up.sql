@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS {{.Table}} (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    name VARCHAR(255),
+    last_name VARCHAR(255),
+    version BIGINT NOT NULL DEFAULT 1
+);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_deleted_at" .Name)}} ON {{.Table}} (deleted_at);
+
+-- Las tablas que creó AutoMigrate antes de las migraciones no tienen la columna version
+ALTER TABLE {{.Table}} ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
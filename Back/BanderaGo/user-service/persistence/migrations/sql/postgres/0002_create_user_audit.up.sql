@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_audit" .Name)}} (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    request_id VARCHAR(128) NOT NULL,
+    operation VARCHAR(16) NOT NULL,
+    changes JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_audit_user_id" .Name)}} ON {{ident (printf "%s_audit" .Name)}} (user_id, id);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_audit_created_at" .Name)}} ON {{ident (printf "%s_audit" .Name)}} (created_at);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_versions" .Name)}} (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    version BIGINT NOT NULL,
+    name VARCHAR(255),
+    last_name VARCHAR(255),
+    deleted_at TIMESTAMPTZ NULL,
+    valid_from TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_versions_user_version" .Name)}} ON {{ident (printf "%s_versions" .Name)}} (user_id, version);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_versions_user_valid_from" .Name)}} ON {{ident (printf "%s_versions" .Name)}} (user_id, valid_from);
+
+INSERT INTO {{ident (printf "%s_versions" .Name)}} (user_id, version, name, last_name, deleted_at, valid_from)
+SELECT id, version, name, last_name, NULL, COALESCE(updated_at, created_at, CURRENT_TIMESTAMP) FROM {{.Table}};
+
+INSERT INTO {{ident (printf "%s_versions" .Name)}} (user_id, version, name, last_name, deleted_at, valid_from)
+SELECT id, version, name, last_name, deleted_at, deleted_at FROM {{.Table}} WHERE deleted_at IS NOT NULL;
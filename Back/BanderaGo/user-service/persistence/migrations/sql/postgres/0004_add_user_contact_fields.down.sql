@@ -0,0 +1,11 @@
+ALTER TABLE {{ident (printf "%s_versions" .Name)}}
+    DROP COLUMN IF EXISTS email,
+    DROP COLUMN IF EXISTS username,
+    DROP COLUMN IF EXISTS phone,
+    DROP COLUMN IF EXISTS display_name;
+
+ALTER TABLE {{.Table}}
+    DROP COLUMN IF EXISTS email,
+    DROP COLUMN IF EXISTS username,
+    DROP COLUMN IF EXISTS phone,
+    DROP COLUMN IF EXISTS display_name;
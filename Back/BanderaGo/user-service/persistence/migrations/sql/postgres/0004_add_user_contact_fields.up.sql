@@ -0,0 +1,14 @@
+ALTER TABLE {{.Table}}
+    ADD COLUMN IF NOT EXISTS email VARCHAR(254) NULL,
+    ADD COLUMN IF NOT EXISTS username VARCHAR(64) NULL,
+    ADD COLUMN IF NOT EXISTS phone VARCHAR(16) NULL,
+    ADD COLUMN IF NOT EXISTS display_name VARCHAR(255) NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS {{ident (printf "idx_%s_email" .Name)}} ON {{.Table}} (email);
+CREATE UNIQUE INDEX IF NOT EXISTS {{ident (printf "idx_%s_username" .Name)}} ON {{.Table}} (username);
+CREATE UNIQUE INDEX IF NOT EXISTS {{ident (printf "idx_%s_phone" .Name)}} ON {{.Table}} (phone);
+
+ALTER TABLE {{ident (printf "%s_versions" .Name)}}
+    ADD COLUMN IF NOT EXISTS email VARCHAR(254) NULL,
+    ADD COLUMN IF NOT EXISTS username VARCHAR(64) NULL,
+    ADD COLUMN IF NOT EXISTS phone VARCHAR(16) NULL,
+    ADD COLUMN IF NOT EXISTS display_name VARCHAR(255) NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE {{.Table}}
+    DROP COLUMN IF EXISTS password_hash,
+    DROP COLUMN IF EXISTS password_changed_at;
@@ -0,0 +1,3 @@
+ALTER TABLE {{.Table}}
+    ADD COLUMN IF NOT EXISTS password_hash VARCHAR(255) NULL,
+    ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMPTZ NULL;
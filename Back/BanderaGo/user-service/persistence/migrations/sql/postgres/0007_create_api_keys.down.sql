@@ -0,0 +1,2 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = permissions - 'apikeys:manage';
+DROP TABLE IF EXISTS {{ident (printf "%s_api_keys" .Name)}};
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_api_keys" .Name)}} (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(32) NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    permissions JSONB NOT NULL,
+    expires_at TIMESTAMPTZ NULL,
+    revoked_at TIMESTAMPTZ NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS {{ident (printf "idx_%s_api_keys_prefix" .Name)}} ON {{ident (printf "%s_api_keys" .Name)}} (prefix);
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = permissions || '["apikeys:manage"]'::jsonb
+WHERE name = 'admin' AND NOT permissions @> '["apikeys:manage"]'::jsonb;
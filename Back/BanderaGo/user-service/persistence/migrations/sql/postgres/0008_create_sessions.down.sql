@@ -0,0 +1,3 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = permissions - 'sessions:manage';
+DROP TABLE IF EXISTS {{ident (printf "%s_refresh_tokens" .Name)}};
+DROP TABLE IF EXISTS {{ident (printf "%s_sessions" .Name)}};
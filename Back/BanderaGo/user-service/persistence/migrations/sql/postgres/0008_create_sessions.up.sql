@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_sessions" .Name)}} (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES {{.Table}} (id) ON DELETE CASCADE,
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_sessions_user_id" .Name)}} ON {{ident (printf "%s_sessions" .Name)}} (user_id);
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_refresh_tokens" .Name)}} (
+    hash VARCHAR(64) PRIMARY KEY,
+    session_id BIGINT NOT NULL REFERENCES {{ident (printf "%s_sessions" .Name)}} (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_refresh_tokens_session_id" .Name)}} ON {{ident (printf "%s_refresh_tokens" .Name)}} (session_id);
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = permissions || '["sessions:manage"]'::jsonb
+WHERE name = 'admin' AND NOT permissions @> '["sessions:manage"]'::jsonb;
@@ -0,0 +1,4 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = permissions - 'mfa:manage';
+ALTER TABLE {{ident (printf "%s_sessions" .Name)}} DROP COLUMN IF EXISTS mfa;
+DROP TABLE IF EXISTS {{ident (printf "%s_recovery_codes" .Name)}};
+DROP TABLE IF EXISTS {{ident (printf "%s_mfa" .Name)}};
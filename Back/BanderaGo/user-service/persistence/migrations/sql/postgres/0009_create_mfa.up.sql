@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_mfa" .Name)}} (
+    user_id BIGINT PRIMARY KEY REFERENCES {{.Table}} (id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    last_step BIGINT NOT NULL DEFAULT 0,
+    confirmed_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_recovery_codes" .Name)}} (
+    hash VARCHAR(64) PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES {{.Table}} (id) ON DELETE CASCADE,
+    used_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_recovery_codes_user_id" .Name)}} ON {{ident (printf "%s_recovery_codes" .Name)}} (user_id);
+ALTER TABLE {{ident (printf "%s_sessions" .Name)}} ADD COLUMN IF NOT EXISTS mfa BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = permissions || '["mfa:manage"]'::jsonb
+WHERE name = 'admin' AND NOT permissions @> '["mfa:manage"]'::jsonb;
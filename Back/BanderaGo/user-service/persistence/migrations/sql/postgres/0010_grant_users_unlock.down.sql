@@ -0,0 +1 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = permissions - 'users:unlock';
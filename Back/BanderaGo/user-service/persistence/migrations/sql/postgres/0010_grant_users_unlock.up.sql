@@ -0,0 +1,2 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = permissions || '["users:unlock"]'::jsonb
+WHERE name = 'admin' AND NOT permissions @> '["users:unlock"]'::jsonb;
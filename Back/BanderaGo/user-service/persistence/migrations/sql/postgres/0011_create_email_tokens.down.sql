@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS {{ident (printf "%s_email_tokens" .Name)}};
+ALTER TABLE {{.Table}} DROP COLUMN IF EXISTS email_verified_at;
@@ -0,0 +1,11 @@
+ALTER TABLE {{.Table}} ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ NULL;
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_email_tokens" .Name)}} (
+    hash VARCHAR(64) PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES {{.Table}} (id) ON DELETE CASCADE,
+    purpose VARCHAR(32) NOT NULL,
+    email VARCHAR(254) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_email_tokens_user_id" .Name)}} ON {{ident (printf "%s_email_tokens" .Name)}} (user_id);
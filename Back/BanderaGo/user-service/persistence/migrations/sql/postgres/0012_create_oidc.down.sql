@@ -0,0 +1,4 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = permissions - 'oidc:manage';
+DROP TABLE IF EXISTS {{ident (printf "%s_oidc_keys" .Name)}};
+DROP TABLE IF EXISTS {{ident (printf "%s_oidc_codes" .Name)}};
+DROP TABLE IF EXISTS {{ident (printf "%s_oidc_clients" .Name)}};
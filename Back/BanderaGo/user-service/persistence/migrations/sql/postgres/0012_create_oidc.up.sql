@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_oidc_clients" .Name)}} (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    secret_hash VARCHAR(64) NULL,
+    redirect_uris JSONB NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_oidc_codes" .Name)}} (
+    hash VARCHAR(64) PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL REFERENCES {{ident (printf "%s_oidc_clients" .Name)}} (id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES {{.Table}} (id) ON DELETE CASCADE,
+    redirect_uri TEXT NOT NULL,
+    scope TEXT NOT NULL,
+    nonce TEXT NOT NULL DEFAULT '',
+    code_challenge VARCHAR(128) NOT NULL,
+    auth_time TIMESTAMPTZ NOT NULL,
+    multi_factor BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_oidc_codes_client_id" .Name)}} ON {{ident (printf "%s_oidc_codes" .Name)}} (client_id);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_oidc_codes_user_id" .Name)}} ON {{ident (printf "%s_oidc_codes" .Name)}} (user_id);
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_oidc_keys" .Name)}} (
+    id VARCHAR(64) PRIMARY KEY,
+    algorithm VARCHAR(16) NOT NULL,
+    public_key BYTEA NOT NULL,
+    private_key BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    retired_at TIMESTAMPTZ NULL
+);
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = permissions || '["oidc:manage"]'::jsonb
+WHERE name = 'admin' AND NOT permissions @> '["oidc:manage"]'::jsonb;
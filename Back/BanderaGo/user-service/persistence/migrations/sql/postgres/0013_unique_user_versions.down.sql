@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS {{ident (printf "idx_%s_versions_user_version" .Name)}};
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_versions_user_version" .Name)}} ON {{ident (printf "%s_versions" .Name)}} (user_id, version);
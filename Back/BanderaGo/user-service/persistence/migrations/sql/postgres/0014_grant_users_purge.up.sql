@@ -0,0 +1,2 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = permissions || '["users:purge"]'::jsonb
+WHERE name = 'admin' AND NOT permissions @> '["users:purge"]'::jsonb;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS {{.Table}} (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    name TEXT,
+    last_name TEXT,
+    version INTEGER NOT NULL DEFAULT 1
+);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_deleted_at" .Name)}} ON {{.Table}} (deleted_at);
+
+-- Las tablas que creó AutoMigrate antes de las migraciones no tienen la columna version
+ALTER TABLE {{.Table}} ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS {{ident (printf "%s_audit" .Name)}};
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_audit" .Name)}} (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    actor TEXT NOT NULL,
+    request_id TEXT NOT NULL,
+    operation TEXT NOT NULL,
+    changes TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_audit_user_id" .Name)}} ON {{ident (printf "%s_audit" .Name)}} (user_id, id);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_audit_created_at" .Name)}} ON {{ident (printf "%s_audit" .Name)}} (created_at);
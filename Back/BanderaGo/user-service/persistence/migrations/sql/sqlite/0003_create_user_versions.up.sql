@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_versions" .Name)}} (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    name TEXT,
+    last_name TEXT,
+    deleted_at DATETIME NULL,
+    valid_from DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_versions_user_version" .Name)}} ON {{ident (printf "%s_versions" .Name)}} (user_id, version);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_versions_user_valid_from" .Name)}} ON {{ident (printf "%s_versions" .Name)}} (user_id, valid_from);
+
+INSERT INTO {{ident (printf "%s_versions" .Name)}} (user_id, version, name, last_name, deleted_at, valid_from)
+SELECT id, version, name, last_name, NULL, COALESCE(updated_at, created_at, CURRENT_TIMESTAMP) FROM {{.Table}};
+
+INSERT INTO {{ident (printf "%s_versions" .Name)}} (user_id, version, name, last_name, deleted_at, valid_from)
+SELECT id, version, name, last_name, deleted_at, deleted_at FROM {{.Table}} WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS {{ident (printf "idx_%s_email" .Name)}};
+DROP INDEX IF EXISTS {{ident (printf "idx_%s_username" .Name)}};
+DROP INDEX IF EXISTS {{ident (printf "idx_%s_phone" .Name)}};
+
+ALTER TABLE {{ident (printf "%s_versions" .Name)}} DROP COLUMN email;
+ALTER TABLE {{ident (printf "%s_versions" .Name)}} DROP COLUMN username;
+ALTER TABLE {{ident (printf "%s_versions" .Name)}} DROP COLUMN phone;
+ALTER TABLE {{ident (printf "%s_versions" .Name)}} DROP COLUMN display_name;
+
+ALTER TABLE {{.Table}} DROP COLUMN email;
+ALTER TABLE {{.Table}} DROP COLUMN username;
+ALTER TABLE {{.Table}} DROP COLUMN phone;
+ALTER TABLE {{.Table}} DROP COLUMN display_name;
@@ -0,0 +1,12 @@
+ALTER TABLE {{.Table}} ADD COLUMN email TEXT NULL;
+ALTER TABLE {{.Table}} ADD COLUMN username TEXT NULL;
+ALTER TABLE {{.Table}} ADD COLUMN phone TEXT NULL;
+ALTER TABLE {{.Table}} ADD COLUMN display_name TEXT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS {{ident (printf "idx_%s_email" .Name)}} ON {{.Table}} (email);
+CREATE UNIQUE INDEX IF NOT EXISTS {{ident (printf "idx_%s_username" .Name)}} ON {{.Table}} (username);
+CREATE UNIQUE INDEX IF NOT EXISTS {{ident (printf "idx_%s_phone" .Name)}} ON {{.Table}} (phone);
+
+ALTER TABLE {{ident (printf "%s_versions" .Name)}} ADD COLUMN email TEXT NULL;
+ALTER TABLE {{ident (printf "%s_versions" .Name)}} ADD COLUMN username TEXT NULL;
+ALTER TABLE {{ident (printf "%s_versions" .Name)}} ADD COLUMN phone TEXT NULL;
+ALTER TABLE {{ident (printf "%s_versions" .Name)}} ADD COLUMN display_name TEXT NULL;
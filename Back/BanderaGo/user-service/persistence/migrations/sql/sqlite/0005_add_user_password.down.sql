@@ -0,0 +1,2 @@
+ALTER TABLE {{.Table}} DROP COLUMN password_hash;
+ALTER TABLE {{.Table}} DROP COLUMN password_changed_at;
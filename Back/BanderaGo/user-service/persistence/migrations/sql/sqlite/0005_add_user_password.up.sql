@@ -0,0 +1,2 @@
+ALTER TABLE {{.Table}} ADD COLUMN password_hash TEXT NULL;
+ALTER TABLE {{.Table}} ADD COLUMN password_changed_at DATETIME NULL;
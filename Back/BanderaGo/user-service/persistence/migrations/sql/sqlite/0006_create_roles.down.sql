@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS {{ident (printf "%s_user_roles" .Name)}};
+DROP TABLE IF EXISTS {{ident (printf "%s_roles" .Name)}};
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_roles" .Name)}} (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    permissions TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_user_roles" .Name)}} (
+    user_id INTEGER NOT NULL REFERENCES {{.Table}} (id) ON DELETE CASCADE,
+    role TEXT NOT NULL REFERENCES {{ident (printf "%s_roles" .Name)}} (name) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (user_id, role)
+);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_user_roles_role" .Name)}} ON {{ident (printf "%s_user_roles" .Name)}} (role);
+INSERT OR IGNORE INTO {{ident (printf "%s_roles" .Name)}} (name, description, permissions, created_at, updated_at) VALUES
+    ('admin', 'Administración completa de usuarios y roles', '["users:read","users:create","users:update","users:delete","users:password","audit:read","roles:manage"]', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+    ('auditor', 'Consulta de usuarios y de la auditoría', '["users:read","audit:read"]', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
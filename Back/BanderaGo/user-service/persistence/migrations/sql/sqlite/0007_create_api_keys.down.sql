@@ -0,0 +1,4 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = (
+    SELECT json_group_array(value) FROM json_each(permissions) WHERE value <> 'apikeys:manage'
+);
+DROP TABLE IF EXISTS {{ident (printf "%s_api_keys" .Name)}};
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_api_keys" .Name)}} (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    hash TEXT NOT NULL,
+    permissions TEXT NOT NULL,
+    expires_at DATETIME NULL,
+    revoked_at DATETIME NULL,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS {{ident (printf "idx_%s_api_keys_prefix" .Name)}} ON {{ident (printf "%s_api_keys" .Name)}} (prefix);
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = json_insert(permissions, '$[#]', 'apikeys:manage')
+WHERE name = 'admin' AND NOT EXISTS (SELECT 1 FROM json_each(permissions) WHERE value = 'apikeys:manage');
@@ -0,0 +1,5 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = (
+    SELECT json_group_array(value) FROM json_each(permissions) WHERE value <> 'sessions:manage'
+);
+DROP TABLE IF EXISTS {{ident (printf "%s_refresh_tokens" .Name)}};
+DROP TABLE IF EXISTS {{ident (printf "%s_sessions" .Name)}};
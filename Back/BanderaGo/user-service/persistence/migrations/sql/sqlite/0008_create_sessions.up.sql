@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_sessions" .Name)}} (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES {{.Table}} (id) ON DELETE CASCADE,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    last_used_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_sessions_user_id" .Name)}} ON {{ident (printf "%s_sessions" .Name)}} (user_id);
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_refresh_tokens" .Name)}} (
+    hash TEXT PRIMARY KEY,
+    session_id INTEGER NOT NULL REFERENCES {{ident (printf "%s_sessions" .Name)}} (id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL,
+    used_at DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_refresh_tokens_session_id" .Name)}} ON {{ident (printf "%s_refresh_tokens" .Name)}} (session_id);
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = json_insert(permissions, '$[#]', 'sessions:manage')
+WHERE name = 'admin' AND NOT EXISTS (SELECT 1 FROM json_each(permissions) WHERE value = 'sessions:manage');
@@ -0,0 +1,6 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = (
+    SELECT json_group_array(value) FROM json_each(permissions) WHERE value <> 'mfa:manage'
+);
+ALTER TABLE {{ident (printf "%s_sessions" .Name)}} DROP COLUMN mfa;
+DROP TABLE IF EXISTS {{ident (printf "%s_recovery_codes" .Name)}};
+DROP TABLE IF EXISTS {{ident (printf "%s_mfa" .Name)}};
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_mfa" .Name)}} (
+    user_id INTEGER PRIMARY KEY REFERENCES {{.Table}} (id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    last_step INTEGER NOT NULL DEFAULT 0,
+    confirmed_at DATETIME NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_recovery_codes" .Name)}} (
+    hash TEXT PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES {{.Table}} (id) ON DELETE CASCADE,
+    used_at DATETIME NULL,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_recovery_codes_user_id" .Name)}} ON {{ident (printf "%s_recovery_codes" .Name)}} (user_id);
+ALTER TABLE {{ident (printf "%s_sessions" .Name)}} ADD COLUMN mfa BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = json_insert(permissions, '$[#]', 'mfa:manage')
+WHERE name = 'admin' AND NOT EXISTS (SELECT 1 FROM json_each(permissions) WHERE value = 'mfa:manage');
@@ -0,0 +1,3 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = (
+    SELECT json_group_array(value) FROM json_each(permissions) WHERE value <> 'users:unlock'
+);
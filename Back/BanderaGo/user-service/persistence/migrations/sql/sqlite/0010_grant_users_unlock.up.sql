@@ -0,0 +1,2 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = json_insert(permissions, '$[#]', 'users:unlock')
+WHERE name = 'admin' AND NOT EXISTS (SELECT 1 FROM json_each(permissions) WHERE value = 'users:unlock');
@@ -0,0 +1,11 @@
+ALTER TABLE {{.Table}} ADD COLUMN email_verified_at DATETIME NULL;
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_email_tokens" .Name)}} (
+    hash TEXT PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES {{.Table}} (id) ON DELETE CASCADE,
+    purpose TEXT NOT NULL,
+    email TEXT NOT NULL,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME NULL,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_email_tokens_user_id" .Name)}} ON {{ident (printf "%s_email_tokens" .Name)}} (user_id);
@@ -0,0 +1,6 @@
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = (
+    SELECT json_group_array(value) FROM json_each(permissions) WHERE value <> 'oidc:manage'
+);
+DROP TABLE IF EXISTS {{ident (printf "%s_oidc_keys" .Name)}};
+DROP TABLE IF EXISTS {{ident (printf "%s_oidc_codes" .Name)}};
+DROP TABLE IF EXISTS {{ident (printf "%s_oidc_clients" .Name)}};
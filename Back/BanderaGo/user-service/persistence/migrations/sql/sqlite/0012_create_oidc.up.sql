@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_oidc_clients" .Name)}} (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    secret_hash TEXT NULL,
+    redirect_uris TEXT NOT NULL,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_oidc_codes" .Name)}} (
+    hash TEXT PRIMARY KEY,
+    client_id TEXT NOT NULL REFERENCES {{ident (printf "%s_oidc_clients" .Name)}} (id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES {{.Table}} (id) ON DELETE CASCADE,
+    redirect_uri TEXT NOT NULL,
+    scope TEXT NOT NULL,
+    nonce TEXT NOT NULL DEFAULT '',
+    code_challenge TEXT NOT NULL,
+    auth_time DATETIME NOT NULL,
+    multi_factor BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME NULL,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_oidc_codes_client_id" .Name)}} ON {{ident (printf "%s_oidc_codes" .Name)}} (client_id);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_oidc_codes_user_id" .Name)}} ON {{ident (printf "%s_oidc_codes" .Name)}} (user_id);
+CREATE TABLE IF NOT EXISTS {{ident (printf "%s_oidc_keys" .Name)}} (
+    id TEXT PRIMARY KEY,
+    algorithm TEXT NOT NULL,
+    public_key BLOB NOT NULL,
+    private_key BLOB NOT NULL,
+    created_at DATETIME NOT NULL,
+    retired_at DATETIME NULL
+);
+UPDATE {{ident (printf "%s_roles" .Name)}} SET permissions = json_insert(permissions, '$[#]', 'oidc:manage')
+WHERE name = 'admin' AND NOT EXISTS (SELECT 1 FROM json_each(permissions) WHERE value = 'oidc:manage');
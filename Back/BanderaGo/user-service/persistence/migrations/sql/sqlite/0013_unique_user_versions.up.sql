@@ -0,0 +1,11 @@
+UPDATE {{ident (printf "%s_versions" .Name)}} SET version = version + (
+    SELECT COUNT(*) FROM {{ident (printf "%s_versions" .Name)}} deleted
+    WHERE deleted.user_id = {{ident (printf "%s_versions" .Name)}}.user_id AND deleted.deleted_at IS NOT NULL AND deleted.id <= {{ident (printf "%s_versions" .Name)}}.id
+);
+
+UPDATE {{.Table}} SET version = version + (
+    SELECT COUNT(*) FROM {{ident (printf "%s_versions" .Name)}} deleted WHERE deleted.user_id = {{.Table}}.id AND deleted.deleted_at IS NOT NULL
+);
+
+DROP INDEX IF EXISTS {{ident (printf "idx_%s_versions_user_version" .Name)}};
+CREATE UNIQUE INDEX IF NOT EXISTS {{ident (printf "idx_%s_versions_user_version" .Name)}} ON {{ident (printf "%s_versions" .Name)}} (user_id, version);
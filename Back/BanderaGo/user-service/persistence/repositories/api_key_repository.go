@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"application/models"
+	"context"
+)
+
+// APIKeyRepository guarda las llaves de API; las revocadas se conservan
+type APIKeyRepository interface {
+	// GetAPIKeys devuelve todas las llaves ordenadas por ID
+	GetAPIKeys(ctx context.Context) ([]*models.APIKey, error)
+	GetAPIKey(ctx context.Context, id uint) (*models.APIKey, error)
+	// GetAPIKeyByPrefix busca la llave por su prefijo visible
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	// UpdateAPIKey reemplaza el prefijo, el hash y la fecha de revocación de la llave
+	UpdateAPIKey(ctx context.Context, key *models.APIKey) error
+}
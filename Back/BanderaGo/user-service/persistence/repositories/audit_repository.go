@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"application/models"
+	"context"
+	"time"
+)
+
+// AuditQuery describe una página de registros de auditoría, del más reciente al más
+// antiguo. Los campos vacíos no filtran.
+type AuditQuery struct {
+	UserID    uint
+	Actor     string
+	Operation string
+	From      *time.Time
+	To        *time.Time
+	Limit     int
+	Offset    int
+}
+
+type AuditPage struct {
+	Records []*models.AuditRecord
+	Total   int64
+}
+
+// AuditRepository guarda la auditoría de usuarios; los registros no se modifican ni se eliminan
+type AuditRepository interface {
+	CreateAuditRecord(ctx context.Context, record *models.AuditRecord) error
+	GetAuditRecords(ctx context.Context, query AuditQuery) (*AuditPage, error)
+}
@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"application/models"
+	"context"
+	"time"
+)
+
+// EmailTokenRepository guarda los tokens de un solo uso que se envían por correo
+type EmailTokenRepository interface {
+	// CreateEmailToken guarda el token e invalida los anteriores sin usar del mismo
+	// usuario y propósito: solo vale el último enlace enviado
+	CreateEmailToken(ctx context.Context, token *models.EmailToken) error
+	// UseEmailToken marca como usado el token con ese hash y propósito y lo devuelve. Un
+	// token vencido o ya usado se trata como inexistente.
+	UseEmailToken(ctx context.Context, hash, purpose string, at time.Time) (*models.EmailToken, error)
+}
@@ -1,13 +1,81 @@
 package repositories
 
 import (
+	"context"
+
 	"gorm.io/gorm"
 )
 
 type GormDB interface {
+	// Dialect devuelve el nombre del motor, p. ej. "mysql", "postgres" o "sqlite"
+	Dialect() string
+	WithContext(ctx context.Context) GormDB
+	// Unscoped incluye los registros eliminados lógicamente
+	Unscoped() GormDB
+	Model(value interface{}) GormDB
+	Where(query interface{}, args ...interface{}) GormDB
+	Order(value interface{}) GormDB
+	Limit(limit int) GormDB
+	Offset(offset int) GormDB
+	Select(query interface{}, args ...interface{}) GormDB
+	Count(count *int64) *gorm.DB
 	Create(value interface{}) *gorm.DB
 	Find(dest interface{}, conds ...interface{}) *gorm.DB
 	First(dest interface{}, conds ...interface{}) *gorm.DB
 	Save(value interface{}) *gorm.DB
+	Updates(values interface{}) *gorm.DB
 	Delete(value interface{}, conds ...interface{}) *gorm.DB
+	// Transaction ejecuta fn en una transacción que se confirma si fn no devuelve error
+	Transaction(fn func(tx GormDB) error) error
+}
+
+// gormDB adapta *gorm.DB a GormDB para que los métodos encadenables conserven la interfaz
+type gormDB struct {
+	*gorm.DB
+}
+
+func NewGormDB(db *gorm.DB) GormDB {
+	return &gormDB{DB: db}
+}
+
+func (g *gormDB) Dialect() string {
+	return g.DB.Dialector.Name()
+}
+
+func (g *gormDB) WithContext(ctx context.Context) GormDB {
+	return &gormDB{DB: g.DB.WithContext(ctx)}
+}
+
+func (g *gormDB) Transaction(fn func(tx GormDB) error) error {
+	return g.DB.Transaction(func(tx *gorm.DB) error {
+		return fn(&gormDB{DB: tx})
+	})
+}
+
+func (g *gormDB) Unscoped() GormDB {
+	return &gormDB{DB: g.DB.Unscoped()}
+}
+
+func (g *gormDB) Model(value interface{}) GormDB {
+	return &gormDB{DB: g.DB.Model(value)}
+}
+
+func (g *gormDB) Where(query interface{}, args ...interface{}) GormDB {
+	return &gormDB{DB: g.DB.Where(query, args...)}
+}
+
+func (g *gormDB) Order(value interface{}) GormDB {
+	return &gormDB{DB: g.DB.Order(value)}
+}
+
+func (g *gormDB) Limit(limit int) GormDB {
+	return &gormDB{DB: g.DB.Limit(limit)}
+}
+
+func (g *gormDB) Offset(offset int) GormDB {
+	return &gormDB{DB: g.DB.Offset(offset)}
+}
+
+func (g *gormDB) Select(query interface{}, args ...interface{}) GormDB {
+	return &gormDB{DB: g.DB.Select(query, args...)}
 }
@@ -0,0 +1,68 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var errAPIKeyNotFound = errors.New("la llave de API no existe")
+
+type APIKeyRepositoryImpl struct {
+	db repositories.GormDB
+}
+
+func NewAPIKeyRepository(db repositories.GormDB) *APIKeyRepositoryImpl {
+	return &APIKeyRepositoryImpl{db: db}
+}
+
+func (r *APIKeyRepositoryImpl) GetAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	var keys []*models.APIKey
+	if err := conn(ctx, r.db).Order("id").Find(&keys).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return keys, nil
+}
+
+func (r *APIKeyRepositoryImpl) GetAPIKey(ctx context.Context, id uint) (*models.APIKey, error) {
+	return r.first(ctx, "id = ?", id)
+}
+
+func (r *APIKeyRepositoryImpl) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	return r.first(ctx, "prefix = ?", prefix)
+}
+
+func (r *APIKeyRepositoryImpl) first(ctx context.Context, query string, arg interface{}) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := conn(ctx, r.db).Where(query, arg).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFoundField("api_key", errAPIKeyNotFound)
+		}
+		return nil, translateError(err)
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepositoryImpl) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	err := translateError(conn(ctx, r.db).Create(key).Error)
+	if errors.Is(err, apperrors.ErrConflict) {
+		return apperrors.Conflict("prefix", err)
+	}
+	return err
+}
+
+func (r *APIKeyRepositoryImpl) UpdateAPIKey(ctx context.Context, key *models.APIKey) error {
+	result := conn(ctx, r.db).Model(&models.APIKey{}).Where("id = ?", key.ID).
+		Select("prefix", "hash", "revoked_at", "updated_at").Updates(key)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NotFoundField("api_key", errAPIKeyNotFound)
+	}
+	return nil
+}
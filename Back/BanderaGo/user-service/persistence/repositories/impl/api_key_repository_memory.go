@@ -0,0 +1,113 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+var errDuplicatePrefix = errors.New("el prefijo de la llave ya existe")
+
+// MemoryAPIKeyRepository guarda las llaves en memoria con la misma semántica que
+// APIKeyRepositoryImpl
+type MemoryAPIKeyRepository struct {
+	mu     sync.RWMutex
+	keys   []models.APIKey
+	nextID uint
+}
+
+func NewMemoryAPIKeyRepository() *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{nextID: 1}
+}
+
+func (r *MemoryAPIKeyRepository) GetAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*models.APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, cloneAPIKey(key))
+	}
+	return keys, nil
+}
+
+func (r *MemoryAPIKeyRepository) GetAPIKey(ctx context.Context, id uint) (*models.APIKey, error) {
+	return r.find(ctx, func(key models.APIKey) bool { return key.ID == id })
+}
+
+func (r *MemoryAPIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	return r.find(ctx, func(key models.APIKey) bool { return key.Prefix == prefix })
+}
+
+func (r *MemoryAPIKeyRepository) find(ctx context.Context, match func(key models.APIKey) bool) (*models.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	index := slices.IndexFunc(r.keys, match)
+	if index < 0 {
+		return nil, apperrors.NotFoundField("api_key", errAPIKeyNotFound)
+	}
+	return cloneAPIKey(r.keys[index]), nil
+}
+
+func (r *MemoryAPIKeyRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if slices.ContainsFunc(r.keys, func(stored models.APIKey) bool { return stored.Prefix == key.Prefix }) {
+		return apperrors.Conflict("prefix", errDuplicatePrefix)
+	}
+	now := time.Now()
+	key.ID = r.nextID
+	key.CreatedAt, key.UpdatedAt = now, now
+	r.nextID++
+	r.keys = append(r.keys, *cloneAPIKey(*key))
+	return nil
+}
+
+func (r *MemoryAPIKeyRepository) UpdateAPIKey(ctx context.Context, key *models.APIKey) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index := slices.IndexFunc(r.keys, func(stored models.APIKey) bool { return stored.ID == key.ID })
+	if index < 0 {
+		return apperrors.NotFoundField("api_key", errAPIKeyNotFound)
+	}
+	if slices.ContainsFunc(r.keys, func(stored models.APIKey) bool { return stored.Prefix == key.Prefix && stored.ID != key.ID }) {
+		return apperrors.Conflict("prefix", errDuplicatePrefix)
+	}
+	stored := &r.keys[index]
+	stored.Prefix, stored.Hash, stored.RevokedAt = key.Prefix, key.Hash, cloneTime(key.RevokedAt)
+	stored.UpdatedAt = time.Now()
+	return nil
+}
+
+func cloneAPIKey(key models.APIKey) *models.APIKey {
+	key.Permissions = slices.Clone(key.Permissions)
+	key.ExpiresAt, key.RevokedAt = cloneTime(key.ExpiresAt), cloneTime(key.RevokedAt)
+	return &key
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	clone := *t
+	return &clone
+}
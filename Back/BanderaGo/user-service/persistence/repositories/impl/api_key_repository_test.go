@@ -0,0 +1,67 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: ambos repositorios guardan llaves, las buscan por prefijo y las rotan o revocan
+func TestAPIKeyRepositories(t *testing.T) {
+	repos := map[string]func(t *testing.T) repositories.APIKeyRepository{
+		"memoria": func(t *testing.T) repositories.APIKeyRepository { return NewMemoryAPIKeyRepository() },
+		"sqlite":  func(t *testing.T) repositories.APIKeyRepository { return NewAPIKeyRepository(newSQLiteGormDB(t)) },
+	}
+
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			ctx := context.Background()
+			expires := time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond)
+
+			batch := &models.APIKey{Name: "batch", Prefix: "usk_000000000001", Hash: "h1", Permissions: []string{models.PermissionUsersRead}, ExpiresAt: &expires, CreatedBy: "admin"}
+			require.NoError(t, repo.CreateAPIKey(ctx, batch))
+			require.NoError(t, repo.CreateAPIKey(ctx, &models.APIKey{Name: "sync", Prefix: "usk_000000000002", Hash: "h2", Permissions: []string{}}))
+			err := repo.CreateAPIKey(ctx, &models.APIKey{Name: "otra", Prefix: "usk_000000000001", Hash: "h3", Permissions: []string{}})
+			assert.ErrorIs(t, err, apperrors.ErrConflict)
+
+			keys, err := repo.GetAPIKeys(ctx)
+			require.NoError(t, err)
+			require.Len(t, keys, 2)
+			assert.Equal(t, []string{"batch", "sync"}, []string{keys[0].Name, keys[1].Name})
+
+			found, err := repo.GetAPIKeyByPrefix(ctx, "usk_000000000001")
+			require.NoError(t, err)
+			assert.Equal(t, batch.ID, found.ID)
+			assert.Equal(t, []string{models.PermissionUsersRead}, found.Permissions)
+			require.NotNil(t, found.ExpiresAt)
+			assert.True(t, expires.Equal(*found.ExpiresAt))
+			_, err = repo.GetAPIKeyByPrefix(ctx, "usk_ffffffffffff")
+			assert.ErrorIs(t, err, apperrors.ErrNotFound)
+			assert.Equal(t, "api_key", apperrors.FieldOf(err))
+
+			// Rotar reemplaza el prefijo y el hash; revocar conserva la llave
+			revoked := time.Now()
+			found.Prefix, found.Hash, found.RevokedAt = "usk_000000000003", "h4", &revoked
+			require.NoError(t, repo.UpdateAPIKey(ctx, found))
+			_, err = repo.GetAPIKeyByPrefix(ctx, "usk_000000000001")
+			assert.ErrorIs(t, err, apperrors.ErrNotFound)
+			updated, err := repo.GetAPIKey(ctx, batch.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "usk_000000000003", updated.Prefix)
+			assert.Equal(t, "h4", updated.Hash)
+			assert.NotNil(t, updated.RevokedAt)
+			assert.Equal(t, "admin", updated.CreatedBy)
+
+			assert.ErrorIs(t, repo.UpdateAPIKey(ctx, &models.APIKey{ID: 99}), apperrors.ErrNotFound)
+			_, err = repo.GetAPIKey(ctx, 99)
+			assert.ErrorIs(t, err, apperrors.ErrNotFound)
+		})
+	}
+}
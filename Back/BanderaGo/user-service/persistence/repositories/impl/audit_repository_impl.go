@@ -0,0 +1,53 @@
+package impl
+
+import (
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+)
+
+type AuditRepositoryImpl struct {
+	db repositories.GormDB
+}
+
+func NewAuditRepository(db repositories.GormDB) *AuditRepositoryImpl {
+	return &AuditRepositoryImpl{db: db}
+}
+
+func (r *AuditRepositoryImpl) CreateAuditRecord(ctx context.Context, record *models.AuditRecord) error {
+	return translateError(conn(ctx, r.db).Create(record).Error)
+}
+
+func (r *AuditRepositoryImpl) GetAuditRecords(ctx context.Context, query repositories.AuditQuery) (*repositories.AuditPage, error) {
+	var total int64
+	if err := filterAudit(conn(ctx, r.db).Model(&models.AuditRecord{}), query).Count(&total).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	var records []*models.AuditRecord
+	db := filterAudit(conn(ctx, r.db).Model(&models.AuditRecord{}), query).Order("id DESC").Offset(query.Offset).Limit(query.Limit)
+	if err := db.Find(&records).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	return &repositories.AuditPage{Records: records, Total: total}, nil
+}
+
+func filterAudit(db repositories.GormDB, query repositories.AuditQuery) repositories.GormDB {
+	if query.UserID != 0 {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.Actor != "" {
+		db = db.Where("actor = ?", query.Actor)
+	}
+	if query.Operation != "" {
+		db = db.Where("operation = ?", query.Operation)
+	}
+	if query.From != nil {
+		db = db.Where("created_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where("created_at < ?", *query.To)
+	}
+	return db
+}
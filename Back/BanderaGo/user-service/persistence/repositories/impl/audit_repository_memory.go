@@ -0,0 +1,72 @@
+package impl
+
+import (
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryAuditRepository guarda la auditoría en memoria con la misma semántica que AuditRepositoryImpl
+type MemoryAuditRepository struct {
+	mu      sync.RWMutex
+	records []models.AuditRecord
+}
+
+func NewMemoryAuditRepository() *MemoryAuditRepository {
+	return &MemoryAuditRepository{}
+}
+
+func (r *MemoryAuditRepository) CreateAuditRecord(ctx context.Context, record *models.AuditRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record.ID = uint(len(r.records) + 1)
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+	r.records = append(r.records, *record)
+	return nil
+}
+
+func (r *MemoryAuditRepository) GetAuditRecords(ctx context.Context, query repositories.AuditQuery) (*repositories.AuditPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	page := &repositories.AuditPage{}
+	for i := len(r.records) - 1; i >= 0; i-- {
+		record := r.records[i]
+		if !matchesAudit(&record, query) {
+			continue
+		}
+		if page.Total >= int64(query.Offset) && len(page.Records) < query.Limit {
+			page.Records = append(page.Records, &record)
+		}
+		page.Total++
+	}
+	return page, nil
+}
+
+// matchesAudit replica los filtros de filterAudit
+func matchesAudit(record *models.AuditRecord, query repositories.AuditQuery) bool {
+	switch {
+	case query.UserID != 0 && record.UserID != query.UserID:
+		return false
+	case query.Actor != "" && record.Actor != query.Actor:
+		return false
+	case query.Operation != "" && record.Operation != query.Operation:
+		return false
+	case query.From != nil && record.CreatedAt.Before(*query.From):
+		return false
+	case query.To != nil && !record.CreatedAt.Before(*query.To):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package impl
+
+import (
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: la auditoría se lista de la más reciente a la más antigua con filtros y paginación
+func TestMemoryAuditRecords(t *testing.T) {
+	repo := NewMemoryAuditRepository()
+	ctx := context.Background()
+	start := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+	for i, record := range []models.AuditRecord{
+		{UserID: 1, Actor: "alice", Operation: models.AuditCreate},
+		{UserID: 2, Actor: "bob", Operation: models.AuditCreate},
+		{UserID: 1, Actor: "bob", Operation: models.AuditUpdate},
+		{UserID: 1, Actor: "alice", Operation: models.AuditDelete},
+	} {
+		record.CreatedAt = start.Add(time.Duration(i) * time.Hour)
+		require.NoError(t, repo.CreateAuditRecord(ctx, &record))
+		assert.Equal(t, uint(i+1), record.ID)
+	}
+
+	page, err := repo.GetAuditRecords(ctx, repositories.AuditQuery{UserID: 1, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, []uint{4, 3}, auditIDs(page))
+
+	page, err = repo.GetAuditRecords(ctx, repositories.AuditQuery{UserID: 1, Limit: 2, Offset: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{1}, auditIDs(page))
+
+	page, err = repo.GetAuditRecords(ctx, repositories.AuditQuery{Actor: "bob", Operation: models.AuditCreate, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{2}, auditIDs(page))
+
+	// Caso de prueba: el rango de fechas incluye el inicio y excluye el final
+	from, to := start.Add(time.Hour), start.Add(3*time.Hour)
+	page, err = repo.GetAuditRecords(ctx, repositories.AuditQuery{From: &from, To: &to, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{3, 2}, auditIDs(page))
+}
+
+func auditIDs(page *repositories.AuditPage) []uint {
+	ids := make([]uint, 0, len(page.Records))
+	for _, record := range page.Records {
+		ids = append(ids, record.ID)
+	}
+	return ids
+}
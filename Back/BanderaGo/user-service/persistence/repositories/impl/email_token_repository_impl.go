@@ -0,0 +1,50 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"errors"
+	"time"
+)
+
+var errEmailTokenNotFound = errors.New("el token no existe, venció o ya se usó")
+
+type EmailTokenRepositoryImpl struct {
+	db repositories.GormDB
+}
+
+func NewEmailTokenRepository(db repositories.GormDB) *EmailTokenRepositoryImpl {
+	return &EmailTokenRepositoryImpl{db: db}
+}
+
+func (r *EmailTokenRepositoryImpl) CreateEmailToken(ctx context.Context, token *models.EmailToken) error {
+	return conn(ctx, r.db).Transaction(func(tx repositories.GormDB) error {
+		err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, token.Purpose).Delete(&models.EmailToken{}).Error
+		if err != nil {
+			return translateError(err)
+		}
+		return translateError(tx.Create(token).Error)
+	})
+}
+
+func (r *EmailTokenRepositoryImpl) UseEmailToken(ctx context.Context, hash, purpose string, at time.Time) (*models.EmailToken, error) {
+	var token models.EmailToken
+	err := conn(ctx, r.db).Transaction(func(tx repositories.GormDB) error {
+		// La condición sobre used_at impide que dos solicitudes usen el mismo token
+		result := tx.Model(&models.EmailToken{}).Where("hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hash, purpose, at).
+			Updates(map[string]interface{}{"used_at": at})
+		if result.Error != nil {
+			return translateError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return apperrors.NotFoundField("token", errEmailTokenNotFound)
+		}
+		return translateError(tx.Where("hash = ?", hash).First(&token).Error)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
@@ -0,0 +1,57 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryEmailTokenRepository guarda los tokens de los correos en memoria con la misma
+// semántica que EmailTokenRepositoryImpl
+type MemoryEmailTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]models.EmailToken
+}
+
+func NewMemoryEmailTokenRepository() *MemoryEmailTokenRepository {
+	return &MemoryEmailTokenRepository{tokens: map[string]models.EmailToken{}}
+}
+
+func (r *MemoryEmailTokenRepository) CreateEmailToken(ctx context.Context, token *models.EmailToken) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, stored := range r.tokens {
+		if stored.UserID == token.UserID && stored.Purpose == token.Purpose && stored.UsedAt == nil {
+			delete(r.tokens, hash)
+		}
+	}
+	token.CreatedAt = time.Now()
+	stored := *token
+	stored.UsedAt = cloneTime(token.UsedAt)
+	r.tokens[token.Hash] = stored
+	return nil
+}
+
+func (r *MemoryEmailTokenRepository) UseEmailToken(ctx context.Context, hash, purpose string, at time.Time) (*models.EmailToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.tokens[hash]
+	if !ok || stored.Purpose != purpose || stored.UsedAt != nil || !stored.ExpiresAt.After(at) {
+		return nil, apperrors.NotFoundField("token", errEmailTokenNotFound)
+	}
+	stored.UsedAt = &at
+	r.tokens[hash] = stored
+	token := stored
+	token.UsedAt = cloneTime(stored.UsedAt)
+	return &token, nil
+}
@@ -0,0 +1,59 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: ambos repositorios aceptan cada token una vez, antes de vencer y solo el último de cada propósito
+func TestEmailTokenRepositories(t *testing.T) {
+	repos := map[string]func(t *testing.T) repositories.EmailTokenRepository{
+		"memoria": func(t *testing.T) repositories.EmailTokenRepository { return NewMemoryEmailTokenRepository() },
+		"sqlite": func(t *testing.T) repositories.EmailTokenRepository {
+			db := newSQLiteGormDB(t)
+			require.NoError(t, NewUserRepository(db).CreateUser(context.Background(), &models.User{Name: "Ana"}))
+			return NewEmailTokenRepository(db)
+		},
+	}
+
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			ctx := context.Background()
+			now := time.Now().UTC().Truncate(time.Millisecond)
+			newToken := func(hash, purpose string, expiresAt time.Time) {
+				require.NoError(t, repo.CreateEmailToken(ctx, &models.EmailToken{Hash: hash, UserID: 1, Purpose: purpose,
+					Email: "ana@example.com", ExpiresAt: expiresAt}))
+			}
+
+			newToken("v1", "verify_email", now.Add(time.Hour))
+			newToken("r1", "reset_password", now.Add(time.Hour))
+			_, err := repo.UseEmailToken(ctx, "v1", "reset_password", now)
+			assert.ErrorIs(t, err, apperrors.ErrNotFound, "otro propósito")
+
+			token, err := repo.UseEmailToken(ctx, "v1", "verify_email", now)
+			require.NoError(t, err)
+			assert.Equal(t, uint(1), token.UserID)
+			assert.Equal(t, "ana@example.com", token.Email)
+			_, err = repo.UseEmailToken(ctx, "v1", "verify_email", now)
+			assert.ErrorIs(t, err, apperrors.ErrNotFound, "ya usado")
+			assert.Equal(t, "token", apperrors.FieldOf(err))
+
+			// Un token nuevo invalida el anterior sin usar del mismo propósito
+			newToken("r2", "reset_password", now.Add(time.Hour))
+			_, err = repo.UseEmailToken(ctx, "r1", "reset_password", now)
+			assert.ErrorIs(t, err, apperrors.ErrNotFound, "reemplazado")
+			_, err = repo.UseEmailToken(ctx, "r2", "reset_password", now.Add(time.Hour))
+			assert.ErrorIs(t, err, apperrors.ErrNotFound, "vencido")
+			_, err = repo.UseEmailToken(ctx, "r2", "reset_password", now)
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package impl
+
+import (
+	"application/apperrors"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/glebarez/go-sqlite"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Códigos de error de MySQL relevantes para la traducción a errores del dominio
+const (
+	mysqlErrDupEntry          = 1062
+	mysqlErrRowIsReferenced   = 1451
+	mysqlErrNoReferencedRow   = 1452
+	mysqlErrBadNull           = 1048
+	mysqlErrDataTooLong       = 1406
+	mysqlErrWarnDataOutRange  = 1264
+	mysqlErrTruncatedValue    = 1366
+	mysqlErrLockWaitTimeout   = 1205
+	mysqlErrLockDeadlock      = 1213
+	mysqlErrTooManyConnection = 1040
+	mysqlErrServerShutdown    = 1053
+)
+
+// Códigos extendidos de SQLite relevantes para la traducción a errores del dominio
+const (
+	sqliteErrBusy                 = 5
+	sqliteErrLocked               = 6
+	sqliteErrConstraintForeignKey = 787
+	sqliteErrConstraintNotNull    = 1299
+	sqliteErrConstraintPrimaryKey = 1555
+	sqliteErrConstraintUnique     = 2067
+)
+
+// Códigos SQLSTATE de PostgreSQL relevantes para la traducción a errores del dominio
+const (
+	pgUniqueViolation           = "23505"
+	pgForeignKeyViolation       = "23503"
+	pgNotNullViolation          = "23502"
+	pgCheckViolation            = "23514"
+	pgStringDataRightTruncation = "22001"
+	pgNumericValueOutOfRange    = "22003"
+	pgInvalidTextRepresentation = "22P02"
+	pgSerializationFailure      = "40001"
+	pgDeadlockDetected          = "40P01"
+	pgLockNotAvailable          = "55P03"
+	pgTooManyConnections        = "53300"
+	pgAdminShutdown             = "57P01"
+	pgCannotConnectNow          = "57P03"
+)
+
+var (
+	duplicateKeyPattern     = regexp.MustCompile(`for key '([^']+)'`)
+	uniqueConstraintPattern = regexp.MustCompile(`UNIQUE constraint failed: ([\w.]+)`)
+	pgKeyPattern            = regexp.MustCompile(`^Key \(([^)]+)\)=`)
+)
+
+// translateError convierte los errores de GORM y del driver en errores del dominio.
+// Los errores de contexto se devuelven sin cambios para conservar su semántica.
+func translateError(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.NotFound(err)
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case mysqlErrDupEntry:
+			return apperrors.Conflict(duplicateKey(mysqlErr.Message), err)
+		case mysqlErrRowIsReferenced, mysqlErrNoReferencedRow:
+			return apperrors.Conflict("", err)
+		case mysqlErrBadNull, mysqlErrDataTooLong, mysqlErrWarnDataOutRange, mysqlErrTruncatedValue:
+			return apperrors.Validation("", err)
+		case mysqlErrLockWaitTimeout, mysqlErrLockDeadlock, mysqlErrTooManyConnection, mysqlErrServerShutdown:
+			return apperrors.Unavailable(err)
+		}
+		return err
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgUniqueViolation:
+			return apperrors.Conflict(pgKeyColumn(pgErr.Detail), err)
+		case pgForeignKeyViolation:
+			return apperrors.Conflict("", err)
+		case pgNotNullViolation:
+			return apperrors.Validation(pgErr.ColumnName, err)
+		case pgCheckViolation, pgStringDataRightTruncation, pgNumericValueOutOfRange, pgInvalidTextRepresentation:
+			return apperrors.Validation("", err)
+		case pgSerializationFailure, pgDeadlockDetected, pgLockNotAvailable, pgTooManyConnections, pgAdminShutdown, pgCannotConnectNow:
+			return apperrors.Unavailable(err)
+		}
+		return err
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqliteErrConstraintUnique, sqliteErrConstraintPrimaryKey:
+			return apperrors.Conflict(uniqueColumn(sqliteErr.Error()), err)
+		case sqliteErrConstraintForeignKey:
+			return apperrors.Conflict("", err)
+		case sqliteErrConstraintNotNull:
+			return apperrors.Validation("", err)
+		case sqliteErrBusy, sqliteErrLocked:
+			return apperrors.Unavailable(err)
+		}
+		return err
+	}
+
+	var netErr net.Error
+	var pgConnectErr *pgconn.ConnectError
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) || errors.As(err, &netErr) || errors.As(err, &pgConnectErr) {
+		return apperrors.Unavailable(err)
+	}
+	return err
+}
+
+// duplicateKey extrae el nombre del índice violado de un mensaje ER_DUP_ENTRY,
+// p. ej. "Duplicate entry 'x' for key 'users.idx_email'" -> "idx_email".
+func duplicateKey(message string) string {
+	matches := duplicateKeyPattern.FindStringSubmatch(message)
+	if len(matches) < 2 {
+		return ""
+	}
+	key := matches[1]
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		key = key[i+1:]
+	}
+	return key
+}
+
+// uniqueColumn extrae la columna de un error de restricción UNIQUE de SQLite,
+// p. ej. "UNIQUE constraint failed: users.email" -> "email".
+func uniqueColumn(message string) string {
+	matches := uniqueConstraintPattern.FindStringSubmatch(message)
+	if len(matches) < 2 {
+		return ""
+	}
+	column := matches[1]
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		column = column[i+1:]
+	}
+	return column
+}
+
+// pgKeyColumn extrae la columna del detalle de una violación de unicidad de PostgreSQL,
+// p. ej. "Key (email)=(a@b.com) already exists." -> "email". En llaves compuestas se
+// devuelve la primera columna.
+func pgKeyColumn(detail string) string {
+	matches := pgKeyPattern.FindStringSubmatch(detail)
+	if len(matches) < 2 {
+		return ""
+	}
+	column, _, _ := strings.Cut(matches[1], ",")
+	return strings.Trim(strings.TrimSpace(column), `"`)
+}
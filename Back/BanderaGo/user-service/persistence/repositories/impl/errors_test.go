@@ -0,0 +1,115 @@
+package impl
+
+import (
+	"application/apperrors"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Caso de prueba: traducción de errores del driver a errores del dominio
+func TestTranslateError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{"registro no encontrado", gorm.ErrRecordNotFound, apperrors.ErrNotFound},
+		{"entrada duplicada", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'x' for key 'users.idx_name'"}, apperrors.ErrConflict},
+		{"llave foránea", &mysql.MySQLError{Number: 1451}, apperrors.ErrConflict},
+		{"dato demasiado largo", &mysql.MySQLError{Number: 1406}, apperrors.ErrValidation},
+		{"columna nula", &mysql.MySQLError{Number: 1048}, apperrors.ErrValidation},
+		{"deadlock", &mysql.MySQLError{Number: 1213}, apperrors.ErrUnavailable},
+		{"conexión inválida", mysql.ErrInvalidConn, apperrors.ErrUnavailable},
+		{"conexión rota", fmt.Errorf("query: %w", driver.ErrBadConn), apperrors.ErrUnavailable},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := translateError(tc.err)
+			assert.ErrorIs(t, err, tc.kind)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+// Caso de prueba: traducción de errores de PostgreSQL
+func TestTranslateErrorPostgres(t *testing.T) {
+	cases := []struct {
+		name  string
+		err   error
+		kind  error
+		field string
+	}{
+		{"violación de unicidad", &pgconn.PgError{Code: "23505", Detail: "Key (email)=(a@b.com) already exists."}, apperrors.ErrConflict, "email"},
+		{"unicidad compuesta", &pgconn.PgError{Code: "23505", Detail: `Key ("last_name", name)=(Doe, John) already exists.`}, apperrors.ErrConflict, "last_name"},
+		{"llave foránea", &pgconn.PgError{Code: "23503"}, apperrors.ErrConflict, ""},
+		{"columna nula", &pgconn.PgError{Code: "23502", ColumnName: "name"}, apperrors.ErrValidation, "name"},
+		{"dato demasiado largo", &pgconn.PgError{Code: "22001"}, apperrors.ErrValidation, ""},
+		{"falla de serialización", &pgconn.PgError{Code: "40001"}, apperrors.ErrUnavailable, ""},
+		{"deadlock", &pgconn.PgError{Code: "40P01"}, apperrors.ErrUnavailable, ""},
+		{"servidor apagándose", &pgconn.PgError{Code: "57P01"}, apperrors.ErrUnavailable, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := translateError(fmt.Errorf("query: %w", tc.err))
+			assert.ErrorIs(t, err, tc.kind)
+			assert.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.field, apperrors.FieldOf(err))
+		})
+	}
+
+	unknown := &pgconn.PgError{Code: "42P01"}
+	assert.Equal(t, error(unknown), translateError(unknown))
+}
+
+// Caso de prueba: errores sin categoría y de contexto se devuelven sin cambios
+func TestTranslateErrorPassThrough(t *testing.T) {
+	assert.Nil(t, translateError(nil))
+
+	plain := errors.New("unexpected")
+	assert.Equal(t, plain, translateError(plain))
+	assert.Equal(t, context.DeadlineExceeded, translateError(context.DeadlineExceeded))
+
+	unknown := &mysql.MySQLError{Number: 1146}
+	assert.Equal(t, error(unknown), translateError(unknown))
+}
+
+// Caso de prueba: la entrada duplicada reporta el índice violado
+func TestTranslateErrorDuplicateKey(t *testing.T) {
+	err := translateError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'x' for key 'users.idx_name'"})
+	assert.Equal(t, "idx_name", apperrors.FieldOf(err))
+
+	// Los índices únicos de los datos de contacto se nombran como su columna
+	err = translateError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a@b.com' for key 'users.email'"})
+	assert.Equal(t, "email", apperrors.FieldOf(err))
+}
+
+// Caso de prueba: errores de restricciones de SQLite producidos por el driver real
+func TestTranslateErrorSQLite(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("CREATE TABLE accounts (id INTEGER PRIMARY KEY, email TEXT NOT NULL UNIQUE)").Error)
+	require.NoError(t, db.Exec("INSERT INTO accounts (id, email) VALUES (1, 'a@example.com')").Error)
+
+	duplicate := translateError(db.Exec("INSERT INTO accounts (id, email) VALUES (2, 'a@example.com')").Error)
+	assert.ErrorIs(t, duplicate, apperrors.ErrConflict)
+	assert.Equal(t, "email", apperrors.FieldOf(duplicate))
+
+	notNull := translateError(db.Exec("INSERT INTO accounts (id, email) VALUES (3, NULL)").Error)
+	assert.ErrorIs(t, notNull, apperrors.ErrValidation)
+
+	unknown := db.Exec("SELECT * FROM missing").Error
+	assert.Equal(t, unknown, translateError(unknown))
+}
@@ -0,0 +1,134 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	errMFANotFound          = errors.New("el usuario no tiene un segundo factor")
+	errMFAConfirmed         = errors.New("el segundo factor ya está confirmado")
+	errTOTPStepUsed         = errors.New("el código ya se usó")
+	errRecoveryCodeNotFound = errors.New("el código de recuperación no existe o ya se usó")
+)
+
+type MFARepositoryImpl struct {
+	db repositories.GormDB
+}
+
+func NewMFARepository(db repositories.GormDB) *MFARepositoryImpl {
+	return &MFARepositoryImpl{db: db}
+}
+
+func (r *MFARepositoryImpl) GetMFA(ctx context.Context, userID uint) (*models.UserMFA, error) {
+	var mfa models.UserMFA
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).First(&mfa).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFoundField("mfa", errMFANotFound)
+		}
+		return nil, translateError(err)
+	}
+	return &mfa, nil
+}
+
+func (r *MFARepositoryImpl) SaveMFA(ctx context.Context, mfa *models.UserMFA) error {
+	return conn(ctx, r.db).Transaction(func(tx repositories.GormDB) error {
+		if err := tx.Where("user_id = ?", mfa.UserID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return translateError(err)
+		}
+		if err := tx.Where("user_id = ?", mfa.UserID).Delete(&models.UserMFA{}).Error; err != nil {
+			return translateError(err)
+		}
+		return translateError(tx.Create(mfa).Error)
+	})
+}
+
+func (r *MFARepositoryImpl) ConfirmMFA(ctx context.Context, userID uint, step int64, at time.Time, hashes []string) error {
+	if _, err := r.GetMFA(ctx, userID); err != nil {
+		return err
+	}
+	return conn(ctx, r.db).Transaction(func(tx repositories.GormDB) error {
+		result := tx.Model(&models.UserMFA{}).Where("user_id = ? AND confirmed_at IS NULL", userID).
+			Updates(map[string]interface{}{"confirmed_at": at, "last_step": step})
+		if result.Error != nil {
+			return translateError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return apperrors.Conflict("mfa", errMFAConfirmed)
+		}
+		return replaceRecoveryCodes(tx, userID, hashes)
+	})
+}
+
+func (r *MFARepositoryImpl) UseTOTPStep(ctx context.Context, userID uint, step int64) error {
+	// La condición sobre last_step impide que dos inicios de sesión usen el mismo código
+	result := conn(ctx, r.db).Model(&models.UserMFA{}).Where("user_id = ? AND last_step < ?", userID, step).
+		Updates(map[string]interface{}{"last_step": step})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.Conflict("otp", errTOTPStepUsed)
+	}
+	return nil
+}
+
+func (r *MFARepositoryImpl) DeleteMFA(ctx context.Context, userID uint) error {
+	return conn(ctx, r.db).Transaction(func(tx repositories.GormDB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return translateError(err)
+		}
+		result := tx.Where("user_id = ?", userID).Delete(&models.UserMFA{})
+		if result.Error != nil {
+			return translateError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return apperrors.NotFoundField("mfa", errMFANotFound)
+		}
+		return nil
+	})
+}
+
+func (r *MFARepositoryImpl) ReplaceRecoveryCodes(ctx context.Context, userID uint, hashes []string) error {
+	return conn(ctx, r.db).Transaction(func(tx repositories.GormDB) error {
+		return replaceRecoveryCodes(tx, userID, hashes)
+	})
+}
+
+func (r *MFARepositoryImpl) UseRecoveryCode(ctx context.Context, userID uint, hash string, at time.Time) error {
+	result := conn(ctx, r.db).Model(&models.RecoveryCode{}).Where("hash = ? AND user_id = ? AND used_at IS NULL", hash, userID).
+		Updates(map[string]interface{}{"used_at": at})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NotFoundField("recovery_code", errRecoveryCodeNotFound)
+	}
+	return nil
+}
+
+func (r *MFARepositoryImpl) CountRecoveryCodes(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, translateError(err)
+}
+
+func replaceRecoveryCodes(tx repositories.GormDB, userID uint, hashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return translateError(err)
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+	codes := make([]models.RecoveryCode, 0, len(hashes))
+	for _, hash := range hashes {
+		codes = append(codes, models.RecoveryCode{Hash: hash, UserID: userID})
+	}
+	return translateError(tx.Create(&codes).Error)
+}
@@ -0,0 +1,162 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryMFARepository guarda los segundos factores en memoria con la misma semántica que
+// MFARepositoryImpl
+type MemoryMFARepository struct {
+	mu    sync.RWMutex
+	mfa   map[uint]models.UserMFA
+	codes map[string]models.RecoveryCode
+}
+
+func NewMemoryMFARepository() *MemoryMFARepository {
+	return &MemoryMFARepository{mfa: map[uint]models.UserMFA{}, codes: map[string]models.RecoveryCode{}}
+}
+
+func (r *MemoryMFARepository) GetMFA(ctx context.Context, userID uint) (*models.UserMFA, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	mfa, ok := r.mfa[userID]
+	if !ok {
+		return nil, apperrors.NotFoundField("mfa", errMFANotFound)
+	}
+	mfa.ConfirmedAt = cloneTime(mfa.ConfirmedAt)
+	return &mfa, nil
+}
+
+func (r *MemoryMFARepository) SaveMFA(ctx context.Context, mfa *models.UserMFA) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	mfa.CreatedAt, mfa.UpdatedAt = now, now
+	stored := *mfa
+	stored.ConfirmedAt = cloneTime(mfa.ConfirmedAt)
+	r.mfa[mfa.UserID] = stored
+	r.deleteCodes(mfa.UserID)
+	return nil
+}
+
+func (r *MemoryMFARepository) ConfirmMFA(ctx context.Context, userID uint, step int64, at time.Time, hashes []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mfa, ok := r.mfa[userID]
+	if !ok {
+		return apperrors.NotFoundField("mfa", errMFANotFound)
+	}
+	if mfa.ConfirmedAt != nil {
+		return apperrors.Conflict("mfa", errMFAConfirmed)
+	}
+	mfa.ConfirmedAt, mfa.LastStep, mfa.UpdatedAt = &at, step, time.Now()
+	r.mfa[userID] = mfa
+	r.replaceCodes(userID, hashes)
+	return nil
+}
+
+func (r *MemoryMFARepository) UseTOTPStep(ctx context.Context, userID uint, step int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mfa, ok := r.mfa[userID]
+	if !ok || mfa.LastStep >= step {
+		return apperrors.Conflict("otp", errTOTPStepUsed)
+	}
+	mfa.LastStep, mfa.UpdatedAt = step, time.Now()
+	r.mfa[userID] = mfa
+	return nil
+}
+
+func (r *MemoryMFARepository) DeleteMFA(ctx context.Context, userID uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.mfa[userID]; !ok {
+		return apperrors.NotFoundField("mfa", errMFANotFound)
+	}
+	delete(r.mfa, userID)
+	r.deleteCodes(userID)
+	return nil
+}
+
+func (r *MemoryMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, hashes []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.replaceCodes(userID, hashes)
+	return nil
+}
+
+func (r *MemoryMFARepository) UseRecoveryCode(ctx context.Context, userID uint, hash string, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, ok := r.codes[hash]
+	if !ok || code.UserID != userID || code.UsedAt != nil {
+		return apperrors.NotFoundField("recovery_code", errRecoveryCodeNotFound)
+	}
+	code.UsedAt = &at
+	r.codes[hash] = code
+	return nil
+}
+
+func (r *MemoryMFARepository) CountRecoveryCodes(ctx context.Context, userID uint) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, code := range r.codes {
+		if code.UserID == userID && code.UsedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *MemoryMFARepository) replaceCodes(userID uint, hashes []string) {
+	r.deleteCodes(userID)
+	now := time.Now()
+	for _, hash := range hashes {
+		r.codes[hash] = models.RecoveryCode{Hash: hash, UserID: userID, CreatedAt: now}
+	}
+}
+
+func (r *MemoryMFARepository) deleteCodes(userID uint) {
+	for hash, code := range r.codes {
+		if code.UserID == userID {
+			delete(r.codes, hash)
+		}
+	}
+}
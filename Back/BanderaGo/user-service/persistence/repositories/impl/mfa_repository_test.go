@@ -0,0 +1,80 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: ambos repositorios confirman el segundo factor una vez y consumen cada código una sola vez
+func TestMFARepositories(t *testing.T) {
+	repos := map[string]func(t *testing.T) repositories.MFARepository{
+		"memoria": func(t *testing.T) repositories.MFARepository { return NewMemoryMFARepository() },
+		"sqlite": func(t *testing.T) repositories.MFARepository {
+			db := newSQLiteGormDB(t)
+			require.NoError(t, NewUserRepository(db).CreateUser(context.Background(), &models.User{Name: "Ana"}))
+			return NewMFARepository(db)
+		},
+	}
+
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			ctx := context.Background()
+			now := time.Now().UTC().Truncate(time.Millisecond)
+
+			_, err := repo.GetMFA(ctx, 1)
+			assert.ErrorIs(t, err, apperrors.ErrNotFound)
+			assert.Equal(t, "mfa", apperrors.FieldOf(err))
+			assert.ErrorIs(t, repo.ConfirmMFA(ctx, 1, 10, now, nil), apperrors.ErrNotFound)
+
+			require.NoError(t, repo.SaveMFA(ctx, &models.UserMFA{UserID: 1, Secret: "SECRETO"}))
+			mfa, err := repo.GetMFA(ctx, 1)
+			require.NoError(t, err)
+			assert.False(t, mfa.Enabled())
+			assert.Equal(t, "SECRETO", mfa.Secret)
+
+			require.NoError(t, repo.ConfirmMFA(ctx, 1, 10, now, []string{"c1", "c2"}))
+			assert.ErrorIs(t, repo.ConfirmMFA(ctx, 1, 11, now, nil), apperrors.ErrConflict)
+			mfa, err = repo.GetMFA(ctx, 1)
+			require.NoError(t, err)
+			assert.True(t, mfa.Enabled())
+			assert.Equal(t, int64(10), mfa.LastStep)
+
+			// Un periodo ya aceptado, o uno anterior, no se acepta otra vez
+			assert.ErrorIs(t, repo.UseTOTPStep(ctx, 1, 10), apperrors.ErrConflict)
+			require.NoError(t, repo.UseTOTPStep(ctx, 1, 12))
+			assert.ErrorIs(t, repo.UseTOTPStep(ctx, 1, 11), apperrors.ErrConflict)
+
+			count, err := repo.CountRecoveryCodes(ctx, 1)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), count)
+			require.NoError(t, repo.UseRecoveryCode(ctx, 1, "c1", now))
+			err = repo.UseRecoveryCode(ctx, 1, "c1", now)
+			assert.ErrorIs(t, err, apperrors.ErrNotFound)
+			assert.Equal(t, "recovery_code", apperrors.FieldOf(err))
+			assert.ErrorIs(t, repo.UseRecoveryCode(ctx, 2, "c2", now), apperrors.ErrNotFound)
+			count, err = repo.CountRecoveryCodes(ctx, 1)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), count)
+
+			require.NoError(t, repo.ReplaceRecoveryCodes(ctx, 1, []string{"c3", "c4", "c5"}))
+			assert.ErrorIs(t, repo.UseRecoveryCode(ctx, 1, "c2", now), apperrors.ErrNotFound)
+			count, err = repo.CountRecoveryCodes(ctx, 1)
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), count)
+
+			require.NoError(t, repo.DeleteMFA(ctx, 1))
+			assert.ErrorIs(t, repo.DeleteMFA(ctx, 1), apperrors.ErrNotFound)
+			count, err = repo.CountRecoveryCodes(ctx, 1)
+			require.NoError(t, err)
+			assert.Zero(t, count)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	errOIDCClientNotFound        = errors.New("el cliente de OpenID Connect no existe")
+	errAuthorizationCodeNotFound = errors.New("el código de autorización no existe, venció o ya se usó")
+)
+
+type OIDCRepositoryImpl struct {
+	db repositories.GormDB
+}
+
+func NewOIDCRepository(db repositories.GormDB) *OIDCRepositoryImpl {
+	return &OIDCRepositoryImpl{db: db}
+}
+
+func (r *OIDCRepositoryImpl) GetClients(ctx context.Context) ([]*models.OIDCClient, error) {
+	var clients []*models.OIDCClient
+	if err := conn(ctx, r.db).Order("created_at, id").Find(&clients).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return clients, nil
+}
+
+func (r *OIDCRepositoryImpl) GetClient(ctx context.Context, id string) (*models.OIDCClient, error) {
+	var client models.OIDCClient
+	if err := conn(ctx, r.db).Where("id = ?", id).First(&client).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFoundField("client_id", errOIDCClientNotFound)
+		}
+		return nil, translateError(err)
+	}
+	return &client, nil
+}
+
+func (r *OIDCRepositoryImpl) CreateClient(ctx context.Context, client *models.OIDCClient) error {
+	err := translateError(conn(ctx, r.db).Create(client).Error)
+	if errors.Is(err, apperrors.ErrConflict) {
+		return apperrors.Conflict("client_id", err)
+	}
+	return err
+}
+
+func (r *OIDCRepositoryImpl) UpdateClient(ctx context.Context, client *models.OIDCClient) error {
+	result := conn(ctx, r.db).Model(&models.OIDCClient{}).Where("id = ?", client.ID).
+		Select("name", "redirect_uris", "secret_hash", "updated_at").Updates(client)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NotFoundField("client_id", errOIDCClientNotFound)
+	}
+	return nil
+}
+
+func (r *OIDCRepositoryImpl) DeleteClient(ctx context.Context, id string) error {
+	return conn(ctx, r.db).Transaction(func(tx repositories.GormDB) error {
+		if err := tx.Where("client_id = ?", id).Delete(&models.AuthorizationCode{}).Error; err != nil {
+			return translateError(err)
+		}
+		result := tx.Where("id = ?", id).Delete(&models.OIDCClient{})
+		if result.Error != nil {
+			return translateError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return apperrors.NotFoundField("client_id", errOIDCClientNotFound)
+		}
+		return nil
+	})
+}
+
+func (r *OIDCRepositoryImpl) CreateAuthorizationCode(ctx context.Context, code *models.AuthorizationCode) error {
+	return conn(ctx, r.db).Transaction(func(tx repositories.GormDB) error {
+		if err := tx.Where("expires_at <= ?", time.Now().UTC()).Delete(&models.AuthorizationCode{}).Error; err != nil {
+			return translateError(err)
+		}
+		return translateError(tx.Create(code).Error)
+	})
+}
+
+func (r *OIDCRepositoryImpl) UseAuthorizationCode(ctx context.Context, hash string, at time.Time) (*models.AuthorizationCode, error) {
+	var code models.AuthorizationCode
+	err := conn(ctx, r.db).Transaction(func(tx repositories.GormDB) error {
+		// La condición sobre used_at impide que dos solicitudes canjeen el mismo código
+		result := tx.Model(&models.AuthorizationCode{}).Where("hash = ? AND used_at IS NULL AND expires_at > ?", hash, at).
+			Updates(map[string]interface{}{"used_at": at})
+		if result.Error != nil {
+			return translateError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return apperrors.NotFoundField("code", errAuthorizationCodeNotFound)
+		}
+		return translateError(tx.Where("hash = ?", hash).First(&code).Error)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+func (r *OIDCRepositoryImpl) GetSigningKeys(ctx context.Context) ([]*models.SigningKey, error) {
+	var keys []*models.SigningKey
+	if err := conn(ctx, r.db).Order("created_at DESC, id").Find(&keys).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return keys, nil
+}
+
+func (r *OIDCRepositoryImpl) RotateSigningKeys(ctx context.Context, key *models.SigningKey, purgeBefore time.Time) error {
+	return conn(ctx, r.db).Transaction(func(tx repositories.GormDB) error {
+		if err := tx.Where("retired_at IS NOT NULL AND retired_at < ?", purgeBefore).Delete(&models.SigningKey{}).Error; err != nil {
+			return translateError(err)
+		}
+		err := tx.Model(&models.SigningKey{}).Where("retired_at IS NULL").
+			Updates(map[string]interface{}{"retired_at": key.CreatedAt}).Error
+		if err != nil {
+			return translateError(err)
+		}
+		return translateError(tx.Create(key).Error)
+	})
+}
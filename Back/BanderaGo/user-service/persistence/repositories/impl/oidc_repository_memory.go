@@ -0,0 +1,191 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+var errDuplicateClientID = errors.New("el client_id ya existe")
+
+// MemoryOIDCRepository guarda los clientes, los códigos y las llaves en memoria con la
+// misma semántica que OIDCRepositoryImpl
+type MemoryOIDCRepository struct {
+	mu      sync.RWMutex
+	clients []models.OIDCClient
+	codes   map[string]models.AuthorizationCode
+	keys    []models.SigningKey
+}
+
+func NewMemoryOIDCRepository() *MemoryOIDCRepository {
+	return &MemoryOIDCRepository{codes: map[string]models.AuthorizationCode{}}
+}
+
+func (r *MemoryOIDCRepository) GetClients(ctx context.Context) ([]*models.OIDCClient, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clients := make([]*models.OIDCClient, 0, len(r.clients))
+	for _, client := range r.clients {
+		clients = append(clients, cloneOIDCClient(client))
+	}
+	return clients, nil
+}
+
+func (r *MemoryOIDCRepository) GetClient(ctx context.Context, id string) (*models.OIDCClient, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	index := slices.IndexFunc(r.clients, func(client models.OIDCClient) bool { return client.ID == id })
+	if index < 0 {
+		return nil, apperrors.NotFoundField("client_id", errOIDCClientNotFound)
+	}
+	return cloneOIDCClient(r.clients[index]), nil
+}
+
+func (r *MemoryOIDCRepository) CreateClient(ctx context.Context, client *models.OIDCClient) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if slices.ContainsFunc(r.clients, func(stored models.OIDCClient) bool { return stored.ID == client.ID }) {
+		return apperrors.Conflict("client_id", errDuplicateClientID)
+	}
+	now := time.Now()
+	client.CreatedAt, client.UpdatedAt = now, now
+	r.clients = append(r.clients, *cloneOIDCClient(*client))
+	return nil
+}
+
+func (r *MemoryOIDCRepository) UpdateClient(ctx context.Context, client *models.OIDCClient) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index := slices.IndexFunc(r.clients, func(stored models.OIDCClient) bool { return stored.ID == client.ID })
+	if index < 0 {
+		return apperrors.NotFoundField("client_id", errOIDCClientNotFound)
+	}
+	stored := &r.clients[index]
+	stored.Name, stored.RedirectURIs = client.Name, slices.Clone(client.RedirectURIs)
+	stored.SecretHash = client.SecretHash
+	stored.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *MemoryOIDCRepository) DeleteClient(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index := slices.IndexFunc(r.clients, func(client models.OIDCClient) bool { return client.ID == id })
+	if index < 0 {
+		return apperrors.NotFoundField("client_id", errOIDCClientNotFound)
+	}
+	r.clients = slices.Delete(r.clients, index, index+1)
+	for hash, code := range r.codes {
+		if code.ClientID == id {
+			delete(r.codes, hash)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryOIDCRepository) CreateAuthorizationCode(ctx context.Context, code *models.AuthorizationCode) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for hash, stored := range r.codes {
+		if !stored.ExpiresAt.After(now) {
+			delete(r.codes, hash)
+		}
+	}
+	code.CreatedAt = now
+	stored := *code
+	stored.UsedAt = cloneTime(code.UsedAt)
+	r.codes[code.Hash] = stored
+	return nil
+}
+
+func (r *MemoryOIDCRepository) UseAuthorizationCode(ctx context.Context, hash string, at time.Time) (*models.AuthorizationCode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.codes[hash]
+	if !ok || stored.UsedAt != nil || !stored.ExpiresAt.After(at) {
+		return nil, apperrors.NotFoundField("code", errAuthorizationCodeNotFound)
+	}
+	stored.UsedAt = &at
+	r.codes[hash] = stored
+	code := stored
+	code.UsedAt = cloneTime(stored.UsedAt)
+	return &code, nil
+}
+
+func (r *MemoryOIDCRepository) GetSigningKeys(ctx context.Context) ([]*models.SigningKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*models.SigningKey, 0, len(r.keys))
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		keys = append(keys, cloneSigningKey(r.keys[i]))
+	}
+	return keys, nil
+}
+
+func (r *MemoryOIDCRepository) RotateSigningKeys(ctx context.Context, key *models.SigningKey, purgeBefore time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.keys = slices.DeleteFunc(r.keys, func(stored models.SigningKey) bool {
+		return stored.RetiredAt != nil && stored.RetiredAt.Before(purgeBefore)
+	})
+	for i := range r.keys {
+		if r.keys[i].RetiredAt == nil {
+			retiredAt := key.CreatedAt
+			r.keys[i].RetiredAt = &retiredAt
+		}
+	}
+	r.keys = append(r.keys, *cloneSigningKey(*key))
+	return nil
+}
+
+func cloneOIDCClient(client models.OIDCClient) *models.OIDCClient {
+	client.RedirectURIs = slices.Clone(client.RedirectURIs)
+	return &client
+}
+
+func cloneSigningKey(key models.SigningKey) *models.SigningKey {
+	key.PublicKey, key.PrivateKey = slices.Clone(key.PublicKey), slices.Clone(key.PrivateKey)
+	key.RetiredAt = cloneTime(key.RetiredAt)
+	return &key
+}
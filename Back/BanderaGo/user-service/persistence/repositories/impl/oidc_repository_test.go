@@ -0,0 +1,99 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOIDCRepositories() map[string]func(t *testing.T) repositories.OIDCRepository {
+	return map[string]func(t *testing.T) repositories.OIDCRepository{
+		"memoria": func(t *testing.T) repositories.OIDCRepository { return NewMemoryOIDCRepository() },
+		"sqlite": func(t *testing.T) repositories.OIDCRepository {
+			db := newSQLiteGormDB(t)
+			require.NoError(t, NewUserRepository(db).CreateUser(context.Background(), &models.User{Name: "Ana"}))
+			return NewOIDCRepository(db)
+		},
+	}
+}
+
+// Caso de prueba: ambos repositorios canjean cada código una vez y lo eliminan junto con su cliente
+func TestOIDCRepositoriesClientsAndCodes(t *testing.T) {
+	for name, newRepo := range newOIDCRepositories() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			ctx := context.Background()
+			now := time.Now().UTC().Truncate(time.Millisecond)
+			hash := "hash"
+
+			client := &models.OIDCClient{ID: "portal", Name: "Portal", SecretHash: &hash, RedirectURIs: []string{"https://portal.example.com/callback"}}
+			require.NoError(t, repo.CreateClient(ctx, client))
+			assert.ErrorIs(t, repo.CreateClient(ctx, &models.OIDCClient{ID: "portal", Name: "Otro", RedirectURIs: []string{}}), apperrors.ErrConflict)
+
+			client.Name, client.SecretHash = "Portal interno", nil
+			client.RedirectURIs = append(client.RedirectURIs, "http://localhost:3000/callback")
+			require.NoError(t, repo.UpdateClient(ctx, client))
+			stored, err := repo.GetClient(ctx, "portal")
+			require.NoError(t, err)
+			assert.Equal(t, "Portal interno", stored.Name)
+			assert.True(t, stored.Public())
+			assert.Len(t, stored.RedirectURIs, 2)
+
+			newCode := func(hash string, expiresAt time.Time) {
+				require.NoError(t, repo.CreateAuthorizationCode(ctx, &models.AuthorizationCode{Hash: hash, ClientID: "portal", UserID: 1,
+					RedirectURI: "https://portal.example.com/callback", Scope: "openid", CodeChallenge: "reto", AuthTime: now, ExpiresAt: expiresAt}))
+			}
+			newCode("c1", now.Add(time.Minute))
+			_, err = repo.UseAuthorizationCode(ctx, "c1", now.Add(time.Minute))
+			assert.ErrorIs(t, err, apperrors.ErrNotFound, "vencido")
+			code, err := repo.UseAuthorizationCode(ctx, "c1", now)
+			require.NoError(t, err)
+			assert.Equal(t, "reto", code.CodeChallenge)
+			_, err = repo.UseAuthorizationCode(ctx, "c1", now)
+			assert.ErrorIs(t, err, apperrors.ErrNotFound, "ya usado")
+			assert.Equal(t, "code", apperrors.FieldOf(err))
+
+			newCode("c2", now.Add(time.Minute))
+			require.NoError(t, repo.DeleteClient(ctx, "portal"))
+			_, err = repo.UseAuthorizationCode(ctx, "c2", now)
+			assert.ErrorIs(t, err, apperrors.ErrNotFound, "cliente eliminado")
+			_, err = repo.GetClient(ctx, "portal")
+			assert.ErrorIs(t, err, apperrors.ErrNotFound)
+			assert.ErrorIs(t, repo.DeleteClient(ctx, "portal"), apperrors.ErrNotFound)
+		})
+	}
+}
+
+// Caso de prueba: rotar retira la llave activa y elimina las retiradas antes de la retención
+func TestOIDCRepositoriesSigningKeys(t *testing.T) {
+	for name, newRepo := range newOIDCRepositories() {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			ctx := context.Background()
+			now := time.Now().UTC().Truncate(time.Millisecond)
+			rotate := func(id string, at time.Time) {
+				require.NoError(t, repo.RotateSigningKeys(ctx, &models.SigningKey{ID: id, Algorithm: "RS256", PublicKey: []byte("pub"),
+					PrivateKey: []byte("priv"), CreatedAt: at}, at.Add(-time.Hour)))
+			}
+
+			rotate("k1", now.Add(-3*time.Hour))
+			rotate("k2", now.Add(-2*time.Hour))
+			rotate("k3", now)
+			keys, err := repo.GetSigningKeys(ctx)
+			require.NoError(t, err)
+			require.Len(t, keys, 2)
+			assert.Equal(t, "k3", keys[0].ID)
+			assert.Nil(t, keys[0].RetiredAt)
+			assert.Equal(t, "k2", keys[1].ID)
+			require.NotNil(t, keys[1].RetiredAt)
+			assert.True(t, keys[1].RetiredAt.Equal(now))
+			assert.Equal(t, []byte("pub"), keys[1].PublicKey)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"errors"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+var errRoleNotFound = errors.New("el rol no existe")
+
+type RoleRepositoryImpl struct {
+	db repositories.GormDB
+}
+
+func NewRoleRepository(db repositories.GormDB) *RoleRepositoryImpl {
+	return &RoleRepositoryImpl{db: db}
+}
+
+func (r *RoleRepositoryImpl) GetRoles(ctx context.Context) ([]*models.Role, error) {
+	var roles []*models.Role
+	if err := conn(ctx, r.db).Order("name").Find(&roles).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return roles, nil
+}
+
+func (r *RoleRepositoryImpl) GetRole(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	if err := conn(ctx, r.db).Where("name = ?", name).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFoundField("role", errRoleNotFound)
+		}
+		return nil, translateError(err)
+	}
+	return &role, nil
+}
+
+func (r *RoleRepositoryImpl) CreateRole(ctx context.Context, role *models.Role) error {
+	err := translateError(conn(ctx, r.db).Create(role).Error)
+	if errors.Is(err, apperrors.ErrConflict) {
+		return apperrors.Conflict("name", err)
+	}
+	return err
+}
+
+func (r *RoleRepositoryImpl) UpdateRole(ctx context.Context, role *models.Role) error {
+	result := conn(ctx, r.db).Model(&models.Role{}).Where("name = ?", role.Name).
+		Select("description", "permissions", "updated_at").Updates(role)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NotFoundField("role", errRoleNotFound)
+	}
+	return nil
+}
+
+func (r *RoleRepositoryImpl) DeleteRole(ctx context.Context, name string) error {
+	return conn(ctx, r.db).Transaction(func(tx repositories.GormDB) error {
+		if err := tx.Where("role = ?", name).Delete(&models.UserRole{}).Error; err != nil {
+			return translateError(err)
+		}
+		result := tx.Where("name = ?", name).Delete(&models.Role{})
+		if result.Error != nil {
+			return translateError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return apperrors.NotFoundField("role", errRoleNotFound)
+		}
+		return nil
+	})
+}
+
+func (r *RoleRepositoryImpl) GetUserRoles(ctx context.Context, userID uint) ([]string, error) {
+	var assignments []models.UserRole
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("role").Find(&assignments).Error; err != nil {
+		return nil, translateError(err)
+	}
+	roles := make([]string, 0, len(assignments))
+	for _, assignment := range assignments {
+		roles = append(roles, assignment.Role)
+	}
+	return roles, nil
+}
+
+func (r *RoleRepositoryImpl) GetRoleMembers(ctx context.Context, role string) ([]uint, error) {
+	var assignments []models.UserRole
+	if err := conn(ctx, r.db).Where("role = ?", role).Order("user_id").Find(&assignments).Error; err != nil {
+		return nil, translateError(err)
+	}
+	members := make([]uint, 0, len(assignments))
+	for _, assignment := range assignments {
+		members = append(members, assignment.UserID)
+	}
+	return members, nil
+}
+
+func (r *RoleRepositoryImpl) AssignRole(ctx context.Context, userID uint, role string) error {
+	if _, err := r.GetRole(ctx, role); err != nil {
+		return err
+	}
+	var count int64
+	if err := conn(ctx, r.db).Model(&models.UserRole{}).Where("user_id = ? AND role = ?", userID, role).Count(&count).Error; err != nil {
+		return translateError(err)
+	}
+	if count > 0 {
+		return nil
+	}
+	return translateError(conn(ctx, r.db).Create(&models.UserRole{UserID: userID, Role: role}).Error)
+}
+
+func (r *RoleRepositoryImpl) RevokeRole(ctx context.Context, userID uint, role string) error {
+	return translateError(conn(ctx, r.db).Where("user_id = ? AND role = ?", userID, role).Delete(&models.UserRole{}).Error)
+}
+
+func (r *RoleRepositoryImpl) GetPermissions(ctx context.Context, roles []string) ([]string, error) {
+	if len(roles) == 0 {
+		return []string{}, nil
+	}
+	var found []*models.Role
+	if err := conn(ctx, r.db).Where("name IN ?", roles).Find(&found).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return mergePermissions(found), nil
+}
+
+// mergePermissions une los permisos de los roles sin repetirlos
+func mergePermissions(roles []*models.Role) []string {
+	permissions := []string{}
+	for _, role := range roles {
+		for _, permission := range role.Permissions {
+			if !slices.Contains(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	slices.Sort(permissions)
+	return permissions
+}
@@ -0,0 +1,196 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+var errDuplicateRole = errors.New("el rol ya existe")
+
+// MemoryRoleRepository guarda los roles en memoria con la misma semántica que
+// RoleRepositoryImpl. Inicia con los roles que crea la migración.
+type MemoryRoleRepository struct {
+	mu        sync.RWMutex
+	roles     map[string]models.Role
+	userRoles map[uint][]string
+}
+
+func NewMemoryRoleRepository() *MemoryRoleRepository {
+	now := time.Now()
+	return &MemoryRoleRepository{
+		roles: map[string]models.Role{
+			models.RoleAdmin: {Name: models.RoleAdmin, Description: "Administración completa de usuarios y roles",
+				Permissions: slices.Clone(models.Permissions), CreatedAt: now, UpdatedAt: now},
+			models.RoleAuditor: {Name: models.RoleAuditor, Description: "Consulta de usuarios y de la auditoría",
+				Permissions: []string{models.PermissionUsersRead, models.PermissionAuditRead}, CreatedAt: now, UpdatedAt: now},
+		},
+		userRoles: make(map[uint][]string),
+	}
+}
+
+func (r *MemoryRoleRepository) GetRoles(ctx context.Context) ([]*models.Role, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	roles := make([]*models.Role, 0, len(r.roles))
+	for _, role := range r.roles {
+		roles = append(roles, cloneRole(role))
+	}
+	slices.SortFunc(roles, func(a, b *models.Role) int { return cmp.Compare(a.Name, b.Name) })
+	return roles, nil
+}
+
+func (r *MemoryRoleRepository) GetRole(ctx context.Context, name string) (*models.Role, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	role, ok := r.roles[name]
+	if !ok {
+		return nil, apperrors.NotFoundField("role", errRoleNotFound)
+	}
+	return cloneRole(role), nil
+}
+
+func (r *MemoryRoleRepository) CreateRole(ctx context.Context, role *models.Role) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.roles[role.Name]; exists {
+		return apperrors.Conflict("name", errDuplicateRole)
+	}
+	now := time.Now()
+	role.CreatedAt, role.UpdatedAt = now, now
+	r.roles[role.Name] = *cloneRole(*role)
+	return nil
+}
+
+func (r *MemoryRoleRepository) UpdateRole(ctx context.Context, role *models.Role) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.roles[role.Name]
+	if !ok {
+		return apperrors.NotFoundField("role", errRoleNotFound)
+	}
+	stored.Description, stored.Permissions = role.Description, slices.Clone(role.Permissions)
+	stored.UpdatedAt = time.Now()
+	r.roles[role.Name] = stored
+	return nil
+}
+
+func (r *MemoryRoleRepository) DeleteRole(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.roles[name]; !ok {
+		return apperrors.NotFoundField("role", errRoleNotFound)
+	}
+	delete(r.roles, name)
+	for userID, roles := range r.userRoles {
+		r.userRoles[userID] = slices.DeleteFunc(roles, func(role string) bool { return role == name })
+	}
+	return nil
+}
+
+func (r *MemoryRoleRepository) GetUserRoles(ctx context.Context, userID uint) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	roles := slices.Clone(r.userRoles[userID])
+	if roles == nil {
+		roles = []string{}
+	}
+	return roles, nil
+}
+
+func (r *MemoryRoleRepository) GetRoleMembers(ctx context.Context, role string) ([]uint, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	members := []uint{}
+	for userID, roles := range r.userRoles {
+		if slices.Contains(roles, role) {
+			members = append(members, userID)
+		}
+	}
+	slices.Sort(members)
+	return members, nil
+}
+
+func (r *MemoryRoleRepository) AssignRole(ctx context.Context, userID uint, role string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.roles[role]; !ok {
+		return apperrors.NotFoundField("role", errRoleNotFound)
+	}
+	roles := r.userRoles[userID]
+	if !slices.Contains(roles, role) {
+		roles = append(roles, role)
+		slices.Sort(roles)
+		r.userRoles[userID] = roles
+	}
+	return nil
+}
+
+func (r *MemoryRoleRepository) RevokeRole(ctx context.Context, userID uint, role string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.userRoles[userID] = slices.DeleteFunc(r.userRoles[userID], func(assigned string) bool { return assigned == role })
+	return nil
+}
+
+func (r *MemoryRoleRepository) GetPermissions(ctx context.Context, roles []string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found []*models.Role
+	for _, name := range roles {
+		if role, ok := r.roles[name]; ok {
+			found = append(found, cloneRole(role))
+		}
+	}
+	return mergePermissions(found), nil
+}
+
+func cloneRole(role models.Role) *models.Role {
+	role.Permissions = slices.Clone(role.Permissions)
+	return &role
+}
@@ -0,0 +1,88 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: ambos repositorios guardan roles, los asignan y unen sus permisos
+func TestRoleRepositories(t *testing.T) {
+	repos := map[string]func(t *testing.T) repositories.RoleRepository{
+		"memoria": func(t *testing.T) repositories.RoleRepository { return NewMemoryRoleRepository() },
+		"sqlite": func(t *testing.T) repositories.RoleRepository {
+			// La migración crea los mismos roles iniciales que el repositorio en memoria
+			repo := NewRoleRepository(newSQLiteGormDB(t))
+			for _, role := range []string{models.RoleAdmin, models.RoleAuditor} {
+				require.NoError(t, repo.CreateRole(context.Background(), &models.Role{Name: role, Permissions: []string{}}))
+			}
+			return repo
+		},
+	}
+
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			ctx := context.Background()
+			require.NoError(t, repo.DeleteRole(ctx, models.RoleAdmin))
+			require.NoError(t, repo.DeleteRole(ctx, models.RoleAuditor))
+			assert.ErrorIs(t, repo.DeleteRole(ctx, models.RoleAdmin), apperrors.ErrNotFound)
+
+			for _, role := range []*models.Role{
+				{Name: "support", Permissions: []string{models.PermissionUsersRead, models.PermissionUsersUpdate}},
+				{Name: "auditor", Description: "Lectura", Permissions: []string{models.PermissionAuditRead, models.PermissionUsersRead}},
+			} {
+				require.NoError(t, repo.CreateRole(ctx, role))
+			}
+			err := repo.CreateRole(ctx, &models.Role{Name: "auditor", Permissions: []string{}})
+			assert.ErrorIs(t, err, apperrors.ErrConflict)
+			assert.Equal(t, "name", apperrors.FieldOf(err))
+
+			roles, err := repo.GetRoles(ctx)
+			require.NoError(t, err)
+			require.Len(t, roles, 2)
+			assert.Equal(t, "auditor", roles[0].Name)
+			assert.Equal(t, []string{models.PermissionAuditRead, models.PermissionUsersRead}, roles[0].Permissions)
+
+			// Asignar es idempotente y los permisos se unen sin repetirse
+			require.NoError(t, repo.AssignRole(ctx, 7, "support"))
+			require.NoError(t, repo.AssignRole(ctx, 7, "auditor"))
+			require.NoError(t, repo.AssignRole(ctx, 7, "auditor"))
+			err = repo.AssignRole(ctx, 7, "nadie")
+			assert.ErrorIs(t, err, apperrors.ErrNotFound)
+			assert.Equal(t, "role", apperrors.FieldOf(err))
+
+			assigned, err := repo.GetUserRoles(ctx, 7)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"auditor", "support"}, assigned)
+			require.NoError(t, repo.AssignRole(ctx, 3, "auditor"))
+			members, err := repo.GetRoleMembers(ctx, "auditor")
+			require.NoError(t, err)
+			assert.Equal(t, []uint{3, 7}, members)
+			permissions, err := repo.GetPermissions(ctx, append(assigned, "nadie"))
+			require.NoError(t, err)
+			assert.Equal(t, []string{models.PermissionAuditRead, models.PermissionUsersRead, models.PermissionUsersUpdate}, permissions)
+
+			// Actualizar reemplaza los permisos
+			require.NoError(t, repo.UpdateRole(ctx, &models.Role{Name: "support", Description: "Soporte", Permissions: []string{models.PermissionUsersCreate}}))
+			support, err := repo.GetRole(ctx, "support")
+			require.NoError(t, err)
+			assert.Equal(t, "Soporte", support.Description)
+			assert.Equal(t, []string{models.PermissionUsersCreate}, support.Permissions)
+			assert.ErrorIs(t, repo.UpdateRole(ctx, &models.Role{Name: "nadie"}), apperrors.ErrNotFound)
+
+			// Retirar un rol, incluso uno que no tiene, y eliminar un rol lo quita de los usuarios
+			require.NoError(t, repo.RevokeRole(ctx, 7, "support"))
+			require.NoError(t, repo.RevokeRole(ctx, 7, "support"))
+			require.NoError(t, repo.DeleteRole(ctx, "auditor"))
+			assigned, err = repo.GetUserRoles(ctx, 7)
+			require.NoError(t, err)
+			assert.Empty(t, assigned)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	errSessionNotFound      = errors.New("la sesión no existe")
+	errRefreshTokenNotFound = errors.New("el refresh token no existe")
+	errRefreshTokenUsed     = errors.New("el refresh token ya se usó")
+)
+
+type SessionRepositoryImpl struct {
+	db repositories.GormDB
+}
+
+func NewSessionRepository(db repositories.GormDB) *SessionRepositoryImpl {
+	return &SessionRepositoryImpl{db: db}
+}
+
+func (r *SessionRepositoryImpl) CreateSession(ctx context.Context, session *models.Session, tokenHash string) error {
+	return conn(ctx, r.db).Transaction(func(tx repositories.GormDB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return translateError(err)
+		}
+		return translateError(tx.Create(&models.RefreshToken{Hash: tokenHash, SessionID: session.ID}).Error)
+	})
+}
+
+func (r *SessionRepositoryImpl) GetSession(ctx context.Context, id uint) (*models.Session, error) {
+	var session models.Session
+	if err := conn(ctx, r.db).Where("id = ?", id).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFoundField("session", errSessionNotFound)
+		}
+		return nil, translateError(err)
+	}
+	return &session, nil
+}
+
+func (r *SessionRepositoryImpl) GetUserSessions(ctx context.Context, userID uint, now time.Time) ([]*models.Session, error) {
+	var sessions []*models.Session
+	err := conn(ctx, r.db).Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_used_at DESC, id DESC").Find(&sessions).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return sessions, nil
+}
+
+func (r *SessionRepositoryImpl) GetRefreshToken(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := conn(ctx, r.db).Where("hash = ?", hash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFoundField("refresh_token", errRefreshTokenNotFound)
+		}
+		return nil, translateError(err)
+	}
+	return &token, nil
+}
+
+func (r *SessionRepositoryImpl) RotateRefreshToken(ctx context.Context, oldHash, newHash string, session *models.Session) error {
+	return conn(ctx, r.db).Transaction(func(tx repositories.GormDB) error {
+		// La condición sobre used_at hace que de dos renovaciones simultáneas solo gane una
+		result := tx.Model(&models.RefreshToken{}).Where("hash = ? AND used_at IS NULL", oldHash).
+			Updates(map[string]interface{}{"used_at": session.LastUsedAt})
+		if result.Error != nil {
+			return translateError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return apperrors.Conflict("refresh_token", errRefreshTokenUsed)
+		}
+		if err := tx.Create(&models.RefreshToken{Hash: newHash, SessionID: session.ID}).Error; err != nil {
+			return translateError(err)
+		}
+		return translateError(tx.Model(&models.Session{}).Where("id = ?", session.ID).
+			Select("user_agent", "ip", "last_used_at", "expires_at").Updates(session).Error)
+	})
+}
+
+func (r *SessionRepositoryImpl) RevokeSession(ctx context.Context, id uint, at time.Time) error {
+	if _, err := r.GetSession(ctx, id); err != nil {
+		return err
+	}
+	return translateError(conn(ctx, r.db).Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).Updates(map[string]interface{}{"revoked_at": at}).Error)
+}
+
+func (r *SessionRepositoryImpl) RevokeUserSessions(ctx context.Context, userID uint, at time.Time) error {
+	return translateError(conn(ctx, r.db).Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).Updates(map[string]interface{}{"revoked_at": at}).Error)
+}
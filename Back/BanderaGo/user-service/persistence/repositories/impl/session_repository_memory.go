@@ -0,0 +1,161 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemorySessionRepository guarda las sesiones en memoria con la misma semántica que
+// SessionRepositoryImpl
+type MemorySessionRepository struct {
+	mu       sync.RWMutex
+	sessions []models.Session
+	tokens   map[string]models.RefreshToken
+	nextID   uint
+}
+
+func NewMemorySessionRepository() *MemorySessionRepository {
+	return &MemorySessionRepository{tokens: map[string]models.RefreshToken{}, nextID: 1}
+}
+
+func (r *MemorySessionRepository) CreateSession(ctx context.Context, session *models.Session, tokenHash string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tokens[tokenHash]; ok {
+		return apperrors.Conflict("refresh_token", errRefreshTokenUsed)
+	}
+	now := time.Now()
+	session.ID = r.nextID
+	session.CreatedAt = now
+	r.nextID++
+	r.sessions = append(r.sessions, *cloneSession(*session))
+	r.tokens[tokenHash] = models.RefreshToken{Hash: tokenHash, SessionID: session.ID, CreatedAt: now}
+	return nil
+}
+
+func (r *MemorySessionRepository) GetSession(ctx context.Context, id uint) (*models.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	index := r.index(id)
+	if index < 0 {
+		return nil, apperrors.NotFoundField("session", errSessionNotFound)
+	}
+	return cloneSession(r.sessions[index]), nil
+}
+
+func (r *MemorySessionRepository) GetUserSessions(ctx context.Context, userID uint, now time.Time) ([]*models.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sessions := []*models.Session{}
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.Active(now) {
+			sessions = append(sessions, cloneSession(session))
+		}
+	}
+	slices.SortStableFunc(sessions, func(a, b *models.Session) int {
+		if c := b.LastUsedAt.Compare(a.LastUsedAt); c != 0 {
+			return c
+		}
+		return int(b.ID) - int(a.ID)
+	})
+	return sessions, nil
+}
+
+func (r *MemorySessionRepository) GetRefreshToken(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	token, ok := r.tokens[hash]
+	if !ok {
+		return nil, apperrors.NotFoundField("refresh_token", errRefreshTokenNotFound)
+	}
+	token.UsedAt = cloneTime(token.UsedAt)
+	return &token, nil
+}
+
+func (r *MemorySessionRepository) RotateRefreshToken(ctx context.Context, oldHash, newHash string, session *models.Session) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.tokens[oldHash]
+	if !ok || old.UsedAt != nil {
+		return apperrors.Conflict("refresh_token", errRefreshTokenUsed)
+	}
+	if _, ok := r.tokens[newHash]; ok {
+		return apperrors.Conflict("refresh_token", errRefreshTokenUsed)
+	}
+	usedAt := session.LastUsedAt
+	old.UsedAt = &usedAt
+	r.tokens[oldHash] = old
+	r.tokens[newHash] = models.RefreshToken{Hash: newHash, SessionID: session.ID, CreatedAt: time.Now()}
+	if index := r.index(session.ID); index >= 0 {
+		stored := &r.sessions[index]
+		stored.UserAgent, stored.IP = session.UserAgent, session.IP
+		stored.LastUsedAt, stored.ExpiresAt = session.LastUsedAt, session.ExpiresAt
+	}
+	return nil
+}
+
+func (r *MemorySessionRepository) RevokeSession(ctx context.Context, id uint, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index := r.index(id)
+	if index < 0 {
+		return apperrors.NotFoundField("session", errSessionNotFound)
+	}
+	if r.sessions[index].RevokedAt == nil {
+		r.sessions[index].RevokedAt = &at
+	}
+	return nil
+}
+
+func (r *MemorySessionRepository) RevokeUserSessions(ctx context.Context, userID uint, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.sessions {
+		if r.sessions[i].UserID == userID && r.sessions[i].RevokedAt == nil {
+			revokedAt := at
+			r.sessions[i].RevokedAt = &revokedAt
+		}
+	}
+	return nil
+}
+
+func (r *MemorySessionRepository) index(id uint) int {
+	return slices.IndexFunc(r.sessions, func(session models.Session) bool { return session.ID == id })
+}
+
+func cloneSession(session models.Session) *models.Session {
+	session.RevokedAt = cloneTime(session.RevokedAt)
+	return &session
+}
@@ -0,0 +1,91 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: ambos repositorios rotan refresh tokens una sola vez y revocan sesiones
+func TestSessionRepositories(t *testing.T) {
+	repos := map[string]func(t *testing.T) repositories.SessionRepository{
+		"memoria": func(t *testing.T) repositories.SessionRepository { return NewMemorySessionRepository() },
+		"sqlite": func(t *testing.T) repositories.SessionRepository {
+			db := newSQLiteGormDB(t)
+			require.NoError(t, NewUserRepository(db).CreateUser(context.Background(), &models.User{Name: "Ana"}))
+			return NewSessionRepository(db)
+		},
+	}
+
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			ctx := context.Background()
+			now := time.Now().UTC().Truncate(time.Millisecond)
+
+			laptop := &models.Session{UserID: 1, UserAgent: "Firefox", IP: "10.0.0.1", LastUsedAt: now, ExpiresAt: now.Add(time.Hour)}
+			require.NoError(t, repo.CreateSession(ctx, laptop, "h1"))
+			phone := &models.Session{UserID: 1, UserAgent: "Android", IP: "10.0.0.2", LastUsedAt: now.Add(time.Second), ExpiresAt: now.Add(time.Hour)}
+			require.NoError(t, repo.CreateSession(ctx, phone, "h2"))
+			expired := &models.Session{UserID: 1, LastUsedAt: now, ExpiresAt: now.Add(-time.Minute)}
+			require.NoError(t, repo.CreateSession(ctx, expired, "h3"))
+
+			sessions, err := repo.GetUserSessions(ctx, 1, now)
+			require.NoError(t, err)
+			require.Len(t, sessions, 2)
+			assert.Equal(t, []uint{phone.ID, laptop.ID}, []uint{sessions[0].ID, sessions[1].ID})
+
+			token, err := repo.GetRefreshToken(ctx, "h1")
+			require.NoError(t, err)
+			assert.Equal(t, laptop.ID, token.SessionID)
+			assert.Nil(t, token.UsedAt)
+			_, err = repo.GetRefreshToken(ctx, "nada")
+			assert.ErrorIs(t, err, apperrors.ErrNotFound)
+			assert.Equal(t, "refresh_token", apperrors.FieldOf(err))
+
+			// Rotar marca el token como usado; volver a usarlo es un conflicto
+			laptop.LastUsedAt, laptop.ExpiresAt, laptop.IP = now.Add(2*time.Second), now.Add(2*time.Hour), "10.0.0.9"
+			require.NoError(t, repo.RotateRefreshToken(ctx, "h1", "h4", laptop))
+			assert.ErrorIs(t, repo.RotateRefreshToken(ctx, "h1", "h5", laptop), apperrors.ErrConflict)
+			token, err = repo.GetRefreshToken(ctx, "h1")
+			require.NoError(t, err)
+			assert.NotNil(t, token.UsedAt)
+			token, err = repo.GetRefreshToken(ctx, "h4")
+			require.NoError(t, err)
+			assert.Equal(t, laptop.ID, token.SessionID)
+			stored, err := repo.GetSession(ctx, laptop.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "10.0.0.9", stored.IP)
+			assert.Equal(t, "Firefox", stored.UserAgent)
+			assert.True(t, now.Add(2*time.Hour).Equal(stored.ExpiresAt))
+
+			// Revocar una sesión es idempotente y la quita del listado
+			require.NoError(t, repo.RevokeSession(ctx, laptop.ID, now))
+			require.NoError(t, repo.RevokeSession(ctx, laptop.ID, now.Add(time.Minute)))
+			stored, err = repo.GetSession(ctx, laptop.ID)
+			require.NoError(t, err)
+			require.NotNil(t, stored.RevokedAt)
+			assert.True(t, now.Equal(*stored.RevokedAt))
+			sessions, err = repo.GetUserSessions(ctx, 1, now)
+			require.NoError(t, err)
+			require.Len(t, sessions, 1)
+			assert.Equal(t, phone.ID, sessions[0].ID)
+
+			require.NoError(t, repo.RevokeUserSessions(ctx, 1, now))
+			sessions, err = repo.GetUserSessions(ctx, 1, now)
+			require.NoError(t, err)
+			assert.Empty(t, sessions)
+
+			_, err = repo.GetSession(ctx, 99)
+			assert.ErrorIs(t, err, apperrors.ErrNotFound)
+			assert.Equal(t, "session", apperrors.FieldOf(err))
+			assert.ErrorIs(t, repo.RevokeSession(ctx, 99, now), apperrors.ErrNotFound)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package impl
+
+import (
+	"application/persistence/repositories"
+	"context"
+)
+
+type txKey struct{}
+
+// GormTransactor abre transacciones de GORM y las propaga a los repositorios en el contexto
+type GormTransactor struct {
+	db repositories.GormDB
+}
+
+func NewGormTransactor(db repositories.GormDB) *GormTransactor {
+	return &GormTransactor{db: db}
+}
+
+// WithinTransaction ejecuta fn en una transacción; si el contexto ya tiene una, fn se une a ella
+func (t *GormTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(repositories.GormDB); ok {
+		return fn(ctx)
+	}
+	err := t.db.WithContext(ctx).Transaction(func(tx repositories.GormDB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+	return translateError(err)
+}
+
+// conn devuelve la transacción del contexto o, si no hay, la conexión del repositorio
+func conn(ctx context.Context, db repositories.GormDB) repositories.GormDB {
+	if tx, ok := ctx.Value(txKey{}).(repositories.GormDB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// MemoryTransactor acompaña a los repositorios en memoria: cada operación es atómica pero
+// un error no revierte las anteriores, lo que basta para desarrollo y pruebas
+type MemoryTransactor struct{}
+
+func NewMemoryTransactor() *MemoryTransactor {
+	return &MemoryTransactor{}
+}
+
+func (t *MemoryTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -0,0 +1,77 @@
+package impl
+
+import (
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newSQLiteGormDB abre una base SQLite en memoria con las tablas de usuarios, versiones y auditoría
+func newSQLiteGormDB(t *testing.T) repositories.GormDB {
+	t.Helper()
+	t.Setenv("DB_TABLE", "users")
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.UserVersion{}, &models.AuditRecord{}, &models.Role{}, &models.UserRole{}, &models.APIKey{}, &models.Session{}, &models.RefreshToken{}, &models.UserMFA{}, &models.RecoveryCode{}, &models.EmailToken{}, &models.OIDCClient{}, &models.AuthorizationCode{}, &models.SigningKey{}))
+	return repositories.NewGormDB(db)
+}
+
+// Caso de prueba: un error revierte el usuario y la auditoría escritos en la transacción
+func TestGormTransactorRollback(t *testing.T) {
+	db := newSQLiteGormDB(t)
+	users, audit, tx := NewUserRepository(db), NewAuditRepository(db), NewGormTransactor(db)
+	ctx := context.Background()
+	failure := errors.New("fallo")
+
+	err := tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		require.NoError(t, users.CreateUser(ctx, &models.User{Name: "John"}))
+		require.NoError(t, audit.CreateAuditRecord(ctx, &models.AuditRecord{UserID: 1, Operation: models.AuditCreate}))
+		// Una transacción anidada se une a la exterior
+		return tx.WithinTransaction(ctx, func(ctx context.Context) error {
+			return failure
+		})
+	})
+	assert.ErrorIs(t, err, failure)
+
+	page, err := audit.GetAuditRecords(ctx, repositories.AuditQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Zero(t, page.Total)
+	_, err = users.GetUserByID(ctx, 1)
+	assert.Error(t, err)
+}
+
+// Caso de prueba: al confirmar la transacción los cambios quedan visibles fuera de ella
+func TestGormTransactorCommit(t *testing.T) {
+	db := newSQLiteGormDB(t)
+	users, audit, tx := NewUserRepository(db), NewAuditRepository(db), NewGormTransactor(db)
+	ctx := context.Background()
+
+	err := tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := users.CreateUser(ctx, &models.User{Name: "John"}); err != nil {
+			return err
+		}
+		return audit.CreateAuditRecord(ctx, &models.AuditRecord{UserID: 1, Actor: "alice", Operation: models.AuditCreate,
+			Changes: map[string]models.FieldChange{"name": {Before: nil, After: "John"}}})
+	})
+	require.NoError(t, err)
+
+	page, err := audit.GetAuditRecords(ctx, repositories.AuditQuery{UserID: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Records, 1)
+	assert.Equal(t, "alice", page.Records[0].Actor)
+	assert.Equal(t, "John", page.Records[0].Changes["name"].After)
+	_, err = users.GetUserByID(ctx, 1)
+	assert.NoError(t, err)
+}
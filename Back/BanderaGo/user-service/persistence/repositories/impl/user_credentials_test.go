@@ -0,0 +1,66 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: ambos repositorios buscan por correo o nombre de usuario y guardan la contraseña sin cambiar la versión
+func TestUserRepositoriesCredentials(t *testing.T) {
+	repos := map[string]func(t *testing.T) repositories.UserRepository{
+		"memoria": func(t *testing.T) repositories.UserRepository { return NewMemoryUserRepository() },
+		"sqlite":  func(t *testing.T) repositories.UserRepository { return NewUserRepository(newSQLiteGormDB(t)) },
+	}
+
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			ctx := context.Background()
+			email, username := "ana@example.com", "ana"
+			user := &models.User{Name: "Ana", Email: &email, Username: &username}
+			require.NoError(t, repo.CreateUser(ctx, user))
+			require.NoError(t, repo.CreateUser(ctx, &models.User{Name: "Luis"}))
+
+			for _, login := range []string{email, username} {
+				found, err := repo.GetUserByLogin(ctx, login)
+				require.NoError(t, err, login)
+				assert.Equal(t, user.ID, found.ID)
+			}
+			_, err := repo.GetUserByLogin(ctx, "luis")
+			assert.ErrorIs(t, err, apperrors.ErrNotFound)
+
+			hash, changedAt := "$argon2id$hash", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+			user.PasswordHash, user.PasswordChangedAt = &hash, &changedAt
+			require.NoError(t, repo.UpdatePassword(ctx, user))
+
+			found, err := repo.GetUserByID(ctx, user.ID)
+			require.NoError(t, err)
+			require.NotNil(t, found.PasswordHash)
+			assert.Equal(t, hash, *found.PasswordHash)
+			assert.True(t, changedAt.Equal(*found.PasswordChangedAt))
+			assert.Equal(t, uint(1), found.Version)
+
+			// Solo se verifica el correo vigente del usuario
+			assert.ErrorIs(t, repo.VerifyEmail(ctx, user.ID, "otro@example.com", changedAt), apperrors.ErrNotFound)
+			require.NoError(t, repo.VerifyEmail(ctx, user.ID, email, changedAt))
+			found, err = repo.GetUserByID(ctx, user.ID)
+			require.NoError(t, err)
+			require.NotNil(t, found.EmailVerifiedAt)
+			assert.True(t, changedAt.Equal(*found.EmailVerifiedAt))
+			assert.Equal(t, uint(1), found.Version)
+
+			// Los usuarios eliminados no inician sesión ni cambian su contraseña
+			require.NoError(t, repo.DeleteUser(ctx, user.ID))
+			_, err = repo.GetUserByLogin(ctx, email)
+			assert.ErrorIs(t, err, apperrors.ErrNotFound)
+			assert.ErrorIs(t, repo.UpdatePassword(ctx, user), apperrors.ErrNotFound)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package impl
+
+import (
+	"application/persistence/repositories"
+	"strings"
+)
+
+// likeEscaper escapa los comodines de LIKE usando '!', que se comporta igual en todos
+// los motores a diferencia de la barra invertida.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+func likePrefix(value string) string {
+	return likeEscaper.Replace(value) + "%"
+}
+
+// likeOperator devuelve el operador de búsqueda por prefijo sin distinguir mayúsculas:
+// LIKE ya se comporta así en MySQL y SQLite, mientras que PostgreSQL requiere ILIKE.
+func likeOperator(db repositories.GormDB) string {
+	if db.Dialect() == "postgres" {
+		return "ILIKE"
+	}
+	return "LIKE"
+}
+
+func filterUsers(db repositories.GormDB, filter repositories.UserFilter) repositories.GormDB {
+	switch filter.Deleted {
+	case repositories.DeletedInclude:
+		db = db.Unscoped()
+	case repositories.DeletedOnly:
+		db = db.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	like := likeOperator(db)
+	if filter.Name != "" {
+		db = db.Where("name "+like+" ? ESCAPE '!'", likePrefix(filter.Name))
+	}
+	if filter.LastName != "" {
+		db = db.Where("last_name "+like+" ? ESCAPE '!'", likePrefix(filter.LastName))
+	}
+	if filter.Email != "" {
+		db = db.Where("email = ?", filter.Email)
+	}
+	if filter.Username != "" {
+		db = db.Where("username = ?", filter.Username)
+	}
+	if filter.Phone != "" {
+		db = db.Where("phone = ?", filter.Phone)
+	}
+	if filter.CreatedAfter != nil {
+		db = db.Where("created_at > ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		db = db.Where("created_at < ?", *filter.CreatedBefore)
+	}
+	return db
+}
+
+// orderClause devuelve la cláusula ORDER BY de una columna; al recorrer hacia atrás
+// se invierte la dirección y el resultado se reordena después.
+func orderClause(field repositories.SortField, backward bool) string {
+	if field.Desc != backward {
+		return field.Column + " DESC"
+	}
+	return field.Column + " ASC"
+}
+
+// keysetCondition construye la condición que selecciona los registros posteriores
+// (o anteriores, si el cursor va hacia atrás) al cursor según el ordenamiento, p. ej.
+// para last_name ASC, id ASC: (last_name > ?) OR (last_name = ? AND id > ?).
+// Las columnas provienen de la lista permitida, por lo que se interpolan sin riesgo.
+func keysetCondition(sort []repositories.SortField, cursor *repositories.Cursor) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	for i, field := range sort {
+		var parts []string
+		for _, previous := range sort[:i] {
+			parts = append(parts, previous.Column+" = ?")
+			args = append(args, cursor.Value(previous.Column))
+		}
+
+		operator := " > ?"
+		if field.Desc != cursor.Backward {
+			operator = " < ?"
+		}
+		parts = append(parts, field.Column+operator)
+		args = append(args, cursor.Value(field.Column))
+
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return strings.Join(clauses, " OR "), args
+}
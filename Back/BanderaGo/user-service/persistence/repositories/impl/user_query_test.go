@@ -0,0 +1,61 @@
+package impl
+
+import (
+	"application/persistence/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Caso de prueba: los comodines de LIKE se escapan
+func TestLikePrefix(t *testing.T) {
+	assert.Equal(t, "Ana%", likePrefix("Ana"))
+	assert.Equal(t, "50!%!_off!!%", likePrefix("50%_off!"))
+}
+
+// Caso de prueba: dirección del ordenamiento según el sentido del recorrido
+func TestOrderClause(t *testing.T) {
+	assert.Equal(t, "name ASC", orderClause(repositories.SortField{Column: "name"}, false))
+	assert.Equal(t, "name DESC", orderClause(repositories.SortField{Column: "name", Desc: true}, false))
+	assert.Equal(t, "name DESC", orderClause(repositories.SortField{Column: "name"}, true))
+	assert.Equal(t, "name ASC", orderClause(repositories.SortField{Column: "name", Desc: true}, true))
+}
+
+// Caso de prueba: condición de llaves hacia adelante con ordenamiento mixto
+func TestKeysetConditionForward(t *testing.T) {
+	createdAt := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+	sort := []repositories.SortField{{Column: "last_name"}, {Column: "created_at", Desc: true}, {Column: "id"}}
+	cursor := &repositories.Cursor{ID: 5, LastName: "Doe", CreatedAt: createdAt}
+
+	condition, args := keysetCondition(sort, cursor)
+
+	assert.Equal(t, "(last_name > ?) OR (last_name = ? AND created_at < ?) OR (last_name = ? AND created_at = ? AND id > ?)", condition)
+	assert.Equal(t, []interface{}{"Doe", "Doe", createdAt, "Doe", createdAt, uint(5)}, args)
+}
+
+// Caso de prueba: condición de llaves hacia atrás invierte las comparaciones
+func TestKeysetConditionBackward(t *testing.T) {
+	sort := []repositories.SortField{{Column: "name", Desc: true}, {Column: "id"}}
+	cursor := &repositories.Cursor{ID: 5, Name: "Ana", Backward: true}
+
+	condition, args := keysetCondition(sort, cursor)
+
+	assert.Equal(t, "(name > ?) OR (name = ? AND id < ?)", condition)
+	assert.Equal(t, []interface{}{"Ana", "Ana", uint(5)}, args)
+}
+
+// postgresDBMock reporta el dialecto de PostgreSQL
+type postgresDBMock struct {
+	GormDBMock
+}
+
+func (m *postgresDBMock) Dialect() string {
+	return "postgres"
+}
+
+// Caso de prueba: PostgreSQL usa ILIKE para no distinguir mayúsculas
+func TestLikeOperator(t *testing.T) {
+	assert.Equal(t, "LIKE", likeOperator(new(GormDBMock)))
+	assert.Equal(t, "ILIKE", likeOperator(new(postgresDBMock)))
+}
@@ -1,10 +1,19 @@
 package impl
 
 import (
+	"application/apperrors"
 	"application/models"
 	"application/persistence/repositories"
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+// userColumns son las columnas que reemplaza UpdateUser
+var userColumns = []string{"name", "last_name", "email", "email_verified_at", "username", "phone", "display_name"}
+
 type UserRepositoryImpl struct {
 	db repositories.GormDB
 }
@@ -13,38 +22,201 @@ func NewUserRepository(db repositories.GormDB) *UserRepositoryImpl {
 	return &UserRepositoryImpl{db: db}
 }
 
-func (r *UserRepositoryImpl) CreateUser(user *models.User) error {
-	return r.db.Create(user).Error
+func (r *UserRepositoryImpl) CreateUser(ctx context.Context, user *models.User) error {
+	return translateError(conn(ctx, r.db).Create(user).Error)
+}
+
+func (r *UserRepositoryImpl) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := conn(ctx, r.db).First(&user, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
+}
+
+func (r *UserRepositoryImpl) GetUserByIDUnscoped(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := conn(ctx, r.db).Unscoped().First(&user, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
 }
 
-func (r *UserRepositoryImpl) GetUserByID(id uint) (*models.User, error) {
+func (r *UserRepositoryImpl) GetUserByLogin(ctx context.Context, login string) (*models.User, error) {
 	var user models.User
-	if err := r.db.First(&user, id).Error; err != nil {
-		return nil, err
+	if err := conn(ctx, r.db).Where("email = ? OR username = ?", login, login).First(&user).Error; err != nil {
+		return nil, translateError(err)
 	}
 	return &user, nil
 }
 
-func (r *UserRepositoryImpl) GetAllUsers() ([]*models.User, error) {
+func (r *UserRepositoryImpl) GetAllUsers(ctx context.Context, query repositories.UserQuery) (*repositories.UserPage, error) {
+	var total int64
+	if err := filterUsers(conn(ctx, r.db).Model(&models.User{}), query.Filter).Count(&total).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	db := filterUsers(conn(ctx, r.db).Model(&models.User{}), query.Filter)
+	backward := query.Cursor != nil && query.Cursor.Backward
+	if query.Cursor != nil {
+		condition, args := keysetCondition(query.Sort, query.Cursor)
+		db = db.Where(condition, args...)
+	} else if query.Offset > 0 {
+		db = db.Offset(query.Offset)
+	}
+	for _, field := range query.Sort {
+		db = db.Order(orderClause(field, backward))
+	}
+
+	// Se pide un registro adicional para saber si existe otra página
 	var users []*models.User
-	if err := r.db.Find(&users).Error; err != nil {
-		return nil, err
+	if err := db.Limit(query.Limit + 1).Find(&users).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	hasMore := len(users) > query.Limit
+	if hasMore {
+		users = users[:query.Limit]
+	}
+	if backward {
+		for i, j := 0, len(users)-1; i < j; i, j = i+1, j-1 {
+			users[i], users[j] = users[j], users[i]
+		}
+	}
+
+	return &repositories.UserPage{Users: users, Total: total, HasMore: hasMore}, nil
+}
+
+func (r *UserRepositoryImpl) UpdateUser(ctx context.Context, id uint, user *models.User) error {
+	user.ID = id
+	return r.PatchUser(ctx, user, userColumns)
+}
+
+// PatchUser escribe únicamente las columnas indicadas (además de updated_at) siempre que
+// el usuario conserve la versión con la que se leyó, e incrementa la versión.
+func (r *UserRepositoryImpl) PatchUser(ctx context.Context, user *models.User, columns []string) error {
+	db := conn(ctx, r.db)
+	version := user.Version
+
+	user.Version++
+	result := db.Model(user).Where("version = ?", version).Select(append([]string{"version"}, columns...)).Updates(user)
+	if result.Error != nil {
+		user.Version = version
+		return translateError(result.Error)
 	}
-	return users, nil
+	if result.RowsAffected == 0 {
+		user.Version = version
+		// Distinguir un usuario eliminado de uno modificado por otra solicitud
+		var current models.User
+		if err := db.First(&current, user.ID).Error; err != nil {
+			return translateError(err)
+		}
+		return apperrors.PreconditionFailed(fmt.Errorf("se esperaba la versión %d y la actual es %d", version, current.Version))
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) UpdatePassword(ctx context.Context, user *models.User) error {
+	result := conn(ctx, r.db).Model(user).Select("password_hash", "password_changed_at").Updates(user)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NotFound(gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) VerifyEmail(ctx context.Context, id uint, email string, at time.Time) error {
+	result := conn(ctx, r.db).Model(&models.User{}).Where("id = ? AND email = ?", id, email).
+		Updates(map[string]interface{}{"email_verified_at": at})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NotFound(gorm.ErrRecordNotFound)
+	}
+	return nil
 }
 
-func (r *UserRepositoryImpl) UpdateUser(id uint, updatedUser *models.User) error {
+// DeleteUser marca al usuario como eliminado e incrementa la versión, como RestoreUser
+func (r *UserRepositoryImpl) DeleteUser(ctx context.Context, id uint) error {
+	result := conn(ctx, r.db).Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"deleted_at": time.Now(),
+		"version":    gorm.Expr("version + 1"),
+	})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NotFound(gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// RestoreUser limpia deleted_at e incrementa la versión. Restaurar un usuario que no
+// está eliminado no tiene efecto.
+func (r *UserRepositoryImpl) RestoreUser(ctx context.Context, id uint) (*models.User, error) {
+	db := conn(ctx, r.db)
+
 	var user models.User
-	if err := r.db.First(&user, id).Error; err != nil {
-		return err
+	if err := db.Unscoped().First(&user, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	if !user.DeletedAt.Valid {
+		return &user, nil
 	}
 
-	user.Name = updatedUser.Name
-	user.LastName = updatedUser.LastName
+	result := db.Unscoped().Model(&user).Where("deleted_at IS NOT NULL").Updates(map[string]interface{}{
+		"deleted_at": nil,
+		"version":    gorm.Expr("version + 1"),
+	})
+	if result.Error != nil {
+		return nil, translateError(result.Error)
+	}
+	if result.RowsAffected > 0 {
+		user.DeletedAt = gorm.DeletedAt{}
+		user.Version++
+		return &user, nil
+	}
 
-	return r.db.Save(&user).Error
+	// Otra solicitud lo restauró primero
+	user = models.User{}
+	if err := db.First(&user, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
 }
 
-func (r *UserRepositoryImpl) DeleteUser(id uint) error {
-	return r.db.Delete(&models.User{}, id).Error
+func (r *UserRepositoryImpl) PurgeUser(ctx context.Context, id uint) error {
+	result := conn(ctx, r.db).Unscoped().Delete(&models.User{}, id)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NotFound(gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// PurgeDeletedUsers elimina uno a uno los usuarios vencidos; si otra solicitud restauró
+// alguno mientras tanto, ese se conserva y no se devuelve
+func (r *UserRepositoryImpl) PurgeDeletedUsers(ctx context.Context, before time.Time) ([]*models.User, error) {
+	db := conn(ctx, r.db)
+
+	var users []*models.User
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Find(&users).Error; err != nil {
+		return nil, translateError(err)
+	}
+	purged := make([]*models.User, 0, len(users))
+	for _, user := range users {
+		result := db.Unscoped().Where("deleted_at IS NOT NULL").Delete(&models.User{}, user.ID)
+		if result.Error != nil {
+			return nil, translateError(result.Error)
+		}
+		if result.RowsAffected > 0 {
+			purged = append(purged, user)
+		}
+	}
+	return purged, nil
 }
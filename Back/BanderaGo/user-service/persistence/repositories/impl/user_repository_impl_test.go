@@ -1,13 +1,19 @@
 package impl
 
 import (
+	"context"
+	"database/sql/driver"
 	"errors"
 	"testing"
+	"time"
 
+	"application/apperrors"
 	"application/models"
+	"application/persistence/repositories"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
@@ -15,6 +21,76 @@ type GormDBMock struct {
 	mock.Mock
 }
 
+// WithContext implements repositories.GormDB.
+func (m *GormDBMock) WithContext(ctx context.Context) repositories.GormDB {
+	args := m.Called(ctx)
+	return args.Get(0).(repositories.GormDB)
+}
+
+// Dialect implements repositories.GormDB.
+func (m *GormDBMock) Dialect() string {
+	return "mysql"
+}
+
+// Unscoped implements repositories.GormDB.
+func (m *GormDBMock) Unscoped() repositories.GormDB {
+	args := m.Called()
+	return args.Get(0).(repositories.GormDB)
+}
+
+// Transaction implements repositories.GormDB.
+func (m *GormDBMock) Transaction(fn func(tx repositories.GormDB) error) error {
+	return fn(m)
+}
+
+// Model implements repositories.GormDB.
+func (m *GormDBMock) Model(value interface{}) repositories.GormDB {
+	args := m.Called(value)
+	return args.Get(0).(repositories.GormDB)
+}
+
+// Where implements repositories.GormDB.
+func (m *GormDBMock) Where(query interface{}, conds ...interface{}) repositories.GormDB {
+	args := m.Called(query, conds)
+	return args.Get(0).(repositories.GormDB)
+}
+
+// Order implements repositories.GormDB.
+func (m *GormDBMock) Order(value interface{}) repositories.GormDB {
+	args := m.Called(value)
+	return args.Get(0).(repositories.GormDB)
+}
+
+// Limit implements repositories.GormDB.
+func (m *GormDBMock) Limit(limit int) repositories.GormDB {
+	args := m.Called(limit)
+	return args.Get(0).(repositories.GormDB)
+}
+
+// Offset implements repositories.GormDB.
+func (m *GormDBMock) Offset(offset int) repositories.GormDB {
+	args := m.Called(offset)
+	return args.Get(0).(repositories.GormDB)
+}
+
+// Select implements repositories.GormDB.
+func (m *GormDBMock) Select(query interface{}, args ...interface{}) repositories.GormDB {
+	called := m.Called(query, args)
+	return called.Get(0).(repositories.GormDB)
+}
+
+// Updates implements repositories.GormDB.
+func (m *GormDBMock) Updates(values interface{}) *gorm.DB {
+	args := m.Called(values)
+	return args.Get(0).(*gorm.DB)
+}
+
+// Count implements repositories.GormDB.
+func (m *GormDBMock) Count(count *int64) *gorm.DB {
+	args := m.Called(count)
+	return args.Get(0).(*gorm.DB)
+}
+
 // Create implements repositories.GormDB.
 func (m *GormDBMock) Create(value interface{}) *gorm.DB {
 	args := m.Called(value)
@@ -48,12 +124,13 @@ func (m *GormDBMock) Save(value interface{}) *gorm.DB {
 func TestCreateUser(t *testing.T) {
 	mockDB := new(GormDBMock)
 	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
 
 	user := &models.User{Name: "John", LastName: "Doe"}
 
 	mockDB.On("Create", user).Return(&gorm.DB{})
 
-	err := repo.CreateUser(user)
+	err := repo.CreateUser(context.Background(), user)
 	assert.NoError(t, err)
 	mockDB.AssertExpectations(t)
 }
@@ -61,6 +138,7 @@ func TestCreateUser(t *testing.T) {
 func TestGetUserByID(t *testing.T) {
 	mockDB := new(GormDBMock)
 	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
 
 	user := &models.User{Model: gorm.Model{ID: 1}, Name: "John", LastName: "Doe"}
 
@@ -72,7 +150,7 @@ func TestGetUserByID(t *testing.T) {
 		*arg = *user
 	})
 
-	result, err := repo.GetUserByID(1)
+	result, err := repo.GetUserByID(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.Equal(t, user, result)
 	mockDB.AssertExpectations(t)
@@ -81,12 +159,20 @@ func TestGetUserByID(t *testing.T) {
 func TestGetAllUsers(t *testing.T) {
 	mockDB := new(GormDBMock)
 	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+	mockDB.On("Model", mock.Anything).Return(mockDB)
 
 	users := []*models.User{
 		{Model: gorm.Model{ID: 1}, Name: "John", LastName: "Doe"},
 		{Model: gorm.Model{ID: 2}, Name: "Jane", LastName: "Doe"},
 	}
 
+	// Simular el conteo total y la consulta de la página
+	mockDB.On("Count", mock.Anything).Return(&gorm.DB{Error: nil}).Run(func(args mock.Arguments) {
+		*args.Get(0).(*int64) = 2
+	})
+	mockDB.On("Order", "id ASC").Return(mockDB)
+	mockDB.On("Limit", 11).Return(mockDB)
 	mockDB.On("Find", mock.Anything, mock.Anything).Return(&gorm.DB{
 		// Simular que no hubo error en la operación
 		Error: nil,
@@ -95,45 +181,192 @@ func TestGetAllUsers(t *testing.T) {
 		*arg = users
 	})
 
-	result, err := repo.GetAllUsers()
+	query := repositories.UserQuery{Sort: []repositories.SortField{{Column: "id"}}, Limit: 10}
+	result, err := repo.GetAllUsers(context.Background(), query)
 	assert.NoError(t, err)
-	assert.Equal(t, users, result)
+	assert.Equal(t, users, result.Users)
+	assert.Equal(t, int64(2), result.Total)
+	assert.False(t, result.HasMore)
 	mockDB.AssertExpectations(t)
+	mockDB.AssertNotCalled(t, "Offset", mock.Anything)
 }
 
-func TestUpdateUser(t *testing.T) {
+func TestGetAllUsersFilteredPage(t *testing.T) {
 	mockDB := new(GormDBMock)
 	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+	mockDB.On("Model", mock.Anything).Return(mockDB)
 
-	user := &models.User{Model: gorm.Model{ID: 1}, Name: "John", LastName: "Doe"}
+	createdAfter := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	users := []*models.User{
+		{Model: gorm.Model{ID: 3}, Name: "Ana"},
+		{Model: gorm.Model{ID: 4}, Name: "Andrés"},
+		{Model: gorm.Model{ID: 5}, Name: "Antonio"},
+	}
 
-	mockDB.On("First", mock.Anything, mock.Anything).Return(&gorm.DB{
-		// Simular que la búsqueda del usuario existente no generó errores y devolvió el usuario
-		Error: nil,
-	}).Run(func(args mock.Arguments) {
-		arg := args.Get(0).(*models.User)
-		*arg = *user
+	// Verificar que los filtros, el orden y el desplazamiento se traduzcan a SQL
+	mockDB.On("Where", "name LIKE ? ESCAPE '!'", []interface{}{"An%"}).Return(mockDB)
+	mockDB.On("Where", "username = ?", []interface{}{"ana"}).Return(mockDB)
+	mockDB.On("Where", "created_at > ?", []interface{}{createdAfter}).Return(mockDB)
+	mockDB.On("Count", mock.Anything).Return(&gorm.DB{Error: nil}).Run(func(args mock.Arguments) {
+		*args.Get(0).(*int64) = 7
+	})
+	mockDB.On("Offset", 2).Return(mockDB)
+	mockDB.On("Order", "name DESC").Return(mockDB)
+	mockDB.On("Order", "id ASC").Return(mockDB)
+	mockDB.On("Limit", 3).Return(mockDB)
+	mockDB.On("Find", mock.Anything, mock.Anything).Return(&gorm.DB{Error: nil}).Run(func(args mock.Arguments) {
+		*args.Get(0).(*[]*models.User) = users
+	})
+
+	query := repositories.UserQuery{
+		Filter: repositories.UserFilter{Name: "An", Username: "ana", CreatedAfter: &createdAfter},
+		Sort:   []repositories.SortField{{Column: "name", Desc: true}, {Column: "id"}},
+		Limit:  2,
+		Offset: 2,
+	}
+	result, err := repo.GetAllUsers(context.Background(), query)
+
+	// Verificar que el registro adicional solo indique que hay más páginas
+	assert.NoError(t, err)
+	assert.Equal(t, users[:2], result.Users)
+	assert.Equal(t, int64(7), result.Total)
+	assert.True(t, result.HasMore)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetAllUsersBackwardCursor(t *testing.T) {
+	mockDB := new(GormDBMock)
+	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+	mockDB.On("Model", mock.Anything).Return(mockDB)
+
+	// La consulta hacia atrás devuelve los registros en orden inverso
+	users := []*models.User{
+		{Model: gorm.Model{ID: 9}},
+		{Model: gorm.Model{ID: 8}},
+	}
+
+	mockDB.On("Count", mock.Anything).Return(&gorm.DB{Error: nil})
+	mockDB.On("Where", "(id < ?)", []interface{}{uint(10)}).Return(mockDB)
+	mockDB.On("Order", "id DESC").Return(mockDB)
+	mockDB.On("Limit", 3).Return(mockDB)
+	mockDB.On("Find", mock.Anything, mock.Anything).Return(&gorm.DB{Error: nil}).Run(func(args mock.Arguments) {
+		*args.Get(0).(*[]*models.User) = users
 	})
-	mockDB.On("Save", mock.Anything).Return(&gorm.DB{
+
+	query := repositories.UserQuery{
+		Sort:   []repositories.SortField{{Column: "id"}},
+		Limit:  2,
+		Cursor: &repositories.Cursor{ID: 10, Backward: true},
+	}
+	result, err := repo.GetAllUsers(context.Background(), query)
+
+	// Verificar que los registros se devuelvan en el orden solicitado
+	assert.NoError(t, err)
+	assert.Equal(t, uint(8), result.Users[0].ID)
+	assert.Equal(t, uint(9), result.Users[1].ID)
+	assert.False(t, result.HasMore)
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdateUser(t *testing.T) {
+	mockDB := new(GormDBMock)
+	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+
+	user := &models.User{Name: "John", LastName: "Doe", Version: 3}
+
+	// Verificar que la actualización sea condicional sobre la versión leída
+	mockDB.On("Model", user).Return(mockDB)
+	mockDB.On("Where", "version = ?", []interface{}{uint(3)}).Return(mockDB)
+	mockDB.On("Select", append([]string{"version"}, userColumns...), []interface{}(nil)).Return(mockDB)
+	mockDB.On("Updates", user).Return(&gorm.DB{
 		// Simular que la actualización del usuario no generó errores
-		Error: nil,
+		Error:        nil,
+		RowsAffected: 1,
 	})
 
-	err := repo.UpdateUser(1, user)
+	err := repo.UpdateUser(context.Background(), 1, user)
 	assert.NoError(t, err)
+	// Verificar que se actualice el identificador y se incremente la versión
+	assert.Equal(t, uint(1), user.ID)
+	assert.Equal(t, uint(4), user.Version)
 	mockDB.AssertExpectations(t)
+	mockDB.AssertNotCalled(t, "Save", mock.Anything)
 }
 
-func TestDeleteUser(t *testing.T) {
+func TestPatchUser(t *testing.T) {
 	mockDB := new(GormDBMock)
 	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
 
-	mockDB.On("Delete", mock.Anything, mock.Anything).Return(&gorm.DB{
-		// Simular que no hubo error en la operación
-		Error: nil,
+	user := &models.User{Model: gorm.Model{ID: 1}, Name: "John", LastName: "Doe"}
+
+	// Verificar que solo se seleccionen las columnas modificadas
+	mockDB.On("Model", user).Return(mockDB)
+	mockDB.On("Where", "version = ?", []interface{}{uint(0)}).Return(mockDB)
+	mockDB.On("Select", []string{"version", "name"}, []interface{}(nil)).Return(mockDB)
+	mockDB.On("Updates", user).Return(&gorm.DB{Error: nil, RowsAffected: 1})
+
+	err := repo.PatchUser(context.Background(), user, []string{"name"})
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+}
+
+func TestPatchUserNotFound(t *testing.T) {
+	mockDB := new(GormDBMock)
+	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+	mockDB.On("Model", mock.Anything).Return(mockDB)
+	mockDB.On("Where", mock.Anything, mock.Anything).Return(mockDB)
+	mockDB.On("Select", mock.Anything, mock.Anything).Return(mockDB)
+
+	// Simular que el usuario fue eliminado antes de la actualización
+	mockDB.On("Updates", mock.Anything).Return(&gorm.DB{Error: nil, RowsAffected: 0})
+	mockDB.On("First", mock.Anything, mock.Anything).Return(&gorm.DB{Error: gorm.ErrRecordNotFound})
+
+	err := repo.PatchUser(context.Background(), &models.User{Model: gorm.Model{ID: 99}}, []string{"name"})
+
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	mockDB.AssertExpectations(t)
+}
+
+func TestPatchUserError(t *testing.T) {
+	mockDB := new(GormDBMock)
+	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+	mockDB.On("Model", mock.Anything).Return(mockDB)
+	mockDB.On("Where", mock.Anything, mock.Anything).Return(mockDB)
+	mockDB.On("Select", mock.Anything, mock.Anything).Return(mockDB)
+
+	// Simular que la base de datos no está disponible
+	mockDB.On("Updates", mock.Anything).Return(&gorm.DB{Error: driver.ErrBadConn})
+
+	err := repo.PatchUser(context.Background(), &models.User{Model: gorm.Model{ID: 1}}, []string{"last_name"})
+
+	assert.ErrorIs(t, err, apperrors.ErrUnavailable)
+	mockDB.AssertExpectations(t)
+}
+
+func TestDeleteUser(t *testing.T) {
+	mockDB := new(GormDBMock)
+	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+
+	mockDB.On("Model", mock.Anything).Return(mockDB)
+	mockDB.On("Where", "id = ?", []interface{}{uint(1)}).Return(mockDB)
+	mockDB.On("Updates", mock.MatchedBy(func(values map[string]interface{}) bool {
+		// La eliminación marca deleted_at e incrementa la versión
+		_, deleted := values["deleted_at"]
+		return deleted && values["version"] != nil
+	})).Return(&gorm.DB{
+		// Simular que no hubo error en la operación y se eliminó un registro
+		Error:        nil,
+		RowsAffected: 1,
 	})
 
-	err := repo.DeleteUser(1)
+	err := repo.DeleteUser(context.Background(), 1)
 	assert.NoError(t, err)
 	mockDB.AssertExpectations(t)
 }
@@ -142,6 +375,7 @@ func TestDeleteUser(t *testing.T) {
 func TestGetUserByIDError(t *testing.T) {
 	mockDB := new(GormDBMock)
 	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
 
 	// Simular un error al obtener el usuario por ID
 	mockDB.On("First", mock.Anything, mock.Anything).Return(&gorm.DB{
@@ -150,7 +384,7 @@ func TestGetUserByIDError(t *testing.T) {
 	})
 
 	// Llamar al método GetUserByID
-	_, err := repo.GetUserByID(1)
+	_, err := repo.GetUserByID(context.Background(), 1)
 
 	// Verificar que se haya producido un error y que sea el esperado
 	assert.Error(t, err)
@@ -162,6 +396,11 @@ func TestGetUserByIDError(t *testing.T) {
 func TestGetAllUsersError(t *testing.T) {
 	mockDB := new(GormDBMock)
 	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+	mockDB.On("Model", mock.Anything).Return(mockDB)
+	mockDB.On("Count", mock.Anything).Return(&gorm.DB{Error: nil})
+	mockDB.On("Order", mock.Anything).Return(mockDB)
+	mockDB.On("Limit", mock.Anything).Return(mockDB)
 
 	// Simular un error al obtener todos los usuarios
 	mockDB.On("Find", mock.Anything, mock.Anything).Return(&gorm.DB{
@@ -170,7 +409,7 @@ func TestGetAllUsersError(t *testing.T) {
 	})
 
 	// Llamar al método GetAllUsers
-	_, err := repo.GetAllUsers()
+	_, err := repo.GetAllUsers(context.Background(), repositories.UserQuery{Sort: []repositories.SortField{{Column: "id"}}, Limit: 10})
 
 	// Verificar que se haya producido un error y que sea el esperado
 	assert.Error(t, err)
@@ -179,22 +418,228 @@ func TestGetAllUsersError(t *testing.T) {
 	mockDB.AssertExpectations(t)
 }
 
+func TestGetAllUsersCountError(t *testing.T) {
+	mockDB := new(GormDBMock)
+	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+	mockDB.On("Model", mock.Anything).Return(mockDB)
+
+	// Simular que la base de datos no está disponible al contar
+	mockDB.On("Count", mock.Anything).Return(&gorm.DB{Error: driver.ErrBadConn})
+
+	_, err := repo.GetAllUsers(context.Background(), repositories.UserQuery{Limit: 10})
+
+	assert.ErrorIs(t, err, apperrors.ErrUnavailable)
+	mockDB.AssertNotCalled(t, "Find", mock.Anything, mock.Anything)
+}
+
 func TestUpdateUserError(t *testing.T) {
 	mockDB := new(GormDBMock)
 	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+	mockDB.On("Model", mock.Anything).Return(mockDB)
+	mockDB.On("Where", mock.Anything, mock.Anything).Return(mockDB)
+	mockDB.On("Select", mock.Anything, mock.Anything).Return(mockDB)
 
 	// Simular un error al actualizar el usuario
-	mockDB.On("First", mock.Anything, mock.Anything).Return(&gorm.DB{
-		// Simular que la búsqueda del usuario generó un error
-		Error: errors.New("error getting user"),
+	mockDB.On("Updates", mock.Anything).Return(&gorm.DB{
+		// Simular que la actualización del usuario generó un error
+		Error: errors.New("error updating user"),
 	})
 
 	// Llamar al método UpdateUser
-	err := repo.UpdateUser(1, &models.User{})
+	user := &models.User{Version: 2}
+	err := repo.UpdateUser(context.Background(), 1, user)
 
-	// Verificar que se haya producido un error y que sea el esperado
+	// Verificar que se haya producido un error, que sea el esperado y que la versión no cambie
 	assert.Error(t, err)
-	assert.EqualError(t, err, "error getting user")
+	assert.EqualError(t, err, "error updating user")
+	assert.Equal(t, uint(2), user.Version)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestWithContextPropagatesContext(t *testing.T) {
+	mockDB := new(GormDBMock)
+	repo := NewUserRepository(mockDB)
+
+	// Verificar que el contexto de la solicitud llegue a la base de datos
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockDB.On("WithContext", ctx).Return(mockDB)
+	mockDB.On("Model", mock.Anything).Return(mockDB)
+	mockDB.On("Where", "id = ?", []interface{}{uint(1)}).Return(mockDB)
+	mockDB.On("Updates", mock.Anything).Return(&gorm.DB{Error: nil, RowsAffected: 1})
+
+	err := repo.DeleteUser(ctx, 1)
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetUserByIDNotFound(t *testing.T) {
+	mockDB := new(GormDBMock)
+	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+
+	// Simular que el usuario no existe
+	mockDB.On("First", mock.Anything, mock.Anything).Return(&gorm.DB{Error: gorm.ErrRecordNotFound})
+
+	_, err := repo.GetUserByID(context.Background(), 1)
+
+	// Verificar que el error se traduzca a la categoría del dominio
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdateUserNotFound(t *testing.T) {
+	mockDB := new(GormDBMock)
+	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+	mockDB.On("Model", mock.Anything).Return(mockDB)
+	mockDB.On("Where", mock.Anything, mock.Anything).Return(mockDB)
+	mockDB.On("Select", mock.Anything, mock.Anything).Return(mockDB)
+	mockDB.On("Updates", mock.Anything).Return(&gorm.DB{Error: nil, RowsAffected: 0})
+
+	// Simular que el usuario a actualizar no existe
+	mockDB.On("First", mock.Anything, mock.Anything).Return(&gorm.DB{Error: gorm.ErrRecordNotFound})
+
+	err := repo.UpdateUser(context.Background(), 1, &models.User{})
+
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdateUserStaleVersion(t *testing.T) {
+	mockDB := new(GormDBMock)
+	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+	mockDB.On("Model", mock.Anything).Return(mockDB)
+	mockDB.On("Where", mock.Anything, mock.Anything).Return(mockDB)
+	mockDB.On("Select", mock.Anything, mock.Anything).Return(mockDB)
+
+	// Simular que otra solicitud actualizó el usuario después de leerlo
+	mockDB.On("Updates", mock.Anything).Return(&gorm.DB{Error: nil, RowsAffected: 0})
+	mockDB.On("First", mock.Anything, []interface{}{uint(1)}).Return(&gorm.DB{Error: nil}).Run(func(args mock.Arguments) {
+		*args.Get(0).(*models.User) = models.User{Model: gorm.Model{ID: 1}, Version: 5}
+	})
+
+	user := &models.User{Version: 4}
+	err := repo.UpdateUser(context.Background(), 1, user)
+
+	assert.ErrorIs(t, err, apperrors.ErrPreconditionFailed)
+	assert.Equal(t, uint(4), user.Version)
+	mockDB.AssertExpectations(t)
+}
+
+func TestDeleteUserNotFound(t *testing.T) {
+	mockDB := new(GormDBMock)
+	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+
+	// Simular que no se eliminó ningún registro
+	mockDB.On("Model", mock.Anything).Return(mockDB)
+	mockDB.On("Where", "id = ?", []interface{}{uint(99)}).Return(mockDB)
+	mockDB.On("Updates", mock.Anything).Return(&gorm.DB{Error: nil, RowsAffected: 0})
+
+	err := repo.DeleteUser(context.Background(), 99)
+
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	mockDB.AssertExpectations(t)
+}
+
+func TestDeleteUserError(t *testing.T) {
+	mockDB := new(GormDBMock)
+	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+
+	// Simular que la base de datos no está disponible
+	mockDB.On("Model", mock.Anything).Return(mockDB)
+	mockDB.On("Where", "id = ?", []interface{}{uint(1)}).Return(mockDB)
+	mockDB.On("Updates", mock.Anything).Return(&gorm.DB{Error: driver.ErrBadConn})
+
+	err := repo.DeleteUser(context.Background(), 1)
+
+	assert.ErrorIs(t, err, apperrors.ErrUnavailable)
+	mockDB.AssertExpectations(t)
+}
+
+func TestRestoreUser(t *testing.T) {
+	mockDB := new(GormDBMock)
+	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+	mockDB.On("Unscoped").Return(mockDB)
+
+	// Simular un usuario eliminado lógicamente en la versión 2
+	mockDB.On("First", mock.AnythingOfType("*models.User"), []interface{}{uint(1)}).Return(&gorm.DB{}).Run(func(args mock.Arguments) {
+		user := args.Get(0).(*models.User)
+		user.ID, user.Version = 1, 2
+		user.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	})
+	mockDB.On("Model", mock.Anything).Return(mockDB)
+	mockDB.On("Where", "deleted_at IS NOT NULL", mock.Anything).Return(mockDB)
+	mockDB.On("Updates", mock.Anything).Return(&gorm.DB{RowsAffected: 1})
 
+	user, err := repo.RestoreUser(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.False(t, user.DeletedAt.Valid)
+	assert.Equal(t, uint(3), user.Version)
+	mockDB.AssertExpectations(t)
+}
+
+func TestRestoreUserNotDeleted(t *testing.T) {
+	mockDB := new(GormDBMock)
+	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+	mockDB.On("Unscoped").Return(mockDB)
+
+	// Simular un usuario activo: no se escribe nada
+	mockDB.On("First", mock.AnythingOfType("*models.User"), []interface{}{uint(1)}).Return(&gorm.DB{}).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.User).Version = 2
+	})
+
+	user, err := repo.RestoreUser(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), user.Version)
+	mockDB.AssertNotCalled(t, "Updates", mock.Anything)
+}
+
+func TestPurgeUserNotFound(t *testing.T) {
+	mockDB := new(GormDBMock)
+	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+	mockDB.On("Unscoped").Return(mockDB)
+
+	// Simular que no se eliminó ningún registro
+	mockDB.On("Delete", mock.Anything, []interface{}{uint(99)}).Return(&gorm.DB{RowsAffected: 0})
+
+	err := repo.PurgeUser(context.Background(), 99)
+
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	mockDB.AssertExpectations(t)
+}
+
+func TestPurgeDeletedUsers(t *testing.T) {
+	mockDB := new(GormDBMock)
+	repo := NewUserRepository(mockDB)
+	mockDB.On("WithContext", mock.Anything).Return(mockDB)
+	mockDB.On("Unscoped").Return(mockDB)
+
+	before := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+	mockDB.On("Where", "deleted_at IS NOT NULL AND deleted_at < ?", []interface{}{before}).Return(mockDB)
+	mockDB.On("Find", mock.Anything, mock.Anything).Return(&gorm.DB{Error: nil}).Run(func(args mock.Arguments) {
+		*args.Get(0).(*[]*models.User) = []*models.User{{Model: gorm.Model{ID: 1}}, {Model: gorm.Model{ID: 2}}}
+	})
+	mockDB.On("Where", "deleted_at IS NOT NULL", []interface{}(nil)).Return(mockDB)
+	mockDB.On("Delete", mock.Anything, []interface{}{uint(1)}).Return(&gorm.DB{RowsAffected: 1})
+	// Otra solicitud restauró al usuario 2 antes de purgarlo
+	mockDB.On("Delete", mock.Anything, []interface{}{uint(2)}).Return(&gorm.DB{RowsAffected: 0})
+
+	purged, err := repo.PurgeDeletedUsers(context.Background(), before)
+
+	assert.NoError(t, err)
+	require.Len(t, purged, 1)
+	assert.Equal(t, uint(1), purged[0].ID)
 	mockDB.AssertExpectations(t)
 }
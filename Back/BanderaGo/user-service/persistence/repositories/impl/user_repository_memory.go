@@ -0,0 +1,396 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"application/persistence/repositories"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var errDuplicateValue = errors.New("el valor ya está registrado para otro usuario")
+
+// MemoryUserRepository guarda los usuarios en memoria con la misma semántica que el
+// repositorio de GORM (borrado lógico, versiones y paginación), para desarrollo y pruebas.
+type MemoryUserRepository struct {
+	mu     sync.RWMutex
+	users  map[uint]*models.User
+	nextID uint
+}
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{users: make(map[uint]*models.User), nextID: 1}
+}
+
+func (r *MemoryUserRepository) CreateUser(ctx context.Context, user *models.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkUnique(user, 0); err != nil {
+		return err
+	}
+	now := time.Now()
+	user.ID = r.nextID
+	user.CreatedAt, user.UpdatedAt = now, now
+	if err := user.BeforeCreate(nil); err != nil {
+		return err
+	}
+	r.nextID++
+
+	stored := *user
+	r.users[user.ID] = &stored
+	return nil
+}
+
+func (r *MemoryUserRepository) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.find(id)
+	if !ok {
+		return nil, apperrors.NotFound(gorm.ErrRecordNotFound)
+	}
+	user := *stored
+	return &user, nil
+}
+
+func (r *MemoryUserRepository) GetUserByIDUnscoped(ctx context.Context, id uint) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.users[id]
+	if !ok {
+		return nil, apperrors.NotFound(gorm.ErrRecordNotFound)
+	}
+	user := *stored
+	return &user, nil
+}
+
+func (r *MemoryUserRepository) GetUserByLogin(ctx context.Context, login string) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, stored := range r.users {
+		if stored.DeletedAt.Valid {
+			continue
+		}
+		if sameValue(stored.Email, &login) || sameValue(stored.Username, &login) {
+			user := *stored
+			return &user, nil
+		}
+	}
+	return nil, apperrors.NotFound(gorm.ErrRecordNotFound)
+}
+
+func (r *MemoryUserRepository) GetAllUsers(ctx context.Context, query repositories.UserQuery) (*repositories.UserPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var users []*models.User
+	for _, stored := range r.users {
+		if matchesFilter(stored, query.Filter) {
+			user := *stored
+			users = append(users, &user)
+		}
+	}
+	slices.SortFunc(users, func(a, b *models.User) int {
+		return compareCursors(repositories.NewCursor(a, "", false), repositories.NewCursor(b, "", false), query.Sort)
+	})
+	total := int64(len(users))
+
+	var page []*models.User
+	var hasMore bool
+	switch {
+	case query.Cursor != nil && query.Cursor.Backward:
+		before := slices.IndexFunc(users, func(user *models.User) bool {
+			return compareCursors(repositories.NewCursor(user, "", false), query.Cursor, query.Sort) >= 0
+		})
+		if before < 0 {
+			before = len(users)
+		}
+		start := max(before-query.Limit, 0)
+		page, hasMore = users[start:before], start > 0
+	case query.Cursor != nil:
+		after := slices.IndexFunc(users, func(user *models.User) bool {
+			return compareCursors(repositories.NewCursor(user, "", false), query.Cursor, query.Sort) > 0
+		})
+		if after < 0 {
+			after = len(users)
+		}
+		end := min(after+query.Limit, len(users))
+		page, hasMore = users[after:end], end < len(users)
+	default:
+		start := min(query.Offset, len(users))
+		end := min(start+query.Limit, len(users))
+		page, hasMore = users[start:end], end < len(users)
+	}
+
+	return &repositories.UserPage{Users: page, Total: total, HasMore: hasMore}, nil
+}
+
+func (r *MemoryUserRepository) UpdateUser(ctx context.Context, id uint, user *models.User) error {
+	user.ID = id
+	return r.PatchUser(ctx, user, userColumns)
+}
+
+func (r *MemoryUserRepository) PatchUser(ctx context.Context, user *models.User, columns []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.find(user.ID)
+	if !ok {
+		return apperrors.NotFound(gorm.ErrRecordNotFound)
+	}
+	if stored.Version != user.Version {
+		return apperrors.PreconditionFailed(fmt.Errorf("se esperaba la versión %d y la actual es %d", user.Version, stored.Version))
+	}
+	if err := r.checkUnique(user, user.ID); err != nil {
+		return err
+	}
+
+	for _, column := range columns {
+		switch column {
+		case "name":
+			stored.Name = user.Name
+		case "last_name":
+			stored.LastName = user.LastName
+		case "email":
+			stored.Email = user.Email
+		case "email_verified_at":
+			stored.EmailVerifiedAt = user.EmailVerifiedAt
+		case "username":
+			stored.Username = user.Username
+		case "phone":
+			stored.Phone = user.Phone
+		case "display_name":
+			stored.DisplayName = user.DisplayName
+		}
+	}
+	stored.Version++
+	stored.UpdatedAt = time.Now()
+
+	user.Version, user.UpdatedAt = stored.Version, stored.UpdatedAt
+	return nil
+}
+
+func (r *MemoryUserRepository) UpdatePassword(ctx context.Context, user *models.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.find(user.ID)
+	if !ok {
+		return apperrors.NotFound(gorm.ErrRecordNotFound)
+	}
+	stored.PasswordHash, stored.PasswordChangedAt = user.PasswordHash, user.PasswordChangedAt
+	stored.UpdatedAt = time.Now()
+	user.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+func (r *MemoryUserRepository) VerifyEmail(ctx context.Context, id uint, email string, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.find(id)
+	if !ok || stored.Email == nil || *stored.Email != email {
+		return apperrors.NotFound(gorm.ErrRecordNotFound)
+	}
+	stored.EmailVerifiedAt = &at
+	stored.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *MemoryUserRepository) DeleteUser(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.find(id)
+	if !ok {
+		return apperrors.NotFound(gorm.ErrRecordNotFound)
+	}
+	stored.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	stored.Version++
+	return nil
+}
+
+func (r *MemoryUserRepository) RestoreUser(ctx context.Context, id uint) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[id]
+	if !ok {
+		return nil, apperrors.NotFound(gorm.ErrRecordNotFound)
+	}
+	if stored.DeletedAt.Valid {
+		stored.DeletedAt = gorm.DeletedAt{}
+		stored.Version++
+		stored.UpdatedAt = time.Now()
+	}
+	user := *stored
+	return &user, nil
+}
+
+func (r *MemoryUserRepository) PurgeUser(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[id]; !ok {
+		return apperrors.NotFound(gorm.ErrRecordNotFound)
+	}
+	delete(r.users, id)
+	return nil
+}
+
+func (r *MemoryUserRepository) PurgeDeletedUsers(ctx context.Context, before time.Time) ([]*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := make([]*models.User, 0)
+	for id, stored := range r.users {
+		if stored.DeletedAt.Valid && stored.DeletedAt.Time.Before(before) {
+			delete(r.users, id)
+			user := *stored
+			purged = append(purged, &user)
+		}
+	}
+	slices.SortFunc(purged, func(a, b *models.User) int { return cmp.Compare(a.ID, b.ID) })
+	return purged, nil
+}
+
+// checkUnique replica los índices únicos: ningún otro usuario, aunque esté eliminado
+// lógicamente, puede tener el mismo correo, nombre de usuario o teléfono
+func (r *MemoryUserRepository) checkUnique(user *models.User, id uint) error {
+	for _, stored := range r.users {
+		if stored.ID == id {
+			continue
+		}
+		switch {
+		case sameValue(stored.Email, user.Email):
+			return apperrors.Conflict("email", errDuplicateValue)
+		case sameValue(stored.Username, user.Username):
+			return apperrors.Conflict("username", errDuplicateValue)
+		case sameValue(stored.Phone, user.Phone):
+			return apperrors.Conflict("phone", errDuplicateValue)
+		}
+	}
+	return nil
+}
+
+func sameValue(a, b *string) bool {
+	return a != nil && b != nil && *a == *b
+}
+
+// find devuelve el usuario almacenado si existe y no fue eliminado
+func (r *MemoryUserRepository) find(id uint) (*models.User, bool) {
+	stored, ok := r.users[id]
+	if !ok || stored.DeletedAt.Valid {
+		return nil, false
+	}
+	return stored, true
+}
+
+// matchesFilter replica los filtros de filterUsers; LIKE no distingue mayúsculas en
+// las intercalaciones por defecto, por lo que aquí tampoco se distinguen.
+func matchesFilter(user *models.User, filter repositories.UserFilter) bool {
+	switch filter.Deleted {
+	case repositories.DeletedExclude:
+		if user.DeletedAt.Valid {
+			return false
+		}
+	case repositories.DeletedOnly:
+		if !user.DeletedAt.Valid {
+			return false
+		}
+	}
+	if filter.Name != "" && !hasPrefixFold(user.Name, filter.Name) {
+		return false
+	}
+	if filter.LastName != "" && !hasPrefixFold(user.LastName, filter.LastName) {
+		return false
+	}
+	if !matchesExact(user.Email, filter.Email) || !matchesExact(user.Username, filter.Username) || !matchesExact(user.Phone, filter.Phone) {
+		return false
+	}
+	if filter.CreatedAfter != nil && !user.CreatedAt.After(*filter.CreatedAfter) {
+		return false
+	}
+	if filter.CreatedBefore != nil && !user.CreatedAt.Before(*filter.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+// matchesExact indica si el dato opcional es igual al buscado; un criterio vacío no filtra
+func matchesExact(value *string, wanted string) bool {
+	return wanted == "" || value != nil && *value == wanted
+}
+
+func hasPrefixFold(value, prefix string) bool {
+	return len(value) >= len(prefix) && strings.EqualFold(value[:len(prefix)], prefix)
+}
+
+// compareCursors ordena dos registros según las columnas de ordenamiento
+func compareCursors(a, b *repositories.Cursor, sort []repositories.SortField) int {
+	for _, field := range sort {
+		var result int
+		switch av := a.Value(field.Column).(type) {
+		case string:
+			result = strings.Compare(av, b.Value(field.Column).(string))
+		case time.Time:
+			result = av.Compare(b.Value(field.Column).(time.Time))
+		case uint:
+			result = cmp.Compare(av, b.Value(field.Column).(uint))
+		}
+		if field.Desc {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
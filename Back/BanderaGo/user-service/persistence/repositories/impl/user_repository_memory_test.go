@@ -0,0 +1,220 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// newMemoryRepositoryWith crea un repositorio en memoria con los usuarios indicados
+func newMemoryRepositoryWith(t *testing.T, users ...models.User) *MemoryUserRepository {
+	t.Helper()
+	repo := NewMemoryUserRepository()
+	for _, user := range users {
+		require.NoError(t, repo.CreateUser(context.Background(), &user))
+	}
+	return repo
+}
+
+func TestMemoryCreateAndGetUser(t *testing.T) {
+	repo := NewMemoryUserRepository()
+
+	// Caso de prueba: se asignan identificador, fechas y versión inicial
+	user := &models.User{Name: "John", LastName: "Doe"}
+	require.NoError(t, repo.CreateUser(context.Background(), user))
+	assert.Equal(t, uint(1), user.ID)
+	assert.Equal(t, uint(1), user.Version)
+	assert.False(t, user.CreatedAt.IsZero())
+
+	// Caso de prueba: el usuario devuelto es una copia del almacenado
+	found, err := repo.GetUserByID(context.Background(), 1)
+	require.NoError(t, err)
+	found.Name = "Changed"
+	again, _ := repo.GetUserByID(context.Background(), 1)
+	assert.Equal(t, "John", again.Name)
+
+	_, err = repo.GetUserByID(context.Background(), 2)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+}
+
+func TestMemoryGetAllUsers(t *testing.T) {
+	repo := newMemoryRepositoryWith(t,
+		models.User{Name: "Ana", LastName: "Díaz"},
+		models.User{Name: "andrés", LastName: "Báez"},
+		models.User{Name: "Carlos", LastName: "Báez"},
+		models.User{Name: "Anabel", LastName: "Acosta"},
+	)
+	sort := []repositories.SortField{{Column: "last_name"}, {Column: "id", Desc: true}}
+
+	// Caso de prueba: filtro por prefijo sin distinguir mayúsculas con desplazamiento
+	page, err := repo.GetAllUsers(context.Background(), repositories.UserQuery{
+		Filter: repositories.UserFilter{Name: "an"},
+		Sort:   sort,
+		Limit:  1,
+		Offset: 1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, []uint{2}, memoryIDs(page))
+	assert.True(t, page.HasMore)
+
+	// Caso de prueba: el correo se compara por valor exacto
+	email := "carlos@example.com"
+	require.NoError(t, repo.PatchUser(context.Background(), &models.User{Model: gorm.Model{ID: 3}, Email: &email, Version: 1}, []string{"email"}))
+	page, err = repo.GetAllUsers(context.Background(), repositories.UserQuery{Filter: repositories.UserFilter{Email: email}, Sort: sort, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{3}, memoryIDs(page))
+	page, err = repo.GetAllUsers(context.Background(), repositories.UserQuery{Filter: repositories.UserFilter{Email: "carlos@"}, Sort: sort, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, page.Users)
+
+	// Caso de prueba: cursor hacia adelante a partir del usuario 3
+	cursor := repositories.NewCursor(&models.User{Name: "Carlos", LastName: "Báez"}, "", false)
+	cursor.ID = 3
+	page, err = repo.GetAllUsers(context.Background(), repositories.UserQuery{Sort: sort, Limit: 2, Cursor: cursor})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{2, 1}, memoryIDs(page))
+	assert.False(t, page.HasMore)
+
+	// Caso de prueba: cursor hacia atrás a partir del usuario 1
+	cursor = repositories.NewCursor(&models.User{Name: "Ana", LastName: "Díaz"}, "", true)
+	cursor.ID = 1
+	page, err = repo.GetAllUsers(context.Background(), repositories.UserQuery{Sort: sort, Limit: 2, Cursor: cursor})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{3, 2}, memoryIDs(page))
+	assert.True(t, page.HasMore)
+}
+
+func TestMemoryPatchUser(t *testing.T) {
+	repo := newMemoryRepositoryWith(t, models.User{Name: "John", LastName: "Doe"})
+
+	// Caso de prueba: solo se escriben las columnas indicadas y se incrementa la versión
+	user := &models.User{Name: "Jane", LastName: "Smith", Version: 1}
+	user.ID = 1
+	require.NoError(t, repo.PatchUser(context.Background(), user, []string{"name"}))
+	assert.Equal(t, uint(2), user.Version)
+
+	stored, _ := repo.GetUserByID(context.Background(), 1)
+	assert.Equal(t, "Jane", stored.Name)
+	assert.Equal(t, "Doe", stored.LastName)
+
+	// Caso de prueba: una versión desactualizada no modifica el usuario
+	stale := &models.User{Name: "Other", Version: 1}
+	stale.ID = 1
+	err := repo.UpdateUser(context.Background(), 1, stale)
+	assert.ErrorIs(t, err, apperrors.ErrPreconditionFailed)
+	stored, _ = repo.GetUserByID(context.Background(), 1)
+	assert.Equal(t, "Jane", stored.Name)
+}
+
+// Caso de prueba: los datos de contacto no se repiten, ni siquiera con usuarios eliminados
+func TestMemoryUniqueContactFields(t *testing.T) {
+	email, phone := "john@example.com", "+525512345678"
+	repo := newMemoryRepositoryWith(t,
+		models.User{Name: "John", Email: &email},
+		models.User{Name: "Jane", Phone: &phone},
+		models.User{Name: "Ann"},
+	)
+	ctx := context.Background()
+	require.NoError(t, repo.DeleteUser(ctx, 2))
+
+	err := repo.CreateUser(ctx, &models.User{Name: "Other", Email: &email})
+	assert.ErrorIs(t, err, apperrors.ErrConflict)
+	assert.Equal(t, "email", apperrors.FieldOf(err))
+
+	user := &models.User{Name: "Ann", Phone: &phone, Version: 1}
+	user.ID = 3
+	err = repo.PatchUser(ctx, user, []string{"phone"})
+	assert.Equal(t, "phone", apperrors.FieldOf(err))
+
+	// Conservar su propio valor no es un conflicto y varios usuarios pueden no tenerlo
+	user = &models.User{Name: "Johnny", Email: &email, Version: 1}
+	user.ID = 1
+	assert.NoError(t, repo.PatchUser(ctx, user, []string{"name"}))
+	assert.NoError(t, repo.CreateUser(ctx, &models.User{Name: "Nobody"}))
+}
+
+func TestMemoryDeleteUser(t *testing.T) {
+	repo := newMemoryRepositoryWith(t, models.User{Name: "John", LastName: "Doe"})
+
+	// Caso de prueba: el usuario eliminado deja de encontrarse y de listarse
+	require.NoError(t, repo.DeleteUser(context.Background(), 1))
+	_, err := repo.GetUserByID(context.Background(), 1)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	page, _ := repo.GetAllUsers(context.Background(), repositories.UserQuery{Limit: 10})
+	assert.Zero(t, page.Total)
+
+	assert.ErrorIs(t, repo.DeleteUser(context.Background(), 1), apperrors.ErrNotFound)
+}
+
+func TestMemoryCanceledContext(t *testing.T) {
+	repo := NewMemoryUserRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Caso de prueba: una solicitud cancelada no modifica el repositorio
+	assert.ErrorIs(t, repo.CreateUser(ctx, &models.User{Name: "John"}), context.Canceled)
+	_, err := repo.GetUserByID(context.Background(), 1)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+}
+
+func memoryIDs(page *repositories.UserPage) []uint {
+	ids := make([]uint, 0, len(page.Users))
+	for _, user := range page.Users {
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
+
+func TestMemoryDeletedLifecycle(t *testing.T) {
+	repo := newMemoryRepositoryWith(t, models.User{Name: "John"}, models.User{Name: "Jane"})
+	ctx := context.Background()
+	require.NoError(t, repo.DeleteUser(ctx, 1))
+
+	// Caso de prueba: el alcance de borrado filtra el listado
+	query := repositories.UserQuery{Sort: []repositories.SortField{{Column: "id"}}, Limit: 10}
+	for scope, expected := range map[repositories.DeletedScope]int64{
+		repositories.DeletedExclude: 1,
+		repositories.DeletedInclude: 2,
+		repositories.DeletedOnly:    1,
+	} {
+		query.Filter.Deleted = scope
+		page, err := repo.GetAllUsers(ctx, query)
+		require.NoError(t, err)
+		assert.Equal(t, expected, page.Total, scope)
+	}
+
+	// Caso de prueba: eliminar y restaurar incrementan la versión y es idempotente
+	restored, err := repo.RestoreUser(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, restored.DeletedAt.Valid)
+	assert.Equal(t, uint(3), restored.Version)
+	restored, err = repo.RestoreUser(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), restored.Version)
+	_, err = repo.RestoreUser(ctx, 99)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+
+	// Caso de prueba: la purga por antigüedad solo elimina los borrados antes del límite
+	require.NoError(t, repo.DeleteUser(ctx, 2))
+	purged, err := repo.PurgeDeletedUsers(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, purged)
+	purged, err = repo.PurgeDeletedUsers(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	require.Len(t, purged, 1)
+	assert.Equal(t, uint(2), purged[0].ID)
+	_, err = repo.RestoreUser(ctx, 2)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+
+	// Caso de prueba: la eliminación definitiva también aplica a usuarios activos
+	require.NoError(t, repo.PurgeUser(ctx, 1))
+	assert.ErrorIs(t, repo.PurgeUser(ctx, 1), apperrors.ErrNotFound)
+}
@@ -0,0 +1,41 @@
+package impl
+
+import (
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"time"
+)
+
+type UserVersionRepositoryImpl struct {
+	db repositories.GormDB
+}
+
+func NewUserVersionRepository(db repositories.GormDB) *UserVersionRepositoryImpl {
+	return &UserVersionRepositoryImpl{db: db}
+}
+
+// CreateUserVersion guarda la instantánea en UTC para que las comparaciones por fecha
+// no dependan de la zona horaria del servidor
+func (r *UserVersionRepositoryImpl) CreateUserVersion(ctx context.Context, snapshot *models.UserVersion) error {
+	snapshot.ValidFrom = snapshot.ValidFrom.UTC()
+	return translateError(conn(ctx, r.db).Create(snapshot).Error)
+}
+
+func (r *UserVersionRepositoryImpl) GetUserVersion(ctx context.Context, userID, version uint) (*models.UserVersion, error) {
+	var snapshot models.UserVersion
+	err := conn(ctx, r.db).Where("user_id = ? AND version = ?", userID, version).Order("id ASC").First(&snapshot).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &snapshot, nil
+}
+
+func (r *UserVersionRepositoryImpl) GetUserAsOf(ctx context.Context, userID uint, at time.Time) (*models.UserVersion, error) {
+	var snapshot models.UserVersion
+	err := conn(ctx, r.db).Where("user_id = ? AND valid_from <= ?", userID, at.UTC()).Order("valid_from DESC, id DESC").First(&snapshot).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &snapshot, nil
+}
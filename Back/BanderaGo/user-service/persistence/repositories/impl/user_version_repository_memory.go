@@ -0,0 +1,74 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryUserVersionRepository guarda las instantáneas en memoria con la misma semántica que
+// UserVersionRepositoryImpl
+type MemoryUserVersionRepository struct {
+	mu        sync.RWMutex
+	snapshots []models.UserVersion
+}
+
+func NewMemoryUserVersionRepository() *MemoryUserVersionRepository {
+	return &MemoryUserVersionRepository{}
+}
+
+func (r *MemoryUserVersionRepository) CreateUserVersion(ctx context.Context, snapshot *models.UserVersion) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot.ID = uint(len(r.snapshots) + 1)
+	snapshot.ValidFrom = snapshot.ValidFrom.UTC()
+	r.snapshots = append(r.snapshots, *snapshot)
+	return nil
+}
+
+func (r *MemoryUserVersionRepository) GetUserVersion(ctx context.Context, userID, version uint) (*models.UserVersion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, snapshot := range r.snapshots {
+		if snapshot.UserID == userID && snapshot.Version == version {
+			return &snapshot, nil
+		}
+	}
+	return nil, apperrors.NotFound(gorm.ErrRecordNotFound)
+}
+
+func (r *MemoryUserVersionRepository) GetUserAsOf(ctx context.Context, userID uint, at time.Time) (*models.UserVersion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found *models.UserVersion
+	for i := range r.snapshots {
+		snapshot := &r.snapshots[i]
+		if snapshot.UserID != userID || snapshot.ValidFrom.After(at) {
+			continue
+		}
+		if found == nil || !snapshot.ValidFrom.Before(found.ValidFrom) {
+			found = snapshot
+		}
+	}
+	if found == nil {
+		return nil, apperrors.NotFound(gorm.ErrRecordNotFound)
+	}
+	snapshot := *found
+	return &snapshot, nil
+}
@@ -0,0 +1,65 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: ambos repositorios reconstruyen el usuario por versión y por instante
+func TestUserVersionRepositories(t *testing.T) {
+	repos := map[string]func(t *testing.T) repositories.UserVersionRepository{
+		"memoria": func(t *testing.T) repositories.UserVersionRepository { return NewMemoryUserVersionRepository() },
+		"sqlite": func(t *testing.T) repositories.UserVersionRepository {
+			return NewUserVersionRepository(newSQLiteGormDB(t))
+		},
+	}
+
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+			ctx := context.Background()
+			start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+			deletedAt := start.Add(48 * time.Hour)
+			for _, snapshot := range []models.UserVersion{
+				{UserID: 1, Version: 1, Name: "Jon", ValidFrom: start},
+				{UserID: 2, Version: 1, Name: "Ana", ValidFrom: start},
+				{UserID: 1, Version: 2, Name: "John", ValidFrom: start.Add(24 * time.Hour)},
+				{UserID: 1, Version: 2, Name: "John", DeletedAt: &deletedAt, ValidFrom: deletedAt},
+			} {
+				require.NoError(t, repo.CreateUserVersion(ctx, &snapshot))
+			}
+
+			// La versión devuelve el primer estado guardado con ella, no el borrado
+			found, err := repo.GetUserVersion(ctx, 1, 2)
+			require.NoError(t, err)
+			assert.Equal(t, "John", found.Name)
+			assert.Nil(t, found.DeletedAt)
+
+			// Un instante en otra zona horaria se compara como el mismo instante
+			asOf, err := repo.GetUserAsOf(ctx, 1, start.Add(36*time.Hour).In(time.FixedZone("UTC-5", -5*3600)))
+			require.NoError(t, err)
+			assert.Equal(t, uint(2), asOf.Version)
+			assert.Nil(t, asOf.DeletedAt)
+
+			asOf, err = repo.GetUserAsOf(ctx, 1, start.Add(12*time.Hour))
+			require.NoError(t, err)
+			assert.Equal(t, "Jon", asOf.Name)
+
+			asOf, err = repo.GetUserAsOf(ctx, 1, deletedAt)
+			require.NoError(t, err)
+			assert.NotNil(t, asOf.DeletedAt)
+
+			_, err = repo.GetUserAsOf(ctx, 1, start.Add(-time.Second))
+			assert.ErrorIs(t, err, apperrors.ErrNotFound)
+			_, err = repo.GetUserVersion(ctx, 1, 3)
+			assert.ErrorIs(t, err, apperrors.ErrNotFound)
+		})
+	}
+}
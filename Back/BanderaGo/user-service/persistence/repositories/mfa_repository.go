@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"application/models"
+	"context"
+	"time"
+)
+
+// MFARepository guarda el segundo factor TOTP de cada usuario y sus códigos de recuperación
+type MFARepository interface {
+	GetMFA(ctx context.Context, userID uint) (*models.UserMFA, error)
+	// SaveMFA reemplaza la inscripción del usuario por una pendiente de confirmar
+	SaveMFA(ctx context.Context, mfa *models.UserMFA) error
+	// ConfirmMFA activa la inscripción pendiente y guarda los hashes de los códigos de
+	// recuperación. Devuelve un conflicto si ya estaba confirmada.
+	ConfirmMFA(ctx context.Context, userID uint, step int64, at time.Time, hashes []string) error
+	// UseTOTPStep registra el periodo de un código aceptado. Devuelve un conflicto si ya se
+	// aceptó un código de ese periodo o de uno posterior.
+	UseTOTPStep(ctx context.Context, userID uint, step int64) error
+	// DeleteMFA elimina la inscripción junto con sus códigos de recuperación
+	DeleteMFA(ctx context.Context, userID uint) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uint, hashes []string) error
+	// UseRecoveryCode marca el código como usado; uno ya usado se trata como inexistente
+	UseRecoveryCode(ctx context.Context, userID uint, hash string, at time.Time) error
+	// CountRecoveryCodes devuelve cuántos códigos quedan sin usar
+	CountRecoveryCodes(ctx context.Context, userID uint) (int64, error)
+}
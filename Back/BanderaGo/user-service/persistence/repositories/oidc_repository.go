@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"application/models"
+	"context"
+	"time"
+)
+
+// OIDCRepository guarda los clientes del proveedor OpenID Connect, los códigos de
+// autorización que se les entregan y las llaves con las que se firman sus tokens
+type OIDCRepository interface {
+	GetClients(ctx context.Context) ([]*models.OIDCClient, error)
+	GetClient(ctx context.Context, id string) (*models.OIDCClient, error)
+	CreateClient(ctx context.Context, client *models.OIDCClient) error
+	// UpdateClient reemplaza el nombre, las URIs de redirección y el hash del secreto
+	UpdateClient(ctx context.Context, client *models.OIDCClient) error
+	// DeleteClient elimina el cliente junto con sus códigos pendientes
+	DeleteClient(ctx context.Context, id string) error
+	// CreateAuthorizationCode guarda el código y elimina los vencidos
+	CreateAuthorizationCode(ctx context.Context, code *models.AuthorizationCode) error
+	// UseAuthorizationCode marca el código como usado y lo devuelve; uno vencido o ya usado
+	// se trata como inexistente
+	UseAuthorizationCode(ctx context.Context, hash string, at time.Time) (*models.AuthorizationCode, error)
+	// GetSigningKeys devuelve las llaves de firma de la más nueva a la más antigua
+	GetSigningKeys(ctx context.Context) ([]*models.SigningKey, error)
+	// RotateSigningKeys guarda la llave nueva, retira las que seguían activas y elimina
+	// las retiradas antes de purgeBefore
+	RotateSigningKeys(ctx context.Context, key *models.SigningKey, purgeBefore time.Time) error
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"application/models"
+	"context"
+)
+
+// RoleRepository guarda los roles con sus permisos y los roles asignados a cada usuario
+type RoleRepository interface {
+	// GetRoles devuelve todos los roles ordenados por nombre
+	GetRoles(ctx context.Context) ([]*models.Role, error)
+	GetRole(ctx context.Context, name string) (*models.Role, error)
+	CreateRole(ctx context.Context, role *models.Role) error
+	// UpdateRole reemplaza la descripción y los permisos del rol
+	UpdateRole(ctx context.Context, role *models.Role) error
+	// DeleteRole elimina el rol y lo retira de los usuarios que lo tenían
+	DeleteRole(ctx context.Context, name string) error
+	// GetUserRoles devuelve los nombres de los roles del usuario ordenados
+	GetUserRoles(ctx context.Context, userID uint) ([]string, error)
+	// GetRoleMembers devuelve los IDs ordenados de los usuarios que tienen el rol
+	GetRoleMembers(ctx context.Context, role string) ([]uint, error)
+	// AssignRole asigna el rol al usuario; asignarlo de nuevo no es un error
+	AssignRole(ctx context.Context, userID uint, role string) error
+	// RevokeRole retira el rol al usuario; retirar uno que no tiene no es un error
+	RevokeRole(ctx context.Context, userID uint, role string) error
+	// GetPermissions devuelve la unión ordenada de los permisos de los roles indicados;
+	// los roles que no existen se ignoran
+	GetPermissions(ctx context.Context, roles []string) ([]string, error)
+}
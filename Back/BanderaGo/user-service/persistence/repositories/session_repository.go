@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"application/models"
+	"context"
+	"time"
+)
+
+// SessionRepository guarda las sesiones y sus refresh tokens; las revocadas se conservan
+type SessionRepository interface {
+	// CreateSession guarda la sesión junto con su primer refresh token
+	CreateSession(ctx context.Context, session *models.Session, tokenHash string) error
+	GetSession(ctx context.Context, id uint) (*models.Session, error)
+	// GetUserSessions devuelve las sesiones activas del usuario, la usada más recientemente primero
+	GetUserSessions(ctx context.Context, userID uint, now time.Time) ([]*models.Session, error)
+	GetRefreshToken(ctx context.Context, hash string) (*models.RefreshToken, error)
+	// RotateRefreshToken marca como usado el token anterior, guarda el nuevo y actualiza la
+	// sesión. Devuelve un conflicto si el token anterior ya se había usado.
+	RotateRefreshToken(ctx context.Context, oldHash, newHash string, session *models.Session) error
+	// RevokeSession es idempotente: una sesión ya revocada conserva su fecha de revocación
+	RevokeSession(ctx context.Context, id uint, at time.Time) error
+	RevokeUserSessions(ctx context.Context, userID uint, at time.Time) error
+}
@@ -0,0 +1,9 @@
+package repositories
+
+import "context"
+
+// Transactor agrupa varias operaciones de los repositorios en una transacción. Los
+// repositorios que reciben el contexto de fn operan dentro de ella.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"application/models"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("cursor inválido")
+
+// DeletedScope indica si una consulta considera a los usuarios eliminados lógicamente
+type DeletedScope string
+
+const (
+	DeletedExclude DeletedScope = ""
+	DeletedInclude DeletedScope = "include"
+	DeletedOnly    DeletedScope = "only"
+)
+
+// UserFilter agrupa los criterios de búsqueda de usuarios. Los campos vacíos no filtran.
+// Name y LastName buscan por prefijo; Email, Username y Phone por valor exacto, ya
+// normalizado como se guarda.
+type UserFilter struct {
+	Name          string
+	LastName      string
+	Email         string
+	Username      string
+	Phone         string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Deleted       DeletedScope
+}
+
+// SortField es una columna de ordenamiento ya validada contra la lista permitida.
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// UserQuery describe una página de usuarios. Si Cursor no es nil se pagina por
+// conjunto de llaves a partir de él y Offset se ignora.
+type UserQuery struct {
+	Filter UserFilter
+	Sort   []SortField
+	Limit  int
+	Offset int
+	Cursor *Cursor
+}
+
+// UserPage es el resultado de una consulta paginada. HasMore indica si existen más
+// registros en la dirección en que se recorrió la consulta.
+type UserPage struct {
+	Users   []*models.User
+	Total   int64
+	HasMore bool
+}
+
+// Cursor guarda los valores de ordenamiento del registro límite de una página para
+// continuar desde él. Sort identifica el ordenamiento con el que fue emitido.
+type Cursor struct {
+	Sort      string    `json:"s"`
+	Backward  bool      `json:"b,omitempty"`
+	ID        uint      `json:"id"`
+	Name      string    `json:"n,omitempty"`
+	LastName  string    `json:"l,omitempty"`
+	CreatedAt time.Time `json:"c"`
+	UpdatedAt time.Time `json:"u"`
+}
+
+func NewCursor(user *models.User, sort string, backward bool) *Cursor {
+	return &Cursor{
+		Sort:      sort,
+		Backward:  backward,
+		ID:        user.ID,
+		Name:      user.Name,
+		LastName:  user.LastName,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+// Encode devuelve la representación opaca del cursor para los clientes.
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(encoded string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// Value devuelve el valor del cursor para una columna de ordenamiento.
+func (c *Cursor) Value(column string) interface{} {
+	switch column {
+	case "name":
+		return c.Name
+	case "last_name":
+		return c.LastName
+	case "created_at":
+		return c.CreatedAt
+	case "updated_at":
+		return c.UpdatedAt
+	}
+	return c.ID
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"application/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// Caso de prueba: un cursor codificado se recupera sin pérdida
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 3, 3, 10, 0, 0, 0, time.UTC)
+	user := &models.User{Model: gorm.Model{ID: 7, CreatedAt: createdAt}, Name: "Ana", LastName: "Pérez"}
+
+	cursor := NewCursor(user, "last_name,-created_at,id", true)
+	decoded, err := DecodeCursor(cursor.Encode())
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), decoded.ID)
+	assert.True(t, decoded.Backward)
+	assert.Equal(t, "last_name,-created_at,id", decoded.Sort)
+	assert.Equal(t, "Pérez", decoded.Value("last_name"))
+	assert.Equal(t, "Ana", decoded.Value("name"))
+	assert.True(t, createdAt.Equal(decoded.Value("created_at").(time.Time)))
+	assert.Equal(t, uint(7), decoded.Value("id"))
+}
+
+// Caso de prueba: cursores alterados o vacíos se rechazan
+func TestDecodeCursorInvalid(t *testing.T) {
+	for _, encoded := range []string{"", "not-base64!", "e30", "bnVsbA"} {
+		_, err := DecodeCursor(encoded)
+		assert.ErrorIs(t, err, ErrInvalidCursor, "cursor %q", encoded)
+	}
+}
@@ -1,11 +1,34 @@
 package repositories
 
-import "application/models"
+import (
+	"application/models"
+	"context"
+	"time"
+)
 
 type UserRepository interface {
-	CreateUser(user *models.User) error
-	GetUserByID(id uint) (*models.User, error)
-	GetAllUsers() ([]*models.User, error)
-	UpdateUser(id uint, user *models.User) error
-	DeleteUser(id uint) error
+	CreateUser(ctx context.Context, user *models.User) error
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+	// GetUserByIDUnscoped también encuentra a los usuarios eliminados lógicamente
+	GetUserByIDUnscoped(ctx context.Context, id uint) (*models.User, error)
+	// GetUserByLogin busca al usuario cuyo correo o nombre de usuario coincide con login
+	GetUserByLogin(ctx context.Context, login string) (*models.User, error)
+	GetAllUsers(ctx context.Context, query UserQuery) (*UserPage, error)
+	UpdateUser(ctx context.Context, id uint, user *models.User) error
+	PatchUser(ctx context.Context, user *models.User, columns []string) error
+	// UpdatePassword guarda el hash de la contraseña y la fecha de cambio sin modificar
+	// la versión, que solo cubre los datos visibles del usuario
+	UpdatePassword(ctx context.Context, user *models.User) error
+	// VerifyEmail marca el correo del usuario como verificado si sigue siendo email; si el
+	// usuario cambió de correo responde que no existe
+	VerifyEmail(ctx context.Context, id uint, email string, at time.Time) error
+	// DeleteUser elimina lógicamente al usuario e incrementa su versión
+	DeleteUser(ctx context.Context, id uint) error
+	// RestoreUser deshace el borrado lógico y devuelve el usuario restaurado
+	RestoreUser(ctx context.Context, id uint) (*models.User, error)
+	// PurgeUser elimina definitivamente al usuario, esté o no eliminado lógicamente
+	PurgeUser(ctx context.Context, id uint) error
+	// PurgeDeletedUsers elimina definitivamente los usuarios eliminados lógicamente antes
+	// de la fecha indicada y los devuelve tal como estaban
+	PurgeDeletedUsers(ctx context.Context, before time.Time) ([]*models.User, error)
 }
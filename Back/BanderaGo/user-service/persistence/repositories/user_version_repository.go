@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"application/models"
+	"context"
+	"time"
+)
+
+// UserVersionRepository guarda una instantánea por cada estado de un usuario y con ellas
+// reconstruye el usuario en una versión o en un instante dado
+type UserVersionRepository interface {
+	CreateUserVersion(ctx context.Context, snapshot *models.UserVersion) error
+	// GetUserVersion devuelve el primer estado guardado con esa versión
+	GetUserVersion(ctx context.Context, userID, version uint) (*models.UserVersion, error)
+	// GetUserAsOf devuelve el estado vigente en el instante indicado
+	GetUserAsOf(ctx context.Context, userID uint, at time.Time) (*models.UserVersion, error)
+}
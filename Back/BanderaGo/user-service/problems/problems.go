@@ -0,0 +1,101 @@
+package problems
+
+import (
+	"application/dtos/output"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ContentType = "application/problem+json"
+
+// Códigos estables que los clientes pueden usar para distinguir cada tipo de error
+const (
+	CodeInvalidUserID               = "INVALID_USER_ID"
+	CodeInvalidVersion              = "INVALID_USER_VERSION"
+	CodeInvalidRequestBody          = "INVALID_REQUEST_BODY"
+	CodeInvalidQuery                = "INVALID_QUERY_PARAMETERS"
+	CodeUnsupportedMedia            = "UNSUPPORTED_MEDIA_TYPE"
+	CodeValidationFailed            = "VALIDATION_FAILED"
+	CodePreconditionFailed          = "PRECONDITION_FAILED"
+	CodeUserNotFound                = "USER_NOT_FOUND"
+	CodeUserConflict                = "USER_CONFLICT"
+	CodeServiceUnavailable          = "SERVICE_UNAVAILABLE"
+	CodeRequestTimeout              = "REQUEST_TIMEOUT"
+	CodeUserCreateFailed            = "USER_CREATE_FAILED"
+	CodeUserListFailed              = "USER_LIST_FAILED"
+	CodeUserGetFailed               = "USER_GET_FAILED"
+	CodeUserUpdateFailed            = "USER_UPDATE_FAILED"
+	CodeUserDeleteFailed            = "USER_DELETE_FAILED"
+	CodeUserRestoreFailed           = "USER_RESTORE_FAILED"
+	CodeAuditListFailed             = "AUDIT_LIST_FAILED"
+	CodeInvalidCredentials          = "INVALID_CREDENTIALS"
+	CodePasswordFailed              = "PASSWORD_UPDATE_FAILED"
+	CodeLoginFailed                 = "LOGIN_FAILED"
+	CodeAuthRequired                = "AUTHENTICATION_REQUIRED"
+	CodeInvalidToken                = "INVALID_TOKEN"
+	CodeForbidden                   = "FORBIDDEN"
+	CodeRoleNotFound                = "ROLE_NOT_FOUND"
+	CodeRoleConflict                = "ROLE_CONFLICT"
+	CodeRoleGetFailed               = "ROLE_GET_FAILED"
+	CodeRoleUpdateFailed            = "ROLE_UPDATE_FAILED"
+	CodeInvalidAPIKey               = "INVALID_API_KEY"
+	CodeInvalidAPIKeyID             = "INVALID_API_KEY_ID"
+	CodeAPIKeyNotFound              = "API_KEY_NOT_FOUND"
+	CodeAPIKeyGetFailed             = "API_KEY_GET_FAILED"
+	CodeAPIKeyUpdateFailed          = "API_KEY_UPDATE_FAILED"
+	CodeInvalidRefresh              = "INVALID_REFRESH_TOKEN"
+	CodeInvalidSessionID            = "INVALID_SESSION_ID"
+	CodeSessionNotFound             = "SESSION_NOT_FOUND"
+	CodeSessionGetFailed            = "SESSION_GET_FAILED"
+	CodeSessionRevokeFailed         = "SESSION_REVOKE_FAILED"
+	CodeMFARequired                 = "MFA_REQUIRED"
+	CodeMFANotFound                 = "MFA_NOT_FOUND"
+	CodeMFAConflict                 = "MFA_CONFLICT"
+	CodeMFAGetFailed                = "MFA_GET_FAILED"
+	CodeMFAUpdateFailed             = "MFA_UPDATE_FAILED"
+	CodeAccountLocked               = "ACCOUNT_LOCKED"
+	CodeLoginThrottled              = "LOGIN_THROTTLED"
+	CodeLockoutGetFailed            = "LOCKOUT_GET_FAILED"
+	CodeUnlockFailed                = "UNLOCK_FAILED"
+	CodeInvalidEmailToken           = "INVALID_EMAIL_TOKEN"
+	CodeEmailVerified               = "EMAIL_ALREADY_VERIFIED"
+	CodeEmailVerifyFailed           = "EMAIL_VERIFICATION_FAILED"
+	CodePasswordResetFailed         = "PASSWORD_RESET_FAILED"
+	CodePasswordResetThrottled      = "PASSWORD_RESET_THROTTLED"
+	CodeOIDCClientNotFound          = "OIDC_CLIENT_NOT_FOUND"
+	CodeOIDCClientGetFailed         = "OIDC_CLIENT_GET_FAILED"
+	CodeOIDCClientUpdateFailed      = "OIDC_CLIENT_UPDATE_FAILED"
+	CodeOIDCPublicClient            = "OIDC_PUBLIC_CLIENT"
+	CodeInvalidAuthorizationRequest = "INVALID_AUTHORIZATION_REQUEST"
+	CodeAuthorizeFailed             = "AUTHORIZE_FAILED"
+	CodeSigningKeyGetFailed         = "SIGNING_KEY_GET_FAILED"
+	CodeSigningKeyRotateFailed      = "SIGNING_KEY_ROTATE_FAILED"
+)
+
+// TypeURI construye el identificador del tipo de problema a partir de su código,
+// p. ej. USER_NOT_FOUND -> /problems/user-not-found.
+func TypeURI(code string) string {
+	return "/problems/" + strings.ReplaceAll(strings.ToLower(code), "_", "-")
+}
+
+func New(status int, code, detail string) output.ProblemOut {
+	return output.ProblemOut{
+		Type:   TypeURI(code),
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Respond escribe el problema como application/problem+json usando la ruta de la
+// solicitud como instancia y detiene la cadena de handlers.
+func Respond(c *gin.Context, problem output.ProblemOut) {
+	if problem.Instance == "" {
+		problem.Instance = c.Request.URL.Path
+	}
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
@@ -0,0 +1,47 @@
+package problems
+
+import (
+	"application/dtos/output"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: el tipo del problema se deriva del código
+func TestTypeURI(t *testing.T) {
+	assert.Equal(t, "/problems/user-not-found", TypeURI(CodeUserNotFound))
+}
+
+// Caso de prueba: construir un problema con título estándar
+func TestNew(t *testing.T) {
+	problem := New(http.StatusNotFound, CodeUserNotFound, "Usuario no encontrado")
+
+	assert.Equal(t, "/problems/user-not-found", problem.Type)
+	assert.Equal(t, "Not Found", problem.Title)
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	assert.Equal(t, "Usuario no encontrado", problem.Detail)
+	assert.Equal(t, CodeUserNotFound, problem.Code)
+}
+
+// Caso de prueba: la respuesta usa application/problem+json y la ruta como instancia
+func TestRespond(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/api/users/7", nil)
+
+	Respond(c, New(http.StatusNotFound, CodeUserNotFound, "Usuario no encontrado"))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.True(t, c.IsAborted())
+
+	var problem output.ProblemOut
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, "/api/users/7", problem.Instance)
+	assert.Equal(t, CodeUserNotFound, problem.Code)
+}
@@ -0,0 +1,113 @@
+package requestctx
+
+import (
+	"context"
+	"slices"
+	"strconv"
+)
+
+type requestIDKey struct{}
+
+type actorKey struct{}
+
+type identityKey struct{}
+
+type clientKey struct{}
+
+type languageKey struct{}
+
+// Identity es quien hace la solicitud según su token de acceso
+type Identity struct {
+	// Subject es el sujeto del token: el ID del usuario o el nombre de un sistema
+	Subject  string
+	Username string
+	// Roles son los roles que trae el token
+	Roles []string
+	// Permissions son los permisos de los roles del token y de los asignados al usuario
+	Permissions []string
+	// SessionID es la sesión con la que se emitió el token, si la hay
+	SessionID string
+	// MFA indica que el token se emitió después de verificar un segundo factor
+	MFA bool
+}
+
+// Client describe desde dónde se hace la solicitud
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+// Can indica si la identidad tiene el permiso
+func (i Identity) Can(permission string) bool {
+	return slices.Contains(i.Permissions, permission)
+}
+
+// UserID devuelve el ID del usuario cuando el sujeto es un usuario
+func (i Identity) UserID() (uint, bool) {
+	id, err := strconv.ParseUint(i.Subject, 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// IsUser indica si la identidad es el usuario indicado
+func (i Identity) IsUser(id uint) bool {
+	userID, ok := i.UserID()
+	return ok && userID == id
+}
+
+// WithRequestID guarda en el contexto el identificador de la solicitud en curso
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID devuelve el identificador de la solicitud, o "" fuera de una solicitud
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// WithActor guarda en el contexto quién realiza la operación
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor devuelve quién realiza la operación, o "" si no se conoce
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// WithIdentity guarda en el contexto la identidad autenticada
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFrom devuelve la identidad autenticada y si la solicitud está autenticada
+func IdentityFrom(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// WithClient guarda en el contexto los datos del cliente de la solicitud
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// ClientFrom devuelve los datos del cliente, vacíos fuera de una solicitud
+func ClientFrom(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey{}).(Client)
+	return client
+}
+
+// WithLanguage guarda en el contexto el idioma de la solicitud
+func WithLanguage(ctx context.Context, language string) context.Context {
+	return context.WithValue(ctx, languageKey{}, language)
+}
+
+// Language devuelve el idioma de la solicitud, o "" fuera de una solicitud
+func Language(ctx context.Context) string {
+	language, _ := ctx.Value(languageKey{}).(string)
+	return language
+}
@@ -0,0 +1,47 @@
+package requestctx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Caso de prueba: los valores se recuperan del contexto y faltan fuera de una solicitud
+func TestRequestContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, RequestID(ctx))
+	assert.Empty(t, Actor(ctx))
+	_, ok := IdentityFrom(ctx)
+	assert.False(t, ok)
+	assert.Equal(t, Client{}, ClientFrom(ctx))
+	assert.Empty(t, Language(ctx))
+
+	ctx = WithActor(WithRequestID(ctx, "req-1"), "ana")
+	assert.Equal(t, "req-1", RequestID(ctx))
+	assert.Equal(t, "ana", Actor(ctx))
+
+	ctx = WithIdentity(ctx, Identity{Subject: "7", Username: "ana"})
+	identity, ok := IdentityFrom(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, Identity{Subject: "7", Username: "ana"}, identity)
+
+	ctx = WithClient(ctx, Client{IP: "10.0.0.1", UserAgent: "curl/8.0"})
+	assert.Equal(t, Client{IP: "10.0.0.1", UserAgent: "curl/8.0"}, ClientFrom(ctx))
+
+	ctx = WithLanguage(ctx, "en")
+	assert.Equal(t, "en", Language(ctx))
+}
+
+// Caso de prueba: los permisos y el usuario se derivan de la identidad
+func TestIdentity(t *testing.T) {
+	identity := Identity{Subject: "7", Permissions: []string{"users:read"}}
+	assert.True(t, identity.Can("users:read"))
+	assert.False(t, identity.Can("users:delete"))
+	assert.True(t, identity.IsUser(7))
+	assert.False(t, identity.IsUser(8))
+
+	_, ok := Identity{Subject: "deploy-bot"}.UserID()
+	assert.False(t, ok)
+	assert.False(t, Identity{Subject: "0"}.IsUser(0))
+}
@@ -0,0 +1,199 @@
+package scim
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Operadores de comparación de los filtros (RFC 7644, sección 3.4.2.2)
+const (
+	OpEqual      = "eq"
+	OpNotEqual   = "ne"
+	OpContains   = "co"
+	OpStartsWith = "sw"
+	OpEndsWith   = "ew"
+	OpGreater    = "gt"
+	OpGreaterEq  = "ge"
+	OpLess       = "lt"
+	OpLessEq     = "le"
+	OpPresent    = "pr"
+)
+
+var (
+	operators        = []string{OpEqual, OpNotEqual, OpContains, OpStartsWith, OpEndsWith, OpGreater, OpGreaterEq, OpLess, OpLessEq, OpPresent}
+	attributePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_$-]*(\.[A-Za-z][A-Za-z0-9_$-]*)?$`)
+)
+
+// Comparison es una comparación de un filtro, p. ej. userName eq "ana". Value es el
+// literal ya decodificado: string, bool, float64 o nil; es nil también con pr.
+type Comparison struct {
+	Attribute string
+	Operator  string
+	Value     any
+}
+
+// Is indica si la comparación es sobre el atributo, sin distinguir mayúsculas como pide
+// el RFC 7643, sección 2.1
+func (c Comparison) Is(attribute string) bool {
+	return strings.EqualFold(c.Attribute, attribute)
+}
+
+// Path es la ruta de una operación de PATCH, p. ej. emails[type eq "work"].value
+type Path struct {
+	Attribute    string
+	Filter       []Comparison
+	SubAttribute string
+}
+
+// Is indica si la ruta es sobre el atributo, sin distinguir mayúsculas
+func (p Path) Is(attribute string) bool {
+	return strings.EqualFold(p.Attribute, attribute)
+}
+
+// String devuelve el atributo y el subatributo de la ruta sin el filtro
+func (p Path) String() string {
+	if p.SubAttribute == "" {
+		return p.Attribute
+	}
+	return p.Attribute + "." + p.SubAttribute
+}
+
+type token struct {
+	text    string
+	literal bool
+	value   any
+}
+
+// ParseFilter analiza un filtro formado por comparaciones unidas con and. Las
+// expresiones con or, not, paréntesis o filtros de valores no se admiten.
+func ParseFilter(filter string) ([]Comparison, error) {
+	tokens, err := tokenize(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, NewError(ErrorInvalidFilter, "el filtro está vacío")
+	}
+
+	var comparisons []Comparison
+	for i := 0; ; {
+		comparison, next, err := parseComparison(tokens, i)
+		if err != nil {
+			return nil, err
+		}
+		comparisons = append(comparisons, comparison)
+		if next == len(tokens) {
+			return comparisons, nil
+		}
+		if tokens[next].literal || !strings.EqualFold(tokens[next].text, "and") {
+			return nil, NewError(ErrorInvalidFilter, "solo se admiten comparaciones unidas con and")
+		}
+		i = next + 1
+	}
+}
+
+func parseComparison(tokens []token, i int) (Comparison, int, error) {
+	if i >= len(tokens) || tokens[i].literal || !attributePattern.MatchString(trimSchema(tokens[i].text)) {
+		return Comparison{}, 0, NewError(ErrorInvalidFilter, "se esperaba un atributo")
+	}
+	comparison := Comparison{Attribute: trimSchema(tokens[i].text)}
+	if i+1 >= len(tokens) || tokens[i+1].literal {
+		return Comparison{}, 0, NewError(ErrorInvalidFilter, "se esperaba un operador")
+	}
+	comparison.Operator = strings.ToLower(tokens[i+1].text)
+	if !slices.Contains(operators, comparison.Operator) {
+		return Comparison{}, 0, NewError(ErrorInvalidFilter, "operador desconocido "+tokens[i+1].text)
+	}
+	if comparison.Operator == OpPresent {
+		return comparison, i + 2, nil
+	}
+	if i+2 >= len(tokens) {
+		return Comparison{}, 0, NewError(ErrorInvalidFilter, "se esperaba un valor")
+	}
+	value := tokens[i+2]
+	if !value.literal {
+		// true, false, null y los números se escriben sin comillas
+		if err := json.Unmarshal([]byte(value.text), &value.value); err != nil {
+			return Comparison{}, 0, NewError(ErrorInvalidFilter, "valor inválido "+value.text)
+		}
+		if _, isString := value.value.(string); isString {
+			return Comparison{}, 0, NewError(ErrorInvalidFilter, "valor inválido "+value.text)
+		}
+	}
+	comparison.Value = value.value
+	return comparison, i + 3, nil
+}
+
+// tokenize separa el filtro en palabras y cadenas entre comillas, que se decodifican
+// como cadenas JSON
+func tokenize(filter string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(filter); {
+		switch c := filter[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')' || c == '[' || c == ']':
+			return nil, NewError(ErrorInvalidFilter, "no se admiten agrupaciones ni filtros de valores")
+		case c == '"':
+			end := i + 1
+			for end < len(filter) && filter[end] != '"' {
+				if filter[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(filter) {
+				return nil, NewError(ErrorInvalidFilter, "cadena sin cerrar")
+			}
+			var value string
+			if err := json.Unmarshal([]byte(filter[i:end+1]), &value); err != nil {
+				return nil, NewError(ErrorInvalidFilter, "cadena inválida")
+			}
+			tokens = append(tokens, token{text: filter[i : end+1], literal: true, value: value})
+			i = end + 1
+		default:
+			end := i
+			for end < len(filter) && !strings.ContainsRune(" \t()[]\"", rune(filter[end])) {
+				end++
+			}
+			tokens = append(tokens, token{text: filter[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// ParsePath analiza la ruta de una operación de PATCH: un atributo, opcionalmente con un
+// filtro de valores entre corchetes, seguido de un subatributo
+func ParsePath(path string) (Path, error) {
+	path = trimSchema(strings.TrimSpace(path))
+	open := strings.IndexByte(path, '[')
+	if open < 0 {
+		if !attributePattern.MatchString(path) {
+			return Path{}, NewError(ErrorInvalidPath, "ruta inválida "+path)
+		}
+		attribute, sub, _ := strings.Cut(path, ".")
+		return Path{Attribute: attribute, SubAttribute: sub}, nil
+	}
+
+	closing := strings.LastIndexByte(path, ']')
+	attribute, rest := path[:open], path[closing+1:]
+	if closing < open || !attributePattern.MatchString(attribute) || strings.Contains(attribute, ".") {
+		return Path{}, NewError(ErrorInvalidPath, "ruta inválida "+path)
+	}
+	filter, err := ParseFilter(path[open+1 : closing])
+	if err != nil {
+		return Path{}, NewError(ErrorInvalidPath, "filtro inválido en la ruta "+path)
+	}
+	result := Path{Attribute: attribute, Filter: filter}
+	if rest != "" {
+		sub, ok := strings.CutPrefix(rest, ".")
+		if !ok || !attributePattern.MatchString(sub) || strings.Contains(sub, ".") {
+			return Path{}, NewError(ErrorInvalidPath, "ruta inválida "+path)
+		}
+		result.SubAttribute = sub
+	}
+	return result, nil
+}
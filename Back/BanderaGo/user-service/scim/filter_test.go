@@ -0,0 +1,66 @@
+package scim
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: las comparaciones unidas con and se separan con sus literales decodificados
+func TestParseFilter(t *testing.T) {
+	comparisons, err := ParseFilter(`urn:ietf:params:scim:schemas:core:2.0:User:userName EQ "ana \"la\" díaz" and active eq true AND title pr and meta.created gt 5`)
+	require.NoError(t, err)
+	assert.Equal(t, []Comparison{
+		{Attribute: "userName", Operator: OpEqual, Value: `ana "la" díaz`},
+		{Attribute: "active", Operator: OpEqual, Value: true},
+		{Attribute: "title", Operator: OpPresent},
+		{Attribute: "meta.created", Operator: OpGreater, Value: float64(5)},
+	}, comparisons)
+	assert.True(t, comparisons[0].Is("username"))
+}
+
+// Caso de prueba: las expresiones que no se admiten responden invalidFilter
+func TestParseFilterInvalid(t *testing.T) {
+	for _, filter := range []string{
+		``,
+		`userName eq "ana" or userName eq "eva"`,
+		`not (userName eq "ana")`,
+		`emails[type eq "work"]`,
+		`userName eq ana`,
+		`userName like "ana"`,
+		`userName eq "ana`,
+		`userName eq`,
+		`"ana" eq userName`,
+	} {
+		_, err := ParseFilter(filter)
+		var scimErr *Error
+		require.True(t, errors.As(err, &scimErr), filter)
+		assert.Equal(t, ErrorInvalidFilter, scimErr.Type, filter)
+	}
+}
+
+// Caso de prueba: las rutas de PATCH admiten subatributos y un filtro de valores
+func TestParsePath(t *testing.T) {
+	path, err := ParsePath("name.givenName")
+	require.NoError(t, err)
+	assert.Equal(t, Path{Attribute: "name", SubAttribute: "givenName"}, path)
+
+	path, err = ParsePath(`emails[type eq "work"].value`)
+	require.NoError(t, err)
+	assert.Equal(t, Path{Attribute: "emails", SubAttribute: "value",
+		Filter: []Comparison{{Attribute: "type", Operator: OpEqual, Value: "work"}}}, path)
+	assert.Equal(t, "emails.value", path.String())
+
+	path, err = ParsePath(SchemaGroup + `:members[value eq "5"]`)
+	require.NoError(t, err)
+	assert.Equal(t, "members", path.Attribute)
+
+	for _, invalid := range []string{"", "name..givenName", `emails[type eq "work"`, `emails[type]`, `emails[type eq "work"]value`} {
+		_, err := ParsePath(invalid)
+		var scimErr *Error
+		require.True(t, errors.As(err, &scimErr), invalid)
+		assert.Equal(t, ErrorInvalidPath, scimErr.Type, invalid)
+	}
+}
@@ -0,0 +1,96 @@
+package scim
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MediaType es el tipo de contenido de las respuestas SCIM (RFC 7644, sección 3.1)
+const MediaType = "application/scim+json"
+
+// Esquemas de los recursos y de los mensajes del protocolo (RFC 7643 y RFC 7644)
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	SchemaSchema                = "urn:ietf:params:scim:schemas:core:2.0:Schema"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// Rutas de los recursos, relativas a la raíz de SCIM
+const (
+	BasePath   = "/scim/v2"
+	UsersPath  = BasePath + "/Users"
+	GroupsPath = BasePath + "/Groups"
+)
+
+// Valores de scimType de los errores 400 y 409 (RFC 7644, sección 3.12)
+const (
+	ErrorInvalidFilter = "invalidFilter"
+	ErrorInvalidSyntax = "invalidSyntax"
+	ErrorInvalidPath   = "invalidPath"
+	ErrorInvalidValue  = "invalidValue"
+	ErrorNoTarget      = "noTarget"
+	ErrorMutability    = "mutability"
+	ErrorUniqueness    = "uniqueness"
+)
+
+// Operaciones de PATCH (RFC 7644, sección 3.5.2)
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+const (
+	// DefaultCount es el tamaño de página cuando el cliente no envía count, que también
+	// es el máximo que se devuelve
+	DefaultCount = 100
+)
+
+// Error es un error del protocolo que responde 400 con su scimType
+type Error struct {
+	Type   string
+	Detail string
+}
+
+func NewError(scimType, detail string) *Error {
+	return &Error{Type: scimType, Detail: detail}
+}
+
+func (e *Error) Error() string {
+	return e.Type + ": " + e.Detail
+}
+
+// ResourceID devuelve el id SCIM de un usuario
+func ResourceID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// ParseID convierte el id SCIM de un usuario; un id que no es un número no existe
+func ParseID(id string) (uint, bool) {
+	value, err := strconv.ParseUint(id, 10, 0)
+	if err != nil || value == 0 {
+		return 0, false
+	}
+	return uint(value), true
+}
+
+// Version representa la versión de un usuario como en el encabezado ETag, p. ej. "3"
+func Version(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// trimSchema quita el URN del esquema con el que el cliente puede calificar un atributo,
+// p. ej. urn:ietf:params:scim:schemas:core:2.0:User:userName
+func trimSchema(attribute string) string {
+	for _, schema := range []string{SchemaUser, SchemaGroup} {
+		if len(attribute) > len(schema) && strings.EqualFold(attribute[:len(schema)+1], schema+":") {
+			return attribute[len(schema)+1:]
+		}
+	}
+	return attribute
+}
@@ -0,0 +1,19 @@
+package services
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/requestctx"
+	"context"
+)
+
+type APIKeyService interface {
+	GetAPIKeys(ctx context.Context) ([]output.APIKeyOut, error)
+	GetAPIKey(ctx context.Context, id uint) (output.APIKeyOut, error)
+	CreateAPIKey(ctx context.Context, apiKeyIn input.APIKeyIn) (output.APIKeyCreatedOut, error)
+	// RotateAPIKey reemplaza el secreto de la llave; el anterior deja de funcionar
+	RotateAPIKey(ctx context.Context, id uint) (output.APIKeyCreatedOut, error)
+	RevokeAPIKey(ctx context.Context, id uint) error
+	// VerifyAPIKey devuelve la identidad de una llave activa
+	VerifyAPIKey(ctx context.Context, key string) (requestctx.Identity, error)
+}
@@ -0,0 +1,18 @@
+package services
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"context"
+)
+
+type AuthService interface {
+	SetPassword(ctx context.Context, id uint, passwordIn input.SetPasswordIn) error
+	ChangePassword(ctx context.Context, id uint, passwordIn input.ChangePasswordIn) error
+	Login(ctx context.Context, loginIn input.LoginIn) (output.LoginOut, error)
+	IssueToken(ctx context.Context, tokenIn input.TokenIn) (output.TokenOut, error)
+	Refresh(ctx context.Context, refreshIn input.RefreshIn) (output.TokenOut, error)
+	Logout(ctx context.Context, refreshIn input.RefreshIn) error
+	GetLockout(ctx context.Context, userID uint) (output.LockoutOut, error)
+	Unlock(ctx context.Context, userID uint) error
+}
@@ -0,0 +1,13 @@
+package services
+
+import (
+	"application/dtos/input"
+	"context"
+)
+
+type EmailService interface {
+	RequestVerification(ctx context.Context, userID uint) error
+	VerifyEmail(ctx context.Context, verifyIn input.VerifyEmailIn) error
+	RequestPasswordReset(ctx context.Context, forgotIn input.ForgotPasswordIn) error
+	ResetPassword(ctx context.Context, resetIn input.ResetPasswordIn) error
+}
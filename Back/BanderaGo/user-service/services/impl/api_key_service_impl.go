@@ -0,0 +1,195 @@
+package impl
+
+import (
+	"application/apikeys"
+	"application/apperrors"
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/models"
+	"application/persistence/repositories"
+	"application/requestctx"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+const maxAPIKeyNameLength = 100
+
+var (
+	errInvalidAPIKey     = errors.New("la llave de API no es válida, está revocada o venció")
+	errAPIKeyRevoked     = errors.New("la llave de API está revocada")
+	errPermissionNotHeld = errors.New("no se puede otorgar a una llave un permiso que no se tiene")
+)
+
+type APIKeyServiceImpl struct {
+	keys repositories.APIKeyRepository
+}
+
+func NewAPIKeyService(keys repositories.APIKeyRepository) *APIKeyServiceImpl {
+	return &APIKeyServiceImpl{keys: keys}
+}
+
+func (s *APIKeyServiceImpl) GetAPIKeys(ctx context.Context) ([]output.APIKeyOut, error) {
+	keys, err := s.keys.GetAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keysOut := make([]output.APIKeyOut, 0, len(keys))
+	for _, key := range keys {
+		keysOut = append(keysOut, apiKeyOut(key))
+	}
+	return keysOut, nil
+}
+
+func (s *APIKeyServiceImpl) GetAPIKey(ctx context.Context, id uint) (output.APIKeyOut, error) {
+	key, err := s.keys.GetAPIKey(ctx, id)
+	if err != nil {
+		return output.APIKeyOut{}, err
+	}
+	return apiKeyOut(key), nil
+}
+
+func (s *APIKeyServiceImpl) CreateAPIKey(ctx context.Context, apiKeyIn input.APIKeyIn) (output.APIKeyCreatedOut, error) {
+	key := &models.APIKey{
+		Name:        normalizeText(apiKeyIn.Name),
+		Permissions: normalizePermissions(apiKeyIn.Permissions),
+		ExpiresAt:   apiKeyIn.ExpiresAt,
+		CreatedBy:   requestctx.Actor(ctx),
+	}
+	if err := validateAPIKey(key, time.Now()); err != nil {
+		return output.APIKeyCreatedOut{}, err
+	}
+	if err := authorizeKeyPermissions(ctx, key); err != nil {
+		return output.APIKeyCreatedOut{}, err
+	}
+
+	raw, prefix, hash, err := apikeys.Generate()
+	if err != nil {
+		return output.APIKeyCreatedOut{}, err
+	}
+	key.Prefix, key.Hash = prefix, hash
+	if err := s.keys.CreateAPIKey(ctx, key); err != nil {
+		return output.APIKeyCreatedOut{}, err
+	}
+	return output.APIKeyCreatedOut{APIKeyOut: apiKeyOut(key), Key: raw}, nil
+}
+
+func (s *APIKeyServiceImpl) RotateAPIKey(ctx context.Context, id uint) (output.APIKeyCreatedOut, error) {
+	key, err := s.keys.GetAPIKey(ctx, id)
+	if err != nil {
+		return output.APIKeyCreatedOut{}, err
+	}
+	if key.RevokedAt != nil {
+		return output.APIKeyCreatedOut{}, apperrors.NotFoundField("api_key", errAPIKeyRevoked)
+	}
+	// Rotar entrega el secreto nuevo, así que exige lo mismo que crear la llave
+	if err := authorizeKeyPermissions(ctx, key); err != nil {
+		return output.APIKeyCreatedOut{}, err
+	}
+
+	raw, prefix, hash, err := apikeys.Generate()
+	if err != nil {
+		return output.APIKeyCreatedOut{}, err
+	}
+	key.Prefix, key.Hash = prefix, hash
+	if err := s.keys.UpdateAPIKey(ctx, key); err != nil {
+		return output.APIKeyCreatedOut{}, err
+	}
+	return output.APIKeyCreatedOut{APIKeyOut: apiKeyOut(key), Key: raw}, nil
+}
+
+// authorizeKeyPermissions impide que quien crea o rota una llave obtenga permisos que no
+// tiene
+func authorizeKeyPermissions(ctx context.Context, key *models.APIKey) error {
+	identity, ok := requestctx.IdentityFrom(ctx)
+	if !ok {
+		return nil
+	}
+	for _, permission := range key.Permissions {
+		if !identity.Can(permission) {
+			return apperrors.Forbidden(fmt.Errorf("%w: %s", errPermissionNotHeld, permission))
+		}
+	}
+	return nil
+}
+
+// RevokeAPIKey revoca la llave de inmediato; revocar una llave revocada no tiene efecto
+func (s *APIKeyServiceImpl) RevokeAPIKey(ctx context.Context, id uint) error {
+	key, err := s.keys.GetAPIKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+	revokedAt := time.Now().UTC()
+	key.RevokedAt = &revokedAt
+	return s.keys.UpdateAPIKey(ctx, key)
+}
+
+// VerifyAPIKey busca la llave por su prefijo y compara el hash. El sujeto de la
+// identidad es apikey:<id>, que queda como actor en la auditoría, y sus permisos son
+// los de la llave.
+func (s *APIKeyServiceImpl) VerifyAPIKey(ctx context.Context, raw string) (requestctx.Identity, error) {
+	prefix, ok := apikeys.Prefix(raw)
+	if !ok {
+		return requestctx.Identity{}, apperrors.Unauthorized(errInvalidAPIKey)
+	}
+	key, err := s.keys.GetAPIKeyByPrefix(ctx, prefix)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return requestctx.Identity{}, apperrors.Unauthorized(errInvalidAPIKey)
+	}
+	if err != nil {
+		return requestctx.Identity{}, err
+	}
+	if !apikeys.Matches(raw, key.Hash) || !key.Active(time.Now()) {
+		return requestctx.Identity{}, apperrors.Unauthorized(errInvalidAPIKey)
+	}
+	return requestctx.Identity{
+		Subject:     "apikey:" + strconv.FormatUint(uint64(key.ID), 10),
+		Username:    key.Name,
+		Permissions: slices.Clone(key.Permissions),
+	}, nil
+}
+
+// validateAPIKey reporta juntas las reglas que no cumple la llave
+func validateAPIKey(key *models.APIKey, now time.Time) error {
+	var violations apperrors.Violations
+	switch {
+	case key.Name == "":
+		violations = append(violations, apperrors.Violation{Field: "name", Rule: "required"})
+	case utf8.RuneCountInString(key.Name) > maxAPIKeyNameLength:
+		violations = append(violations, apperrors.Violation{Field: "name", Rule: "max", Param: strconv.Itoa(maxAPIKeyNameLength)})
+	case !isPrintable(key.Name):
+		violations = append(violations, apperrors.Violation{Field: "name", Rule: "printable"})
+	}
+	if len(key.Permissions) == 0 {
+		violations = append(violations, apperrors.Violation{Field: "permissions", Rule: "required"})
+	}
+	for _, permission := range key.Permissions {
+		if !slices.Contains(models.Permissions, permission) {
+			violations = append(violations, apperrors.Violation{Field: "permissions", Rule: "permission", Param: permission})
+		}
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		violations = append(violations, apperrors.Violation{Field: "expires_at", Rule: "future"})
+	}
+	return apperrors.Invalid(violations)
+}
+
+func apiKeyOut(key *models.APIKey) output.APIKeyOut {
+	return output.APIKeyOut{
+		ID:          key.ID,
+		Name:        key.Name,
+		Prefix:      key.Prefix,
+		Permissions: key.Permissions,
+		ExpiresAt:   key.ExpiresAt,
+		RevokedAt:   key.RevokedAt,
+		CreatedBy:   key.CreatedBy,
+		CreatedAt:   key.CreatedAt,
+	}
+}
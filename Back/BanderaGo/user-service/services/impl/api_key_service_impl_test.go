@@ -0,0 +1,121 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/models"
+	repoImpl "application/persistence/repositories/impl"
+	"application/requestctx"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// adminContext simula una solicitud de un administrador con todos los permisos
+func adminContext() context.Context {
+	ctx := requestctx.WithIdentity(context.Background(), requestctx.Identity{Subject: "1", Permissions: models.Permissions})
+	return requestctx.WithActor(ctx, "1")
+}
+
+// Caso de prueba: la llave creada solo se muestra una vez y autentica con sus permisos
+func TestCreateAndVerifyAPIKey(t *testing.T) {
+	repo := repoImpl.NewMemoryAPIKeyRepository()
+	service := NewAPIKeyService(repo)
+	ctx := adminContext()
+
+	created, err := service.CreateAPIKey(ctx, input.APIKeyIn{Name: " batch ", Permissions: []string{models.PermissionUsersRead, models.PermissionUsersRead}})
+	require.NoError(t, err)
+	assert.Equal(t, "batch", created.Name)
+	assert.Equal(t, "1", created.CreatedBy)
+	assert.True(t, strings.HasPrefix(created.Key, created.Prefix+"_"))
+
+	stored, err := repo.GetAPIKey(ctx, created.ID)
+	require.NoError(t, err)
+	assert.NotContains(t, stored.Hash, created.Key)
+
+	identity, err := service.VerifyAPIKey(ctx, created.Key)
+	require.NoError(t, err)
+	assert.Equal(t, requestctx.Identity{Subject: "apikey:1", Username: "batch", Permissions: []string{models.PermissionUsersRead}}, identity)
+
+	for _, key := range []string{"", "abc", created.Prefix + "_otro", "usk_ffffffffffff_secreto"} {
+		_, err = service.VerifyAPIKey(ctx, key)
+		assert.ErrorIs(t, err, apperrors.ErrUnauthorized, key)
+	}
+
+	keys, err := service.GetAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, created.Prefix, keys[0].Prefix)
+}
+
+// Caso de prueba: nombre, permisos y vencimiento inválidos se reportan juntos y no se otorgan permisos ajenos
+func TestCreateAPIKeyInvalid(t *testing.T) {
+	service := NewAPIKeyService(repoImpl.NewMemoryAPIKeyRepository())
+	past := time.Now().Add(-time.Minute)
+
+	_, err := service.CreateAPIKey(adminContext(), input.APIKeyIn{Name: "  ", Permissions: []string{"users:fly"}, ExpiresAt: &past})
+	assert.Equal(t, apperrors.Violations{
+		{Field: "name", Rule: "required"},
+		{Field: "permissions", Rule: "permission", Param: "users:fly"},
+		{Field: "expires_at", Rule: "future"},
+	}, apperrors.ViolationsOf(err))
+
+	ctx := requestctx.WithIdentity(context.Background(), requestctx.Identity{Subject: "2", Permissions: []string{models.PermissionAPIKeysManage}})
+	_, err = service.CreateAPIKey(ctx, input.APIKeyIn{Name: "batch", Permissions: []string{models.PermissionUsersDelete}})
+	assert.ErrorIs(t, err, apperrors.ErrForbidden)
+}
+
+// Caso de prueba: rotar invalida el secreto anterior y una llave revocada o vencida deja de autenticar
+func TestRotateAndRevokeAPIKey(t *testing.T) {
+	repo := repoImpl.NewMemoryAPIKeyRepository()
+	service := NewAPIKeyService(repo)
+	ctx := adminContext()
+
+	created, err := service.CreateAPIKey(ctx, input.APIKeyIn{Name: "batch", Permissions: []string{models.PermissionUsersRead}})
+	require.NoError(t, err)
+	rotated, err := service.RotateAPIKey(ctx, created.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, created.Prefix, rotated.Prefix)
+	_, err = service.VerifyAPIKey(ctx, created.Key)
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+	_, err = service.VerifyAPIKey(ctx, rotated.Key)
+	require.NoError(t, err)
+
+	require.NoError(t, service.RevokeAPIKey(ctx, created.ID))
+	require.NoError(t, service.RevokeAPIKey(ctx, created.ID))
+	_, err = service.VerifyAPIKey(ctx, rotated.Key)
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+	_, err = service.RotateAPIKey(ctx, created.ID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	keyOut, err := service.GetAPIKey(ctx, created.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, keyOut.RevokedAt)
+	assert.ErrorIs(t, service.RevokeAPIKey(ctx, 99), apperrors.ErrNotFound)
+
+	// Una llave que ya venció no autentica
+	expiresAt := time.Now().Add(20 * time.Millisecond)
+	expiring, err := service.CreateAPIKey(ctx, input.APIKeyIn{Name: "corta", Permissions: []string{models.PermissionUsersRead}, ExpiresAt: &expiresAt})
+	require.NoError(t, err)
+	_, err = service.VerifyAPIKey(ctx, expiring.Key)
+	require.NoError(t, err)
+	time.Sleep(30 * time.Millisecond)
+	_, err = service.VerifyAPIKey(ctx, expiring.Key)
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+}
+
+// Caso de prueba: rotar una llave con permisos que no se tienen responde 403 y conserva el secreto
+func TestRotateAPIKeyForbidden(t *testing.T) {
+	service := NewAPIKeyService(repoImpl.NewMemoryAPIKeyRepository())
+	created, err := service.CreateAPIKey(adminContext(), input.APIKeyIn{Name: "admin", Permissions: []string{models.PermissionUsersDelete}})
+	require.NoError(t, err)
+
+	ctx := requestctx.WithIdentity(context.Background(), requestctx.Identity{Subject: "2", Permissions: []string{models.PermissionAPIKeysManage}})
+	_, err = service.RotateAPIKey(ctx, created.ID)
+	assert.ErrorIs(t, err, apperrors.ErrForbidden)
+	_, err = service.VerifyAPIKey(ctx, created.Key)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,116 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/dtos/output"
+	"application/lockout"
+	"application/models"
+	"application/requestctx"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+// signIn comprueba las credenciales limitando los intentos fallidos por cuenta y por IP.
+// Pedir el segundo factor no cuenta como fallo: es el primer paso de esos usuarios.
+func (s *AuthServiceImpl) signIn(ctx context.Context, login, password, otp string) (*models.User, bool, error) {
+	normalized := strings.ToLower(normalizeText(login))
+	user, err := s.repo.GetUserByLogin(ctx, normalized)
+	if err != nil && !errors.Is(err, apperrors.ErrNotFound) {
+		return nil, false, err
+	}
+	account := lockout.LoginKey(normalized)
+	if user != nil {
+		account = lockout.UserKey(user.ID)
+	}
+	ip := requestctx.ClientFrom(ctx).IP
+	attempt, err := s.limiter.Reserve(ctx, account, ip)
+	if err != nil {
+		return nil, false, tooManyAttempts(err)
+	}
+
+	multiFactor, err := s.authenticate(ctx, user, password, otp)
+	if errors.Is(err, apperrors.ErrUnauthorized) && apperrors.FieldOf(err) != "otp" {
+		s.recordFailure(ctx, user, account, ip, attempt)
+		return nil, false, err
+	}
+	if err != nil {
+		if err := s.limiter.Release(ctx, attempt); err != nil {
+			log.Printf("No se pudo liberar el intento de %s: %v", account, err)
+		}
+		return nil, false, err
+	}
+	if err := s.limiter.Succeed(ctx, attempt); err != nil {
+		log.Printf("No se pudieron reiniciar los intentos fallidos del usuario %d: %v", user.ID, err)
+	}
+	return user, multiFactor, nil
+}
+
+// recordFailure registra los bloqueos que causó el intento fallido, que ya quedó contado
+// al reservarlo, y audita el de la cuenta. Un error al auditar no cambia la respuesta.
+func (s *AuthServiceImpl) recordFailure(ctx context.Context, user *models.User, account, ip string, attempt *lockout.Attempt) {
+	if !attempt.IPUntil.IsZero() {
+		log.Printf("Se bloqueó la IP %s hasta %s por intentos fallidos de inicio de sesión", ip, attempt.IPUntil.UTC().Format(time.RFC3339))
+	}
+	if attempt.AccountUntil.IsZero() {
+		return
+	}
+	log.Printf("Se bloqueó %s hasta %s por intentos fallidos de inicio de sesión desde %s", account, attempt.AccountUntil.UTC().Format(time.RFC3339), ip)
+	if user == nil {
+		return
+	}
+	lockedUntil := attempt.AccountUntil.UTC()
+	changes := map[string]models.FieldChange{"locked_until": {Before: nil, After: lockedUntil}}
+	if err := s.audit.CreateAuditRecord(ctx, newAuditEntry(ctx, models.AuditLock, user.ID, changes)); err != nil {
+		log.Printf("No se pudo auditar el bloqueo del usuario %d: %v", user.ID, err)
+	}
+}
+
+// GetLockout devuelve los intentos fallidos recientes y el bloqueo del usuario
+func (s *AuthServiceImpl) GetLockout(ctx context.Context, userID uint) (output.LockoutOut, error) {
+	if _, err := s.repo.GetUserByID(ctx, userID); err != nil {
+		return output.LockoutOut{}, err
+	}
+	entry, err := s.limiter.Status(ctx, lockout.UserKey(userID))
+	if err != nil {
+		return output.LockoutOut{}, err
+	}
+	lockoutOut := output.LockoutOut{FailedAttempts: entry.Failures}
+	if !entry.LockedUntil.IsZero() {
+		lockedUntil := entry.LockedUntil.UTC()
+		lockoutOut.Locked, lockoutOut.LockedUntil = true, &lockedUntil
+	}
+	return lockoutOut, nil
+}
+
+// Unlock desbloquea al usuario y olvida sus intentos fallidos. Es idempotente; solo se
+// audita si el usuario estaba bloqueado.
+func (s *AuthServiceImpl) Unlock(ctx context.Context, userID uint) error {
+	if _, err := s.repo.GetUserByID(ctx, userID); err != nil {
+		return err
+	}
+	account := lockout.UserKey(userID)
+	entry, err := s.limiter.Status(ctx, account)
+	if err != nil {
+		return err
+	}
+	if err := s.limiter.Unlock(ctx, account); err != nil {
+		return err
+	}
+	if entry.LockedUntil.IsZero() {
+		return nil
+	}
+	changes := map[string]models.FieldChange{"locked_until": {Before: entry.LockedUntil.UTC(), After: nil}}
+	return s.audit.CreateAuditRecord(ctx, newAuditEntry(ctx, models.AuditUnlock, userID, changes))
+}
+
+// tooManyAttempts clasifica la espera que exige el limitador
+func tooManyAttempts(err error) error {
+	var locked *lockout.LockedError
+	if errors.As(err, &locked) {
+		return apperrors.TooManyRequests(err)
+	}
+	return err
+}
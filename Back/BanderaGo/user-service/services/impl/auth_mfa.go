@@ -0,0 +1,53 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/mfa"
+	"application/models"
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+var (
+	errMFARequired         = errors.New("se requiere el código del segundo factor")
+	errInvalidSecondFactor = errors.New("el código del segundo factor no es válido o ya se usó")
+)
+
+// secondFactor comprueba el código TOTP o de recuperación del usuario que tiene el
+// segundo factor confirmado. Devuelve true si se verificó; un usuario sin segundo factor
+// no necesita código y devuelve false.
+func (s *AuthServiceImpl) secondFactor(ctx context.Context, user *models.User, otp string) (bool, error) {
+	enrollment, err := s.mfa.GetMFA(ctx, user.ID)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !enrollment.Enabled() {
+		return false, nil
+	}
+	if otp == "" {
+		return false, apperrors.UnauthorizedField("otp", errMFARequired)
+	}
+
+	now := time.Now().UTC()
+	if step, ok := mfa.Verify(enrollment.Secret, otp, now); ok {
+		err := s.mfa.UseTOTPStep(ctx, user.ID, step)
+		if errors.Is(err, apperrors.ErrConflict) {
+			return false, apperrors.Unauthorized(errInvalidSecondFactor)
+		}
+		return err == nil, err
+	}
+	err = s.mfa.UseRecoveryCode(ctx, user.ID, mfa.HashRecoveryCode(otp), now)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return false, apperrors.Unauthorized(errInvalidSecondFactor)
+	}
+	if err != nil {
+		return false, err
+	}
+	log.Printf("El usuario %d inició sesión con un código de recuperación", user.ID)
+	return true, nil
+}
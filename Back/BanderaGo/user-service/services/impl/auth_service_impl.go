@@ -0,0 +1,165 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/lockout"
+	"application/models"
+	"application/passwords"
+	"application/persistence/repositories"
+	"application/services"
+	"application/tokens"
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+var errInvalidCredentials = errors.New("el usuario no existe, no tiene contraseña o la contraseña no coincide")
+
+type AuthServiceImpl struct {
+	repo     repositories.UserRepository
+	sessions repositories.SessionRepository
+	// sessionService revoca las sesiones para que la revocación valga de inmediato
+	sessionService services.SessionService
+	mfa            repositories.MFARepository
+	audit          repositories.AuditRepository
+	tx             repositories.Transactor
+	hasher         *passwords.Hasher
+	policy         passwords.Policy
+	tokens         *tokens.Manager
+	limiter        *lockout.Limiter
+}
+
+func NewAuthService(repo repositories.UserRepository, sessions repositories.SessionRepository, sessionService services.SessionService, mfa repositories.MFARepository, audit repositories.AuditRepository, tx repositories.Transactor, hasher *passwords.Hasher, policy passwords.Policy, tokens *tokens.Manager, limiter *lockout.Limiter) *AuthServiceImpl {
+	return &AuthServiceImpl{repo: repo, sessions: sessions, sessionService: sessionService, mfa: mfa, audit: audit, tx: tx, hasher: hasher, policy: policy, tokens: tokens, limiter: limiter}
+}
+
+func (s *AuthServiceImpl) SetPassword(ctx context.Context, id uint, passwordIn input.SetPasswordIn) error {
+	if err := apperrors.Invalid(s.policy.Check("password", passwordIn.Password)); err != nil {
+		return err
+	}
+	return s.updatePassword(ctx, id, passwordIn.Password, nil)
+}
+
+func (s *AuthServiceImpl) ChangePassword(ctx context.Context, id uint, passwordIn input.ChangePasswordIn) error {
+	if err := authorizeSelf(ctx, id, models.PermissionUsersPassword); err != nil {
+		return err
+	}
+	if err := apperrors.Invalid(s.policy.Check("new_password", passwordIn.NewPassword)); err != nil {
+		return err
+	}
+	return s.updatePassword(ctx, id, passwordIn.NewPassword, &passwordIn.CurrentPassword)
+}
+
+// updatePassword guarda el hash de la nueva contraseña y lo audita. Si current no es nil
+// debe coincidir con la contraseña actual del usuario.
+func (s *AuthServiceImpl) updatePassword(ctx context.Context, id uint, password string, current *string) error {
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.repo.GetUserByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if current != nil {
+			if match, _ := s.verify(*current, before); !match {
+				return apperrors.Invalid(apperrors.Violations{{Field: "current_password", Rule: "current_password"}})
+			}
+		}
+
+		changedAt := time.Now().UTC()
+		user := *before
+		user.PasswordHash, user.PasswordChangedAt = &hash, &changedAt
+		if err := s.repo.UpdatePassword(ctx, &user); err != nil {
+			return err
+		}
+		return s.audit.CreateAuditRecord(ctx, newAuditRecord(ctx, models.AuditPassword, id, before, &user))
+	})
+}
+
+// Login comprueba las credenciales, y el segundo factor si el usuario lo tiene, y devuelve
+// el usuario
+func (s *AuthServiceImpl) Login(ctx context.Context, loginIn input.LoginIn) (output.LoginOut, error) {
+	user, _, err := s.signIn(ctx, loginIn.Login, loginIn.Password, loginIn.OTP)
+	if err != nil {
+		return output.LoginOut{}, err
+	}
+
+	loginOut := output.LoginOut{
+		User: output.GetUserOut{
+			ID:            user.ID,
+			Name:          user.Name,
+			LastName:      user.LastName,
+			Email:         stringValue(user.Email),
+			EmailVerified: user.EmailVerifiedAt != nil,
+			Username:      stringValue(user.Username),
+			Phone:         stringValue(user.Phone),
+			DisplayName:   user.DisplayName,
+			Version:       user.Version,
+		},
+	}
+	return loginOut, nil
+}
+
+// IssueToken emite un token de acceso con la concesión password de OAuth 2.0 (RFC 6749,
+// sección 4.3). El sujeto del token es el ID del usuario. Cada emisión abre una sesión
+// nueva y devuelve su primer refresh token.
+func (s *AuthServiceImpl) IssueToken(ctx context.Context, tokenIn input.TokenIn) (output.TokenOut, error) {
+	user, multiFactor, err := s.signIn(ctx, tokenIn.Username, tokenIn.Password, tokenIn.OTP)
+	if err != nil {
+		return output.TokenOut{}, err
+	}
+
+	session, refreshToken, err := s.startSession(ctx, user, multiFactor)
+	if err != nil {
+		return output.TokenOut{}, err
+	}
+	return s.issueTokens(user, session, refreshToken)
+}
+
+// authenticate comprueba la contraseña y el segundo factor del usuario, que puede ser
+// nil. El usuario inexistente, sin contraseña o con otra contraseña responden el mismo
+// error y tardan lo mismo para no revelar cuál fue. Devuelve si se verificó el segundo factor.
+func (s *AuthServiceImpl) authenticate(ctx context.Context, user *models.User, password, otp string) (bool, error) {
+	match, rehash := s.verify(password, user)
+	if !match {
+		return false, apperrors.Unauthorized(errInvalidCredentials)
+	}
+	if rehash {
+		s.rehash(ctx, user, password)
+	}
+	return s.secondFactor(ctx, user, otp)
+}
+
+// verify compara la contraseña con la del usuario; sin usuario o sin contraseña se
+// verifica contra un hash ficticio para consumir el mismo tiempo
+func (s *AuthServiceImpl) verify(password string, user *models.User) (match, rehash bool) {
+	if user == nil || user.PasswordHash == nil {
+		s.hasher.VerifyDummy(password)
+		return false, false
+	}
+	match, rehash, err := s.hasher.Verify(password, *user.PasswordHash)
+	if err != nil {
+		log.Printf("No se pudo verificar la contraseña del usuario %d: %v", user.ID, err)
+		return false, false
+	}
+	return match, rehash
+}
+
+// rehash vuelve a calcular el hash con los parámetros actuales sin cambiar la fecha de
+// cambio de contraseña. Un error no impide iniciar sesión; se reintenta en el siguiente.
+func (s *AuthServiceImpl) rehash(ctx context.Context, user *models.User, password string) {
+	hash, err := s.hasher.Hash(password)
+	if err == nil {
+		updated := *user
+		updated.PasswordHash = &hash
+		err = s.repo.UpdatePassword(ctx, &updated)
+	}
+	if err != nil {
+		log.Printf("No se pudo actualizar el hash de la contraseña del usuario %d: %v", user.ID, err)
+	}
+}
@@ -0,0 +1,383 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/config"
+	"application/dtos/input"
+	"application/lockout"
+	"application/models"
+	"application/passwords"
+	"application/persistence/repositories"
+	repoImpl "application/persistence/repositories/impl"
+	"application/requestctx"
+	"application/tokens"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// Parámetros bajos para que las pruebas no tarden en calcular los hashes
+var testPasswordConfig = config.PasswordConfig{MinLength: 10, MaxLength: 64, CheckBreached: true, Argon2Memory: 1024, Argon2Iterations: 1, Argon2Parallelism: 1}
+
+var testAuthConfig = config.AuthConfig{
+	Issuer:   config.DefaultTokenIssuer,
+	Audience: config.DefaultTokenAudience,
+	Secret:   "0123456789abcdef0123456789abcdef",
+	TokenTTL: config.DefaultTokenTTL,
+}
+
+var testTokens, _ = tokens.NewManager(testAuthConfig)
+
+// Sin esperas entre intentos para que las pruebas no dependan del reloj
+var testLockoutConfig = config.LockoutConfig{MaxUserFailures: 3, MaxIPFailures: 10, Window: time.Minute, Duration: time.Minute}
+
+func newTestLimiter() *lockout.Limiter {
+	return lockout.NewLimiter(lockout.NewMemoryStore(), testLockoutConfig)
+}
+
+func newTestAuthService(repo *repoImpl.MemoryUserRepository, audit *repoImpl.MemoryAuditRepository, passwordConfig config.PasswordConfig) *AuthServiceImpl {
+	sessions := repoImpl.NewMemorySessionRepository()
+	return NewAuthService(repo, sessions, NewSessionService(sessions, repo, time.Minute), repoImpl.NewMemoryMFARepository(), audit, repoImpl.NewMemoryTransactor(), passwords.NewHasher(passwordConfig), passwords.NewPolicy(passwordConfig), testTokens, newTestLimiter())
+}
+
+func createTestUser(t *testing.T, repo *repoImpl.MemoryUserRepository) *models.User {
+	t.Helper()
+	user := &models.User{Name: "Ana", LastName: "Díaz", Email: optional("ana@example.com"), Username: optional("ana")}
+	require.NoError(t, repo.CreateUser(context.Background(), user))
+	return user
+}
+
+// Caso de prueba: asignar una contraseña e iniciar sesión con el correo o el nombre de usuario
+func TestSetPasswordAndLogin(t *testing.T) {
+	repo, audit := repoImpl.NewMemoryUserRepository(), repoImpl.NewMemoryAuditRepository()
+	service := newTestAuthService(repo, audit, testPasswordConfig)
+	user := createTestUser(t, repo)
+	ctx := context.Background()
+
+	require.NoError(t, service.SetPassword(ctx, user.ID, input.SetPasswordIn{Password: "caballo correcto"}))
+
+	stored, err := repo.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.PasswordHash)
+	assert.True(t, strings.HasPrefix(*stored.PasswordHash, "$argon2id$"))
+	assert.NotNil(t, stored.PasswordChangedAt)
+	assert.Equal(t, user.Version, stored.Version, "la contraseña no cambia la versión del usuario")
+
+	for _, login := range []string{"ana@example.com", " ANA ", "Ana@Example.com"} {
+		loginOut, err := service.Login(ctx, input.LoginIn{Login: login, Password: "caballo correcto"})
+		require.NoError(t, err, login)
+		assert.Equal(t, user.ID, loginOut.User.ID)
+		assert.Equal(t, "ana", loginOut.User.Username)
+	}
+
+	// El cambio queda auditado con la fecha y sin el hash
+	page, err := audit.GetAuditRecords(ctx, repositories.AuditQuery{Operation: models.AuditPassword, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Records, 1)
+	assert.Contains(t, page.Records[0].Changes, "password_changed_at")
+	assert.Len(t, page.Records[0].Changes, 1)
+}
+
+// Caso de prueba: el token emitido identifica al usuario y las credenciales inválidas no emiten token
+func TestIssueToken(t *testing.T) {
+	repo := repoImpl.NewMemoryUserRepository()
+	service := newTestAuthService(repo, repoImpl.NewMemoryAuditRepository(), testPasswordConfig)
+	user := createTestUser(t, repo)
+	ctx := context.Background()
+	require.NoError(t, service.SetPassword(ctx, user.ID, input.SetPasswordIn{Password: "caballo correcto"}))
+
+	tokenOut, err := service.IssueToken(ctx, input.TokenIn{GrantType: "password", Username: "ana@example.com", Password: "caballo correcto"})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", tokenOut.TokenType)
+	assert.Equal(t, int64(config.DefaultTokenTTL.Seconds()), tokenOut.ExpiresIn)
+
+	claims, err := service.tokens.Verify(tokenOut.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.FormatUint(uint64(user.ID), 10), claims.Subject)
+	assert.Equal(t, "ana", claims.Username)
+	assert.NotEmpty(t, claims.SessionID)
+	assert.NotEmpty(t, tokenOut.RefreshToken)
+
+	_, err = service.IssueToken(ctx, input.TokenIn{GrantType: "password", Username: "ana", Password: "otra contraseña"})
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+}
+
+// Caso de prueba: renovar rota el refresh token y reutilizar uno ya cambiado revoca la sesión
+func TestRefreshToken(t *testing.T) {
+	repo := repoImpl.NewMemoryUserRepository()
+	service := newTestAuthService(repo, repoImpl.NewMemoryAuditRepository(), testPasswordConfig)
+	user := createTestUser(t, repo)
+	ctx := requestctx.WithClient(context.Background(), requestctx.Client{IP: "10.0.0.1", UserAgent: "Firefox"})
+	require.NoError(t, service.SetPassword(ctx, user.ID, input.SetPasswordIn{Password: "caballo correcto"}))
+	issued, err := service.IssueToken(ctx, input.TokenIn{GrantType: "password", Username: "ana", Password: "caballo correcto"})
+	require.NoError(t, err)
+
+	ctx = requestctx.WithClient(context.Background(), requestctx.Client{IP: "10.0.0.2", UserAgent: "Firefox"})
+	refreshed, err := service.Refresh(ctx, input.RefreshIn{RefreshToken: issued.RefreshToken})
+	require.NoError(t, err)
+	assert.NotEqual(t, issued.RefreshToken, refreshed.RefreshToken)
+	claims, err := service.tokens.Verify(refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.FormatUint(uint64(user.ID), 10), claims.Subject)
+	sessions, err := service.sessions.GetUserSessions(ctx, user.ID, time.Now())
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, strconv.FormatUint(uint64(sessions[0].ID), 10), claims.SessionID)
+	assert.Equal(t, "10.0.0.2", sessions[0].IP)
+	require.NoError(t, service.sessionService.VerifySession(ctx, claims.SessionID))
+
+	_, err = service.Refresh(ctx, input.RefreshIn{RefreshToken: "desconocido"})
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+
+	// El token ya cambiado se rechaza y la sesión queda revocada, también para el nuevo
+	_, err = service.Refresh(ctx, input.RefreshIn{RefreshToken: issued.RefreshToken})
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+	_, err = service.Refresh(ctx, input.RefreshIn{RefreshToken: refreshed.RefreshToken})
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+	sessions, err = service.sessions.GetUserSessions(ctx, user.ID, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+	assert.ErrorIs(t, service.sessionService.VerifySession(ctx, claims.SessionID), apperrors.ErrUnauthorized)
+}
+
+// Caso de prueba: cerrar sesión revoca la sesión del token y es idempotente
+func TestLogout(t *testing.T) {
+	repo := repoImpl.NewMemoryUserRepository()
+	service := newTestAuthService(repo, repoImpl.NewMemoryAuditRepository(), testPasswordConfig)
+	user := createTestUser(t, repo)
+	ctx := context.Background()
+	require.NoError(t, service.SetPassword(ctx, user.ID, input.SetPasswordIn{Password: "caballo correcto"}))
+	issued, err := service.IssueToken(ctx, input.TokenIn{GrantType: "password", Username: "ana", Password: "caballo correcto"})
+	require.NoError(t, err)
+	claims, err := service.tokens.Verify(issued.AccessToken)
+	require.NoError(t, err)
+	require.NoError(t, service.sessionService.VerifySession(ctx, claims.SessionID))
+
+	// El token de acceso se rechaza de inmediato aunque la sesión estuviera en el caché
+	require.NoError(t, service.Logout(ctx, input.RefreshIn{RefreshToken: issued.RefreshToken}))
+	assert.ErrorIs(t, service.sessionService.VerifySession(ctx, claims.SessionID), apperrors.ErrUnauthorized)
+	require.NoError(t, service.Logout(ctx, input.RefreshIn{RefreshToken: issued.RefreshToken}))
+	require.NoError(t, service.Logout(ctx, input.RefreshIn{RefreshToken: "desconocido"}))
+	_, err = service.Refresh(ctx, input.RefreshIn{RefreshToken: issued.RefreshToken})
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+
+	// Un usuario eliminado ya no puede renovar
+	issued, err = service.IssueToken(ctx, input.TokenIn{GrantType: "password", Username: "ana", Password: "caballo correcto"})
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteUser(ctx, user.ID))
+	_, err = service.Refresh(ctx, input.RefreshIn{RefreshToken: issued.RefreshToken})
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+}
+
+// Caso de prueba: usuario inexistente, sin contraseña o contraseña incorrecta responden igual
+func TestLoginInvalidCredentials(t *testing.T) {
+	repo := repoImpl.NewMemoryUserRepository()
+	service := newTestAuthService(repo, repoImpl.NewMemoryAuditRepository(), testPasswordConfig)
+	user := createTestUser(t, repo)
+	ctx := context.Background()
+
+	_, err := service.Login(ctx, input.LoginIn{Login: "ana", Password: "caballo correcto"})
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized, "sin contraseña")
+
+	require.NoError(t, service.SetPassword(ctx, user.ID, input.SetPasswordIn{Password: "caballo correcto"}))
+	_, err = service.Login(ctx, input.LoginIn{Login: "ana", Password: "caballo incorrecto"})
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized, "contraseña incorrecta")
+	_, err = service.Login(ctx, input.LoginIn{Login: "nadie", Password: "caballo correcto"})
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized, "usuario inexistente")
+
+	// Un usuario eliminado no puede iniciar sesión
+	require.NoError(t, repo.DeleteUser(ctx, user.ID))
+	_, err = service.Login(ctx, input.LoginIn{Login: "ana", Password: "caballo correcto"})
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized, "usuario eliminado")
+}
+
+// Caso de prueba: un error del repositorio no se confunde con credenciales inválidas
+func TestLoginRepositoryError(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewAuthService(mockRepo, repoImpl.NewMemorySessionRepository(), nil, repoImpl.NewMemoryMFARepository(), repoImpl.NewMemoryAuditRepository(), repoImpl.NewMemoryTransactor(), passwords.NewHasher(testPasswordConfig), passwords.NewPolicy(testPasswordConfig), testTokens, newTestLimiter())
+	unavailable := apperrors.Unavailable(errors.New("connection refused"))
+	mockRepo.On("GetUserByLogin", "ana").Return(nil, unavailable)
+
+	_, err := service.Login(context.Background(), input.LoginIn{Login: "ana", Password: "caballo correcto"})
+
+	assert.ErrorIs(t, err, apperrors.ErrUnavailable)
+	assert.NotErrorIs(t, err, apperrors.ErrUnauthorized)
+}
+
+// Caso de prueba: al cambiar los parámetros el hash se recalcula en el siguiente inicio de sesión
+func TestLoginRehash(t *testing.T) {
+	repo := repoImpl.NewMemoryUserRepository()
+	user := createTestUser(t, repo)
+	ctx := context.Background()
+	require.NoError(t, newTestAuthService(repo, repoImpl.NewMemoryAuditRepository(), testPasswordConfig).SetPassword(ctx, user.ID, input.SetPasswordIn{Password: "caballo correcto"}))
+	before, err := repo.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+
+	bcryptConfig := testPasswordConfig
+	bcryptConfig.Algorithm, bcryptConfig.BcryptCost = config.PasswordBcrypt, 4
+	_, err = newTestAuthService(repo, repoImpl.NewMemoryAuditRepository(), bcryptConfig).Login(ctx, input.LoginIn{Login: "ana", Password: "caballo correcto"})
+	require.NoError(t, err)
+
+	after, err := repo.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(*after.PasswordHash, "$2a$04$"), *after.PasswordHash)
+	assert.Equal(t, before.PasswordChangedAt, after.PasswordChangedAt, "recalcular el hash no es un cambio de contraseña")
+
+	// La contraseña sigue siendo válida con el nuevo hash
+	_, err = newTestAuthService(repo, repoImpl.NewMemoryAuditRepository(), bcryptConfig).Login(ctx, input.LoginIn{Login: "ana", Password: "caballo correcto"})
+	assert.NoError(t, err)
+}
+
+// Caso de prueba: cambiar la contraseña exige la actual y la nueva cumple la política
+func TestChangePassword(t *testing.T) {
+	repo := repoImpl.NewMemoryUserRepository()
+	service := newTestAuthService(repo, repoImpl.NewMemoryAuditRepository(), testPasswordConfig)
+	user := createTestUser(t, repo)
+	ctx := context.Background()
+	require.NoError(t, service.SetPassword(ctx, user.ID, input.SetPasswordIn{Password: "caballo correcto"}))
+
+	err := service.ChangePassword(ctx, user.ID, input.ChangePasswordIn{CurrentPassword: "otra contraseña", NewPassword: "batería grapa"})
+	assert.Equal(t, apperrors.Violations{{Field: "current_password", Rule: "current_password"}}, apperrors.ViolationsOf(err))
+
+	err = service.ChangePassword(ctx, user.ID, input.ChangePasswordIn{CurrentPassword: "caballo correcto", NewPassword: "password1234"})
+	assert.Equal(t, apperrors.Violations{{Field: "new_password", Rule: "breached"}}, apperrors.ViolationsOf(err))
+
+	require.NoError(t, service.ChangePassword(ctx, user.ID, input.ChangePasswordIn{CurrentPassword: "caballo correcto", NewPassword: "batería grapa"}))
+	_, err = service.Login(ctx, input.LoginIn{Login: "ana", Password: "caballo correcto"})
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+	_, err = service.Login(ctx, input.LoginIn{Login: "ana", Password: "batería grapa"})
+	assert.NoError(t, err)
+}
+
+// Caso de prueba: una contraseña que no cumple la política no llega al repositorio
+func TestSetPasswordPolicy(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewAuthService(mockRepo, repoImpl.NewMemorySessionRepository(), nil, repoImpl.NewMemoryMFARepository(), repoImpl.NewMemoryAuditRepository(), repoImpl.NewMemoryTransactor(), passwords.NewHasher(testPasswordConfig), passwords.NewPolicy(testPasswordConfig), testTokens, newTestLimiter())
+
+	err := service.SetPassword(context.Background(), 1, input.SetPasswordIn{Password: "corta"})
+
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+	assert.Equal(t, apperrors.Violations{{Field: "password", Rule: "min", Param: "10"}}, apperrors.ViolationsOf(err))
+	mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything)
+}
+
+// Caso de prueba: asignar la contraseña de un usuario inexistente
+func TestSetPasswordNotFound(t *testing.T) {
+	service := newTestAuthService(repoImpl.NewMemoryUserRepository(), repoImpl.NewMemoryAuditRepository(), testPasswordConfig)
+
+	err := service.SetPassword(context.Background(), 7, input.SetPasswordIn{Password: "caballo correcto"})
+
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+}
+
+// Caso de prueba: los intentos fallidos bloquean la cuenta, el bloqueo se audita y un administrador lo levanta
+func TestLoginLockout(t *testing.T) {
+	repo, audit := repoImpl.NewMemoryUserRepository(), repoImpl.NewMemoryAuditRepository()
+	service := newTestAuthService(repo, audit, testPasswordConfig)
+	user := createTestUser(t, repo)
+	ctx := requestctx.WithClient(context.Background(), requestctx.Client{IP: "192.0.2.1"})
+	require.NoError(t, service.SetPassword(ctx, user.ID, input.SetPasswordIn{Password: "caballo correcto"}))
+
+	// El correo y el nombre de usuario cuentan para la misma cuenta
+	for _, login := range []string{"ana", "ANA@example.com", "ana"} {
+		_, err := service.Login(ctx, input.LoginIn{Login: login, Password: "caballo incorrecto"})
+		assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+	}
+	_, err := service.Login(ctx, input.LoginIn{Login: "ana", Password: "caballo correcto"})
+	assert.ErrorIs(t, err, apperrors.ErrTooManyRequests, "cuenta bloqueada")
+	var locked *lockout.LockedError
+	require.ErrorAs(t, err, &locked)
+	assert.True(t, locked.Locked)
+
+	lockoutOut, err := service.GetLockout(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, lockoutOut.Locked)
+	require.NotNil(t, lockoutOut.LockedUntil)
+
+	page, err := audit.GetAuditRecords(ctx, repositories.AuditQuery{Operation: models.AuditLock, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Records, 1)
+	assert.Equal(t, *lockoutOut.LockedUntil, page.Records[0].Changes["locked_until"].After)
+
+	admin := requestctx.WithActor(ctx, "99")
+	require.NoError(t, service.Unlock(admin, user.ID))
+	require.NoError(t, service.Unlock(admin, user.ID))
+	_, err = service.Login(ctx, input.LoginIn{Login: "ana", Password: "caballo correcto"})
+	assert.NoError(t, err)
+
+	// Solo el desbloqueo efectivo queda en la auditoría, con quien lo hizo
+	page, err = audit.GetAuditRecords(ctx, repositories.AuditQuery{Operation: models.AuditUnlock, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Records, 1)
+	assert.Equal(t, "99", page.Records[0].Actor)
+
+	_, err = service.GetLockout(ctx, 99)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	assert.ErrorIs(t, service.Unlock(ctx, 99), apperrors.ErrNotFound)
+}
+
+// Caso de prueba: los intentos fallidos simultáneos no pasan del máximo y dejan la cuenta bloqueada
+func TestLoginLockoutConcurrent(t *testing.T) {
+	repo, audit := repoImpl.NewMemoryUserRepository(), repoImpl.NewMemoryAuditRepository()
+	service := newTestAuthService(repo, audit, testPasswordConfig)
+	user := createTestUser(t, repo)
+	ctx := requestctx.WithClient(context.Background(), requestctx.Client{IP: "192.0.2.1"})
+	require.NoError(t, service.SetPassword(ctx, user.ID, input.SetPasswordIn{Password: "caballo correcto"}))
+
+	const attempts = 8
+	errs := make([]error, attempts)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = service.Login(ctx, input.LoginIn{Login: "ana", Password: "caballo incorrecto"})
+		}(i)
+	}
+	wg.Wait()
+
+	var unauthorized int
+	for _, err := range errs {
+		if errors.Is(err, apperrors.ErrUnauthorized) {
+			unauthorized++
+		} else {
+			assert.ErrorIs(t, err, apperrors.ErrTooManyRequests)
+		}
+	}
+	assert.Equal(t, testLockoutConfig.MaxUserFailures, unauthorized)
+
+	_, err := service.Login(ctx, input.LoginIn{Login: "ana", Password: "caballo correcto"})
+	assert.ErrorIs(t, err, apperrors.ErrTooManyRequests)
+	page, err := audit.GetAuditRecords(ctx, repositories.AuditQuery{Operation: models.AuditLock, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, page.Records, 1)
+}
+
+// Caso de prueba: los intentos desde una IP se cuentan aunque cada uno use otra cuenta
+func TestLoginLockoutIP(t *testing.T) {
+	repo := repoImpl.NewMemoryUserRepository()
+	service := newTestAuthService(repo, repoImpl.NewMemoryAuditRepository(), testPasswordConfig)
+	user := createTestUser(t, repo)
+	ctx := requestctx.WithClient(context.Background(), requestctx.Client{IP: "192.0.2.1"})
+	require.NoError(t, service.SetPassword(ctx, user.ID, input.SetPasswordIn{Password: "caballo correcto"}))
+
+	for i := 0; i < testLockoutConfig.MaxIPFailures; i++ {
+		_, err := service.Login(ctx, input.LoginIn{Login: "nadie" + strconv.Itoa(i), Password: "caballo correcto"})
+		assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+	}
+	_, err := service.Login(ctx, input.LoginIn{Login: "ana", Password: "caballo correcto"})
+	assert.ErrorIs(t, err, apperrors.ErrTooManyRequests)
+
+	// Desde otra IP la cuenta sigue disponible
+	other := requestctx.WithClient(context.Background(), requestctx.Client{IP: "192.0.2.2"})
+	_, err = service.Login(other, input.LoginIn{Login: "ana", Password: "caballo correcto"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,151 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/models"
+	"application/requestctx"
+	"application/tokens"
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+)
+
+var (
+	errInvalidRefreshToken = errors.New("el refresh token no existe, ya se usó o su sesión no está activa")
+	errRefreshTokenReused  = errors.New("se reutilizó un refresh token")
+)
+
+// Refresh cambia un refresh token por un token de acceso y un refresh token nuevos. Usar
+// otra vez un token ya cambiado indica que pudo robarse, así que se revoca la sesión.
+func (s *AuthServiceImpl) Refresh(ctx context.Context, refreshIn input.RefreshIn) (output.TokenOut, error) {
+	token, err := s.sessions.GetRefreshToken(ctx, tokens.HashRefreshToken(refreshIn.RefreshToken))
+	if err != nil {
+		return output.TokenOut{}, invalidRefreshToken(err)
+	}
+	session, err := s.sessions.GetSession(ctx, token.SessionID)
+	if err != nil {
+		return output.TokenOut{}, invalidRefreshToken(err)
+	}
+	if token.UsedAt != nil {
+		return output.TokenOut{}, s.revokeReused(ctx, session)
+	}
+	now := time.Now().UTC()
+	if !session.Active(now) {
+		return output.TokenOut{}, apperrors.Unauthorized(errInvalidRefreshToken)
+	}
+	user, err := s.repo.GetUserByID(ctx, session.UserID)
+	if err != nil {
+		return output.TokenOut{}, invalidRefreshToken(err)
+	}
+
+	raw, hash, err := tokens.NewRefreshToken()
+	if err != nil {
+		return output.TokenOut{}, err
+	}
+	client := requestctx.ClientFrom(ctx)
+	if client.IP != "" {
+		session.IP = client.IP
+	}
+	if client.UserAgent != "" {
+		session.UserAgent = truncate(client.UserAgent, 255)
+	}
+	session.LastUsedAt, session.ExpiresAt = now, now.Add(s.tokens.RefreshTTL())
+	if err := s.sessions.RotateRefreshToken(ctx, token.Hash, hash, session); err != nil {
+		// Otra renovación con el mismo token se adelantó
+		if errors.Is(err, apperrors.ErrConflict) {
+			return output.TokenOut{}, s.revokeReused(ctx, session)
+		}
+		return output.TokenOut{}, err
+	}
+	return s.issueTokens(user, session, raw)
+}
+
+// Logout revoca la sesión del refresh token. Es idempotente: un token desconocido o de
+// una sesión ya revocada no es un error.
+func (s *AuthServiceImpl) Logout(ctx context.Context, refreshIn input.RefreshIn) error {
+	token, err := s.sessions.GetRefreshToken(ctx, tokens.HashRefreshToken(refreshIn.RefreshToken))
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = s.sessionService.EndSession(ctx, token.SessionID)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// startSession abre una sesión para el usuario con los datos del cliente de la solicitud.
+// multiFactor indica si se verificó el segundo factor; la sesión lo conserva al renovarse.
+func (s *AuthServiceImpl) startSession(ctx context.Context, user *models.User, multiFactor bool) (*models.Session, string, error) {
+	raw, hash, err := tokens.NewRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now().UTC()
+	client := requestctx.ClientFrom(ctx)
+	session := &models.Session{
+		UserID:     user.ID,
+		UserAgent:  truncate(client.UserAgent, 255),
+		IP:         client.IP,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.tokens.RefreshTTL()),
+		MFA:        multiFactor,
+	}
+	if err := s.sessions.CreateSession(ctx, session, hash); err != nil {
+		return nil, "", err
+	}
+	return session, raw, nil
+}
+
+// issueTokens emite el token de acceso de la sesión junto con su refresh token
+func (s *AuthServiceImpl) issueTokens(user *models.User, session *models.Session, refreshToken string) (output.TokenOut, error) {
+	subject := strconv.FormatUint(uint64(user.ID), 10)
+	amr := []string{tokens.AMRPassword}
+	if session.MFA {
+		amr = append(amr, tokens.AMROTP, tokens.AMRMultiFactor)
+	}
+	accessToken, expiresAt, err := s.tokens.IssueForSession(subject, stringValue(user.Username), strconv.FormatUint(uint64(session.ID), 10), amr)
+	if err != nil {
+		return output.TokenOut{}, err
+	}
+	tokenOut := output.TokenOut{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(time.Until(expiresAt).Round(time.Second).Seconds()),
+		RefreshToken: refreshToken,
+	}
+	return tokenOut, nil
+}
+
+// revokeReused revoca la sesión de un refresh token reutilizado
+func (s *AuthServiceImpl) revokeReused(ctx context.Context, session *models.Session) error {
+	log.Printf("Se reutilizó un refresh token de la sesión %d del usuario %d; se revoca la sesión", session.ID, session.UserID)
+	if err := s.sessionService.EndSession(ctx, session.ID); err != nil {
+		return err
+	}
+	return apperrors.Unauthorized(errRefreshTokenReused)
+}
+
+// invalidRefreshToken responde como credencial inválida lo que no se encuentra
+func invalidRefreshToken(err error) error {
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return apperrors.Unauthorized(errInvalidRefreshToken)
+	}
+	return err
+}
+
+// truncate recorta s a max runas
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
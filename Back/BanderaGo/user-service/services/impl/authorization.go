@@ -0,0 +1,59 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/requestctx"
+	"context"
+	"errors"
+)
+
+var (
+	errNotSelf        = errors.New("sin el permiso solo se permite la operación sobre el propio usuario")
+	errNotOwner       = errors.New("solo el propio usuario puede hacer la operación")
+	errNoSecondFactor = errors.New("la operación requiere una sesión con segundo factor")
+	errNoPermission   = errors.New("la operación requiere un permiso que la identidad no tiene")
+)
+
+// authorizeSelf permite la operación sobre el usuario id a quien tiene el permiso o es
+// ese mismo usuario. Repite la regla de las rutas para que no dependa de cómo se
+// configuren; las operaciones sin identidad, como las de los trabajos, no se restringen.
+func authorizeSelf(ctx context.Context, id uint, permission string) error {
+	identity, ok := requestctx.IdentityFrom(ctx)
+	if !ok || identity.Can(permission) || identity.IsUser(id) {
+		return nil
+	}
+	return apperrors.Forbidden(errNotSelf)
+}
+
+// authorizeOwner permite la operación sobre el usuario id solo a ese mismo usuario, sin
+// importar sus permisos
+func authorizeOwner(ctx context.Context, id uint) error {
+	identity, ok := requestctx.IdentityFrom(ctx)
+	if !ok || identity.IsUser(id) {
+		return nil
+	}
+	return apperrors.Forbidden(errNotOwner)
+}
+
+// authorizeSecondFactor permite la operación a quien tiene el permiso o al propio usuario
+// en una sesión en la que verificó el segundo factor
+func authorizeSecondFactor(ctx context.Context, id uint, permission string) error {
+	identity, ok := requestctx.IdentityFrom(ctx)
+	if !ok || identity.Can(permission) || (identity.IsUser(id) && identity.MFA) {
+		return nil
+	}
+	if identity.IsUser(id) {
+		return apperrors.Forbidden(errNoSecondFactor)
+	}
+	return apperrors.Forbidden(errNotSelf)
+}
+
+// authorizePermission exige el permiso cuando una operación lo necesita además del que ya
+// pide su ruta, p. ej. desactivar a un usuario al reemplazarlo por SCIM
+func authorizePermission(ctx context.Context, permission string) error {
+	identity, ok := requestctx.IdentityFrom(ctx)
+	if !ok || identity.Can(permission) {
+		return nil
+	}
+	return apperrors.Forbidden(errNoPermission)
+}
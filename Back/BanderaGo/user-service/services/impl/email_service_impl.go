@@ -0,0 +1,238 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/config"
+	"application/dtos/input"
+	"application/lockout"
+	"application/mailer"
+	"application/models"
+	"application/passwords"
+	"application/persistence/repositories"
+	"application/requestctx"
+	"application/services"
+	"application/tokens"
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// resetMailTimeout limita el envío en segundo plano del enlace para restablecer la contraseña
+const resetMailTimeout = time.Minute
+
+var (
+	errInvalidEmailToken = errors.New("el enlace no es válido, venció o ya se usó")
+	errEmailVerified     = errors.New("el correo del usuario ya está verificado")
+)
+
+// EmailServiceImpl envía y consume los enlaces de un solo uso para verificar el correo y
+// restablecer la contraseña. Solo se guarda el hash de cada token; el token viaja en el
+// enlace del correo.
+type EmailServiceImpl struct {
+	users       repositories.UserRepository
+	emailTokens repositories.EmailTokenRepository
+	sessions    services.SessionService
+	audit       repositories.AuditRepository
+	tx          repositories.Transactor
+	hasher      *passwords.Hasher
+	policy      passwords.Policy
+	limiter     *lockout.Limiter
+	// resetLimiter limita las solicitudes de restablecimiento por correo y por IP
+	resetLimiter *lockout.Limiter
+	mail         mailer.Mailer
+	templates    *mailer.Templates
+	signer       *tokens.LinkSigner
+	mailConfig   config.MailConfig
+	// dispatch ejecuta en segundo plano el envío de los enlaces para restablecer la contraseña
+	dispatch func(send func())
+}
+
+func NewEmailService(users repositories.UserRepository, emailTokens repositories.EmailTokenRepository, sessions services.SessionService, audit repositories.AuditRepository, tx repositories.Transactor, hasher *passwords.Hasher, policy passwords.Policy, limiter, resetLimiter *lockout.Limiter, mail mailer.Mailer, templates *mailer.Templates, signer *tokens.LinkSigner, mailConfig config.MailConfig) *EmailServiceImpl {
+	return &EmailServiceImpl{users: users, emailTokens: emailTokens, sessions: sessions, audit: audit, tx: tx, hasher: hasher, policy: policy, limiter: limiter, resetLimiter: resetLimiter, mail: mail, templates: templates, signer: signer, mailConfig: mailConfig,
+		dispatch: func(send func()) { go send() }}
+}
+
+// RequestVerification envía al usuario el enlace para verificar su correo. Un enlace
+// nuevo invalida los anteriores.
+func (s *EmailServiceImpl) RequestVerification(ctx context.Context, userID uint) error {
+	if err := authorizeSelf(ctx, userID, models.PermissionUsersUpdate); err != nil {
+		return err
+	}
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Email == nil {
+		return apperrors.Invalid(apperrors.Violations{{Field: "email", Rule: "required"}})
+	}
+	if user.EmailVerifiedAt != nil {
+		return apperrors.Conflict("email_verified", errEmailVerified)
+	}
+	if err := s.sendLink(ctx, user, tokens.PurposeVerifyEmail, mailer.TemplateVerifyEmail, "/verify-email", s.mailConfig.VerificationTTL); err != nil {
+		return apperrors.Unavailable(err)
+	}
+	return nil
+}
+
+// VerifyEmail marca como verificado el correo al que se envió el enlace, siempre que el
+// usuario no lo haya cambiado desde entonces
+func (s *EmailServiceImpl) VerifyEmail(ctx context.Context, verifyIn input.VerifyEmailIn) error {
+	hash, err := s.signer.Verify(tokens.PurposeVerifyEmail, verifyIn.Token)
+	if err != nil {
+		return apperrors.Unauthorized(errInvalidEmailToken)
+	}
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now().UTC()
+		user, token, err := s.useToken(ctx, hash, tokens.PurposeVerifyEmail, now)
+		if err != nil {
+			return err
+		}
+		if err := s.users.VerifyEmail(ctx, user.ID, token.Email, now); err != nil {
+			return err
+		}
+		verified := *user
+		verified.EmailVerifiedAt = &now
+		ctx = requestctx.WithActor(ctx, strconv.FormatUint(uint64(user.ID), 10))
+		return s.audit.CreateAuditRecord(ctx, newAuditRecord(ctx, models.AuditVerifyEmail, user.ID, user, &verified))
+	})
+}
+
+// RequestPasswordReset envía el enlace para restablecer la contraseña al usuario con ese
+// correo. Responde igual si el correo no existe o no se pudo enviar, para no revelar qué
+// correos están registrados: la búsqueda y el envío siguen en segundo plano para que la
+// respuesta tampoco tarde distinto. Las solicitudes se limitan por correo y por IP.
+func (s *EmailServiceImpl) RequestPasswordReset(ctx context.Context, forgotIn input.ForgotPasswordIn) error {
+	email := strings.ToLower(normalizeText(forgotIn.Email))
+	if _, err := s.resetLimiter.Reserve(ctx, lockout.ResetKey(email), requestctx.ClientFrom(ctx).IP); err != nil {
+		return tooManyAttempts(err)
+	}
+
+	// El envío conserva el idioma de la solicitud pero no termina con ella
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), resetMailTimeout)
+	s.dispatch(func() {
+		defer cancel()
+		s.sendReset(ctx, email)
+	})
+	return nil
+}
+
+// sendReset envía el enlace si el correo es de un usuario; los errores solo quedan en el log
+func (s *EmailServiceImpl) sendReset(ctx context.Context, email string) {
+	user, err := s.users.GetUserByLogin(ctx, email)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		log.Printf("No se pudo buscar al usuario para restablecer su contraseña: %v", err)
+		return
+	}
+	// El login también coincide con el nombre de usuario; el enlace solo se envía al correo
+	if !strings.EqualFold(stringValue(user.Email), email) {
+		return
+	}
+	if err := s.sendLink(ctx, user, tokens.PurposeResetPassword, mailer.TemplateResetPassword, "/reset-password", s.mailConfig.ResetTTL); err != nil {
+		log.Printf("No se pudo enviar el enlace para restablecer la contraseña del usuario %d: %v", user.ID, err)
+	}
+}
+
+// ResetPassword asigna la contraseña nueva, cierra las sesiones abiertas y desbloquea la
+// cuenta del usuario del enlace
+func (s *EmailServiceImpl) ResetPassword(ctx context.Context, resetIn input.ResetPasswordIn) error {
+	if err := apperrors.Invalid(s.policy.Check("password", resetIn.Password)); err != nil {
+		return err
+	}
+	tokenHash, err := s.signer.Verify(tokens.PurposeResetPassword, resetIn.Token)
+	if err != nil {
+		return apperrors.Unauthorized(errInvalidEmailToken)
+	}
+	hash, err := s.hasher.Hash(resetIn.Password)
+	if err != nil {
+		return err
+	}
+
+	var userID uint
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now().UTC()
+		before, _, err := s.useToken(ctx, tokenHash, tokens.PurposeResetPassword, now)
+		if err != nil {
+			return err
+		}
+		user := *before
+		user.PasswordHash, user.PasswordChangedAt = &hash, &now
+		if err := s.users.UpdatePassword(ctx, &user); err != nil {
+			return err
+		}
+		ctx = requestctx.WithActor(ctx, strconv.FormatUint(uint64(user.ID), 10))
+		if err := s.audit.CreateAuditRecord(ctx, newAuditRecord(ctx, models.AuditPassword, user.ID, before, &user)); err != nil {
+			return err
+		}
+		userID = user.ID
+		return s.sessions.EndUserSessions(ctx, user.ID)
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.limiter.Unlock(ctx, lockout.UserKey(userID)); err != nil {
+		log.Printf("No se pudo desbloquear al usuario %d después de restablecer su contraseña: %v", userID, err)
+	}
+	return nil
+}
+
+// sendLink guarda un token nuevo para el usuario y le envía el enlace con la plantilla
+// indicada en el idioma de la solicitud
+func (s *EmailServiceImpl) sendLink(ctx context.Context, user *models.User, purpose, template, path string, ttl time.Duration) error {
+	raw, hash, err := s.signer.New(purpose)
+	if err != nil {
+		return err
+	}
+	email := stringValue(user.Email)
+	token := &models.EmailToken{
+		Hash:      hash,
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Email:     email,
+		ExpiresAt: time.Now().UTC().Add(ttl),
+	}
+	if err := s.emailTokens.CreateEmailToken(ctx, token); err != nil {
+		return err
+	}
+
+	message, err := s.templates.Render(requestctx.Language(ctx), template, mailer.LinkData{
+		Name:      user.Name,
+		Email:     email,
+		Link:      s.mailConfig.BaseURL + path + "?token=" + url.QueryEscape(raw),
+		ExpiresAt: token.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	message.To = email
+	return s.mail.Send(ctx, message)
+}
+
+// useToken consume el token y devuelve a su usuario. El token vencido, usado, de otro
+// propósito o enviado a un correo que el usuario ya cambió no es válido.
+func (s *EmailServiceImpl) useToken(ctx context.Context, hash, purpose string, now time.Time) (*models.User, *models.EmailToken, error) {
+	token, err := s.emailTokens.UseEmailToken(ctx, hash, purpose, now)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return nil, nil, apperrors.Unauthorized(errInvalidEmailToken)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := s.users.GetUserByID(ctx, token.UserID)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return nil, nil, apperrors.Unauthorized(errInvalidEmailToken)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if !strings.EqualFold(stringValue(user.Email), token.Email) {
+		return nil, nil, apperrors.Unauthorized(errInvalidEmailToken)
+	}
+	return user, token, nil
+}
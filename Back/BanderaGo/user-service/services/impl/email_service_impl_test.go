@@ -0,0 +1,207 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/config"
+	"application/dtos/input"
+	"application/lockout"
+	"application/mailer"
+	"application/models"
+	"application/passwords"
+	"application/persistence/repositories"
+	repoImpl "application/persistence/repositories/impl"
+	"application/requestctx"
+	"application/tokens"
+	"context"
+	"net/url"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMailConfig = config.MailConfig{BaseURL: "https://app.example.com", VerificationTTL: time.Hour, ResetTTL: time.Hour}
+
+var linkToken = regexp.MustCompile(`token=(\S+)`)
+
+type emailTestEnv struct {
+	users    *repoImpl.MemoryUserRepository
+	sessions *repoImpl.MemorySessionRepository
+	// sessionService recuerda las sesiones activas para comprobar que la revocación las olvida
+	sessionService *SessionServiceImpl
+	audit          *repoImpl.MemoryAuditRepository
+	limiter        *lockout.Limiter
+	resets         *lockout.Limiter
+	mail           *mailer.MemoryMailer
+	service        *EmailServiceImpl
+}
+
+func newEmailTestEnv(t *testing.T) *emailTestEnv {
+	t.Helper()
+	templates, err := mailer.NewTemplates("es")
+	require.NoError(t, err)
+	env := &emailTestEnv{
+		users:    repoImpl.NewMemoryUserRepository(),
+		sessions: repoImpl.NewMemorySessionRepository(),
+		audit:    repoImpl.NewMemoryAuditRepository(),
+		limiter:  newTestLimiter(),
+		resets:   newTestLimiter(),
+		mail:     mailer.NewMemoryMailer(),
+	}
+	env.sessionService = NewSessionService(env.sessions, env.users, time.Minute)
+	env.service = NewEmailService(env.users, repoImpl.NewMemoryEmailTokenRepository(), env.sessionService, env.audit, repoImpl.NewMemoryTransactor(),
+		passwords.NewHasher(testPasswordConfig), passwords.NewPolicy(testPasswordConfig), env.limiter, env.resets, env.mail, templates,
+		tokens.NewLinkSigner([]byte(testAuthConfig.Secret)), testMailConfig)
+	// Los enlaces se envían antes de responder para poder comprobarlos
+	env.service.dispatch = func(send func()) { send() }
+	return env
+}
+
+// sentToken devuelve el token del enlace del último correo enviado a la dirección
+func (env *emailTestEnv) sentToken(t *testing.T, to string) string {
+	t.Helper()
+	message, ok := env.mail.Last(to)
+	require.True(t, ok)
+	match := linkToken.FindStringSubmatch(message.Text)
+	require.Len(t, match, 2)
+	token, err := url.QueryUnescape(match[1])
+	require.NoError(t, err)
+	return token
+}
+
+// Caso de prueba: el usuario pide el enlace, verifica su correo una sola vez y no puede pedir otro
+func TestEmailVerification(t *testing.T) {
+	env := newEmailTestEnv(t)
+	user := createTestUser(t, env.users)
+	ctx := requestctx.WithLanguage(requestctx.WithIdentity(context.Background(), requestctx.Identity{Subject: "1"}), "en")
+
+	assert.ErrorIs(t, env.service.RequestVerification(requestctx.WithIdentity(context.Background(), requestctx.Identity{Subject: "2"}), user.ID), apperrors.ErrForbidden)
+	require.NoError(t, env.service.RequestVerification(ctx, user.ID))
+	message, ok := env.mail.Last("ana@example.com")
+	require.True(t, ok)
+	assert.Equal(t, "Confirm your email", message.Subject)
+	assert.Contains(t, message.Text, "https://app.example.com/verify-email?token=")
+	token := env.sentToken(t, "ana@example.com")
+
+	// Un token alterado o de otro propósito no sirve
+	assert.ErrorIs(t, env.service.VerifyEmail(context.Background(), input.VerifyEmailIn{Token: token + "x"}), apperrors.ErrUnauthorized)
+	assert.ErrorIs(t, env.service.ResetPassword(context.Background(), input.ResetPasswordIn{Token: token, Password: "otra clave segura 123"}), apperrors.ErrUnauthorized)
+
+	require.NoError(t, env.service.VerifyEmail(context.Background(), input.VerifyEmailIn{Token: token}))
+	verified, err := env.users.GetUserByID(context.Background(), user.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, verified.EmailVerifiedAt)
+	assert.ErrorIs(t, env.service.VerifyEmail(context.Background(), input.VerifyEmailIn{Token: token}), apperrors.ErrUnauthorized)
+
+	page, err := env.audit.GetAuditRecords(context.Background(), repositories.AuditQuery{Operation: models.AuditVerifyEmail, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Records, 1)
+	assert.Equal(t, "1", page.Records[0].Actor)
+	assert.Contains(t, page.Records[0].Changes, "email_verified_at")
+
+	assert.ErrorIs(t, env.service.RequestVerification(ctx, user.ID), apperrors.ErrConflict)
+}
+
+// Caso de prueba: el enlace deja de valer si el usuario cambia su correo o pide otro
+func TestEmailVerificationInvalidated(t *testing.T) {
+	env := newEmailTestEnv(t)
+	user := createTestUser(t, env.users)
+	ctx := requestctx.WithIdentity(context.Background(), requestctx.Identity{Subject: "1"})
+
+	require.NoError(t, env.service.RequestVerification(ctx, user.ID))
+	first := env.sentToken(t, "ana@example.com")
+	require.NoError(t, env.service.RequestVerification(ctx, user.ID))
+	assert.ErrorIs(t, env.service.VerifyEmail(ctx, input.VerifyEmailIn{Token: first}), apperrors.ErrUnauthorized)
+
+	second := env.sentToken(t, "ana@example.com")
+	changed := *user
+	changed.Email = optional("ana.diaz@example.com")
+	require.NoError(t, env.users.UpdateUser(context.Background(), user.ID, &changed))
+	assert.ErrorIs(t, env.service.VerifyEmail(ctx, input.VerifyEmailIn{Token: second}), apperrors.ErrUnauthorized)
+}
+
+// Caso de prueba: restablecer la contraseña con el enlace cierra las sesiones y desbloquea la cuenta
+func TestPasswordReset(t *testing.T) {
+	env := newEmailTestEnv(t)
+	user := createTestUser(t, env.users)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	session := &models.Session{UserID: user.ID, LastUsedAt: now, ExpiresAt: now.Add(time.Hour)}
+	require.NoError(t, env.sessions.CreateSession(ctx, session, "refresh"))
+	sessionID := strconv.FormatUint(uint64(session.ID), 10)
+	require.NoError(t, env.sessionService.VerifySession(ctx, sessionID))
+	for i := 0; i < testLockoutConfig.MaxUserFailures; i++ {
+		_, err := env.limiter.Reserve(ctx, lockout.UserKey(user.ID), "")
+		require.NoError(t, err)
+	}
+
+	// Un correo desconocido o un nombre de usuario responden igual pero no envían nada
+	require.NoError(t, env.service.RequestPasswordReset(ctx, input.ForgotPasswordIn{Email: "nadie@example.com"}))
+	require.NoError(t, env.service.RequestPasswordReset(ctx, input.ForgotPasswordIn{Email: "ana"}))
+	assert.Empty(t, env.mail.Messages())
+
+	require.NoError(t, env.service.RequestPasswordReset(ctx, input.ForgotPasswordIn{Email: " ANA@example.com "}))
+	message, ok := env.mail.Last("ana@example.com")
+	require.True(t, ok)
+	assert.Contains(t, message.Text, "https://app.example.com/reset-password?token=")
+	token := env.sentToken(t, "ana@example.com")
+
+	assert.ErrorIs(t, env.service.VerifyEmail(ctx, input.VerifyEmailIn{Token: token}), apperrors.ErrUnauthorized)
+	err := env.service.ResetPassword(ctx, input.ResetPasswordIn{Token: token, Password: "corta"})
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+
+	require.NoError(t, env.service.ResetPassword(ctx, input.ResetPasswordIn{Token: token, Password: "otra clave segura 123"}))
+	updated, err := env.users.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, updated.PasswordHash)
+	match, _, err := passwords.NewHasher(testPasswordConfig).Verify("otra clave segura 123", *updated.PasswordHash)
+	require.NoError(t, err)
+	assert.True(t, match)
+
+	sessions, err := env.sessions.GetUserSessions(ctx, user.ID, time.Now().UTC())
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+	assert.ErrorIs(t, env.sessionService.VerifySession(ctx, sessionID), apperrors.ErrUnauthorized)
+	entry, err := env.limiter.Status(ctx, lockout.UserKey(user.ID))
+	require.NoError(t, err)
+	assert.Zero(t, entry.Failures)
+
+	page, err := env.audit.GetAuditRecords(ctx, repositories.AuditQuery{Operation: models.AuditPassword, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Records, 1)
+	assert.Equal(t, "1", page.Records[0].Actor)
+
+	err = env.service.ResetPassword(ctx, input.ResetPasswordIn{Token: token, Password: "una tercera clave 456"})
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+}
+
+// Caso de prueba: las solicitudes de restablecimiento se limitan por correo, exista o no, y por IP
+func TestPasswordResetRateLimit(t *testing.T) {
+	env := newEmailTestEnv(t)
+	createTestUser(t, env.users)
+	ctx := requestctx.WithClient(context.Background(), requestctx.Client{IP: "192.0.2.1"})
+
+	for _, email := range []string{"ana@example.com", "nadie@example.com"} {
+		for i := 0; i < testLockoutConfig.MaxUserFailures; i++ {
+			require.NoError(t, env.service.RequestPasswordReset(ctx, input.ForgotPasswordIn{Email: email}))
+		}
+		err := env.service.RequestPasswordReset(ctx, input.ForgotPasswordIn{Email: email})
+		assert.ErrorIs(t, err, apperrors.ErrTooManyRequests, email)
+		var locked *lockout.LockedError
+		require.ErrorAs(t, err, &locked)
+		assert.True(t, locked.Locked)
+	}
+	assert.Len(t, env.mail.Messages(), testLockoutConfig.MaxUserFailures)
+
+	// La IP se bloquea al llegar a su máximo con correos distintos; otra IP sigue pudiendo pedir
+	for i := 2 * testLockoutConfig.MaxUserFailures; i < testLockoutConfig.MaxIPFailures; i++ {
+		require.NoError(t, env.service.RequestPasswordReset(ctx, input.ForgotPasswordIn{Email: "otro" + strconv.Itoa(i) + "@example.com"}))
+	}
+	err := env.service.RequestPasswordReset(ctx, input.ForgotPasswordIn{Email: "ultimo@example.com"})
+	assert.ErrorIs(t, err, apperrors.ErrTooManyRequests)
+	other := requestctx.WithClient(context.Background(), requestctx.Client{IP: "192.0.2.2"})
+	assert.NoError(t, env.service.RequestPasswordReset(other, input.ForgotPasswordIn{Email: "ultimo@example.com"}))
+}
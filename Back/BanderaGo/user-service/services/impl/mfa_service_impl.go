@@ -0,0 +1,166 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/mfa"
+	"application/models"
+	"application/persistence/repositories"
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"time"
+)
+
+var (
+	errMFAConfirmed  = errors.New("el segundo factor ya está confirmado; hay que desactivarlo antes de inscribir otro")
+	errMFANotEnabled = errors.New("el usuario no tiene un segundo factor confirmado")
+)
+
+// MFAServiceImpl inscribe y administra el segundo factor TOTP de los usuarios. Solo el
+// propio usuario inscribe su segundo factor; regenerar los códigos exige además una
+// sesión con segundo factor.
+type MFAServiceImpl struct {
+	mfa    repositories.MFARepository
+	users  repositories.UserRepository
+	issuer string
+}
+
+func NewMFAService(mfa repositories.MFARepository, users repositories.UserRepository, issuer string) *MFAServiceImpl {
+	return &MFAServiceImpl{mfa: mfa, users: users, issuer: issuer}
+}
+
+func (s *MFAServiceImpl) GetMFA(ctx context.Context, userID uint) (output.MFAOut, error) {
+	if err := authorizeSelf(ctx, userID, models.PermissionMFAManage); err != nil {
+		return output.MFAOut{}, err
+	}
+	if _, err := s.users.GetUserByID(ctx, userID); err != nil {
+		return output.MFAOut{}, err
+	}
+	enrollment, err := s.mfa.GetMFA(ctx, userID)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return output.MFAOut{}, nil
+	}
+	if err != nil {
+		return output.MFAOut{}, err
+	}
+	if !enrollment.Enabled() {
+		return output.MFAOut{}, nil
+	}
+	remaining, err := s.mfa.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return output.MFAOut{}, err
+	}
+	return output.MFAOut{Enabled: true, ConfirmedAt: enrollment.ConfirmedAt, RecoveryCodesRemaining: remaining}, nil
+}
+
+// EnrollTOTP genera un secreto nuevo que queda pendiente hasta confirmarlo con un código.
+// Volver a inscribirse antes de confirmar reemplaza el secreto pendiente.
+func (s *MFAServiceImpl) EnrollTOTP(ctx context.Context, userID uint) (output.TOTPEnrollmentOut, error) {
+	if err := authorizeOwner(ctx, userID); err != nil {
+		return output.TOTPEnrollmentOut{}, err
+	}
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return output.TOTPEnrollmentOut{}, err
+	}
+	current, err := s.mfa.GetMFA(ctx, userID)
+	if err != nil && !errors.Is(err, apperrors.ErrNotFound) {
+		return output.TOTPEnrollmentOut{}, err
+	}
+	if current != nil && current.Enabled() {
+		return output.TOTPEnrollmentOut{}, apperrors.Conflict("mfa", errMFAConfirmed)
+	}
+
+	secret, err := mfa.GenerateSecret()
+	if err != nil {
+		return output.TOTPEnrollmentOut{}, err
+	}
+	if err := s.mfa.SaveMFA(ctx, &models.UserMFA{UserID: userID, Secret: secret}); err != nil {
+		return output.TOTPEnrollmentOut{}, err
+	}
+	uri := mfa.URI(s.issuer, accountName(user), secret)
+	png, err := mfa.QRCode(uri)
+	if err != nil {
+		return output.TOTPEnrollmentOut{}, err
+	}
+	enrollmentOut := output.TOTPEnrollmentOut{
+		Secret:     secret,
+		OTPAuthURI: uri,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}
+	return enrollmentOut, nil
+}
+
+// ConfirmTOTP activa el segundo factor pendiente si el código coincide y devuelve los
+// códigos de recuperación
+func (s *MFAServiceImpl) ConfirmTOTP(ctx context.Context, userID uint, confirmIn input.ConfirmTOTPIn) (output.RecoveryCodesOut, error) {
+	if err := authorizeOwner(ctx, userID); err != nil {
+		return output.RecoveryCodesOut{}, err
+	}
+	enrollment, err := s.mfa.GetMFA(ctx, userID)
+	if err != nil {
+		return output.RecoveryCodesOut{}, err
+	}
+	if enrollment.Enabled() {
+		return output.RecoveryCodesOut{}, apperrors.Conflict("mfa", errMFAConfirmed)
+	}
+	now := time.Now().UTC()
+	step, ok := mfa.Verify(enrollment.Secret, confirmIn.Code, now)
+	if !ok {
+		return output.RecoveryCodesOut{}, apperrors.Invalid(apperrors.Violations{{Field: "code", Rule: "totp"}})
+	}
+
+	codes, hashes, err := mfa.NewRecoveryCodes(mfa.RecoveryCodeCount)
+	if err != nil {
+		return output.RecoveryCodesOut{}, err
+	}
+	if err := s.mfa.ConfirmMFA(ctx, userID, step, now, hashes); err != nil {
+		return output.RecoveryCodesOut{}, err
+	}
+	return output.RecoveryCodesOut{RecoveryCodes: codes}, nil
+}
+
+// RegenerateRecoveryCodes reemplaza los códigos de recuperación; los anteriores dejan de valer
+func (s *MFAServiceImpl) RegenerateRecoveryCodes(ctx context.Context, userID uint) (output.RecoveryCodesOut, error) {
+	if err := authorizeSecondFactor(ctx, userID, models.PermissionMFAManage); err != nil {
+		return output.RecoveryCodesOut{}, err
+	}
+	enrollment, err := s.mfa.GetMFA(ctx, userID)
+	if err != nil {
+		return output.RecoveryCodesOut{}, err
+	}
+	if !enrollment.Enabled() {
+		return output.RecoveryCodesOut{}, apperrors.NotFoundField("mfa", errMFANotEnabled)
+	}
+	codes, hashes, err := mfa.NewRecoveryCodes(mfa.RecoveryCodeCount)
+	if err != nil {
+		return output.RecoveryCodesOut{}, err
+	}
+	if err := s.mfa.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return output.RecoveryCodesOut{}, err
+	}
+	return output.RecoveryCodesOut{RecoveryCodes: codes}, nil
+}
+
+// DisableMFA elimina el segundo factor, confirmado o pendiente, y sus códigos de
+// recuperación. Quien tiene el permiso lo hace, p. ej., si el usuario perdió el dispositivo.
+func (s *MFAServiceImpl) DisableMFA(ctx context.Context, userID uint) error {
+	if err := authorizeSecondFactor(ctx, userID, models.PermissionMFAManage); err != nil {
+		return err
+	}
+	return s.mfa.DeleteMFA(ctx, userID)
+}
+
+// accountName es el nombre con el que la aplicación de autenticación muestra la cuenta
+func accountName(user *models.User) string {
+	if user.Username != nil {
+		return *user.Username
+	}
+	if user.Email != nil {
+		return *user.Email
+	}
+	return strconv.FormatUint(uint64(user.ID), 10)
+}
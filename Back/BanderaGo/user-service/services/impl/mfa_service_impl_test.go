@@ -0,0 +1,118 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/mfa"
+	"application/models"
+	repoImpl "application/persistence/repositories/impl"
+	"application/requestctx"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: inscribir y confirmar el segundo factor lo exige al iniciar sesión
+func TestMFAEnrollmentAndLogin(t *testing.T) {
+	repo := repoImpl.NewMemoryUserRepository()
+	authService := newTestAuthService(repo, repoImpl.NewMemoryAuditRepository(), testPasswordConfig)
+	service := NewMFAService(authService.mfa, repo, "user-service")
+	user := createTestUser(t, repo)
+	require.NoError(t, authService.SetPassword(context.Background(), user.ID, input.SetPasswordIn{Password: "caballo correcto"}))
+	ctx := requestctx.WithIdentity(context.Background(), requestctx.Identity{Subject: "1"})
+
+	enrollment, err := service.EnrollTOTP(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(enrollment.OTPAuthURI, "otpauth://totp/user-service:ana?"))
+	assert.True(t, strings.HasPrefix(enrollment.QRCode, "data:image/png;base64,"))
+
+	// Mientras no se confirme no se pide el código
+	mfaOut, err := service.GetMFA(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, mfaOut.Enabled)
+	_, err = authService.Login(ctx, input.LoginIn{Login: "ana", Password: "caballo correcto"})
+	require.NoError(t, err)
+
+	_, err = service.ConfirmTOTP(ctx, user.ID, input.ConfirmTOTPIn{Code: "000000x"})
+	assert.Equal(t, apperrors.Violations{{Field: "code", Rule: "totp"}}, apperrors.ViolationsOf(err))
+	now := time.Now()
+	code, err := mfa.Code(enrollment.Secret, mfa.Step(now))
+	require.NoError(t, err)
+	codes, err := service.ConfirmTOTP(ctx, user.ID, input.ConfirmTOTPIn{Code: code})
+	require.NoError(t, err)
+	assert.Len(t, codes.RecoveryCodes, mfa.RecoveryCodeCount)
+	_, err = service.EnrollTOTP(ctx, user.ID)
+	assert.ErrorIs(t, err, apperrors.ErrConflict)
+
+	mfaOut, err = service.GetMFA(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, mfaOut.Enabled)
+	assert.Equal(t, int64(mfa.RecoveryCodeCount), mfaOut.RecoveryCodesRemaining)
+
+	// Sin código se pide el segundo factor; el código de la confirmación no se acepta otra vez
+	_, err = authService.IssueToken(ctx, input.TokenIn{GrantType: "password", Username: "ana", Password: "caballo correcto"})
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+	assert.Equal(t, "otp", apperrors.FieldOf(err))
+	_, err = authService.IssueToken(ctx, input.TokenIn{GrantType: "password", Username: "ana", Password: "caballo correcto", OTP: code})
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+	assert.Empty(t, apperrors.FieldOf(err))
+
+	next, err := mfa.Code(enrollment.Secret, mfa.Step(now)+1)
+	require.NoError(t, err)
+	tokenOut, err := authService.IssueToken(ctx, input.TokenIn{GrantType: "password", Username: "ana", Password: "caballo correcto", OTP: next})
+	require.NoError(t, err)
+	claims, err := authService.tokens.Verify(tokenOut.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, claims.MultiFactor())
+
+	// La sesión renovada conserva el segundo factor
+	refreshed, err := authService.Refresh(ctx, input.RefreshIn{RefreshToken: tokenOut.RefreshToken})
+	require.NoError(t, err)
+	claims, err = authService.tokens.Verify(refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, claims.MultiFactor())
+
+	// Cada código de recuperación vale una sola vez
+	recovery := strings.ToUpper(codes.RecoveryCodes[0])
+	_, err = authService.Login(ctx, input.LoginIn{Login: "ana", Password: "caballo correcto", OTP: recovery})
+	require.NoError(t, err)
+	_, err = authService.Login(ctx, input.LoginIn{Login: "ana", Password: "caballo correcto", OTP: recovery})
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+	mfaOut, err = service.GetMFA(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(mfa.RecoveryCodeCount-1), mfaOut.RecoveryCodesRemaining)
+}
+
+// Caso de prueba: regenerar los códigos o desactivar el segundo factor exige una sesión con segundo factor
+func TestMFAStepUp(t *testing.T) {
+	repo := repoImpl.NewMemoryUserRepository()
+	mfaRepo := repoImpl.NewMemoryMFARepository()
+	service := NewMFAService(mfaRepo, repo, "user-service")
+	user := createTestUser(t, repo)
+	background := context.Background()
+	require.NoError(t, mfaRepo.SaveMFA(background, &models.UserMFA{UserID: user.ID, Secret: "JBSWY3DPEHPK3PXP"}))
+	require.NoError(t, mfaRepo.ConfirmMFA(background, user.ID, 1, time.Now(), []string{"h1"}))
+
+	password := requestctx.WithIdentity(background, requestctx.Identity{Subject: "1"})
+	_, err := service.RegenerateRecoveryCodes(password, user.ID)
+	assert.ErrorIs(t, err, apperrors.ErrForbidden)
+	assert.ErrorIs(t, service.DisableMFA(password, user.ID), apperrors.ErrForbidden)
+
+	multiFactor := requestctx.WithIdentity(background, requestctx.Identity{Subject: "1", MFA: true})
+	codes, err := service.RegenerateRecoveryCodes(multiFactor, user.ID)
+	require.NoError(t, err)
+	assert.Len(t, codes.RecoveryCodes, mfa.RecoveryCodeCount)
+
+	// Otro usuario no inscribe el segundo factor ajeno, ni con el permiso
+	admin := requestctx.WithIdentity(background, requestctx.Identity{Subject: "2", Permissions: []string{models.PermissionMFAManage}})
+	_, err = service.EnrollTOTP(admin, user.ID)
+	assert.ErrorIs(t, err, apperrors.ErrForbidden)
+	require.NoError(t, service.DisableMFA(admin, user.ID))
+	assert.ErrorIs(t, service.DisableMFA(admin, user.ID), apperrors.ErrNotFound)
+	_, err = service.RegenerateRecoveryCodes(multiFactor, user.ID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+}
@@ -0,0 +1,189 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/models"
+	"application/oidc"
+	"application/persistence/repositories"
+	"application/requestctx"
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxOIDCClientNameLength = 100
+	maxRedirectURIs         = 10
+)
+
+var errPublicClient = errors.New("un cliente público no tiene secreto")
+
+type OIDCClientServiceImpl struct {
+	store repositories.OIDCRepository
+}
+
+func NewOIDCClientService(store repositories.OIDCRepository) *OIDCClientServiceImpl {
+	return &OIDCClientServiceImpl{store: store}
+}
+
+func (s *OIDCClientServiceImpl) GetClients(ctx context.Context) ([]output.OIDCClientOut, error) {
+	clients, err := s.store.GetClients(ctx)
+	if err != nil {
+		return nil, err
+	}
+	clientsOut := make([]output.OIDCClientOut, 0, len(clients))
+	for _, client := range clients {
+		clientsOut = append(clientsOut, oidcClientOut(client))
+	}
+	return clientsOut, nil
+}
+
+func (s *OIDCClientServiceImpl) GetClient(ctx context.Context, id string) (output.OIDCClientOut, error) {
+	client, err := s.store.GetClient(ctx, id)
+	if err != nil {
+		return output.OIDCClientOut{}, err
+	}
+	return oidcClientOut(client), nil
+}
+
+func (s *OIDCClientServiceImpl) CreateClient(ctx context.Context, clientIn input.OIDCClientIn) (output.OIDCClientCreatedOut, error) {
+	client := &models.OIDCClient{
+		Name:         normalizeText(clientIn.Name),
+		RedirectURIs: normalizeRedirectURIs(clientIn.RedirectURIs),
+		CreatedBy:    requestctx.Actor(ctx),
+	}
+	if err := validateOIDCClient(client); err != nil {
+		return output.OIDCClientCreatedOut{}, err
+	}
+	id, err := oidc.NewID()
+	if err != nil {
+		return output.OIDCClientCreatedOut{}, err
+	}
+	client.ID = id
+
+	var secret string
+	if !clientIn.Public {
+		var hash string
+		if secret, hash, err = oidc.NewSecret(); err != nil {
+			return output.OIDCClientCreatedOut{}, err
+		}
+		client.SecretHash = &hash
+	}
+	if err := s.store.CreateClient(ctx, client); err != nil {
+		return output.OIDCClientCreatedOut{}, err
+	}
+	return output.OIDCClientCreatedOut{OIDCClientOut: oidcClientOut(client), ClientSecret: secret}, nil
+}
+
+func (s *OIDCClientServiceImpl) UpdateClient(ctx context.Context, id string, clientIn input.UpdateOIDCClientIn) (output.OIDCClientOut, error) {
+	client, err := s.store.GetClient(ctx, id)
+	if err != nil {
+		return output.OIDCClientOut{}, err
+	}
+	client.Name, client.RedirectURIs = normalizeText(clientIn.Name), normalizeRedirectURIs(clientIn.RedirectURIs)
+	if err := validateOIDCClient(client); err != nil {
+		return output.OIDCClientOut{}, err
+	}
+	if err := s.store.UpdateClient(ctx, client); err != nil {
+		return output.OIDCClientOut{}, err
+	}
+	return s.GetClient(ctx, id)
+}
+
+func (s *OIDCClientServiceImpl) RotateClientSecret(ctx context.Context, id string) (output.OIDCClientCreatedOut, error) {
+	client, err := s.store.GetClient(ctx, id)
+	if err != nil {
+		return output.OIDCClientCreatedOut{}, err
+	}
+	if client.Public() {
+		return output.OIDCClientCreatedOut{}, apperrors.Conflict("public", errPublicClient)
+	}
+	secret, hash, err := oidc.NewSecret()
+	if err != nil {
+		return output.OIDCClientCreatedOut{}, err
+	}
+	client.SecretHash = &hash
+	if err := s.store.UpdateClient(ctx, client); err != nil {
+		return output.OIDCClientCreatedOut{}, err
+	}
+	return output.OIDCClientCreatedOut{OIDCClientOut: oidcClientOut(client), ClientSecret: secret}, nil
+}
+
+func (s *OIDCClientServiceImpl) DeleteClient(ctx context.Context, id string) error {
+	return s.store.DeleteClient(ctx, id)
+}
+
+// normalizeRedirectURIs quita los espacios y los repetidos sin cambiar el orden
+func normalizeRedirectURIs(uris []string) []string {
+	normalized := make([]string, 0, len(uris))
+	for _, uri := range uris {
+		uri = strings.TrimSpace(uri)
+		if uri != "" && !slices.Contains(normalized, uri) {
+			normalized = append(normalized, uri)
+		}
+	}
+	return normalized
+}
+
+// validateOIDCClient reporta juntas las reglas que no cumple el cliente
+func validateOIDCClient(client *models.OIDCClient) error {
+	var violations apperrors.Violations
+	switch {
+	case client.Name == "":
+		violations = append(violations, apperrors.Violation{Field: "name", Rule: "required"})
+	case utf8.RuneCountInString(client.Name) > maxOIDCClientNameLength:
+		violations = append(violations, apperrors.Violation{Field: "name", Rule: "max", Param: strconv.Itoa(maxOIDCClientNameLength)})
+	case !isPrintable(client.Name):
+		violations = append(violations, apperrors.Violation{Field: "name", Rule: "printable"})
+	}
+	switch {
+	case len(client.RedirectURIs) == 0:
+		violations = append(violations, apperrors.Violation{Field: "redirect_uris", Rule: "required"})
+	case len(client.RedirectURIs) > maxRedirectURIs:
+		violations = append(violations, apperrors.Violation{Field: "redirect_uris", Rule: "max_items", Param: strconv.Itoa(maxRedirectURIs)})
+	}
+	for _, uri := range client.RedirectURIs {
+		if !validRedirectURI(uri) {
+			violations = append(violations, apperrors.Violation{Field: "redirect_uris", Rule: "redirect_uri", Param: uri})
+		}
+	}
+	return apperrors.Invalid(violations)
+}
+
+// validRedirectURI acepta URIs absolutas sin fragmento (RFC 6749, sección 3.1.2) con
+// https, o con http solo en la interfaz de loopback para el desarrollo y las
+// aplicaciones nativas (RFC 8252, sección 7.3)
+func validRedirectURI(raw string) bool {
+	uri, err := url.Parse(raw)
+	if err != nil || uri.Host == "" || uri.Fragment != "" || uri.User != nil {
+		return false
+	}
+	switch uri.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := uri.Hostname()
+		ip := net.ParseIP(host)
+		return host == "localhost" || ip != nil && ip.IsLoopback()
+	}
+	return false
+}
+
+func oidcClientOut(client *models.OIDCClient) output.OIDCClientOut {
+	return output.OIDCClientOut{
+		ClientID:     client.ID,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectURIs,
+		Public:       client.Public(),
+		CreatedBy:    client.CreatedBy,
+		CreatedAt:    client.CreatedAt,
+		UpdatedAt:    client.UpdatedAt,
+	}
+}
@@ -0,0 +1,75 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/oidc"
+	repoImpl "application/persistence/repositories/impl"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: el secreto solo se muestra al crear o rotar y un cliente público no tiene
+func TestCreateOIDCClient(t *testing.T) {
+	repo := repoImpl.NewMemoryOIDCRepository()
+	service := NewOIDCClientService(repo)
+	ctx := adminContext()
+
+	created, err := service.CreateClient(ctx, input.OIDCClientIn{Name: " Portal ", RedirectURIs: []string{"https://portal.example.com/callback", " https://portal.example.com/callback"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Portal", created.Name)
+	assert.Equal(t, []string{"https://portal.example.com/callback"}, created.RedirectURIs)
+	assert.False(t, created.Public)
+	assert.Equal(t, "1", created.CreatedBy)
+	require.NotEmpty(t, created.ClientSecret)
+
+	stored, err := repo.GetClient(ctx, created.ClientID)
+	require.NoError(t, err)
+	assert.True(t, oidc.Matches(created.ClientSecret, *stored.SecretHash))
+
+	rotated, err := service.RotateClientSecret(ctx, created.ClientID)
+	require.NoError(t, err)
+	stored, err = repo.GetClient(ctx, created.ClientID)
+	require.NoError(t, err)
+	assert.False(t, oidc.Matches(created.ClientSecret, *stored.SecretHash))
+	assert.True(t, oidc.Matches(rotated.ClientSecret, *stored.SecretHash))
+
+	spa, err := service.CreateClient(ctx, input.OIDCClientIn{Name: "SPA", RedirectURIs: []string{"http://localhost:5173/callback"}, Public: true})
+	require.NoError(t, err)
+	assert.True(t, spa.Public)
+	assert.Empty(t, spa.ClientSecret)
+	_, err = service.RotateClientSecret(ctx, spa.ClientID)
+	assert.ErrorIs(t, err, apperrors.ErrConflict)
+
+	updated, err := service.UpdateClient(ctx, spa.ClientID, input.UpdateOIDCClientIn{Name: "SPA interna", RedirectURIs: []string{"http://127.0.0.1:5173/callback"}})
+	require.NoError(t, err)
+	assert.Equal(t, "SPA interna", updated.Name)
+	assert.True(t, updated.Public)
+
+	clients, err := service.GetClients(ctx)
+	require.NoError(t, err)
+	assert.Len(t, clients, 2)
+	require.NoError(t, service.DeleteClient(ctx, spa.ClientID))
+	_, err = service.GetClient(ctx, spa.ClientID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+}
+
+// Caso de prueba: solo se aceptan URIs https absolutas sin fragmento o http en loopback
+func TestCreateOIDCClientInvalid(t *testing.T) {
+	service := NewOIDCClientService(repoImpl.NewMemoryOIDCRepository())
+
+	_, err := service.CreateClient(adminContext(), input.OIDCClientIn{Name: "  ", RedirectURIs: []string{
+		"http://portal.example.com/callback", "https://portal.example.com/#frag", "/callback", "javascript:alert(1)", "https://portal.example.com/ok"}})
+	assert.Equal(t, apperrors.Violations{
+		{Field: "name", Rule: "required"},
+		{Field: "redirect_uris", Rule: "redirect_uri", Param: "http://portal.example.com/callback"},
+		{Field: "redirect_uris", Rule: "redirect_uri", Param: "https://portal.example.com/#frag"},
+		{Field: "redirect_uris", Rule: "redirect_uri", Param: "/callback"},
+		{Field: "redirect_uris", Rule: "redirect_uri", Param: "javascript:alert(1)"},
+	}, apperrors.ViolationsOf(err))
+
+	_, err = service.CreateClient(adminContext(), input.OIDCClientIn{Name: "Portal", RedirectURIs: []string{}})
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+}
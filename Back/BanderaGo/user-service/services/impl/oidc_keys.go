@@ -0,0 +1,157 @@
+package impl
+
+import (
+	"application/dtos/output"
+	"application/models"
+	"application/oidc"
+	"application/tokens"
+	"context"
+	"crypto/rsa"
+	"log"
+	"time"
+)
+
+// PublicKeys devuelve en formato JWK las llaves que pueden verificar tokens vigentes
+func (s *OIDCServiceImpl) PublicKeys(ctx context.Context) (tokens.JWKS, error) {
+	keys, err := s.publishedKeys(ctx)
+	if err != nil {
+		return tokens.JWKS{}, err
+	}
+	set := tokens.JWKS{Keys: []tokens.JWK{}}
+	for _, key := range keys {
+		public, err := oidc.ParsePublicKey(key.PublicKey)
+		if err != nil {
+			log.Printf("No se pudo leer la llave pública de OpenID Connect '%s': %v", key.ID, err)
+			continue
+		}
+		set.Keys = append(set.Keys, tokens.PublicJWK(key.ID, key.Algorithm, public))
+	}
+	return set, nil
+}
+
+func (s *OIDCServiceImpl) GetSigningKeys(ctx context.Context) ([]output.SigningKeyOut, error) {
+	keys, err := s.publishedKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keysOut := make([]output.SigningKeyOut, 0, len(keys))
+	for _, key := range keys {
+		keysOut = append(keysOut, signingKeyOut(key))
+	}
+	return keysOut, nil
+}
+
+func (s *OIDCServiceImpl) RotateSigningKey(ctx context.Context) (output.SigningKeyOut, error) {
+	key, _, err := s.rotate(ctx)
+	if err != nil {
+		return output.SigningKeyOut{}, err
+	}
+	return signingKeyOut(key), nil
+}
+
+func (s *OIDCServiceImpl) RotateExpiredSigningKey(ctx context.Context, maxAge time.Duration) (bool, error) {
+	keys, err := s.store.GetSigningKeys(ctx)
+	if err != nil {
+		return false, err
+	}
+	if active := activeKey(keys); active != nil && time.Since(active.CreatedAt) < maxAge {
+		return false, nil
+	}
+	if _, _, err := s.rotate(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// signingKey devuelve la llave activa. La primera se crea con el primer token; si la
+// activa no se puede descifrar, p. ej. porque cambió OIDC_KEY_SECRET, se rota.
+func (s *OIDCServiceImpl) signingKey(ctx context.Context) (string, *rsa.PrivateKey, error) {
+	keys, err := s.store.GetSigningKeys(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if active := activeKey(keys); active != nil {
+		private, err := oidc.OpenPrivateKey(s.sealer, active.PrivateKey)
+		if err == nil {
+			return active.ID, private, nil
+		}
+		log.Printf("No se pudo descifrar la llave de firma de OpenID Connect '%s'; se genera otra: %v", active.ID, err)
+	}
+	key, private, err := s.rotate(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	return key.ID, private, nil
+}
+
+// rotate genera una llave, la activa y retira la anterior
+func (s *OIDCServiceImpl) rotate(ctx context.Context) (*models.SigningKey, *rsa.PrivateKey, error) {
+	kid, public, sealed, err := oidc.GenerateKey(s.sealer)
+	if err != nil {
+		return nil, nil, err
+	}
+	private, err := oidc.OpenPrivateKey(s.sealer, sealed)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now().UTC()
+	key := &models.SigningKey{ID: kid, Algorithm: oidc.AlgRS256, PublicKey: public, PrivateKey: sealed, CreatedAt: now}
+	if err := s.store.RotateSigningKeys(ctx, key, now.Add(-s.cfg.KeyRetention)); err != nil {
+		return nil, nil, err
+	}
+	log.Printf("Nueva llave de firma de OpenID Connect '%s'", kid)
+	return key, private, nil
+}
+
+// publishedKeys descarta las llaves retiradas hace más que la retención, que ya no
+// verifican ningún token vigente aunque aún no se hayan eliminado
+func (s *OIDCServiceImpl) publishedKeys(ctx context.Context) ([]*models.SigningKey, error) {
+	keys, err := s.store.GetSigningKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := time.Now().Add(-s.cfg.KeyRetention)
+	published := keys[:0]
+	for _, key := range keys {
+		if key.RetiredAt == nil || key.RetiredAt.After(cutoff) {
+			published = append(published, key)
+		}
+	}
+	return published, nil
+}
+
+// publicKeys indexa por kid las llaves publicadas para verificar los tokens de acceso
+func (s *OIDCServiceImpl) publicKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	keys, err := s.publishedKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	publicKeys := make(map[string]*rsa.PublicKey, len(keys))
+	for _, key := range keys {
+		if public, err := oidc.ParsePublicKey(key.PublicKey); err == nil {
+			publicKeys[key.ID] = public
+		}
+	}
+	return publicKeys, nil
+}
+
+// activeKey devuelve la llave más nueva sin retirar; las llaves vienen de la más nueva a
+// la más antigua
+func activeKey(keys []*models.SigningKey) *models.SigningKey {
+	for _, key := range keys {
+		if key.RetiredAt == nil {
+			return key
+		}
+	}
+	return nil
+}
+
+func signingKeyOut(key *models.SigningKey) output.SigningKeyOut {
+	return output.SigningKeyOut{
+		Kid:       key.ID,
+		Algorithm: key.Algorithm,
+		Active:    key.RetiredAt == nil,
+		CreatedAt: key.CreatedAt,
+		RetiredAt: key.RetiredAt,
+	}
+}
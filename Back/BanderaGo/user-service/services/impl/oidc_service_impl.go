@@ -0,0 +1,376 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/config"
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/models"
+	"application/oidc"
+	"application/persistence/repositories"
+	"application/requestctx"
+	"application/tokens"
+	"context"
+	"crypto/rsa"
+	"errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var errRedirectURIMismatch = errors.New("la URI de redirección no está registrada para el cliente")
+
+// idTokenClaims son los claims del ID token (OIDC Core, sección 2) más los del usuario
+// según los scopes concedidos
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	AuthTime        int64    `json:"auth_time"`
+	Nonce           string   `json:"nonce,omitempty"`
+	AMR             []string `json:"amr,omitempty"`
+	AccessTokenHash string   `json:"at_hash"`
+	output.UserClaimsOut
+}
+
+// accessTokenClaims son los claims del token de acceso para userinfo (RFC 9068)
+type accessTokenClaims struct {
+	jwt.RegisteredClaims
+	ClientID string `json:"client_id"`
+	Scope    string `json:"scope"`
+}
+
+type OIDCServiceImpl struct {
+	store    repositories.OIDCRepository
+	users    repositories.UserRepository
+	sessions repositories.SessionRepository
+	sealer   *oidc.Sealer
+	cfg      config.OIDCConfig
+}
+
+func NewOIDCService(store repositories.OIDCRepository, users repositories.UserRepository, sessions repositories.SessionRepository, sealer *oidc.Sealer, cfg config.OIDCConfig) *OIDCServiceImpl {
+	return &OIDCServiceImpl{store: store, users: users, sessions: sessions, sealer: sealer, cfg: cfg}
+}
+
+func (s *OIDCServiceImpl) Discovery() output.DiscoveryOut {
+	return output.DiscoveryOut{
+		Issuer:                            s.cfg.Issuer,
+		AuthorizationEndpoint:             s.cfg.Issuer + oidc.AuthorizePath,
+		TokenEndpoint:                     s.cfg.Issuer + oidc.TokenPath,
+		UserinfoEndpoint:                  s.cfg.Issuer + oidc.UserInfoPath,
+		JWKSURI:                           s.cfg.Issuer + oidc.JWKSPath,
+		ScopesSupported:                   oidc.SupportedScopes,
+		ResponseTypesSupported:            []string{oidc.ResponseTypeCode},
+		ResponseModesSupported:            []string{"query"},
+		GrantTypesSupported:               []string{oidc.GrantTypeAuthorizationCode},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{oidc.AlgRS256},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{oidc.PKCEMethodS256},
+		ClaimsSupported: []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "amr", "at_hash",
+			"name", "given_name", "family_name", "nickname", "preferred_username", "updated_at", "email", "email_verified", "phone_number"},
+		AuthorizationResponseIssParameterSupported: true,
+	}
+}
+
+// Authorize valida la solicitud y, si el usuario inició sesión en el servicio, entrega al
+// cliente un código de un solo uso ligado al code_challenge de PKCE
+func (s *OIDCServiceImpl) Authorize(ctx context.Context, authorizeIn input.AuthorizeIn) (string, error) {
+	client, err := s.store.GetClient(ctx, authorizeIn.ClientID)
+	if err != nil {
+		return "", err
+	}
+	// Sin una URI registrada no se redirige, para no enviar al usuario a otro sitio (RFC 6749, sección 4.1.2.1)
+	if !slices.Contains(client.RedirectURIs, authorizeIn.RedirectURI) {
+		return "", apperrors.Invalid(apperrors.Violations{{Field: "redirect_uri", Rule: "redirect_uri", Param: authorizeIn.RedirectURI}})
+	}
+
+	fail := func(code, description string) (string, error) {
+		return s.redirect(authorizeIn.RedirectURI, url.Values{"error": {code}, "error_description": {description}, "state": {authorizeIn.State}}), nil
+	}
+	if authorizeIn.ResponseType != oidc.ResponseTypeCode {
+		return fail(oidc.ErrorUnsupportedResponseType, "only the code response type is supported")
+	}
+	scopes := oidc.ParseScope(authorizeIn.Scope)
+	if !slices.Contains(scopes, oidc.ScopeOpenID) {
+		return fail(oidc.ErrorInvalidScope, "the openid scope is required")
+	}
+	if authorizeIn.CodeChallengeMethod != oidc.PKCEMethodS256 || !oidc.ValidChallenge(authorizeIn.CodeChallenge) {
+		return fail(oidc.ErrorInvalidRequest, "PKCE with the S256 method is required")
+	}
+
+	identity, _ := requestctx.IdentityFrom(ctx)
+	user, authTime, err := s.signedInUser(ctx, identity)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		if authorizeIn.Prompt == "none" {
+			return fail(oidc.ErrorLoginRequired, "the user is not signed in")
+		}
+		return s.loginRedirect(authorizeIn), nil
+	}
+
+	raw, hash, err := oidc.NewSecret()
+	if err != nil {
+		return "", err
+	}
+	code := &models.AuthorizationCode{
+		Hash:          hash,
+		ClientID:      client.ID,
+		UserID:        user.ID,
+		RedirectURI:   authorizeIn.RedirectURI,
+		Scope:         strings.Join(scopes, " "),
+		Nonce:         authorizeIn.Nonce,
+		CodeChallenge: authorizeIn.CodeChallenge,
+		AuthTime:      authTime,
+		MultiFactor:   identity.MFA,
+		ExpiresAt:     time.Now().UTC().Add(s.cfg.CodeTTL),
+	}
+	if err := s.store.CreateAuthorizationCode(ctx, code); err != nil {
+		return "", err
+	}
+	return s.redirect(authorizeIn.RedirectURI, url.Values{"code": {raw}, "state": {authorizeIn.State}}), nil
+}
+
+// signedInUser devuelve el usuario de la identidad y el momento en que inició su sesión,
+// o nil si no hay un usuario con una sesión activa
+func (s *OIDCServiceImpl) signedInUser(ctx context.Context, identity requestctx.Identity) (*models.User, time.Time, error) {
+	userID, ok := identity.UserID()
+	if !ok {
+		return nil, time.Time{}, nil
+	}
+	user, err := s.users.GetUserByID(ctx, userID)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	// Los tokens emitidos sin sesión, p. ej. para pruebas, cuentan como un inicio de sesión ahora
+	authTime := time.Now().UTC()
+	if sessionID, err := strconv.ParseUint(identity.SessionID, 10, 64); err == nil {
+		session, err := s.sessions.GetSession(ctx, uint(sessionID))
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return nil, time.Time{}, nil
+		}
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if !session.Active(authTime) || session.UserID != user.ID {
+			return nil, time.Time{}, nil
+		}
+		authTime = session.CreatedAt
+	}
+	return user, authTime, nil
+}
+
+// loginRedirect envía al usuario a la página de inicio de sesión con la solicitud de
+// autorización en return_to para retomarla después
+func (s *OIDCServiceImpl) loginRedirect(authorizeIn input.AuthorizeIn) string {
+	query := url.Values{}
+	for key, value := range map[string]string{
+		"response_type":         authorizeIn.ResponseType,
+		"client_id":             authorizeIn.ClientID,
+		"redirect_uri":          authorizeIn.RedirectURI,
+		"scope":                 authorizeIn.Scope,
+		"state":                 authorizeIn.State,
+		"nonce":                 authorizeIn.Nonce,
+		"code_challenge":        authorizeIn.CodeChallenge,
+		"code_challenge_method": authorizeIn.CodeChallengeMethod,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	returnTo := s.cfg.Issuer + oidc.AuthorizePath + "?" + query.Encode()
+	return addQuery(s.cfg.LoginURL, url.Values{"return_to": {returnTo}})
+}
+
+// redirect agrega la respuesta a la URI de redirección junto con el emisor (RFC 9207)
+func (s *OIDCServiceImpl) redirect(redirectURI string, params url.Values) string {
+	params.Set("iss", s.cfg.Issuer)
+	return addQuery(redirectURI, params)
+}
+
+// Token canjea el código por un ID token y un token de acceso para userinfo. El código se
+// consume aunque la solicitud falle, para que no pueda probarse con otros datos.
+func (s *OIDCServiceImpl) Token(ctx context.Context, tokenIn input.TokenRequestIn) (output.OIDCTokenOut, error) {
+	if tokenIn.GrantType != oidc.GrantTypeAuthorizationCode {
+		return output.OIDCTokenOut{}, oidc.NewError(oidc.ErrorUnsupportedGrantType, "only the authorization_code grant is supported")
+	}
+	client, err := s.authenticateClient(ctx, tokenIn)
+	if err != nil {
+		return output.OIDCTokenOut{}, err
+	}
+	if tokenIn.Code == "" || tokenIn.CodeVerifier == "" {
+		return output.OIDCTokenOut{}, oidc.NewError(oidc.ErrorInvalidRequest, "code and code_verifier are required")
+	}
+
+	now := time.Now().UTC()
+	invalidGrant := oidc.NewError(oidc.ErrorInvalidGrant, "the authorization code is invalid, expired or was already used")
+	code, err := s.store.UseAuthorizationCode(ctx, oidc.Hash(tokenIn.Code), now)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return output.OIDCTokenOut{}, invalidGrant
+	}
+	if err != nil {
+		return output.OIDCTokenOut{}, err
+	}
+	if code.ClientID != client.ID || code.RedirectURI != tokenIn.RedirectURI || !oidc.VerifyPKCE(tokenIn.CodeVerifier, code.CodeChallenge) {
+		return output.OIDCTokenOut{}, invalidGrant
+	}
+	user, err := s.users.GetUserByID(ctx, code.UserID)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return output.OIDCTokenOut{}, invalidGrant
+	}
+	if err != nil {
+		return output.OIDCTokenOut{}, err
+	}
+
+	kid, private, err := s.signingKey(ctx)
+	if err != nil {
+		return output.OIDCTokenOut{}, err
+	}
+	subject := strconv.FormatUint(uint64(user.ID), 10)
+	expiresAt := now.Add(s.cfg.TokenTTL)
+	registered := jwt.RegisteredClaims{
+		Issuer:    s.cfg.Issuer,
+		Subject:   subject,
+		Audience:  jwt.ClaimStrings{client.ID},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+	accessClaims := accessTokenClaims{RegisteredClaims: registered, ClientID: client.ID, Scope: code.Scope}
+	if accessClaims.ID, err = oidc.NewID(); err != nil {
+		return output.OIDCTokenOut{}, err
+	}
+	accessToken, err := oidc.Sign(accessClaims, oidc.TypeAccessToken, kid, private)
+	if err != nil {
+		return output.OIDCTokenOut{}, err
+	}
+
+	amr := []string{tokens.AMRPassword}
+	if code.MultiFactor {
+		amr = append(amr, tokens.AMROTP, tokens.AMRMultiFactor)
+	}
+	idToken, err := oidc.Sign(idTokenClaims{
+		RegisteredClaims: registered,
+		AuthTime:         code.AuthTime.Unix(),
+		Nonce:            code.Nonce,
+		AMR:              amr,
+		AccessTokenHash:  oidc.AccessTokenHash(accessToken),
+		UserClaimsOut:    userClaims(user, strings.Fields(code.Scope)),
+	}, "", kid, private)
+	if err != nil {
+		return output.OIDCTokenOut{}, err
+	}
+
+	return output.OIDCTokenOut{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.cfg.TokenTTL.Seconds()),
+		IDToken:     idToken,
+		Scope:       code.Scope,
+	}, nil
+}
+
+// authenticateClient comprueba el secreto de un cliente confidencial; un cliente público
+// no debe enviarlo y solo lo protege PKCE
+func (s *OIDCServiceImpl) authenticateClient(ctx context.Context, tokenIn input.TokenRequestIn) (*models.OIDCClient, error) {
+	invalidClient := oidc.NewError(oidc.ErrorInvalidClient, "client authentication failed")
+	if tokenIn.ClientID == "" {
+		return nil, invalidClient
+	}
+	client, err := s.store.GetClient(ctx, tokenIn.ClientID)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return nil, invalidClient
+	}
+	if err != nil {
+		return nil, err
+	}
+	if client.Public() {
+		if tokenIn.ClientSecret != "" {
+			return nil, invalidClient
+		}
+		return client, nil
+	}
+	if !oidc.Matches(tokenIn.ClientSecret, *client.SecretHash) {
+		return nil, invalidClient
+	}
+	return client, nil
+}
+
+// UserInfo devuelve los claims del usuario del token de acceso según sus scopes. El token
+// deja de servir si se elimina el cliente o el usuario.
+func (s *OIDCServiceImpl) UserInfo(ctx context.Context, accessToken string) (output.UserInfoOut, error) {
+	invalidToken := oidc.NewError(oidc.ErrorInvalidToken, "the access token is invalid or expired")
+	keys, err := s.publicKeys(ctx)
+	if err != nil {
+		return output.UserInfoOut{}, err
+	}
+	claims := &accessTokenClaims{}
+	err = oidc.Verify(accessToken, oidc.TypeAccessToken, s.cfg.Issuer, claims, func(kid string) (*rsa.PublicKey, bool) {
+		public, ok := keys[kid]
+		return public, ok
+	})
+	if err != nil {
+		return output.UserInfoOut{}, invalidToken
+	}
+	if _, err := s.store.GetClient(ctx, claims.ClientID); err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return output.UserInfoOut{}, invalidToken
+		}
+		return output.UserInfoOut{}, err
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return output.UserInfoOut{}, invalidToken
+	}
+	user, err := s.users.GetUserByID(ctx, uint(userID))
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return output.UserInfoOut{}, invalidToken
+	}
+	if err != nil {
+		return output.UserInfoOut{}, err
+	}
+	return output.UserInfoOut{Subject: claims.Subject, UserClaimsOut: userClaims(user, strings.Fields(claims.Scope))}, nil
+}
+
+// userClaims devuelve los claims de los scopes concedidos (OIDC Core, sección 5.4)
+func userClaims(user *models.User, scopes []string) output.UserClaimsOut {
+	var claims output.UserClaimsOut
+	if slices.Contains(scopes, oidc.ScopeProfile) {
+		claims.Name = strings.TrimSpace(user.Name + " " + user.LastName)
+		claims.GivenName, claims.FamilyName = user.Name, user.LastName
+		claims.Nickname = user.DisplayName
+		claims.PreferredUsername = stringValue(user.Username)
+		claims.UpdatedAt = user.UpdatedAt.Unix()
+	}
+	if slices.Contains(scopes, oidc.ScopeEmail) && user.Email != nil {
+		verified := user.EmailVerifiedAt != nil
+		claims.Email, claims.EmailVerified = *user.Email, &verified
+	}
+	if slices.Contains(scopes, oidc.ScopePhone) {
+		claims.PhoneNumber = stringValue(user.Phone)
+	}
+	return claims
+}
+
+// addQuery agrega los parámetros no vacíos a la query de la URI sin perder los que ya tiene
+func addQuery(rawURI string, params url.Values) string {
+	uri, err := url.Parse(rawURI)
+	if err != nil {
+		return rawURI
+	}
+	query := uri.Query()
+	for key, values := range params {
+		if len(values) > 0 && values[0] != "" {
+			query.Set(key, values[0])
+		}
+	}
+	uri.RawQuery = query.Encode()
+	return uri.String()
+}
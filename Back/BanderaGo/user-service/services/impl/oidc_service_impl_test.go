@@ -0,0 +1,325 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/config"
+	"application/dtos/input"
+	"application/models"
+	"application/oidc"
+	repoImpl "application/persistence/repositories/impl"
+	"application/requestctx"
+	"application/tokens"
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testOIDCConfig = config.OIDCConfig{
+	Issuer:       "https://sso.example.com",
+	LoginURL:     "https://app.example.com/login",
+	CodeTTL:      time.Minute,
+	TokenTTL:     time.Hour,
+	KeyRotation:  30 * 24 * time.Hour,
+	KeyRetention: 24 * time.Hour,
+}
+
+// Verifier y challenge del apéndice B de RFC 7636
+const (
+	testVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	testChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+type oidcTestEnv struct {
+	users    *repoImpl.MemoryUserRepository
+	sessions *repoImpl.MemorySessionRepository
+	store    *repoImpl.MemoryOIDCRepository
+	clients  *OIDCClientServiceImpl
+	service  *OIDCServiceImpl
+}
+
+func newOIDCTestEnv(t *testing.T, cfg config.OIDCConfig) *oidcTestEnv {
+	t.Helper()
+	sealer, err := oidc.NewSealer("0123456789abcdef0123456789abcdef")
+	require.NoError(t, err)
+	env := &oidcTestEnv{
+		users:    repoImpl.NewMemoryUserRepository(),
+		sessions: repoImpl.NewMemorySessionRepository(),
+		store:    repoImpl.NewMemoryOIDCRepository(),
+	}
+	env.clients = NewOIDCClientService(env.store)
+	env.service = NewOIDCService(env.store, env.users, env.sessions, sealer, cfg)
+	return env
+}
+
+// signIn simula la solicitud de un usuario que inició sesión con segundo factor
+func (env *oidcTestEnv) signIn(t *testing.T, user *models.User) (context.Context, *models.Session) {
+	t.Helper()
+	now := time.Now().UTC()
+	session := &models.Session{UserID: user.ID, LastUsedAt: now, ExpiresAt: now.Add(time.Hour), MFA: true}
+	require.NoError(t, env.sessions.CreateSession(context.Background(), session, "refresh"))
+	identity := requestctx.Identity{Subject: "1", SessionID: "1", MFA: true}
+	return requestctx.WithIdentity(context.Background(), identity), session
+}
+
+// authorize pide un código y devuelve la query de la redirección
+func (env *oidcTestEnv) authorize(t *testing.T, ctx context.Context, authorizeIn input.AuthorizeIn) url.Values {
+	t.Helper()
+	redirectTo, err := env.service.Authorize(ctx, authorizeIn)
+	require.NoError(t, err)
+	parsed, err := url.Parse(redirectTo)
+	require.NoError(t, err)
+	return parsed.Query()
+}
+
+func authorizeRequest(clientID string) input.AuthorizeIn {
+	return input.AuthorizeIn{ResponseType: "code", ClientID: clientID, RedirectURI: "https://portal.example.com/callback",
+		Scope: "openid email", State: "xyz", Nonce: "n-0S6", CodeChallenge: testChallenge, CodeChallengeMethod: "S256"}
+}
+
+// Caso de prueba: el usuario con sesión obtiene un código que el cliente canjea una sola vez por un ID token y un token para userinfo
+func TestOIDCAuthorizationCodeFlow(t *testing.T) {
+	env := newOIDCTestEnv(t, testOIDCConfig)
+	user := createTestUser(t, env.users)
+	ctx, session := env.signIn(t, user)
+	client, err := env.clients.CreateClient(adminContext(), input.OIDCClientIn{Name: "Portal", RedirectURIs: []string{"https://portal.example.com/callback"}})
+	require.NoError(t, err)
+
+	query := env.authorize(t, ctx, authorizeRequest(client.ClientID))
+	assert.Equal(t, "xyz", query.Get("state"))
+	assert.Equal(t, "https://sso.example.com", query.Get("iss"))
+	code := query.Get("code")
+	require.NotEmpty(t, code)
+
+	tokenIn := input.TokenRequestIn{GrantType: "authorization_code", Code: code, RedirectURI: "https://portal.example.com/callback",
+		ClientID: client.ClientID, ClientSecret: client.ClientSecret, CodeVerifier: testVerifier}
+	wrongSecret := tokenIn
+	wrongSecret.ClientSecret = "otro"
+	_, err = env.service.Token(context.Background(), wrongSecret)
+	assertOAuthError(t, err, oidc.ErrorInvalidClient)
+
+	tokenOut, err := env.service.Token(context.Background(), tokenIn)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", tokenOut.TokenType)
+	assert.Equal(t, int64(3600), tokenOut.ExpiresIn)
+	assert.Equal(t, "openid email", tokenOut.Scope)
+	_, err = env.service.Token(context.Background(), tokenIn)
+	assertOAuthError(t, err, oidc.ErrorInvalidGrant)
+
+	// El ID token se verifica con el JWKS publicado
+	set, err := env.service.PublicKeys(context.Background())
+	require.NoError(t, err)
+	require.Len(t, set.Keys, 1)
+	claims := &idTokenClaims{}
+	_, err = jwt.ParseWithClaims(tokenOut.IDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return publicKeyFromJWK(t, set, token.Header["kid"].(string)), nil
+	}, jwt.WithIssuer("https://sso.example.com"), jwt.WithAudience(client.ClientID))
+	require.NoError(t, err)
+	assert.Equal(t, "1", claims.Subject)
+	assert.Equal(t, "n-0S6", claims.Nonce)
+	assert.Equal(t, session.CreatedAt.Unix(), claims.AuthTime)
+	assert.Equal(t, []string{tokens.AMRPassword, tokens.AMROTP, tokens.AMRMultiFactor}, claims.AMR)
+	assert.Equal(t, oidc.AccessTokenHash(tokenOut.AccessToken), claims.AccessTokenHash)
+	assert.Equal(t, "ana@example.com", claims.Email)
+	require.NotNil(t, claims.EmailVerified)
+	assert.False(t, *claims.EmailVerified)
+	assert.Empty(t, claims.GivenName, "sin el scope profile")
+
+	userInfo, err := env.service.UserInfo(context.Background(), tokenOut.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "1", userInfo.Subject)
+	assert.Equal(t, "ana@example.com", userInfo.Email)
+	_, err = env.service.UserInfo(context.Background(), tokenOut.IDToken)
+	assertOAuthError(t, err, oidc.ErrorInvalidToken)
+
+	require.NoError(t, env.clients.DeleteClient(adminContext(), client.ClientID))
+	_, err = env.service.UserInfo(context.Background(), tokenOut.AccessToken)
+	assertOAuthError(t, err, oidc.ErrorInvalidToken)
+}
+
+// Caso de prueba: un verifier, una URI o un cliente distintos a los de la autorización consumen el código sin canjearlo
+func TestOIDCTokenRejects(t *testing.T) {
+	env := newOIDCTestEnv(t, testOIDCConfig)
+	user := createTestUser(t, env.users)
+	ctx, _ := env.signIn(t, user)
+	spa, err := env.clients.CreateClient(adminContext(), input.OIDCClientIn{Name: "SPA", RedirectURIs: []string{"https://portal.example.com/callback"}, Public: true})
+	require.NoError(t, err)
+
+	valid := input.TokenRequestIn{GrantType: "authorization_code", RedirectURI: "https://portal.example.com/callback", ClientID: spa.ClientID, CodeVerifier: testVerifier}
+	cases := map[string]func(in *input.TokenRequestIn){
+		"otro verifier": func(in *input.TokenRequestIn) { in.CodeVerifier = testVerifier[1:] + "A" },
+		"otra URI":      func(in *input.TokenRequestIn) { in.RedirectURI = "https://portal.example.com/otra" },
+	}
+	for name, change := range cases {
+		t.Run(name, func(t *testing.T) {
+			tokenIn := valid
+			tokenIn.Code = env.authorize(t, ctx, authorizeRequest(spa.ClientID)).Get("code")
+			change(&tokenIn)
+			_, err := env.service.Token(context.Background(), tokenIn)
+			assertOAuthError(t, err, oidc.ErrorInvalidGrant)
+
+			tokenIn.CodeVerifier, tokenIn.RedirectURI = valid.CodeVerifier, valid.RedirectURI
+			_, err = env.service.Token(context.Background(), tokenIn)
+			assertOAuthError(t, err, oidc.ErrorInvalidGrant)
+		})
+	}
+
+	tokenIn := valid
+	tokenIn.Code = env.authorize(t, ctx, authorizeRequest(spa.ClientID)).Get("code")
+	tokenIn.ClientSecret = "un cliente público no tiene secreto"
+	_, err = env.service.Token(context.Background(), tokenIn)
+	assertOAuthError(t, err, oidc.ErrorInvalidClient)
+	tokenIn.ClientSecret = ""
+	tokenIn.GrantType = "refresh_token"
+	_, err = env.service.Token(context.Background(), tokenIn)
+	assertOAuthError(t, err, oidc.ErrorUnsupportedGrantType)
+	tokenIn.GrantType = "authorization_code"
+	_, err = env.service.Token(context.Background(), tokenIn)
+	assert.NoError(t, err, "el cliente público canjea el código solo con PKCE")
+}
+
+// Caso de prueba: sin cliente o URI válidos no se redirige; los demás errores y el inicio de sesión se informan con una redirección
+func TestOIDCAuthorizeErrors(t *testing.T) {
+	env := newOIDCTestEnv(t, testOIDCConfig)
+	user := createTestUser(t, env.users)
+	ctx, session := env.signIn(t, user)
+	client, err := env.clients.CreateClient(adminContext(), input.OIDCClientIn{Name: "Portal", RedirectURIs: []string{"https://portal.example.com/callback"}})
+	require.NoError(t, err)
+
+	_, err = env.service.Authorize(ctx, authorizeRequest("desconocido"))
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	otherURI := authorizeRequest(client.ClientID)
+	otherURI.RedirectURI = "https://atacante.example.com/callback"
+	_, err = env.service.Authorize(ctx, otherURI)
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+
+	cases := map[string]struct {
+		change func(in *input.AuthorizeIn)
+		error  string
+	}{
+		"response_type": {func(in *input.AuthorizeIn) { in.ResponseType = "token" }, oidc.ErrorUnsupportedResponseType},
+		"sin openid":    {func(in *input.AuthorizeIn) { in.Scope = "profile" }, oidc.ErrorInvalidScope},
+		"sin PKCE":      {func(in *input.AuthorizeIn) { in.CodeChallengeMethod = "plain" }, oidc.ErrorInvalidRequest},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			authorizeIn := authorizeRequest(client.ClientID)
+			tc.change(&authorizeIn)
+			query := env.authorize(t, ctx, authorizeIn)
+			assert.Equal(t, tc.error, query.Get("error"))
+			assert.Equal(t, "xyz", query.Get("state"))
+			assert.Empty(t, query.Get("code"))
+		})
+	}
+
+	// Sin sesión se envía al usuario a iniciar sesión, salvo con prompt=none
+	require.NoError(t, env.sessions.RevokeSession(context.Background(), session.ID, time.Now()))
+	redirectTo, err := env.service.Authorize(ctx, authorizeRequest(client.ClientID))
+	require.NoError(t, err)
+	login, err := url.Parse(redirectTo)
+	require.NoError(t, err)
+	assert.Equal(t, "app.example.com", login.Host)
+	returnTo, err := url.Parse(login.Query().Get("return_to"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://sso.example.com/oauth2/authorize", returnTo.Scheme+"://"+returnTo.Host+returnTo.Path)
+	assert.Equal(t, client.ClientID, returnTo.Query().Get("client_id"))
+	assert.Equal(t, testChallenge, returnTo.Query().Get("code_challenge"))
+
+	silent := authorizeRequest(client.ClientID)
+	silent.Prompt = "none"
+	query := env.authorize(t, context.Background(), silent)
+	assert.Equal(t, oidc.ErrorLoginRequired, query.Get("error"))
+}
+
+// Caso de prueba: una sesión vencida sin revocar tampoco cuenta como inicio de sesión
+func TestOIDCAuthorizeExpiredSession(t *testing.T) {
+	env := newOIDCTestEnv(t, testOIDCConfig)
+	user := createTestUser(t, env.users)
+	client, err := env.clients.CreateClient(adminContext(), input.OIDCClientIn{Name: "Portal", RedirectURIs: []string{"https://portal.example.com/callback"}})
+	require.NoError(t, err)
+	now := time.Now().UTC()
+	session := &models.Session{UserID: user.ID, LastUsedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)}
+	require.NoError(t, env.sessions.CreateSession(context.Background(), session, "refresh"))
+	ctx := requestctx.WithIdentity(context.Background(), requestctx.Identity{Subject: "1", SessionID: strconv.FormatUint(uint64(session.ID), 10)})
+
+	silent := authorizeRequest(client.ClientID)
+	silent.Prompt = "none"
+	query := env.authorize(t, ctx, silent)
+	assert.Equal(t, oidc.ErrorLoginRequired, query.Get("error"))
+	assert.Empty(t, query.Get("code"))
+}
+
+// Caso de prueba: la llave retirada sigue publicada y verificando durante la retención
+func TestOIDCSigningKeyRotation(t *testing.T) {
+	env := newOIDCTestEnv(t, testOIDCConfig)
+	user := createTestUser(t, env.users)
+	ctx, _ := env.signIn(t, user)
+	client, err := env.clients.CreateClient(adminContext(), input.OIDCClientIn{Name: "SPA", RedirectURIs: []string{"https://portal.example.com/callback"}, Public: true})
+	require.NoError(t, err)
+
+	rotated, err := env.service.RotateExpiredSigningKey(context.Background(), time.Hour)
+	require.NoError(t, err)
+	assert.True(t, rotated, "crea la primera llave")
+	rotated, err = env.service.RotateExpiredSigningKey(context.Background(), time.Hour)
+	require.NoError(t, err)
+	assert.False(t, rotated)
+
+	tokenIn := input.TokenRequestIn{GrantType: "authorization_code", Code: env.authorize(t, ctx, authorizeRequest(client.ClientID)).Get("code"),
+		RedirectURI: "https://portal.example.com/callback", ClientID: client.ClientID, CodeVerifier: testVerifier}
+	tokenOut, err := env.service.Token(context.Background(), tokenIn)
+	require.NoError(t, err)
+
+	newKey, err := env.service.RotateSigningKey(context.Background())
+	require.NoError(t, err)
+	assert.True(t, newKey.Active)
+	keys, err := env.service.GetSigningKeys(context.Background())
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, newKey.Kid, keys[0].Kid)
+	assert.False(t, keys[1].Active)
+	assert.NotNil(t, keys[1].RetiredAt)
+
+	_, err = env.service.UserInfo(context.Background(), tokenOut.AccessToken)
+	assert.NoError(t, err, "el token firmado con la llave retirada sigue valiendo")
+
+	// Sin retención la llave retirada deja de publicarse
+	expired := newOIDCTestEnv(t, config.OIDCConfig{Issuer: testOIDCConfig.Issuer, CodeTTL: time.Minute, TokenTTL: time.Hour})
+	_, err = expired.service.RotateSigningKey(context.Background())
+	require.NoError(t, err)
+	_, err = expired.service.RotateSigningKey(context.Background())
+	require.NoError(t, err)
+	set, err := expired.service.PublicKeys(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, set.Keys, 1)
+}
+
+func assertOAuthError(t *testing.T, err error, code string) {
+	t.Helper()
+	var oauthErr *oidc.Error
+	require.ErrorAs(t, err, &oauthErr)
+	assert.Equal(t, code, oauthErr.Code)
+}
+
+// publicKeyFromJWK reconstruye la llave pública RSA del JWK con el kid indicado
+func publicKeyFromJWK(t *testing.T, set tokens.JWKS, kid string) *rsa.PublicKey {
+	t.Helper()
+	for _, jwk := range set.Keys {
+		if jwk.Kid == kid {
+			n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+			require.NoError(t, err)
+			e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+			require.NoError(t, err)
+			return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		}
+	}
+	t.Fatalf("el JWKS no tiene la llave %s", kid)
+	return nil
+}
@@ -0,0 +1,200 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/models"
+	"application/persistence/repositories"
+	"application/requestctx"
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxRoleDescriptionLength = 255
+
+// Los nombres de rol aparecen en las rutas, por lo que se limitan a minúsculas,
+// dígitos, guiones y guiones bajos
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,63}$`)
+
+type RoleServiceImpl struct {
+	roles repositories.RoleRepository
+	users repositories.UserRepository
+	audit repositories.AuditRepository
+	tx    repositories.Transactor
+	// mfaRoles son los roles asignados que solo cuentan en sesiones con segundo factor
+	mfaRoles []string
+}
+
+func NewRoleService(roles repositories.RoleRepository, users repositories.UserRepository, audit repositories.AuditRepository, tx repositories.Transactor, mfaRoles []string) *RoleServiceImpl {
+	return &RoleServiceImpl{roles: roles, users: users, audit: audit, tx: tx, mfaRoles: mfaRoles}
+}
+
+func (s *RoleServiceImpl) GetRoles(ctx context.Context) ([]output.RoleOut, error) {
+	roles, err := s.roles.GetRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rolesOut := make([]output.RoleOut, 0, len(roles))
+	for _, role := range roles {
+		rolesOut = append(rolesOut, roleOut(role))
+	}
+	return rolesOut, nil
+}
+
+func (s *RoleServiceImpl) GetRole(ctx context.Context, name string) (output.RoleOut, error) {
+	role, err := s.roles.GetRole(ctx, name)
+	if err != nil {
+		return output.RoleOut{}, err
+	}
+	return roleOut(role), nil
+}
+
+func (s *RoleServiceImpl) CreateRole(ctx context.Context, roleIn input.RoleIn) (output.RoleOut, error) {
+	role := &models.Role{Name: strings.TrimSpace(roleIn.Name), Description: normalizeText(roleIn.Description), Permissions: normalizePermissions(roleIn.Permissions)}
+	if err := validateRole(role); err != nil {
+		return output.RoleOut{}, err
+	}
+	if err := s.roles.CreateRole(ctx, role); err != nil {
+		return output.RoleOut{}, err
+	}
+	return roleOut(role), nil
+}
+
+func (s *RoleServiceImpl) UpdateRole(ctx context.Context, name string, roleIn input.UpdateRoleIn) (output.RoleOut, error) {
+	role := &models.Role{Name: name, Description: normalizeText(roleIn.Description), Permissions: normalizePermissions(roleIn.Permissions)}
+	if err := validateRole(role); err != nil {
+		return output.RoleOut{}, err
+	}
+	if err := s.roles.UpdateRole(ctx, role); err != nil {
+		return output.RoleOut{}, err
+	}
+	return roleOut(role), nil
+}
+
+func (s *RoleServiceImpl) DeleteRole(ctx context.Context, name string) error {
+	return s.roles.DeleteRole(ctx, name)
+}
+
+func (s *RoleServiceImpl) GetUserRoles(ctx context.Context, userID uint) (output.UserRolesOut, error) {
+	if err := authorizeSelf(ctx, userID, models.PermissionRolesManage); err != nil {
+		return output.UserRolesOut{}, err
+	}
+	if _, err := s.users.GetUserByID(ctx, userID); err != nil {
+		return output.UserRolesOut{}, err
+	}
+	roles, err := s.roles.GetUserRoles(ctx, userID)
+	if err != nil {
+		return output.UserRolesOut{}, err
+	}
+	permissions, err := s.roles.GetPermissions(ctx, roles)
+	if err != nil {
+		return output.UserRolesOut{}, err
+	}
+	return output.UserRolesOut{UserID: userID, Roles: roles, Permissions: permissions}, nil
+}
+
+func (s *RoleServiceImpl) AssignRole(ctx context.Context, userID uint, role string) error {
+	return s.changeRoles(ctx, userID, func(ctx context.Context) error {
+		return s.roles.AssignRole(ctx, userID, role)
+	})
+}
+
+func (s *RoleServiceImpl) RevokeRole(ctx context.Context, userID uint, role string) error {
+	return s.changeRoles(ctx, userID, func(ctx context.Context) error {
+		return s.roles.RevokeRole(ctx, userID, role)
+	})
+}
+
+// changeRoles aplica el cambio sobre los roles de un usuario existente y lo audita con
+// los roles antes y después; un cambio que no modifica los roles no se audita
+func (s *RoleServiceImpl) changeRoles(ctx context.Context, userID uint, change func(ctx context.Context) error) error {
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.users.GetUserByID(ctx, userID); err != nil {
+			return err
+		}
+		before, err := s.roles.GetUserRoles(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if err := change(ctx); err != nil {
+			return err
+		}
+		after, err := s.roles.GetUserRoles(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if slices.Equal(before, after) {
+			return nil
+		}
+		changes := map[string]models.FieldChange{"roles": {Before: before, After: after}}
+		return s.audit.CreateAuditRecord(ctx, newAuditEntry(ctx, models.AuditRoles, userID, changes))
+	})
+}
+
+// Permissions une los permisos que ya trae la identidad, como los de una llave de API,
+// con los de los roles del token y los de los roles asignados al usuario. Un usuario
+// eliminado conserva sus asignaciones, pero no se toman en cuenta. Sin segundo factor no
+// cuentan los roles que lo exigen, vengan del token o de la base.
+func (s *RoleServiceImpl) Permissions(ctx context.Context, identity requestctx.Identity) ([]string, error) {
+	roles := slices.Clone(identity.Roles)
+	if userID, ok := identity.UserID(); ok {
+		_, err := s.users.GetUserByID(ctx, userID)
+		switch {
+		case err == nil:
+			assigned, err := s.roles.GetUserRoles(ctx, userID)
+			if err != nil {
+				return nil, err
+			}
+			roles = append(roles, assigned...)
+		case !errors.Is(err, apperrors.ErrNotFound):
+			return nil, err
+		}
+	}
+	if !identity.MFA {
+		roles = slices.DeleteFunc(roles, func(role string) bool { return slices.Contains(s.mfaRoles, role) })
+	}
+	permissions, err := s.roles.GetPermissions(ctx, roles)
+	if err != nil {
+		return nil, err
+	}
+	return normalizePermissions(append(permissions, identity.Permissions...)), nil
+}
+
+// validateRole reporta juntas las reglas que no cumple el rol
+func validateRole(role *models.Role) error {
+	var violations apperrors.Violations
+	if !roleNamePattern.MatchString(role.Name) {
+		violations = append(violations, apperrors.Violation{Field: "name", Rule: "role_name"})
+	}
+	if utf8.RuneCountInString(role.Description) > maxRoleDescriptionLength {
+		violations = append(violations, apperrors.Violation{Field: "description", Rule: "max", Param: strconv.Itoa(maxRoleDescriptionLength)})
+	} else if !isPrintable(role.Description) {
+		violations = append(violations, apperrors.Violation{Field: "description", Rule: "printable"})
+	}
+	for _, permission := range role.Permissions {
+		if !slices.Contains(models.Permissions, permission) {
+			violations = append(violations, apperrors.Violation{Field: "permissions", Rule: "permission", Param: permission})
+		}
+	}
+	return apperrors.Invalid(violations)
+}
+
+// normalizePermissions ordena los permisos y quita los repetidos
+func normalizePermissions(permissions []string) []string {
+	normalized := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		normalized = append(normalized, strings.TrimSpace(permission))
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+func roleOut(role *models.Role) output.RoleOut {
+	return output.RoleOut{Name: role.Name, Description: role.Description, Permissions: role.Permissions}
+}
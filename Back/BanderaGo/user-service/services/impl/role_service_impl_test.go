@@ -0,0 +1,156 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/models"
+	"application/persistence/repositories"
+	repoImpl "application/persistence/repositories/impl"
+	"application/requestctx"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRoleService(users *repoImpl.MemoryUserRepository, audit *repoImpl.MemoryAuditRepository) *RoleServiceImpl {
+	return NewRoleService(repoImpl.NewMemoryRoleRepository(), users, audit, repoImpl.NewMemoryTransactor(), nil)
+}
+
+// Caso de prueba: crear y actualizar un rol valida el nombre y los permisos
+func TestCreateAndUpdateRole(t *testing.T) {
+	service := newTestRoleService(repoImpl.NewMemoryUserRepository(), repoImpl.NewMemoryAuditRepository())
+	ctx := context.Background()
+
+	roleOut, err := service.CreateRole(ctx, input.RoleIn{Name: "support", Description: " Soporte ",
+		Permissions: []string{models.PermissionUsersUpdate, models.PermissionUsersRead, models.PermissionUsersRead}})
+	require.NoError(t, err)
+	assert.Equal(t, "Soporte", roleOut.Description)
+	assert.Equal(t, []string{models.PermissionUsersRead, models.PermissionUsersUpdate}, roleOut.Permissions)
+
+	_, err = service.CreateRole(ctx, input.RoleIn{Name: "support", Permissions: []string{}})
+	assert.ErrorIs(t, err, apperrors.ErrConflict)
+
+	_, err = service.CreateRole(ctx, input.RoleIn{Name: "Soporte Técnico", Permissions: []string{"users:fly"}})
+	assert.Equal(t, apperrors.Violations{
+		{Field: "name", Rule: "role_name"},
+		{Field: "permissions", Rule: "permission", Param: "users:fly"},
+	}, apperrors.ViolationsOf(err))
+
+	roleOut, err = service.UpdateRole(ctx, "support", input.UpdateRoleIn{Permissions: []string{models.PermissionAuditRead}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.PermissionAuditRead}, roleOut.Permissions)
+	_, err = service.UpdateRole(ctx, "nadie", input.UpdateRoleIn{Permissions: []string{}})
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+}
+
+// Caso de prueba: asignar y retirar roles se audita y cambia los permisos del usuario
+func TestAssignRole(t *testing.T) {
+	users, audit := repoImpl.NewMemoryUserRepository(), repoImpl.NewMemoryAuditRepository()
+	service := newTestRoleService(users, audit)
+	user := createTestUser(t, users)
+	ctx := context.Background()
+
+	require.NoError(t, service.AssignRole(ctx, user.ID, models.RoleAuditor))
+	require.NoError(t, service.AssignRole(ctx, user.ID, models.RoleAuditor))
+	assert.ErrorIs(t, service.AssignRole(ctx, user.ID, "nadie"), apperrors.ErrNotFound)
+	assert.ErrorIs(t, service.AssignRole(ctx, 99, models.RoleAuditor), apperrors.ErrNotFound)
+
+	rolesOut, err := service.GetUserRoles(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.RoleAuditor}, rolesOut.Roles)
+	assert.Equal(t, []string{models.PermissionAuditRead, models.PermissionUsersRead}, rolesOut.Permissions)
+
+	require.NoError(t, service.RevokeRole(ctx, user.ID, models.RoleAuditor))
+	rolesOut, err = service.GetUserRoles(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, rolesOut.Roles)
+
+	// Solo los cambios efectivos quedan en la auditoría
+	page, err := audit.GetAuditRecords(ctx, repositories.AuditQuery{Operation: models.AuditRoles, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Records, 2)
+	assert.Equal(t, models.FieldChange{Before: []string{models.RoleAuditor}, After: []string{}}, page.Records[0].Changes["roles"])
+}
+
+// Caso de prueba: los permisos unen los roles del token y los del usuario, salvo si el usuario fue eliminado
+func TestRolePermissions(t *testing.T) {
+	users := repoImpl.NewMemoryUserRepository()
+	service := newTestRoleService(users, repoImpl.NewMemoryAuditRepository())
+	user := createTestUser(t, users)
+	ctx := context.Background()
+	require.NoError(t, service.AssignRole(ctx, user.ID, models.RoleAuditor))
+
+	permissions, err := service.Permissions(ctx, requestctx.Identity{Subject: "deploy-bot", Roles: []string{models.RoleAdmin}})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, models.Permissions, permissions)
+
+	permissions, err = service.Permissions(ctx, requestctx.Identity{Subject: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.PermissionAuditRead, models.PermissionUsersRead}, permissions)
+
+	// Los permisos de una llave de API se conservan
+	permissions, err = service.Permissions(ctx, requestctx.Identity{Subject: "apikey:1", Permissions: []string{models.PermissionUsersCreate}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.PermissionUsersCreate}, permissions)
+
+	require.NoError(t, users.DeleteUser(ctx, user.ID))
+	permissions, err = service.Permissions(ctx, requestctx.Identity{Subject: "1"})
+	require.NoError(t, err)
+	assert.Empty(t, permissions)
+}
+
+// Caso de prueba: los roles que exigen segundo factor solo cuentan en sesiones que lo verificaron
+func TestRolePermissionsRequireMFA(t *testing.T) {
+	users := repoImpl.NewMemoryUserRepository()
+	service := NewRoleService(repoImpl.NewMemoryRoleRepository(), users, repoImpl.NewMemoryAuditRepository(), repoImpl.NewMemoryTransactor(), []string{models.RoleAdmin})
+	user := createTestUser(t, users)
+	ctx := context.Background()
+	require.NoError(t, service.AssignRole(ctx, user.ID, models.RoleAdmin))
+	require.NoError(t, service.AssignRole(ctx, user.ID, models.RoleAuditor))
+
+	permissions, err := service.Permissions(ctx, requestctx.Identity{Subject: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.PermissionAuditRead, models.PermissionUsersRead}, permissions)
+
+	permissions, err = service.Permissions(ctx, requestctx.Identity{Subject: "1", MFA: true})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, models.Permissions, permissions)
+
+	// La regla también se aplica a los roles del claim del token
+	permissions, err = service.Permissions(ctx, requestctx.Identity{Subject: "deploy-bot", Roles: []string{models.RoleAdmin, models.RoleAuditor}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.PermissionAuditRead, models.PermissionUsersRead}, permissions)
+	permissions, err = service.Permissions(ctx, requestctx.Identity{Subject: "deploy-bot", Roles: []string{models.RoleAdmin}, MFA: true})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, models.Permissions, permissions)
+}
+
+// Caso de prueba: sin el permiso un usuario solo consulta sus roles y modifica sus datos
+func TestSelfOnlyOperations(t *testing.T) {
+	users := repoImpl.NewMemoryUserRepository()
+	owner := createTestUser(t, users)
+	other := &models.User{Name: "Luis", LastName: "Paz"}
+	require.NoError(t, users.CreateUser(context.Background(), other))
+
+	ctx := requestctx.WithIdentity(context.Background(), requestctx.Identity{Subject: "1"})
+	userService := newTestService(users)
+
+	_, err := userService.UpdateUser(ctx, other.ID, input.UpdateUserIn{Name: "Luisa", LastName: "Paz"})
+	assert.ErrorIs(t, err, apperrors.ErrForbidden)
+	_, err = userService.PatchUser(ctx, other.ID, input.PatchUserIn{Type: input.MergePatchType, Patch: []byte(`{"name":"Luisa"}`)})
+	assert.ErrorIs(t, err, apperrors.ErrForbidden)
+	_, err = userService.UpdateUser(ctx, owner.ID, input.UpdateUserIn{Name: "Ana", LastName: "Ruiz"})
+	assert.NoError(t, err)
+
+	_, err = newTestRoleService(users, repoImpl.NewMemoryAuditRepository()).GetUserRoles(ctx, other.ID)
+	assert.ErrorIs(t, err, apperrors.ErrForbidden)
+	err = newTestAuthService(users, repoImpl.NewMemoryAuditRepository(), testPasswordConfig).ChangePassword(ctx, other.ID, input.ChangePasswordIn{NewPassword: "caballo correcto"})
+	assert.ErrorIs(t, err, apperrors.ErrForbidden)
+
+	// Con el permiso se modifica a cualquier usuario
+	admin := requestctx.WithIdentity(context.Background(), requestctx.Identity{Subject: "1", Permissions: []string{models.PermissionUsersUpdate}})
+	_, err = userService.UpdateUser(admin, other.ID, input.UpdateUserIn{Name: "Luisa", LastName: "Paz"})
+	assert.NoError(t, err)
+}
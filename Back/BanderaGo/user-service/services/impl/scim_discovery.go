@@ -0,0 +1,123 @@
+package impl
+
+import (
+	"application/dtos/output"
+	"application/scim"
+)
+
+func (s *SCIMServiceImpl) ServiceProviderConfig() output.SCIMServiceProviderConfigOut {
+	return output.SCIMServiceProviderConfigOut{
+		Schemas:        []string{scim.SchemaServiceProviderConfig},
+		Patch:          output.SCIMSupportedOut{Supported: true},
+		Bulk:           output.SCIMBulkOut{Supported: false},
+		Filter:         output.SCIMFilterOut{Supported: true, MaxResults: scim.DefaultCount},
+		ChangePassword: output.SCIMSupportedOut{Supported: false},
+		Sort:           output.SCIMSupportedOut{Supported: false},
+		ETag:           output.SCIMSupportedOut{Supported: true},
+		AuthenticationSchemes: []output.SCIMAuthenticationSchemeOut{{
+			Type:        "oauthbearertoken",
+			Name:        "OAuth Bearer Token",
+			Description: "Access token of the service in the Authorization header, or an API key in the X-API-Key header",
+			Primary:     true,
+		}},
+		Meta: output.SCIMMetaOut{ResourceType: "ServiceProviderConfig", Location: scim.BasePath + "/ServiceProviderConfig"},
+	}
+}
+
+func (s *SCIMServiceImpl) ResourceTypes() output.SCIMResourceTypeListOut {
+	resourceTypes := []output.SCIMResourceTypeOut{
+		{
+			Schemas: []string{scim.SchemaResourceType}, ID: "User", Name: "User", Endpoint: "/Users",
+			Description: "User account", Schema: scim.SchemaUser,
+			Meta: output.SCIMMetaOut{ResourceType: "ResourceType", Location: scim.BasePath + "/ResourceTypes/User"},
+		},
+		{
+			Schemas: []string{scim.SchemaResourceType}, ID: "Group", Name: "Group", Endpoint: "/Groups",
+			Description: "Role assigned to users", Schema: scim.SchemaGroup,
+			Meta: output.SCIMMetaOut{ResourceType: "ResourceType", Location: scim.BasePath + "/ResourceTypes/Group"},
+		},
+	}
+	return output.SCIMResourceTypeListOut{
+		Schemas:      []string{scim.SchemaListResponse},
+		TotalResults: int64(len(resourceTypes)),
+		StartIndex:   1,
+		ItemsPerPage: len(resourceTypes),
+		Resources:    resourceTypes,
+	}
+}
+
+// Schemas describe solo los atributos que el servicio guarda (RFC 7643, sección 7)
+func (s *SCIMServiceImpl) Schemas() output.SCIMSchemaListOut {
+	multiValue := func(name, description string) output.SCIMAttributeOut {
+		attribute := scimAttribute(name, "complex", description, "readWrite")
+		attribute.MultiValued = true
+		attribute.SubAttributes = []output.SCIMAttributeOut{
+			scimAttribute("value", "string", "The value", "readWrite"),
+			scimAttribute("type", "string", "Always work", "readWrite"),
+			scimAttribute("primary", "boolean", "Only one value is stored, which is the primary", "readWrite"),
+		}
+		return attribute
+	}
+	reference := func(name, description, mutability, referenceType string) output.SCIMAttributeOut {
+		attribute := scimAttribute(name, "complex", description, mutability)
+		attribute.MultiValued = true
+		ref := scimAttribute("$ref", "reference", "URI of the resource", mutability)
+		ref.ReferenceTypes = []string{referenceType}
+		attribute.SubAttributes = []output.SCIMAttributeOut{scimAttribute("value", "string", "id of the resource", mutability), ref}
+		return attribute
+	}
+
+	userName := scimAttribute("userName", "string", "Unique username: letters, digits, dots, hyphens and underscores", "readWrite")
+	userName.Required, userName.Uniqueness = true, "server"
+	name := scimAttribute("name", "complex", "Name of the user", "readWrite")
+	name.Required = true
+	name.SubAttributes = []output.SCIMAttributeOut{
+		scimAttribute("formatted", "string", "Given and family name", "readOnly"),
+		scimAttribute("givenName", "string", "Required", "readWrite"),
+		scimAttribute("familyName", "string", "Required", "readWrite"),
+	}
+	displayName := scimAttribute("displayName", "string", "Name of the group, which is the name of the role and cannot change", "immutable")
+	displayName.Required, displayName.Uniqueness = true, "server"
+
+	schemas := []output.SCIMSchemaOut{
+		{
+			Schemas: []string{scim.SchemaSchema}, ID: scim.SchemaUser, Name: "User", Description: "User account",
+			Attributes: []output.SCIMAttributeOut{
+				userName,
+				name,
+				scimAttribute("displayName", "string", "Name shown to other users", "readWrite"),
+				multiValue("emails", "Email address, unique among users"),
+				multiValue("phoneNumbers", "Phone number in E.164 format, unique among users"),
+				scimAttribute("active", "boolean", "false when the user is deleted; changing it requires users:delete", "readWrite"),
+				reference("groups", "Roles of the user", "readOnly", "Group"),
+			},
+			Meta: output.SCIMMetaOut{ResourceType: "Schema", Location: scim.BasePath + "/Schemas/" + scim.SchemaUser},
+		},
+		{
+			Schemas: []string{scim.SchemaSchema}, ID: scim.SchemaGroup, Name: "Group", Description: "Role assigned to users",
+			Attributes: []output.SCIMAttributeOut{
+				displayName,
+				reference("members", "Users with the role", "readWrite", "User"),
+			},
+			Meta: output.SCIMMetaOut{ResourceType: "Schema", Location: scim.BasePath + "/Schemas/" + scim.SchemaGroup},
+		},
+	}
+	return output.SCIMSchemaListOut{
+		Schemas:      []string{scim.SchemaListResponse},
+		TotalResults: int64(len(schemas)),
+		StartIndex:   1,
+		ItemsPerPage: len(schemas),
+		Resources:    schemas,
+	}
+}
+
+func scimAttribute(name, attributeType, description, mutability string) output.SCIMAttributeOut {
+	return output.SCIMAttributeOut{
+		Name:        name,
+		Type:        attributeType,
+		Description: description,
+		Mutability:  mutability,
+		Returned:    "default",
+		Uniqueness:  "none",
+	}
+}
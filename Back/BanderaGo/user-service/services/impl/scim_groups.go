@@ -0,0 +1,288 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/models"
+	"application/scim"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+)
+
+func (s *SCIMServiceImpl) GetGroups(ctx context.Context, listIn input.SCIMListIn) (output.SCIMGroupListOut, error) {
+	roles, err := s.filterRoles(ctx, listIn.Filter)
+	if err != nil {
+		return output.SCIMGroupListOut{}, err
+	}
+	startIndex, count := scimPage(listIn)
+	first := min(startIndex-1, len(roles))
+	last := min(first+count, len(roles))
+
+	groupsOut := make([]output.SCIMGroupOut, 0, last-first)
+	for _, role := range roles[first:last] {
+		groupOut, err := s.groupOut(ctx, role)
+		if err != nil {
+			return output.SCIMGroupListOut{}, err
+		}
+		groupsOut = append(groupsOut, groupOut)
+	}
+	return output.SCIMGroupListOut{
+		Schemas:      []string{scim.SchemaListResponse},
+		TotalResults: int64(len(roles)),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(groupsOut),
+		Resources:    groupsOut,
+	}, nil
+}
+
+// filterRoles devuelve los roles ordenados por nombre; el único filtro que se admite es
+// displayName eq, con el que los proveedores de identidad buscan un grupo antes de crearlo
+func (s *SCIMServiceImpl) filterRoles(ctx context.Context, filter string) ([]*models.Role, error) {
+	if strings.TrimSpace(filter) == "" {
+		return s.roles.GetRoles(ctx)
+	}
+	comparisons, err := scim.ParseFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	name, isText := comparisons[0].Value.(string)
+	if len(comparisons) != 1 || !isText || comparisons[0].Operator != scim.OpEqual || !comparisons[0].Is("displayName") {
+		return nil, scim.NewError(scim.ErrorInvalidFilter, "solo se admite el filtro displayName eq")
+	}
+	role, err := s.roles.GetRole(ctx, name)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return []*models.Role{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []*models.Role{role}, nil
+}
+
+func (s *SCIMServiceImpl) GetGroup(ctx context.Context, id string) (output.SCIMGroupOut, error) {
+	role, err := s.roles.GetRole(ctx, id)
+	if err != nil {
+		return output.SCIMGroupOut{}, err
+	}
+	return s.groupOut(ctx, role)
+}
+
+// CreateGroup crea el rol sin permisos, que se le otorgan con la API de roles, y se lo
+// asigna a los miembros
+func (s *SCIMServiceImpl) CreateGroup(ctx context.Context, groupIn input.SCIMGroupIn) (output.SCIMGroupOut, error) {
+	members, err := scimMembers(groupIn.Members)
+	if err != nil {
+		return output.SCIMGroupOut{}, err
+	}
+	var groupOut output.SCIMGroupOut
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		roleOut, err := s.roleService.CreateRole(ctx, input.RoleIn{Name: groupIn.DisplayName, Permissions: []string{}})
+		if err != nil {
+			return err
+		}
+		if err := s.setMembers(ctx, roleOut.Name, []uint{}, members); err != nil {
+			return err
+		}
+		groupOut, err = s.GetGroup(ctx, roleOut.Name)
+		return err
+	})
+	return groupOut, err
+}
+
+func (s *SCIMServiceImpl) ReplaceGroup(ctx context.Context, id string, groupIn input.SCIMGroupIn) (output.SCIMGroupOut, error) {
+	if groupIn.DisplayName != "" && groupIn.DisplayName != id {
+		return output.SCIMGroupOut{}, scim.NewError(scim.ErrorMutability, "el nombre de un grupo no puede cambiar")
+	}
+	members, err := scimMembers(groupIn.Members)
+	if err != nil {
+		return output.SCIMGroupOut{}, err
+	}
+	return s.changeMembers(ctx, id, func([]uint) ([]uint, error) { return members, nil })
+}
+
+func (s *SCIMServiceImpl) PatchGroup(ctx context.Context, id string, patchIn input.SCIMPatchIn) (output.SCIMGroupOut, error) {
+	return s.changeMembers(ctx, id, func(members []uint) ([]uint, error) {
+		for _, operation := range patchIn.Operations {
+			var err error
+			if members, err = applyGroupOperation(id, members, operation); err != nil {
+				return nil, err
+			}
+		}
+		return members, nil
+	})
+}
+
+// changeMembers calcula los nuevos miembros del grupo y asigna o retira el rol a quienes
+// entran o salen
+func (s *SCIMServiceImpl) changeMembers(ctx context.Context, id string, change func(members []uint) ([]uint, error)) (output.SCIMGroupOut, error) {
+	var groupOut output.SCIMGroupOut
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.roles.GetRole(ctx, id); err != nil {
+			return err
+		}
+		current, err := s.roles.GetRoleMembers(ctx, id)
+		if err != nil {
+			return err
+		}
+		members, err := change(slices.Clone(current))
+		if err != nil {
+			return err
+		}
+		if err := s.setMembers(ctx, id, current, members); err != nil {
+			return err
+		}
+		groupOut, err = s.GetGroup(ctx, id)
+		return err
+	})
+	return groupOut, err
+}
+
+func (s *SCIMServiceImpl) setMembers(ctx context.Context, role string, current, members []uint) error {
+	for _, userID := range members {
+		if !slices.Contains(current, userID) {
+			if err := memberError(userID, s.roleService.AssignRole(ctx, userID, role)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, userID := range current {
+		if !slices.Contains(members, userID) {
+			if err := memberError(userID, s.roleService.RevokeRole(ctx, userID, role)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// memberError informa como un valor inválido que el miembro no existe; RoleService no
+// cambia los roles de los usuarios eliminados lógicamente
+func memberError(userID uint, err error) error {
+	if errors.Is(err, apperrors.ErrNotFound) && apperrors.FieldOf(err) != "role" {
+		return scim.NewError(scim.ErrorInvalidValue, "el miembro "+scim.ResourceID(userID)+" no existe o está inactivo")
+	}
+	return err
+}
+
+func (s *SCIMServiceImpl) DeleteGroup(ctx context.Context, id string) error {
+	return s.roleService.DeleteRole(ctx, id)
+}
+
+func (s *SCIMServiceImpl) groupOut(ctx context.Context, role *models.Role) (output.SCIMGroupOut, error) {
+	members, err := s.roles.GetRoleMembers(ctx, role.Name)
+	if err != nil {
+		return output.SCIMGroupOut{}, err
+	}
+	membersOut := make([]output.SCIMMemberOut, 0, len(members))
+	for _, userID := range members {
+		id := scim.ResourceID(userID)
+		membersOut = append(membersOut, output.SCIMMemberOut{Value: id, Ref: scim.UsersPath + "/" + id})
+	}
+	return output.SCIMGroupOut{
+		Schemas:     []string{scim.SchemaGroup},
+		ID:          role.Name,
+		DisplayName: role.Name,
+		Members:     membersOut,
+		Meta: output.SCIMMetaOut{
+			ResourceType: "Group",
+			Created:      &role.CreatedAt,
+			LastModified: &role.UpdatedAt,
+			Location:     scim.GroupsPath + "/" + role.Name,
+		},
+	}, nil
+}
+
+// scimMembers convierte los miembros en IDs de usuario ordenados y sin repetir
+func scimMembers(membersIn []input.SCIMMemberIn) ([]uint, error) {
+	members := make([]uint, 0, len(membersIn))
+	for _, member := range membersIn {
+		userID, ok := scim.ParseID(member.Value)
+		if !ok {
+			return nil, scim.NewError(scim.ErrorInvalidValue, "el miembro "+member.Value+" no es el id de un usuario")
+		}
+		members = append(members, userID)
+	}
+	slices.Sort(members)
+	return slices.Compact(members), nil
+}
+
+// applyGroupOperation aplica una operación de PATCH a los miembros del grupo. Las rutas
+// admitidas son members, con un filtro value eq para quitar a uno, y displayName, que no
+// puede cambiar.
+func applyGroupOperation(name string, members []uint, operation input.SCIMOperationIn) ([]uint, error) {
+	op := strings.ToLower(operation.Op)
+	if op != scim.OpAdd && op != scim.OpReplace && op != scim.OpRemove {
+		return nil, scim.NewError(scim.ErrorInvalidSyntax, "operación desconocida "+operation.Op)
+	}
+	if operation.Path == "" {
+		if op == scim.OpRemove {
+			return nil, scim.NewError(scim.ErrorNoTarget, "remove requiere path")
+		}
+		var groupIn input.SCIMGroupIn
+		if err := json.Unmarshal(operation.Value, &groupIn); err != nil {
+			return nil, scim.NewError(scim.ErrorInvalidValue, "sin path el valor debe ser un objeto")
+		}
+		if groupIn.DisplayName != "" && groupIn.DisplayName != name {
+			return nil, scim.NewError(scim.ErrorMutability, "el nombre de un grupo no puede cambiar")
+		}
+		if groupIn.Members == nil {
+			return members, nil
+		}
+		value, _ := json.Marshal(groupIn.Members)
+		operation.Path, operation.Value = "members", value
+	}
+
+	path, err := scim.ParsePath(operation.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case path.Is("displayName") && path.SubAttribute == "" && len(path.Filter) == 0:
+		var displayName string
+		if op == scim.OpRemove || json.Unmarshal(operation.Value, &displayName) != nil || displayName != name {
+			return nil, scim.NewError(scim.ErrorMutability, "el nombre de un grupo no puede cambiar")
+		}
+		return members, nil
+	case !path.Is("members") || (path.SubAttribute != "" && !strings.EqualFold(path.SubAttribute, "value")):
+		return nil, scim.NewError(scim.ErrorInvalidPath, "atributo desconocido "+path.String())
+	case len(path.Filter) > 0:
+		// members[value eq "5"] selecciona al miembro que se quita
+		filter := path.Filter[0]
+		value, isText := filter.Value.(string)
+		userID, ok := scim.ParseID(value)
+		if op != scim.OpRemove || len(path.Filter) != 1 || !isText || !ok || filter.Operator != scim.OpEqual || !filter.Is("value") {
+			return nil, scim.NewError(scim.ErrorInvalidPath, "solo se admite quitar un miembro con members[value eq \"id\"]")
+		}
+		return slices.DeleteFunc(members, func(member uint) bool { return member == userID }), nil
+	}
+
+	var changed []uint
+	hasValue := len(operation.Value) > 0 && string(operation.Value) != "null"
+	if hasValue {
+		var membersIn []input.SCIMMemberIn
+		if err := json.Unmarshal(operation.Value, &membersIn); err != nil {
+			return nil, scim.NewError(scim.ErrorInvalidValue, "members debe ser una lista de miembros")
+		}
+		if changed, err = scimMembers(membersIn); err != nil {
+			return nil, err
+		}
+	}
+	switch op {
+	case scim.OpAdd:
+		members = append(members, changed...)
+	case scim.OpReplace:
+		members = changed
+	case scim.OpRemove:
+		// Sin valor se quitan todos los miembros
+		if !hasValue {
+			return []uint{}, nil
+		}
+		members = slices.DeleteFunc(members, func(member uint) bool { return slices.Contains(changed, member) })
+	}
+	slices.Sort(members)
+	return slices.Compact(members), nil
+}
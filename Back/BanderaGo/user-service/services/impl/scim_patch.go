@@ -0,0 +1,239 @@
+package impl
+
+import (
+	"application/dtos/input"
+	"application/models"
+	"application/scim"
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// scimUser son los atributos de SCIM que el servicio guarda de un usuario. El correo y el
+// teléfono son uno solo aunque en SCIM sean multivaluados.
+type scimUser struct {
+	userName    string
+	givenName   string
+	familyName  string
+	displayName string
+	email       string
+	phone       string
+	active      bool
+}
+
+func scimUserOf(user *models.User) scimUser {
+	return scimUser{
+		userName:    stringValue(user.Username),
+		givenName:   user.Name,
+		familyName:  user.LastName,
+		displayName: user.DisplayName,
+		email:       stringValue(user.Email),
+		phone:       stringValue(user.Phone),
+		active:      !user.DeletedAt.Valid,
+	}
+}
+
+// newSCIMUser toma los atributos de la representación completa de un usuario; si no
+// indica active se usa el valor por defecto
+func newSCIMUser(userIn input.SCIMUserIn, active bool) scimUser {
+	user := scimUser{
+		userName:    userIn.UserName,
+		displayName: userIn.DisplayName,
+		email:       primaryValue(userIn.Emails),
+		phone:       primaryValue(userIn.PhoneNumbers),
+		active:      active,
+	}
+	if userIn.Name != nil {
+		user.givenName, user.familyName = userIn.Name.GivenName, userIn.Name.FamilyName
+	}
+	if userIn.Active != nil {
+		user.active = *userIn.Active
+	}
+	return user
+}
+
+// normalized lleva los atributos a la forma con la que se guardan para comparar el
+// estado nuevo con el actual
+func (u scimUser) normalized() scimUser {
+	user := &models.User{Name: u.givenName, LastName: u.familyName, DisplayName: u.displayName,
+		Email: optional(u.email), Username: optional(u.userName), Phone: optional(u.phone)}
+	normalizeUser(user)
+	u.givenName, u.familyName, u.displayName = user.Name, user.LastName, user.DisplayName
+	u.email, u.userName, u.phone = stringValue(user.Email), stringValue(user.Username), stringValue(user.Phone)
+	return u
+}
+
+func (u scimUser) createIn() input.CreateUserIn {
+	return input.CreateUserIn{Name: u.givenName, LastName: u.familyName, Email: u.email,
+		Username: u.userName, Phone: u.phone, DisplayName: u.displayName}
+}
+
+func (u scimUser) updateIn() input.UpdateUserIn {
+	return input.UpdateUserIn{Name: u.givenName, LastName: u.familyName, Email: u.email,
+		Username: u.userName, Phone: u.phone, DisplayName: u.displayName}
+}
+
+// primaryValue elige el valor primario de un atributo multivaluado o, si ninguno lo es, el primero
+func primaryValue(values []input.SCIMValueIn) string {
+	for _, value := range values {
+		if value.Primary {
+			return value.Value
+		}
+	}
+	if len(values) > 0 {
+		return values[0].Value
+	}
+	return ""
+}
+
+// apply aplica una operación de PATCH. Sin path, el valor es un objeto cuyas llaves son
+// las rutas a modificar, como lo envían algunos proveedores de identidad.
+func (u *scimUser) apply(operation input.SCIMOperationIn) error {
+	op := strings.ToLower(operation.Op)
+	if op != scim.OpAdd && op != scim.OpReplace && op != scim.OpRemove {
+		return scim.NewError(scim.ErrorInvalidSyntax, "operación desconocida "+operation.Op)
+	}
+	if operation.Path != "" {
+		path, err := scim.ParsePath(operation.Path)
+		if err != nil {
+			return err
+		}
+		if op == scim.OpRemove {
+			return u.remove(path)
+		}
+		return u.set(path, operation.Value)
+	}
+
+	if op == scim.OpRemove {
+		return scim.NewError(scim.ErrorNoTarget, "remove requiere path")
+	}
+	var attributes map[string]json.RawMessage
+	if err := json.Unmarshal(operation.Value, &attributes); err != nil {
+		return scim.NewError(scim.ErrorInvalidValue, "sin path el valor debe ser un objeto")
+	}
+	// Se recorren en orden para que el resultado no dependa del orden del mapa
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		path, err := scim.ParsePath(key)
+		if err != nil {
+			return err
+		}
+		if err := u.set(path, attributes[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// set asigna el valor de add o replace. Un filtro solo se admite en emails y
+// phoneNumbers, que guardan un único valor, por lo que siempre lo selecciona.
+func (u *scimUser) set(path scim.Path, value json.RawMessage) error {
+	if len(path.Filter) > 0 && !path.Is("emails") && !path.Is("phoneNumbers") {
+		return scim.NewError(scim.ErrorInvalidPath, "no se admite un filtro en "+path.Attribute)
+	}
+	switch target := strings.ToLower(path.String()); target {
+	case "username":
+		return decodeSCIMString(value, &u.userName)
+	case "displayname":
+		return decodeSCIMString(value, &u.displayName)
+	case "name.givenname":
+		return decodeSCIMString(value, &u.givenName)
+	case "name.familyname":
+		return decodeSCIMString(value, &u.familyName)
+	case "name.formatted", "externalid":
+		// formatted se deriva de los nombres y externalId no se guarda
+		return nil
+	case "name":
+		// Los subatributos que no se envían conservan su valor (RFC 7644, sección 3.5.2.3)
+		var name map[string]json.RawMessage
+		if err := json.Unmarshal(value, &name); err != nil {
+			return scim.NewError(scim.ErrorInvalidValue, "name debe ser un objeto")
+		}
+		for sub, subValue := range name {
+			if err := u.set(scim.Path{Attribute: "name", SubAttribute: sub}, subValue); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "emails", "phonenumbers":
+		var values []input.SCIMValueIn
+		if err := json.Unmarshal(value, &values); err != nil {
+			// Con un filtro el valor es el elemento seleccionado y no una lista
+			var single input.SCIMValueIn
+			if err := json.Unmarshal(value, &single); err != nil {
+				return scim.NewError(scim.ErrorInvalidValue, path.Attribute+" debe ser una lista de valores")
+			}
+			values = []input.SCIMValueIn{single}
+		}
+		if target == "emails" {
+			u.email = primaryValue(values)
+		} else {
+			u.phone = primaryValue(values)
+		}
+		return nil
+	case "emails.value":
+		return decodeSCIMString(value, &u.email)
+	case "phonenumbers.value":
+		return decodeSCIMString(value, &u.phone)
+	case "emails.type", "emails.primary", "phonenumbers.type", "phonenumbers.primary":
+		return nil
+	case "active":
+		// Algunos proveedores envían el booleano como texto
+		var active any
+		if err := json.Unmarshal(value, &active); err == nil {
+			switch active := active.(type) {
+			case bool:
+				u.active = active
+				return nil
+			case string:
+				if strings.EqualFold(active, "true") || strings.EqualFold(active, "false") {
+					u.active = strings.EqualFold(active, "true")
+					return nil
+				}
+			}
+		}
+		return scim.NewError(scim.ErrorInvalidValue, "active debe ser un booleano")
+	}
+	return scim.NewError(scim.ErrorInvalidPath, "atributo desconocido "+path.String())
+}
+
+// remove quita el valor del atributo; quitar un dato obligatorio lo deja vacío y la
+// validación del usuario lo rechaza
+func (u *scimUser) remove(path scim.Path) error {
+	if len(path.Filter) > 0 && !path.Is("emails") && !path.Is("phoneNumbers") {
+		return scim.NewError(scim.ErrorInvalidPath, "no se admite un filtro en "+path.Attribute)
+	}
+	switch strings.ToLower(path.String()) {
+	case "username":
+		u.userName = ""
+	case "displayname":
+		u.displayName = ""
+	case "name":
+		u.givenName, u.familyName = "", ""
+	case "name.givenname":
+		u.givenName = ""
+	case "name.familyname":
+		u.familyName = ""
+	case "emails", "emails.value":
+		u.email = ""
+	case "phonenumbers", "phonenumbers.value":
+		u.phone = ""
+	case "name.formatted", "externalid":
+	case "active":
+		return scim.NewError(scim.ErrorMutability, "active no se puede quitar")
+	default:
+		return scim.NewError(scim.ErrorInvalidPath, "atributo desconocido "+path.String())
+	}
+	return nil
+}
+
+func decodeSCIMString(value json.RawMessage, target *string) error {
+	if err := json.Unmarshal(value, target); err != nil {
+		return scim.NewError(scim.ErrorInvalidValue, "se esperaba un texto")
+	}
+	return nil
+}
@@ -0,0 +1,331 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/models"
+	"application/persistence/repositories"
+	"application/scim"
+	"application/services"
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	errSCIMUserNotFound = errors.New("el usuario no existe")
+	errSCIMInactiveUser = errors.New("un usuario inactivo no se puede modificar sin activarlo")
+)
+
+// SCIMServiceImpl traduce los recursos de SCIM a los usuarios y roles del servicio. Las
+// escrituras pasan por UserService y RoleService para que se validen, versionen y auditen
+// igual que en la API; las lecturas usan los repositorios porque incluyen a los usuarios
+// eliminados lógicamente, que SCIM muestra como inactivos.
+type SCIMServiceImpl struct {
+	userService services.UserService
+	roleService services.RoleService
+	users       repositories.UserRepository
+	roles       repositories.RoleRepository
+	tx          repositories.Transactor
+}
+
+func NewSCIMService(userService services.UserService, roleService services.RoleService, users repositories.UserRepository, roles repositories.RoleRepository, tx repositories.Transactor) *SCIMServiceImpl {
+	return &SCIMServiceImpl{userService: userService, roleService: roleService, users: users, roles: roles, tx: tx}
+}
+
+func (s *SCIMServiceImpl) GetUsers(ctx context.Context, listIn input.SCIMListIn) (output.SCIMUserListOut, error) {
+	filter, err := scimUserFilter(listIn.Filter)
+	if err != nil {
+		return output.SCIMUserListOut{}, err
+	}
+	startIndex, count := scimPage(listIn)
+	page, err := s.users.GetAllUsers(ctx, repositories.UserQuery{
+		Filter: filter,
+		Sort:   []repositories.SortField{{Column: "id"}},
+		Limit:  count,
+		Offset: startIndex - 1,
+	})
+	if err != nil {
+		return output.SCIMUserListOut{}, err
+	}
+
+	usersOut := make([]output.SCIMUserOut, 0, len(page.Users))
+	for _, user := range page.Users {
+		userOut, err := s.userOut(ctx, user)
+		if err != nil {
+			return output.SCIMUserListOut{}, err
+		}
+		usersOut = append(usersOut, userOut)
+	}
+	return output.SCIMUserListOut{
+		Schemas:      []string{scim.SchemaListResponse},
+		TotalResults: page.Total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(usersOut),
+		Resources:    usersOut,
+	}, nil
+}
+
+func (s *SCIMServiceImpl) GetUser(ctx context.Context, id string) (output.SCIMUserOut, error) {
+	user, err := s.findUser(ctx, id)
+	if err != nil {
+		return output.SCIMUserOut{}, err
+	}
+	return s.userOut(ctx, user)
+}
+
+// CreateUser crea al usuario con UserService; si llega inactivo se elimina lógicamente
+// en la misma transacción, lo que requiere el permiso users:delete
+func (s *SCIMServiceImpl) CreateUser(ctx context.Context, userIn input.SCIMUserIn) (output.SCIMUserOut, error) {
+	state := newSCIMUser(userIn, true)
+	if state.userName == "" {
+		return output.SCIMUserOut{}, scim.NewError(scim.ErrorInvalidValue, "userName es obligatorio")
+	}
+	if !state.active {
+		if err := authorizePermission(ctx, models.PermissionUsersDelete); err != nil {
+			return output.SCIMUserOut{}, err
+		}
+	}
+
+	var user *models.User
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		created, err := s.userService.CreateUser(ctx, state.createIn())
+		if err != nil {
+			return err
+		}
+		if !state.active {
+			if _, err := s.userService.DeleteUser(ctx, created.ID); err != nil {
+				return err
+			}
+		}
+		user, err = s.users.GetUserByIDUnscoped(ctx, created.ID)
+		return err
+	})
+	if err != nil {
+		return output.SCIMUserOut{}, err
+	}
+	return s.userOut(ctx, user)
+}
+
+func (s *SCIMServiceImpl) ReplaceUser(ctx context.Context, id string, userIn input.SCIMUserIn, ifMatch *input.Precondition) (output.SCIMUserOut, error) {
+	return s.changeUser(ctx, id, ifMatch, func(user *models.User) (scimUser, error) {
+		state := newSCIMUser(userIn, !user.DeletedAt.Valid)
+		if state.userName == "" {
+			return scimUser{}, scim.NewError(scim.ErrorInvalidValue, "userName es obligatorio")
+		}
+		return state, nil
+	})
+}
+
+func (s *SCIMServiceImpl) PatchUser(ctx context.Context, id string, patchIn input.SCIMPatchIn, ifMatch *input.Precondition) (output.SCIMUserOut, error) {
+	return s.changeUser(ctx, id, ifMatch, func(user *models.User) (scimUser, error) {
+		state := scimUserOf(user)
+		for _, operation := range patchIn.Operations {
+			if err := state.apply(operation); err != nil {
+				return scimUser{}, err
+			}
+		}
+		return state, nil
+	})
+}
+
+// changeUser calcula el nuevo estado del usuario y lo guarda con UserService. Cambiar
+// active restaura o elimina lógicamente al usuario y requiere el permiso users:delete,
+// como las rutas que hacen lo mismo en la API.
+func (s *SCIMServiceImpl) changeUser(ctx context.Context, id string, ifMatch *input.Precondition, change func(user *models.User) (scimUser, error)) (output.SCIMUserOut, error) {
+	var user *models.User
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := s.findUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !ifMatch.Matches(current.Version) {
+			return apperrors.PreconditionFailed(errVersionMismatch)
+		}
+		state, err := change(current)
+		if err != nil {
+			return err
+		}
+		if err := s.saveUser(ctx, current, state.normalized()); err != nil {
+			return err
+		}
+		user, err = s.users.GetUserByIDUnscoped(ctx, current.ID)
+		return err
+	})
+	if err != nil {
+		return output.SCIMUserOut{}, err
+	}
+	return s.userOut(ctx, user)
+}
+
+func (s *SCIMServiceImpl) saveUser(ctx context.Context, user *models.User, state scimUser) error {
+	current := scimUserOf(user).normalized()
+	active := current.active
+	if state.active != active {
+		if err := authorizePermission(ctx, models.PermissionUsersDelete); err != nil {
+			return err
+		}
+	}
+
+	version := user.Version
+	if state.active && !active {
+		restored, err := s.userService.RestoreUser(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		version = restored.Version
+	}
+	current.active = state.active
+	if state != current {
+		if !state.active && !active {
+			return scim.NewError(scim.ErrorInvalidValue, errSCIMInactiveUser.Error())
+		}
+		updateIn := state.updateIn()
+		// La versión leída protege la escritura de los cambios que ocurran mientras tanto
+		updateIn.IfMatch = &input.Precondition{Versions: []uint{version}}
+		if _, err := s.userService.UpdateUser(ctx, user.ID, updateIn); err != nil {
+			return err
+		}
+	}
+	if !state.active && active {
+		if _, err := s.userService.DeleteUser(ctx, user.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteUser elimina definitivamente al usuario; para conservarlo, el proveedor de
+// identidad debe desactivarlo con active en false
+func (s *SCIMServiceImpl) DeleteUser(ctx context.Context, id string) error {
+	userID, ok := scim.ParseID(id)
+	if !ok {
+		return apperrors.NotFound(errSCIMUserNotFound)
+	}
+	_, err := s.userService.PurgeUser(ctx, userID)
+	return err
+}
+
+func (s *SCIMServiceImpl) findUser(ctx context.Context, id string) (*models.User, error) {
+	userID, ok := scim.ParseID(id)
+	if !ok {
+		return nil, apperrors.NotFound(errSCIMUserNotFound)
+	}
+	return s.users.GetUserByIDUnscoped(ctx, userID)
+}
+
+func (s *SCIMServiceImpl) userOut(ctx context.Context, user *models.User) (output.SCIMUserOut, error) {
+	roles, err := s.roles.GetUserRoles(ctx, user.ID)
+	if err != nil {
+		return output.SCIMUserOut{}, err
+	}
+	groups := make([]output.SCIMMemberOut, 0, len(roles))
+	for _, role := range roles {
+		groups = append(groups, output.SCIMMemberOut{Value: role, Ref: scim.GroupsPath + "/" + role, Display: role})
+	}
+
+	id := scim.ResourceID(user.ID)
+	userOut := output.SCIMUserOut{
+		Schemas:  []string{scim.SchemaUser},
+		ID:       id,
+		UserName: stringValue(user.Username),
+		Name: output.SCIMNameOut{
+			Formatted:  strings.TrimSpace(user.Name + " " + user.LastName),
+			GivenName:  user.Name,
+			FamilyName: user.LastName,
+		},
+		DisplayName: user.DisplayName,
+		Active:      !user.DeletedAt.Valid,
+		Groups:      groups,
+		Meta: output.SCIMMetaOut{
+			ResourceType: "User",
+			Created:      &user.CreatedAt,
+			LastModified: &user.UpdatedAt,
+			Version:      scim.Version(user.Version),
+			Location:     scim.UsersPath + "/" + id,
+		},
+		Version: user.Version,
+	}
+	if user.Email != nil {
+		userOut.Emails = []output.SCIMValueOut{{Value: *user.Email, Type: "work", Primary: true}}
+	}
+	if user.Phone != nil {
+		userOut.PhoneNumbers = []output.SCIMValueOut{{Value: *user.Phone, Type: "work", Primary: true}}
+	}
+	return userOut, nil
+}
+
+// scimUserFilter traduce el filtro de SCIM a los criterios del repositorio. Se admiten
+// userName, emails y phoneNumbers por igualdad, name.givenName y name.familyName por
+// prefijo, active y meta.created; los valores se normalizan como se guardan. Cada criterio
+// se admite una sola vez, ya que el repositorio no combina dos valores del mismo campo.
+func scimUserFilter(filter string) (repositories.UserFilter, error) {
+	result := repositories.UserFilter{Deleted: repositories.DeletedInclude}
+	if strings.TrimSpace(filter) == "" {
+		return result, nil
+	}
+	comparisons, err := scim.ParseFilter(filter)
+	if err != nil {
+		return repositories.UserFilter{}, err
+	}
+
+	seen := make(map[string]bool)
+	for _, comparison := range comparisons {
+		text, isText := comparison.Value.(string)
+		text = normalizeText(text)
+		flag, isFlag := comparison.Value.(bool)
+		equal := comparison.Operator == scim.OpEqual
+		var criterion string
+		switch {
+		case isText && text != "" && equal && comparison.Is("userName"):
+			result.Username, criterion = strings.ToLower(text), "userName"
+		case isText && text != "" && equal && (comparison.Is("emails") || comparison.Is("emails.value")):
+			result.Email, criterion = strings.ToLower(text), "emails"
+		case isText && text != "" && equal && (comparison.Is("phoneNumbers") || comparison.Is("phoneNumbers.value")):
+			result.Phone, criterion = normalizePhone(text), "phoneNumbers"
+		case isText && text != "" && comparison.Operator == scim.OpStartsWith && comparison.Is("name.givenName"):
+			result.Name, criterion = text, "name.givenName"
+		case isText && text != "" && comparison.Operator == scim.OpStartsWith && comparison.Is("name.familyName"):
+			result.LastName, criterion = text, "name.familyName"
+		case isFlag && equal && comparison.Is("active"):
+			criterion = "active"
+			result.Deleted = repositories.DeletedExclude
+			if !flag {
+				result.Deleted = repositories.DeletedOnly
+			}
+		case isText && comparison.Is("meta.created") && (comparison.Operator == scim.OpGreater || comparison.Operator == scim.OpLess):
+			created, err := time.Parse(time.RFC3339, text)
+			if err != nil {
+				return repositories.UserFilter{}, scim.NewError(scim.ErrorInvalidFilter, "meta.created debe ser una fecha RFC 3339")
+			}
+			criterion = "meta.created " + comparison.Operator
+			if comparison.Operator == scim.OpGreater {
+				result.CreatedAfter = &created
+			} else {
+				result.CreatedBefore = &created
+			}
+		default:
+			return repositories.UserFilter{}, scim.NewError(scim.ErrorInvalidFilter,
+				"no se admite el filtro "+comparison.Attribute+" "+comparison.Operator)
+		}
+		if seen[criterion] {
+			return repositories.UserFilter{}, scim.NewError(scim.ErrorInvalidFilter,
+				"no se admite repetir el filtro "+comparison.Attribute+" "+comparison.Operator)
+		}
+		seen[criterion] = true
+	}
+	return result, nil
+}
+
+// scimPage devuelve el índice inicial, que empieza en 1, y el tamaño de la página. Los
+// valores fuera de rango se ajustan como indica el RFC 7644, sección 3.4.2.4.
+func scimPage(listIn input.SCIMListIn) (int, int) {
+	startIndex := max(listIn.StartIndex, 1)
+	count := scim.DefaultCount
+	if listIn.Count != nil {
+		count = min(max(*listIn.Count, 0), scim.DefaultCount)
+	}
+	return startIndex, count
+}
@@ -0,0 +1,234 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/models"
+	repoImpl "application/persistence/repositories/impl"
+	"application/requestctx"
+	"application/scim"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSCIMService() *SCIMServiceImpl {
+	users, roles := repoImpl.NewMemoryUserRepository(), repoImpl.NewMemoryRoleRepository()
+	audit, tx := repoImpl.NewMemoryAuditRepository(), repoImpl.NewMemoryTransactor()
+	userService := NewUserService(users, repoImpl.NewMemoryUserVersionRepository(), audit, tx)
+	roleService := NewRoleService(roles, users, audit, tx, nil)
+	return NewSCIMService(userService, roleService, users, roles, tx)
+}
+
+func scimUserIn(userName, email string) input.SCIMUserIn {
+	return input.SCIMUserIn{
+		Schemas:  []string{scim.SchemaUser},
+		UserName: userName,
+		Name:     &input.SCIMNameIn{GivenName: "Ana", FamilyName: "Díaz"},
+		Emails:   []input.SCIMValueIn{{Value: "otra@example.com"}, {Value: email, Type: "work", Primary: true}},
+	}
+}
+
+func scimOperation(op, path, value string) input.SCIMOperationIn {
+	return input.SCIMOperationIn{Op: op, Path: path, Value: json.RawMessage(value)}
+}
+
+func assertSCIMError(t *testing.T, err error, scimType string) {
+	t.Helper()
+	var scimErr *scim.Error
+	require.True(t, errors.As(err, &scimErr), "se esperaba un error de SCIM: %v", err)
+	assert.Equal(t, scimType, scimErr.Type)
+}
+
+// Caso de prueba: crear un usuario guarda el correo primario y los filtros lo encuentran
+func TestSCIMCreateAndFilterUsers(t *testing.T) {
+	service := newTestSCIMService()
+	ctx := context.Background()
+
+	userOut, err := service.CreateUser(ctx, scimUserIn("Ana.Diaz", "ANA@example.com"))
+	require.NoError(t, err)
+	assert.Equal(t, "1", userOut.ID)
+	assert.Equal(t, "ana.diaz", userOut.UserName)
+	assert.Equal(t, "Ana Díaz", userOut.Name.Formatted)
+	assert.Equal(t, "ana@example.com", userOut.Emails[0].Value)
+	assert.True(t, userOut.Active)
+	assert.Equal(t, `"1"`, userOut.Meta.Version)
+	assert.Equal(t, "/scim/v2/Users/1", userOut.Meta.Location)
+	eva := scimUserIn("eva", "eva@example.com")
+	eva.Name.GivenName = "Eva"
+	_, err = service.CreateUser(ctx, eva)
+	require.NoError(t, err)
+
+	for _, filter := range []string{`userName eq "ANA.DIAZ"`, `emails.value eq "ana@example.com" and active eq true`, `name.givenName sw "an"`} {
+		listOut, err := service.GetUsers(ctx, input.SCIMListIn{Filter: filter})
+		require.NoError(t, err, filter)
+		assert.Equal(t, int64(1), listOut.TotalResults, filter)
+		assert.Equal(t, "1", listOut.Resources[0].ID, filter)
+	}
+
+	// count=0 solo cuenta los resultados
+	count := 0
+	listOut, err := service.GetUsers(ctx, input.SCIMListIn{Count: &count})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), listOut.TotalResults)
+	assert.Empty(t, listOut.Resources)
+
+	// Un rango de fechas usa dos comparaciones; repetir un criterio no se admite
+	listOut, err = service.GetUsers(ctx, input.SCIMListIn{Filter: `meta.created gt "2000-01-01T00:00:00Z" and meta.created lt "2100-01-01T00:00:00Z"`})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), listOut.TotalResults)
+	for _, filter := range []string{`displayName co "a"`, `userName eq "ana.diaz" and userName eq "eva"`, `emails eq "ana@example.com" and emails.value eq "eva@example.com"`} {
+		_, err = service.GetUsers(ctx, input.SCIMListIn{Filter: filter})
+		assertSCIMError(t, err, scim.ErrorInvalidFilter)
+	}
+	_, err = service.CreateUser(ctx, scimUserIn("", "x@example.com"))
+	assertSCIMError(t, err, scim.ErrorInvalidValue)
+	_, err = service.CreateUser(ctx, scimUserIn("otra", "ana@example.com"))
+	assert.ErrorIs(t, err, apperrors.ErrConflict)
+	_, err = service.GetUser(ctx, "abc")
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+}
+
+// Caso de prueba: cambiar active elimina lógicamente o restaura al usuario y requiere users:delete
+func TestSCIMReplaceUserActive(t *testing.T) {
+	service := newTestSCIMService()
+	userOut, err := service.CreateUser(context.Background(), scimUserIn("ana", "ana@example.com"))
+	require.NoError(t, err)
+
+	inactive := scimUserIn("ana", "ana@example.com")
+	inactive.Active = new(bool)
+	updater := requestctx.WithIdentity(context.Background(), requestctx.Identity{Subject: "9", Permissions: []string{models.PermissionUsersUpdate}})
+	_, err = service.ReplaceUser(updater, userOut.ID, inactive, nil)
+	assert.ErrorIs(t, err, apperrors.ErrForbidden)
+
+	admin := requestctx.WithIdentity(context.Background(), requestctx.Identity{Subject: "9", Permissions: models.Permissions})
+	_, err = service.ReplaceUser(admin, userOut.ID, inactive, &input.Precondition{Versions: []uint{7}})
+	assert.ErrorIs(t, err, apperrors.ErrPreconditionFailed)
+	userOut, err = service.ReplaceUser(admin, userOut.ID, inactive, &input.Precondition{Versions: []uint{userOut.Version}})
+	require.NoError(t, err)
+	assert.False(t, userOut.Active)
+
+	listOut, err := service.GetUsers(admin, input.SCIMListIn{Filter: "active eq false"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), listOut.TotalResults)
+
+	// Un usuario inactivo solo puede modificarse al activarlo
+	renamed := scimUserIn("ana.maria", "ana@example.com")
+	_, err = service.ReplaceUser(admin, userOut.ID, renamed, nil)
+	assertSCIMError(t, err, scim.ErrorInvalidValue)
+
+	userOut, err = service.PatchUser(admin, userOut.ID, input.SCIMPatchIn{Operations: []input.SCIMOperationIn{
+		scimOperation("Replace", "", `{"active": "True", "userName": "ana.maria"}`),
+	}}, nil)
+	require.NoError(t, err)
+	assert.True(t, userOut.Active)
+	assert.Equal(t, "ana.maria", userOut.UserName)
+
+	require.NoError(t, service.DeleteUser(admin, userOut.ID))
+	_, err = service.GetUser(admin, userOut.ID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+}
+
+// Caso de prueba: las operaciones de PATCH modifican solo los atributos indicados
+func TestSCIMPatchUser(t *testing.T) {
+	service := newTestSCIMService()
+	ctx := context.Background()
+	userIn := scimUserIn("ana", "ana@example.com")
+	userIn.PhoneNumbers = []input.SCIMValueIn{{Value: "+52 55 1234 5678"}}
+	userOut, err := service.CreateUser(ctx, userIn)
+	require.NoError(t, err)
+	assert.Equal(t, "+525512345678", userOut.PhoneNumbers[0].Value)
+
+	userOut, err = service.PatchUser(ctx, userOut.ID, input.SCIMPatchIn{Operations: []input.SCIMOperationIn{
+		scimOperation("replace", "name.givenName", `"Eva"`),
+		scimOperation("replace", `emails[type eq "work"].value`, `"eva@example.com"`),
+		scimOperation("add", "", `{"displayName": "Eva D.", "name": {"familyName": "Pérez"}}`),
+		scimOperation("remove", "phoneNumbers", ``),
+	}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Eva", userOut.Name.GivenName)
+	assert.Equal(t, "Pérez", userOut.Name.FamilyName)
+	assert.Equal(t, "Eva D.", userOut.DisplayName)
+	assert.Equal(t, "eva@example.com", userOut.Emails[0].Value)
+	assert.Empty(t, userOut.PhoneNumbers)
+	assert.Equal(t, `"2"`, userOut.Meta.Version)
+
+	for _, operation := range []struct {
+		operation input.SCIMOperationIn
+		scimType  string
+	}{
+		{scimOperation("replace", "title", `"CTO"`), scim.ErrorInvalidPath},
+		{scimOperation("replace", "userName", `5`), scim.ErrorInvalidValue},
+		{scimOperation("remove", "", ``), scim.ErrorNoTarget},
+		{scimOperation("move", "userName", `"eva"`), scim.ErrorInvalidSyntax},
+		{scimOperation("remove", "active", ``), scim.ErrorMutability},
+	} {
+		_, err := service.PatchUser(ctx, userOut.ID, input.SCIMPatchIn{Operations: []input.SCIMOperationIn{operation.operation}}, nil)
+		assertSCIMError(t, err, operation.scimType)
+	}
+
+	// Quitar un dato obligatorio lo rechaza la validación del usuario
+	_, err = service.PatchUser(ctx, userOut.ID, input.SCIMPatchIn{Operations: []input.SCIMOperationIn{scimOperation("remove", "name.familyName", ``)}}, nil)
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+}
+
+// Caso de prueba: los grupos son roles y sus miembros los usuarios que lo tienen asignado
+func TestSCIMGroups(t *testing.T) {
+	service := newTestSCIMService()
+	ctx := context.Background()
+	for _, userName := range []string{"ana", "eva", "luis"} {
+		_, err := service.CreateUser(ctx, scimUserIn(userName, userName+"@example.com"))
+		require.NoError(t, err)
+	}
+
+	groupOut, err := service.CreateGroup(ctx, input.SCIMGroupIn{DisplayName: "support", Members: []input.SCIMMemberIn{{Value: "2"}, {Value: "1"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "support", groupOut.ID)
+	assert.Equal(t, []string{"1", "2"}, memberValues(groupOut.Members))
+	userOut, err := service.GetUser(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "support", userOut.Groups[0].Value)
+
+	groupOut, err = service.PatchGroup(ctx, "support", input.SCIMPatchIn{Operations: []input.SCIMOperationIn{
+		scimOperation("add", "members", `[{"value": "3"}]`),
+		scimOperation("remove", `members[value eq "1"]`, ``),
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "3"}, memberValues(groupOut.Members))
+
+	groupOut, err = service.ReplaceGroup(ctx, "support", input.SCIMGroupIn{DisplayName: "support", Members: []input.SCIMMemberIn{{Value: "1"}}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, memberValues(groupOut.Members))
+
+	_, err = service.ReplaceGroup(ctx, "support", input.SCIMGroupIn{DisplayName: "soporte"})
+	assertSCIMError(t, err, scim.ErrorMutability)
+	_, err = service.PatchGroup(ctx, "support", input.SCIMPatchIn{Operations: []input.SCIMOperationIn{scimOperation("add", "members", `[{"value": "99"}]`)}})
+	assertSCIMError(t, err, scim.ErrorInvalidValue)
+	_, err = service.CreateGroup(ctx, input.SCIMGroupIn{DisplayName: "support"})
+	assert.ErrorIs(t, err, apperrors.ErrConflict)
+
+	listOut, err := service.GetGroups(ctx, input.SCIMListIn{Filter: `displayName eq "support"`})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), listOut.TotalResults)
+	listOut, err = service.GetGroups(ctx, input.SCIMListIn{StartIndex: 2, Count: new(int)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), listOut.TotalResults)
+	assert.Equal(t, 2, listOut.StartIndex)
+
+	require.NoError(t, service.DeleteGroup(ctx, "support"))
+	_, err = service.GetGroup(ctx, "support")
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+}
+
+func memberValues(members []output.SCIMMemberOut) []string {
+	values := make([]string, 0, len(members))
+	for _, member := range members {
+		values = append(values, member.Value)
+	}
+	return values
+}
@@ -0,0 +1,177 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/dtos/output"
+	"application/models"
+	"application/persistence/repositories"
+	"application/requestctx"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	errSessionNotFound = errors.New("la sesión no existe o es de otro usuario")
+	errSessionInactive = errors.New("la sesión del token fue revocada o venció")
+)
+
+// sessionPruneInterval es cada cuánto se eliminan del caché las sesiones vencidas
+const sessionPruneInterval = time.Minute
+
+// SessionServiceImpl consulta y revoca las sesiones de los usuarios. Revocar una sesión
+// impide renovar sus tokens y, pasado a lo sumo cacheTTL, usar los de acceso ya emitidos.
+type SessionServiceImpl struct {
+	sessions repositories.SessionRepository
+	users    repositories.UserRepository
+	cacheTTL time.Duration
+
+	mu        sync.Mutex
+	cache     map[string]cachedSession
+	lastPrune time.Time
+}
+
+// cachedSession recuerda si una sesión estaba activa al consultarla
+type cachedSession struct {
+	active    bool
+	expiresAt time.Time
+}
+
+// NewSessionService recibe cuánto tiempo se recuerda si la sesión de un token está
+// activa; con 0 se consulta en cada solicitud
+func NewSessionService(sessions repositories.SessionRepository, users repositories.UserRepository, cacheTTL time.Duration) *SessionServiceImpl {
+	return &SessionServiceImpl{sessions: sessions, users: users, cacheTTL: cacheTTL, cache: map[string]cachedSession{}}
+}
+
+func (s *SessionServiceImpl) GetUserSessions(ctx context.Context, userID uint) ([]output.SessionOut, error) {
+	if err := s.authorize(ctx, userID); err != nil {
+		return nil, err
+	}
+	sessions, err := s.sessions.GetUserSessions(ctx, userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	var current string
+	if identity, ok := requestctx.IdentityFrom(ctx); ok {
+		current = identity.SessionID
+	}
+	sessionsOut := make([]output.SessionOut, 0, len(sessions))
+	for _, session := range sessions {
+		sessionsOut = append(sessionsOut, output.SessionOut{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    current == strconv.FormatUint(uint64(session.ID), 10),
+		})
+	}
+	return sessionsOut, nil
+}
+
+func (s *SessionServiceImpl) RevokeSession(ctx context.Context, userID, sessionID uint) error {
+	if err := s.authorize(ctx, userID); err != nil {
+		return err
+	}
+	session, err := s.sessions.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	// La sesión de otro usuario se trata como inexistente para no revelar que existe
+	if session.UserID != userID {
+		return apperrors.NotFoundField("session", errSessionNotFound)
+	}
+	return s.EndSession(ctx, sessionID)
+}
+
+func (s *SessionServiceImpl) RevokeUserSessions(ctx context.Context, userID uint) error {
+	if err := s.authorize(ctx, userID); err != nil {
+		return err
+	}
+	return s.EndUserSessions(ctx, userID)
+}
+
+func (s *SessionServiceImpl) EndSession(ctx context.Context, sessionID uint) error {
+	if err := s.sessions.RevokeSession(ctx, sessionID, time.Now().UTC()); err != nil {
+		return err
+	}
+	s.forget(strconv.FormatUint(uint64(sessionID), 10))
+	return nil
+}
+
+func (s *SessionServiceImpl) EndUserSessions(ctx context.Context, userID uint) error {
+	if err := s.sessions.RevokeUserSessions(ctx, userID, time.Now().UTC()); err != nil {
+		return err
+	}
+	s.forget("")
+	return nil
+}
+
+func (s *SessionServiceImpl) VerifySession(ctx context.Context, sessionID string) error {
+	now := time.Now()
+	s.mu.Lock()
+	cached, ok := s.cache[sessionID]
+	s.mu.Unlock()
+	if !ok || !now.Before(cached.expiresAt) {
+		id, err := strconv.ParseUint(sessionID, 10, 64)
+		if err != nil {
+			return apperrors.Unauthorized(errSessionInactive)
+		}
+		session, err := s.sessions.GetSession(ctx, uint(id))
+		if err != nil && !errors.Is(err, apperrors.ErrNotFound) {
+			return err
+		}
+		cached = cachedSession{active: err == nil && session.Active(now), expiresAt: now.Add(s.cacheTTL)}
+		s.remember(sessionID, cached, now)
+	}
+	if !cached.active {
+		return apperrors.Unauthorized(errSessionInactive)
+	}
+	return nil
+}
+
+// remember guarda el estado de la sesión en el caché y elimina de vez en cuando las vencidas
+func (s *SessionServiceImpl) remember(sessionID string, cached cachedSession, now time.Time) {
+	if s.cacheTTL <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastPrune) >= sessionPruneInterval {
+		for id, entry := range s.cache {
+			if !now.Before(entry.expiresAt) {
+				delete(s.cache, id)
+			}
+		}
+		s.lastPrune = now
+	}
+	s.cache[sessionID] = cached
+}
+
+// forget olvida la sesión del caché para que su revocación valga de inmediato en esta
+// instancia; sin sesión olvida todas
+func (s *SessionServiceImpl) forget(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sessionID == "" {
+		clear(s.cache)
+		return
+	}
+	delete(s.cache, sessionID)
+}
+
+// authorize permite la operación al propio usuario o a quien puede gestionar sesiones, y
+// comprueba que el usuario exista
+func (s *SessionServiceImpl) authorize(ctx context.Context, userID uint) error {
+	if err := authorizeSelf(ctx, userID, models.PermissionSessionsManage); err != nil {
+		return err
+	}
+	_, err := s.users.GetUserByID(ctx, userID)
+	return err
+}
@@ -0,0 +1,101 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	repoImpl "application/persistence/repositories/impl"
+	"application/requestctx"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: el usuario lista sus sesiones, ve cuál es la actual y las revoca
+func TestUserSessions(t *testing.T) {
+	users, sessions := repoImpl.NewMemoryUserRepository(), repoImpl.NewMemorySessionRepository()
+	owner := createTestUser(t, users)
+	other := &models.User{Name: "Luis", LastName: "Paz"}
+	require.NoError(t, users.CreateUser(context.Background(), other))
+	service := NewSessionService(sessions, users, 0)
+
+	now := time.Now().UTC()
+	laptop := &models.Session{UserID: owner.ID, UserAgent: "Firefox", IP: "10.0.0.1", LastUsedAt: now, ExpiresAt: now.Add(time.Hour)}
+	phone := &models.Session{UserID: owner.ID, UserAgent: "Android", IP: "10.0.0.2", LastUsedAt: now.Add(time.Second), ExpiresAt: now.Add(time.Hour)}
+	foreign := &models.Session{UserID: other.ID, LastUsedAt: now, ExpiresAt: now.Add(time.Hour)}
+	for i, session := range []*models.Session{laptop, phone, foreign} {
+		require.NoError(t, sessions.CreateSession(context.Background(), session, strconv.Itoa(i)))
+	}
+
+	ctx := requestctx.WithIdentity(context.Background(), requestctx.Identity{Subject: "1", SessionID: strconv.FormatUint(uint64(laptop.ID), 10)})
+	sessionsOut, err := service.GetUserSessions(ctx, owner.ID)
+	require.NoError(t, err)
+	require.Len(t, sessionsOut, 2)
+	assert.Equal(t, "Android", sessionsOut[0].UserAgent)
+	assert.False(t, sessionsOut[0].Current)
+	assert.Equal(t, "10.0.0.1", sessionsOut[1].IP)
+	assert.True(t, sessionsOut[1].Current)
+
+	// Sin el permiso no se ven ni se revocan las sesiones de otro usuario
+	_, err = service.GetUserSessions(ctx, other.ID)
+	assert.ErrorIs(t, err, apperrors.ErrForbidden)
+	assert.ErrorIs(t, service.RevokeUserSessions(ctx, other.ID), apperrors.ErrForbidden)
+	// La sesión de otro usuario no se encuentra bajo el propio
+	err = service.RevokeSession(ctx, owner.ID, foreign.ID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	assert.Equal(t, "session", apperrors.FieldOf(err))
+
+	require.NoError(t, service.RevokeSession(ctx, owner.ID, phone.ID))
+	sessionsOut, err = service.GetUserSessions(ctx, owner.ID)
+	require.NoError(t, err)
+	require.Len(t, sessionsOut, 1)
+	assert.Equal(t, laptop.ID, sessionsOut[0].ID)
+
+	admin := requestctx.WithIdentity(context.Background(), requestctx.Identity{Subject: "9", Permissions: []string{models.PermissionSessionsManage}})
+	require.NoError(t, service.RevokeUserSessions(admin, owner.ID))
+	sessionsOut, err = service.GetUserSessions(admin, owner.ID)
+	require.NoError(t, err)
+	assert.Empty(t, sessionsOut)
+	sessionsOut, err = service.GetUserSessions(admin, other.ID)
+	require.NoError(t, err)
+	assert.Len(t, sessionsOut, 1)
+
+	_, err = service.GetUserSessions(admin, 99)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+}
+
+// Caso de prueba: la sesión de un token se recuerda activa durante el caché y revocarla
+// desde el servicio la rechaza de inmediato
+func TestVerifySession(t *testing.T) {
+	users, sessions := repoImpl.NewMemoryUserRepository(), repoImpl.NewMemorySessionRepository()
+	owner := createTestUser(t, users)
+	service := NewSessionService(sessions, users, time.Minute)
+	ctx := requestctx.WithIdentity(context.Background(), requestctx.Identity{Subject: strconv.FormatUint(uint64(owner.ID), 10)})
+
+	now := time.Now().UTC()
+	laptop := &models.Session{UserID: owner.ID, LastUsedAt: now, ExpiresAt: now.Add(time.Hour)}
+	phone := &models.Session{UserID: owner.ID, LastUsedAt: now, ExpiresAt: now.Add(time.Hour)}
+	expired := &models.Session{UserID: owner.ID, LastUsedAt: now, ExpiresAt: now.Add(-time.Minute)}
+	for i, session := range []*models.Session{laptop, phone, expired} {
+		require.NoError(t, sessions.CreateSession(ctx, session, strconv.Itoa(i)))
+	}
+	laptopID, phoneID := strconv.FormatUint(uint64(laptop.ID), 10), strconv.FormatUint(uint64(phone.ID), 10)
+	require.NoError(t, service.VerifySession(ctx, laptopID))
+	require.NoError(t, service.VerifySession(ctx, phoneID))
+
+	// Revocada fuera del servicio, como desde otra instancia, sigue en el caché
+	require.NoError(t, sessions.RevokeSession(ctx, phone.ID, now))
+	assert.NoError(t, service.VerifySession(ctx, phoneID))
+
+	require.NoError(t, service.RevokeSession(ctx, owner.ID, laptop.ID))
+	assert.ErrorIs(t, service.VerifySession(ctx, laptopID), apperrors.ErrUnauthorized)
+	require.NoError(t, service.RevokeUserSessions(ctx, owner.ID))
+	assert.ErrorIs(t, service.VerifySession(ctx, phoneID), apperrors.ErrUnauthorized)
+
+	for _, sessionID := range []string{strconv.FormatUint(uint64(expired.ID), 10), "99", "abc"} {
+		assert.ErrorIs(t, service.VerifySession(ctx, sessionID), apperrors.ErrUnauthorized, sessionID)
+	}
+}
@@ -0,0 +1,88 @@
+package impl
+
+import (
+	"application/models"
+	"application/requestctx"
+	"context"
+	"reflect"
+	"time"
+)
+
+// Actor de las operaciones que no provienen de una solicitud
+const systemActor = "system"
+
+// auditSnapshot devuelve los campos auditables del usuario; nil representa un usuario
+// que todavía no existe o que ya se eliminó definitivamente
+func auditSnapshot(user *models.User) map[string]interface{} {
+	if user == nil {
+		return nil
+	}
+	var deletedAt *time.Time
+	if user.DeletedAt.Valid {
+		deletedAt = &user.DeletedAt.Time
+	}
+	return map[string]interface{}{
+		"name":         user.Name,
+		"last_name":    user.LastName,
+		"email":        user.Email,
+		"username":     user.Username,
+		"phone":        user.Phone,
+		"display_name": optional(user.DisplayName),
+		"deleted_at":   deletedAt,
+		// Del cambio de contraseña solo se registra la fecha, nunca el hash
+		"password_changed_at": user.PasswordChangedAt,
+		"email_verified_at":   user.EmailVerifiedAt,
+	}
+}
+
+// diffUsers devuelve los campos que cambian entre las dos versiones del usuario
+func diffUsers(before, after *models.User) map[string]models.FieldChange {
+	beforeFields, afterFields := auditSnapshot(before), auditSnapshot(after)
+	changes := make(map[string]models.FieldChange)
+	for _, fields := range []map[string]interface{}{beforeFields, afterFields} {
+		for field := range fields {
+			beforeValue, afterValue := nullable(beforeFields[field]), nullable(afterFields[field])
+			if !reflect.DeepEqual(beforeValue, afterValue) {
+				changes[field] = models.FieldChange{Before: beforeValue, After: afterValue}
+			}
+		}
+	}
+	return changes
+}
+
+// nullable convierte los punteros nulos en nil para compararlos y serializarlos igual
+func nullable(value interface{}) interface{} {
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		return v.Elem().Interface()
+	}
+	return value
+}
+
+// recordAudit registra la operación con el actor y la solicitud del contexto. Se llama
+// dentro de la transacción de la operación para que ambas se confirmen o ninguna.
+func (s *UserServiceImpl) recordAudit(ctx context.Context, operation string, userID uint, before, after *models.User) error {
+	return s.audit.CreateAuditRecord(ctx, newAuditRecord(ctx, operation, userID, before, after))
+}
+
+// newAuditRecord arma el registro de auditoría de una operación sobre el usuario
+func newAuditRecord(ctx context.Context, operation string, userID uint, before, after *models.User) *models.AuditRecord {
+	return newAuditEntry(ctx, operation, userID, diffUsers(before, after))
+}
+
+// newAuditEntry arma el registro de auditoría con los cambios indicados
+func newAuditEntry(ctx context.Context, operation string, userID uint, changes map[string]models.FieldChange) *models.AuditRecord {
+	actor := requestctx.Actor(ctx)
+	if actor == "" {
+		actor = systemActor
+	}
+	return &models.AuditRecord{
+		UserID:    userID,
+		Actor:     actor,
+		RequestID: requestctx.RequestID(ctx),
+		Operation: operation,
+		Changes:   changes,
+	}
+}
@@ -0,0 +1,60 @@
+package impl
+
+import (
+	"application/models"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var phoneSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+
+// normalizeUser lleva los datos del usuario a la forma con la que se guardan y comparan:
+// nombres en Unicode NFC, correo y nombre de usuario en minúsculas y teléfono sin
+// separadores. Así dos valores que solo difieren en su escritura chocan con los índices
+// únicos. La validez de cada dato la revisa validateUser.
+func normalizeUser(user *models.User) {
+	user.Name = normalizeText(user.Name)
+	user.LastName = normalizeText(user.LastName)
+	user.DisplayName = normalizeText(user.DisplayName)
+
+	if user.Email != nil {
+		user.Email = optional(strings.ToLower(normalizeText(*user.Email)))
+	}
+	if user.Username != nil {
+		user.Username = optional(strings.ToLower(normalizeText(*user.Username)))
+	}
+	if user.Phone != nil {
+		user.Phone = optional(normalizePhone(*user.Phone))
+	}
+}
+
+func normalizeText(value string) string {
+	return norm.NFC.String(strings.TrimSpace(value))
+}
+
+// normalizePhone quita los separadores habituales y acepta el prefijo internacional 00
+// en lugar de +
+func normalizePhone(phone string) string {
+	phone = phoneSeparators.Replace(strings.TrimSpace(phone))
+	if rest, ok := strings.CutPrefix(phone, "00"); ok {
+		phone = "+" + rest
+	}
+	return phone
+}
+
+// optional convierte el texto vacío en nil para guardarlo como NULL
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// stringValue devuelve el texto de un dato opcional, vacío si no existe
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
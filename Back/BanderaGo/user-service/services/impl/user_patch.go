@@ -0,0 +1,121 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/models"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// applyPatch aplica un JSON Merge Patch (RFC 7386) o un JSON Patch (RFC 6902) sobre doc.
+// Una operación test fallida es un conflicto con el estado actual del recurso; cualquier
+// otro documento que no pueda aplicarse es un error de validación.
+func applyPatch(patchType string, doc, patch []byte) ([]byte, error) {
+	switch patchType {
+	case input.MergePatchType:
+		patched, err := jsonpatch.MergePatch(doc, patch)
+		if err != nil {
+			return nil, apperrors.Validation("", err)
+		}
+		return patched, nil
+	case input.JSONPatchType:
+		operations, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, apperrors.Validation("", err)
+		}
+		patched, err := operations.Apply(doc)
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			return nil, apperrors.Conflict("", err)
+		}
+		if err != nil {
+			return nil, apperrors.Validation("", err)
+		}
+		return patched, nil
+	}
+	return nil, apperrors.Validation("", fmt.Errorf("tipo de documento no soportado: %s", patchType))
+}
+
+// decodePatchedUser lee el documento resultante y lo valida con las mismas reglas que PUT
+func decodePatchedUser(patched []byte) (input.UpdateUserIn, error) {
+	var userIn input.UpdateUserIn
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&userIn); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return userIn, apperrors.Validation(typeErr.Field, err)
+		}
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return userIn, apperrors.Validation(strings.Trim(field, `"`), err)
+		}
+		return userIn, apperrors.Validation("", err)
+	}
+
+	if err := binding.Validator.ValidateStruct(&userIn); err != nil {
+		return userIn, apperrors.Validation("", err)
+	}
+	return userIn, nil
+}
+
+// userDocument es el documento JSON del usuario sobre el que se aplican los cambios
+func userDocument(user *models.User) input.UpdateUserIn {
+	return input.UpdateUserIn{
+		Name:        user.Name,
+		LastName:    user.LastName,
+		Email:       stringValue(user.Email),
+		Username:    stringValue(user.Username),
+		Phone:       stringValue(user.Phone),
+		DisplayName: user.DisplayName,
+	}
+}
+
+// updatedUser devuelve una copia normalizada y validada del usuario con los datos de userIn
+func updatedUser(user *models.User, userIn input.UpdateUserIn) (*models.User, error) {
+	updated := *user
+	updated.Name = userIn.Name
+	updated.LastName = userIn.LastName
+	updated.Email = optional(userIn.Email)
+	updated.Username = optional(userIn.Username)
+	updated.Phone = optional(userIn.Phone)
+	updated.DisplayName = userIn.DisplayName
+	if err := prepareUser(&updated); err != nil {
+		return nil, err
+	}
+	// La verificación corresponde al correo anterior
+	if stringValue(updated.Email) != stringValue(user.Email) {
+		updated.EmailVerifiedAt = nil
+	}
+	return &updated, nil
+}
+
+// changedColumns devuelve las columnas cuyo valor cambia entre user y updated
+func changedColumns(user, updated *models.User) []string {
+	var columns []string
+	if user.Name != updated.Name {
+		columns = append(columns, "name")
+	}
+	if user.LastName != updated.LastName {
+		columns = append(columns, "last_name")
+	}
+	if stringValue(user.Email) != stringValue(updated.Email) {
+		columns = append(columns, "email", "email_verified_at")
+	}
+	if stringValue(user.Username) != stringValue(updated.Username) {
+		columns = append(columns, "username")
+	}
+	if stringValue(user.Phone) != stringValue(updated.Phone) {
+		columns = append(columns, "phone")
+	}
+	if user.DisplayName != updated.DisplayName {
+		columns = append(columns, "display_name")
+	}
+	return columns
+}
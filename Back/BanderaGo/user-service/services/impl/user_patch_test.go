@@ -0,0 +1,138 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/dtos/input"
+	"application/models"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	doc := []byte(`{"name":"John","last_name":"Doe"}`)
+
+	// Caso de prueba: null elimina el campo y los demás se conservan
+	patched, err := applyPatch(input.MergePatchType, doc, []byte(`{"last_name":null}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name":"John"}`, string(patched))
+
+	// Caso de prueba: un documento que no es objeto reemplaza al usuario completo
+	patched, err = applyPatch(input.MergePatchType, doc, []byte(`"John"`))
+	assert.NoError(t, err)
+	_, err = decodePatchedUser(patched)
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	doc := []byte(`{"name":"John","last_name":"Doe"}`)
+
+	// Caso de prueba: las operaciones se aplican en orden
+	patched, err := applyPatch(input.JSONPatchType, doc, []byte(`[{"op":"move","from":"/name","path":"/last_name"},{"op":"add","path":"/name","value":"Jane"}]`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name":"Jane","last_name":"John"}`, string(patched))
+
+	// Caso de prueba: una operación desconocida no puede aplicarse
+	_, err = applyPatch(input.JSONPatchType, doc, []byte(`[{"op":"rename","path":"/name"}]`))
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+}
+
+func TestDecodePatchedUser(t *testing.T) {
+	// Caso de prueba: documento válido
+	userIn, err := decodePatchedUser([]byte(`{"name":"John","last_name":"Doe"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, input.UpdateUserIn{Name: "John", LastName: "Doe"}, userIn)
+
+	// Caso de prueba: campo de solo lectura o desconocido
+	_, err = decodePatchedUser([]byte(`{"name":"John","last_name":"Doe","created_at":"2024-01-01"}`))
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+	assert.Equal(t, "created_at", apperrors.FieldOf(err))
+}
+
+func TestChangedColumns(t *testing.T) {
+	user := &models.User{Name: "John", LastName: "Doe", Email: optional("john@example.com")}
+
+	changed := func(userIn input.UpdateUserIn) []string {
+		updated, err := updatedUser(user, userIn)
+		assert.NoError(t, err)
+		return changedColumns(user, updated)
+	}
+	assert.Empty(t, changed(input.UpdateUserIn{Name: "John", LastName: "Doe", Email: "john@example.com"}))
+	assert.Equal(t, []string{"name"}, changed(input.UpdateUserIn{Name: "Jane", LastName: "Doe", Email: "john@example.com"}))
+	assert.Equal(t, []string{"name", "last_name"}, changed(input.UpdateUserIn{Name: "Jane", LastName: "Smith", Email: "john@example.com"}))
+
+	// Caso de prueba: un valor que solo difiere en su escritura no es un cambio
+	assert.Empty(t, changed(input.UpdateUserIn{Name: " John", LastName: "Doe", Email: "John@Example.com"}))
+	// Caso de prueba: cambiar el correo también reinicia su verificación
+	assert.Equal(t, []string{"email", "email_verified_at", "phone", "display_name"}, changed(input.UpdateUserIn{Name: "John", LastName: "Doe", Phone: "+52 55 1234 5678", DisplayName: "JD"}))
+}
+
+// Caso de prueba: formas canónicas de cada dato
+func TestNormalizeUser(t *testing.T) {
+	user := &models.User{
+		Name:     "Jose\u0301 ",
+		LastName: " Pe\u0301rez",
+		Email:    optional(" Ana.Diaz@Example.COM "),
+		Username: optional("Ana_Diaz"),
+		Phone:    optional("0052 (55) 1234-5678"),
+	}
+
+	normalizeUser(user)
+	assert.Equal(t, "José", user.Name)
+	assert.Equal(t, "Pérez", user.LastName)
+	assert.Equal(t, "ana.diaz@example.com", *user.Email)
+	assert.Equal(t, "ana_diaz", *user.Username)
+	assert.Equal(t, "+525512345678", *user.Phone)
+
+	// Caso de prueba: un dato vacío se guarda como NULL
+	user = &models.User{Phone: optional(" "), Email: optional("")}
+	normalizeUser(user)
+	assert.Nil(t, user.Phone)
+	assert.Nil(t, user.Email)
+}
+
+// Caso de prueba: datos válidos, incluidos nombres con acentos, apóstrofos y guiones
+func TestValidateUserValid(t *testing.T) {
+	users := []models.User{
+		{Name: "José María", LastName: "Núñez-Ibáñez"},
+		{Name: "Seán", LastName: "O'Connor Jr.", DisplayName: "Seán 🚀", Email: optional("sean@example.ie"), Username: optional("sean.o_c-1"), Phone: optional("+353861234567")},
+		{Name: "Jose\u0301", LastName: "Müller"},
+	}
+	for _, user := range users {
+		assert.NoError(t, prepareUser(&user), user.Name)
+	}
+}
+
+// Caso de prueba: todas las reglas incumplidas se reportan juntas, por campo
+func TestValidateUserViolations(t *testing.T) {
+	user := &models.User{
+		Name:        "   ",
+		LastName:    strings.Repeat("a", maxNameLength+1) + "1",
+		Email:       optional("Ana <ana@example.com>"),
+		Username:    optional("a b"),
+		Phone:       optional("55 1234 5678"),
+		DisplayName: "Ana\x00",
+	}
+
+	err := prepareUser(user)
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+	assert.Equal(t, apperrors.Violations{
+		{Field: "name", Rule: "required"},
+		{Field: "last_name", Rule: "max", Param: "100"},
+		{Field: "last_name", Rule: "name"},
+		{Field: "email", Rule: "email"},
+		{Field: "username", Rule: "username"},
+		{Field: "phone", Rule: "phone"},
+		{Field: "display_name", Rule: "printable"},
+	}, apperrors.ViolationsOf(err))
+
+	// Caso de prueba: la longitud se cuenta en caracteres y no en bytes
+	assert.NoError(t, prepareUser(&models.User{Name: strings.Repeat("ñ", maxNameLength), LastName: "Díaz"}))
+	err = prepareUser(&models.User{Name: "Ana", LastName: "Díaz\nRuiz", Username: optional("ab"), Email: optional("ana@localhost")})
+	assert.Equal(t, apperrors.Violations{
+		{Field: "last_name", Rule: "name"},
+		{Field: "email", Rule: "email"},
+		{Field: "username", Rule: "min", Param: "3"},
+	}, apperrors.ViolationsOf(err))
+}
@@ -0,0 +1,57 @@
+package impl
+
+import (
+	"application/persistence/repositories"
+	"fmt"
+	"strings"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// sortableColumns relaciona los campos que aceptan los clientes en sort con su columna
+var sortableColumns = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"last_name":  "last_name",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// parseSort interpreta una lista como "last_name,-created_at" (el prefijo '-' indica
+// orden descendente). Siempre termina en id para que el orden sea total, requisito de
+// la paginación por cursor. También devuelve la forma normalizada del ordenamiento.
+func parseSort(sort string) ([]repositories.SortField, string, error) {
+	var fields []repositories.SortField
+	var keys []string
+	seen := map[string]bool{}
+
+	for _, part := range strings.Split(sort, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		desc := strings.HasPrefix(part, "-")
+		name := strings.TrimPrefix(part, "-")
+
+		column, ok := sortableColumns[name]
+		if !ok {
+			return nil, "", fmt.Errorf("campo de ordenamiento no permitido '%s'", name)
+		}
+		if seen[column] {
+			return nil, "", fmt.Errorf("campo de ordenamiento repetido '%s'", name)
+		}
+		seen[column] = true
+
+		fields = append(fields, repositories.SortField{Column: column, Desc: desc})
+		keys = append(keys, part)
+	}
+
+	if !seen["id"] {
+		fields = append(fields, repositories.SortField{Column: "id"})
+		keys = append(keys, "id")
+	}
+	return fields, strings.Join(keys, ","), nil
+}
@@ -0,0 +1,48 @@
+package impl
+
+import (
+	"application/persistence/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Caso de prueba: ordenamiento por defecto
+func TestParseSortDefault(t *testing.T) {
+	fields, key, err := parseSort("")
+
+	require.NoError(t, err)
+	assert.Equal(t, []repositories.SortField{{Column: "id"}}, fields)
+	assert.Equal(t, "id", key)
+}
+
+// Caso de prueba: varios campos con dirección
+func TestParseSortMultiple(t *testing.T) {
+	fields, key, err := parseSort("last_name, -created_at")
+
+	require.NoError(t, err)
+	assert.Equal(t, []repositories.SortField{
+		{Column: "last_name"},
+		{Column: "created_at", Desc: true},
+		{Column: "id"},
+	}, fields)
+	assert.Equal(t, "last_name,-created_at,id", key)
+}
+
+// Caso de prueba: id explícito no se duplica
+func TestParseSortExplicitID(t *testing.T) {
+	fields, key, err := parseSort("-id")
+
+	require.NoError(t, err)
+	assert.Equal(t, []repositories.SortField{{Column: "id", Desc: true}}, fields)
+	assert.Equal(t, "-id", key)
+}
+
+// Caso de prueba: campos no permitidos o repetidos
+func TestParseSortInvalid(t *testing.T) {
+	for _, sort := range []string{"password", "name,-name", "name;DROP TABLE users"} {
+		_, _, err := parseSort(sort)
+		assert.Error(t, err, "sort %q", sort)
+	}
+}
@@ -1,92 +1,453 @@
 package impl
 
 import (
+	"application/apperrors"
 	"application/dtos/input"
 	"application/dtos/output"
 	"application/models"
 	"application/persistence/repositories"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+var errVersionMismatch = errors.New("la versión del usuario no coincide con If-Match")
+
 type UserServiceImpl struct {
-	repo repositories.UserRepository
+	repo     repositories.UserRepository
+	versions repositories.UserVersionRepository
+	audit    repositories.AuditRepository
+	tx       repositories.Transactor
 }
 
-func NewUserService(repo repositories.UserRepository) *UserServiceImpl {
-	return &UserServiceImpl{repo: repo}
+func NewUserService(repo repositories.UserRepository, versions repositories.UserVersionRepository, audit repositories.AuditRepository, tx repositories.Transactor) *UserServiceImpl {
+	return &UserServiceImpl{repo: repo, versions: versions, audit: audit, tx: tx}
 }
 
-func (s *UserServiceImpl) CreateUser(userIn input.CreateUserIn) (output.CreateUserOut, error) {
+func (s *UserServiceImpl) CreateUser(ctx context.Context, userIn input.CreateUserIn) (output.CreateUserOut, error) {
 	user := models.User{
-		Name:     userIn.Name,
-		LastName: userIn.LastName,
+		Name:        userIn.Name,
+		LastName:    userIn.LastName,
+		Email:       optional(userIn.Email),
+		Username:    optional(userIn.Username),
+		Phone:       optional(userIn.Phone),
+		DisplayName: userIn.DisplayName,
+	}
+	if err := prepareUser(&user); err != nil {
+		return output.CreateUserOut{}, err
 	}
-	if err := s.repo.CreateUser(&user); err != nil {
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateUser(ctx, &user); err != nil {
+			return err
+		}
+		if err := s.recordVersion(ctx, &user); err != nil {
+			return err
+		}
+		return s.recordAudit(ctx, models.AuditCreate, user.ID, nil, &user)
+	})
+	if err != nil {
 		return output.CreateUserOut{}, err
 	}
 	userOut := output.CreateUserOut{
-		ID:        user.ID,
-		Name:      user.Name,
-		LastName:  user.LastName,
-		CreatedAt: user.CreatedAt,
+		ID:          user.ID,
+		Name:        user.Name,
+		LastName:    user.LastName,
+		Email:       stringValue(user.Email),
+		Username:    stringValue(user.Username),
+		Phone:       stringValue(user.Phone),
+		DisplayName: user.DisplayName,
+		CreatedAt:   user.CreatedAt,
 	}
 	return userOut, nil
 }
 
-func (s *UserServiceImpl) GetUserByID(id uint) (output.GetUserOut, error) {
-	user, err := s.repo.GetUserByID(id)
+func (s *UserServiceImpl) GetUserByID(ctx context.Context, id uint) (output.GetUserOut, error) {
+	user, err := s.repo.GetUserByID(ctx, id)
 	if err != nil {
 		return output.GetUserOut{}, err
 	}
 	userOut := output.GetUserOut{
-		ID:       user.ID,
-		Name:     user.Name,
-		LastName: user.LastName,
+		ID:            user.ID,
+		Name:          user.Name,
+		LastName:      user.LastName,
+		Email:         stringValue(user.Email),
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Username:      stringValue(user.Username),
+		Phone:         stringValue(user.Phone),
+		DisplayName:   user.DisplayName,
+		Version:       user.Version,
 	}
 	return userOut, nil
 }
 
-func (s *UserServiceImpl) GetAllUsers() ([]output.GetUsersOut, error) {
-	users, err := s.repo.GetAllUsers()
+func (s *UserServiceImpl) GetAllUsers(ctx context.Context, listIn input.ListUsersIn) (output.GetUsersPageOut, error) {
+	sortFields, sortKey, err := parseSort(listIn.Sort)
+	if err != nil {
+		return output.GetUsersPageOut{}, apperrors.Validation("sort", err)
+	}
+
+	pageSize := listIn.PageSize
+	if pageSize == 0 {
+		pageSize = DefaultPageSize
+	}
+
+	query := repositories.UserQuery{
+		Filter: repositories.UserFilter{
+			Name:          listIn.Name,
+			LastName:      listIn.LastName,
+			Email:         strings.ToLower(normalizeText(listIn.Email)),
+			Username:      strings.ToLower(normalizeText(listIn.Username)),
+			Phone:         normalizePhone(listIn.Phone),
+			CreatedAfter:  listIn.CreatedAfter,
+			CreatedBefore: listIn.CreatedBefore,
+			Deleted:       repositories.DeletedScope(listIn.Deleted),
+		},
+		Sort:  sortFields,
+		Limit: pageSize,
+	}
+
+	page := 0
+	if listIn.Cursor != "" {
+		if listIn.Page != 0 {
+			return output.GetUsersPageOut{}, apperrors.Validation("page", errors.New("page y cursor no pueden usarse juntos"))
+		}
+		cursor, err := repositories.DecodeCursor(listIn.Cursor)
+		if err != nil {
+			return output.GetUsersPageOut{}, apperrors.Validation("cursor", err)
+		}
+		if cursor.Sort != sortKey {
+			return output.GetUsersPageOut{}, apperrors.Validation("cursor", errors.New("el cursor fue emitido con otro ordenamiento"))
+		}
+		query.Cursor = cursor
+	} else {
+		page = max(listIn.Page, 1)
+		query.Offset = (page - 1) * pageSize
+	}
+
+	result, err := s.repo.GetAllUsers(ctx, query)
 	if err != nil {
-		return nil, err
+		return output.GetUsersPageOut{}, err
 	}
-	var usersOut []output.GetUsersOut
-	for _, user := range users {
+
+	pageOut := output.GetUsersPageOut{
+		Data:     make([]output.GetUsersOut, 0, len(result.Users)),
+		Total:    result.Total,
+		Page:     page,
+		PageSize: pageSize,
+	}
+	for _, user := range result.Users {
 		userOut := output.GetUsersOut{
-			ID:       user.ID,
-			Name:     user.Name,
-			LastName: user.LastName,
+			ID:          user.ID,
+			Name:        user.Name,
+			LastName:    user.LastName,
+			Email:       stringValue(user.Email),
+			Username:    stringValue(user.Username),
+			Phone:       stringValue(user.Phone),
+			DisplayName: user.DisplayName,
+		}
+		if user.DeletedAt.Valid {
+			userOut.DeletedAt = &user.DeletedAt.Time
+		}
+		pageOut.Data = append(pageOut.Data, userOut)
+	}
+
+	if len(result.Users) > 0 {
+		first, last := result.Users[0], result.Users[len(result.Users)-1]
+		backward := query.Cursor != nil && query.Cursor.Backward
+		if backward || result.HasMore {
+			pageOut.NextCursor = repositories.NewCursor(last, sortKey, false).Encode()
+		}
+		if (backward && result.HasMore) || (!backward && (query.Cursor != nil || query.Offset > 0)) {
+			pageOut.PrevCursor = repositories.NewCursor(first, sortKey, true).Encode()
 		}
-		usersOut = append(usersOut, userOut)
 	}
-	return usersOut, nil
+	return pageOut, nil
 }
 
-func (s *UserServiceImpl) UpdateUser(id uint, userIn input.UpdateUserIn) (output.UpdateUserOut, error) {
-	user, err := s.repo.GetUserByID(id)
+func (s *UserServiceImpl) UpdateUser(ctx context.Context, id uint, userIn input.UpdateUserIn) (output.UpdateUserOut, error) {
+	if err := authorizeSelf(ctx, id, models.PermissionUsersUpdate); err != nil {
+		return output.UpdateUserOut{}, err
+	}
+
+	var user *models.User
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = s.repo.GetUserByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !userIn.IfMatch.Matches(user.Version) {
+			return apperrors.PreconditionFailed(errVersionMismatch)
+		}
+
+		updated, err := updatedUser(user, userIn)
+		if err != nil {
+			return err
+		}
+		before := *user
+		*user = *updated
+
+		if err := s.repo.UpdateUser(ctx, id, user); err != nil {
+			return err
+		}
+		if err := s.recordVersion(ctx, user); err != nil {
+			return err
+		}
+		return s.recordAudit(ctx, models.AuditUpdate, id, &before, user)
+	})
 	if err != nil {
 		return output.UpdateUserOut{}, err
 	}
 
-	user.Name = userIn.Name
-	user.LastName = userIn.LastName
+	userOut := output.UpdateUserOut{
+		ID:          user.ID,
+		Name:        user.Name,
+		LastName:    user.LastName,
+		Email:       stringValue(user.Email),
+		Username:    stringValue(user.Username),
+		Phone:       stringValue(user.Phone),
+		DisplayName: user.DisplayName,
+		UpdatedAt:   user.UpdatedAt,
+		Version:     user.Version,
+	}
+	return userOut, nil
+}
+
+// PatchUser aplica el documento de cambios sobre el usuario almacenado y escribe solo
+// las columnas que cambian
+func (s *UserServiceImpl) PatchUser(ctx context.Context, id uint, patchIn input.PatchUserIn) (output.UpdateUserOut, error) {
+	if err := authorizeSelf(ctx, id, models.PermissionUsersUpdate); err != nil {
+		return output.UpdateUserOut{}, err
+	}
+
+	var user *models.User
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = s.repo.GetUserByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !patchIn.IfMatch.Matches(user.Version) {
+			return apperrors.PreconditionFailed(errVersionMismatch)
+		}
 
-	if err := s.repo.UpdateUser(id, user); err != nil {
+		doc, err := json.Marshal(userDocument(user))
+		if err != nil {
+			return err
+		}
+		patched, err := applyPatch(patchIn.Type, doc, patchIn.Patch)
+		if err != nil {
+			return err
+		}
+		userIn, err := decodePatchedUser(patched)
+		if err != nil {
+			return err
+		}
+
+		updated, err := updatedUser(user, userIn)
+		if err != nil {
+			return err
+		}
+		columns := changedColumns(user, updated)
+		if len(columns) == 0 {
+			return nil
+		}
+		before := *user
+		*user = *updated
+		if err := s.repo.PatchUser(ctx, user, columns); err != nil {
+			return err
+		}
+		if err := s.recordVersion(ctx, user); err != nil {
+			return err
+		}
+		return s.recordAudit(ctx, models.AuditUpdate, id, &before, user)
+	})
+	if err != nil {
 		return output.UpdateUserOut{}, err
 	}
 
 	userOut := output.UpdateUserOut{
-		ID:        user.ID,
-		Name:      user.Name,
-		LastName:  user.LastName,
-		UpdatedAt: user.UpdatedAt,
+		ID:          user.ID,
+		Name:        user.Name,
+		LastName:    user.LastName,
+		Email:       stringValue(user.Email),
+		Username:    stringValue(user.Username),
+		Phone:       stringValue(user.Phone),
+		DisplayName: user.DisplayName,
+		UpdatedAt:   user.UpdatedAt,
+		Version:     user.Version,
+	}
+	return userOut, nil
+}
+
+func (s *UserServiceImpl) DeleteUser(ctx context.Context, id uint) (output.DeleteUserOut, error) {
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.repo.GetUserByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.DeleteUser(ctx, id); err != nil {
+			return err
+		}
+		// La eliminación es una versión más, para que su instantánea tenga número propio
+		deleted := *user
+		deleted.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		deleted.Version++
+		if err := s.recordVersion(ctx, &deleted); err != nil {
+			return err
+		}
+		return s.recordAudit(ctx, models.AuditDelete, id, user, &deleted)
+	})
+	if err != nil {
+		return output.DeleteUserOut{Success: false}, err
+	}
+	return output.DeleteUserOut{Success: true}, nil
+}
+
+// RestoreUser deshace el borrado lógico; si el usuario no estaba eliminado lo devuelve
+// sin cambios y sin registrar auditoría
+func (s *UserServiceImpl) RestoreUser(ctx context.Context, id uint) (output.GetUserOut, error) {
+	var user *models.User
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.repo.GetUserByIDUnscoped(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !before.DeletedAt.Valid {
+			user = before
+			return nil
+		}
+		user, err = s.repo.RestoreUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.recordVersion(ctx, user); err != nil {
+			return err
+		}
+		return s.recordAudit(ctx, models.AuditRestore, id, before, user)
+	})
+	if err != nil {
+		return output.GetUserOut{}, err
+	}
+
+	userOut := output.GetUserOut{
+		ID:            user.ID,
+		Name:          user.Name,
+		LastName:      user.LastName,
+		Email:         stringValue(user.Email),
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Username:      stringValue(user.Username),
+		Phone:         stringValue(user.Phone),
+		DisplayName:   user.DisplayName,
+		Version:       user.Version,
 	}
 	return userOut, nil
 }
 
-func (s *UserServiceImpl) DeleteUser(id uint) (output.DeleteUserOut, error) {
-	if err := s.repo.DeleteUser(id); err != nil {
+func (s *UserServiceImpl) PurgeUser(ctx context.Context, id uint) (output.DeleteUserOut, error) {
+	if err := authorizePermission(ctx, models.PermissionUsersPurge); err != nil {
+		return output.DeleteUserOut{Success: false}, err
+	}
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.repo.GetUserByIDUnscoped(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.PurgeUser(ctx, id); err != nil {
+			return err
+		}
+		// Un usuario activo deja de existir desde ahora; uno eliminado ya tiene su instantánea
+		if !user.DeletedAt.Valid {
+			purged := *user
+			purged.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+			purged.Version++
+			if err := s.recordVersion(ctx, &purged); err != nil {
+				return err
+			}
+		}
+		return s.recordAudit(ctx, models.AuditPurge, id, user, nil)
+	})
+	if err != nil {
 		return output.DeleteUserOut{Success: false}, err
 	}
 	return output.DeleteUserOut{Success: true}, nil
 }
+
+// PurgeDeletedUsers elimina definitivamente los usuarios eliminados lógicamente antes de
+// la fecha indicada y registra cada purga en la auditoría, en la misma transacción
+func (s *UserServiceImpl) PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		users, err := s.repo.PurgeDeletedUsers(ctx, before)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			if err := s.recordAudit(ctx, models.AuditPurge, user.ID, user, nil); err != nil {
+				return err
+			}
+		}
+		purged = int64(len(users))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+func (s *UserServiceImpl) GetUserHistory(ctx context.Context, id uint, listIn input.ListAuditIn) (output.GetAuditPageOut, error) {
+	return s.listAudit(ctx, id, listIn)
+}
+
+func (s *UserServiceImpl) GetAuditRecords(ctx context.Context, listIn input.ListAuditIn) (output.GetAuditPageOut, error) {
+	return s.listAudit(ctx, 0, listIn)
+}
+
+func (s *UserServiceImpl) listAudit(ctx context.Context, userID uint, listIn input.ListAuditIn) (output.GetAuditPageOut, error) {
+	pageSize := listIn.PageSize
+	if pageSize == 0 {
+		pageSize = DefaultPageSize
+	}
+	page := max(listIn.Page, 1)
+
+	result, err := s.audit.GetAuditRecords(ctx, repositories.AuditQuery{
+		UserID:    userID,
+		Actor:     listIn.Actor,
+		Operation: listIn.Operation,
+		From:      listIn.From,
+		To:        listIn.To,
+		Limit:     pageSize,
+		Offset:    (page - 1) * pageSize,
+	})
+	if err != nil {
+		return output.GetAuditPageOut{}, err
+	}
+
+	pageOut := output.GetAuditPageOut{
+		Data:     make([]output.AuditRecordOut, 0, len(result.Records)),
+		Total:    result.Total,
+		Page:     page,
+		PageSize: pageSize,
+	}
+	for _, record := range result.Records {
+		recordOut := output.AuditRecordOut{
+			ID:        record.ID,
+			UserID:    record.UserID,
+			Actor:     record.Actor,
+			RequestID: record.RequestID,
+			Operation: record.Operation,
+			Changes:   make(map[string]output.FieldChangeOut, len(record.Changes)),
+			CreatedAt: record.CreatedAt,
+		}
+		for field, change := range record.Changes {
+			recordOut.Changes[field] = output.FieldChangeOut{Before: change.Before, After: change.After}
+		}
+		pageOut.Data = append(pageOut.Data, recordOut)
+	}
+	return pageOut, nil
+}
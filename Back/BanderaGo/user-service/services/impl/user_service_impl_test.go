@@ -1,13 +1,21 @@
 package impl
 
 import (
+	"application/apperrors"
 	"application/dtos/input"
+	"application/dtos/output"
 	"application/models"
+	"application/persistence/repositories"
+	repoImpl "application/persistence/repositories/impl"
+	"application/requestctx"
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
@@ -17,39 +25,91 @@ type MockUserRepository struct {
 }
 
 // Implementación de CreateUser para el mock
-func (m *MockUserRepository) CreateUser(user *models.User) error {
+func (m *MockUserRepository) CreateUser(ctx context.Context, user *models.User) error {
 	args := m.Called(user)
 	return args.Error(0)
 }
 
 // Implementación de GetUserByID para el mock
-func (m *MockUserRepository) GetUserByID(id uint) (*models.User, error) {
+func (m *MockUserRepository) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
 	args := m.Called(id)
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+// Implementación de GetUserByIDUnscoped para el mock
+func (m *MockUserRepository) GetUserByIDUnscoped(ctx context.Context, id uint) (*models.User, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+// Implementación de GetUserByLogin para el mock
+func (m *MockUserRepository) GetUserByLogin(ctx context.Context, login string) (*models.User, error) {
+	args := m.Called(login)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
 // Implementación de GetAllUsers para el mock
-func (m *MockUserRepository) GetAllUsers() ([]*models.User, error) {
-	args := m.Called()
-	return args.Get(0).([]*models.User), args.Error(1)
+func (m *MockUserRepository) GetAllUsers(ctx context.Context, query repositories.UserQuery) (*repositories.UserPage, error) {
+	args := m.Called(query)
+	return args.Get(0).(*repositories.UserPage), args.Error(1)
 }
 
 // Implementación de UpdateUser para el mock
-func (m *MockUserRepository) UpdateUser(id uint, user *models.User) error {
+func (m *MockUserRepository) UpdateUser(ctx context.Context, id uint, user *models.User) error {
 	args := m.Called(id, user)
 	return args.Error(0)
 }
 
+// Implementación de PatchUser para el mock
+func (m *MockUserRepository) PatchUser(ctx context.Context, user *models.User, columns []string) error {
+	args := m.Called(user, columns)
+	return args.Error(0)
+}
+
+// Implementación de UpdatePassword para el mock
+func (m *MockUserRepository) VerifyEmail(ctx context.Context, id uint, email string, at time.Time) error {
+	return m.Called(id, email).Error(0)
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
 // Implementación de DeleteUser para el mock
-func (m *MockUserRepository) DeleteUser(id uint) error {
+func (m *MockUserRepository) DeleteUser(ctx context.Context, id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
+// Implementación de RestoreUser para el mock
+func (m *MockUserRepository) RestoreUser(ctx context.Context, id uint) (*models.User, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+// Implementación de PurgeUser para el mock
+func (m *MockUserRepository) PurgeUser(ctx context.Context, id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// Implementación de PurgeDeletedUsers para el mock
+func (m *MockUserRepository) PurgeDeletedUsers(ctx context.Context, before time.Time) ([]*models.User, error) {
+	args := m.Called(before)
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+// newTestService crea el servicio con auditoría y transacciones en memoria
+func newTestService(repo repositories.UserRepository) *UserServiceImpl {
+	return NewUserService(repo, repoImpl.NewMemoryUserVersionRepository(), repoImpl.NewMemoryAuditRepository(), repoImpl.NewMemoryTransactor())
+}
+
 // Test para CreateUser en UserServiceImpl
 func TestCreateUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := NewUserService(mockRepo)
+	service := newTestService(mockRepo)
 
 	userIn := input.CreateUserIn{
 		Name:     "John",
@@ -63,7 +123,7 @@ func TestCreateUser(t *testing.T) {
 	})
 
 	// Ejecutar el método CreateUser del servicio
-	userOut, err := service.CreateUser(userIn)
+	userOut, err := service.CreateUser(context.Background(), userIn)
 
 	// Verificar que no se produzca un error
 	assert.NoError(t, err)
@@ -76,7 +136,7 @@ func TestCreateUser(t *testing.T) {
 // Test para GetUserByID en UserServiceImpl
 func TestGetUserByID(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := NewUserService(mockRepo)
+	service := newTestService(mockRepo)
 
 	userID := uint(1)
 	user := &models.User{Model: gorm.Model{ID: 1}, Name: "John", LastName: "Doe"}
@@ -85,7 +145,7 @@ func TestGetUserByID(t *testing.T) {
 	mockRepo.On("GetUserByID", userID).Return(user, nil)
 
 	// Ejecutar el método GetUserByID del servicio
-	userOut, err := service.GetUserByID(userID)
+	userOut, err := service.GetUserByID(context.Background(), userID)
 
 	// Verificar que no se produzca un error
 	assert.NoError(t, err)
@@ -100,31 +160,147 @@ func TestGetUserByID(t *testing.T) {
 // Test para GetAllUsers en UserServiceImpl
 func TestGetAllUsers(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := NewUserService(mockRepo)
+	service := newTestService(mockRepo)
 
 	users := []*models.User{
 		{Model: gorm.Model{ID: 1}, Name: "John", LastName: "Doe"},
 		{Model: gorm.Model{ID: 2}, Name: "Jane", LastName: "Smith"},
 	}
 
-	// Configurar el comportamiento esperado del mock
-	mockRepo.On("GetAllUsers").Return(users, nil)
+	// Configurar el comportamiento esperado del mock con la consulta por defecto
+	expectedQuery := repositories.UserQuery{Sort: []repositories.SortField{{Column: "id"}}, Limit: DefaultPageSize}
+	mockRepo.On("GetAllUsers", expectedQuery).Return(&repositories.UserPage{Users: users, Total: 2}, nil)
 
 	// Ejecutar el método GetAllUsers del servicio
-	usersOut, err := service.GetAllUsers()
+	pageOut, err := service.GetAllUsers(context.Background(), input.ListUsersIn{})
 
 	// Verificar que no se produzca un error
 	assert.NoError(t, err)
 	// Verificar que la cantidad de usuarios de salida sea la misma que la cantidad de usuarios simulados
-	assert.Len(t, usersOut, len(users))
+	assert.Len(t, pageOut.Data, len(users))
+	assert.Equal(t, int64(2), pageOut.Total)
+	assert.Equal(t, 1, pageOut.Page)
+	assert.Equal(t, DefaultPageSize, pageOut.PageSize)
+	// Verificar que no existan cursores en la única página
+	assert.Empty(t, pageOut.NextCursor)
+	assert.Empty(t, pageOut.PrevCursor)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// Caso de prueba: los filtros exactos se normalizan como se guardan los datos
+func TestGetAllUsersExactFilters(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := newTestService(mockRepo)
+
+	expectedQuery := repositories.UserQuery{
+		Filter: repositories.UserFilter{Email: "ana@example.com", Username: "ana.diaz", Phone: "+5215512345678"},
+		Sort:   []repositories.SortField{{Column: "id"}},
+		Limit:  DefaultPageSize,
+	}
+	mockRepo.On("GetAllUsers", expectedQuery).Return(&repositories.UserPage{}, nil)
+
+	listIn := input.ListUsersIn{Email: " Ana@Example.com ", Username: "ANA.DIAZ", Phone: "0052 (155) 1234-5678"}
+	_, err := service.GetAllUsers(context.Background(), listIn)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+// Test para GetAllUsers con número de página, filtros y ordenamiento
+func TestGetAllUsersPage(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := newTestService(mockRepo)
+
+	users := []*models.User{
+		{Model: gorm.Model{ID: 4}, Name: "John", LastName: "Doe"},
+		{Model: gorm.Model{ID: 5}, Name: "Jane", LastName: "Doe"},
+	}
+	createdAfter := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+
+	// Configurar el comportamiento esperado del mock
+	expectedQuery := repositories.UserQuery{
+		Filter: repositories.UserFilter{LastName: "Do", CreatedAfter: &createdAfter},
+		Sort:   []repositories.SortField{{Column: "last_name"}, {Column: "created_at", Desc: true}, {Column: "id"}},
+		Limit:  2,
+		Offset: 4,
+	}
+	mockRepo.On("GetAllUsers", expectedQuery).Return(&repositories.UserPage{Users: users, Total: 9, HasMore: true}, nil)
+
+	// Ejecutar el método GetAllUsers del servicio
+	listIn := input.ListUsersIn{Page: 3, PageSize: 2, Sort: "last_name,-created_at", LastName: "Do", CreatedAfter: &createdAfter}
+	pageOut, err := service.GetAllUsers(context.Background(), listIn)
+
+	// Verificar los cursores emitidos para continuar desde los extremos de la página
+	assert.NoError(t, err)
+	assert.Equal(t, 3, pageOut.Page)
+	next, err := repositories.DecodeCursor(pageOut.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(5), next.ID)
+	assert.False(t, next.Backward)
+	assert.Equal(t, "last_name,-created_at,id", next.Sort)
+	prev, err := repositories.DecodeCursor(pageOut.PrevCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(4), prev.ID)
+	assert.True(t, prev.Backward)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// Test para GetAllUsers recorriendo hacia atrás con un cursor
+func TestGetAllUsersBackwardCursor(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := newTestService(mockRepo)
+
+	users := []*models.User{{Model: gorm.Model{ID: 1}}, {Model: gorm.Model{ID: 2}}}
+	cursor := &repositories.Cursor{Sort: "id", ID: 3, Backward: true}
+
+	// Configurar el comportamiento esperado del mock: no hay más registros antes
+	mockRepo.On("GetAllUsers", mock.MatchedBy(func(query repositories.UserQuery) bool {
+		return query.Cursor != nil && query.Cursor.ID == 3 && query.Cursor.Backward && query.Offset == 0
+	})).Return(&repositories.UserPage{Users: users, Total: 5}, nil)
+
+	// Ejecutar el método GetAllUsers del servicio
+	pageOut, err := service.GetAllUsers(context.Background(), input.ListUsersIn{Cursor: cursor.Encode()})
+
+	// Verificar que exista la página siguiente pero no la anterior
+	assert.NoError(t, err)
+	assert.Equal(t, 0, pageOut.Page)
+	assert.NotEmpty(t, pageOut.NextCursor)
+	assert.Empty(t, pageOut.PrevCursor)
 
 	mockRepo.AssertExpectations(t)
 }
 
+// Test para GetAllUsers con parámetros inválidos
+func TestGetAllUsersInvalidParameters(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := newTestService(mockRepo)
+
+	otherSort := (&repositories.Cursor{Sort: "name,id", ID: 3}).Encode()
+	cases := map[string]input.ListUsersIn{
+		"sort":   {Sort: "password"},
+		"cursor": {Cursor: "not-a-cursor"},
+		"page":   {Page: 2, Cursor: otherSort},
+	}
+	for field, listIn := range cases {
+		_, err := service.GetAllUsers(context.Background(), listIn)
+		assert.ErrorIs(t, err, apperrors.ErrValidation)
+		assert.Equal(t, field, apperrors.FieldOf(err))
+	}
+
+	// El cursor emitido con otro ordenamiento se rechaza
+	_, err := service.GetAllUsers(context.Background(), input.ListUsersIn{Cursor: otherSort})
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+	assert.Equal(t, "cursor", apperrors.FieldOf(err))
+
+	mockRepo.AssertNotCalled(t, "GetAllUsers", mock.Anything)
+}
+
 // Test para UpdateUser en UserServiceImpl
 func TestUpdateUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := NewUserService(mockRepo)
+	service := newTestService(mockRepo)
 
 	userID := uint(1)
 	userIn := input.UpdateUserIn{Name: "John Updated", LastName: "Doe Updated"}
@@ -135,7 +311,7 @@ func TestUpdateUser(t *testing.T) {
 	mockRepo.On("UpdateUser", userID, mock.AnythingOfType("*models.User")).Return(nil)
 
 	// Ejecutar el método UpdateUser del servicio
-	userOut, err := service.UpdateUser(userID, userIn)
+	userOut, err := service.UpdateUser(context.Background(), userID, userIn)
 
 	// Verificar que no se produzca un error
 	assert.NoError(t, err)
@@ -146,18 +322,133 @@ func TestUpdateUser(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+// Test para PatchUser con JSON Merge Patch en UserServiceImpl
+func TestPatchUserMergePatch(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := newTestService(mockRepo)
+
+	user := &models.User{Model: gorm.Model{ID: 1}, Name: "John", LastName: "Doe"}
+
+	// Configurar el comportamiento esperado del mock: solo cambia el apellido
+	mockRepo.On("GetUserByID", uint(1)).Return(user, nil)
+	mockRepo.On("PatchUser", user, []string{"last_name"}).Return(nil)
+
+	// Ejecutar el método PatchUser del servicio
+	patchIn := input.PatchUserIn{Type: input.MergePatchType, Patch: []byte(`{"last_name":"Smith"}`)}
+	userOut, err := service.PatchUser(context.Background(), 1, patchIn)
+
+	// Verificar que se conserve el nombre y cambie el apellido
+	assert.NoError(t, err)
+	assert.Equal(t, "John", userOut.Name)
+	assert.Equal(t, "Smith", userOut.LastName)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// Test para PatchUser con JSON Patch en UserServiceImpl
+func TestPatchUserJSONPatch(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := newTestService(mockRepo)
+
+	user := &models.User{Model: gorm.Model{ID: 1}, Name: "John", LastName: "Doe"}
+
+	// Configurar el comportamiento esperado del mock
+	mockRepo.On("GetUserByID", uint(1)).Return(user, nil)
+	mockRepo.On("PatchUser", user, []string{"name", "last_name"}).Return(nil)
+
+	// Ejecutar el método PatchUser del servicio
+	patch := `[
+		{"op":"test","path":"/name","value":"John"},
+		{"op":"copy","from":"/name","path":"/last_name"},
+		{"op":"replace","path":"/name","value":"Johnny"}
+	]`
+	userOut, err := service.PatchUser(context.Background(), 1, input.PatchUserIn{Type: input.JSONPatchType, Patch: []byte(patch)})
+
+	// Verificar que se apliquen las operaciones en orden
+	assert.NoError(t, err)
+	assert.Equal(t, "Johnny", userOut.Name)
+	assert.Equal(t, "John", userOut.LastName)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// Test para PatchUser sin cambios en UserServiceImpl
+func TestPatchUserNoChanges(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := newTestService(mockRepo)
+
+	user := &models.User{Model: gorm.Model{ID: 1}, Name: "John", LastName: "Doe"}
+	mockRepo.On("GetUserByID", uint(1)).Return(user, nil)
+
+	// Ejecutar el método PatchUser con los mismos valores almacenados
+	patchIn := input.PatchUserIn{Type: input.MergePatchType, Patch: []byte(`{"name":"John"}`)}
+	_, err := service.PatchUser(context.Background(), 1, patchIn)
+
+	// Verificar que no se escriba en el repositorio
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "PatchUser", mock.Anything, mock.Anything)
+}
+
+// Test para PatchUser con documentos que no pueden aplicarse
+func TestPatchUserInvalidPatch(t *testing.T) {
+	cases := []struct {
+		name    string
+		patchIn input.PatchUserIn
+		kind    error
+		field   string
+	}{
+		{"merge patch elimina un campo obligatorio", input.PatchUserIn{Type: input.MergePatchType, Patch: []byte(`{"name":null}`)}, apperrors.ErrValidation, ""},
+		{"merge patch con campo desconocido", input.PatchUserIn{Type: input.MergePatchType, Patch: []byte(`{"id":7}`)}, apperrors.ErrValidation, "id"},
+		{"merge patch con tipo incorrecto", input.PatchUserIn{Type: input.MergePatchType, Patch: []byte(`{"name":5}`)}, apperrors.ErrValidation, "name"},
+		{"json patch sobre una ruta inexistente", input.PatchUserIn{Type: input.JSONPatchType, Patch: []byte(`[{"op":"replace","path":"/age","value":"a"}]`)}, apperrors.ErrValidation, ""},
+		{"json patch con un correo inválido", input.PatchUserIn{Type: input.JSONPatchType, Patch: []byte(`[{"op":"replace","path":"/email","value":"a"}]`)}, apperrors.ErrValidation, "email"},
+		{"merge patch con un nombre en blanco", input.PatchUserIn{Type: input.MergePatchType, Patch: []byte(`{"name":"  "}`)}, apperrors.ErrValidation, "name"},
+		{"json patch que no es un arreglo", input.PatchUserIn{Type: input.JSONPatchType, Patch: []byte(`{"op":"remove"}`)}, apperrors.ErrValidation, ""},
+		{"json patch con test fallido", input.PatchUserIn{Type: input.JSONPatchType, Patch: []byte(`[{"op":"test","path":"/name","value":"Jane"}]`)}, apperrors.ErrConflict, ""},
+		{"tipo de documento no soportado", input.PatchUserIn{Type: "application/json", Patch: []byte(`{}`)}, apperrors.ErrValidation, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			service := newTestService(mockRepo)
+			mockRepo.On("GetUserByID", uint(1)).Return(&models.User{Model: gorm.Model{ID: 1}, Name: "John", LastName: "Doe"}, nil)
+
+			_, err := service.PatchUser(context.Background(), 1, tc.patchIn)
+
+			assert.ErrorIs(t, err, tc.kind)
+			assert.Equal(t, tc.field, apperrors.FieldOf(err))
+			mockRepo.AssertNotCalled(t, "PatchUser", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// Test para PatchUser cuando el usuario no existe
+func TestPatchUserNotFound(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := newTestService(mockRepo)
+
+	mockRepo.On("GetUserByID", uint(1)).Return((*models.User)(nil), apperrors.NotFound(errors.New("record not found")))
+
+	_, err := userService.PatchUser(context.Background(), 1, input.PatchUserIn{Type: input.MergePatchType, Patch: []byte(`{}`)})
+
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	mockRepo.AssertNotCalled(t, "PatchUser", mock.Anything, mock.Anything)
+}
+
 // Test para DeleteUser en UserServiceImpl
 func TestDeleteUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := NewUserService(mockRepo)
+	service := newTestService(mockRepo)
 
 	userID := uint(1)
 
 	// Configurar el comportamiento esperado del mock
+	mockRepo.On("GetUserByID", userID).Return(&models.User{Name: "John"}, nil)
 	mockRepo.On("DeleteUser", userID).Return(nil)
 
 	// Ejecutar el método DeleteUser del servicio
-	deleteOut, err := service.DeleteUser(userID)
+	deleteOut, err := service.DeleteUser(context.Background(), userID)
 
 	// Verificar que no se produzca un error
 	assert.NoError(t, err)
@@ -173,7 +464,7 @@ func TestCreateUserError(t *testing.T) {
 	mockRepo := &MockUserRepository{}
 
 	// Configurar el servicio con el mock
-	userService := NewUserService(mockRepo)
+	userService := newTestService(mockRepo)
 
 	// Configurar el error que quieres simular
 	expectedErr := errors.New("error creating user")
@@ -182,18 +473,37 @@ func TestCreateUserError(t *testing.T) {
 	mockRepo.On("CreateUser", mock.Anything).Return(expectedErr)
 
 	// Llamar al método CreateUser del servicio
-	_, err := userService.CreateUser(input.CreateUserIn{Name: "John", LastName: "Doe"})
+	_, err := userService.CreateUser(context.Background(), input.CreateUserIn{Name: "John", LastName: "Doe"})
 
 	// Verificar que se haya devuelto el error esperado
 	assert.EqualError(t, err, expectedErr.Error())
 }
 
+// Caso de prueba: los datos de contacto se normalizan antes de guardarse y un teléfono inválido no llega al repositorio
+func TestCreateUserNormalizesContactFields(t *testing.T) {
+	service := newTestService(repoImpl.NewMemoryUserRepository())
+	ctx := context.Background()
+
+	userOut, err := service.CreateUser(ctx, input.CreateUserIn{Name: " John ", LastName: "Doe", Email: "John@Example.COM", Username: "JDoe", Phone: "00 52 (55) 1234-5678"})
+	require.NoError(t, err)
+	assert.Equal(t, "John", userOut.Name)
+	assert.Equal(t, "john@example.com", userOut.Email)
+	assert.Equal(t, "jdoe", userOut.Username)
+	assert.Equal(t, "+525512345678", userOut.Phone)
+
+	mockRepo := &MockUserRepository{}
+	_, err = newTestService(mockRepo).CreateUser(ctx, input.CreateUserIn{Name: "John", LastName: "Doe", Phone: "12345"})
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+	assert.Equal(t, "phone", apperrors.FieldOf(err))
+	mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
+
 func TestGetUserByIDError(t *testing.T) {
 	// Configurar el mock del repositorio
 	mockRepo := &MockUserRepository{}
 
 	// Configurar el servicio con el mock
-	userService := NewUserService(mockRepo)
+	userService := newTestService(mockRepo)
 
 	// Configurar el error que quieres simular
 	expectedErr := errors.New("error getting user by ID")
@@ -202,7 +512,7 @@ func TestGetUserByIDError(t *testing.T) {
 	mockRepo.On("GetUserByID", mock.Anything).Return(&models.User{}, expectedErr)
 
 	// Llamar al método GetUserByID del servicio
-	_, err := userService.GetUserByID(1)
+	_, err := userService.GetUserByID(context.Background(), 1)
 
 	// Verificar que se haya devuelto el error esperado
 	assert.EqualError(t, err, expectedErr.Error())
@@ -213,17 +523,16 @@ func TestGetAllUsersError(t *testing.T) {
 	mockRepo := &MockUserRepository{}
 
 	// Configurar el servicio con el mock
-	userService := NewUserService(mockRepo)
+	userService := newTestService(mockRepo)
 
 	// Configurar el error que quieres simular
 	expectedErr := errors.New("error getting all users")
 
-	users := []*models.User{}
 	// Configurar el comportamiento del mock para devolver un error
-	mockRepo.On("GetAllUsers").Return(users, expectedErr)
+	mockRepo.On("GetAllUsers", mock.Anything).Return((*repositories.UserPage)(nil), expectedErr)
 
 	// Llamar al método GetAllUsers del servicio
-	_, err := userService.GetAllUsers()
+	_, err := userService.GetAllUsers(context.Background(), input.ListUsersIn{})
 
 	// Verificar que se haya devuelto el error esperado
 	assert.EqualError(t, err, expectedErr.Error())
@@ -234,7 +543,7 @@ func TestUpdateUserError(t *testing.T) {
 	mockRepo := &MockUserRepository{}
 
 	// Configurar el servicio con el mock
-	userService := NewUserService(mockRepo)
+	userService := newTestService(mockRepo)
 
 	// Configurar el error que quieres simular al obtener el usuario
 	expectedErr := errors.New("error getting user by ID")
@@ -245,7 +554,7 @@ func TestUpdateUserError(t *testing.T) {
 	mockRepo.On("GetUserByID", uint(1)).Return(user, expectedErr)
 
 	// Llamar al método UpdateUser del servicio
-	_, err := userService.UpdateUser(1, input.UpdateUserIn{Name: "John Updated", LastName: "Doe Updated"})
+	_, err := userService.UpdateUser(context.Background(), 1, input.UpdateUserIn{Name: "John Updated", LastName: "Doe Updated"})
 
 	// Verificar que se haya devuelto el error esperado
 	assert.EqualError(t, err, expectedErr.Error())
@@ -256,16 +565,17 @@ func TestDeleteUserError(t *testing.T) {
 	mockRepo := &MockUserRepository{}
 
 	// Configurar el servicio con el mock
-	userService := NewUserService(mockRepo)
+	userService := newTestService(mockRepo)
 
 	// Configurar el error que quieres simular
 	expectedErr := errors.New("error deleting user")
 
 	// Configurar el comportamiento del mock para devolver un error
+	mockRepo.On("GetUserByID", uint(1)).Return(&models.User{}, nil)
 	mockRepo.On("DeleteUser", mock.Anything).Return(expectedErr)
 
 	// Llamar al método DeleteUser del servicio
-	_, err := userService.DeleteUser(1)
+	_, err := userService.DeleteUser(context.Background(), 1)
 
 	// Verificar que se haya devuelto el error esperado
 	assert.EqualError(t, err, expectedErr.Error())
@@ -276,7 +586,7 @@ func TestUpdateUserUpdateError(t *testing.T) {
 	mockRepo := &MockUserRepository{}
 
 	// Configurar el servicio con el mock
-	userService := NewUserService(mockRepo)
+	userService := newTestService(mockRepo)
 
 	userID := uint(1)
 	user := &models.User{}
@@ -287,8 +597,344 @@ func TestUpdateUserUpdateError(t *testing.T) {
 	mockRepo.On("UpdateUser", userID, mock.AnythingOfType("*models.User")).Return(expectedErr)
 
 	// Llamar al método UpdateUser del servicio
-	_, err := userService.UpdateUser(1, input.UpdateUserIn{Name: "John Updated", LastName: "Doe Updated"})
+	_, err := userService.UpdateUser(context.Background(), 1, input.UpdateUserIn{Name: "John Updated", LastName: "Doe Updated"})
 
 	// Verificar que se haya devuelto el error esperado
 	assert.EqualError(t, err, expectedErr.Error())
 }
+
+func TestUpdateUserNotFound(t *testing.T) {
+	// Configurar el mock del repositorio
+	mockRepo := &MockUserRepository{}
+
+	// Configurar el servicio con el mock
+	userService := newTestService(mockRepo)
+
+	// Configurar el comportamiento del mock para devolver un usuario inexistente
+	notFoundErr := apperrors.NotFound(errors.New("record not found"))
+	mockRepo.On("GetUserByID", uint(1)).Return((*models.User)(nil), notFoundErr)
+
+	// Llamar al método UpdateUser del servicio
+	_, err := userService.UpdateUser(context.Background(), 1, input.UpdateUserIn{Name: "John Updated", LastName: "Doe Updated"})
+
+	// Verificar que la categoría del error se conserve
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything)
+}
+
+func TestDeleteUserNotFound(t *testing.T) {
+	// Configurar el mock del repositorio
+	mockRepo := &MockUserRepository{}
+
+	// Configurar el servicio con el mock
+	userService := newTestService(mockRepo)
+
+	// Configurar el comportamiento del mock para devolver un usuario inexistente
+	mockRepo.On("GetUserByID", uint(1)).Return((*models.User)(nil), apperrors.NotFound(nil))
+
+	// Llamar al método DeleteUser del servicio
+	deleteOut, err := userService.DeleteUser(context.Background(), 1)
+
+	// Verificar que no se reporte éxito y que la categoría del error se conserve
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	assert.False(t, deleteOut.Success)
+}
+
+// Test para UpdateUser con If-Match en UserServiceImpl
+func TestUpdateUserIfMatch(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := newTestService(mockRepo)
+
+	user := &models.User{Model: gorm.Model{ID: 1}, Name: "John", LastName: "Doe", Version: 3}
+	mockRepo.On("GetUserByID", uint(1)).Return(user, nil)
+	mockRepo.On("UpdateUser", uint(1), user).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.User).Version++
+	})
+
+	// Ejecutar el método UpdateUser con la versión actual
+	userIn := input.UpdateUserIn{Name: "John", LastName: "Smith", IfMatch: &input.Precondition{Versions: []uint{3}}}
+	userOut, err := userService.UpdateUser(context.Background(), 1, userIn)
+
+	// Verificar que se devuelva la nueva versión
+	assert.NoError(t, err)
+	assert.Equal(t, uint(4), userOut.Version)
+	mockRepo.AssertExpectations(t)
+}
+
+// Test para UpdateUser y PatchUser con una versión desactualizada en UserServiceImpl
+func TestUpdateUserPreconditionFailed(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := newTestService(mockRepo)
+
+	mockRepo.On("GetUserByID", uint(1)).Return(&models.User{Model: gorm.Model{ID: 1}, Name: "John", LastName: "Doe", Version: 3}, nil)
+	stale := &input.Precondition{Versions: []uint{2}}
+
+	// Llamar a los métodos de actualización con una versión anterior
+	_, err := userService.UpdateUser(context.Background(), 1, input.UpdateUserIn{Name: "John", LastName: "Smith", IfMatch: stale})
+	assert.ErrorIs(t, err, apperrors.ErrPreconditionFailed)
+
+	_, err = userService.PatchUser(context.Background(), 1, input.PatchUserIn{Type: input.MergePatchType, Patch: []byte(`{"name":"Jane"}`), IfMatch: stale})
+	assert.ErrorIs(t, err, apperrors.ErrPreconditionFailed)
+
+	// Verificar que no se escriba en el repositorio
+	mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "PatchUser", mock.Anything, mock.Anything)
+}
+
+// Test para la precondición de If-Match
+func TestPreconditionMatches(t *testing.T) {
+	var none *input.Precondition
+	assert.True(t, none.Matches(3))
+	assert.True(t, (&input.Precondition{Any: true}).Matches(3))
+	assert.True(t, (&input.Precondition{Versions: []uint{2, 3}}).Matches(3))
+	assert.False(t, (&input.Precondition{Versions: []uint{}}).Matches(3))
+}
+
+// Caso de prueba: restaurar devuelve el usuario con su nueva versión y registra la auditoría
+func TestRestoreUser(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	audit := repoImpl.NewMemoryAuditRepository()
+	service := NewUserService(mockRepo, repoImpl.NewMemoryUserVersionRepository(), audit, repoImpl.NewMemoryTransactor())
+
+	deleted := &models.User{Model: gorm.Model{ID: 1, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}, Name: "John", LastName: "Doe", Version: 2}
+	mockRepo.On("GetUserByIDUnscoped", uint(1)).Return(deleted, nil)
+	mockRepo.On("RestoreUser", uint(1)).Return(&models.User{Model: gorm.Model{ID: 1}, Name: "John", LastName: "Doe", Version: 3}, nil)
+
+	userOut, err := service.RestoreUser(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), userOut.ID)
+	assert.Equal(t, uint(3), userOut.Version)
+	mockRepo.AssertExpectations(t)
+
+	page, err := audit.GetAuditRecords(context.Background(), repositories.AuditQuery{UserID: 1, Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, page.Records, 1) {
+		assert.Equal(t, models.AuditRestore, page.Records[0].Operation)
+		assert.Nil(t, page.Records[0].Changes["deleted_at"].After)
+	}
+}
+
+// Caso de prueba: restaurar un usuario que no está eliminado no escribe ni audita
+func TestRestoreUserNotDeleted(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	audit := repoImpl.NewMemoryAuditRepository()
+	service := NewUserService(mockRepo, repoImpl.NewMemoryUserVersionRepository(), audit, repoImpl.NewMemoryTransactor())
+
+	mockRepo.On("GetUserByIDUnscoped", uint(1)).Return(&models.User{Model: gorm.Model{ID: 1}, Name: "John", Version: 2}, nil)
+
+	userOut, err := service.RestoreUser(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), userOut.Version)
+	mockRepo.AssertNotCalled(t, "RestoreUser", mock.Anything)
+	page, _ := audit.GetAuditRecords(context.Background(), repositories.AuditQuery{Limit: 10})
+	assert.Empty(t, page.Records)
+}
+
+// Caso de prueba: restaurar un usuario inexistente conserva la categoría del error
+func TestRestoreUserNotFound(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := newTestService(mockRepo)
+
+	mockRepo.On("GetUserByIDUnscoped", uint(9)).Return((*models.User)(nil), apperrors.NotFound(nil))
+
+	_, err := service.RestoreUser(context.Background(), 9)
+
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+}
+
+// Caso de prueba: eliminación definitiva
+func TestPurgeUser(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := newTestService(mockRepo)
+
+	mockRepo.On("GetUserByIDUnscoped", uint(1)).Return(&models.User{Name: "John"}, nil)
+	mockRepo.On("PurgeUser", uint(1)).Return(nil)
+
+	deleteOut, err := service.PurgeUser(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.True(t, deleteOut.Success)
+	mockRepo.AssertExpectations(t)
+}
+
+// Caso de prueba: eliminar definitivamente requiere users:purge aunque se tenga users:delete
+func TestPurgeUserRequiresPermission(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := newTestService(mockRepo)
+	ctx := requestctx.WithIdentity(context.Background(), requestctx.Identity{Subject: "2", Permissions: []string{models.PermissionUsersDelete}})
+
+	deleteOut, err := service.PurgeUser(ctx, 1)
+
+	assert.ErrorIs(t, err, apperrors.ErrForbidden)
+	assert.False(t, deleteOut.Success)
+	mockRepo.AssertNotCalled(t, "PurgeUser", uint(1))
+}
+
+// Caso de prueba: la purga por retención audita a cada usuario purgado con el actor del sistema
+func TestPurgeDeletedUsersAudit(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	audit := repoImpl.NewMemoryAuditRepository()
+	service := NewUserService(mockRepo, repoImpl.NewMemoryUserVersionRepository(), audit, repoImpl.NewMemoryTransactor())
+	before := time.Now()
+	deletedAt := gorm.DeletedAt{Time: before.Add(-time.Hour), Valid: true}
+
+	mockRepo.On("PurgeDeletedUsers", before).Return([]*models.User{
+		{Model: gorm.Model{ID: 1, DeletedAt: deletedAt}, Name: "John"},
+		{Model: gorm.Model{ID: 2, DeletedAt: deletedAt}, Name: "Jane"},
+	}, nil)
+
+	purged, err := service.PurgeDeletedUsers(context.Background(), before)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	page, err := audit.GetAuditRecords(context.Background(), repositories.AuditQuery{Operation: models.AuditPurge, Limit: 10})
+	assert.NoError(t, err)
+	require.Len(t, page.Records, 2)
+	names := map[uint]string{1: "John", 2: "Jane"}
+	for _, record := range page.Records {
+		assert.Equal(t, systemActor, record.Actor)
+		assert.Equal(t, models.FieldChange{Before: names[record.UserID]}, record.Changes["name"])
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+// Caso de prueba: cada cambio queda auditado con el actor y la solicitud del contexto
+func TestUserAudit(t *testing.T) {
+	repo := repoImpl.NewMemoryUserRepository()
+	audit := repoImpl.NewMemoryAuditRepository()
+	service := NewUserService(repo, repoImpl.NewMemoryUserVersionRepository(), audit, repoImpl.NewMemoryTransactor())
+	ctx := requestctx.WithActor(requestctx.WithRequestID(context.Background(), "req-1"), "alice")
+
+	created, err := service.CreateUser(ctx, input.CreateUserIn{Name: "Jon", LastName: "Doe"})
+	assert.NoError(t, err)
+	_, err = service.PatchUser(ctx, created.ID, input.PatchUserIn{Type: input.MergePatchType, Patch: []byte(`{"name":"John"}`)})
+	assert.NoError(t, err)
+	_, err = service.DeleteUser(context.Background(), created.ID)
+	assert.NoError(t, err)
+
+	history, err := service.GetUserHistory(ctx, created.ID, input.ListAuditIn{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), history.Total)
+	if assert.Len(t, history.Data, 3) {
+		deleted, patched, createdRecord := history.Data[0], history.Data[1], history.Data[2]
+		assert.Equal(t, "delete", deleted.Operation)
+		assert.Equal(t, "system", deleted.Actor)
+		assert.Equal(t, "update", patched.Operation)
+		assert.Equal(t, "alice", patched.Actor)
+		assert.Equal(t, "req-1", patched.RequestID)
+		assert.Equal(t, map[string]output.FieldChangeOut{"name": {Before: "Jon", After: "John"}}, patched.Changes)
+		assert.Equal(t, "create", createdRecord.Operation)
+		assert.Equal(t, "Doe", createdRecord.Changes["last_name"].After)
+	}
+
+	filtered, err := service.GetAuditRecords(ctx, input.ListAuditIn{Actor: "alice", Operation: "create"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), filtered.Total)
+}
+
+// Caso de prueba: si falla el registro de auditoría no se confirma el cambio
+func TestUserAuditError(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	auditErr := errors.New("audit unavailable")
+	service := NewUserService(mockRepo, repoImpl.NewMemoryUserVersionRepository(), &failingAuditRepository{err: auditErr}, repoImpl.NewMemoryTransactor())
+
+	mockRepo.On("CreateUser", mock.Anything).Return(nil)
+
+	_, err := service.CreateUser(context.Background(), input.CreateUserIn{Name: "John", LastName: "Doe"})
+
+	assert.ErrorIs(t, err, auditErr)
+}
+
+// failingAuditRepository simula una auditoría que no puede escribir
+type failingAuditRepository struct {
+	repoImpl.MemoryAuditRepository
+	err error
+}
+
+func (r *failingAuditRepository) CreateAuditRecord(ctx context.Context, record *models.AuditRecord) error {
+	return r.err
+}
+
+// Caso de prueba: cada cambio guarda una instantánea para consultar el usuario en el tiempo
+func TestUserVersions(t *testing.T) {
+	service := NewUserService(repoImpl.NewMemoryUserRepository(), repoImpl.NewMemoryUserVersionRepository(), repoImpl.NewMemoryAuditRepository(), repoImpl.NewMemoryTransactor())
+	ctx := context.Background()
+
+	beforeCreate := time.Now()
+	time.Sleep(time.Millisecond)
+	created, err := service.CreateUser(ctx, input.CreateUserIn{Name: "Jon", LastName: "Doe"})
+	assert.NoError(t, err)
+	afterCreate := time.Now()
+	time.Sleep(time.Millisecond)
+	_, err = service.UpdateUser(ctx, created.ID, input.UpdateUserIn{Name: "John", LastName: "Doe"})
+	assert.NoError(t, err)
+	afterUpdate := time.Now()
+	time.Sleep(time.Millisecond)
+	_, err = service.DeleteUser(ctx, created.ID)
+	assert.NoError(t, err)
+
+	userOut, err := service.GetUserAsOf(ctx, created.ID, afterCreate)
+	assert.NoError(t, err)
+	assert.Equal(t, "Jon", userOut.Name)
+	userOut, err = service.GetUserAsOf(ctx, created.ID, afterUpdate)
+	assert.NoError(t, err)
+	assert.Equal(t, "John", userOut.Name)
+	assert.Equal(t, uint(2), userOut.Version)
+
+	// Antes de crearse y después de eliminarse el usuario no existe
+	_, err = service.GetUserAsOf(ctx, created.ID, beforeCreate)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	_, err = service.GetUserAsOf(ctx, created.ID, time.Now())
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+
+	versionOut, err := service.GetUserVersion(ctx, created.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Jon", versionOut.Name)
+	assert.Nil(t, versionOut.DeletedAt)
+
+	diffOut, err := service.DiffUserVersions(ctx, created.ID, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]output.FieldChangeOut{"name": {Before: "Jon", After: "John"}}, diffOut.Changes)
+
+	// La eliminación tiene su propia versión
+	versionOut, err = service.GetUserVersion(ctx, created.ID, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "John", versionOut.Name)
+	assert.NotNil(t, versionOut.DeletedAt)
+
+	_, err = service.DiffUserVersions(ctx, created.ID, 1, 4)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+}
+
+// Caso de prueba: solo se registran los campos que cambiaron
+func TestDiffUsers(t *testing.T) {
+	before := &models.User{Name: "Jon", LastName: "Doe"}
+	after := &models.User{Name: "John", LastName: "Doe"}
+
+	assert.Equal(t, map[string]models.FieldChange{"name": {Before: "Jon", After: "John"}}, diffUsers(before, after))
+	assert.Empty(t, diffUsers(before, before))
+	assert.Equal(t, map[string]models.FieldChange{
+		"name":      {Before: "Jon", After: nil},
+		"last_name": {Before: "Doe", After: nil},
+	}, diffUsers(before, nil))
+}
+
+// Caso de prueba: el listado expone la fecha de borrado y traslada el alcance al repositorio
+func TestGetAllUsersDeleted(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := newTestService(mockRepo)
+
+	deletedAt := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+	users := []*models.User{{Model: gorm.Model{ID: 1, DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}}, Name: "John"}}
+	mockRepo.On("GetAllUsers", mock.MatchedBy(func(query repositories.UserQuery) bool {
+		return query.Filter.Deleted == repositories.DeletedOnly
+	})).Return(&repositories.UserPage{Users: users, Total: 1}, nil)
+
+	pageOut, err := service.GetAllUsers(context.Background(), input.ListUsersIn{Deleted: "only"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &deletedAt, pageOut.Data[0].DeletedAt)
+	mockRepo.AssertExpectations(t)
+}
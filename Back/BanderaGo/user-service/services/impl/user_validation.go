@@ -0,0 +1,89 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/models"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Límites de longitud en caracteres (no en bytes) de cada dato
+const (
+	maxNameLength        = 100
+	maxDisplayNameLength = 255
+	maxEmailLength       = 254
+	minUsernameLength    = 3
+	maxUsernameLength    = 64
+)
+
+var (
+	// Letras de cualquier alfabeto (incluidas las acentuadas y la ñ, compuestas o no),
+	// espacios, apóstrofos, guiones y puntos, con al menos una letra
+	namePattern     = regexp.MustCompile(`^[\p{L}\p{M}' ’.-]*\p{L}[\p{L}\p{M}' ’.-]*$`)
+	usernamePattern = regexp.MustCompile(`^[\p{L}\p{N}._-]+$`)
+	e164Pattern     = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+)
+
+// validateUser revisa un usuario ya normalizado y reporta juntas todas las reglas que
+// no cumple, en el orden de los campos.
+func validateUser(user *models.User) error {
+	var violations apperrors.Violations
+	check := func(field string, ok bool, rule, param string) bool {
+		if !ok {
+			violations = append(violations, apperrors.Violation{Field: field, Rule: rule, Param: param})
+		}
+		return ok
+	}
+
+	for _, name := range []struct{ field, value string }{{"name", user.Name}, {"last_name", user.LastName}} {
+		if check(name.field, name.value != "", "required", "") {
+			check(name.field, utf8.RuneCountInString(name.value) <= maxNameLength, "max", strconv.Itoa(maxNameLength))
+			check(name.field, namePattern.MatchString(name.value), "name", "")
+		}
+	}
+	if user.Email != nil {
+		check("email", utf8.RuneCountInString(*user.Email) <= maxEmailLength, "max", strconv.Itoa(maxEmailLength))
+		check("email", isEmail(*user.Email), "email", "")
+	}
+	if user.Username != nil {
+		length := utf8.RuneCountInString(*user.Username)
+		check("username", length >= minUsernameLength, "min", strconv.Itoa(minUsernameLength))
+		check("username", length <= maxUsernameLength, "max", strconv.Itoa(maxUsernameLength))
+		check("username", usernamePattern.MatchString(*user.Username), "username", "")
+	}
+	if user.Phone != nil {
+		check("phone", e164Pattern.MatchString(*user.Phone), "phone", "")
+	}
+	check("display_name", utf8.RuneCountInString(user.DisplayName) <= maxDisplayNameLength, "max", strconv.Itoa(maxDisplayNameLength))
+	check("display_name", isPrintable(user.DisplayName), "printable", "")
+
+	return apperrors.Invalid(violations)
+}
+
+// isEmail acepta solo la dirección, sin nombre ni ángulos, con un dominio que tenga punto
+func isEmail(value string) bool {
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value || address.Name != "" {
+		return false
+	}
+	_, domain, _ := strings.Cut(value, "@")
+	return strings.Contains(strings.Trim(domain, "."), ".")
+}
+
+// isPrintable rechaza caracteres de control y de formato, como saltos de línea o
+// marcas de dirección invisibles
+func isPrintable(value string) bool {
+	return strings.IndexFunc(value, func(r rune) bool {
+		return unicode.IsControl(r) || unicode.Is(unicode.Cf, r)
+	}) < 0
+}
+
+// prepareUser normaliza los datos del usuario y después los valida
+func prepareUser(user *models.User) error {
+	normalizeUser(user)
+	return validateUser(user)
+}
@@ -0,0 +1,118 @@
+package impl
+
+import (
+	"application/apperrors"
+	"application/dtos/output"
+	"application/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var errDeletedAsOf = errors.New("el usuario estaba eliminado en el instante indicado")
+
+// recordVersion guarda el estado del usuario tras una operación, dentro de su transacción
+func (s *UserServiceImpl) recordVersion(ctx context.Context, user *models.User) error {
+	snapshot := models.UserVersion{
+		UserID:      user.ID,
+		Version:     user.Version,
+		Name:        user.Name,
+		LastName:    user.LastName,
+		Email:       user.Email,
+		Username:    user.Username,
+		Phone:       user.Phone,
+		DisplayName: user.DisplayName,
+		ValidFrom:   time.Now(),
+	}
+	if user.DeletedAt.Valid {
+		snapshot.DeletedAt = &user.DeletedAt.Time
+		snapshot.ValidFrom = user.DeletedAt.Time
+	}
+	return s.versions.CreateUserVersion(ctx, &snapshot)
+}
+
+// userFromVersion reconstruye el usuario a partir de una instantánea
+func userFromVersion(snapshot *models.UserVersion) *models.User {
+	user := &models.User{
+		Model:       gorm.Model{ID: snapshot.UserID},
+		Name:        snapshot.Name,
+		LastName:    snapshot.LastName,
+		Email:       snapshot.Email,
+		Username:    snapshot.Username,
+		Phone:       snapshot.Phone,
+		DisplayName: snapshot.DisplayName,
+		Version:     snapshot.Version,
+	}
+	if snapshot.DeletedAt != nil {
+		user.DeletedAt = gorm.DeletedAt{Time: *snapshot.DeletedAt, Valid: true}
+	}
+	return user
+}
+
+// GetUserAsOf devuelve el usuario tal como estaba en el instante indicado; si todavía no
+// existía o ya estaba eliminado responde como no encontrado
+func (s *UserServiceImpl) GetUserAsOf(ctx context.Context, id uint, at time.Time) (output.GetUserOut, error) {
+	snapshot, err := s.versions.GetUserAsOf(ctx, id, at)
+	if err != nil {
+		return output.GetUserOut{}, err
+	}
+	if snapshot.DeletedAt != nil {
+		return output.GetUserOut{}, apperrors.NotFound(errDeletedAsOf)
+	}
+	userOut := output.GetUserOut{
+		ID:          snapshot.UserID,
+		Name:        snapshot.Name,
+		LastName:    snapshot.LastName,
+		Email:       stringValue(snapshot.Email),
+		Username:    stringValue(snapshot.Username),
+		Phone:       stringValue(snapshot.Phone),
+		DisplayName: snapshot.DisplayName,
+		Version:     snapshot.Version,
+	}
+	return userOut, nil
+}
+
+func (s *UserServiceImpl) GetUserVersion(ctx context.Context, id, version uint) (output.UserVersionOut, error) {
+	snapshot, err := s.versions.GetUserVersion(ctx, id, version)
+	if err != nil {
+		return output.UserVersionOut{}, err
+	}
+	versionOut := output.UserVersionOut{
+		ID:          snapshot.UserID,
+		Version:     snapshot.Version,
+		Name:        snapshot.Name,
+		LastName:    snapshot.LastName,
+		Email:       stringValue(snapshot.Email),
+		Username:    stringValue(snapshot.Username),
+		Phone:       stringValue(snapshot.Phone),
+		DisplayName: snapshot.DisplayName,
+		DeletedAt:   snapshot.DeletedAt,
+		ValidFrom:   snapshot.ValidFrom,
+	}
+	return versionOut, nil
+}
+
+// DiffUserVersions devuelve los campos que cambian entre dos versiones del usuario
+func (s *UserServiceImpl) DiffUserVersions(ctx context.Context, id, from, to uint) (output.UserDiffOut, error) {
+	fromSnapshot, err := s.versions.GetUserVersion(ctx, id, from)
+	if err != nil {
+		return output.UserDiffOut{}, err
+	}
+	toSnapshot, err := s.versions.GetUserVersion(ctx, id, to)
+	if err != nil {
+		return output.UserDiffOut{}, err
+	}
+
+	diffOut := output.UserDiffOut{
+		ID:      id,
+		From:    from,
+		To:      to,
+		Changes: make(map[string]output.FieldChangeOut),
+	}
+	for field, change := range diffUsers(userFromVersion(fromSnapshot), userFromVersion(toSnapshot)) {
+		diffOut.Changes[field] = output.FieldChangeOut{Before: change.Before, After: change.After}
+	}
+	return diffOut, nil
+}
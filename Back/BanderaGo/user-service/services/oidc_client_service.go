@@ -0,0 +1,20 @@
+package services
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"context"
+)
+
+// OIDCClientService administra las aplicaciones registradas en el proveedor OpenID Connect
+type OIDCClientService interface {
+	GetClients(ctx context.Context) ([]output.OIDCClientOut, error)
+	GetClient(ctx context.Context, id string) (output.OIDCClientOut, error)
+	CreateClient(ctx context.Context, clientIn input.OIDCClientIn) (output.OIDCClientCreatedOut, error)
+	UpdateClient(ctx context.Context, id string, clientIn input.UpdateOIDCClientIn) (output.OIDCClientOut, error)
+	// RotateClientSecret reemplaza el secreto de un cliente confidencial; el anterior deja
+	// de funcionar
+	RotateClientSecret(ctx context.Context, id string) (output.OIDCClientCreatedOut, error)
+	// DeleteClient elimina el cliente; los tokens que ya recibió dejan de servir en userinfo
+	DeleteClient(ctx context.Context, id string) error
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/tokens"
+	"context"
+	"time"
+)
+
+// OIDCService implementa el proveedor OpenID Connect: el flujo del código de autorización
+// con PKCE, la emisión de los tokens, userinfo y la rotación de las llaves de firma
+type OIDCService interface {
+	Discovery() output.DiscoveryOut
+	// Authorize devuelve la dirección a la que se envía al navegador: la URI de redirección
+	// del cliente con el código o con el error, o la página de inicio de sesión. Devuelve
+	// un error sin redirigir si el cliente o la URI de redirección no son válidos.
+	Authorize(ctx context.Context, authorizeIn input.AuthorizeIn) (string, error)
+	// Token canjea el código de autorización; los errores del protocolo son *oidc.Error
+	Token(ctx context.Context, tokenIn input.TokenRequestIn) (output.OIDCTokenOut, error)
+	UserInfo(ctx context.Context, accessToken string) (output.UserInfoOut, error)
+	// PublicKeys devuelve las llaves de firma vigentes y las retiradas que siguen publicadas
+	PublicKeys(ctx context.Context) (tokens.JWKS, error)
+	GetSigningKeys(ctx context.Context) ([]output.SigningKeyOut, error)
+	// RotateSigningKey firma desde ya con una llave nueva; la anterior sigue publicada
+	// durante la retención
+	RotateSigningKey(ctx context.Context) (output.SigningKeyOut, error)
+	// RotateExpiredSigningKey rota la llave activa si es más antigua que maxAge e indica si
+	// la rotó
+	RotateExpiredSigningKey(ctx context.Context, maxAge time.Duration) (bool, error)
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"application/requestctx"
+	"context"
+)
+
+type RoleService interface {
+	GetRoles(ctx context.Context) ([]output.RoleOut, error)
+	GetRole(ctx context.Context, name string) (output.RoleOut, error)
+	CreateRole(ctx context.Context, roleIn input.RoleIn) (output.RoleOut, error)
+	UpdateRole(ctx context.Context, name string, roleIn input.UpdateRoleIn) (output.RoleOut, error)
+	DeleteRole(ctx context.Context, name string) error
+	GetUserRoles(ctx context.Context, userID uint) (output.UserRolesOut, error)
+	AssignRole(ctx context.Context, userID uint, role string) error
+	RevokeRole(ctx context.Context, userID uint, role string) error
+	// Permissions resuelve los permisos de los roles del token y de los asignados al usuario
+	Permissions(ctx context.Context, identity requestctx.Identity) ([]string, error)
+}
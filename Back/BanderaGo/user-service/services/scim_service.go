@@ -0,0 +1,35 @@
+package services
+
+import (
+	"application/dtos/input"
+	"application/dtos/output"
+	"context"
+)
+
+// SCIMService expone los usuarios y los roles con el protocolo SCIM 2.0 para que un
+// proveedor de identidad los aprovisione. Los usuarios se modifican a través de
+// UserService y los grupos corresponden a los roles.
+type SCIMService interface {
+	GetUsers(ctx context.Context, listIn input.SCIMListIn) (output.SCIMUserListOut, error)
+	GetUser(ctx context.Context, id string) (output.SCIMUserOut, error)
+	CreateUser(ctx context.Context, userIn input.SCIMUserIn) (output.SCIMUserOut, error)
+	// ReplaceUser reemplaza los atributos del usuario; cambiar active lo elimina
+	// lógicamente o lo restaura
+	ReplaceUser(ctx context.Context, id string, userIn input.SCIMUserIn, ifMatch *input.Precondition) (output.SCIMUserOut, error)
+	PatchUser(ctx context.Context, id string, patchIn input.SCIMPatchIn, ifMatch *input.Precondition) (output.SCIMUserOut, error)
+	// DeleteUser elimina definitivamente al usuario
+	DeleteUser(ctx context.Context, id string) error
+
+	GetGroups(ctx context.Context, listIn input.SCIMListIn) (output.SCIMGroupListOut, error)
+	GetGroup(ctx context.Context, id string) (output.SCIMGroupOut, error)
+	// CreateGroup crea un rol sin permisos con los miembros indicados
+	CreateGroup(ctx context.Context, groupIn input.SCIMGroupIn) (output.SCIMGroupOut, error)
+	// ReplaceGroup reemplaza los miembros del grupo; el nombre no puede cambiar
+	ReplaceGroup(ctx context.Context, id string, groupIn input.SCIMGroupIn) (output.SCIMGroupOut, error)
+	PatchGroup(ctx context.Context, id string, patchIn input.SCIMPatchIn) (output.SCIMGroupOut, error)
+	DeleteGroup(ctx context.Context, id string) error
+
+	ServiceProviderConfig() output.SCIMServiceProviderConfigOut
+	ResourceTypes() output.SCIMResourceTypeListOut
+	Schemas() output.SCIMSchemaListOut
+}
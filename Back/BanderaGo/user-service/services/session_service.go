@@ -0,0 +1,20 @@
+package services
+
+import (
+	"application/dtos/output"
+	"context"
+)
+
+type SessionService interface {
+	GetUserSessions(ctx context.Context, userID uint) ([]output.SessionOut, error)
+	RevokeSession(ctx context.Context, userID, sessionID uint) error
+	RevokeUserSessions(ctx context.Context, userID uint) error
+	// EndSession y EndUserSessions revocan sin comprobar quién lo pide, para las
+	// revocaciones del propio servicio como el cierre de sesión o el restablecimiento de
+	// la contraseña
+	EndSession(ctx context.Context, sessionID uint) error
+	EndUserSessions(ctx context.Context, userID uint) error
+	// VerifySession devuelve un error no autorizado si la sesión de un token de acceso ya
+	// no está activa
+	VerifySession(ctx context.Context, sessionID string) error
+}
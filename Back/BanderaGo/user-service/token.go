@@ -0,0 +1,55 @@
+package main
+
+import (
+	"application/config"
+	"application/tokens"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const tokenUsage = "uso: token [-roles rol1,rol2] [-mfa] <sujeto> [nombre de usuario]"
+
+// runToken ejecuta el subcomando "token", que firma un token de acceso para el sujeto
+// indicado. Sirve para que otros sistemas o el primer administrador llamen a la API
+// antes de que existan usuarios con contraseña o roles asignados.
+func runToken(out io.Writer, userConfig *config.UserConfig, args []string) error {
+	flags := flag.NewFlagSet("token", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	roleList := flags.String("roles", "", "roles del token separados por comas")
+	multiFactor := flags.Bool("mfa", false, "marca el token como verificado con segundo factor, lo que exigen los roles de MFA_REQUIRED_ROLES")
+	if err := flags.Parse(args); err != nil {
+		return errors.New(tokenUsage)
+	}
+	args = flags.Args()
+	if len(args) == 0 || len(args) > 2 || args[0] == "" {
+		return errors.New(tokenUsage)
+	}
+
+	manager, err := tokens.NewManager(userConfig.Auth)
+	if err != nil {
+		return err
+	}
+	username := ""
+	if len(args) == 2 {
+		username = args[1]
+	}
+	var roles []string
+	for _, role := range strings.Split(*roleList, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	var amr []string
+	if *multiFactor {
+		amr = []string{tokens.AMRMultiFactor}
+	}
+	token, _, err := manager.Issue(args[0], username, roles, amr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, token)
+	return nil
+}
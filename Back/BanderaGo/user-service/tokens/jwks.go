@@ -0,0 +1,175 @@
+package tokens
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// Algoritmos de firma que acepta el servicio
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// JWK es una llave en formato JSON Web Key (RFC 7517). Solo se admiten llaves RSA y
+// Ed25519; los campos privados son opcionales.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	D   string `json:"d,omitempty"`
+	P   string `json:"p,omitempty"`
+	Q   string `json:"q,omitempty"`
+}
+
+// JWKS es un conjunto de llaves (RFC 7517, sección 5)
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// key es una llave lista para verificar y, si tiene la parte privada, para firmar
+type key struct {
+	id      string
+	alg     string
+	public  crypto.PublicKey
+	private crypto.Signer
+}
+
+// loadJWKS lee las llaves del archivo indicado
+func loadJWKS(path string) ([]key, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el JWKS '%s': %w", path, err)
+	}
+	var set JWKS
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, fmt.Errorf("el JWKS '%s' no es válido: %w", path, err)
+	}
+
+	keys := make([]key, 0, len(set.Keys))
+	for i, jwk := range set.Keys {
+		parsed, err := parseJWK(jwk)
+		if err != nil {
+			return nil, fmt.Errorf("llave %d ('%s') del JWKS: %w", i, jwk.Kid, err)
+		}
+		keys = append(keys, parsed)
+	}
+	return keys, nil
+}
+
+func parseJWK(jwk JWK) (key, error) {
+	switch {
+	case jwk.Kty == "RSA":
+		return parseRSA(jwk)
+	case jwk.Kty == "OKP" && jwk.Crv == "Ed25519":
+		return parseEd25519(jwk)
+	}
+	return key{}, fmt.Errorf("tipo de llave no soportado: %s %s", jwk.Kty, jwk.Crv)
+}
+
+func parseRSA(jwk JWK) (key, error) {
+	if jwk.Alg != "" && jwk.Alg != AlgRS256 {
+		return key{}, fmt.Errorf("algoritmo no soportado para RSA: %s", jwk.Alg)
+	}
+	n, err := decodeInt(jwk.N)
+	if err != nil {
+		return key{}, err
+	}
+	e, err := decodeInt(jwk.E)
+	if err != nil {
+		return key{}, err
+	}
+	public := &rsa.PublicKey{N: n, E: int(e.Int64())}
+	if public.N.BitLen() < 2048 {
+		return key{}, fmt.Errorf("la llave RSA debe tener al menos 2048 bits")
+	}
+	parsed := key{id: jwk.Kid, alg: AlgRS256, public: public}
+	if jwk.D == "" {
+		return parsed, nil
+	}
+
+	private := &rsa.PrivateKey{PublicKey: *public}
+	if private.D, err = decodeInt(jwk.D); err != nil {
+		return key{}, err
+	}
+	p, err := decodeInt(jwk.P)
+	if err != nil {
+		return key{}, err
+	}
+	q, err := decodeInt(jwk.Q)
+	if err != nil {
+		return key{}, err
+	}
+	private.Primes = []*big.Int{p, q}
+	if err := private.Validate(); err != nil {
+		return key{}, fmt.Errorf("la llave privada RSA no es válida: %w", err)
+	}
+	private.Precompute()
+	parsed.private = private
+	return parsed, nil
+}
+
+func parseEd25519(jwk JWK) (key, error) {
+	if jwk.Alg != "" && jwk.Alg != AlgEdDSA {
+		return key{}, fmt.Errorf("algoritmo no soportado para Ed25519: %s", jwk.Alg)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil || len(x) != ed25519.PublicKeySize {
+		return key{}, fmt.Errorf("la llave pública Ed25519 no es válida")
+	}
+	parsed := key{id: jwk.Kid, alg: AlgEdDSA, public: ed25519.PublicKey(x)}
+	if jwk.D == "" {
+		return parsed, nil
+	}
+
+	seed, err := base64.RawURLEncoding.DecodeString(jwk.D)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return key{}, fmt.Errorf("la llave privada Ed25519 no es válida")
+	}
+	private := ed25519.NewKeyFromSeed(seed)
+	if !private.Public().(ed25519.PublicKey).Equal(parsed.public) {
+		return key{}, fmt.Errorf("la llave privada Ed25519 no corresponde a la pública")
+	}
+	parsed.private = private
+	return parsed, nil
+}
+
+func decodeInt(value string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(bytes) == 0 {
+		return nil, fmt.Errorf("valor base64url inválido")
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
+
+// publicJWK devuelve la parte pública de la llave para publicarla
+func publicJWK(k key) JWK {
+	return PublicJWK(k.id, k.alg, k.public)
+}
+
+// PublicJWK convierte una llave pública RSA o Ed25519 en un JWK de firma
+func PublicJWK(kid, alg string, public crypto.PublicKey) JWK {
+	jwk := JWK{Kid: kid, Use: "sig", Alg: alg}
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty, jwk.Crv = "OKP", "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
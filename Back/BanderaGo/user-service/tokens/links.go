@@ -0,0 +1,64 @@
+package tokens
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// Propósitos de los tokens de un solo uso que se envían por correo. La firma incluye el
+// propósito, así que un token de verificación no sirve para restablecer la contraseña.
+const (
+	PurposeVerifyEmail   = "verify_email"
+	PurposeResetPassword = "reset_password"
+)
+
+const linkTokenBytes = 32
+
+// ErrInvalidLinkToken indica un token de enlace mal formado, con otra firma o de otro propósito
+var ErrInvalidLinkToken = errors.New("el token del enlace no es válido")
+
+// LinkSigner firma los tokens de un solo uso de los enlaces que se envían por correo. El
+// token es <valor aleatorio>.<HMAC-SHA256 del propósito y el valor>: la firma descarta los
+// tokens alterados sin consultar la base, donde solo se guarda el hash del token.
+type LinkSigner struct {
+	key []byte
+}
+
+func NewLinkSigner(secret []byte) *LinkSigner {
+	return &LinkSigner{key: secret}
+}
+
+// New genera un token firmado. Devuelve el token, que solo viaja en el correo, y el hash
+// que se guarda.
+func (s *LinkSigner) New(purpose string) (raw, hash string, err error) {
+	value := make([]byte, linkTokenBytes)
+	if _, err := rand.Read(value); err != nil {
+		return "", "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(value)
+	raw = encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(purpose, encoded))
+	return raw, HashRefreshToken(raw), nil
+}
+
+// Verify comprueba la firma del token para el propósito indicado y devuelve su hash
+func (s *LinkSigner) Verify(purpose, raw string) (string, error) {
+	encoded, signature, ok := strings.Cut(raw, ".")
+	if !ok {
+		return "", ErrInvalidLinkToken
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(decoded, s.sign(purpose, encoded)) {
+		return "", ErrInvalidLinkToken
+	}
+	return HashRefreshToken(raw), nil
+}
+
+func (s *LinkSigner) sign(purpose, encoded string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(purpose + "." + encoded))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,38 @@
+package tokens
+
+import (
+	"application/config"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+const refreshTokenBytes = 32
+
+// NewRefreshToken genera un token de renovación opaco. Devuelve el token, que solo
+// recibe el cliente, y el hash que se guarda.
+func NewRefreshToken() (raw, hash string, err error) {
+	value := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(value); err != nil {
+		return "", "", err
+	}
+	raw = base64.RawURLEncoding.EncodeToString(value)
+	return raw, HashRefreshToken(raw), nil
+}
+
+// HashRefreshToken calcula el hash con el que se busca el token de renovación; también
+// es el de los tokens de los enlaces
+func HashRefreshToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// RefreshTTL es el tiempo que una sesión puede estar sin renovarse antes de vencer
+func (m *Manager) RefreshTTL() time.Duration {
+	if m.cfg.RefreshTTL <= 0 {
+		return config.DefaultRefreshTTL
+	}
+	return m.cfg.RefreshTTL
+}
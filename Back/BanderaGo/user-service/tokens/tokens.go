@@ -0,0 +1,198 @@
+package tokens
+
+import (
+	"application/config"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken agrupa los tokens mal formados, vencidos, con otra firma o emitidos
+// para otro servicio
+var ErrInvalidToken = errors.New("el token de acceso no es válido")
+
+// Métodos de autenticación del claim amr (RFC 8176)
+const (
+	AMRPassword    = "pwd"
+	AMROTP         = "otp"
+	AMRMultiFactor = "mfa"
+)
+
+// Claims son los datos del token de acceso. Subject identifica al usuario o al sistema
+// que hace la solicitud.
+type Claims struct {
+	jwt.RegisteredClaims
+	Username string `json:"preferred_username,omitempty"`
+	// Roles se suman a los que el usuario tiene asignados en la base; los tokens que
+	// emite el servicio para sus usuarios no los incluyen
+	Roles []string `json:"roles,omitempty"`
+	// SessionID es la sesión con la que se emitió el token
+	SessionID string `json:"sid,omitempty"`
+	// AMR son los métodos con los que se autenticó el usuario
+	AMR []string `json:"amr,omitempty"`
+}
+
+// MultiFactor indica si el usuario se autenticó con un segundo factor
+func (c *Claims) MultiFactor() bool {
+	return slices.Contains(c.AMR, AMRMultiFactor)
+}
+
+// Manager emite y verifica los tokens de acceso del servicio
+type Manager struct {
+	cfg     config.AuthConfig
+	secret  []byte
+	keys    map[string]key
+	signing *key
+	methods []string
+}
+
+// NewManager prepara las llaves configuradas. Se necesita un secreto o un JWKS; los
+// tokens se firman con la llave SigningKeyID o, si no se define, con el secreto.
+func NewManager(cfg config.AuthConfig) (*Manager, error) {
+	m := &Manager{cfg: cfg, keys: map[string]key{}}
+	if cfg.Secret != "" {
+		m.secret = []byte(cfg.Secret)
+		m.methods = append(m.methods, AlgHS256)
+	}
+
+	if cfg.JWKSFile != "" {
+		keys, err := loadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			if _, exists := m.keys[k.id]; exists {
+				return nil, fmt.Errorf("el kid '%s' está repetido en el JWKS", k.id)
+			}
+			m.keys[k.id] = k
+			if !slices.Contains(m.methods, k.alg) {
+				m.methods = append(m.methods, k.alg)
+			}
+		}
+	}
+
+	if len(m.methods) == 0 {
+		return nil, fmt.Errorf("se necesita 'JWT_SECRET' o 'JWT_JWKS_FILE' para autenticar las solicitudes")
+	}
+
+	if cfg.SigningKeyID != "" {
+		k, ok := m.keys[cfg.SigningKeyID]
+		if !ok || k.private == nil {
+			return nil, fmt.Errorf("el JWKS no tiene la llave privada '%s'", cfg.SigningKeyID)
+		}
+		m.signing = &k
+	} else if m.secret == nil {
+		return nil, fmt.Errorf("se necesita 'JWT_SECRET' o 'JWT_SIGNING_KEY_ID' para emitir tokens")
+	}
+	return m, nil
+}
+
+// Issue firma un token de acceso para subject con los roles y los métodos de autenticación
+// amr que vence después de TokenTTL
+func (m *Manager) Issue(subject, username string, roles, amr []string) (string, time.Time, error) {
+	return m.issue(subject, username, roles, "", amr)
+}
+
+// IssueForSession firma un token de acceso de un usuario que inició una sesión con los
+// métodos de autenticación amr
+func (m *Manager) IssueForSession(subject, username, sessionID string, amr []string) (string, time.Time, error) {
+	return m.issue(subject, username, nil, sessionID, amr)
+}
+
+func (m *Manager) issue(subject, username string, roles []string, sessionID string, amr []string) (string, time.Time, error) {
+	now := time.Now().UTC()
+	expiresAt := now.Add(m.cfg.TokenTTL)
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        newTokenID(),
+			Issuer:    m.cfg.Issuer,
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings{m.cfg.Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Username:  username,
+		Roles:     roles,
+		SessionID: sessionID,
+		AMR:       amr,
+	}
+
+	var token *jwt.Token
+	var signingKey interface{}
+	if m.signing != nil {
+		token = jwt.NewWithClaims(jwt.GetSigningMethod(m.signing.alg), claims)
+		token.Header["kid"] = m.signing.id
+		signingKey = m.signing.private
+	} else {
+		token = jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		signingKey = m.secret
+	}
+
+	signed, err := token.SignedString(signingKey)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("no se pudo firmar el token: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// Verify comprueba la firma, el emisor, la audiencia y las fechas del token
+func (m *Manager) Verify(raw string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(raw, claims, m.keyFor,
+		jwt.WithValidMethods(m.methods),
+		jwt.WithIssuer(m.cfg.Issuer),
+		jwt.WithAudience(m.cfg.Audience),
+		jwt.WithLeeway(m.cfg.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: falta el sujeto", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+// PublicKeys devuelve las llaves públicas del JWKS para que otros servicios verifiquen
+// los tokens; el secreto de HS256 nunca se publica
+func (m *Manager) PublicKeys() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, k := range m.keys {
+		set.Keys = append(set.Keys, publicJWK(k))
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+// keyFor elige la llave de verificación según el algoritmo y el kid del encabezado
+func (m *Manager) keyFor(token *jwt.Token) (interface{}, error) {
+	alg := token.Method.Alg()
+	if alg == AlgHS256 {
+		return m.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	k, ok := m.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("llave desconocida '%s'", kid)
+	}
+	if k.alg != alg {
+		return nil, fmt.Errorf("la llave '%s' no usa %s", kid, alg)
+	}
+	return k.public, nil
+}
+
+func newTokenID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
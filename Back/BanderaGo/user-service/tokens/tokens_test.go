@@ -0,0 +1,239 @@
+package tokens
+
+import (
+	"application/config"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func testAuthConfig() config.AuthConfig {
+	return config.AuthConfig{
+		Issuer:   config.DefaultTokenIssuer,
+		Audience: config.DefaultTokenAudience,
+		Secret:   testSecret,
+		TokenTTL: time.Minute,
+		Leeway:   5 * time.Second,
+	}
+}
+
+func b64(bytes []byte) string {
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+// writeJWKS guarda un JWKS con una llave RSA y una Ed25519, ambas con la parte privada
+func writeJWKS(t *testing.T) string {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	set := JWKS{Keys: []JWK{
+		{Kty: "RSA", Kid: "rsa-1", Alg: AlgRS256, N: b64(rsaKey.N.Bytes()), E: b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+			D: b64(rsaKey.D.Bytes()), P: b64(rsaKey.Primes[0].Bytes()), Q: b64(rsaKey.Primes[1].Bytes())},
+		{Kty: "OKP", Crv: "Ed25519", Kid: "ed-1", X: b64(edKey.Public().(ed25519.PublicKey)), D: b64(edKey.Seed())},
+	}}
+	content, err := json.Marshal(set)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+	return path
+}
+
+// Caso de prueba: un token emitido con cada algoritmo se verifica con sus datos
+func TestIssueAndVerify(t *testing.T) {
+	jwksFile := writeJWKS(t)
+	for name, signingKeyID := range map[string]string{"HS256": "", "RS256": "rsa-1", "EdDSA": "ed-1"} {
+		t.Run(name, func(t *testing.T) {
+			cfg := testAuthConfig()
+			cfg.JWKSFile, cfg.SigningKeyID = jwksFile, signingKeyID
+			manager, err := NewManager(cfg)
+			require.NoError(t, err)
+
+			raw, expiresAt, err := manager.Issue("7", "ana", []string{"auditor"}, nil)
+			require.NoError(t, err)
+			assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, 5*time.Second)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(raw, &Claims{})
+			require.NoError(t, err)
+			assert.Equal(t, name, parsed.Method.Alg())
+
+			claims, err := manager.Verify(raw)
+			require.NoError(t, err)
+			assert.Equal(t, "7", claims.Subject)
+			assert.Equal(t, "ana", claims.Username)
+			assert.Equal(t, []string{"auditor"}, claims.Roles)
+			assert.NotEmpty(t, claims.ID)
+			assert.Empty(t, claims.SessionID)
+		})
+	}
+}
+
+// Caso de prueba: el token de una sesión la identifica y el de renovación solo se guarda como hash
+func TestIssueForSession(t *testing.T) {
+	manager, err := NewManager(testAuthConfig())
+	require.NoError(t, err)
+
+	raw, _, err := manager.IssueForSession("7", "ana", "12", []string{AMRPassword})
+	require.NoError(t, err)
+	claims, err := manager.Verify(raw)
+	require.NoError(t, err)
+	assert.Equal(t, "12", claims.SessionID)
+	assert.Empty(t, claims.Roles)
+	assert.False(t, claims.MultiFactor())
+
+	raw, _, err = manager.IssueForSession("7", "ana", "12", []string{AMRPassword, AMROTP, AMRMultiFactor})
+	require.NoError(t, err)
+	claims, err = manager.Verify(raw)
+	require.NoError(t, err)
+	assert.True(t, claims.MultiFactor())
+
+	refresh, hash, err := NewRefreshToken()
+	require.NoError(t, err)
+	assert.Equal(t, HashRefreshToken(refresh), hash)
+	assert.NotContains(t, hash, refresh)
+	other, _, err := NewRefreshToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, refresh, other)
+	assert.Equal(t, config.DefaultRefreshTTL, manager.RefreshTTL())
+}
+
+// Caso de prueba: se rechazan los tokens vencidos, alterados o de otro emisor o audiencia
+func TestVerifyRejects(t *testing.T) {
+	manager, err := NewManager(testAuthConfig())
+	require.NoError(t, err)
+
+	sign := func(claims jwt.Claims, method jwt.SigningMethod, key interface{}) string {
+		raw, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		require.NoError(t, err)
+		return raw
+	}
+	valid := func() jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{
+			Issuer:    config.DefaultTokenIssuer,
+			Audience:  jwt.ClaimStrings{config.DefaultTokenAudience},
+			Subject:   "7",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		}
+	}
+
+	expired := valid()
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	otherIssuer := valid()
+	otherIssuer.Issuer = "otro"
+	otherAudience := valid()
+	otherAudience.Audience = jwt.ClaimStrings{"otro"}
+	withoutExpiry := valid()
+	withoutExpiry.ExpiresAt = nil
+	withoutSubject := valid()
+	withoutSubject.Subject = ""
+
+	cases := map[string]string{
+		"vencido":         sign(expired, jwt.SigningMethodHS256, []byte(testSecret)),
+		"otro emisor":     sign(otherIssuer, jwt.SigningMethodHS256, []byte(testSecret)),
+		"otra audiencia":  sign(otherAudience, jwt.SigningMethodHS256, []byte(testSecret)),
+		"sin vencimiento": sign(withoutExpiry, jwt.SigningMethodHS256, []byte(testSecret)),
+		"sin sujeto":      sign(withoutSubject, jwt.SigningMethodHS256, []byte(testSecret)),
+		"otro secreto":    sign(valid(), jwt.SigningMethodHS256, []byte("otro-secreto-otro-secreto-otro-s")),
+		"algoritmo none":  sign(valid(), jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType),
+		"mal formado":     "no-es-un-token",
+	}
+	for name, raw := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := manager.Verify(raw)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+
+	// El margen tolera una diferencia de reloj menor que Leeway
+	skewed := valid()
+	skewed.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-2 * time.Second))
+	_, err = manager.Verify(sign(skewed, jwt.SigningMethodHS256, []byte(testSecret)))
+	assert.NoError(t, err)
+}
+
+// Caso de prueba: sin secreto no se aceptan tokens HS256 y el JWKS público no expone la parte privada
+func TestJWKSOnly(t *testing.T) {
+	cfg := testAuthConfig()
+	cfg.Secret, cfg.JWKSFile, cfg.SigningKeyID = "", writeJWKS(t), "ed-1"
+	manager, err := NewManager(cfg)
+	require.NoError(t, err)
+
+	hs, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Issuer: cfg.Issuer, Audience: jwt.ClaimStrings{cfg.Audience}, Subject: "7",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}).SignedString([]byte(""))
+	require.NoError(t, err)
+	_, err = manager.Verify(hs)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	public := manager.PublicKeys()
+	require.Len(t, public.Keys, 2)
+	assert.Equal(t, "ed-1", public.Keys[0].Kid)
+	for _, jwk := range public.Keys {
+		assert.Empty(t, jwk.D)
+		assert.Empty(t, jwk.P)
+		assert.Empty(t, jwk.Q)
+		assert.Equal(t, "sig", jwk.Use)
+	}
+}
+
+// Caso de prueba: la configuración sin llaves o con un kid sin parte privada no es válida
+func TestNewManagerErrors(t *testing.T) {
+	cfg := testAuthConfig()
+	cfg.Secret = ""
+	_, err := NewManager(cfg)
+	assert.Error(t, err)
+
+	cfg = testAuthConfig()
+	cfg.JWKSFile, cfg.SigningKeyID = writeJWKS(t), "desconocida"
+	_, err = NewManager(cfg)
+	assert.Error(t, err)
+
+	cfg.JWKSFile = filepath.Join(t.TempDir(), "no-existe.json")
+	_, err = NewManager(cfg)
+	assert.Error(t, err)
+}
+
+// Caso de prueba: el token de un enlace solo se acepta con su firma y su propósito
+func TestLinkSigner(t *testing.T) {
+	signer := NewLinkSigner([]byte(testSecret))
+
+	raw, hash, err := signer.New(PurposeVerifyEmail)
+	require.NoError(t, err)
+	assert.Equal(t, HashRefreshToken(raw), hash)
+	verified, err := signer.Verify(PurposeVerifyEmail, raw)
+	require.NoError(t, err)
+	assert.Equal(t, hash, verified)
+
+	value, _, _ := strings.Cut(raw, ".")
+	other, _, err := signer.New(PurposeVerifyEmail)
+	require.NoError(t, err)
+	otherValue, _, _ := strings.Cut(other, ".")
+	for name, candidate := range map[string]string{
+		"sin firma":  value,
+		"alterado":   otherValue + raw[len(value):],
+		"firma rota": value + ".%%%",
+	} {
+		_, err := signer.Verify(PurposeVerifyEmail, candidate)
+		assert.ErrorIs(t, err, ErrInvalidLinkToken, name)
+	}
+	_, err = signer.Verify(PurposeResetPassword, raw)
+	assert.ErrorIs(t, err, ErrInvalidLinkToken, "otro propósito")
+	_, err = NewLinkSigner([]byte("otro-secreto-otro-secreto-otro-s")).Verify(PurposeVerifyEmail, raw)
+	assert.ErrorIs(t, err, ErrInvalidLinkToken, "otro secreto")
+}